@@ -5,11 +5,16 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"flag"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 
-	"github.com/jalitriver/gitlab-cmds/cmd/internal/commands"
+	"github.com/jalitriver/gitlab-cmds/pkg/commands"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
 )
 
 var (
@@ -21,7 +26,7 @@ func main() {
 
 	// Sanity check.
 	if len(os.Args) < 1 {
-		fmt.Fprintf(
+		i18n.Fprintf(
 			os.Stderr,
 			"\n*** Error: invalid command-line arguments: %v\n\n",
 			os.Args)
@@ -34,10 +39,32 @@ func main() {
 	// Create the GlobalCommand which is the parent of all other commands.
 	globalCmd := commands.NewGlobalCommand(basename, version)
 
-	// Invoke the global command.
-	err = globalCmd.Run(os.Args[1:])
+	// Cancel the context on SIGINT (Ctrl-C) or SIGTERM so that the
+	// command stops cleanly after the current API call.  Once the
+	// context is canceled, the default signal handling is restored so
+	// a second Ctrl-C exits immediately.  Note that stop() is not
+	// deferred because it would cancel the context when main()
+	// returns and print the message below.
+	ctx, stop := signal.NotifyContext(
+		context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+		i18n.Fprintf(os.Stderr,
+			"\n*** Interrupted: stopping after the current API call "+
+				"(press Ctrl-C again to exit immediately)\n")
+	}()
+
+	// Invoke the global command.  Note that this is the only place
+	// where the program decides to exit.  If the user asked for help
+	// with -h or --help, the usage has already been printed so we
+	// exit successfully.  Otherwise, the exit code depends on the
+	// kind of failure so scripts can branch on it.
+	result, err := globalCmd.Run(ctx, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(commands.ExitOK)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "\n*** Error: %v\n\n", err)
-		os.Exit(1)
+		os.Exit(globalCmd.ReportError(os.Stderr, result, err))
 	}
 }
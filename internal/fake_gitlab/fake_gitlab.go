@@ -0,0 +1,894 @@
+// This file provides a fake Gitlab server for testing.  The server
+// keeps groups, projects, and users in memory and emulates the subset
+// of the Gitlab REST API used by this module including pagination.
+// Errors can be injected so error paths can be tested without a real
+// Gitlab instance.
+
+package fake_gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Server
+////////////////////////////////////////////////////////////////////////
+
+// Server is a fake Gitlab server.  Use NewServer() to create a new
+// instance and the Add*() methods to populate it.
+type Server struct {
+
+	// Embed the underlying HTTP test server.
+	*httptest.Server
+
+	// PerPage is the default number of items per page when the
+	// client does not specify "per_page".  Defaults to 20 which is
+	// the same as Gitlab.
+	PerPage int
+
+	// Version is the Gitlab version reported by the server.  Defaults
+	// to "16.11.0".
+	Version string
+
+	// Enterprise is whether the server reports that it is the
+	// enterprise edition.  Defaults to false.
+	Enterprise bool
+
+	// LicensePlan is the plan of the license reported by the server
+	// (e.g., "premium").  If empty, requests for the license fail
+	// with 403 Forbidden the same as for non-administrators.
+	LicensePlan string
+
+	// Username is the username of the user who owns the token which
+	// is reported for "GET /user".  Defaults to "root".
+	Username string
+
+	// mutex protects the members below.
+	mutex sync.Mutex
+
+	// nextID is the next ID that will be assigned to a new group,
+	// project, or user.
+	nextID int
+
+	// groups are the groups on the server.
+	groups []*gitlab.Group
+
+	// projects are the projects on the server.
+	projects []*gitlab.Project
+
+	// redirects maps from the old full path of a transferred project
+	// to its ID so the project can still be found by the old path the
+	// same as with Gitlab.
+	redirects map[string]int
+
+	// users are the users on the server.
+	users []*gitlab.User
+
+	// members maps from the resource key of a group or project (see
+	// resourceKey()) to its direct members.
+	members map[string][]*gitlab.GroupMember
+
+	// variables maps from the resource key of a group or project to
+	// its CI/CD variables.
+	variables map[string][]*gitlab.ProjectVariable
+
+	// labels maps from the resource key of a group or project to its
+	// labels.
+	labels map[string][]*gitlab.GroupLabel
+
+	// approvalRules maps from the resource key of a project to its
+	// approval rules.
+	approvalRules map[string][]*gitlab.ProjectApprovalRule
+
+	// protectedBranches maps from the resource key of a project to
+	// its protected branches.
+	protectedBranches map[string][]*gitlab.ProtectedBranch
+
+	// branches maps from the resource key of a project to the
+	// branches of its repository.
+	branches map[string][]*gitlab.Branch
+
+	// milestones maps from the resource key of a project to its
+	// milestones.
+	milestones map[string][]*gitlab.Milestone
+
+	// boards maps from the resource key of a project to its issue
+	// boards.
+	boards map[string][]*gitlab.IssueBoard
+
+	// files maps from the resource key of a project to a map from
+	// the path of each file in its repository to the file content.
+	// Branches are not modeled, so every ref sees the same files.
+	files map[string]map[string]string
+
+	// commits maps from the resource key of a project to the number
+	// of commits created through the API.
+	commits map[string]int
+
+	// issues maps from the resource key of a project to its issues.
+	issues map[string][]*gitlab.Issue
+
+	// mergeRequests maps from the resource key of a project to its
+	// merge requests.
+	mergeRequests map[string][]*gitlab.MergeRequest
+
+	// events maps from the resource key of a project to its events.
+	events map[string][]*gitlab.ProjectEvent
+
+	// notes maps from the ID of a merge request to its notes.
+	notes map[int][]*gitlab.Note
+
+	// mrApprovals maps from the ID of a merge request to the number
+	// of times it was approved through the API.
+	mrApprovals map[int]int
+
+	// pipelines maps from the resource key of a project to its
+	// pipelines from oldest to newest.
+	pipelines map[string][]*gitlab.Pipeline
+
+	// pipelineVariables maps from the ID of a pipeline to the
+	// "KEY=VALUE" variables it was created with.
+	pipelineVariables map[int][]string
+
+	// releases maps from the resource key of a project to its
+	// releases.
+	releases map[string][]*gitlab.Release
+
+	// tags maps from the resource key of a project to the tags of its
+	// repository.
+	tags map[string][]*gitlab.Tag
+
+	// protectedTags maps from the resource key of a project to its
+	// protected tags.
+	protectedTags map[string][]*gitlab.ProtectedTag
+
+	// runners are the CI/CD runners on the server.
+	runners []*gitlab.Runner
+
+	// runnerKeys maps from the ID of a runner to the resource keys of
+	// the group or projects the runner is assigned to.
+	runnerKeys map[int][]string
+
+	// hooks maps from the resource key of a project to its webhooks.
+	hooks map[string][]*gitlab.ProjectHook
+
+	// hookStatus maps from the ID of a webhook to the HTTP status code
+	// its endpoint responds with when the webhook is tested.
+	hookStatus map[int]int
+
+	// hookTokens maps from the ID of a webhook to its secret token
+	// which Gitlab never returns.
+	hookTokens map[int]string
+
+	// pushMirrors maps from the resource key of a project to its push
+	// mirrors.
+	pushMirrors map[string][]*gitlab.ProjectMirror
+
+	// pullMirrors maps from the resource key of a project to its pull
+	// mirror.
+	pullMirrors map[string]*gitlab.ProjectPullMirrorDetails
+
+	// integrations maps from the resource key of a project to the
+	// settings of each of its configured integrations by name.
+	integrations map[string]map[string]map[string]string
+
+	// notificationLevels maps from the username (or "" for the caller)
+	// and resource key of a group or project separated by "|" to the
+	// notification level of the user for the group or project.
+	notificationLevels map[string]gitlab.NotificationLevelValue
+
+	// snippets are the personal snippets of the caller.
+	snippets []*gitlab.Snippet
+
+	// projectSnippets maps from the resource key of a project to its
+	// snippets.
+	projectSnippets map[string][]*gitlab.Snippet
+
+	// snippetContent maps from the ID of a personal or project
+	// snippet to its content.
+	snippetContent map[int]string
+
+	// avatars maps from the resource key of a group or project to the
+	// content of its avatar.
+	avatars map[string]string
+
+	// tokens are the personal access tokens of all users.
+	tokens []*gitlab.PersonalAccessToken
+
+	// deployTokens maps from the resource key of a group or project
+	// to its deploy tokens.
+	deployTokens map[string][]*gitlab.DeployToken
+
+	// accessTokens maps from the resource key of a group or project
+	// to its access tokens.  Group access tokens are stored as
+	// project access tokens because the two have the same fields.
+	accessTokens map[string][]*gitlab.ProjectAccessToken
+
+	// faults are the errors that will be injected.
+	faults []*fault
+
+	// requests records "METHOD path" for each request.
+	requests []string
+
+	// requestTokens records the private token sent with each request.
+	requestTokens []string
+}
+
+// fault is an error that will be injected into the response of
+// requests matching the method and path prefix.
+type fault struct {
+	method     string
+	pathPrefix string
+	status     int
+	count      int
+}
+
+// NewServer starts and returns a new fake Gitlab server.  The server
+// is closed automatically when the test finishes.
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		PerPage:       20,
+		Version:       "16.11.0",
+		Username:      "root",
+		nextID:        1,
+		members:       make(map[string][]*gitlab.GroupMember),
+		variables:     make(map[string][]*gitlab.ProjectVariable),
+		labels:        make(map[string][]*gitlab.GroupLabel),
+		approvalRules: make(map[string][]*gitlab.ProjectApprovalRule),
+
+		protectedBranches: make(map[string][]*gitlab.ProtectedBranch),
+		branches:          make(map[string][]*gitlab.Branch),
+		milestones:        make(map[string][]*gitlab.Milestone),
+		boards:            make(map[string][]*gitlab.IssueBoard),
+		files:             make(map[string]map[string]string),
+		commits:           make(map[string]int),
+		issues:            make(map[string][]*gitlab.Issue),
+		mergeRequests:     make(map[string][]*gitlab.MergeRequest),
+		events:            make(map[string][]*gitlab.ProjectEvent),
+		notes:             make(map[int][]*gitlab.Note),
+		mrApprovals:       make(map[int]int),
+		pipelines:         make(map[string][]*gitlab.Pipeline),
+		pipelineVariables: make(map[int][]string),
+		releases:          make(map[string][]*gitlab.Release),
+		tags:              make(map[string][]*gitlab.Tag),
+		protectedTags:     make(map[string][]*gitlab.ProtectedTag),
+		redirects:         make(map[string]int),
+		runnerKeys:        make(map[int][]string),
+		hooks:             make(map[string][]*gitlab.ProjectHook),
+		hookStatus:        make(map[int]int),
+		hookTokens:        make(map[int]string),
+		pushMirrors:       make(map[string][]*gitlab.ProjectMirror),
+		pullMirrors:       make(map[string]*gitlab.ProjectPullMirrorDetails),
+		integrations:      make(map[string]map[string]map[string]string),
+
+		notificationLevels: make(map[string]gitlab.NotificationLevelValue),
+		projectSnippets:    make(map[string][]*gitlab.Snippet),
+		snippetContent:     make(map[int]string),
+		avatars:            make(map[string]string),
+		deployTokens:       make(map[string][]*gitlab.DeployToken),
+		accessTokens:       make(map[string][]*gitlab.ProjectAccessToken),
+	}
+
+	// Register the handlers.
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/groups", s.listGroups)
+	mux.HandleFunc("GET /api/v4/groups/{id}", s.getGroup)
+	mux.HandleFunc("GET /api/v4/groups/{id}/projects", s.listGroupProjects)
+	mux.HandleFunc("GET /api/v4/projects", s.listProjects)
+	mux.HandleFunc("POST /api/v4/projects", s.createProject)
+	mux.HandleFunc("DELETE /api/v4/projects/{id}", s.deleteProject)
+	mux.HandleFunc("GET /api/v4/users", s.listUsers)
+	mux.HandleFunc("POST /api/v4/users", s.createUser)
+	mux.HandleFunc("GET /api/v4/users/{id}", s.getUser)
+	mux.HandleFunc("GET /api/v4/user", s.getCurrentUser)
+	mux.HandleFunc("DELETE /api/v4/users/{id}", s.deleteUser)
+	mux.HandleFunc("POST /api/v4/users/{id}/block", s.changeUserState("blocked", "active", "deactivated"))
+	mux.HandleFunc("POST /api/v4/users/{id}/unblock", s.changeUserState("active", "blocked"))
+	mux.HandleFunc("POST /api/v4/users/{id}/deactivate", s.changeUserState("deactivated", "active"))
+	mux.HandleFunc("POST /api/v4/users/{id}/activate", s.changeUserState("active", "deactivated"))
+	mux.HandleFunc("GET /api/v4/version", s.getVersion)
+	mux.HandleFunc("GET /api/v4/metadata", s.getMetadata)
+	mux.HandleFunc("GET /api/v4/license", s.getLicense)
+	s.registerResourceHandlers(mux)
+
+	// Start the server wrapping the handlers so faults can be
+	// injected.
+	s.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if s.injectFault(w, r) {
+				return
+			}
+			mux.ServeHTTP(w, r)
+		}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Client returns a new Gitlab client that talks to this server.
+// Retries are disabled so injected errors are returned immediately.
+func (s *Server) Client(t testing.TB) *gitlab.Client {
+	client, err := gitlab.NewClient("token",
+		gitlab.WithBaseURL(s.URL),
+		gitlab.WithCustomRetryMax(0))
+	if err != nil {
+		t.Fatalf("unable to create Gitlab client: %v", err)
+	}
+	return client
+}
+
+// InjectError causes the next count requests whose method matches
+// and whose path (relative to "/api/v4") starts with pathPrefix to
+// fail with the HTTP status.
+func (s *Server) InjectError(method string, pathPrefix string, status int, count int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.faults = append(s.faults, &fault{
+		method:     method,
+		pathPrefix: pathPrefix,
+		status:     status,
+		count:      count,
+	})
+}
+
+// Requests returns "METHOD path" for each request received so far.
+func (s *Server) Requests() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.requests)
+}
+
+// RequestTokens returns the private token sent with each request
+// received so far.
+func (s *Server) RequestTokens() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.requestTokens)
+}
+
+// injectFault writes an error response and returns true if the
+// request matches an injected fault.  This function also records the
+// request.
+func (s *Server) injectFault(w http.ResponseWriter, r *http.Request) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/api/v4")
+	s.requests = append(s.requests, r.Method+" "+path)
+	s.requestTokens = append(s.requestTokens, r.Header.Get("PRIVATE-TOKEN"))
+	for _, f := range s.faults {
+		if f.count > 0 && f.method == r.Method && strings.HasPrefix(path, f.pathPrefix) {
+			f.count--
+			writeError(w, f.status, "injected error")
+			return true
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////
+// Fixtures
+////////////////////////////////////////////////////////////////////////
+
+// AddGroup adds a group having the full path.  The parent group, if
+// any, must have already been added.
+func (s *Server) AddGroup(fullPath string) *gitlab.Group {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.addGroup(fullPath)
+}
+
+// addGroup adds a group having the full path.  The caller must hold
+// the mutex.
+func (s *Server) addGroup(fullPath string) *gitlab.Group {
+	g := &gitlab.Group{
+		ID:       s.nextID,
+		Name:     fullPath[strings.LastIndex(fullPath, "/")+1:],
+		Path:     fullPath[strings.LastIndex(fullPath, "/")+1:],
+		FullPath: fullPath,
+		FullName: fullPath,
+	}
+	if i := strings.LastIndex(fullPath, "/"); i >= 0 {
+		if parent := s.findGroup(fullPath[:i]); parent != nil {
+			g.ParentID = parent.ID
+		}
+	}
+	s.nextID++
+	s.groups = append(s.groups, g)
+	return g
+}
+
+// AddProject adds a project having the path to the group having the
+// full path.  The group must have already been added.
+func (s *Server) AddProject(groupFullPath string, path string) *gitlab.Project {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.addProject(s.findGroup(groupFullPath), path)
+}
+
+// AddUser adds a user.
+func (s *Server) AddUser(username string, name string, email string) *gitlab.User {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	u := &gitlab.User{
+		ID:       s.nextID,
+		Username: username,
+		Name:     name,
+		Email:    email,
+		State:    "active",
+	}
+	s.nextID++
+	s.users = append(s.users, u)
+	return u
+}
+
+// Users returns the usernames of all users on the server.
+func (s *Server) Users() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, u := range s.users {
+		result = append(result, u.Username)
+	}
+	return result
+}
+
+// Projects returns the full paths of all projects on the server.
+func (s *Server) Projects() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, p := range s.projects {
+		result = append(result, p.PathWithNamespace)
+	}
+	return result
+}
+
+// Project returns a copy of the project having the ID or full path or
+// nil if it does not exist.
+func (s *Server) Project(id string) *gitlab.Project {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p := s.findProject(id)
+	if p == nil {
+		return nil
+	}
+	result := *p
+	return &result
+}
+
+// addProject adds a project having the path to the group.  The
+// caller must hold the mutex.
+func (s *Server) addProject(g *gitlab.Group, path string) *gitlab.Project {
+	p := &gitlab.Project{
+		ID:                s.nextID,
+		Name:              path,
+		Path:              path,
+		PathWithNamespace: g.FullPath + "/" + path,
+		NameWithNamespace: g.FullPath + "/" + path,
+		DefaultBranch:     "main",
+		Visibility:        gitlab.PrivateVisibility,
+		Namespace: &gitlab.ProjectNamespace{
+			ID:       g.ID,
+			Name:     g.Name,
+			Path:     g.Path,
+			Kind:     "group",
+			FullPath: g.FullPath,
+		},
+	}
+	s.nextID++
+	s.projects = append(s.projects, p)
+	return p
+}
+
+// findProject returns the project having the ID or full path or nil
+// if the project does not exist.  The caller must hold the mutex.
+func (s *Server) findProject(id string) *gitlab.Project {
+	for _, p := range s.projects {
+		if strconv.Itoa(p.ID) == id || p.PathWithNamespace == id {
+			return p
+		}
+	}
+	return nil
+}
+
+// findGroup returns the group having the ID or full path or nil if
+// the group does not exist.  The caller must hold the mutex.
+func (s *Server) findGroup(id string) *gitlab.Group {
+	for _, g := range s.groups {
+		if strconv.Itoa(g.ID) == id || g.FullPath == id {
+			return g
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// Handlers
+////////////////////////////////////////////////////////////////////////
+
+// listGroups handles "GET /groups".
+func (s *Server) listGroups(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	search := r.URL.Query().Get("search")
+	var result []*gitlab.Group
+	for _, g := range s.groups {
+		if strings.Contains(g.FullPath, search) {
+			result = append(result, g)
+		}
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// getGroup handles "GET /groups/:id".
+func (s *Server) getGroup(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	g := s.findGroup(r.PathValue("id"))
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, g)
+}
+
+// listGroupProjects handles "GET /groups/:id/projects".
+func (s *Server) listGroupProjects(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	g := s.findGroup(r.PathValue("id"))
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+	recursive := r.URL.Query().Get("include_subgroups") == "true"
+	var result []*gitlab.Project
+	for _, p := range s.projects {
+		ns := p.Namespace.FullPath
+		if ns == g.FullPath || (recursive && strings.HasPrefix(ns, g.FullPath+"/")) {
+			result = append(result, p)
+		}
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// listProjects handles "GET /projects".  The search string matches
+// the path or name of the project.
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	search := r.URL.Query().Get("search")
+	var result []*gitlab.Project
+	for _, p := range s.projects {
+		if strings.Contains(p.Path, search) || strings.Contains(p.Name, search) {
+			result = append(result, p)
+		}
+	}
+	writeKeysetPage(w, r, result, s.PerPage)
+}
+
+// createProject handles "POST /projects".
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var opts gitlab.CreateProjectOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.NamespaceID == nil || opts.Path == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	g := s.findGroup(strconv.Itoa(*opts.NamespaceID))
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+	if s.findProject(g.FullPath+"/"+*opts.Path) != nil {
+		writeError(w, http.StatusBadRequest, "has already been taken")
+		return
+	}
+	p := s.addProject(g, *opts.Path)
+	if opts.Name != nil {
+		p.Name = *opts.Name
+	}
+	if opts.DefaultBranch != nil {
+		p.DefaultBranch = *opts.DefaultBranch
+	}
+	if opts.Description != nil {
+		p.Description = *opts.Description
+	}
+	if opts.Visibility != nil {
+		p.Visibility = *opts.Visibility
+	}
+	if opts.OnlyAllowMergeIfAllDiscussionsAreResolved != nil {
+		p.OnlyAllowMergeIfAllDiscussionsAreResolved =
+			*opts.OnlyAllowMergeIfAllDiscussionsAreResolved
+	}
+	if opts.OnlyAllowMergeIfPipelineSucceeds != nil {
+		p.OnlyAllowMergeIfPipelineSucceeds = *opts.OnlyAllowMergeIfPipelineSucceeds
+	}
+	if opts.RemoveSourceBranchAfterMerge != nil {
+		p.RemoveSourceBranchAfterMerge = *opts.RemoveSourceBranchAfterMerge
+	}
+	if opts.Topics != nil {
+		p.Topics = *opts.Topics
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// deleteProject handles "DELETE /projects/:id".
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := r.PathValue("id")
+	i := slices.IndexFunc(s.projects, func(p *gitlab.Project) bool {
+		return strconv.Itoa(p.ID) == id || p.PathWithNamespace == id
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	s.projects = slices.Delete(s.projects, i, i+1)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// listUsers handles "GET /users".
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	search := r.URL.Query().Get("search")
+	username := r.URL.Query().Get("username")
+	var result []*gitlab.User
+	for _, u := range s.users {
+		if username != "" {
+			if u.Username == username {
+				result = append(result, u)
+			}
+		} else if strings.Contains(u.Username, search) ||
+			strings.Contains(u.Name, search) ||
+			u.Email == search {
+			result = append(result, u)
+		}
+	}
+	writeKeysetPage(w, r, result, s.PerPage)
+}
+
+// createUser handles "POST /users".
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var opts gitlab.CreateUserOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Username == nil || opts.Email == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	for _, u := range s.users {
+		if u.Username == *opts.Username {
+			writeError(w, http.StatusConflict, "Username has already been taken")
+			return
+		}
+	}
+	u := &gitlab.User{
+		ID:       s.nextID,
+		Username: *opts.Username,
+		Email:    *opts.Email,
+		State:    "active",
+	}
+	if opts.Name != nil {
+		u.Name = *opts.Name
+	}
+	s.nextID++
+	s.users = append(s.users, u)
+	writeJSON(w, http.StatusCreated, u)
+}
+
+// deleteUser handles "DELETE /users/:id".
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := r.PathValue("id")
+	i := slices.IndexFunc(s.users, func(u *gitlab.User) bool {
+		return strconv.Itoa(u.ID) == id
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 User Not Found")
+		return
+	}
+	s.users = slices.Delete(s.users, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getUser handles "GET /users/:id".
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := r.PathValue("id")
+	for _, u := range s.users {
+		if strconv.Itoa(u.ID) == id {
+			writeJSON(w, http.StatusOK, u)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 User Not Found")
+}
+
+// getCurrentUser handles "GET /user" which returns the user who owns
+// the token.
+func (s *Server) getCurrentUser(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if u.Username == s.Username {
+			writeJSON(w, http.StatusOK, u)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, &gitlab.User{Username: s.Username})
+}
+
+// changeUserState returns the handler for "POST /users/:id/<action>"
+// which changes the state of the user to the state.  Like Gitlab, the
+// handler responds with "403 Forbidden" if the user is not in one of
+// the from states or already in the state.
+func (s *Server) changeUserState(state string, from ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		id := r.PathValue("id")
+		for _, u := range s.users {
+			if strconv.Itoa(u.ID) != id {
+				continue
+			}
+			if !slices.Contains(from, u.State) {
+				writeError(w, http.StatusForbidden, "403 Forbidden")
+				return
+			}
+			u.State = state
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		writeError(w, http.StatusNotFound, "404 User Not Found")
+	}
+}
+
+////////////////////////////////////////////////////////////////////////
+// Responses
+////////////////////////////////////////////////////////////////////////
+
+// writeJSON writes the value as the JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the Gitlab-style error message.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+// writePage writes the page of items requested by the "page" and
+// "per_page" query parameters along with the pagination headers that
+// Gitlab uses for offset-based pagination.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T, defaultPerPage int) {
+
+	// Get the requested page.
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	totalPages := (len(items) + perPage - 1) / perPage
+
+	// Slice out the page.
+	start := min((page-1)*perPage, len(items))
+	end := min(start+perPage, len(items))
+	result := items[start:end]
+	if result == nil {
+		result = []T{}
+	}
+
+	// Set the pagination headers.
+	w.Header().Set("X-Page", strconv.Itoa(page))
+	w.Header().Set("X-Per-Page", strconv.Itoa(perPage))
+	w.Header().Set("X-Total", strconv.Itoa(len(items)))
+	w.Header().Set("X-Total-Pages", strconv.Itoa(totalPages))
+	if page < totalPages {
+		w.Header().Set("X-Next-Page", strconv.Itoa(page+1))
+	}
+	if page > 1 {
+		w.Header().Set("X-Prev-Page", strconv.Itoa(page-1))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// writeKeysetPage writes the page of items requested by the "cursor"
+// and "per_page" query parameters along with the Link header that
+// Gitlab uses for keyset-based pagination.  The cursor is the index of
+// the first item on the page.  If keyset pagination was not requested
+// by the "pagination" query parameter, the page is written by
+// writePage() instead.
+func writeKeysetPage[T any](w http.ResponseWriter, r *http.Request, items []T, defaultPerPage int) {
+	query := r.URL.Query()
+	if query.Get("pagination") != "keyset" {
+		writePage(w, r, items, defaultPerPage)
+		return
+	}
+
+	// Get the requested page.
+	start, err := strconv.Atoi(query.Get("cursor"))
+	if err != nil || start < 0 {
+		start = 0
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+
+	// Slice out the page.
+	start = min(start, len(items))
+	end := min(start+perPage, len(items))
+	result := items[start:end]
+	if result == nil {
+		result = []T{}
+	}
+
+	// Set the pagination headers.
+	w.Header().Set("X-Per-Page", strconv.Itoa(perPage))
+	if end < len(items) {
+		query.Set("cursor", strconv.Itoa(end))
+		next := url.URL{
+			Scheme:   "http",
+			Host:     r.Host,
+			Path:     r.URL.Path,
+			RawQuery: query.Encode(),
+		}
+		w.Header().Set("Link", "<"+next.String()+">; rel=\"next\"")
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Instance
+////////////////////////////////////////////////////////////////////////
+
+// getVersion handles "GET /version".
+func (s *Server) getVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, gitlab.Version{
+		Version:  s.Version,
+		Revision: "fake",
+	})
+}
+
+// getMetadata handles "GET /metadata".
+func (s *Server) getMetadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, gitlab.Metadata{
+		Version:    s.Version,
+		Revision:   "fake",
+		Enterprise: s.Enterprise,
+	})
+}
+
+// getLicense handles "GET /license".
+func (s *Server) getLicense(w http.ResponseWriter, r *http.Request) {
+	if s.LicensePlan == "" {
+		writeError(w, http.StatusForbidden, "403 Forbidden")
+		return
+	}
+	writeJSON(w, http.StatusOK, gitlab.License{Plan: s.LicensePlan})
+}
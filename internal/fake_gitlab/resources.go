@@ -0,0 +1,4070 @@
+// This file extends the fake Gitlab server with subgroups, members,
+// CI/CD variables, labels, milestones, issue boards, approval rules,
+// protected branches, branches, repository files, commits, issues, merge
+// requests, merge request notes, project events, pipelines, releases,
+// tags, protected tags, runners, webhooks, push and pull mirrors,
+// integrations, notification settings, snippets, archiving, transfers,
+// avatars, search, project import/export, user memberships, personal
+// access tokens, deploy tokens, and group and project access tokens.
+
+package fake_gitlab
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Registration
+////////////////////////////////////////////////////////////////////////
+
+// registerResourceHandlers registers the handlers in this file.
+func (s *Server) registerResourceHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v4/groups", s.createGroup)
+	mux.HandleFunc("DELETE /api/v4/groups/{id}", s.deleteGroup)
+	mux.HandleFunc("GET /api/v4/groups/{id}/subgroups", s.listSubgroups)
+	mux.HandleFunc("GET /api/v4/projects/{id}", s.getProject)
+
+	// Members.
+	mux.HandleFunc("GET /api/v4/groups/{id}/members",
+		s.resourceHandler("group", s.listMembers))
+	mux.HandleFunc("GET /api/v4/groups/{id}/members/all",
+		s.resourceHandler("group", s.listAllMembers))
+	mux.HandleFunc("POST /api/v4/groups/{id}/members",
+		s.resourceHandler("group", s.addMember))
+	mux.HandleFunc("PUT /api/v4/groups/{id}/members/{user}",
+		s.resourceHandler("group", s.editMember))
+	mux.HandleFunc("DELETE /api/v4/groups/{id}/members/{user}",
+		s.resourceHandler("group", s.removeMember))
+	mux.HandleFunc("GET /api/v4/projects/{id}/members",
+		s.resourceHandler("project", s.listMembers))
+	mux.HandleFunc("GET /api/v4/projects/{id}/members/all",
+		s.resourceHandler("project", s.listAllMembers))
+	mux.HandleFunc("POST /api/v4/projects/{id}/members",
+		s.resourceHandler("project", s.addMember))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/members/{user}",
+		s.resourceHandler("project", s.editMember))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/members/{user}",
+		s.resourceHandler("project", s.removeMember))
+
+	// Variables.
+	mux.HandleFunc("GET /api/v4/groups/{id}/variables",
+		s.resourceHandler("group", s.listVariables))
+	mux.HandleFunc("POST /api/v4/groups/{id}/variables",
+		s.resourceHandler("group", s.createVariable))
+	mux.HandleFunc("PUT /api/v4/groups/{id}/variables/{key}",
+		s.resourceHandler("group", s.updateVariable))
+	mux.HandleFunc("DELETE /api/v4/groups/{id}/variables/{key}",
+		s.resourceHandler("group", s.deleteVariable))
+	mux.HandleFunc("GET /api/v4/projects/{id}/variables",
+		s.resourceHandler("project", s.listVariables))
+	mux.HandleFunc("POST /api/v4/projects/{id}/variables",
+		s.resourceHandler("project", s.createVariable))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/variables/{key}",
+		s.resourceHandler("project", s.updateVariable))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/variables/{key}",
+		s.resourceHandler("project", s.deleteVariable))
+
+	// Labels.
+	mux.HandleFunc("GET /api/v4/groups/{id}/labels",
+		s.resourceHandler("group", s.listLabels))
+	mux.HandleFunc("POST /api/v4/groups/{id}/labels",
+		s.resourceHandler("group", s.createLabel))
+	mux.HandleFunc("GET /api/v4/projects/{id}/labels",
+		s.resourceHandler("project", s.listLabels))
+	mux.HandleFunc("POST /api/v4/projects/{id}/labels",
+		s.resourceHandler("project", s.createLabel))
+
+	// Milestones.
+	mux.HandleFunc("GET /api/v4/projects/{id}/milestones",
+		s.resourceHandler("project", s.listMilestones))
+	mux.HandleFunc("POST /api/v4/projects/{id}/milestones",
+		s.resourceHandler("project", s.createMilestone))
+
+	// Issue boards.
+	mux.HandleFunc("GET /api/v4/projects/{id}/boards",
+		s.resourceHandler("project", s.listBoards))
+	mux.HandleFunc("POST /api/v4/projects/{id}/boards",
+		s.resourceHandler("project", s.createBoard))
+	mux.HandleFunc("POST /api/v4/projects/{id}/boards/{board}/lists",
+		s.resourceHandler("project", s.createBoardList))
+
+	// Approval rules.
+	mux.HandleFunc("GET /api/v4/projects/{id}/approval_rules",
+		s.resourceHandler("project", s.listApprovalRules))
+	mux.HandleFunc("POST /api/v4/projects/{id}/approval_rules",
+		s.resourceHandler("project", s.createApprovalRule))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/approval_rules/{rule}",
+		s.resourceHandler("project", s.updateApprovalRule))
+
+	// Protected branches.
+	mux.HandleFunc("GET /api/v4/projects/{id}/protected_branches",
+		s.resourceHandler("project", s.listProtectedBranches))
+	mux.HandleFunc("POST /api/v4/projects/{id}/protected_branches",
+		s.resourceHandler("project", s.protectBranch))
+	mux.HandleFunc("PATCH /api/v4/projects/{id}/protected_branches/{name}",
+		s.resourceHandler("project", s.updateProtectedBranch))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/protected_branches/{name}",
+		s.resourceHandler("project", s.unprotectBranch))
+
+	// Branches.
+	mux.HandleFunc("GET /api/v4/projects/{id}/repository/branches",
+		s.resourceHandler("project", s.listBranches))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/repository/branches/{name}",
+		s.resourceHandler("project", s.deleteBranch))
+
+	// Repository files and commits.
+	mux.HandleFunc("GET /api/v4/projects/{id}/repository/files/{path}",
+		s.resourceHandler("project", s.getFile))
+	mux.HandleFunc("POST /api/v4/projects/{id}/repository/commits",
+		s.resourceHandler("project", s.createCommit))
+
+	// Issues and merge requests.
+	mux.HandleFunc("GET /api/v4/projects/{id}/issues",
+		s.resourceHandler("project", s.listIssues))
+	mux.HandleFunc("POST /api/v4/projects/{id}/issues",
+		s.resourceHandler("project", s.createIssue))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/issues/{iid}",
+		s.resourceHandler("project", s.updateIssue))
+	mux.HandleFunc("POST /api/v4/projects/{id}/issues/{iid}/move",
+		s.resourceHandler("project", s.moveIssue))
+	mux.HandleFunc("GET /api/v4/projects/{id}/merge_requests",
+		s.resourceHandler("project", s.listMergeRequests))
+	mux.HandleFunc("POST /api/v4/projects/{id}/merge_requests",
+		s.resourceHandler("project", s.createMergeRequest))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/merge_requests/{iid}",
+		s.resourceHandler("project", s.updateMergeRequest))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/merge_requests/{iid}/merge",
+		s.resourceHandler("project", s.acceptMergeRequest))
+	mux.HandleFunc("POST /api/v4/projects/{id}/merge_requests/{iid}/approve",
+		s.resourceHandler("project", s.approveMergeRequest))
+	mux.HandleFunc("GET /api/v4/projects/{id}/merge_requests/{iid}/notes",
+		s.resourceHandler("project", s.listMergeRequestNotes))
+	mux.HandleFunc("GET /api/v4/projects/{id}/events",
+		s.resourceHandler("project", s.listEvents))
+
+	// Pipelines.
+	mux.HandleFunc("GET /api/v4/projects/{id}/pipelines",
+		s.resourceHandler("project", s.listPipelines))
+	mux.HandleFunc("POST /api/v4/projects/{id}/pipeline",
+		s.resourceHandler("project", s.createPipeline))
+	mux.HandleFunc("POST /api/v4/projects/{id}/pipelines/{pipeline}/retry",
+		s.resourceHandler("project", s.retryPipeline))
+	mux.HandleFunc("POST /api/v4/projects/{id}/pipelines/{pipeline}/cancel",
+		s.resourceHandler("project", s.cancelPipeline))
+
+	// Releases.
+	mux.HandleFunc("GET /api/v4/projects/{id}/releases",
+		s.resourceHandler("project", s.listReleases))
+	mux.HandleFunc("POST /api/v4/projects/{id}/releases",
+		s.resourceHandler("project", s.createRelease))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/releases/{tag}",
+		s.resourceHandler("project", s.deleteRelease))
+
+	// Tags.
+	mux.HandleFunc("GET /api/v4/projects/{id}/repository/tags",
+		s.resourceHandler("project", s.listTags))
+	mux.HandleFunc("GET /api/v4/projects/{id}/repository/tags/{tag}",
+		s.resourceHandler("project", s.getTag))
+	mux.HandleFunc("POST /api/v4/projects/{id}/repository/tags",
+		s.resourceHandler("project", s.createTag))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/repository/tags/{tag}",
+		s.resourceHandler("project", s.deleteTag))
+
+	// Protected tags.
+	mux.HandleFunc("GET /api/v4/projects/{id}/protected_tags",
+		s.resourceHandler("project", s.listProtectedTags))
+	mux.HandleFunc("POST /api/v4/projects/{id}/protected_tags",
+		s.resourceHandler("project", s.protectTag))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/protected_tags/{name}",
+		s.resourceHandler("project", s.unprotectTag))
+
+	// Webhooks.
+	mux.HandleFunc("GET /api/v4/projects/{id}/hooks",
+		s.resourceHandler("project", s.listHooks))
+	mux.HandleFunc("POST /api/v4/projects/{id}/hooks",
+		s.resourceHandler("project", s.addHook))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/hooks/{hook}",
+		s.resourceHandler("project", s.editHook))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/hooks/{hook}",
+		s.resourceHandler("project", s.deleteHook))
+	mux.HandleFunc("POST /api/v4/projects/{id}/hooks/{hook}/test/{trigger}",
+		s.resourceHandler("project", s.testHook))
+
+	// Mirrors.
+	mux.HandleFunc("PUT /api/v4/projects/{id}",
+		s.resourceHandler("project", s.editProject))
+	mux.HandleFunc("GET /api/v4/projects/{id}/mirror/pull",
+		s.resourceHandler("project", s.getPullMirror))
+	mux.HandleFunc("GET /api/v4/projects/{id}/remote_mirrors",
+		s.resourceHandler("project", s.listPushMirrors))
+	mux.HandleFunc("POST /api/v4/projects/{id}/remote_mirrors",
+		s.resourceHandler("project", s.addPushMirror))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/remote_mirrors/{mirror}",
+		s.resourceHandler("project", s.editPushMirror))
+
+	// Integrations.
+	mux.HandleFunc("GET /api/v4/projects/{id}/integrations/{slug}",
+		s.resourceHandler("project", s.getIntegration))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/integrations/{slug}",
+		s.resourceHandler("project", s.setIntegration))
+
+	// Notification settings.
+	mux.HandleFunc("GET /api/v4/groups/{id}/notification_settings",
+		s.resourceHandler("group", s.getNotificationSettings))
+	mux.HandleFunc("PUT /api/v4/groups/{id}/notification_settings",
+		s.resourceHandler("group", s.updateNotificationSettings))
+	mux.HandleFunc("GET /api/v4/projects/{id}/notification_settings",
+		s.resourceHandler("project", s.getNotificationSettings))
+	mux.HandleFunc("PUT /api/v4/projects/{id}/notification_settings",
+		s.resourceHandler("project", s.updateNotificationSettings))
+
+	// Snippets.
+	mux.HandleFunc("GET /api/v4/snippets", s.listSnippets)
+	mux.HandleFunc("POST /api/v4/snippets", s.createSnippet)
+	mux.HandleFunc("DELETE /api/v4/snippets/{snippet}", s.deleteSnippet)
+	mux.HandleFunc("GET /api/v4/projects/{id}/snippets",
+		s.resourceHandler("project", s.listProjectSnippets))
+	mux.HandleFunc("POST /api/v4/projects/{id}/snippets",
+		s.resourceHandler("project", s.createProjectSnippet))
+	mux.HandleFunc("DELETE /api/v4/projects/{id}/snippets/{snippet}",
+		s.resourceHandler("project", s.deleteProjectSnippet))
+	mux.HandleFunc("GET /api/v4/projects/{id}/snippets/{snippet}/raw",
+		s.resourceHandler("project", s.getProjectSnippetContent))
+
+	// Runners.
+	mux.HandleFunc("GET /api/v4/runners/all", s.listAllRunners)
+	mux.HandleFunc("PUT /api/v4/runners/{runner}", s.updateRunner)
+	mux.HandleFunc("DELETE /api/v4/runners/{runner}", s.removeRunner)
+	mux.HandleFunc("GET /api/v4/groups/{id}/runners",
+		s.resourceHandler("group", s.listRunners))
+	mux.HandleFunc("GET /api/v4/projects/{id}/runners",
+		s.resourceHandler("project", s.listRunners))
+	mux.HandleFunc("POST /api/v4/projects/{id}/runners",
+		s.resourceHandler("project", s.enableProjectRunner))
+
+	// Archiving.
+	mux.HandleFunc("POST /api/v4/projects/{id}/archive",
+		s.resourceHandler("project", s.archiveProject))
+	mux.HandleFunc("POST /api/v4/projects/{id}/unarchive",
+		s.resourceHandler("project", s.unarchiveProject))
+
+	// Transfers.
+	mux.HandleFunc("PUT /api/v4/projects/{id}/transfer",
+		s.resourceHandler("project", s.transferProject))
+
+	// Avatars.  Note that project avatars are uploaded by
+	// "PUT /projects/:id" which is registered above.
+	mux.HandleFunc("PUT /api/v4/groups/{id}",
+		s.resourceHandler("group", s.editGroup))
+
+	// Search.
+	mux.HandleFunc("GET /api/v4/search", s.searchInstance)
+	mux.HandleFunc("GET /api/v4/groups/{id}/-/search",
+		s.resourceHandler("group", s.searchGroup))
+
+	// Import and export.
+	mux.HandleFunc("POST /api/v4/projects/{id}/export", s.scheduleExport)
+	mux.HandleFunc("GET /api/v4/projects/{id}/export", s.exportStatus)
+	mux.HandleFunc("GET /api/v4/projects/{id}/export/download", s.exportDownload)
+	mux.HandleFunc("POST /api/v4/projects/import", s.importProject)
+	mux.HandleFunc("GET /api/v4/projects/{id}/import", s.importStatus)
+
+	// User memberships and personal access tokens.
+	mux.HandleFunc("GET /api/v4/users/{id}/memberships", s.listUserMemberships)
+	mux.HandleFunc("GET /api/v4/personal_access_tokens", s.listPersonalAccessTokens)
+
+	// Deploy tokens and access tokens.
+	for _, kind := range []string{"group", "project"} {
+		prefix := "/api/v4/" + kind + "s/{id}"
+		mux.HandleFunc("GET "+prefix+"/deploy_tokens",
+			s.resourceHandler(kind, s.listDeployTokens))
+		mux.HandleFunc("POST "+prefix+"/deploy_tokens",
+			s.resourceHandler(kind, s.createDeployToken))
+		mux.HandleFunc("DELETE "+prefix+"/deploy_tokens/{token}",
+			s.resourceHandler(kind, s.deleteDeployToken))
+		mux.HandleFunc("GET "+prefix+"/access_tokens",
+			s.resourceHandler(kind, s.listAccessTokens))
+		mux.HandleFunc("POST "+prefix+"/access_tokens",
+			s.resourceHandler(kind, s.createAccessToken))
+		mux.HandleFunc("POST "+prefix+"/access_tokens/{token}/rotate",
+			s.resourceHandler(kind, s.rotateAccessToken))
+		mux.HandleFunc("DELETE "+prefix+"/access_tokens/{token}",
+			s.resourceHandler(kind, s.revokeAccessToken))
+	}
+}
+
+// resourceKey returns the key for the group or project having the
+// full path in the maps that hold members, variables, labels,
+// milestones, issue boards, approval rules, protected branches,
+// repository files, commits, issues, merge requests, events,
+// pipelines, releases, webhooks, mirrors, integrations, notification
+// settings, snippets, avatars, and tokens.  The kind is "group" or
+// "project".
+func resourceKey(kind string, fullPath string) string {
+	return kind + ":" + fullPath
+}
+
+// resourceHandler returns a handler that looks up the group or
+// project (depending on kind) from the "id" path value and calls f
+// with its resource key while holding the mutex.
+func (s *Server) resourceHandler(
+	kind string,
+	f func(w http.ResponseWriter, r *http.Request, key string),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		id := r.PathValue("id")
+		var fullPath string
+		if kind == "group" {
+			if g := s.findGroup(id); g != nil {
+				fullPath = g.FullPath
+			}
+		} else {
+			if p := s.findProject(id); p != nil {
+				fullPath = p.PathWithNamespace
+			}
+		}
+		if fullPath == "" {
+			writeError(w, http.StatusNotFound, "404 Not Found")
+			return
+		}
+		f(w, r, resourceKey(kind, fullPath))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////
+// Fixtures
+////////////////////////////////////////////////////////////////////////
+
+// AddGroupMember adds the user as a direct member of the group.
+func (s *Server) AddGroupMember(groupFullPath string, username string, level gitlab.AccessLevelValue) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.addMemberByUsername(resourceKey("group", groupFullPath), username, level)
+}
+
+// AddProjectMember adds the user as a direct member of the project.
+func (s *Server) AddProjectMember(projectFullPath string, username string, level gitlab.AccessLevelValue) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.addMemberByUsername(resourceKey("project", projectFullPath), username, level)
+}
+
+// SetMemberExpiry sets the date on which the direct membership of the
+// user in the group or project (depending on kind) expires.
+func (s *Server) SetMemberExpiry(kind string, fullPath string, username string, expires time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, m := range s.members[resourceKey(kind, fullPath)] {
+		if m.Username == username {
+			m.ExpiresAt = gitlab.Ptr(gitlab.ISOTime(expires))
+		}
+	}
+}
+
+// AddGroupVariable adds a CI/CD variable to the group.
+func (s *Server) AddGroupVariable(groupFullPath string, key string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("group", groupFullPath)
+	s.variables[k] = append(s.variables[k], &gitlab.ProjectVariable{
+		Key:              key,
+		Value:            value,
+		EnvironmentScope: "*",
+	})
+}
+
+// AddProjectVariable adds a CI/CD variable to the project.
+func (s *Server) AddProjectVariable(projectFullPath string, v *gitlab.ProjectVariable) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.variables[k] = append(s.variables[k], v)
+}
+
+// AddGroupLabel adds a label to the group.
+func (s *Server) AddGroupLabel(groupFullPath string, name string, color string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("group", groupFullPath)
+	s.labels[k] = append(s.labels[k], &gitlab.GroupLabel{
+		ID:    s.nextID,
+		Name:  name,
+		Color: color,
+	})
+	s.nextID++
+}
+
+// AddProjectLabel adds a label to the project.
+func (s *Server) AddProjectLabel(projectFullPath string, name string, color string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.labels[k] = append(s.labels[k], &gitlab.GroupLabel{
+		ID:    s.nextID,
+		Name:  name,
+		Color: color,
+	})
+	s.nextID++
+}
+
+// AddMilestone adds a milestone to the project.
+func (s *Server) AddMilestone(projectFullPath string, title string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.milestones[k] = append(s.milestones[k], &gitlab.Milestone{
+		ID:    s.nextID,
+		Title: title,
+		State: "active",
+	})
+	s.nextID++
+}
+
+// AddBoard adds an issue board to the project having a list for each
+// of the project's labels having one of the label names.
+func (s *Server) AddBoard(projectFullPath string, name string, labelNames ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	board := &gitlab.IssueBoard{ID: s.nextID, Name: name}
+	s.nextID++
+	for _, l := range s.labels[k] {
+		if slices.Contains(labelNames, l.Name) {
+			board.Lists = append(board.Lists, &gitlab.BoardList{
+				ID:       s.nextID,
+				Label:    (*gitlab.Label)(l),
+				Position: len(board.Lists),
+			})
+			s.nextID++
+		}
+	}
+	s.boards[k] = append(s.boards[k], board)
+}
+
+// AddApprovalRule adds an approval rule to the project with the users
+// as eligible approvers.
+func (s *Server) AddApprovalRule(projectFullPath string, name string, approvals int, usernames ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var users []*gitlab.BasicUser
+	for _, u := range s.users {
+		if slices.Contains(usernames, u.Username) {
+			users = append(users, &gitlab.BasicUser{ID: u.ID, Username: u.Username})
+		}
+	}
+	k := resourceKey("project", projectFullPath)
+	s.approvalRules[k] = append(s.approvalRules[k], &gitlab.ProjectApprovalRule{
+		ID:                s.nextID,
+		Name:              name,
+		RuleType:          "regular",
+		ApprovalsRequired: approvals,
+		Users:             users,
+		EligibleApprovers: users,
+	})
+	s.nextID++
+}
+
+// AddProtectedBranch protects the branch of the project.
+func (s *Server) AddProtectedBranch(projectFullPath string, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.protectedBranches[k] = append(s.protectedBranches[k], &gitlab.ProtectedBranch{
+		ID:   s.nextID,
+		Name: name,
+	})
+	s.nextID++
+}
+
+// AddBranch adds a branch to the repository of the project whose last
+// commit was at the time.  If merged is true, the branch has been
+// merged into the default branch.
+func (s *Server) AddBranch(
+	projectFullPath string,
+	name string,
+	merged bool,
+	committed time.Time,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.branches[k] = append(s.branches[k], &gitlab.Branch{
+		Name:   name,
+		Merged: merged,
+		Commit: &gitlab.Commit{
+			ID:            fmt.Sprintf("%040x", s.nextID),
+			CommittedDate: &committed,
+		},
+	})
+	s.nextID++
+}
+
+// Branches returns the names of the branches of the project.
+func (s *Server) Branches(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, b := range s.branches[resourceKey("project", projectFullPath)] {
+		result = append(result, b.Name)
+	}
+	return result
+}
+
+// AddFile adds a file with the content to the repository of the
+// project.
+func (s *Server) AddFile(projectFullPath string, path string, content string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	if s.files[k] == nil {
+		s.files[k] = make(map[string]string)
+	}
+	s.files[k][path] = content
+}
+
+// SetDefaultBranch sets the default branch of the project.
+func (s *Server) SetDefaultBranch(projectFullPath string, branch string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if p := s.findProject(projectFullPath); p != nil {
+		p.DefaultBranch = branch
+	}
+}
+
+// SetImportStatus sets the status of the import of the project which
+// is one of "none", "scheduled", "started", "finished", or "failed".
+func (s *Server) SetImportStatus(projectFullPath string, status string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if p := s.findProject(projectFullPath); p != nil {
+		p.ImportStatus = status
+	}
+}
+
+// SetLastActivity sets the time of the last activity in the project.
+func (s *Server) SetLastActivity(projectFullPath string, t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if p := s.findProject(projectFullPath); p != nil {
+		p.LastActivityAt = &t
+	}
+}
+
+// SetArchived sets whether the project is archived.
+func (s *Server) SetArchived(projectFullPath string, archived bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if p := s.findProject(projectFullPath); p != nil {
+		p.Archived = archived
+	}
+}
+
+// SetVisibility sets the visibility of the project.
+func (s *Server) SetVisibility(projectFullPath string, visibility gitlab.VisibilityValue) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if p := s.findProject(projectFullPath); p != nil {
+		p.Visibility = visibility
+	}
+}
+
+// SetTopics sets the topics of the project.
+func (s *Server) SetTopics(projectFullPath string, topics ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if p := s.findProject(projectFullPath); p != nil {
+		p.Topics = topics
+	}
+}
+
+// SetSignIn sets the time the user last signed in.
+func (s *Server) SetSignIn(username string, t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			u.LastSignInAt = &t
+			u.CurrentSignInAt = &t
+		}
+	}
+}
+
+// SetUserCreated sets the time the user was created.
+func (s *Server) SetUserCreated(username string, t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			u.CreatedAt = &t
+		}
+	}
+}
+
+// SetUserActivity sets the date of the last activity of the user.
+func (s *Server) SetUserActivity(username string, t time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			u.LastActivityOn = gitlab.Ptr(gitlab.ISOTime(t))
+		}
+	}
+}
+
+// BlockUser blocks the user.
+func (s *Server) BlockUser(username string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			u.State = "blocked"
+		}
+	}
+}
+
+// UserState returns the state (e.g., "active" or "blocked") of the
+// user or "" if there is no such user.
+func (s *Server) UserState(username string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			return u.State
+		}
+	}
+	return ""
+}
+
+// AddPersonalAccessToken adds a personal access token to the user.
+func (s *Server) AddPersonalAccessToken(username string, name string, active bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range s.users {
+		if u.Username == username {
+			s.tokens = append(s.tokens, &gitlab.PersonalAccessToken{
+				ID:      s.nextID,
+				Name:    name,
+				UserID:  u.ID,
+				Active:  active,
+				Revoked: !active,
+			})
+			s.nextID++
+		}
+	}
+}
+
+// AddDeployToken adds a deploy token with the "read_repository" scope
+// to the group or project (depending on kind).  The token never
+// expires if expires is the zero time.
+func (s *Server) AddDeployToken(kind string, fullPath string, name string, expires time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey(kind, fullPath)
+	t := &gitlab.DeployToken{
+		ID:       s.nextID,
+		Name:     name,
+		Username: fmt.Sprintf("gitlab+deploy-token-%d", s.nextID),
+		Scopes:   []string{"read_repository"},
+	}
+	if !expires.IsZero() {
+		t.ExpiresAt = &expires
+		t.Expired = expires.Before(time.Now())
+	}
+	s.nextID++
+	s.deployTokens[k] = append(s.deployTokens[k], t)
+}
+
+// AddAccessToken adds an access token with the "api" scope and the
+// maintainer access level to the group or project (depending on
+// kind).  The token never expires if expires is the zero time.
+func (s *Server) AddAccessToken(kind string, fullPath string, name string, expires time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey(kind, fullPath)
+	t := &gitlab.ProjectAccessToken{
+		ID:          s.nextID,
+		Name:        name,
+		Scopes:      []string{"api"},
+		AccessLevel: gitlab.MaintainerPermissions,
+		Active:      true,
+	}
+	if !expires.IsZero() {
+		t.ExpiresAt = gitlab.Ptr(gitlab.ISOTime(expires))
+		t.Active = !expires.Before(time.Now())
+	}
+	s.nextID++
+	s.accessTokens[k] = append(s.accessTokens[k], t)
+}
+
+// AddPushEvent adds an event for a push of the number of commits by
+// the user to the project at the time.
+func (s *Server) AddPushEvent(projectFullPath string, username string, commits int, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	e := &gitlab.ProjectEvent{
+		ID:             s.nextID,
+		ActionName:     "pushed to",
+		CreatedAt:      at.UTC().Format(time.RFC3339),
+		AuthorUsername: username,
+	}
+	e.Author.Username = username
+	e.PushData.CommitCount = commits
+	s.nextID++
+	s.events[k] = append(s.events[k], e)
+}
+
+// AddIssue adds an opened issue by the author to the project that was
+// last updated at the time.
+func (s *Server) AddIssue(
+	projectFullPath string,
+	author string,
+	title string,
+	updated time.Time,
+	labels ...string,
+) *gitlab.Issue {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	issue := &gitlab.Issue{
+		ID:        s.nextID,
+		IID:       len(s.issues[k]) + 1,
+		Title:     title,
+		State:     "opened",
+		Author:    &gitlab.IssueAuthor{Username: author},
+		Labels:    labels,
+		CreatedAt: &updated,
+		UpdatedAt: &updated,
+	}
+	s.nextID++
+	s.issues[k] = append(s.issues[k], issue)
+	return issue
+}
+
+// AddMergeRequest adds a merge request by the author to the project
+// that was created at the time and merged at the time if merged is
+// not nil.
+func (s *Server) AddMergeRequest(
+	projectFullPath string,
+	author string,
+	created time.Time,
+	merged *time.Time,
+	reviewers ...string,
+) *gitlab.MergeRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	mr := &gitlab.MergeRequest{
+		ID:        s.nextID,
+		IID:       len(s.mergeRequests[k]) + 1,
+		State:     "opened",
+		Author:    &gitlab.BasicUser{Username: author},
+		CreatedAt: &created,
+		UpdatedAt: &created,
+	}
+	if merged != nil {
+		mr.State = "merged"
+		mr.MergedAt = merged
+		mr.UpdatedAt = merged
+	}
+	for _, r := range reviewers {
+		mr.Reviewers = append(mr.Reviewers, &gitlab.BasicUser{Username: r})
+	}
+	s.nextID++
+	s.mergeRequests[k] = append(s.mergeRequests[k], mr)
+	return mr
+}
+
+// SetMergeRequest sets the title, target branch, and labels of the
+// merge request.
+func (s *Server) SetMergeRequest(
+	mr *gitlab.MergeRequest,
+	title string,
+	targetBranch string,
+	labels ...string,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	mr.Title = title
+	mr.TargetBranch = targetBranch
+	mr.Labels = labels
+}
+
+// AddMergeRequestNote adds a note by the user to the merge request at
+// the time.  System notes are used for events such as approvals.
+func (s *Server) AddMergeRequestNote(
+	mr *gitlab.MergeRequest,
+	username string,
+	body string,
+	system bool,
+	at time.Time,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	note := &gitlab.Note{
+		ID:          s.nextID,
+		Body:        body,
+		System:      system,
+		CreatedAt:   &at,
+		NoteableID:  mr.ID,
+		NoteableIID: mr.IID,
+	}
+	note.Author.Username = username
+	s.nextID++
+	s.notes[mr.ID] = append(s.notes[mr.ID], note)
+}
+
+// AddRelease adds a release for the tag to the project.
+func (s *Server) AddRelease(projectFullPath string, tag string, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.releases[k] = append(s.releases[k], &gitlab.Release{TagName: tag, Name: name})
+}
+
+// AddTag adds a tag to the repository of the project.
+func (s *Server) AddTag(projectFullPath string, name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.tags[k] = append(s.tags[k], s.newTag(name))
+}
+
+// AddProtectedTag protects the tag or wildcard of the project so only
+// the access level can create matching tags.
+func (s *Server) AddProtectedTag(
+	projectFullPath string,
+	name string,
+	level gitlab.AccessLevelValue,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	s.protectedTags[k] = append(s.protectedTags[k], &gitlab.ProtectedTag{
+		Name:               name,
+		CreateAccessLevels: s.addTagAccess(level),
+	})
+}
+
+// AddProjectHook adds a webhook for the URL to the project.  The
+// status is the HTTP status code the endpoint responds with when the
+// webhook is tested.
+func (s *Server) AddProjectHook(projectFullPath string, url string, status int) *gitlab.ProjectHook {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	h := &gitlab.ProjectHook{
+		ID:         s.nextID,
+		URL:        url,
+		PushEvents: true,
+	}
+	if p := s.findProject(projectFullPath); p != nil {
+		h.ProjectID = p.ID
+	}
+	s.nextID++
+	s.hooks[k] = append(s.hooks[k], h)
+	s.hookStatus[h.ID] = status
+	return h
+}
+
+// AddPushMirror adds an enabled push mirror for the URL to the
+// project with the update status (e.g., "finished" or "failed") and
+// the time of the last successful update which can be nil.
+func (s *Server) AddPushMirror(
+	projectFullPath string,
+	url string,
+	status string,
+	lastSuccess *time.Time,
+) *gitlab.ProjectMirror {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	m := &gitlab.ProjectMirror{
+		ID:                     s.nextID,
+		URL:                    url,
+		Enabled:                true,
+		UpdateStatus:           status,
+		LastSuccessfulUpdateAt: lastSuccess,
+	}
+	if status == "failed" {
+		m.LastError = "fatal: could not read from remote repository"
+	}
+	s.nextID++
+	s.pushMirrors[k] = append(s.pushMirrors[k], m)
+	return m
+}
+
+// SetPullMirror configures the project to pull from the URL with the
+// update status (e.g., "finished" or "failed") and the time of the
+// last successful update which can be nil.
+func (s *Server) SetPullMirror(
+	projectFullPath string,
+	url string,
+	status string,
+	lastSuccess *time.Time,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p := s.findProject(projectFullPath)
+	if p == nil {
+		return
+	}
+	p.Mirror = true
+	p.ImportURL = url
+	m := &gitlab.ProjectPullMirrorDetails{
+		ID:                     p.ID,
+		URL:                    url,
+		UpdateStatus:           status,
+		LastSuccessfulUpdateAt: lastSuccess,
+	}
+	if status == "failed" {
+		m.LastError = "fatal: could not read from remote repository"
+	}
+	s.pullMirrors[resourceKey("project", projectFullPath)] = m
+}
+
+// SetIntegration configures and activates the integration (e.g.,
+// "slack") of the project with the settings.
+func (s *Server) SetIntegration(
+	projectFullPath string,
+	slug string,
+	settings map[string]string,
+) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	if s.integrations[k] == nil {
+		s.integrations[k] = make(map[string]map[string]string)
+	}
+	m := maps.Clone(settings)
+	m["active"] = "true"
+	s.integrations[k][slug] = m
+}
+
+// AddSnippet adds a snippet having a single file with the name and
+// content to the project or, if projectFullPath is empty, to the
+// personal snippets of the caller.
+func (s *Server) AddSnippet(
+	projectFullPath string,
+	title string,
+	fileName string,
+	content string,
+) *gitlab.Snippet {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	snippet := s.newSnippet(title, fileName, "private", content)
+	if projectFullPath == "" {
+		s.snippets = append(s.snippets, snippet)
+	} else {
+		k := resourceKey("project", projectFullPath)
+		s.projectSnippets[k] = append(s.projectSnippets[k], snippet)
+	}
+	return snippet
+}
+
+// SetStatistics sets the statistics of the project which are only
+// returned when they are requested.
+func (s *Server) SetStatistics(projectFullPath string, stats gitlab.Statistics) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if p := s.findProject(projectFullPath); p != nil {
+		p.Statistics = &stats
+	}
+}
+
+// Groups returns the full paths of all groups on the server.
+func (s *Server) Groups() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, g := range s.groups {
+		result = append(result, g.FullPath)
+	}
+	return result
+}
+
+// Members returns the usernames of the direct members of the group
+// or project (depending on kind which is "group" or "project").
+func (s *Server) Members(kind string, fullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, m := range s.members[resourceKey(kind, fullPath)] {
+		result = append(result, m.Username)
+	}
+	return result
+}
+
+// MemberExpiry returns the date in the form of YYYY-MM-DD on which the
+// direct membership of the user in the group or project (depending on
+// kind) expires or "" if it does not expire.
+func (s *Server) MemberExpiry(kind string, fullPath string, username string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, m := range s.members[resourceKey(kind, fullPath)] {
+		if m.Username == username && m.ExpiresAt != nil {
+			return m.ExpiresAt.String()
+		}
+	}
+	return ""
+}
+
+// MemberAccessLevel returns the access level of the direct membership
+// of the user in the group or project (depending on kind) or 0 if the
+// user is not a direct member.
+func (s *Server) MemberAccessLevel(kind string, fullPath string, username string) gitlab.AccessLevelValue {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, m := range s.members[resourceKey(kind, fullPath)] {
+		if m.Username == username {
+			return m.AccessLevel
+		}
+	}
+	return 0
+}
+
+// HookToken returns the secret token of the webhook.
+func (s *Server) HookToken(hookID int) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.hookTokens[hookID]
+}
+
+// ActiveTokens returns the type ("access" or "deploy"), name, and
+// expiry date separated by colons of each active token of the group or
+// project (depending on kind).  The expiry date is empty if the token
+// never expires.
+func (s *Server) ActiveTokens(kind string, fullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	k := resourceKey(kind, fullPath)
+	for _, t := range s.accessTokens[k] {
+		if t.Active {
+			expires := ""
+			if t.ExpiresAt != nil {
+				expires = t.ExpiresAt.String()
+			}
+			result = append(result, "access:"+t.Name+":"+expires)
+		}
+	}
+	for _, t := range s.deployTokens[k] {
+		if !t.Revoked && !t.Expired {
+			expires := ""
+			if t.ExpiresAt != nil {
+				expires = t.ExpiresAt.Format("2006-01-02")
+			}
+			result = append(result, "deploy:"+t.Name+":"+expires)
+		}
+	}
+	return result
+}
+
+// Releases returns copies of the releases of the project.
+func (s *Server) Releases(projectFullPath string) []*gitlab.Release {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []*gitlab.Release
+	for _, release := range s.releases[resourceKey("project", projectFullPath)] {
+		copy := *release
+		result = append(result, &copy)
+	}
+	return result
+}
+
+// Tags returns the names of the tags of the project.
+func (s *Server) Tags(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, t := range s.tags[resourceKey("project", projectFullPath)] {
+		result = append(result, t.Name)
+	}
+	return result
+}
+
+// ProtectedTags returns copies of the protected tags of the project.
+func (s *Server) ProtectedTags(projectFullPath string) []*gitlab.ProtectedTag {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []*gitlab.ProtectedTag
+	for _, t := range s.protectedTags[resourceKey("project", projectFullPath)] {
+		copy := *t
+		result = append(result, &copy)
+	}
+	return result
+}
+
+// ProjectHooks returns copies of the webhooks of the project.
+func (s *Server) ProjectHooks(projectFullPath string) []*gitlab.ProjectHook {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []*gitlab.ProjectHook
+	for _, h := range s.hooks[resourceKey("project", projectFullPath)] {
+		copy := *h
+		result = append(result, &copy)
+	}
+	return result
+}
+
+// PushMirrors returns the URL of each enabled push mirror of the
+// project.
+func (s *Server) PushMirrors(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, m := range s.pushMirrors[resourceKey("project", projectFullPath)] {
+		if m.Enabled {
+			result = append(result, m.URL)
+		}
+	}
+	return result
+}
+
+// PullMirror returns the URL of the pull mirror of the project or ""
+// if the project is not a pull mirror.
+func (s *Server) PullMirror(projectFullPath string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if m := s.pullMirrors[resourceKey("project", projectFullPath)]; m != nil {
+		return m.URL
+	}
+	return ""
+}
+
+// Integration returns a copy of the settings of the integration of the
+// project or nil if the integration has never been configured.
+func (s *Server) Integration(projectFullPath string, slug string) map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return maps.Clone(s.integrations[resourceKey("project", projectFullPath)][slug])
+}
+
+// NotificationLevel returns the notification level of the user for the
+// group or project (depending on kind which is "group" or "project").
+// If username is empty, the level of the caller is returned.
+func (s *Server) NotificationLevel(kind string, fullPath string, username string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := username + "|" + resourceKey(kind, fullPath)
+	if level, ok := s.notificationLevels[k]; ok {
+		return level.String()
+	}
+	return gitlab.GlobalNotificationLevel.String()
+}
+
+// Snippets returns the titles of the snippets of the project or, if
+// projectFullPath is empty, of the personal snippets of the caller.
+func (s *Server) Snippets(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	snippets := s.snippets
+	if projectFullPath != "" {
+		snippets = s.projectSnippets[resourceKey("project", projectFullPath)]
+	}
+	var result []string
+	for _, snippet := range snippets {
+		result = append(result, snippet.Title)
+	}
+	return result
+}
+
+// Avatar returns the content of the avatar of the group or project
+// (depending on kind) or "" if no avatar has been uploaded.
+func (s *Server) Avatar(kind string, fullPath string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.avatars[resourceKey(kind, fullPath)]
+}
+
+// SnippetContent returns the content of the snippet.
+func (s *Server) SnippetContent(id int) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.snippetContent[id]
+}
+
+// Variable returns the CI/CD variable of the project having the key
+// or nil if there is no such variable.
+func (s *Server) Variable(projectFullPath string, key string) *gitlab.ProjectVariable {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, v := range s.variables[resourceKey("project", projectFullPath)] {
+		if v.Key == key {
+			copy := *v
+			return &copy
+		}
+	}
+	return nil
+}
+
+// Variables returns the keys of the CI/CD variables of the group or
+// project (depending on kind which is "group" or "project").
+func (s *Server) Variables(kind string, fullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, v := range s.variables[resourceKey(kind, fullPath)] {
+		result = append(result, v.Key)
+	}
+	return result
+}
+
+// Labels returns the names of the labels of the group.
+func (s *Server) Labels(groupFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, l := range s.labels[resourceKey("group", groupFullPath)] {
+		result = append(result, l.Name)
+	}
+	return result
+}
+
+// ProjectLabels returns the names of the labels of the project.
+func (s *Server) ProjectLabels(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, l := range s.labels[resourceKey("project", projectFullPath)] {
+		result = append(result, l.Name)
+	}
+	return result
+}
+
+// Milestones returns the titles of the milestones of the project.
+func (s *Server) Milestones(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, m := range s.milestones[resourceKey("project", projectFullPath)] {
+		result = append(result, m.Title)
+	}
+	return result
+}
+
+// Boards returns a "name:label1,label2" string for each issue board
+// of the project listing the labels of the board lists in order.
+func (s *Server) Boards(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, b := range s.boards[resourceKey("project", projectFullPath)] {
+		var labels []string
+		for _, list := range b.Lists {
+			labels = append(labels, list.Label.Name)
+		}
+		result = append(result, b.Name+":"+strings.Join(labels, ","))
+	}
+	return result
+}
+
+// ApprovalRules returns the approval rules of the project.
+func (s *Server) ApprovalRules(projectFullPath string) []*gitlab.ProjectApprovalRule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return slices.Clone(s.approvalRules[resourceKey("project", projectFullPath)])
+}
+
+// File returns the content of the file in the repository of the
+// project.  The boolean return value is false if the file does not
+// exist.
+func (s *Server) File(projectFullPath string, path string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	content, ok := s.files[resourceKey("project", projectFullPath)][path]
+	return content, ok
+}
+
+// ProtectedBranches returns the names of the protected branches of
+// the project.
+func (s *Server) ProtectedBranches(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, b := range s.protectedBranches[resourceKey("project", projectFullPath)] {
+		result = append(result, b.Name)
+	}
+	return result
+}
+
+// ProtectedBranch returns a copy of the protected branch of the project
+// having the name or nil if there is no such protected branch.
+func (s *Server) ProtectedBranch(projectFullPath string, name string) *gitlab.ProtectedBranch {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, b := range s.protectedBranches[resourceKey("project", projectFullPath)] {
+		if b.Name == name {
+			copy := *b
+			return &copy
+		}
+	}
+	return nil
+}
+
+// Commits returns the number of commits created in the project
+// through the API.
+func (s *Server) Commits(projectFullPath string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.commits[resourceKey("project", projectFullPath)]
+}
+
+// Issues returns the titles of the issues of the project.
+func (s *Server) Issues(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, i := range s.issues[resourceKey("project", projectFullPath)] {
+		result = append(result, i.Title)
+	}
+	return result
+}
+
+// OpenIssues returns the titles of the open issues of the project.
+func (s *Server) OpenIssues(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, i := range s.issues[resourceKey("project", projectFullPath)] {
+		if i.State == "opened" {
+			result = append(result, i.Title)
+		}
+	}
+	return result
+}
+
+// IssueLabels returns the labels of the issue of the project having
+// the title.
+func (s *Server) IssueLabels(projectFullPath string, title string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, i := range s.issues[resourceKey("project", projectFullPath)] {
+		if i.Title == title {
+			return slices.Clone(i.Labels)
+		}
+	}
+	return nil
+}
+
+// MergeRequests returns a "source->target" string for each merge
+// request of the project.
+func (s *Server) MergeRequests(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, mr := range s.mergeRequests[resourceKey("project", projectFullPath)] {
+		result = append(result, mr.SourceBranch+"->"+mr.TargetBranch)
+	}
+	return result
+}
+
+// MergeRequestStates returns an "iid:state" string for each merge
+// request of the project where the state is "opened", "closed", or
+// "merged".  If the merge request is set to be merged when its
+// pipeline succeeds, the state is followed by "+auto".  If it was
+// approved through the API, the state is followed by "+approved".
+func (s *Server) MergeRequestStates(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, mr := range s.mergeRequests[resourceKey("project", projectFullPath)] {
+		state := fmt.Sprintf("%d:%s", mr.IID, mr.State)
+		if mr.MergeWhenPipelineSucceeds {
+			state += "+auto"
+		}
+		if s.mrApprovals[mr.ID] > 0 {
+			state += "+approved"
+		}
+		result = append(result, state)
+	}
+	return result
+}
+
+// AddPipeline adds a pipeline for the ref having the status (e.g.,
+// "failed") to the project.  Pipelines must be added from oldest to
+// newest.
+func (s *Server) AddPipeline(projectFullPath string, ref string, status string) *gitlab.Pipeline {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	k := resourceKey("project", projectFullPath)
+	pipeline := &gitlab.Pipeline{
+		ID:     s.nextID,
+		IID:    len(s.pipelines[k]) + 1,
+		Ref:    ref,
+		Status: status,
+	}
+	if p := s.findProject(projectFullPath); p != nil {
+		pipeline.ProjectID = p.ID
+	}
+	s.nextID++
+	s.pipelines[k] = append(s.pipelines[k], pipeline)
+	return pipeline
+}
+
+// Pipelines returns the pipelines of the project from oldest to newest
+// in the form "ref:status".  If the pipeline was created with
+// variables, the status is followed by the space-separated
+// "KEY=VALUE" variables.
+func (s *Server) Pipelines(projectFullPath string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, pipeline := range s.pipelines[resourceKey("project", projectFullPath)] {
+		result = append(result, strings.Join(append(
+			[]string{pipeline.Ref + ":" + pipeline.Status},
+			s.pipelineVariables[pipeline.ID]...), " "))
+	}
+	return result
+}
+
+// AddRunner adds a runner having the description and type (e.g.,
+// "project_type").  For group and project runners, owner is the full
+// path of the group or project the runner is assigned to.  For
+// instance runners, owner is ignored.
+func (s *Server) AddRunner(description string, runnerType string, owner string) *gitlab.Runner {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	runner := &gitlab.Runner{
+		ID:          s.nextID,
+		Description: description,
+		Active:      true,
+		IsShared:    runnerType == "instance_type",
+		RunnerType:  runnerType,
+		Online:      true,
+		Status:      "online",
+	}
+	s.nextID++
+	s.runners = append(s.runners, runner)
+	switch runnerType {
+	case "group_type":
+		s.runnerKeys[runner.ID] = []string{resourceKey("group", owner)}
+	case "project_type":
+		s.runnerKeys[runner.ID] = []string{resourceKey("project", owner)}
+	}
+	return runner
+}
+
+// Runner returns a copy of the runner having the ID or nil if there is
+// no such runner.
+func (s *Server) Runner(id int) *gitlab.Runner {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, runner := range s.runners {
+		if runner.ID == id {
+			copy := *runner
+			return &copy
+		}
+	}
+	return nil
+}
+
+// RunnerProjects returns the full paths of the projects the runner is
+// assigned to.
+func (s *Server) RunnerProjects(id int) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var result []string
+	for _, key := range s.runnerKeys[id] {
+		if fullPath, ok := strings.CutPrefix(key, "project:"); ok {
+			result = append(result, fullPath)
+		}
+	}
+	return result
+}
+
+// addMemberByUsername adds the user as a member of the resource.  The
+// caller must hold the mutex.
+func (s *Server) addMemberByUsername(key string, username string, level gitlab.AccessLevelValue) {
+	for _, u := range s.users {
+		if u.Username == username {
+			s.members[key] = append(s.members[key], &gitlab.GroupMember{
+				ID:          u.ID,
+				Username:    u.Username,
+				Name:        u.Name,
+				State:       u.State,
+				AccessLevel: level,
+			})
+			return
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////
+// Groups and Projects
+////////////////////////////////////////////////////////////////////////
+
+// createGroup handles "POST /groups".
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var opts gitlab.CreateGroupOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Path == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	fullPath := *opts.Path
+	if opts.ParentID != nil {
+		parent := s.findGroup(strconv.Itoa(*opts.ParentID))
+		if parent == nil {
+			writeError(w, http.StatusNotFound, "404 Parent Group Not Found")
+			return
+		}
+		fullPath = parent.FullPath + "/" + fullPath
+	}
+	if s.findGroup(fullPath) != nil {
+		writeError(w, http.StatusBadRequest, "400 Path has already been taken")
+		return
+	}
+	g := s.addGroup(fullPath)
+	if opts.Name != nil {
+		g.Name = *opts.Name
+	}
+	writeJSON(w, http.StatusCreated, g)
+}
+
+// deleteGroup handles "DELETE /groups/:id".  The subgroups and
+// projects of the group are deleted along with it.
+func (s *Server) deleteGroup(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	g := s.findGroup(r.PathValue("id"))
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+	inGroup := func(fullPath string) bool {
+		return fullPath == g.FullPath || strings.HasPrefix(fullPath, g.FullPath+"/")
+	}
+	s.projects = slices.DeleteFunc(s.projects, func(p *gitlab.Project) bool {
+		return inGroup(p.PathWithNamespace)
+	})
+	s.groups = slices.DeleteFunc(s.groups, func(sg *gitlab.Group) bool {
+		return inGroup(sg.FullPath)
+	})
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "202 Accepted"})
+}
+
+// listSubgroups handles "GET /groups/:id/subgroups".
+func (s *Server) listSubgroups(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	g := s.findGroup(r.PathValue("id"))
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Group Not Found")
+		return
+	}
+	var result []*gitlab.Group
+	for _, sg := range s.groups {
+		if sg.ParentID == g.ID {
+			result = append(result, sg)
+		}
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// getProject handles "GET /projects/:id" which also finds transferred
+// projects by their old path.
+func (s *Server) getProject(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p := s.findProject(r.PathValue("id"))
+	if id, ok := s.redirects[r.PathValue("id")]; ok && p == nil {
+		p = s.findProject(strconv.Itoa(id))
+	}
+	if p == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	result := *p
+	if r.URL.Query().Get("statistics") != "true" {
+		result.Statistics = nil
+	}
+	writeJSON(w, http.StatusOK, &result)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Members
+////////////////////////////////////////////////////////////////////////
+
+// listMembers handles "GET /groups/:id/members" and "GET
+// /projects/:id/members" which only return the direct members.
+func (s *Server) listMembers(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.members[key], s.PerPage)
+}
+
+// listAllMembers handles "GET /groups/:id/members/all" and "GET
+// /projects/:id/members/all".  The members of the ancestor groups are
+// included, and a user who is a member at several levels is returned
+// once with the highest access level.
+func (s *Server) listAllMembers(w http.ResponseWriter, r *http.Request, key string) {
+	_, fullPath, _ := strings.Cut(key, ":")
+	keys := []string{key}
+	for i := strings.LastIndex(fullPath, "/"); i >= 0; i = strings.LastIndex(fullPath, "/") {
+		fullPath = fullPath[:i]
+		keys = append(keys, resourceKey("group", fullPath))
+	}
+	var result []*gitlab.GroupMember
+	for _, k := range keys {
+		for _, m := range s.members[k] {
+			i := slices.IndexFunc(result, func(x *gitlab.GroupMember) bool {
+				return x.ID == m.ID
+			})
+			switch {
+			case i < 0:
+				result = append(result, m)
+			case m.AccessLevel > result[i].AccessLevel:
+				result[i] = m
+			}
+		}
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// addMember handles "POST /groups/:id/members" and "POST
+// /projects/:id/members".
+func (s *Server) addMember(w http.ResponseWriter, r *http.Request, key string) {
+	var opts struct {
+		UserID      int                     `json:"user_id"`
+		AccessLevel gitlab.AccessLevelValue `json:"access_level"`
+		ExpiresAt   *string                 `json:"expires_at"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	var expiresAt *gitlab.ISOTime
+	if opts.ExpiresAt != nil && *opts.ExpiresAt != "" {
+		t, err := time.Parse("2006-01-02", *opts.ExpiresAt)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "400 Bad Request")
+			return
+		}
+		expiresAt = gitlab.Ptr(gitlab.ISOTime(t))
+	}
+	for _, m := range s.members[key] {
+		if m.ID == opts.UserID {
+			writeError(w, http.StatusConflict, "Member already exists")
+			return
+		}
+	}
+	for _, u := range s.users {
+		if u.ID == opts.UserID {
+			s.addMemberByUsername(key, u.Username, opts.AccessLevel)
+			members := s.members[key]
+			members[len(members)-1].ExpiresAt = expiresAt
+			writeJSON(w, http.StatusCreated, members[len(members)-1])
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 User Not Found")
+}
+
+// editMember handles "PUT /groups/:id/members/:user_id" and "PUT
+// /projects/:id/members/:user_id".
+func (s *Server) editMember(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.EditGroupMemberOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	for _, m := range s.members[key] {
+		if strconv.Itoa(m.ID) != r.PathValue("user") {
+			continue
+		}
+		if opts.AccessLevel != nil {
+			m.AccessLevel = *opts.AccessLevel
+		}
+		if opts.ExpiresAt != nil {
+			m.ExpiresAt = nil
+			if *opts.ExpiresAt != "" {
+				t, err := time.Parse("2006-01-02", *opts.ExpiresAt)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, "400 Bad Request")
+					return
+				}
+				m.ExpiresAt = gitlab.Ptr(gitlab.ISOTime(t))
+			}
+		}
+		writeJSON(w, http.StatusOK, m)
+		return
+	}
+	writeError(w, http.StatusNotFound, "404 Member Not Found")
+}
+
+// removeMember handles "DELETE /groups/:id/members/:user_id" and
+// "DELETE /projects/:id/members/:user_id".
+func (s *Server) removeMember(w http.ResponseWriter, r *http.Request, key string) {
+	members := s.members[key]
+	i := slices.IndexFunc(members, func(m *gitlab.GroupMember) bool {
+		return strconv.Itoa(m.ID) == r.PathValue("user")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Member Not Found")
+		return
+	}
+	s.members[key] = slices.Delete(members, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Variables
+////////////////////////////////////////////////////////////////////////
+
+// listVariables handles "GET /groups/:id/variables" and "GET
+// /projects/:id/variables".
+func (s *Server) listVariables(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.variables[key], s.PerPage)
+}
+
+// createVariable handles "POST /groups/:id/variables" and "POST
+// /projects/:id/variables".
+func (s *Server) createVariable(w http.ResponseWriter, r *http.Request, key string) {
+	var v gitlab.ProjectVariable
+	err := json.NewDecoder(r.Body).Decode(&v)
+	if err != nil || v.Key == "" {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if v.EnvironmentScope == "" {
+		v.EnvironmentScope = "*"
+	}
+	for _, existing := range s.variables[key] {
+		if existing.Key == v.Key && existing.EnvironmentScope == v.EnvironmentScope {
+			writeError(w, http.StatusBadRequest, "(key) has already been taken")
+			return
+		}
+	}
+	s.variables[key] = append(s.variables[key], &v)
+	writeJSON(w, http.StatusCreated, &v)
+}
+
+// updateVariable handles "PUT /groups/:id/variables/:key" and "PUT
+// /projects/:id/variables/:key".  Group variables have no filter, so
+// they are matched by the environment scope in the request instead.
+func (s *Server) updateVariable(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.UpdateProjectVariableOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	scope := "*"
+	if opts.Filter != nil && opts.Filter.EnvironmentScope != "" {
+		scope = opts.Filter.EnvironmentScope
+	} else if opts.Filter == nil && opts.EnvironmentScope != nil {
+		scope = *opts.EnvironmentScope
+	}
+	for _, v := range s.variables[key] {
+		if v.Key != r.PathValue("key") || v.EnvironmentScope != scope {
+			continue
+		}
+		if opts.Value != nil {
+			v.Value = *opts.Value
+		}
+		if opts.Masked != nil {
+			v.Masked = *opts.Masked
+		}
+		if opts.Protected != nil {
+			v.Protected = *opts.Protected
+		}
+		writeJSON(w, http.StatusOK, v)
+		return
+	}
+	writeError(w, http.StatusNotFound, "404 Variable Not Found")
+}
+
+// deleteVariable handles "DELETE /groups/:id/variables/:key" and
+// "DELETE /projects/:id/variables/:key".  Without the
+// "filter[environment_scope]" parameter, the first variable having the
+// key is deleted.
+func (s *Server) deleteVariable(w http.ResponseWriter, r *http.Request, key string) {
+	scope := r.URL.Query().Get("filter[environment_scope]")
+	for i, v := range s.variables[key] {
+		if v.Key != r.PathValue("key") ||
+			(scope != "" && v.EnvironmentScope != scope) {
+			continue
+		}
+		s.variables[key] = slices.Delete(s.variables[key], i, i+1)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeError(w, http.StatusNotFound, "404 Variable Not Found")
+}
+
+////////////////////////////////////////////////////////////////////////
+// Labels
+////////////////////////////////////////////////////////////////////////
+
+// listLabels handles "GET /groups/:id/labels".
+func (s *Server) listLabels(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.labels[key], s.PerPage)
+}
+
+// createLabel handles "POST /groups/:id/labels".
+func (s *Server) createLabel(w http.ResponseWriter, r *http.Request, key string) {
+	var l gitlab.GroupLabel
+	err := json.NewDecoder(r.Body).Decode(&l)
+	if err != nil || l.Name == "" {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	for _, existing := range s.labels[key] {
+		if existing.Name == l.Name {
+			writeError(w, http.StatusConflict, "Label already exists")
+			return
+		}
+	}
+	l.ID = s.nextID
+	s.nextID++
+	s.labels[key] = append(s.labels[key], &l)
+	writeJSON(w, http.StatusCreated, &l)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Milestones
+////////////////////////////////////////////////////////////////////////
+
+// listMilestones handles "GET /projects/:id/milestones".
+func (s *Server) listMilestones(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.milestones[key], s.PerPage)
+}
+
+// createMilestone handles "POST /projects/:id/milestones".
+func (s *Server) createMilestone(w http.ResponseWriter, r *http.Request, key string) {
+	var m gitlab.Milestone
+	err := json.NewDecoder(r.Body).Decode(&m)
+	if err != nil || m.Title == "" {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	for _, existing := range s.milestones[key] {
+		if existing.Title == m.Title {
+			writeError(w, http.StatusBadRequest, "Title has already been taken")
+			return
+		}
+	}
+	m.ID = s.nextID
+	m.State = "active"
+	s.nextID++
+	s.milestones[key] = append(s.milestones[key], &m)
+	writeJSON(w, http.StatusCreated, &m)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Issue Boards
+////////////////////////////////////////////////////////////////////////
+
+// listBoards handles "GET /projects/:id/boards".
+func (s *Server) listBoards(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.boards[key], s.PerPage)
+}
+
+// createBoard handles "POST /projects/:id/boards".
+func (s *Server) createBoard(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateIssueBoardOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Name == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	board := &gitlab.IssueBoard{ID: s.nextID, Name: *opts.Name}
+	s.nextID++
+	s.boards[key] = append(s.boards[key], board)
+	writeJSON(w, http.StatusCreated, board)
+}
+
+// createBoardList handles "POST /projects/:id/boards/:board/lists".
+// Only label lists are supported.
+func (s *Server) createBoardList(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateIssueBoardListOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.LabelID == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	i := slices.IndexFunc(s.boards[key], func(b *gitlab.IssueBoard) bool {
+		return strconv.Itoa(b.ID) == r.PathValue("board")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Board Not Found")
+		return
+	}
+	board := s.boards[key][i]
+	for _, labels := range s.labels {
+		for _, l := range labels {
+			if l.ID == *opts.LabelID {
+				list := &gitlab.BoardList{
+					ID:       s.nextID,
+					Label:    (*gitlab.Label)(l),
+					Position: len(board.Lists),
+				}
+				s.nextID++
+				board.Lists = append(board.Lists, list)
+				writeJSON(w, http.StatusCreated, list)
+				return
+			}
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Label Not Found")
+}
+
+////////////////////////////////////////////////////////////////////////
+// Approval Rules
+////////////////////////////////////////////////////////////////////////
+
+// listApprovalRules handles "GET /projects/:id/approval_rules".
+func (s *Server) listApprovalRules(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.approvalRules[key], s.PerPage)
+}
+
+// createApprovalRule handles "POST /projects/:id/approval_rules".
+func (s *Server) createApprovalRule(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateProjectLevelRuleOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Name == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	rule := &gitlab.ProjectApprovalRule{
+		ID:       s.nextID,
+		Name:     *opts.Name,
+		RuleType: "regular",
+	}
+	s.nextID++
+	if opts.ApprovalsRequired != nil {
+		rule.ApprovalsRequired = *opts.ApprovalsRequired
+	}
+	if opts.UserIDs != nil {
+		for _, u := range s.users {
+			if slices.Contains(*opts.UserIDs, u.ID) {
+				rule.Users = append(rule.Users,
+					&gitlab.BasicUser{ID: u.ID, Username: u.Username})
+			}
+		}
+	}
+	if opts.GroupIDs != nil {
+		for _, g := range s.groups {
+			if slices.Contains(*opts.GroupIDs, g.ID) {
+				rule.Groups = append(rule.Groups, g)
+			}
+		}
+	}
+	rule.EligibleApprovers = rule.Users
+	s.approvalRules[key] = append(s.approvalRules[key], rule)
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// updateApprovalRule handles "PUT /projects/:id/approval_rules/:rule".
+// Only the name, the number of approvals, and the users are modeled.
+func (s *Server) updateApprovalRule(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.approvalRules[key], func(rule *gitlab.ProjectApprovalRule) bool {
+		return strconv.Itoa(rule.ID) == r.PathValue("rule")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	var opts gitlab.UpdateProjectLevelRuleOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	rule := s.approvalRules[key][i]
+	if opts.Name != nil {
+		rule.Name = *opts.Name
+	}
+	if opts.ApprovalsRequired != nil {
+		rule.ApprovalsRequired = *opts.ApprovalsRequired
+	}
+	if opts.UserIDs != nil {
+		rule.Users = nil
+		for _, u := range s.users {
+			if slices.Contains(*opts.UserIDs, u.ID) {
+				rule.Users = append(rule.Users,
+					&gitlab.BasicUser{ID: u.ID, Username: u.Username})
+			}
+		}
+		rule.EligibleApprovers = rule.Users
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Branches
+////////////////////////////////////////////////////////////////////////
+
+// listBranches handles "GET /projects/:id/repository/branches".  Like
+// Gitlab, a branch is reported as protected if a protected branch has
+// the same name, and the default branch of the project is reported as
+// the default.
+func (s *Server) listBranches(w http.ResponseWriter, r *http.Request, key string) {
+	var defaultBranch string
+	if p := s.findProject(strings.TrimPrefix(key, "project:")); p != nil {
+		defaultBranch = p.DefaultBranch
+	}
+	var bs []*gitlab.Branch
+	for _, b := range s.branches[key] {
+		c := *b
+		c.Default = c.Name == defaultBranch
+		c.Protected = slices.ContainsFunc(s.protectedBranches[key],
+			func(pb *gitlab.ProtectedBranch) bool { return pb.Name == c.Name })
+		bs = append(bs, &c)
+	}
+	writePage(w, r, bs, s.PerPage)
+}
+
+// deleteBranch handles
+// "DELETE /projects/:id/repository/branches/:name".
+func (s *Server) deleteBranch(w http.ResponseWriter, r *http.Request, key string) {
+	for i, b := range s.branches[key] {
+		if b.Name == r.PathValue("name") {
+			s.branches[key] = slices.Delete(s.branches[key], i, i+1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Branch Not Found")
+}
+
+////////////////////////////////////////////////////////////////////////
+// Protected Branches
+////////////////////////////////////////////////////////////////////////
+
+// listProtectedBranches handles "GET /projects/:id/protected_branches".
+func (s *Server) listProtectedBranches(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.protectedBranches[key], s.PerPage)
+}
+
+// protectBranch handles "POST /projects/:id/protected_branches".
+func (s *Server) protectBranch(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.ProtectRepositoryBranchesOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Name == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	for _, b := range s.protectedBranches[key] {
+		if b.Name == *opts.Name {
+			writeError(w, http.StatusConflict,
+				"Protected branch '"+b.Name+"' already exists")
+			return
+		}
+	}
+	b := &gitlab.ProtectedBranch{ID: s.nextID, Name: *opts.Name}
+	s.nextID++
+	if opts.PushAccessLevel != nil {
+		b.PushAccessLevels = s.addBranchAccess(nil, *opts.PushAccessLevel)
+	}
+	if opts.MergeAccessLevel != nil {
+		b.MergeAccessLevels = s.addBranchAccess(nil, *opts.MergeAccessLevel)
+	}
+	if opts.AllowForcePush != nil {
+		b.AllowForcePush = *opts.AllowForcePush
+	}
+	if opts.CodeOwnerApprovalRequired != nil {
+		b.CodeOwnerApprovalRequired = *opts.CodeOwnerApprovalRequired
+	}
+	s.protectedBranches[key] = append(s.protectedBranches[key], b)
+	writeJSON(w, http.StatusCreated, b)
+}
+
+// updateProtectedBranch handles
+// "PATCH /projects/:id/protected_branches/:name".
+func (s *Server) updateProtectedBranch(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.UpdateProtectedBranchOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	for _, b := range s.protectedBranches[key] {
+		if b.Name != r.PathValue("name") {
+			continue
+		}
+		if opts.AllowForcePush != nil {
+			b.AllowForcePush = *opts.AllowForcePush
+		}
+		if opts.CodeOwnerApprovalRequired != nil {
+			b.CodeOwnerApprovalRequired = *opts.CodeOwnerApprovalRequired
+		}
+		if opts.AllowedToPush != nil {
+			b.PushAccessLevels = s.changeBranchAccess(
+				b.PushAccessLevels, *opts.AllowedToPush)
+		}
+		if opts.AllowedToMerge != nil {
+			b.MergeAccessLevels = s.changeBranchAccess(
+				b.MergeAccessLevels, *opts.AllowedToMerge)
+		}
+		writeJSON(w, http.StatusOK, b)
+		return
+	}
+	writeError(w, http.StatusNotFound, "404 Not found")
+}
+
+// unprotectBranch handles
+// "DELETE /projects/:id/protected_branches/:name".
+func (s *Server) unprotectBranch(w http.ResponseWriter, r *http.Request, key string) {
+	for i, b := range s.protectedBranches[key] {
+		if b.Name == r.PathValue("name") {
+			s.protectedBranches[key] = slices.Delete(s.protectedBranches[key], i, i+1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Not found")
+}
+
+// addBranchAccess returns ds with a new role-based access description
+// for the access level appended.
+func (s *Server) addBranchAccess(
+	ds []*gitlab.BranchAccessDescription,
+	level gitlab.AccessLevelValue,
+) []*gitlab.BranchAccessDescription {
+	d := &gitlab.BranchAccessDescription{ID: s.nextID, AccessLevel: level}
+	s.nextID++
+	return append(ds, d)
+}
+
+// changeBranchAccess returns ds after the changes in opts are applied.
+// Descriptions whose ID is marked for destruction are removed, and
+// descriptions for new access levels are added.
+func (s *Server) changeBranchAccess(
+	ds []*gitlab.BranchAccessDescription,
+	opts []*gitlab.BranchPermissionOptions,
+) []*gitlab.BranchAccessDescription {
+	for _, opt := range opts {
+		switch {
+		case opt.Destroy != nil && *opt.Destroy && opt.ID != nil:
+			ds = slices.DeleteFunc(ds, func(d *gitlab.BranchAccessDescription) bool {
+				return d.ID == *opt.ID
+			})
+		case opt.AccessLevel != nil:
+			ds = s.addBranchAccess(ds, *opt.AccessLevel)
+		}
+	}
+	return ds
+}
+
+////////////////////////////////////////////////////////////////////////
+// Repository Files and Commits
+////////////////////////////////////////////////////////////////////////
+
+// getFile handles "GET /projects/:id/repository/files/:path".
+func (s *Server) getFile(w http.ResponseWriter, r *http.Request, key string) {
+	path := r.PathValue("path")
+	content, ok := s.files[key][path]
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 File Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &gitlab.File{
+		FileName: filepath.Base(path),
+		FilePath: path,
+		Size:     len(content),
+		Encoding: "base64",
+		Content:  base64.StdEncoding.EncodeToString([]byte(content)),
+		Ref:      r.URL.Query().Get("ref"),
+	})
+}
+
+// createCommit handles "POST /projects/:id/repository/commits".  Only
+// the create and update actions are supported.  The commit is
+// rejected without changing anything if any action is invalid.
+func (s *Server) createCommit(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateCommitOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Branch == nil || opts.CommitMessage == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	files := maps.Clone(s.files[key])
+	if files == nil {
+		files = make(map[string]string)
+	}
+	for _, a := range opts.Actions {
+		if a.Action == nil || a.FilePath == nil || a.Content == nil {
+			writeError(w, http.StatusBadRequest, "400 Bad Request")
+			return
+		}
+		_, exists := files[*a.FilePath]
+		switch *a.Action {
+		case gitlab.FileCreate:
+			if exists {
+				writeError(w, http.StatusBadRequest,
+					"A file with this name already exists")
+				return
+			}
+		case gitlab.FileUpdate:
+			if !exists {
+				writeError(w, http.StatusBadRequest,
+					"A file with this name doesn't exist")
+				return
+			}
+		default:
+			writeError(w, http.StatusBadRequest, "400 Bad Request")
+			return
+		}
+		content := *a.Content
+		if a.Encoding != nil && *a.Encoding == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "400 Bad Request")
+				return
+			}
+			content = string(decoded)
+		}
+		files[*a.FilePath] = content
+	}
+	s.files[key] = files
+	s.commits[key]++
+	writeJSON(w, http.StatusCreated, &gitlab.Commit{
+		ID:      strconv.Itoa(s.nextID),
+		Title:   *opts.CommitMessage,
+		Message: *opts.CommitMessage,
+	})
+	s.nextID++
+}
+
+////////////////////////////////////////////////////////////////////////
+// Issues and Merge Requests
+////////////////////////////////////////////////////////////////////////
+
+// createIssue handles "POST /projects/:id/issues".
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateIssueOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Title == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	now := time.Now()
+	issue := &gitlab.Issue{
+		ID:        s.nextID,
+		IID:       len(s.issues[key]) + 1,
+		Title:     *opts.Title,
+		State:     "opened",
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}
+	if opts.Description != nil {
+		issue.Description = *opts.Description
+	}
+	if opts.Labels != nil {
+		issue.Labels = gitlab.Labels(*opts.Labels)
+	}
+	s.nextID++
+	s.issues[key] = append(s.issues[key], issue)
+
+	// Creating an issue is activity in the project.
+	_, fullPath, _ := strings.Cut(key, ":")
+	s.findProject(fullPath).LastActivityAt = &now
+	writeJSON(w, http.StatusCreated, issue)
+}
+
+// listIssues handles "GET /projects/:id/issues".  Only the "state",
+// "labels", "author_username", and "updated_before" filters are
+// modeled.
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request, key string) {
+	query := r.URL.Query()
+	state := query.Get("state")
+	var labels []string
+	if l := query.Get("labels"); l != "" {
+		labels = strings.Split(l, ",")
+	}
+	author := query.Get("author_username")
+	var updatedBefore time.Time
+	if v := query.Get("updated_before"); v != "" {
+		updatedBefore, _ = time.Parse(time.RFC3339, v)
+	}
+	var result []*gitlab.Issue
+	for _, issue := range s.issues[key] {
+		if state != "" && state != "all" && issue.State != state {
+			continue
+		}
+		if author != "" && (issue.Author == nil || issue.Author.Username != author) {
+			continue
+		}
+		if !updatedBefore.IsZero() && !issue.UpdatedAt.Before(updatedBefore) {
+			continue
+		}
+		if slices.ContainsFunc(labels, func(l string) bool {
+			return !slices.Contains(issue.Labels, l)
+		}) {
+			continue
+		}
+		result = append(result, issue)
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// updateIssue handles "PUT /projects/:id/issues/:iid".  Only closing
+// and reopening issues and adding and removing labels is modeled.
+func (s *Server) updateIssue(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.issues[key], func(issue *gitlab.Issue) bool {
+		return strconv.Itoa(issue.IID) == r.PathValue("iid")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	var opts gitlab.UpdateIssueOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	issue := s.issues[key][i]
+	if opts.StateEvent != nil {
+		switch *opts.StateEvent {
+		case "close":
+			issue.State = "closed"
+		case "reopen":
+			issue.State = "opened"
+		}
+	}
+	if opts.AddLabels != nil {
+		for _, l := range *opts.AddLabels {
+			if !slices.Contains(issue.Labels, l) {
+				issue.Labels = append(issue.Labels, l)
+			}
+		}
+	}
+	if opts.RemoveLabels != nil {
+		issue.Labels = slices.DeleteFunc(issue.Labels, func(l string) bool {
+			return slices.Contains(*opts.RemoveLabels, l)
+		})
+	}
+	writeJSON(w, http.StatusOK, issue)
+}
+
+// moveIssue handles "POST /projects/:id/issues/:iid/move".  The
+// original issue is closed, and a copy that was just updated is
+// opened in the target project.
+func (s *Server) moveIssue(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.issues[key], func(issue *gitlab.Issue) bool {
+		return strconv.Itoa(issue.IID) == r.PathValue("iid")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	var opts gitlab.MoveIssueOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.ToProjectID == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	to := s.findProject(strconv.Itoa(*opts.ToProjectID))
+	if to == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	toKey := resourceKey("project", to.PathWithNamespace)
+	if toKey == key {
+		writeError(w, http.StatusBadRequest,
+			"Cannot move issue to project it originates from!")
+		return
+	}
+	issue := s.issues[key][i]
+	moved := *issue
+	moved.ID = s.nextID
+	moved.IID = len(s.issues[toKey]) + 1
+	moved.Labels = slices.Clone(issue.Labels)
+	now := time.Now()
+	moved.UpdatedAt = &now
+	s.nextID++
+	s.issues[toKey] = append(s.issues[toKey], &moved)
+	issue.State = "closed"
+	writeJSON(w, http.StatusCreated, &moved)
+}
+
+// listMergeRequests handles "GET /projects/:id/merge_requests".  Only
+// the "state", "author_username", "labels", "target_branch", and
+// "updated_after" filters are modeled.
+func (s *Server) listMergeRequests(w http.ResponseWriter, r *http.Request, key string) {
+	query := r.URL.Query()
+	result := []*gitlab.MergeRequest{}
+	updatedAfter, _ := time.Parse(time.RFC3339, query.Get("updated_after"))
+	var labels []string
+	if query.Get("labels") != "" {
+		labels = strings.Split(query.Get("labels"), ",")
+	}
+	for _, mr := range s.mergeRequests[key] {
+		if mr.UpdatedAt != nil && !mr.UpdatedAt.After(updatedAfter) {
+			continue
+		}
+		if state := query.Get("state"); state != "" && state != "all" && state != mr.State {
+			continue
+		}
+		if author := query.Get("author_username"); author != "" &&
+			(mr.Author == nil || mr.Author.Username != author) {
+			continue
+		}
+		if branch := query.Get("target_branch"); branch != "" && branch != mr.TargetBranch {
+			continue
+		}
+		if slices.ContainsFunc(labels, func(l string) bool {
+			return !slices.Contains(mr.Labels, l)
+		}) {
+			continue
+		}
+		result = append(result, mr)
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// findMergeRequest returns the merge request of the project having
+// the IID in the request path or nil if there is no such merge
+// request.
+func (s *Server) findMergeRequest(r *http.Request, key string) *gitlab.MergeRequest {
+	i := slices.IndexFunc(s.mergeRequests[key], func(mr *gitlab.MergeRequest) bool {
+		return strconv.Itoa(mr.IID) == r.PathValue("iid")
+	})
+	if i < 0 {
+		return nil
+	}
+	return s.mergeRequests[key][i]
+}
+
+// updateMergeRequest handles "PUT /projects/:id/merge_requests/:iid".
+// Only closing and reopening are modeled.
+func (s *Server) updateMergeRequest(w http.ResponseWriter, r *http.Request, key string) {
+	mr := s.findMergeRequest(r, key)
+	if mr == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	var opts gitlab.UpdateMergeRequestOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if opts.StateEvent != nil && mr.State != "merged" {
+		switch *opts.StateEvent {
+		case "close":
+			mr.State = "closed"
+		case "reopen":
+			mr.State = "opened"
+		}
+	}
+	writeJSON(w, http.StatusOK, mr)
+}
+
+// acceptMergeRequest handles "PUT
+// /projects/:id/merge_requests/:iid/merge".  Like Gitlab, it responds
+// with 405 Method Not Allowed if the merge request is not opened and
+// with 409 Conflict if the SHA does not match the head of the merge
+// request.  Pipelines are not modeled, so a merge request set to be
+// merged when its pipeline succeeds is never merged.
+func (s *Server) acceptMergeRequest(w http.ResponseWriter, r *http.Request, key string) {
+	mr := s.findMergeRequest(r, key)
+	if mr == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	var opts gitlab.AcceptMergeRequestOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if mr.State != "opened" {
+		writeError(w, http.StatusMethodNotAllowed, "405 Method Not Allowed")
+		return
+	}
+	if opts.SHA != nil && *opts.SHA != mr.SHA {
+		writeError(w, http.StatusConflict, "SHA does not match HEAD of source branch")
+		return
+	}
+	if opts.MergeWhenPipelineSucceeds != nil && *opts.MergeWhenPipelineSucceeds {
+		mr.MergeWhenPipelineSucceeds = true
+	} else {
+		now := time.Now()
+		mr.State = "merged"
+		mr.MergedAt = &now
+		mr.UpdatedAt = &now
+	}
+	writeJSON(w, http.StatusOK, mr)
+}
+
+// approveMergeRequest handles "POST
+// /projects/:id/merge_requests/:iid/approve".  Like Gitlab, it
+// responds with 409 Conflict if the SHA does not match the head of the
+// merge request.
+func (s *Server) approveMergeRequest(w http.ResponseWriter, r *http.Request, key string) {
+	mr := s.findMergeRequest(r, key)
+	if mr == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	var opts gitlab.ApproveMergeRequestOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if opts.SHA != nil && *opts.SHA != mr.SHA {
+		writeError(w, http.StatusConflict, "SHA does not match HEAD of source branch")
+		return
+	}
+	s.mrApprovals[mr.ID]++
+	writeJSON(w, http.StatusCreated, &gitlab.MergeRequestApprovals{
+		ID:       mr.ID,
+		IID:      mr.IID,
+		Approved: true,
+	})
+}
+
+// listMergeRequestNotes handles "GET
+// /projects/:id/merge_requests/:iid/notes".  The notes are always
+// returned from oldest to newest.
+func (s *Server) listMergeRequestNotes(w http.ResponseWriter, r *http.Request, key string) {
+	mr := s.findMergeRequest(r, key)
+	if mr == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	writePage(w, r, s.notes[mr.ID], s.PerPage)
+}
+
+// listEvents handles "GET /projects/:id/events".  Only push events are
+// modeled.  The "after" and "before" dates are exclusive.
+func (s *Server) listEvents(w http.ResponseWriter, r *http.Request, key string) {
+	query := r.URL.Query()
+	if action := query.Get("action"); action != "" && action != "pushed" {
+		writePage(w, r, []*gitlab.ProjectEvent{}, s.PerPage)
+		return
+	}
+	var result []*gitlab.ProjectEvent
+	for _, e := range s.events[key] {
+		day := e.CreatedAt[:len("2006-01-02")]
+		if after := query.Get("after"); after != "" && day <= after {
+			continue
+		}
+		if before := query.Get("before"); before != "" && day >= before {
+			continue
+		}
+		result = append(result, e)
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// listHooks handles "GET /projects/:id/hooks".
+func (s *Server) listHooks(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.hooks[key], s.PerPage)
+}
+
+// addHook handles "POST /projects/:id/hooks".  Like Gitlab, the URL
+// is required.
+func (s *Server) addHook(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.AddProjectHookOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.URL == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	h := &gitlab.ProjectHook{ID: s.nextID, EnableSSLVerification: true}
+	if p := s.findProject(strings.TrimPrefix(key, "project:")); p != nil {
+		h.ProjectID = p.ID
+	}
+	s.nextID++
+	editOpts := gitlab.EditProjectHookOptions(opts)
+	s.applyHookOptions(h, &editOpts)
+	s.hooks[key] = append(s.hooks[key], h)
+	writeJSON(w, http.StatusCreated, h)
+}
+
+// editHook handles "PUT /projects/:id/hooks/:hook_id".  The URL,
+// secret token, event flags, and SSL verification are modeled.  Like
+// Gitlab, the URL is required.
+func (s *Server) editHook(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.EditProjectHookOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.URL == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	i := slices.IndexFunc(s.hooks[key], func(h *gitlab.ProjectHook) bool {
+		return strconv.Itoa(h.ID) == r.PathValue("hook")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	h := s.hooks[key][i]
+	s.applyHookOptions(h, &opts)
+	writeJSON(w, http.StatusOK, h)
+}
+
+// deleteHook handles "DELETE /projects/:id/hooks/:hook_id".
+func (s *Server) deleteHook(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.hooks[key], func(h *gitlab.ProjectHook) bool {
+		return strconv.Itoa(h.ID) == r.PathValue("hook")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	delete(s.hookTokens, s.hooks[key][i].ID)
+	s.hooks[key] = slices.Delete(s.hooks[key], i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyHookOptions applies the options that are set to the webhook.
+// The caller must hold the mutex.
+func (s *Server) applyHookOptions(h *gitlab.ProjectHook, opts *gitlab.EditProjectHookOptions) {
+	set := func(field *bool, value *bool) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	h.URL = *opts.URL
+	if opts.Token != nil {
+		s.hookTokens[h.ID] = *opts.Token
+	}
+	set(&h.ConfidentialIssuesEvents, opts.ConfidentialIssuesEvents)
+	set(&h.ConfidentialNoteEvents, opts.ConfidentialNoteEvents)
+	set(&h.DeploymentEvents, opts.DeploymentEvents)
+	set(&h.EnableSSLVerification, opts.EnableSSLVerification)
+	set(&h.IssuesEvents, opts.IssuesEvents)
+	set(&h.JobEvents, opts.JobEvents)
+	set(&h.MergeRequestsEvents, opts.MergeRequestsEvents)
+	set(&h.NoteEvents, opts.NoteEvents)
+	set(&h.PipelineEvents, opts.PipelineEvents)
+	set(&h.PushEvents, opts.PushEvents)
+	set(&h.ReleasesEvents, opts.ReleasesEvents)
+	set(&h.TagPushEvents, opts.TagPushEvents)
+	set(&h.WikiPageEvents, opts.WikiPageEvents)
+}
+
+// testHook handles "POST /projects/:id/hooks/:hook_id/test/:trigger".
+// Like Gitlab, it responds with 422 Unprocessable Entity if the
+// endpoint of the webhook does not respond with a 2xx status code.
+func (s *Server) testHook(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.hooks[key], func(h *gitlab.ProjectHook) bool {
+		return strconv.Itoa(h.ID) == r.PathValue("hook")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	status := s.hookStatus[s.hooks[key][i].ID]
+	if status < 200 || status > 299 {
+		writeError(w, http.StatusUnprocessableEntity,
+			strconv.Itoa(status)+" "+http.StatusText(status))
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]string{"message": "201 Created"})
+}
+
+// createMergeRequest handles "POST /projects/:id/merge_requests".
+// Branches are not modeled, so any source and target branch is
+// accepted as long as they differ.
+func (s *Server) createMergeRequest(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateMergeRequestOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Title == nil || opts.SourceBranch == nil ||
+		opts.TargetBranch == nil || *opts.SourceBranch == *opts.TargetBranch {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	mr := &gitlab.MergeRequest{
+		ID:           s.nextID,
+		IID:          len(s.mergeRequests[key]) + 1,
+		Title:        *opts.Title,
+		SourceBranch: *opts.SourceBranch,
+		TargetBranch: *opts.TargetBranch,
+		State:        "opened",
+	}
+	s.nextID++
+	s.mergeRequests[key] = append(s.mergeRequests[key], mr)
+	writeJSON(w, http.StatusCreated, mr)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Pipelines
+////////////////////////////////////////////////////////////////////////
+
+// pipelineInfo returns the summary of the pipeline that Gitlab returns
+// when listing pipelines.
+func pipelineInfo(pipeline *gitlab.Pipeline) *gitlab.PipelineInfo {
+	return &gitlab.PipelineInfo{
+		ID:        pipeline.ID,
+		IID:       pipeline.IID,
+		ProjectID: pipeline.ProjectID,
+		Status:    pipeline.Status,
+		Ref:       pipeline.Ref,
+	}
+}
+
+// listPipelines handles "GET /projects/:id/pipelines".  Like Gitlab,
+// the pipelines are returned from newest to oldest unless "sort" is
+// "asc".  Only filtering by "ref", "status", and the "running",
+// "pending", and "finished" scopes is modeled.
+func (s *Server) listPipelines(w http.ResponseWriter, r *http.Request, key string) {
+	query := r.URL.Query()
+	result := []*gitlab.PipelineInfo{}
+	for _, pipeline := range s.pipelines[key] {
+		if ref := query.Get("ref"); ref != "" && ref != pipeline.Ref {
+			continue
+		}
+		if status := query.Get("status"); status != "" && status != pipeline.Status {
+			continue
+		}
+		switch query.Get("scope") {
+		case "running", "pending":
+			if query.Get("scope") != pipeline.Status {
+				continue
+			}
+		case "finished":
+			if !slices.Contains([]string{"success", "failed", "canceled", "skipped"},
+				pipeline.Status) {
+				continue
+			}
+		}
+		result = append(result, pipelineInfo(pipeline))
+	}
+	if query.Get("sort") != "asc" {
+		slices.Reverse(result)
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// findPipeline returns the pipeline of the project having the ID in
+// the request path or nil if there is no such pipeline.
+func (s *Server) findPipeline(r *http.Request, key string) *gitlab.Pipeline {
+	i := slices.IndexFunc(s.pipelines[key], func(pipeline *gitlab.Pipeline) bool {
+		return strconv.Itoa(pipeline.ID) == r.PathValue("pipeline")
+	})
+	if i < 0 {
+		return nil
+	}
+	return s.pipelines[key][i]
+}
+
+// createPipeline handles "POST /projects/:id/pipeline".  Branches are
+// not modeled, so any non-empty ref is accepted.  The new pipeline is
+// pending.
+func (s *Server) createPipeline(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreatePipelineOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Ref == nil || *opts.Ref == "" {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	pipeline := &gitlab.Pipeline{
+		ID:     s.nextID,
+		IID:    len(s.pipelines[key]) + 1,
+		Ref:    *opts.Ref,
+		Status: "pending",
+	}
+	_, fullPath, _ := strings.Cut(key, ":")
+	if p := s.findProject(fullPath); p != nil {
+		pipeline.ProjectID = p.ID
+	}
+	s.nextID++
+	if opts.Variables != nil {
+		for _, v := range *opts.Variables {
+			if v.Key != nil && v.Value != nil {
+				s.pipelineVariables[pipeline.ID] = append(
+					s.pipelineVariables[pipeline.ID], *v.Key+"="+*v.Value)
+			}
+		}
+	}
+	s.pipelines[key] = append(s.pipelines[key], pipeline)
+	writeJSON(w, http.StatusCreated, pipeline)
+}
+
+// retryPipeline handles "POST /projects/:id/pipelines/:pipeline/retry".
+// Jobs are not modeled, so retrying a failed or canceled pipeline just
+// makes it running.  Like Gitlab, it responds with 403 Forbidden if
+// the pipeline has nothing to retry.
+func (s *Server) retryPipeline(w http.ResponseWriter, r *http.Request, key string) {
+	pipeline := s.findPipeline(r, key)
+	if pipeline == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	if pipeline.Status != "failed" && pipeline.Status != "canceled" {
+		writeError(w, http.StatusForbidden, "403 Forbidden")
+		return
+	}
+	pipeline.Status = "running"
+	writeJSON(w, http.StatusCreated, pipeline)
+}
+
+// cancelPipeline handles "POST
+// /projects/:id/pipelines/:pipeline/cancel".  Pipelines that have
+// already finished are returned unchanged like Gitlab does.
+func (s *Server) cancelPipeline(w http.ResponseWriter, r *http.Request, key string) {
+	pipeline := s.findPipeline(r, key)
+	if pipeline == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	if slices.Contains([]string{"created", "pending", "running"}, pipeline.Status) {
+		pipeline.Status = "canceled"
+	}
+	writeJSON(w, http.StatusOK, pipeline)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Mirrors
+////////////////////////////////////////////////////////////////////////
+
+// editProject handles "PUT /projects/:id".  Only the general settings,
+// the topics, and configuring the project as a pull mirror are
+// modeled.
+func (s *Server) editProject(w http.ResponseWriter, r *http.Request, key string) {
+	_, fullPath, _ := strings.Cut(key, ":")
+	p := s.findProject(fullPath)
+	if isMultipart(r) {
+		url, ok := s.uploadAvatar(w, r, key)
+		if ok {
+			p.AvatarURL = url
+			writeJSON(w, http.StatusOK, p)
+		}
+		return
+	}
+	var opts gitlab.EditProjectOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if opts.Name != nil {
+		p.Name = *opts.Name
+	}
+	if opts.DefaultBranch != nil {
+		p.DefaultBranch = *opts.DefaultBranch
+	}
+	if opts.Description != nil {
+		p.Description = *opts.Description
+	}
+	if opts.Visibility != nil {
+		p.Visibility = *opts.Visibility
+	}
+	if opts.OnlyAllowMergeIfAllDiscussionsAreResolved != nil {
+		p.OnlyAllowMergeIfAllDiscussionsAreResolved =
+			*opts.OnlyAllowMergeIfAllDiscussionsAreResolved
+	}
+	if opts.OnlyAllowMergeIfPipelineSucceeds != nil {
+		p.OnlyAllowMergeIfPipelineSucceeds = *opts.OnlyAllowMergeIfPipelineSucceeds
+	}
+	if opts.RemoveSourceBranchAfterMerge != nil {
+		p.RemoveSourceBranchAfterMerge = *opts.RemoveSourceBranchAfterMerge
+	}
+	if opts.ImportURL != nil {
+		p.ImportURL = *opts.ImportURL
+	}
+	if opts.Mirror != nil {
+		p.Mirror = *opts.Mirror
+	}
+	if opts.OnlyMirrorProtectedBranches != nil {
+		p.OnlyMirrorProtectedBranches = *opts.OnlyMirrorProtectedBranches
+	}
+	if opts.Topics != nil {
+		p.Topics = *opts.Topics
+	}
+	if p.Mirror {
+		m := s.pullMirrors[key]
+		if m == nil || m.URL != p.ImportURL {
+			m = &gitlab.ProjectPullMirrorDetails{
+				ID:           p.ID,
+				URL:          p.ImportURL,
+				UpdateStatus: "scheduled",
+			}
+			s.pullMirrors[key] = m
+		}
+	} else {
+		delete(s.pullMirrors, key)
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// getPullMirror handles "GET /projects/:id/mirror/pull".
+func (s *Server) getPullMirror(w http.ResponseWriter, r *http.Request, key string) {
+	m := s.pullMirrors[key]
+	if m == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// listPushMirrors handles "GET /projects/:id/remote_mirrors".
+func (s *Server) listPushMirrors(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.pushMirrors[key], s.PerPage)
+}
+
+// addPushMirror handles "POST /projects/:id/remote_mirrors".
+func (s *Server) addPushMirror(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.AddProjectMirrorOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.URL == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	m := &gitlab.ProjectMirror{
+		ID:           s.nextID,
+		URL:          *opts.URL,
+		UpdateStatus: "none",
+	}
+	if opts.Enabled != nil {
+		m.Enabled = *opts.Enabled
+	}
+	if opts.OnlyProtectedBranches != nil {
+		m.OnlyProtectedBranches = *opts.OnlyProtectedBranches
+	}
+	s.nextID++
+	s.pushMirrors[key] = append(s.pushMirrors[key], m)
+	writeJSON(w, http.StatusCreated, m)
+}
+
+// editPushMirror handles "PUT /projects/:id/remote_mirrors/:mirror_id".
+func (s *Server) editPushMirror(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.EditProjectMirrorOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	i := slices.IndexFunc(s.pushMirrors[key], func(m *gitlab.ProjectMirror) bool {
+		return strconv.Itoa(m.ID) == r.PathValue("mirror")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	m := s.pushMirrors[key][i]
+	if opts.Enabled != nil {
+		m.Enabled = *opts.Enabled
+	}
+	if opts.OnlyProtectedBranches != nil {
+		m.OnlyProtectedBranches = *opts.OnlyProtectedBranches
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Integrations
+////////////////////////////////////////////////////////////////////////
+
+// isIntegrationSecret returns true if Gitlab never returns the value
+// of the setting of an integration.
+func isIntegrationSecret(name string) bool {
+	return strings.Contains(name, "password") || strings.Contains(name, "token")
+}
+
+// getIntegration handles "GET /projects/:id/integrations/:slug".  The
+// event flags and "active" are returned as top-level fields, and the
+// other settings are returned as properties except for secrets.
+func (s *Server) getIntegration(w http.ResponseWriter, r *http.Request, key string) {
+	settings := s.integrations[key][r.PathValue("slug")]
+	if settings == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	properties := make(map[string]any)
+	integration := map[string]any{
+		"id":         1,
+		"slug":       r.PathValue("slug"),
+		"properties": properties,
+	}
+	for name, value := range settings {
+		switch {
+		case isIntegrationSecret(name):
+			// Gitlab never returns secrets.
+		case name == "active" || strings.HasSuffix(name, "_events"):
+			integration[name] = value == "true"
+		default:
+			properties[name] = value
+		}
+	}
+	writeJSON(w, http.StatusOK, integration)
+}
+
+// setIntegration handles "PUT /projects/:id/integrations/:slug".
+// Settings that are not given keep their current value.
+func (s *Server) setIntegration(w http.ResponseWriter, r *http.Request, key string) {
+	var body map[string]any
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if s.integrations[key] == nil {
+		s.integrations[key] = make(map[string]map[string]string)
+	}
+	slug := r.PathValue("slug")
+	settings := s.integrations[key][slug]
+	if settings == nil {
+		settings = make(map[string]string)
+		s.integrations[key][slug] = settings
+	}
+	for name, value := range body {
+		settings[name] = fmt.Sprint(value)
+	}
+	settings["active"] = "true"
+	writeJSON(w, http.StatusOK, map[string]any{"slug": slug, "active": true})
+}
+
+////////////////////////////////////////////////////////////////////////
+// Notification Settings
+////////////////////////////////////////////////////////////////////////
+
+// notificationKey returns the key in notificationLevels for the user
+// named by the "Sudo" header (or the caller if there is no such
+// header) and the resource key.  If the user does not exist, false is
+// returned.
+func (s *Server) notificationKey(r *http.Request, key string) (string, bool) {
+	username := r.Header.Get("Sudo")
+	if username != "" && !slices.ContainsFunc(s.users, func(u *gitlab.User) bool {
+		return u.Username == username
+	}) {
+		return "", false
+	}
+	return username + "|" + key, true
+}
+
+// getNotificationSettings handles "GET /groups/:id/notification_settings"
+// and "GET /projects/:id/notification_settings".  The level defaults
+// to "global".
+func (s *Server) getNotificationSettings(w http.ResponseWriter, r *http.Request, key string) {
+	k, ok := s.notificationKey(r, key)
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 User Not Found")
+		return
+	}
+	level, ok := s.notificationLevels[k]
+	if !ok {
+		level = gitlab.GlobalNotificationLevel
+	}
+	writeJSON(w, http.StatusOK, &gitlab.NotificationSettings{Level: level})
+}
+
+// updateNotificationSettings handles "PUT
+// /groups/:id/notification_settings" and "PUT
+// /projects/:id/notification_settings".  Only the level is modeled.
+func (s *Server) updateNotificationSettings(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.NotificationSettingsOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Level == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	k, ok := s.notificationKey(r, key)
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 User Not Found")
+		return
+	}
+	s.notificationLevels[k] = *opts.Level
+	writeJSON(w, http.StatusOK, &gitlab.NotificationSettings{Level: *opts.Level})
+}
+
+////////////////////////////////////////////////////////////////////////
+// Snippets
+////////////////////////////////////////////////////////////////////////
+
+// newSnippet returns a new snippet having a single file with the name
+// and content.  The caller must hold the mutex.
+func (s *Server) newSnippet(title, fileName, visibility, content string) *gitlab.Snippet {
+	snippet := &gitlab.Snippet{
+		ID:         s.nextID,
+		Title:      title,
+		FileName:   fileName,
+		Visibility: visibility,
+		WebURL:     s.URL + "/-/snippets/" + strconv.Itoa(s.nextID),
+	}
+	snippet.Files = append(snippet.Files, struct {
+		Path   string `json:"path"`
+		RawURL string `json:"raw_url"`
+	}{Path: fileName})
+	s.nextID++
+	s.snippetContent[snippet.ID] = content
+	return snippet
+}
+
+// decodeSnippet decodes the options of a new snippet from the request
+// body and returns the new snippet.  Only snippets having a single
+// file are modeled.  The caller must hold the mutex.
+func (s *Server) decodeSnippet(r *http.Request) *gitlab.Snippet {
+	var opts gitlab.CreateSnippetOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Title == nil || opts.Files == nil || len(*opts.Files) != 1 {
+		return nil
+	}
+	file := (*opts.Files)[0]
+	if file.FilePath == nil || file.Content == nil {
+		return nil
+	}
+	visibility := "private"
+	if opts.Visibility != nil {
+		visibility = string(*opts.Visibility)
+	}
+	return s.newSnippet(*opts.Title, *file.FilePath, visibility, *file.Content)
+}
+
+// removeSnippet removes the snippet having the ID from the snippets
+// and returns the remaining snippets or nil and false if there is no
+// such snippet.  The caller must hold the mutex.
+func (s *Server) removeSnippet(snippets []*gitlab.Snippet, id string) ([]*gitlab.Snippet, bool) {
+	i := slices.IndexFunc(snippets, func(snippet *gitlab.Snippet) bool {
+		return strconv.Itoa(snippet.ID) == id
+	})
+	if i < 0 {
+		return nil, false
+	}
+	delete(s.snippetContent, snippets[i].ID)
+	return slices.Delete(snippets, i, i+1), true
+}
+
+// listSnippets handles "GET /snippets".
+func (s *Server) listSnippets(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	writePage(w, r, s.snippets, s.PerPage)
+}
+
+// createSnippet handles "POST /snippets".
+func (s *Server) createSnippet(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	snippet := s.decodeSnippet(r)
+	if snippet == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	s.snippets = append(s.snippets, snippet)
+	writeJSON(w, http.StatusCreated, snippet)
+}
+
+// deleteSnippet handles "DELETE /snippets/:id".
+func (s *Server) deleteSnippet(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	snippets, ok := s.removeSnippet(s.snippets, r.PathValue("snippet"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 Snippet Not Found")
+		return
+	}
+	s.snippets = snippets
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listProjectSnippets handles "GET /projects/:id/snippets".
+func (s *Server) listProjectSnippets(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.projectSnippets[key], s.PerPage)
+}
+
+// createProjectSnippet handles "POST /projects/:id/snippets".
+func (s *Server) createProjectSnippet(w http.ResponseWriter, r *http.Request, key string) {
+	snippet := s.decodeSnippet(r)
+	if snippet == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	s.projectSnippets[key] = append(s.projectSnippets[key], snippet)
+	writeJSON(w, http.StatusCreated, snippet)
+}
+
+// deleteProjectSnippet handles "DELETE /projects/:id/snippets/:snippet_id".
+func (s *Server) deleteProjectSnippet(w http.ResponseWriter, r *http.Request, key string) {
+	snippets, ok := s.removeSnippet(s.projectSnippets[key], r.PathValue("snippet"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "404 Snippet Not Found")
+		return
+	}
+	s.projectSnippets[key] = snippets
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getProjectSnippetContent handles "GET
+// /projects/:id/snippets/:snippet_id/raw".
+func (s *Server) getProjectSnippetContent(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.projectSnippets[key], func(snippet *gitlab.Snippet) bool {
+		return strconv.Itoa(snippet.ID) == r.PathValue("snippet")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Snippet Not Found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, s.snippetContent[s.projectSnippets[key][i].ID])
+}
+
+////////////////////////////////////////////////////////////////////////
+// Archiving
+////////////////////////////////////////////////////////////////////////
+
+// archiveProject handles "POST /projects/:id/archive".
+func (s *Server) archiveProject(w http.ResponseWriter, r *http.Request, key string) {
+	_, fullPath, _ := strings.Cut(key, ":")
+	p := s.findProject(fullPath)
+	p.Archived = true
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// unarchiveProject handles "POST /projects/:id/unarchive".
+func (s *Server) unarchiveProject(w http.ResponseWriter, r *http.Request, key string) {
+	_, fullPath, _ := strings.Cut(key, ":")
+	p := s.findProject(fullPath)
+	p.Archived = false
+	writeJSON(w, http.StatusCreated, p)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Transfers
+////////////////////////////////////////////////////////////////////////
+
+// transferProject handles "PUT /projects/:id/transfer".  The old path
+// of the project redirects to the project afterwards.  Note that the
+// resources of the project (which are keyed by its full path) are not
+// moved with it.
+func (s *Server) transferProject(w http.ResponseWriter, r *http.Request, key string) {
+	_, fullPath, _ := strings.Cut(key, ":")
+	p := s.findProject(fullPath)
+	var opts struct {
+		Namespace any `json:"namespace"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	g := s.findGroup(fmt.Sprint(opts.Namespace))
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+	if s.findProject(g.FullPath+"/"+p.Path) != nil {
+		writeError(w, http.StatusBadRequest, "400 Project already exists")
+		return
+	}
+	s.redirects[p.PathWithNamespace] = p.ID
+	p.PathWithNamespace = g.FullPath + "/" + p.Path
+	p.NameWithNamespace = g.FullPath + "/" + p.Path
+	p.Namespace = &gitlab.ProjectNamespace{
+		ID:       g.ID,
+		Name:     g.Name,
+		Path:     g.Path,
+		Kind:     "group",
+		FullPath: g.FullPath,
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Avatars
+////////////////////////////////////////////////////////////////////////
+
+// isMultipart returns true if the body of the request is a multipart
+// form which is how avatars are uploaded.
+func isMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// uploadAvatar stores the "avatar" file of the multipart form for the
+// group or project and returns its URL.  If the form does not have the
+// file, it writes an error response and returns false.
+func (s *Server) uploadAvatar(w http.ResponseWriter, r *http.Request, key string) (string, bool) {
+	f, header, err := r.FormFile("avatar")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return "", false
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return "", false
+	}
+	s.avatars[key] = string(content)
+	kind, fullPath, _ := strings.Cut(key, ":")
+	return fmt.Sprintf("%s/uploads/-/system/%s/avatar/%s/%s",
+		s.URL, kind, fullPath, header.Filename), true
+}
+
+// editGroup handles "PUT /groups/:id" which is only modeled for
+// uploading avatars.
+func (s *Server) editGroup(w http.ResponseWriter, r *http.Request, key string) {
+	if !isMultipart(r) {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	url, ok := s.uploadAvatar(w, r, key)
+	if !ok {
+		return
+	}
+	_, fullPath, _ := strings.Cut(key, ":")
+	g := s.findGroup(fullPath)
+	g.AvatarURL = url
+	writeJSON(w, http.StatusOK, g)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Search
+////////////////////////////////////////////////////////////////////////
+
+// searchInstance handles "GET /search".
+func (s *Server) searchInstance(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.search(w, r, "")
+}
+
+// searchGroup handles "GET /groups/:id/-/search".
+func (s *Server) searchGroup(w http.ResponseWriter, r *http.Request, key string) {
+	_, fullPath, _ := strings.Cut(key, ":")
+	s.search(w, r, fullPath+"/")
+}
+
+// search searches the projects whose full paths start with the prefix
+// for the "search" query parameter in the "scope" query parameter.
+// Projects match by name or path, issues and merge requests by title,
+// and blobs by content.  Commits are not modeled and never match.  The
+// caller must hold the mutex.
+func (s *Server) search(w http.ResponseWriter, r *http.Request, prefix string) {
+	query := r.URL.Query().Get("search")
+	var projects []*gitlab.Project
+	for _, p := range s.projects {
+		if strings.HasPrefix(p.PathWithNamespace, prefix) {
+			projects = append(projects, p)
+		}
+	}
+	switch r.URL.Query().Get("scope") {
+	case "projects":
+		var result []*gitlab.Project
+		for _, p := range projects {
+			if strings.Contains(p.Name, query) || strings.Contains(p.Path, query) {
+				result = append(result, p)
+			}
+		}
+		writePage(w, r, result, s.PerPage)
+	case "issues":
+		var result []*gitlab.Issue
+		for _, p := range projects {
+			for _, issue := range s.issues[resourceKey("project", p.PathWithNamespace)] {
+				if strings.Contains(issue.Title, query) {
+					hit := *issue
+					hit.ProjectID = p.ID
+					result = append(result, &hit)
+				}
+			}
+		}
+		writePage(w, r, result, s.PerPage)
+	case "merge_requests":
+		var result []*gitlab.MergeRequest
+		for _, p := range projects {
+			for _, mr := range s.mergeRequests[resourceKey("project", p.PathWithNamespace)] {
+				if strings.Contains(mr.Title, query) {
+					hit := *mr
+					hit.ProjectID = p.ID
+					result = append(result, &hit)
+				}
+			}
+		}
+		writePage(w, r, result, s.PerPage)
+	case "blobs":
+		var result []*gitlab.Blob
+		for _, p := range projects {
+			files := s.files[resourceKey("project", p.PathWithNamespace)]
+			var paths []string
+			for path := range files {
+				paths = append(paths, path)
+			}
+			slices.Sort(paths)
+			for _, path := range paths {
+				lines := strings.Split(files[path], "\n")
+				i := slices.IndexFunc(lines, func(line string) bool {
+					return strings.Contains(line, query)
+				})
+				if i >= 0 {
+					result = append(result, &gitlab.Blob{
+						Basename:  strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+						Data:      lines[i],
+						Path:      path,
+						Filename:  path,
+						Ref:       p.DefaultBranch,
+						Startline: i + 1,
+						ProjectID: p.ID,
+					})
+				}
+			}
+		}
+		writePage(w, r, result, s.PerPage)
+	case "commits":
+		writePage(w, r, []*gitlab.Commit{}, s.PerPage)
+	default:
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+	}
+}
+
+////////////////////////////////////////////////////////////////////////
+// Import and Export
+////////////////////////////////////////////////////////////////////////
+
+// exportPrefix is the prefix of the fake export archive which is
+// followed by the full path of the exported project.
+const exportPrefix = "fake-export:"
+
+// scheduleExport handles "POST /projects/:id/export".
+func (s *Server) scheduleExport(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.findProject(r.PathValue("id")) == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"message": "202 Accepted"})
+}
+
+// exportStatus handles "GET /projects/:id/export".  Exports finish
+// immediately.
+func (s *Server) exportStatus(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p := s.findProject(r.PathValue("id"))
+	if p == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, gitlab.ExportStatus{
+		ID:                p.ID,
+		Path:              p.Path,
+		PathWithNamespace: p.PathWithNamespace,
+		ExportStatus:      "finished",
+	})
+}
+
+// exportDownload handles "GET /projects/:id/export/download".
+func (s *Server) exportDownload(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p := s.findProject(r.PathValue("id"))
+	if p == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	io.WriteString(w, exportPrefix+p.PathWithNamespace)
+}
+
+// importProject handles "POST /projects/import".  The project is
+// created immediately.
+func (s *Server) importProject(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Parse the multipart form.
+	err := r.ParseMultipartForm(1 << 20)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	defer f.Close()
+	archive, err := io.ReadAll(f)
+	if err != nil || !strings.HasPrefix(string(archive), exportPrefix) {
+		writeError(w, http.StatusBadRequest, "400 Invalid Archive")
+		return
+	}
+
+	// Create the project.
+	g := s.findGroup(r.FormValue("namespace"))
+	if g == nil {
+		writeError(w, http.StatusNotFound, "404 Namespace Not Found")
+		return
+	}
+	path := r.FormValue("path")
+	if s.findProject(g.FullPath+"/"+path) != nil {
+		writeError(w, http.StatusBadRequest, "400 Path has already been taken")
+		return
+	}
+	p := s.addProject(g, path)
+	if name := r.FormValue("name"); name != "" {
+		p.Name = name
+	}
+	p.ImportStatus = "finished"
+	writeJSON(w, http.StatusCreated, gitlab.ImportStatus{
+		ID:                p.ID,
+		Path:              p.Path,
+		PathWithNamespace: p.PathWithNamespace,
+		ImportStatus:      "scheduled",
+	})
+}
+
+// importStatus handles "GET /projects/:id/import".  Imports finish
+// immediately unless the status was set by SetImportStatus().  The
+// status of projects that were not imported is "none".
+func (s *Server) importStatus(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	p := s.findProject(r.PathValue("id"))
+	if p == nil {
+		writeError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+	status := p.ImportStatus
+	if status == "" {
+		status = "none"
+	}
+	writeJSON(w, http.StatusOK, gitlab.ImportStatus{
+		ID:                p.ID,
+		Path:              p.Path,
+		PathWithNamespace: p.PathWithNamespace,
+		ImportStatus:      status,
+	})
+}
+
+////////////////////////////////////////////////////////////////////////
+// Users
+////////////////////////////////////////////////////////////////////////
+
+// listUserMemberships handles "GET /users/:id/memberships".
+func (s *Server) listUserMemberships(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := r.PathValue("id")
+	var keys []string
+	for key := range s.members {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var result []*gitlab.UserMembership
+	for _, key := range keys {
+		for _, m := range s.members[key] {
+			if strconv.Itoa(m.ID) != id {
+				continue
+			}
+			kind, fullPath, _ := strings.Cut(key, ":")
+			membership := &gitlab.UserMembership{
+				SourceName:  fullPath,
+				AccessLevel: m.AccessLevel,
+			}
+			if kind == "group" {
+				membership.SourceType = "Namespace"
+				if g := s.findGroup(fullPath); g != nil {
+					membership.SourceID = g.ID
+				}
+			} else {
+				membership.SourceType = "Project"
+				if p := s.findProject(fullPath); p != nil {
+					membership.SourceID = p.ID
+				}
+			}
+			result = append(result, membership)
+		}
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+// listPersonalAccessTokens handles "GET /personal_access_tokens".
+func (s *Server) listPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	userID := r.URL.Query().Get("user_id")
+	var result []*gitlab.PersonalAccessToken
+	for _, t := range s.tokens {
+		if userID == "" || strconv.Itoa(t.UserID) == userID {
+			result = append(result, t)
+		}
+	}
+	writePage(w, r, result, s.PerPage)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Runners
+////////////////////////////////////////////////////////////////////////
+
+// filterRunners returns the runners in rs that match the "type",
+// "status", and "paused" query parameters of the request.
+func filterRunners(r *http.Request, rs []*gitlab.Runner) []*gitlab.Runner {
+	query := r.URL.Query()
+	result := []*gitlab.Runner{}
+	for _, runner := range rs {
+		if t := query.Get("type"); t != "" && runner.RunnerType != t {
+			continue
+		}
+		if status := query.Get("status"); status != "" && runner.Status != status {
+			continue
+		}
+		if paused := query.Get("paused"); paused != "" &&
+			strconv.FormatBool(runner.Paused) != paused {
+			continue
+		}
+		result = append(result, runner)
+	}
+	return result
+}
+
+// findRunner returns the runner having the ID or nil if there is no
+// such runner.  The caller must hold the mutex.
+func (s *Server) findRunner(id string) *gitlab.Runner {
+	for _, runner := range s.runners {
+		if strconv.Itoa(runner.ID) == id {
+			return runner
+		}
+	}
+	return nil
+}
+
+// listAllRunners handles "GET /runners/all".
+func (s *Server) listAllRunners(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	writePage(w, r, filterRunners(r, s.runners), s.PerPage)
+}
+
+// listRunners handles "GET /groups/:id/runners" and
+// "GET /projects/:id/runners".
+func (s *Server) listRunners(w http.ResponseWriter, r *http.Request, key string) {
+	var rs []*gitlab.Runner
+	for _, runner := range s.runners {
+		if slices.Contains(s.runnerKeys[runner.ID], key) {
+			rs = append(rs, runner)
+		}
+	}
+	writePage(w, r, filterRunners(r, rs), s.PerPage)
+}
+
+// enableProjectRunner handles "POST /projects/:id/runners".
+func (s *Server) enableProjectRunner(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.EnableProjectRunnerOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	runner := s.findRunner(strconv.Itoa(opts.RunnerID))
+	if runner == nil {
+		writeError(w, http.StatusNotFound, "404 Runner Not Found")
+		return
+	}
+	if slices.Contains(s.runnerKeys[runner.ID], key) {
+		writeError(w, http.StatusBadRequest, "Runner has already been taken")
+		return
+	}
+	s.runnerKeys[runner.ID] = append(s.runnerKeys[runner.ID], key)
+	writeJSON(w, http.StatusCreated, runner)
+}
+
+// updateRunner handles "PUT /runners/:id".
+func (s *Server) updateRunner(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	runner := s.findRunner(r.PathValue("runner"))
+	if runner == nil {
+		writeError(w, http.StatusNotFound, "404 Runner Not Found")
+		return
+	}
+	var opts gitlab.UpdateRunnerDetailsOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if opts.Description != nil {
+		runner.Description = *opts.Description
+	}
+	if opts.Paused != nil {
+		runner.Paused = *opts.Paused
+		runner.Active = !*opts.Paused
+	}
+	writeJSON(w, http.StatusOK, runner)
+}
+
+// removeRunner handles "DELETE /runners/:id".
+func (s *Server) removeRunner(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	runner := s.findRunner(r.PathValue("runner"))
+	if runner == nil {
+		writeError(w, http.StatusNotFound, "404 Runner Not Found")
+		return
+	}
+	s.runners = slices.DeleteFunc(s.runners, func(x *gitlab.Runner) bool {
+		return x == runner
+	})
+	delete(s.runnerKeys, runner.ID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Releases
+////////////////////////////////////////////////////////////////////////
+
+// listReleases handles "GET /projects/:id/releases".
+func (s *Server) listReleases(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.releases[key], s.PerPage)
+}
+
+// createRelease handles "POST /projects/:id/releases".  The tag,
+// name, description, and asset links are modeled.  Like Gitlab, the
+// tag is required, and there can only be one release per tag.
+func (s *Server) createRelease(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateReleaseOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.TagName == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if slices.ContainsFunc(s.releases[key], func(x *gitlab.Release) bool {
+		return x.TagName == *opts.TagName
+	}) {
+		writeError(w, http.StatusConflict, "Release already exists")
+		return
+	}
+	release := &gitlab.Release{TagName: *opts.TagName, Name: *opts.TagName}
+	if opts.Name != nil {
+		release.Name = *opts.Name
+	}
+	if opts.Description != nil {
+		release.Description = *opts.Description
+	}
+	if opts.Assets != nil {
+		for _, link := range opts.Assets.Links {
+			l := &gitlab.ReleaseLink{ID: s.nextID}
+			s.nextID++
+			if link.Name != nil {
+				l.Name = *link.Name
+			}
+			if link.URL != nil {
+				l.URL = *link.URL
+			}
+			release.Assets.Links = append(release.Assets.Links, l)
+		}
+	}
+	s.releases[key] = append(s.releases[key], release)
+	writeJSON(w, http.StatusCreated, release)
+}
+
+// deleteRelease handles "DELETE /projects/:id/releases/:tag_name".
+func (s *Server) deleteRelease(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.releases[key], func(x *gitlab.Release) bool {
+		return x.TagName == r.PathValue("tag")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	release := s.releases[key][i]
+	s.releases[key] = slices.Delete(s.releases[key], i, i+1)
+	writeJSON(w, http.StatusOK, release)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Tags
+////////////////////////////////////////////////////////////////////////
+
+// newTag returns a new tag having the name whose commit has a unique
+// ID.
+func (s *Server) newTag(name string) *gitlab.Tag {
+	id := fmt.Sprintf("%040d", s.nextID)
+	s.nextID++
+	return &gitlab.Tag{
+		Name:   name,
+		Commit: &gitlab.Commit{ID: id, ShortID: id[len(id)-8:]},
+	}
+}
+
+// isProtectedTag returns whether the tag matches the name or wildcard
+// of one of the protected tags of the project.
+func (s *Server) isProtectedTag(key string, tag string) bool {
+	return slices.ContainsFunc(s.protectedTags[key], func(pt *gitlab.ProtectedTag) bool {
+		matched, _ := filepath.Match(pt.Name, tag)
+		return matched
+	})
+}
+
+// listTags handles "GET /projects/:id/repository/tags".  Like Gitlab,
+// a tag is reported as protected if it matches a protected tag.
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request, key string) {
+	var ts []*gitlab.Tag
+	for _, t := range s.tags[key] {
+		c := *t
+		c.Protected = s.isProtectedTag(key, c.Name)
+		ts = append(ts, &c)
+	}
+	writePage(w, r, ts, s.PerPage)
+}
+
+// getTag handles "GET /projects/:id/repository/tags/:tag_name".
+func (s *Server) getTag(w http.ResponseWriter, r *http.Request, key string) {
+	for _, t := range s.tags[key] {
+		if t.Name == r.PathValue("tag") {
+			c := *t
+			c.Protected = s.isProtectedTag(key, c.Name)
+			writeJSON(w, http.StatusOK, &c)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Tag Not Found")
+}
+
+// createTag handles "POST /projects/:id/repository/tags".  Like
+// Gitlab, the name and ref are required, and the name must be unique.
+// The ref is not resolved, so every tag gets a new commit ID.
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateTagOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.TagName == nil || opts.Ref == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if slices.ContainsFunc(s.tags[key], func(x *gitlab.Tag) bool {
+		return x.Name == *opts.TagName
+	}) {
+		writeError(w, http.StatusBadRequest, "Tag "+*opts.TagName+" already exists")
+		return
+	}
+	t := s.newTag(*opts.TagName)
+	if opts.Message != nil {
+		t.Message = *opts.Message
+	}
+	s.tags[key] = append(s.tags[key], t)
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// deleteTag handles "DELETE /projects/:id/repository/tags/:tag_name".
+func (s *Server) deleteTag(w http.ResponseWriter, r *http.Request, key string) {
+	for i, t := range s.tags[key] {
+		if t.Name == r.PathValue("tag") {
+			s.tags[key] = slices.Delete(s.tags[key], i, i+1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Tag Not Found")
+}
+
+////////////////////////////////////////////////////////////////////////
+// Protected Tags
+////////////////////////////////////////////////////////////////////////
+
+// addTagAccess returns the access descriptions that allow the access
+// level to create a protected tag.
+func (s *Server) addTagAccess(level gitlab.AccessLevelValue) []*gitlab.TagAccessDescription {
+	d := &gitlab.TagAccessDescription{ID: s.nextID, AccessLevel: level}
+	s.nextID++
+	return []*gitlab.TagAccessDescription{d}
+}
+
+// listProtectedTags handles "GET /projects/:id/protected_tags".
+func (s *Server) listProtectedTags(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.protectedTags[key], s.PerPage)
+}
+
+// protectTag handles "POST /projects/:id/protected_tags".  Like Gitlab,
+// the create access level defaults to maintainer, and a tag can only
+// be protected once.
+func (s *Server) protectTag(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.ProtectRepositoryTagsOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Name == nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	if slices.ContainsFunc(s.protectedTags[key], func(x *gitlab.ProtectedTag) bool {
+		return x.Name == *opts.Name
+	}) {
+		writeError(w, http.StatusConflict,
+			"Protected tag '"+*opts.Name+"' already exists")
+		return
+	}
+	level := gitlab.MaintainerPermissions
+	if opts.CreateAccessLevel != nil {
+		level = *opts.CreateAccessLevel
+	}
+	t := &gitlab.ProtectedTag{
+		Name:               *opts.Name,
+		CreateAccessLevels: s.addTagAccess(level),
+	}
+	s.protectedTags[key] = append(s.protectedTags[key], t)
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// unprotectTag handles "DELETE /projects/:id/protected_tags/:name".
+func (s *Server) unprotectTag(w http.ResponseWriter, r *http.Request, key string) {
+	for i, t := range s.protectedTags[key] {
+		if t.Name == r.PathValue("name") {
+			s.protectedTags[key] = slices.Delete(s.protectedTags[key], i, i+1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "404 Not Found")
+}
+
+////////////////////////////////////////////////////////////////////////
+// Deploy Tokens and Access Tokens
+////////////////////////////////////////////////////////////////////////
+
+// listDeployTokens handles "GET /groups/:id/deploy_tokens" and "GET
+// /projects/:id/deploy_tokens".
+func (s *Server) listDeployTokens(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.deployTokens[key], s.PerPage)
+}
+
+// createDeployToken handles "POST /groups/:id/deploy_tokens" and "POST
+// /projects/:id/deploy_tokens".  Like Gitlab, the secret is only
+// returned when the token is created.
+func (s *Server) createDeployToken(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateProjectDeployTokenOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Name == nil || opts.Scopes == nil || len(*opts.Scopes) == 0 {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	t := &gitlab.DeployToken{
+		ID:        s.nextID,
+		Name:      *opts.Name,
+		Username:  fmt.Sprintf("gitlab+deploy-token-%d", s.nextID),
+		Scopes:    *opts.Scopes,
+		ExpiresAt: opts.ExpiresAt,
+	}
+	if opts.Username != nil {
+		t.Username = *opts.Username
+	}
+	s.nextID++
+	s.deployTokens[key] = append(s.deployTokens[key], t)
+	created := *t
+	created.Token = fmt.Sprintf("gldt-secret-%d", t.ID)
+	writeJSON(w, http.StatusCreated, &created)
+}
+
+// deleteDeployToken handles "DELETE /groups/:id/deploy_tokens/:token"
+// and "DELETE /projects/:id/deploy_tokens/:token".
+func (s *Server) deleteDeployToken(w http.ResponseWriter, r *http.Request, key string) {
+	i := slices.IndexFunc(s.deployTokens[key], func(t *gitlab.DeployToken) bool {
+		return strconv.Itoa(t.ID) == r.PathValue("token")
+	})
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	s.deployTokens[key] = slices.Delete(s.deployTokens[key], i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listAccessTokens handles "GET /groups/:id/access_tokens" and "GET
+// /projects/:id/access_tokens".
+func (s *Server) listAccessTokens(w http.ResponseWriter, r *http.Request, key string) {
+	writePage(w, r, s.accessTokens[key], s.PerPage)
+}
+
+// newAccessToken adds a new access token to the group or project
+// having the resource key and returns a copy of it with its secret.
+// Like Gitlab, the token expires in a week if expires is nil.
+func (s *Server) newAccessToken(
+	key string,
+	name string,
+	scopes []string,
+	level gitlab.AccessLevelValue,
+	expires *gitlab.ISOTime,
+) *gitlab.ProjectAccessToken {
+	if expires == nil {
+		expires = gitlab.Ptr(gitlab.ISOTime(time.Now().AddDate(0, 0, 7)))
+	}
+	t := &gitlab.ProjectAccessToken{
+		ID:          s.nextID,
+		Name:        name,
+		Scopes:      scopes,
+		AccessLevel: level,
+		ExpiresAt:   expires,
+		Active:      true,
+	}
+	s.nextID++
+	s.accessTokens[key] = append(s.accessTokens[key], t)
+	created := *t
+	created.Token = fmt.Sprintf("glpat-secret-%d", t.ID)
+	return &created
+}
+
+// createAccessToken handles "POST /groups/:id/access_tokens" and
+// "POST /projects/:id/access_tokens".
+func (s *Server) createAccessToken(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.CreateProjectAccessTokenOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil || opts.Name == nil || opts.Scopes == nil || len(*opts.Scopes) == 0 {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	level := gitlab.MaintainerPermissions
+	if opts.AccessLevel != nil {
+		level = *opts.AccessLevel
+	}
+	writeJSON(w, http.StatusCreated,
+		s.newAccessToken(key, *opts.Name, *opts.Scopes, level, opts.ExpiresAt))
+}
+
+// findActiveAccessToken returns the active access token of the group
+// or project having the resource key with the ID in the "token" path
+// value or nil if there is no such token.
+func (s *Server) findActiveAccessToken(r *http.Request, key string) *gitlab.ProjectAccessToken {
+	for _, t := range s.accessTokens[key] {
+		if strconv.Itoa(t.ID) == r.PathValue("token") && t.Active {
+			return t
+		}
+	}
+	return nil
+}
+
+// rotateAccessToken handles "POST /groups/:id/access_tokens/:token/rotate"
+// and "POST /projects/:id/access_tokens/:token/rotate".  Like Gitlab,
+// the old token is revoked and a new token with the same settings is
+// returned.
+func (s *Server) rotateAccessToken(w http.ResponseWriter, r *http.Request, key string) {
+	var opts gitlab.RotateProjectAccessTokenOptions
+	err := json.NewDecoder(r.Body).Decode(&opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "400 Bad Request")
+		return
+	}
+	t := s.findActiveAccessToken(r, key)
+	if t == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	t.Active = false
+	t.Revoked = true
+	writeJSON(w, http.StatusOK,
+		s.newAccessToken(key, t.Name, t.Scopes, t.AccessLevel, opts.ExpiresAt))
+}
+
+// revokeAccessToken handles "DELETE /groups/:id/access_tokens/:token"
+// and "DELETE /projects/:id/access_tokens/:token".
+func (s *Server) revokeAccessToken(w http.ResponseWriter, r *http.Request, key string) {
+	t := s.findActiveAccessToken(r, key)
+	if t == nil {
+		writeError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	t.Active = false
+	t.Revoked = true
+	w.WriteHeader(http.StatusNoContent)
+}
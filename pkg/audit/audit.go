@@ -0,0 +1,486 @@
+// This file provides the audit log which records each change the
+// program makes through the Gitlab REST API in an append-only JSON
+// Lines file so it can later be determined who changed what and when.
+//
+// The log is written by an http.RoundTripper so every command is
+// covered without having to change the commands themselves.  Each
+// request other than GET, HEAD, or OPTIONS is recorded after its
+// response arrives.  Dry runs send no such requests so they are not
+// recorded.  GraphQL requests are always sent with POST, so they are
+// only recorded if they contain a mutation (e.g., those sent by the
+// "graphql" command) in which case the name of the operation and its
+// redacted variables are recorded.
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/user"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// apiPrefix is the prefix of the path of every Gitlab REST endpoint.
+const apiPrefix = "/api/v4"
+
+// graphQLPath is the path of the Gitlab GraphQL endpoint.
+const graphQLPath = "/api/graphql"
+
+// mutationRegexp matches a GraphQL request that contains a mutation.
+var mutationRegexp = regexp.MustCompile(`\bmutation\b`)
+
+// operationRegexp matches the start of a GraphQL mutation capturing
+// its name (if any) and the name of its first field.
+var operationRegexp = regexp.MustCompile(
+	`\bmutation\s*(\w*)[^{]*\{\s*(?:\w+\s*:\s*)?(\w+)`)
+
+// entityKeys are the keys of the JSON objects returned by Gitlab whose
+// values name the entity in Record.Entity in order of preference.
+var entityKeys = []string{
+	"path_with_namespace", "full_path", "username", "name", "title", "key",
+}
+
+// redactedValue replaces the values of fields that hold secrets.
+const redactedValue = "REDACTED"
+
+////////////////////////////////////////////////////////////////////////
+// Record
+////////////////////////////////////////////////////////////////////////
+
+// Record is a single entry in the audit log.
+type Record struct {
+
+	// Time is when the request was sent.
+	Time time.Time `json:"time"`
+
+	// Actor is the username of the Gitlab user who owns the token or
+	// "" if it cannot be determined.
+	Actor string `json:"actor"`
+
+	// User is the name of the local user who ran the program.
+	User string `json:"user"`
+
+	// Method is the HTTP method of the request (e.g., "DELETE").
+	Method string `json:"method"`
+
+	// Endpoint is the path of the request relative to "/api/v4"
+	// (e.g., "/projects/foo%2Falpha") or "/api/graphql" for a GraphQL
+	// mutation.
+	Endpoint string `json:"endpoint"`
+
+	// Operation is the name of a GraphQL mutation or, if it does not
+	// have a name, the name of its first field (e.g., "projectDelete").
+	Operation string `json:"operation,omitempty"`
+
+	// Payload is the variables of a GraphQL mutation with the values
+	// of the fields that hold secrets redacted.
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Entity is the name of the entity that was changed (e.g., the
+	// full path of a project) taken from After or Before or "" if
+	// neither names it.
+	Entity string `json:"entity,omitempty"`
+
+	// Status is the HTTP status of the response or 0 if the request
+	// could not be sent.
+	Status int `json:"status"`
+
+	// Error is why the request could not be sent or "" if it was.
+	Error string `json:"error,omitempty"`
+
+	// Before is the entity as returned by Gitlab before it was
+	// changed.  It is only recorded for PUT, PATCH, and DELETE
+	// requests whose endpoint can also be read with GET.
+	Before json.RawMessage `json:"before,omitempty"`
+
+	// After is the response returned by Gitlab for a successful
+	// request which is usually the entity after it was changed.
+	After json.RawMessage `json:"after,omitempty"`
+}
+
+////////////////////////////////////////////////////////////////////////
+// Log
+////////////////////////////////////////////////////////////////////////
+
+// Log is an append-only audit log stored as one JSON record per line.
+// It is safe for concurrent use.
+type Log struct {
+
+	// fileName is the name of the file that holds the log.
+	fileName string
+
+	// mutex serializes the writes to the file.
+	mutex sync.Mutex
+}
+
+// NewLog returns a new Log stored in the file.  The file is created if
+// it does not exist so an unwritable log is reported before any
+// change is made instead of after.
+func NewLog(fileName string) (*Log, error) {
+	f, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("NewLog: %w", err)
+	}
+	f.Close()
+	return &Log{fileName: fileName}, nil
+}
+
+// Append appends the record to the log.
+func (l *Log) Append(r *Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("Append: %w", err)
+	}
+	line = append(line, '\n')
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	f, err := os.OpenFile(l.fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Append: %w", err)
+	}
+	_, err = f.Write(line)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("Append: %w", err)
+	}
+	return nil
+}
+
+// ReadLog returns the records in the audit log stored in the file.
+func ReadLog(fileName string) ([]*Record, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("ReadLog: %w", err)
+	}
+	var records []*Record
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for decoder.More() {
+		r := &Record{}
+		err = decoder.Decode(r)
+		if err != nil {
+			return nil, fmt.Errorf("ReadLog: %w", err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// Transport
+////////////////////////////////////////////////////////////////////////
+
+// Transport is an http.RoundTripper that records each change sent to
+// the Gitlab REST API in a Log.  A failure to write the log is logged
+// at the error level, but the response is still returned because the
+// change has already been made.
+type Transport struct {
+
+	// Base is the transport that actually sends the requests.  If
+	// nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+
+	// Log is where the changes are recorded.
+	Log *Log
+
+	// user is the name of the local user.
+	user string
+
+	// actor is the username of the Gitlab user who owns the token.
+	actor string
+
+	// actorOnce ensures the actor is looked up at most once.
+	actorOnce sync.Once
+}
+
+// NewTransport returns a new Transport that sends the requests with
+// base (which can be nil) and records the changes in the audit log
+// stored in the file.
+func NewTransport(base http.RoundTripper, fileName string) (*Transport, error) {
+	log, err := NewLog(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return &Transport{Base: base, Log: log, user: localUser()}, nil
+}
+
+// localUser returns the name of the local user or "" if it cannot be
+// determined.
+func localUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// Only record changes sent to the REST API and GraphQL mutations.
+	path := req.URL.EscapedPath()
+	if req.Method == http.MethodPost && strings.HasSuffix(path, graphQLPath) {
+		return t.roundTripGraphQL(base, req, path)
+	}
+	i := strings.Index(path, apiPrefix+"/")
+	if i < 0 || !isChange(req.Method) {
+		return base.RoundTrip(req)
+	}
+	record := &Record{
+		Time:     time.Now().UTC(),
+		Actor:    t.lookupActor(base, req, path[:i+len(apiPrefix)]),
+		User:     t.user,
+		Method:   req.Method,
+		Endpoint: path[i+len(apiPrefix):],
+	}
+
+	// Read the entity before it is changed.
+	switch req.Method {
+	case http.MethodPut, http.MethodPatch, http.MethodDelete:
+		record.Before, _ = get(base, req, path)
+	}
+
+	return t.send(base, req, record)
+}
+
+// roundTripGraphQL sends the GraphQL request whose path is path and
+// records it if it contains a mutation.
+func (t *Transport) roundTripGraphQL(
+	base http.RoundTripper,
+	req *http.Request,
+	path string,
+) (*http.Response, error) {
+
+	// Read the body so it can be inspected, and send a copy of the
+	// request with the body replaced.
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	if !mutationRegexp.Match(body) {
+		return base.RoundTrip(req)
+	}
+
+	// Record the mutation.
+	var request struct {
+		Query         string          `json:"query"`
+		OperationName string          `json:"operationName"`
+		Variables     json.RawMessage `json:"variables"`
+	}
+	json.Unmarshal(body, &request)
+	record := &Record{
+		Time:      time.Now().UTC(),
+		Actor:     t.lookupActor(base, req, strings.TrimSuffix(path, graphQLPath)+apiPrefix),
+		User:      t.user,
+		Method:    req.Method,
+		Endpoint:  graphQLPath,
+		Operation: operationName(request.Query, request.OperationName),
+		Payload:   redact(request.Variables),
+	}
+	return t.send(base, req, record)
+}
+
+// operationName returns the name of the GraphQL mutation in the query
+// which is name if it is not empty.  Otherwise, it is the name given
+// in the query or, if none, the name of the first field.
+func operationName(query string, name string) string {
+	if name != "" {
+		return name
+	}
+	m := operationRegexp.FindStringSubmatch(query)
+	if m == nil {
+		return ""
+	}
+	if m[1] != "" {
+		return m[1]
+	}
+	return m[2]
+}
+
+// send sends the request and completes and appends the record with
+// the response.
+func (t *Transport) send(
+	base http.RoundTripper,
+	req *http.Request,
+	record *Record,
+) (*http.Response, error) {
+
+	// Send the request and record the response.  The body is read so
+	// it can be recorded and then replaced so the caller can still
+	// read it.
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		record.Error = err.Error()
+		t.append(record)
+		return nil, err
+	}
+	record.Status = resp.StatusCode
+	if resp.StatusCode < http.StatusBadRequest && resp.Body != nil {
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr == nil {
+			record.After = redact(body)
+		}
+	}
+	record.Entity = entityName(record.After)
+	if record.Entity == "" {
+		record.Entity = entityName(record.Before)
+	}
+	t.append(record)
+	return resp, nil
+}
+
+// isChange returns true if a request with the method can change
+// something on the server.
+func isChange(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// append appends the record to the log logging any error.
+func (t *Transport) append(r *Record) {
+	err := t.Log.Append(r)
+	if err != nil {
+		slog.Error("audit record not written", "method", r.Method,
+			"endpoint", r.Endpoint, "error", err)
+	}
+}
+
+// lookupActor returns the username of the Gitlab user who owns the
+// token used by req.  The user is looked up with "GET /user" the first
+// time this method is called.  The root is the path of the REST API
+// (e.g., "/api/v4").
+func (t *Transport) lookupActor(
+	base http.RoundTripper,
+	req *http.Request,
+	root string,
+) string {
+	t.actorOnce.Do(func() {
+		body, err := get(base, req, root+"/user")
+		if err != nil {
+			return
+		}
+		var u struct {
+			Username string `json:"username"`
+		}
+		if json.Unmarshal(body, &u) == nil {
+			t.actor = u.Username
+		}
+	})
+	return t.actor
+}
+
+// get sends a GET request for the path using the URL and the
+// authentication headers of req and returns the (redacted) JSON body
+// of a successful response.
+func get(base http.RoundTripper, req *http.Request, path string) (json.RawMessage, error) {
+	u := *req.URL
+	u.RawQuery = ""
+	u.Path = ""
+	u.RawPath = ""
+	getReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet,
+		u.String()+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	getReq.Header = req.Header.Clone()
+	getReq.Header.Del("Content-Type")
+	resp, err := base.RoundTrip(getReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return redact(body), nil
+}
+
+// redact returns the JSON body with the values of the fields that hold
+// secrets (e.g., tokens, passwords, and the values of CI/CD variables)
+// replaced or nil if the body is not JSON.
+func redact(body []byte) json.RawMessage {
+	var v any
+	if json.Unmarshal(body, &v) != nil {
+		return nil
+	}
+	redactValue(v)
+	result, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+// redactValue replaces the values of the fields that hold secrets in
+// the decoded JSON value in place.
+func redactValue(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if isSecret(key) && value != nil {
+				v[key] = redactedValue
+			} else {
+				redactValue(value)
+			}
+		}
+	case []any:
+		for _, value := range v {
+			redactValue(value)
+		}
+	}
+}
+
+// isSecret returns true if the JSON field with the key holds a secret.
+func isSecret(key string) bool {
+	key = strings.ToLower(key)
+	return key == "value" ||
+		strings.HasSuffix(key, "token") ||
+		strings.HasSuffix(key, "password") ||
+		strings.HasSuffix(key, "secret")
+}
+
+// entityName returns the name of the entity in the JSON body or "" if
+// the body is not an object that names an entity.
+func entityName(body json.RawMessage) string {
+	var object map[string]any
+	if json.Unmarshal(body, &object) != nil {
+		return ""
+	}
+	for _, key := range entityKeys {
+		if name, ok := object[key].(string); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,216 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestRedact(t *testing.T) {
+	type Data []struct {
+		body     string
+		expected string
+	}
+	data := Data{
+		{`{"key":"A","value":"secret"}`, `{"key":"A","value":"REDACTED"}`},
+		{`[{"token":"t","url":"u"}]`, `[{"token":"REDACTED","url":"u"}]`},
+		{`{"hook":{"push_events":true,"secret_token":"t"}}`,
+			`{"hook":{"push_events":true,"secret_token":"REDACTED"}}`},
+		{`{"password":null}`, `{"password":null}`},
+		{`not json`, ``},
+	}
+	for _, d := range data {
+		actual := string(redact([]byte(d.body)))
+		if actual != d.expected {
+			t.Errorf("redact(%s): expected=%s  actual=%s", d.body, d.expected, actual)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := fake_gitlab.NewServer(t)
+	server.Username = "admin"
+	server.AddGroup("foo")
+	server.AddProject("foo", "alpha")
+	server.AddProject("foo", "beta")
+	fileName := filepath.Join(t.TempDir(), "audit.jsonl")
+	transport, err := NewTransport(nil, fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client, err := gitlab.NewClient("token",
+		gitlab.WithBaseURL(server.URL),
+		gitlab.WithCustomRetryMax(0),
+		gitlab.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Make changes with reads in between which are not recorded.  The
+	// second deletion fails because the project no longer exists.
+	_, _, err = client.Projects.GetProject("foo/alpha", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = client.Projects.DeleteProject("foo/alpha", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = client.Projects.DeleteProject("foo/alpha", nil)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	_, _, err = client.ProjectVariables.CreateVariable("foo/beta",
+		&gitlab.CreateProjectVariableOptions{
+			Key:   gitlab.Ptr("PASSWORD"),
+			Value: gitlab.Ptr("hunter2"),
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the records.
+	records, err := ReadLog(fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("records: expected=%v  actual=%v", 3, len(records))
+	}
+	var before struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	}
+	json.Unmarshal(records[0].Before, &before)
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"actor", "admin admin admin",
+			records[0].Actor + " " + records[1].Actor + " " + records[2].Actor},
+		{"user", transport.user, records[0].User},
+		{"delete", "DELETE /projects/foo%2Falpha",
+			records[0].Method + " " + records[0].Endpoint},
+		{"delete entity", "foo/alpha", records[0].Entity},
+		{"delete before", "foo/alpha", before.PathWithNamespace},
+		{"delete status", true, records[0].Status < http.StatusBadRequest},
+		{"failed status", http.StatusNotFound, records[1].Status},
+		{"failed before", 0, len(records[1].Before)},
+		{"create", "POST /projects/foo%2Fbeta/variables",
+			records[2].Method + " " + records[2].Endpoint},
+		{"create entity", "PASSWORD", records[2].Entity},
+		{"create before", 0, len(records[2].Before)},
+		{"redacted", false, strings.Contains(string(records[2].After), "hunter2")},
+		{"time", false, records[0].Time.IsZero()},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("audit %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
+
+func TestTransportGraphQL(t *testing.T) {
+	var received []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"username":"admin"}`)
+	})
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		fmt.Fprint(w, `{"data":{}}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	fileName := filepath.Join(t.TempDir(), "audit.jsonl")
+	transport, err := NewTransport(nil, fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client := &http.Client{Transport: transport}
+
+	// post sends the GraphQL request body.
+	post := func(body string) {
+		resp, err := client.Post(server.URL+"/api/graphql", "application/json",
+			strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// Send a query which is not recorded and two mutations which are.
+	queries := []string{
+		`{"query":"query { currentUser { username } }"}`,
+		`{"query":"mutation { projectDelete: destroyProject(input: {projectPath: \"foo/alpha\"}) { errors } }"}`,
+		`{"query":"mutation SetVar($value: String!) { ciVariableCreate(value: $value) { errors } }",` +
+			`"variables":{"key":"PASSWORD","value":"hunter2"}}`,
+	}
+	for _, q := range queries {
+		post(q)
+	}
+
+	// Verify the records.
+	records, err := ReadLog(fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("records: expected=%v  actual=%v", 2, len(records))
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"received", queries, received},
+		{"actor", "admin", records[0].Actor},
+		{"endpoint", "POST /api/graphql", records[0].Method + " " + records[0].Endpoint},
+		{"first field", "destroyProject", records[0].Operation},
+		{"status", http.StatusOK, records[0].Status},
+		{"named", "SetVar", records[1].Operation},
+		{"payload", `{"key":"PASSWORD","value":"REDACTED"}`, string(records[1].Payload)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("audit graphql %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	type Data []struct {
+		query    string
+		name     string
+		expected string
+	}
+	data := Data{
+		{"mutation { destroyProject(input: {}) { errors } }", "", "destroyProject"},
+		{"mutation Delete($p: ID!) { destroyProject(input: {}) { errors } }", "", "Delete"},
+		{"mutation { x: destroyProject(input: {}) { errors } }", "", "destroyProject"},
+		{"mutation { destroyProject(input: {}) { errors } }", "Given", "Given"},
+		{"query { currentUser { username } }", "", ""},
+	}
+	for _, d := range data {
+		actual := operationName(d.query, d.name)
+		if actual != d.expected {
+			t.Errorf("operationName(%q, %q): expected=%q  actual=%q",
+				d.query, d.name, d.expected, actual)
+		}
+	}
+}
@@ -21,17 +21,45 @@
 //        <password></password>
 //    -->
 //
+//    <!--
+//        <keyring-service></keyring-service>
+//        <keyring-user></keyring-user>
+//    -->
+//
 //  </AuthInfo>
+//
+// The last format reads a private or personal token from the OS
+// credential store instead of storing it in the XML file.  See
+// [KeyringInfo].
+//
+// The authentication information for several Gitlab instances can be
+// kept in one file by wrapping each in a named profile which is
+// selected by [LoadProfile()]:
+//
+//  <AuthInfo>
+//    <profile name="prod">
+//      <private-token></private-token>
+//    </profile>
+//    <profile name="staging">
+//      <private-token></private-token>
+//    </profile>
+//  </AuthInfo>
+//
+// Alternatively, the token can be passed in one of the environment
+// variables GITLAB_TOKEN, GITLAB_PRIVATE_TOKEN, or GITLAB_OAUTH_TOKEN
+// in which case the XML file is not needed.  See [LoadFromEnv()].
 
 package authinfo
 
 import (
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
 
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_schema"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -41,6 +69,7 @@ import (
 
 var (
 	ErrAuthInfoInvalidXML = errors.New("invalid XML")
+	ErrProfileNotFound    = errors.New("profile not found")
 )
 
 ////////////////////////////////////////////////////////////////////////
@@ -109,6 +138,13 @@ func (authInfo *BasicAuthInfo) CreateGitlabClient(options ...gitlab.ClientOption
 		options...)
 }
 
+// String returns a description of the authentication method with the
+// password redacted so it is safe to print.
+func (authInfo *BasicAuthInfo) String() string {
+	return "HTTP basic authentication (username: " + authInfo.Username +
+		", password: " + Redact(authInfo.Password) + ")"
+}
+
 ////////////////////////////////////////////////////////////////////////
 // OAuthToken
 ////////////////////////////////////////////////////////////////////////
@@ -153,6 +189,12 @@ func (token *OAuthToken) CreateGitlabClient(options ...gitlab.ClientOptionFunc)
 	return gitlab.NewOAuthClient(token.Token, options...)
 }
 
+// String returns a description of the authentication method with the
+// token redacted so it is safe to print.
+func (token *OAuthToken) String() string {
+	return "OAuth token (" + Redact(token.Token) + ")"
+}
+
 ////////////////////////////////////////////////////////////////////////
 // PrivateToken
 ////////////////////////////////////////////////////////////////////////
@@ -199,13 +241,108 @@ func (token *PrivateToken) CreateGitlabClient(options ...gitlab.ClientOptionFunc
 	return gitlab.NewClient(token.Token, options...)
 }
 
+// String returns a description of the authentication method with the
+// token redacted so it is safe to print.
+func (token *PrivateToken) String() string {
+	return "private token (" + Redact(token.Token) + ")"
+}
+
+////////////////////////////////////////////////////////////////////////
+// Redact()
+////////////////////////////////////////////////////////////////////////
+
+// Redact returns the secret with all but its last four characters
+// replaced by asterisks.  Secrets that are too short to partially
+// reveal safely are replaced entirely.
+func Redact(secret string) string {
+	if len(secret) < 12 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
+}
+
+////////////////////////////////////////////////////////////////////////
+// LoadFromEnv()
+////////////////////////////////////////////////////////////////////////
+
+// Names of the environment variables that can hold the token.
+const (
+	EnvToken        = "GITLAB_TOKEN"
+	EnvPrivateToken = "GITLAB_PRIVATE_TOKEN"
+	EnvOAuthToken   = "GITLAB_OAUTH_TOKEN"
+)
+
+// LoadFromEnv returns the authentication information held by the
+// environment variables or nil if none of them are set.  GITLAB_TOKEN
+// and GITLAB_PRIVATE_TOKEN hold a private or personal token, and
+// GITLAB_OAUTH_TOKEN holds an OAuth token.  If more than one is set,
+// they are checked in that order.  Empty variables are treated as if
+// they were not set.
+func LoadFromEnv() AuthInfo {
+	for _, name := range []string{EnvToken, EnvPrivateToken} {
+		if token := os.Getenv(name); token != "" {
+			privateToken := NewPrivateToken(token)
+			return &privateToken
+		}
+	}
+	if token := os.Getenv(EnvOAuthToken); token != "" {
+		oauthToken := NewOAuthToken(token)
+		return &oauthToken
+	}
+	return nil
+}
+
+// LoadFromEnvOrFile returns the authentication information held by
+// the environment variables if any of them are set.  Otherwise, it
+// loads the authentication information from the file.  See
+// [LoadFromEnv()] and [Load()].
+func LoadFromEnvOrFile(fname string) (AuthInfo, error) {
+	if authInfo := LoadFromEnv(); authInfo != nil {
+		return authInfo, nil
+	}
+	return Load(fname)
+}
+
 ////////////////////////////////////////////////////////////////////////
 // LoadAuthInfo()
 ////////////////////////////////////////////////////////////////////////
 
+// authInfoSchema describes every element allowed in the XML file.
+type authInfoSchema struct {
+	BasicAuthInfo
+	KeyringInfo
+	OAuthToken
+	PrivateToken
+	Profiles []authInfoProfileSchema `xml:"profile"`
+}
+
+// authInfoProfileSchema describes every element allowed in a profile.
+type authInfoProfileSchema struct {
+	BasicAuthInfo
+	KeyringInfo
+	OAuthToken
+	PrivateToken
+}
+
+// authInfoProfiles holds the profiles of the XML file.
+type authInfoProfiles struct {
+	Profiles []struct {
+		Name     string `xml:"name,attr"`
+		InnerXML string `xml:",innerxml"`
+	} `xml:"profile"`
+}
+
 // LoadAuthInfo loads the authentication information from the file
 // returning the correct type of AuthInfo concrete type.
 func Load(fname string) (AuthInfo, error) {
+	return LoadProfile(fname, "")
+}
+
+// LoadProfile loads the authentication information of the named
+// profile from the file returning the correct type of AuthInfo
+// concrete type.  If profile is empty, the authentication information
+// outside of the profiles is loaded.
+func LoadProfile(fname string, profile string) (AuthInfo, error) {
 	var r io.Reader
 
 	// Open the file and schedule it to be closed.
@@ -221,6 +358,44 @@ func Load(fname string) (AuthInfo, error) {
 		return nil, err
 	}
 
+	// Validate the XML file so that misspelled elements are reported
+	// instead of silently ignored.
+	err = xml_schema.Validate(fname, buf, &authInfoSchema{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Replace the content of the file with the content of the profile.
+	// If the same profile appears more than once, the last one wins.
+	if profile != "" {
+		var profiles authInfoProfiles
+		err = xml.Unmarshal(buf, &profiles)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, p := range profiles.Profiles {
+			if p.Name == profile {
+				buf = []byte("<AuthInfo>" + p.InnerXML + "</AuthInfo>")
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrProfileNotFound, profile)
+		}
+	}
+
+	// Try to load the token from the OS credential store.
+	r = strings.NewReader(string(buf))
+	keyringInfo, err := NewKeyringInfoFromXML(r)
+	if err == nil {
+		privateToken, err := keyringInfo.Load()
+		if err != nil {
+			return nil, err
+		}
+		return privateToken, nil
+	}
+
 	// Try to create a OAuthToken.
 	r = strings.NewReader(string(buf))
 	oauthToken, err := NewOAuthTokenFromXML(r)
@@ -0,0 +1,404 @@
+package authinfo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_schema"
+	"github.com/zalando/go-keyring"
+)
+
+func TestNewBasicAuthInfo(t *testing.T) {
+	// Create new BasicAuthInfo struct.
+	authInfo := NewBasicAuthInfo("foo", "bar")
+	if authInfo.Username != "foo" {
+		t.Errorf("invalid username: expected=%q  actual=%q", "foo", authInfo.Username)
+	}
+	if authInfo.Password != "bar" {
+		t.Errorf("invalid password: expected=%q  actual=%q", "bar", authInfo.Password)
+	}
+}
+
+func TestNewOAuthToken(t *testing.T) {
+	// Create new OAuthToken struct.
+	token := NewOAuthToken("foo")
+	if token.Token != "foo" {
+		t.Errorf("invalid token: expected=%q  actual=%q", "foo", token.Token)
+	}
+}
+
+func TestNewPrivateToken(t *testing.T) {
+	// Create new PrivateToken struct.
+	token := NewPrivateToken("foo")
+	if token.Token != "foo" {
+		t.Errorf("invalid token: expected=%q  actual=%q", "foo", token.Token)
+	}
+}
+
+func TestNewBasicAuthInfoFromXML(t *testing.T) {
+	type Data []struct {
+		root     string
+		username string
+		password string
+		err      error
+	}
+
+	data := Data{
+		{
+			root: `
+                <AuthInfo>
+                    <username>foo</username>
+                    <password>bar</password>
+                </AuthInfo>`,
+			username: "foo",
+			password: "bar",
+			err:      nil,
+		},
+		{
+			root: `
+                <AuthInfo>
+                    <oauth-token>token</oauth-token>
+                </AuthInfo>`,
+			username: "",
+			password: "",
+			err:      ErrAuthInfoInvalidXML,
+		},
+		{
+			root: `
+                <AuthInfo>
+                    <private-token>token</private-token>
+                </AuthInfo>`,
+			username: "",
+			password: "",
+			err:      ErrAuthInfoInvalidXML,
+		},
+	}
+
+	for _, d := range data {
+
+		r := strings.NewReader(d.root)
+		authInfo, err := NewBasicAuthInfoFromXML(r)
+		if err != d.err {
+			t.Fatalf("unexpected error: %v: %s", err, d.root)
+		}
+		if d.err == nil {
+			if authInfo.Username != d.username {
+				t.Errorf("invalid username: expected=%q  actual=%q", d.username, authInfo.Username)
+			}
+			if authInfo.Password != d.password {
+				t.Errorf("invalid password: expected=%q  actual=%q", d.password, authInfo.Password)
+			}
+		}
+	}
+}
+
+func TestNewOAuthTokenFromXML(t *testing.T) {
+	type Data []struct {
+		root  string
+		token string
+		err   error
+	}
+
+	data := Data{
+		{
+			root: `
+                <AuthInfo>
+                    <oauth-token>token</oauth-token>
+                </AuthInfo>`,
+			token: "token",
+			err:   nil,
+		},
+		{
+			root: `
+                <AuthInfo>
+                    <username>foo</username>
+                    <password>bar</password>
+                </AuthInfo>`,
+			err: ErrAuthInfoInvalidXML,
+		},
+		{
+			root: `
+                <AuthInfo>
+                    <private-token>token</private-token>
+                </AuthInfo>`,
+			token: "token",
+			err:   ErrAuthInfoInvalidXML,
+		},
+	}
+
+	for _, d := range data {
+
+		r := strings.NewReader(d.root)
+		token, err := NewOAuthTokenFromXML(r)
+		if err != d.err {
+			t.Fatalf("unexpected error: %v: %s", err, d.root)
+		}
+		if d.err == nil {
+			if token.Token != d.token {
+				t.Errorf("invalid token: expected=%q  actual=%q", d.token, token.Token)
+			}
+		}
+	}
+}
+
+func TestPrivateTokenFromXML(t *testing.T) {
+	type Data []struct {
+		root  string
+		token string
+		err   error
+	}
+
+	data := Data{
+		{
+			root: `
+                <AuthInfo>
+                    <private-token>token</private-token>
+                </AuthInfo>`,
+			token: "token",
+			err:   nil,
+		},
+		{
+			root: `
+                <AuthInfo>
+                    <oauth-token>token</oauth-token>
+                </AuthInfo>`,
+			token: "token",
+			err:   ErrAuthInfoInvalidXML,
+		},
+		{
+			root: `
+                <AuthInfo>
+                    <username>foo</username>
+                    <password>bar</password>
+                </AuthInfo>`,
+			err: ErrAuthInfoInvalidXML,
+		},
+	}
+
+	for _, d := range data {
+
+		r := strings.NewReader(d.root)
+		token, err := NewPrivateTokenFromXML(r)
+		if err != d.err {
+			t.Fatalf("unexpected error: %v: %s", err, d.root)
+		}
+		if d.err == nil {
+			if token.Token != d.token {
+				t.Errorf("invalid token: expected=%q  actual=%q", d.token, token.Token)
+			}
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	type Data []struct {
+		secret   string
+		expected string
+	}
+
+	data := Data{
+		{secret: "", expected: "****"},
+		{secret: "short", expected: "****"},
+		{secret: "glpat-0123456789abcdef", expected: "****cdef"},
+	}
+
+	for _, d := range data {
+		actual := Redact(d.secret)
+		if actual != d.expected {
+			t.Errorf("invalid redaction: expected=%q  actual=%q", d.expected, actual)
+		}
+	}
+
+	// Verify the token does not leak through String().
+	token := NewPrivateToken("glpat-0123456789abcdef")
+	if strings.Contains(token.String(), "0123456789") {
+		t.Errorf("token leaked: %q", token.String())
+	}
+}
+
+func TestLoad(t *testing.T) {
+
+	// Verify the example file is valid.
+	_, err := Load(filepath.Join("..", "..", "auth.xml.example"))
+	if errors.Is(err, xml_schema.ErrInvalid) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Verify a misspelled element is reported instead of ignored.
+	fname := filepath.Join(t.TempDir(), "auth.xml")
+	err = os.WriteFile(fname, []byte(`<AuthInfo>
+  <private_token>token</private_token>
+</AuthInfo>`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = Load(fname)
+	if !errors.Is(err, xml_schema.ErrInvalid) {
+		t.Fatalf("expected ErrInvalid: %v", err)
+	}
+	expected := fname + ":2:3: unknown element <private_token> in <AuthInfo>"
+	if err.Error() != expected {
+		t.Errorf("unexpected error: expected=%q  actual=%q", expected, err.Error())
+	}
+}
+
+func TestLoadProfile(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "auth.xml")
+	err := os.WriteFile(fname, []byte(`<AuthInfo>
+  <private-token>default-token</private-token>
+  <profile name="prod">
+    <private-token>prod-token</private-token>
+  </profile>
+  <profile name="staging">
+    <oauth-token>staging-token</oauth-token>
+  </profile>
+</AuthInfo>`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	type Data []struct {
+		profile  string
+		expected AuthInfo
+	}
+	data := Data{
+		{"", &PrivateToken{Token: "default-token"}},
+		{"prod", &PrivateToken{Token: "prod-token"}},
+		{"staging", &OAuthToken{Token: "staging-token"}},
+	}
+	for _, d := range data {
+		actual, err := LoadProfile(fname, d.profile)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", d.profile, err)
+		}
+		if fmt.Sprintf("%#v", actual) != fmt.Sprintf("%#v", d.expected) {
+			t.Errorf("%q: expected=%#v  actual=%#v", d.profile, d.expected, actual)
+		}
+	}
+
+	// Verify an unknown profile is reported.
+	_, err = LoadProfile(fname, "bogus")
+	if !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("expected ErrProfileNotFound: %v", err)
+	}
+}
+
+func TestLoadFromEnv(t *testing.T) {
+	type Data []struct {
+		env      map[string]string
+		expected string
+	}
+
+	data := Data{
+		{
+			env:      map[string]string{},
+			expected: "",
+		},
+		{
+			env:      map[string]string{EnvToken: "token-1234567890"},
+			expected: "private token (****7890)",
+		},
+		{
+			env:      map[string]string{EnvPrivateToken: "token-1234567890"},
+			expected: "private token (****7890)",
+		},
+		{
+			env:      map[string]string{EnvOAuthToken: "token-1234567890"},
+			expected: "OAuth token (****7890)",
+		},
+		{
+			env: map[string]string{
+				EnvToken:      "token-1234567890",
+				EnvOAuthToken: "token-abcdefghij",
+			},
+			expected: "private token (****7890)",
+		},
+		{
+			env:      map[string]string{EnvToken: "", EnvOAuthToken: "token-abcdefghij"},
+			expected: "OAuth token (****ghij)",
+		},
+	}
+
+	for _, d := range data {
+		for _, name := range []string{EnvToken, EnvPrivateToken, EnvOAuthToken} {
+			t.Setenv(name, d.env[name])
+		}
+		actual := ""
+		if authInfo := LoadFromEnv(); authInfo != nil {
+			actual = fmt.Sprint(authInfo)
+		}
+		if actual != d.expected {
+			t.Errorf("LoadFromEnv(%v): expected=%q  actual=%q", d.env, d.expected, actual)
+		}
+	}
+
+	// Verify the file is not needed when the environment is set.
+	missing := filepath.Join(t.TempDir(), "auth.xml")
+	t.Setenv(EnvToken, "token-1234567890")
+	_, err := LoadFromEnvOrFile(missing)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, name := range []string{EnvToken, EnvPrivateToken, EnvOAuthToken} {
+		t.Setenv(name, "")
+	}
+	_, err = LoadFromEnvOrFile(missing)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected=%v  actual=%v", os.ErrNotExist, err)
+	}
+}
+
+func TestLoadFromKeyring(t *testing.T) {
+	keyring.MockInit()
+
+	// Write an auth.xml file that names the entry in the keyring.
+	fname := filepath.Join(t.TempDir(), "auth.xml")
+	err := os.WriteFile(fname, []byte(`<AuthInfo>
+  <keyring-service>glcmds-test</keyring-service>
+</AuthInfo>`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the missing token is reported.
+	_, err = Load(fname)
+	if !errors.Is(err, ErrKeyringTokenNotFound) {
+		t.Errorf("expected=%v  actual=%v", ErrKeyringTokenNotFound, err)
+	}
+
+	// Verify the stored token is loaded using the default user.
+	keyringInfo := NewKeyringInfo("glcmds-test", "")
+	err = keyringInfo.Store("token-1234567890")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	authInfo, err := Load(fname)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "private token (****7890)"
+	if actual := fmt.Sprint(authInfo); actual != expected {
+		t.Errorf("expected=%q  actual=%q", expected, actual)
+	}
+
+	// Verify the token is deleted.
+	err = keyringInfo.Delete()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = keyringInfo.Delete()
+	if !errors.Is(err, ErrKeyringTokenNotFound) {
+		t.Errorf("expected=%v  actual=%v", ErrKeyringTokenNotFound, err)
+	}
+
+	// Verify the service is required.
+	_, err = NewKeyringInfoFromXML(strings.NewReader(`<AuthInfo>
+  <keyring-user>default</keyring-user>
+</AuthInfo>`))
+	if !errors.Is(err, ErrAuthInfoInvalidXML) {
+		t.Errorf("expected=%v  actual=%v", ErrAuthInfoInvalidXML, err)
+	}
+}
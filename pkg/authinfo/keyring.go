@@ -0,0 +1,123 @@
+// This file is used for reading and writing the user's access token in
+// the OS credential store (the macOS Keychain, the Windows Credential
+// Manager, or the Secret Service on Linux) so that the token does not
+// have to be stored in plaintext in auth.xml.  Instead, auth.xml only
+// names the entry in the credential store:
+//
+//	<AuthInfo>
+//	    <keyring-service>glcmds</keyring-service>
+//	    <keyring-user>default</keyring-user>
+//	</AuthInfo>
+
+package authinfo
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Names of the entry in the OS credential store that holds the token
+// if they are not given.
+const (
+	DefaultKeyringService = "glcmds"
+	DefaultKeyringUser    = "default"
+)
+
+var (
+	// ErrKeyringTokenNotFound is returned when the OS credential
+	// store has no token for the service and user.
+	ErrKeyringTokenNotFound = errors.New("token not found in keyring")
+)
+
+////////////////////////////////////////////////////////////////////////
+// KeyringInfo
+////////////////////////////////////////////////////////////////////////
+
+// KeyringInfo names the entry in the OS credential store that holds a
+// private or personal access token.
+type KeyringInfo struct {
+	Service string `xml:"keyring-service"`
+	User    string `xml:"keyring-user"`
+}
+
+// NewKeyringInfo creates a new KeyringInfo that names the entry in
+// the OS credential store.  Empty names are replaced by
+// DefaultKeyringService and DefaultKeyringUser.
+func NewKeyringInfo(service, user string) KeyringInfo {
+	if service == "" {
+		service = DefaultKeyringService
+	}
+	if user == "" {
+		user = DefaultKeyringUser
+	}
+	return KeyringInfo{
+		Service: service,
+		User:    user,
+	}
+}
+
+// NewKeyringInfoFromXML creates a new KeyringInfo from the XML
+// accessible through the io.Reader.  The format of the XML is as
+// follows where <keyring-user> is optional:
+//
+//	<AuthInfo>
+//	    <keyring-service></keyring-service>
+//	    <keyring-user></keyring-user>
+//	</AuthInfo>
+func NewKeyringInfoFromXML(r io.Reader) (KeyringInfo, error) {
+	result := KeyringInfo{}
+	err := xml.NewDecoder(r).Decode(&result)
+	if err != nil {
+		return KeyringInfo{}, err
+	}
+	if len(result.Service) == 0 {
+		return KeyringInfo{}, ErrAuthInfoInvalidXML
+	}
+	return NewKeyringInfo(result.Service, result.User), nil
+}
+
+// Load returns the token in the OS credential store as a
+// PrivateToken.
+func (info *KeyringInfo) Load() (*PrivateToken, error) {
+	token, err := keyring.Get(info.Service, info.User)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("%w: service %q, user %q",
+			ErrKeyringTokenNotFound, info.Service, info.User)
+	} else if err != nil {
+		return nil, fmt.Errorf("KeyringInfo.Load: %w", err)
+	}
+	privateToken := NewPrivateToken(token)
+	return &privateToken, nil
+}
+
+// Store stores the token in the OS credential store replacing the
+// token already there.
+func (info *KeyringInfo) Store(token string) error {
+	err := keyring.Set(info.Service, info.User, token)
+	if err != nil {
+		return fmt.Errorf("KeyringInfo.Store: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the token from the OS credential store.
+func (info *KeyringInfo) Delete() error {
+	err := keyring.Delete(info.Service, info.User)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("%w: service %q, user %q",
+			ErrKeyringTokenNotFound, info.Service, info.User)
+	} else if err != nil {
+		return fmt.Errorf("KeyringInfo.Delete: %w", err)
+	}
+	return nil
+}
+
+// String returns a description of the entry in the OS credential
+// store.
+func (info *KeyringInfo) String() string {
+	return "keyring (service: " + info.Service + ", user: " + info.User + ")"
+}
@@ -0,0 +1,426 @@
+// This file provides the implementation for the "access-review
+// export" command which exports every membership beneath a root group
+// as CSV for the quarterly access review.
+
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// AccessReviewExportOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// AccessReviewExportOptions are the options needed by this command.
+type AccessReviewExportOptions struct {
+
+	// Group is the full path of the root group beneath which all
+	// memberships are exported.  Defaults to "".
+	Group string `xml:"group"`
+
+	// OutputFileName is the name of the CSV file to which the
+	// memberships are written.  Defaults to "" which means the
+	// memberships are written to os.Stdout.
+	OutputFileName string `xml:"output-file-name"`
+}
+
+// Initialize initializes this AccessReviewExportOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *AccessReviewExportOptions) Initialize(flags *flag.FlagSet) {
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the root group beneath which memberships are exported"))
+
+	// -o
+	flags.StringVar(&opts.OutputFileName, "o", opts.OutputFileName,
+		i18n.T("CSV file to which the memberships are written instead of stdout"))
+
+	// --output
+	flags.StringVar(&opts.OutputFileName, "output", opts.OutputFileName,
+		i18n.T("CSV file to which the memberships are written instead of stdout"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// AccessReviewExportCommand
+////////////////////////////////////////////////////////////////////////
+
+// AccessReviewExportCommand implements the "access-review export"
+// command which exports the memberships beneath a root group.
+type AccessReviewExportCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[AccessReviewExportOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *AccessReviewExportCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] access-review export [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Export every membership of the root group and of the groups\n")
+	i18n.Fprintf(out, "    and projects beneath it as CSV with one row for each user,\n")
+	i18n.Fprintf(out, "    group or project, access level, whether the membership is\n")
+	i18n.Fprintf(out, "    direct or inherited, and the date the membership expires.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Export Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewAccessReviewExportCommand returns a new, initialized
+// AccessReviewExportCommand instance.
+func NewAccessReviewExportCommand(
+	name string,
+	opts *AccessReviewExportOptions,
+	session *Session,
+) *AccessReviewExportCommand {
+
+	// Create the new command.
+	cmd := &AccessReviewExportCommand{
+		GitlabCommand: GitlabCommand[AccessReviewExportOptions]{
+			BasicCommand: BasicCommand[AccessReviewExportOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// AccessReviewGroupsService is an abstraction of gitlab.GroupsService
+// which walks the groups and projects beneath a group and lists their
+// members.
+type AccessReviewGroupsService interface {
+	gitlab_util.ProjectsInGroupLister
+	gitlab_util.SubgroupsLister
+	gitlab_util.GroupMembersLister
+}
+
+// AccessReviewServices are the Gitlab services needed to export the
+// memberships.
+type AccessReviewServices struct {
+	Groups         AccessReviewGroupsService        /* was *gitlab.GroupsService */
+	ProjectMembers gitlab_util.ProjectMembersLister /* was *gitlab.ProjectMembersService */
+}
+
+// Membership is the access of a user to a group or project.
+type Membership struct {
+
+	// UserID is the ID of the member.
+	UserID int
+
+	// Username is the username of the member.
+	Username string
+
+	// Name is the name of the member.
+	Name string
+
+	// SourceType is either "group" or "project".
+	SourceType string
+
+	// Source is the full path of the group or project.
+	Source string
+
+	// AccessLevel is the access level of the member.
+	AccessLevel gitlab.AccessLevelValue
+
+	// Direct is whether the user is a direct member instead of
+	// inheriting the membership from an ancestor group.
+	Direct bool
+
+	// ExpiresAt is the date on which the membership expires or nil
+	// if it does not expire.
+	ExpiresAt *gitlab.ISOTime
+}
+
+// GetGroupMemberships returns the direct and inherited memberships of
+// the group.
+func GetGroupMemberships(
+	ctx context.Context,
+	s gitlab_util.GroupMembersLister, /* was *gitlab.GroupsService */
+	g *gitlab.Group,
+) ([]*Membership, error) {
+	var result []*Membership
+	direct, err := gitlab_util.GetAllGroupMembers(ctx, s, g.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroupMemberships: %w", err)
+	}
+	all, err := gitlab_util.GetAllGroupMembers(ctx, s, g.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroupMemberships: %w", err)
+	}
+	var ids []int
+	for _, m := range direct {
+		ids = append(ids, m.ID)
+	}
+	for _, m := range all {
+		result = append(result, &Membership{
+			UserID:      m.ID,
+			Username:    m.Username,
+			Name:        m.Name,
+			SourceType:  "group",
+			Source:      g.FullPath,
+			AccessLevel: m.AccessLevel,
+			Direct:      slices.Contains(ids, m.ID),
+			ExpiresAt:   m.ExpiresAt,
+		})
+	}
+	return result, nil
+}
+
+// GetProjectMemberships returns the direct and inherited memberships
+// of the project.
+func GetProjectMemberships(
+	ctx context.Context,
+	s gitlab_util.ProjectMembersLister, /* was *gitlab.ProjectMembersService */
+	p *gitlab.Project,
+) ([]*Membership, error) {
+	var result []*Membership
+	direct, err := gitlab_util.GetAllProjectMembers(ctx, s, p.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectMemberships: %w", err)
+	}
+	all, err := gitlab_util.GetAllProjectMembers(ctx, s, p.ID, true)
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectMemberships: %w", err)
+	}
+	var ids []int
+	for _, m := range direct {
+		ids = append(ids, m.ID)
+	}
+	for _, m := range all {
+		result = append(result, &Membership{
+			UserID:      m.ID,
+			Username:    m.Username,
+			Name:        m.Name,
+			SourceType:  "project",
+			Source:      p.PathWithNamespace,
+			AccessLevel: m.AccessLevel,
+			Direct:      slices.Contains(ids, m.ID),
+			ExpiresAt:   m.ExpiresAt,
+		})
+	}
+	return result, nil
+}
+
+// MembershipSource is a group or project with its memberships.
+type MembershipSource struct {
+
+	// Type is either "group" or "project".
+	Type string
+
+	// Path is the full path of the group or project.
+	Path string
+
+	// Memberships are the direct and inherited memberships sorted by
+	// username.
+	Memberships []*Membership
+}
+
+// GetAllMembershipSources returns the root group and the groups and
+// projects beneath it with their memberships sorted by full path.
+func GetAllMembershipSources(
+	ctx context.Context,
+	result *Result,
+	s AccessReviewServices,
+	group string,
+) ([]*MembershipSource, error) {
+	var sources []*MembershipSource
+	hook := gitlab_util.EventHookFromContext(ctx)
+
+	// Get the root group and the groups beneath it.
+	root, err := gitlab_util.FindExactGroup(ctx, s.Groups, group)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllMembershipSources: %w", err)
+	}
+	groups, err := gitlab_util.GetAllDescendantGroups(ctx, s.Groups, root.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllMembershipSources: %w", err)
+	}
+	groups = append([]*gitlab.Group{root}, groups...)
+
+	// Get the memberships of each group.
+	for _, g := range groups {
+		hook.OnItemStart(g.FullPath)
+		ms, err := GetGroupMemberships(ctx, s.Groups, g)
+		if err != nil {
+			hook.OnError(g.FullPath, err)
+			result.Fail(g.FullPath, g, err)
+			return nil, fmt.Errorf("GetAllMembershipSources: %w", err)
+		}
+		sources = append(sources, &MembershipSource{
+			Type:        "group",
+			Path:        g.FullPath,
+			Memberships: ms,
+		})
+		result.Succeed(g.FullPath, g)
+		hook.OnItemDone(g.FullPath)
+	}
+
+	// Get the memberships of each project.
+	projects, err := gitlab_util.GetAllProjects(ctx, s.Groups, root.FullPath, "", true)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllMembershipSources: %w", err)
+	}
+	for _, p := range projects {
+		hook.OnItemStart(p.PathWithNamespace)
+		ms, err := GetProjectMemberships(ctx, s.ProjectMembers, p)
+		if err != nil {
+			hook.OnError(p.PathWithNamespace, err)
+			result.Fail(p.PathWithNamespace, p, err)
+			return nil, fmt.Errorf("GetAllMembershipSources: %w", err)
+		}
+		sources = append(sources, &MembershipSource{
+			Type:        "project",
+			Path:        p.PathWithNamespace,
+			Memberships: ms,
+		})
+		result.Succeed(p.PathWithNamespace, p)
+		hook.OnItemDone(p.PathWithNamespace)
+	}
+
+	// Sort by full path and then by username.
+	slices.SortFunc(sources, func(a, b *MembershipSource) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	for _, source := range sources {
+		slices.SortFunc(source.Memberships, func(a, b *Membership) int {
+			return strings.Compare(a.Username, b.Username)
+		})
+	}
+
+	return sources, nil
+}
+
+// GetAllMemberships returns the memberships of the root group and of
+// the groups and projects beneath it sorted by the full path of the
+// group or project and then by username.
+func GetAllMemberships(
+	ctx context.Context,
+	result *Result,
+	s AccessReviewServices,
+	group string,
+) ([]*Membership, error) {
+	var memberships []*Membership
+	sources, err := GetAllMembershipSources(ctx, result, s, group)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllMemberships: %w", err)
+	}
+	for _, source := range sources {
+		memberships = append(memberships, source.Memberships...)
+	}
+	return memberships, nil
+}
+
+// WriteMembershipsCSV writes the memberships as CSV with a header row.
+func WriteMembershipsCSV(out io.Writer, memberships []*Membership) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"username", "name", "source_type", "source",
+		"access_level", "membership", "expires_at"})
+	for _, m := range memberships {
+		membership := "inherited"
+		if m.Direct {
+			membership = "direct"
+		}
+		expiresAt := ""
+		if m.ExpiresAt != nil {
+			expiresAt = m.ExpiresAt.String()
+		}
+		w.Write([]string{m.Username, m.Name, m.SourceType, m.Source,
+			gitlab_util.AccessLevelName(m.AccessLevel), membership, expiresAt})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("WriteMembershipsCSV: %w", err)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *AccessReviewExportCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Get the memberships.
+	memberships, err := GetAllMemberships(ctx, result, AccessReviewServices{
+		Groups:         cmd.client.Groups,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}, cmd.options.Group)
+	if err != nil {
+		return result, err
+	}
+
+	// Open the output file.
+	out := io.Writer(os.Stdout)
+	if cmd.options.OutputFileName != "" {
+		f, err := os.Create(cmd.options.OutputFileName)
+		if err != nil {
+			return result, err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Write the memberships.
+	return result, WriteMembershipsCSV(out, memberships)
+}
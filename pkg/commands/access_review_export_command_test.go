@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestAccessReviewExportIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	server.AddGroupMember("foo", "aberns", gitlab.OwnerPermissions)
+	server.AddGroupMember("foo/bar", "bcrocket", gitlab.DeveloperPermissions)
+	server.SetMemberExpiry("group", "foo/bar", "bcrocket",
+		time.Date(2030, time.January, 31, 0, 0, 0, 0, time.UTC))
+	server.AddProjectMember("foo/alpha", "bcrocket", gitlab.MaintainerPermissions)
+	cmd := NewAccessReviewCommand("access-review", &AccessReviewOptions{}, session)
+
+	// Export the memberships.
+	out, _, err := runCommand(t, cmd, []string{"export", "--group", "foo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the export.
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"rows", 12, len(lines)},
+		{"header", "username,name,source_type,source,access_level,membership,expires_at", lines[0]},
+		{"root", "aberns,Alice Berns,group,foo,Owner,direct,", lines[1]},
+		{"project direct", true, slices.Contains(lines,
+			"bcrocket,Bob Crocket,project,foo/alpha,Maintainer,direct,")},
+		{"group expiry", true, slices.Contains(lines,
+			"bcrocket,Bob Crocket,group,foo/bar,Developer,direct,2030-01-31")},
+		{"project inherited", true, slices.Contains(lines,
+			"bcrocket,Bob Crocket,project,foo/bar/delta,Developer,inherited,2030-01-31")},
+		{"group inherited", true, slices.Contains(lines,
+			"aberns,Alice Berns,project,foo/test-gamma,Owner,inherited,")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("access-review export %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
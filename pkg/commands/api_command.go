@@ -0,0 +1,329 @@
+// This file provides the implementation for the "api" command which
+// sends an arbitrary authenticated request to the REST API and prints
+// the JSON response so that endpoints not otherwise supported by this
+// program can still be used.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+)
+
+////////////////////////////////////////////////////////////////////////
+// APIOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// APIOptions are the options needed by this command.
+type APIOptions struct {
+
+	// Fields are the "key=value" parameters of the request.  For GET
+	// and DELETE requests, they are added to the query string.  For
+	// other requests, they are sent as the members of a JSON object
+	// in the body.  Defaults to empty.
+	Fields string_slice.StringSlice `xml:"fields>field"`
+
+	// InputFileName is the name of the file with the JSON body of the
+	// request or "-" for stdin.  Defaults to "" which means the body
+	// is built from the fields.
+	InputFileName string `xml:"input-file-name"`
+
+	// Paginate should cause every page of results of a GET request to
+	// be requested and printed as a single JSON array.  Both offset
+	// and keyset pagination are followed.  Defaults to false.
+	Paginate bool `xml:"paginate"`
+}
+
+// Initialize initializes this APIOptions instance so it can be used
+// with the "flag" package to parse the command-line arguments.
+func (opts *APIOptions) Initialize(flags *flag.FlagSet) {
+
+	// -f
+	flags.Var(&opts.Fields, "f",
+		i18n.T("comma-separated list of key=value parameters of the request"))
+
+	// --field
+	flags.Var(&opts.Fields, "field",
+		i18n.T("comma-separated list of key=value parameters of the request"))
+
+	// --input
+	flags.StringVar(&opts.InputFileName, "input", opts.InputFileName,
+		i18n.T("file with the JSON body of the request or - for stdin"))
+
+	// --paginate
+	flags.BoolVar(&opts.Paginate, "paginate", opts.Paginate,
+		i18n.T("request every page of results and print them as a single JSON array"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// APICommand
+////////////////////////////////////////////////////////////////////////
+
+// APICommand implements the "api" command which sends an arbitrary
+// request to the REST API.
+type APICommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[APIOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *APICommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] api [subcmd_options] <method> <path>\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Send an authenticated request with the <method> (e.g., GET)\n")
+	i18n.Fprintf(out, "    for the <path> relative to the api/v4 REST endpoint (e.g.,\n")
+	i18n.Fprintf(out, "    projects/foo%%2Fbar/hooks) and print the JSON response.\n")
+	i18n.Fprintf(out, "    For GET and DELETE requests, the --field parameters are\n")
+	i18n.Fprintf(out, "    added to the query string.  For other requests, they are\n")
+	i18n.Fprintf(out, "    sent as a JSON object in the body unless --input is set.\n")
+	i18n.Fprintf(out, "    Values that are JSON numbers, true, false, or null are\n")
+	i18n.Fprintf(out, "    sent as is; all other values are sent as strings.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "API Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewAPICommand returns a new, initialized APICommand instance.
+func NewAPICommand(
+	name string,
+	opts *APIOptions,
+	session *Session,
+) *APICommand {
+
+	// Create the new command.
+	cmd := &APICommand{
+		GitlabCommand: GitlabCommand[APIOptions]{
+			BasicCommand: BasicCommand[APIOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// apiMethods are the valid methods of the api command.
+var apiMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// ParseAPIFields parses the "key=value" fields.
+func ParseAPIFields(fields []string) ([][2]string, error) {
+	var result [][2]string
+	for _, field := range fields {
+		k, v, ok := strings.Cut(field, "=")
+		if !ok || k == "" {
+			return nil, i18n.Errorf("%w: invalid field: %q",
+				ErrInvalidOption, field)
+		}
+		result = append(result, [2]string{k, v})
+	}
+	return result, nil
+}
+
+// apiFieldValue returns the JSON value for the value of a field.
+// Values that are JSON numbers, true, false, or null are used as is;
+// all other values are strings.
+func apiFieldValue(v string) json.RawMessage {
+	var x any
+	if json.Unmarshal([]byte(v), &x) == nil {
+		switch x.(type) {
+		case nil, bool, float64:
+			return json.RawMessage(v)
+		}
+	}
+	result, _ := json.Marshal(v)
+	return result
+}
+
+// NewAPIRequest returns the request for the method and path with the
+// fields which are added to the query string for GET and DELETE
+// requests and to a JSON object in the body for other requests.  If
+// body is not nil, it is used as the body instead, and the fields are
+// added to the query string.
+func NewAPIRequest(
+	method string,
+	path string,
+	fields [][2]string,
+	body []byte,
+) (*gitlab_util.APIRequest, error) {
+	inQuery := method == http.MethodGet || method == http.MethodDelete
+
+	// Validate the body.
+	if body != nil {
+		if inQuery {
+			return nil, i18n.Errorf("%w: cannot send input with %s requests",
+				ErrInvalidOption, method)
+		}
+		if !json.Valid(body) {
+			return nil, i18n.Errorf("%w: input is not valid JSON",
+				ErrInvalidOption)
+		}
+	}
+
+	// Add the fields to the query string or the body.
+	request := &gitlab_util.APIRequest{
+		Method: method,
+		Path:   path,
+		Body:   body,
+	}
+	if inQuery || body != nil {
+		request.Query = url.Values{}
+		for _, f := range fields {
+			request.Query.Add(f[0], f[1])
+		}
+	} else if len(fields) > 0 {
+		obj := map[string]json.RawMessage{}
+		for _, f := range fields {
+			obj[f[0]] = apiFieldValue(f[1])
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		request.Body = b
+	}
+
+	return request, nil
+}
+
+// PrintAPIResponse pretty-prints the JSON response to the output
+// writer.  Responses that are not JSON are printed as is.
+func PrintAPIResponse(out io.Writer, body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if json.Indent(&buf, body, "", "  ") != nil {
+		buf.Reset()
+		buf.Write(body)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// Run is the entry point for this command.
+func (cmd *APICommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.  Unlike most commands, this
+	// command takes positional arguments so we cannot use
+	// cmd.parseFlags().  The flags are hoisted so they can also be
+	// placed after the positional arguments.
+	err = cmd.flags.Parse(hoistFlags(cmd.flags, args))
+	if err != nil {
+		return result, err
+	}
+	if cmd.flags.NArg() != 2 {
+		return result, i18n.Errorf("%w: expected <method> and <path>",
+			ErrInvalidOption)
+	}
+	method := strings.ToUpper(cmd.flags.Arg(0))
+	path := cmd.flags.Arg(1)
+
+	// Validate the options.
+	if !slices.Contains(apiMethods, method) {
+		return result, i18n.Errorf("%w: invalid method: %q",
+			ErrInvalidOption, cmd.flags.Arg(0))
+	}
+	if cmd.options.Paginate && method != http.MethodGet {
+		return result, i18n.Errorf("%w: cannot paginate %s requests",
+			ErrInvalidOption, method)
+	}
+	fields, err := ParseAPIFields(cmd.options.Fields)
+	if err != nil {
+		return result, err
+	}
+
+	// Read the body of the request.
+	var body []byte
+	switch cmd.options.InputFileName {
+	case "":
+	case "-":
+		body, err = io.ReadAll(os.Stdin)
+	default:
+		body, err = os.ReadFile(cmd.options.InputFileName)
+	}
+	if err != nil {
+		return result, err
+	}
+	request, err := NewAPIRequest(method, path, fields, body)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Send the request.
+	name := method + " " + path
+	var response []byte
+	if cmd.options.Paginate {
+		response, err = gitlab_util.DoAllAPIRequestPages(ctx, cmd.client, request)
+	} else {
+		response, _, err = gitlab_util.DoAPIRequest(ctx, cmd.client, request, 0)
+	}
+	if err != nil {
+		result.Fail(name, nil, err)
+		return result, err
+	}
+	result.Succeed(name, json.RawMessage(response))
+
+	return result, PrintAPIResponse(os.Stdout, response)
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/xanzy/go-gitlab"
+)
+
+// TestAPIIntegration tests sending raw requests with the "api" command.
+func TestAPIIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	group, _, err := server.Client(t).Groups.GetGroup("foo", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := func(args ...string) (string, error) {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewAPICommand("api", &APIOptions{}, session)
+		out, _, err := runCommand(t, cmd, args)
+		return out, err
+	}
+	paths := func(out string) []string {
+		var projects []gitlab.Project
+		err := json.Unmarshal([]byte(out), &projects)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var result []string
+		for _, p := range projects {
+			result = append(result, p.PathWithNamespace)
+		}
+		return result
+	}
+
+	// Get one page and then every page.
+	out, err := run("GET", "/api/v4/projects")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	onePage := paths(out)
+	out, err = run("get", "projects", "--paginate", "-f", "search=test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	allPages := paths(out)
+	out, err = run("get", "projects", "--paginate", "-f", "pagination=keyset")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keysetPages := paths(out)
+
+	// Create a project from fields and another from an input file.
+	_, err = run("POST", "projects",
+		"-f", fmt.Sprintf("namespace_id=%d,path=zeta,description=from fields", group.ID))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fileName := filepath.Join(t.TempDir(), "body.json")
+	body := fmt.Sprintf(`{"namespace_id": %d, "path": "eta"}`, group.ID)
+	err = os.WriteFile(fileName, []byte(body), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = run("--input", fileName, "POST", "projects")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Delete a project and handle errors.
+	_, err = run("DELETE", "projects/foo%2Fbeta")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, notFoundErr := run("GET", "projects/foo%2Fnope")
+	_, methodErr := run("HEAD", "projects")
+	_, paginateErr := run("POST", "projects", "--paginate")
+
+	// Verify the results.
+	var description string
+	if p := server.Project("foo/zeta"); p != nil {
+		description = p.Description
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"one page", []string{"foo/alpha", "foo/beta"}, onePage},
+		{"all pages", []string{"foo/test-gamma", "foo/bar/test-epsilon"}, allPages},
+		{"keyset pages", []string{"foo/alpha", "foo/beta", "foo/test-gamma",
+			"foo/bar/delta", "foo/bar/test-epsilon"}, keysetPages},
+		{"created from fields", "from fields", description},
+		{"created from input", true, server.Project("foo/eta") != nil},
+		{"deleted", true, server.Project("foo/beta") == nil},
+		{"not found", true, errors.Is(notFoundErr, gitlab_util.ErrNotFound)},
+		{"invalid method", true, errors.Is(methodErr, ErrInvalidOption)},
+		{"invalid paginate", true, errors.Is(paginateErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("api %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,643 @@
+// This file provides the implementation for the "apply" command which
+// converges the projects in a group to the desired state described by
+// a manifest file.  Like "terraform plan" followed by "terraform
+// apply", it first prints the plan of changes and then makes them.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ApplyOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ApplyOptions are the options needed by this command.
+type ApplyOptions struct {
+
+	// DryRun should cause the command to only print the plan instead
+	// of also making the changes.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ManifestFileName is the name of the XML, YAML, or JSON file
+	// holding the [Manifest] that describes the desired state.
+	// Defaults to "".
+	ManifestFileName string `xml:"manifest-file-name"`
+}
+
+// Initialize initializes this ApplyOptions instance so it can be used
+// with the "flag" package to parse the command-line arguments.
+func (opts *ApplyOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("only print the plan instead of also making the changes"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("only print the plan instead of also making the changes"))
+
+	// --manifest
+	flags.StringVar(&opts.ManifestFileName, "manifest", opts.ManifestFileName,
+		i18n.T("name of the XML, YAML, or JSON file that describes the desired state"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ApplyCommand
+////////////////////////////////////////////////////////////////////////
+
+// ApplyCommand implements the "apply" command which converges the
+// projects in a group to the desired state described by a manifest
+// file.
+type ApplyCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ApplyOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ApplyCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] apply [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Converges the projects in a group to the desired state\n")
+	i18n.Fprintf(out, "    described by the --manifest file.  The plan of changes is\n")
+	i18n.Fprintf(out, "    printed first and then the changes are made.  Use --dry-run\n")
+	i18n.Fprintf(out, "    to only print the plan.  Projects, members, protected\n")
+	i18n.Fprintf(out, "    branches, and approval rules that are not in the manifest\n")
+	i18n.Fprintf(out, "    are left alone.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Apply Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewApplyCommand returns a new, initialized ApplyCommand instance.
+func NewApplyCommand(
+	name string,
+	opts *ApplyOptions,
+	session *Session,
+) *ApplyCommand {
+
+	// Create the new command.
+	cmd := &ApplyCommand{
+		GitlabCommand: GitlabCommand[ApplyOptions]{
+			BasicCommand: BasicCommand[ApplyOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ApplyServices are the Gitlab services needed to apply a manifest.
+type ApplyServices struct {
+	Groups            gitlab_util.GroupFinder              /* was *gitlab.GroupsService */
+	Projects          ApplyProjectsService                 /* was *gitlab.ProjectsService */
+	ProjectMembers    MembersProjectMembersService         /* was *gitlab.ProjectMembersService */
+	ProtectedBranches gitlab_util.ProtectedBranchesManager /* was *gitlab.ProtectedBranchesService */
+	Users             gitlab_util.UserFinder               /* was *gitlab.UsersService */
+}
+
+// ApplyProjectsService is an abstraction of gitlab.ProjectsService
+// which gets, creates, and edits projects and their approval rules.
+type ApplyProjectsService interface {
+	gitlab_util.ProjectGetter
+	gitlab_util.ProjectCreator
+	gitlab_util.ProjectEditor
+	gitlab_util.ApprovalRulesGetter
+	gitlab_util.ApprovalRuleCreator
+	gitlab_util.ApprovalRuleUpdater
+}
+
+// ManifestChange is a single change needed to converge Gitlab to the
+// manifest.
+type ManifestChange struct {
+
+	// Name identifies what is changed in the result and the event
+	// hooks (e.g., "foo/alpha:aberns").
+	Name string
+
+	// Add is true if the change adds something new and false if it
+	// changes something that already exists.
+	Add bool
+
+	// Desc describes the change (e.g., "add member as developer").
+	Desc string
+
+	// Apply makes the change.
+	Apply func(ctx context.Context) error
+}
+
+// PlanManifest compares the manifest with Gitlab and returns the
+// changes needed to converge Gitlab to the manifest in the order in
+// which they must be made.  Nothing is changed.  All of the users in
+// the manifest must exist so a typo is caught before anything is
+// changed.
+func PlanManifest(
+	ctx context.Context,
+	s *ApplyServices,
+	manifest *Manifest,
+) ([]*ManifestChange, error) {
+	g, err := gitlab_util.FindExactGroup(ctx, s.Groups, manifest.Group)
+	if err != nil {
+		return nil, fmt.Errorf("PlanManifest: %w", err)
+	}
+	users, err := findManifestUsers(ctx, s.Users, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("PlanManifest: %w", err)
+	}
+	var changes []*ManifestChange
+	for _, mp := range manifest.Projects {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		cs, err := planManifestProject(ctx, s, g, mp, users)
+		if err != nil {
+			return nil, fmt.Errorf("PlanManifest: %w", err)
+		}
+		changes = append(changes, cs...)
+	}
+	return changes, nil
+}
+
+// findManifestUsers returns the users for the usernames of the members
+// and approvers in the manifest keyed by username.
+func findManifestUsers(
+	ctx context.Context,
+	s gitlab_util.UserFinder, /* was *gitlab.UsersService */
+	manifest *Manifest,
+) (map[string]*gitlab.User, error) {
+	result := map[string]*gitlab.User{}
+	find := func(username string) error {
+		if result[username] != nil {
+			return nil
+		}
+		users, err := gitlab_util.FindUsers(ctx, s, username, true, time.Time{})
+		if err != nil {
+			return err
+		}
+		result[username] = users[0]
+		return nil
+	}
+	for _, mp := range manifest.Projects {
+		for _, m := range mp.Members {
+			if err := find(m.Username); err != nil {
+				return nil, err
+			}
+		}
+		for _, rule := range mp.ApprovalRules {
+			for _, username := range rule.Approvers {
+				if err := find(username); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// planManifestProject returns the changes needed to converge the
+// project in the group to the manifest.
+func planManifestProject(
+	ctx context.Context,
+	s *ApplyServices,
+	g *gitlab.Group,
+	mp *ManifestProject,
+	users map[string]*gitlab.User,
+) ([]*ManifestChange, error) {
+	var changes []*ManifestChange
+	fullPath := g.FullPath + "/" + mp.Path
+
+	// Get the project.  If it does not exist yet, everything in the
+	// manifest is added after the project is created.  The project is
+	// always referred to by its full path so the changes work even
+	// though the ID of a new project is not known yet.
+	p, _, err := s.Projects.GetProject(fullPath, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		err = gitlab_util.ClassifyError(err)
+		if !errors.Is(err, gitlab_util.ErrNotFound) {
+			return nil, err
+		}
+		p = nil
+	}
+
+	// Create or update the project.
+	if p == nil {
+		namespace := g
+		if dir := path.Dir(mp.Path); dir != "." {
+			namespace, err = gitlab_util.FindExactGroup(
+				ctx, s.Groups, g.FullPath+"/"+dir)
+			if err != nil {
+				return nil, err
+			}
+		}
+		opts := newManifestCreateProjectOptions(namespace, mp)
+		changes = append(changes, &ManifestChange{
+			Name: fullPath,
+			Add:  true,
+			Desc: i18n.T("create project"),
+			Apply: func(ctx context.Context) error {
+				_, _, err := s.Projects.CreateProject(opts,
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+				return err
+			},
+		})
+	} else if opts, fields := newManifestEditProjectOptions(p, mp); len(fields) > 0 {
+		changes = append(changes, &ManifestChange{
+			Name: fullPath,
+			Desc: i18n.Sprintf("update %s", strings.Join(fields, ", ")),
+			Apply: func(ctx context.Context) error {
+				_, _, err := s.Projects.EditProject(fullPath, opts,
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+				return err
+			},
+		})
+	}
+
+	// Add the members or change their access.  Only the direct members
+	// are considered.
+	var members []*gitlab.ProjectMember
+	if p != nil && len(mp.Members) > 0 {
+		members, err = gitlab_util.GetAllProjectMembers(ctx, s.ProjectMembers, p.ID, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, m := range mp.Members {
+		u := users[m.Username]
+		level, err := ParseAccessLevel(m.AccessLevel)
+		if err != nil {
+			return nil, err
+		}
+		name := fullPath + ":" + m.Username
+		i := slices.IndexFunc(members, func(pm *gitlab.ProjectMember) bool {
+			return pm.ID == u.ID
+		})
+		if i < 0 {
+			changes = append(changes, &ManifestChange{
+				Name: name,
+				Add:  true,
+				Desc: i18n.Sprintf("add member as %s", gitlab_util.AccessLevelName(level)),
+				Apply: func(ctx context.Context) error {
+					_, _, err := s.ProjectMembers.AddProjectMember(fullPath,
+						&gitlab.AddProjectMemberOptions{
+							UserID:      u.ID,
+							AccessLevel: gitlab.Ptr(level),
+						},
+						gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+					return err
+				},
+			})
+		} else if members[i].AccessLevel != level {
+			changes = append(changes, &ManifestChange{
+				Name: name,
+				Desc: i18n.Sprintf("change access from %s to %s",
+					gitlab_util.AccessLevelName(members[i].AccessLevel),
+					gitlab_util.AccessLevelName(level)),
+				Apply: func(ctx context.Context) error {
+					_, _, err := s.ProjectMembers.EditProjectMember(fullPath, u.ID,
+						&gitlab.EditProjectMemberOptions{AccessLevel: gitlab.Ptr(level)},
+						gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+					return err
+				},
+			})
+		}
+	}
+
+	// Protect the branches or update their protection in place.
+	var branches []*gitlab.ProtectedBranch
+	if p != nil && len(mp.ProtectedBranches) > 0 {
+		branches, err = gitlab_util.GetAllProtectedBranches(ctx, s.ProtectedBranches, p.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, mb := range mp.ProtectedBranches {
+		want, err := mb.protection()
+		if err != nil {
+			return nil, err
+		}
+		name := fullPath + ":" + mb.Name
+		i := slices.IndexFunc(branches, func(b *gitlab.ProtectedBranch) bool {
+			return b.Name == mb.Name
+		})
+		if i < 0 {
+			opts := newProtectBranchOptions(mb.Name, want)
+			changes = append(changes, &ManifestChange{
+				Name: name,
+				Add:  true,
+				Desc: i18n.T("protect branch"),
+				Apply: func(ctx context.Context) error {
+					_, _, err := s.ProtectedBranches.ProtectRepositoryBranches(
+						fullPath, opts, gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+					return err
+				},
+			})
+		} else if *NewBranchProtection(branches[i]) != *want {
+			opts := newUpdateProtectedBranchOptions(branches[i], want)
+			changes = append(changes, &ManifestChange{
+				Name: name,
+				Desc: i18n.T("update branch protection"),
+				Apply: func(ctx context.Context) error {
+					_, _, err := s.ProtectedBranches.UpdateProtectedBranch(
+						fullPath, mb.Name, opts,
+						gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+					return err
+				},
+			})
+		}
+	}
+
+	// Create or update the approval rules.  Approval rules are only
+	// read when the manifest has some because Gitlab CE does not
+	// support them.
+	var rules []*gitlab.ProjectApprovalRule
+	if p != nil && len(mp.ApprovalRules) > 0 {
+		err = gitlab_util.ForEachApprovalRuleInProject(ctx, s.Projects, p,
+			func(rule *gitlab.ProjectApprovalRule) (bool, error) {
+				rules = append(rules, rule)
+				return true, nil
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, mr := range mp.ApprovalRules {
+		var userIDs []int
+		for _, username := range mr.Approvers {
+			userIDs = append(userIDs, users[username].ID)
+		}
+		approvers := slices.Clone([]string(mr.Approvers))
+		slices.Sort(approvers)
+		name := fullPath + ":" + mr.Name
+		i := slices.IndexFunc(rules, func(rule *gitlab.ProjectApprovalRule) bool {
+			return rule.Name == mr.Name
+		})
+		if i < 0 {
+			opts := &gitlab.CreateProjectLevelRuleOptions{
+				Name:              gitlab.Ptr(mr.Name),
+				ApprovalsRequired: gitlab.Ptr(mr.ApprovalsRequired),
+				UserIDs:           &userIDs,
+			}
+			changes = append(changes, &ManifestChange{
+				Name: name,
+				Add:  true,
+				Desc: i18n.T("create approval rule"),
+				Apply: func(ctx context.Context) error {
+					_, _, err := s.Projects.CreateProjectApprovalRule(
+						fullPath, opts, gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+					return err
+				},
+			})
+		} else if rules[i].ApprovalsRequired != mr.ApprovalsRequired ||
+			!slices.Equal(gitlab_util.GetApprovalRuleUsernames(rules[i]), approvers) {
+
+			// Keep the groups and protected branches of the rule.
+			rule := *rules[i]
+			rule.ApprovalsRequired = mr.ApprovalsRequired
+			changes = append(changes, &ManifestChange{
+				Name: name,
+				Desc: i18n.T("update approval rule"),
+				Apply: func(ctx context.Context) error {
+					_, err := gitlab_util.UpdateApprovalRule(
+						ctx, s.Projects, p.ID, &rule, userIDs)
+					return err
+				},
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// protection returns the settings of the protected branch.
+func (mb *ManifestProtectedBranch) protection() (*BranchProtection, error) {
+	pushAccessLevel, err := ParseBranchAccessLevel(mb.PushAccessLevel)
+	if err != nil {
+		return nil, err
+	}
+	mergeAccessLevel, err := ParseBranchAccessLevel(mb.MergeAccessLevel)
+	if err != nil {
+		return nil, err
+	}
+	return &BranchProtection{
+		PushAccessLevel:           pushAccessLevel,
+		MergeAccessLevel:          mergeAccessLevel,
+		AllowForcePush:            mb.AllowForcePush,
+		CodeOwnerApprovalRequired: mb.CodeOwnerApproval,
+	}, nil
+}
+
+// newManifestCreateProjectOptions returns the options that create the
+// project in the namespace with the settings in the manifest.
+func newManifestCreateProjectOptions(
+	namespace *gitlab.Group,
+	mp *ManifestProject,
+) *gitlab.CreateProjectOptions {
+	opts := &gitlab.CreateProjectOptions{
+		NamespaceID: gitlab.Ptr(namespace.ID),
+		Path:        gitlab.Ptr(path.Base(mp.Path)),
+
+		OnlyAllowMergeIfAllDiscussionsAreResolved: mp.OnlyAllowMergeIfAllDiscussionsAreResolved,
+		OnlyAllowMergeIfPipelineSucceeds:          mp.OnlyAllowMergeIfPipelineSucceeds,
+		RemoveSourceBranchAfterMerge:              mp.RemoveSourceBranchAfterMerge,
+	}
+	if mp.Name != "" {
+		opts.Name = gitlab.Ptr(mp.Name)
+	}
+	if mp.Description != "" {
+		opts.Description = gitlab.Ptr(mp.Description)
+	}
+	if mp.Visibility != "" {
+		opts.Visibility = gitlab.Ptr(gitlab.VisibilityValue(mp.Visibility))
+	}
+	if mp.DefaultBranch != "" {
+		opts.DefaultBranch = gitlab.Ptr(mp.DefaultBranch)
+	}
+	return opts
+}
+
+// newManifestEditProjectOptions returns the options that give the
+// project the settings in the manifest along with the names of the
+// settings that differ.  If no settings differ, the returned list of
+// names is empty.
+func newManifestEditProjectOptions(
+	p *gitlab.Project,
+	mp *ManifestProject,
+) (*gitlab.EditProjectOptions, []string) {
+	var fields []string
+	opts := &gitlab.EditProjectOptions{}
+	if mp.Name != "" && mp.Name != p.Name {
+		opts.Name = gitlab.Ptr(mp.Name)
+		fields = append(fields, "name")
+	}
+	if mp.Description != "" && mp.Description != p.Description {
+		opts.Description = gitlab.Ptr(mp.Description)
+		fields = append(fields, "description")
+	}
+	if mp.Visibility != "" && gitlab.VisibilityValue(mp.Visibility) != p.Visibility {
+		opts.Visibility = gitlab.Ptr(gitlab.VisibilityValue(mp.Visibility))
+		fields = append(fields, "visibility")
+	}
+	if mp.DefaultBranch != "" && mp.DefaultBranch != p.DefaultBranch {
+		opts.DefaultBranch = gitlab.Ptr(mp.DefaultBranch)
+		fields = append(fields, "default-branch")
+	}
+	if mp.OnlyAllowMergeIfPipelineSucceeds != nil &&
+		*mp.OnlyAllowMergeIfPipelineSucceeds != p.OnlyAllowMergeIfPipelineSucceeds {
+		opts.OnlyAllowMergeIfPipelineSucceeds = mp.OnlyAllowMergeIfPipelineSucceeds
+		fields = append(fields, "only-allow-merge-if-pipeline-succeeds")
+	}
+	if mp.OnlyAllowMergeIfAllDiscussionsAreResolved != nil &&
+		*mp.OnlyAllowMergeIfAllDiscussionsAreResolved != p.OnlyAllowMergeIfAllDiscussionsAreResolved {
+		opts.OnlyAllowMergeIfAllDiscussionsAreResolved = mp.OnlyAllowMergeIfAllDiscussionsAreResolved
+		fields = append(fields, "only-allow-merge-if-all-discussions-are-resolved")
+	}
+	if mp.RemoveSourceBranchAfterMerge != nil &&
+		*mp.RemoveSourceBranchAfterMerge != p.RemoveSourceBranchAfterMerge {
+		opts.RemoveSourceBranchAfterMerge = mp.RemoveSourceBranchAfterMerge
+		fields = append(fields, "remove-source-branch-after-merge")
+	}
+	return opts, fields
+}
+
+// PrintPlan prints the changes with a "+" in front of the changes
+// that add something and a "~" in front of the changes that change
+// something followed by a summary.
+func PrintPlan(out io.Writer, changes []*ManifestChange) {
+	if len(changes) == 0 {
+		i18n.Fprintf(out, "No changes.  Gitlab already matches the manifest.\n")
+		return
+	}
+	added := 0
+	i18n.Fprintf(out, "Plan:\n")
+	fmt.Fprintf(out, "\n")
+	for _, c := range changes {
+		sign := "~"
+		if c.Add {
+			sign = "+"
+			added++
+		}
+		fmt.Fprintf(out, "  %s %s: %s\n", sign, c.Name, c.Desc)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Plan: %d to add, %d to change.\n", added, len(changes)-added)
+}
+
+// Run is the entry point for this command.
+func (cmd *ApplyCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.ManifestFileName == "" {
+		return result, i18n.Errorf("%w: manifest not set", ErrInvalidOption)
+	}
+
+	// Load the manifest.
+	manifest, err := LoadManifest(cmd.options.ManifestFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Print the plan.
+	changes, err := PlanManifest(ctx, &ApplyServices{
+		Groups:            cmd.client.Groups,
+		Projects:          cmd.client.Projects,
+		ProjectMembers:    cmd.client.ProjectMembers,
+		ProtectedBranches: cmd.client.ProtectedBranches,
+		Users:             cmd.client.Users,
+	}, manifest)
+	if err != nil {
+		return result, err
+	}
+	PrintPlan(os.Stdout, changes)
+	if cmd.options.DryRun || len(changes) == 0 {
+		return result, nil
+	}
+
+	// Make the changes.  A change that fails is recorded in the
+	// result, and the remaining changes are still made.
+	fmt.Fprintf(os.Stdout, "\n")
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, c := range changes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		hook.OnItemStart(c.Name)
+		logging.Printf("- %s: %s ... ", c.Name, c.Desc)
+		err := c.Apply(gitlab_util.Uninterruptible(ctx))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			err = fmt.Errorf("Apply: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(c.Name, err)
+			result.Fail(c.Name, nil, err)
+			continue
+		}
+		logging.Printf("Done.\n")
+		hook.OnItemDone(c.Name)
+		result.Succeed(c.Name, nil)
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not make %d change(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestApplyIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddProjectMember("foo/beta", "aberns", gitlab.ReporterPermissions)
+	server.AddProtectedBranch("foo/beta", "main")
+	server.AddApprovalRule("foo/beta", "reviewers", 1, "aberns")
+	session := NewSessionWithClient(server.Client(t))
+	manifestFileName := filepath.Join(t.TempDir(), "manifest.yaml")
+	err := os.WriteFile(manifestFileName, []byte(`
+group: foo
+projects:
+  - path: beta
+    description: Beta project
+    visibility: internal
+    only-allow-merge-if-pipeline-succeeds: true
+    members:
+      - username: aberns
+        access-level: developer
+      - username: bcrocket
+        access-level: maintainer
+    protected-branches:
+      - name: main
+        push-access-level: developer
+    approval-rules:
+      - name: reviewers
+        approvals-required: 2
+        approvers: [aberns, bcrocket]
+  - path: bar/zeta
+    visibility: private
+    members:
+      - username: aberns
+        access-level: guest
+    protected-branches:
+      - name: release/*
+`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// run runs the "apply" command and returns its output and the
+	// number of changes made.
+	run := func(args ...string) (string, int) {
+		cmd := NewApplyCommand("apply", &ApplyOptions{}, session)
+		out, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("apply %v: unexpected error: %v", args, err)
+		}
+		return out, len(result.Succeeded())
+	}
+
+	// Verify --dry-run only prints the plan.
+	out, changed := run("--manifest", manifestFileName, "--dry-run")
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"dry-run changed", 0, changed},
+		{"dry-run summary", true, strings.Contains(out, "Plan: 4 to add, 4 to change.")},
+		{"dry-run update", true, strings.Contains(out,
+			"~ foo/beta: update description, visibility, only-allow-merge-if-pipeline-succeeds")},
+		{"dry-run access", true, strings.Contains(out,
+			"~ foo/beta:aberns: change access from Reporter to Developer")},
+		{"dry-run create", true, strings.Contains(out, "+ foo/bar/zeta: create project")},
+		{"dry-run project", nil, server.Project("foo/bar/zeta")},
+	}
+
+	// Verify the changes are made.
+	out, changed = run("--manifest", manifestFileName)
+	beta := server.Project("foo/beta")
+	rule := server.ApprovalRules("foo/beta")[0]
+	data = append(data, Data{
+		{"changed", 8, changed},
+		{"description", "Beta project", beta.Description},
+		{"visibility", gitlab.InternalVisibility, beta.Visibility},
+		{"pipeline", true, beta.OnlyAllowMergeIfPipelineSucceeds},
+		{"aberns", gitlab.DeveloperPermissions,
+			server.MemberAccessLevel("project", "foo/beta", "aberns")},
+		{"bcrocket", gitlab.MaintainerPermissions,
+			server.MemberAccessLevel("project", "foo/beta", "bcrocket")},
+		{"main", BranchProtection{
+			PushAccessLevel:  gitlab.DeveloperPermissions,
+			MergeAccessLevel: gitlab.MaintainerPermissions,
+		}, *NewBranchProtection(server.ProtectedBranch("foo/beta", "main"))},
+		{"rule approvals", 2, rule.ApprovalsRequired},
+		{"rule approvers", "[aberns bcrocket]", gitlab_util.GetApprovalRuleUsernames(rule)},
+		{"zeta visibility", gitlab.PrivateVisibility, server.Project("foo/bar/zeta").Visibility},
+		{"zeta members", "[aberns]", server.Members("project", "foo/bar/zeta")},
+		{"zeta branches", "[release/*]", server.ProtectedBranches("foo/bar/zeta")},
+	}...)
+
+	// Verify applying the manifest again changes nothing.
+	out, changed = run("--manifest", manifestFileName)
+	data = append(data, Data{
+		{"again changed", 0, changed},
+		{"again output", true, strings.Contains(out, "No changes.")},
+	}...)
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("%s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the invalid manifests.
+	for _, manifest := range []string{
+		"<manifest><projects><project><path>beta</path></project></projects></manifest>",
+		"<manifest><group>foo</group><projects><project><path>beta</path>" +
+			"<visibility>secret</visibility></project></projects></manifest>",
+		"<manifest><group>foo</group><projects><project><path>beta</path>" +
+			"<members><member><username>aberns</username><access-level>boss</access-level>" +
+			"</member></members></project></projects></manifest>",
+	} {
+		fileName := filepath.Join(t.TempDir(), "manifest.xml")
+		err := os.WriteFile(fileName, []byte(manifest), 0600)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cmd := NewApplyCommand("apply", &ApplyOptions{}, session)
+		_, err = cmd.Run(context.Background(), []string{"--manifest", fileName})
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("apply %s: expected=%v  actual=%v", manifest, ErrInvalidOption, err)
+		}
+	}
+}
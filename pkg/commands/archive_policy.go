@@ -0,0 +1,103 @@
+// This file provides the policy file that describes when the "projects
+// enforce-archive-policy" command warns about and archives inactive
+// projects.
+
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// ArchivePolicy describes when inactive projects are archived.  A
+// project without activity for InactiveMonths gets a warning issue
+// having the Label.  If the project is still inactive GraceDays after
+// the warning was filed, it is archived.  For example:
+//
+//	<archive-policy>
+//	  <inactive-months>12</inactive-months>
+//	  <grace-days>30</grace-days>
+//	</archive-policy>
+type ArchivePolicy struct {
+	XMLName xml.Name `xml:"archive-policy"`
+
+	// InactiveMonths is the number of months without activity after
+	// which a warning is filed.  Defaults to 12.
+	InactiveMonths int `xml:"inactive-months"`
+
+	// GraceDays is the number of days after the warning is filed
+	// before the project is archived if it is still inactive.  Zero
+	// means the project is archived on the next run.
+	GraceDays int `xml:"grace-days"`
+
+	// Label is the label of the warning issue which is how the
+	// warning is found on later runs.  Defaults to "archive-policy".
+	Label string `xml:"label"`
+
+	// IssueTitle is the title of the warning issue.  Defaults to
+	// "This project will be archived due to inactivity".
+	IssueTitle string `xml:"issue-title"`
+
+	// IssueDescription is the description of the warning issue.
+	// Defaults to a short explanation that includes the number of
+	// inactive months and grace days.
+	IssueDescription string `xml:"issue-description"`
+}
+
+// LoadArchivePolicy loads and validates the archive policy from the
+// file.  Leading and trailing white space is removed from the title
+// and description of the warning issue.
+func LoadArchivePolicy(fileName string) (*ArchivePolicy, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("LoadArchivePolicy: %w", err)
+	}
+	policy := &ArchivePolicy{}
+	err = xml.Unmarshal(data, policy)
+	if err != nil {
+		return nil, fmt.Errorf("LoadArchivePolicy: %s: %w", fileName, err)
+	}
+	policy.IssueTitle = strings.TrimSpace(policy.IssueTitle)
+	policy.IssueDescription = strings.TrimSpace(policy.IssueDescription)
+	policy.SetDefaults()
+	err = policy.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("LoadArchivePolicy: %s: %w", fileName, err)
+	}
+	return policy, nil
+}
+
+// SetDefaults sets the defaults of the fields that are not set.
+func (policy *ArchivePolicy) SetDefaults() {
+	if policy.InactiveMonths == 0 {
+		policy.InactiveMonths = 12
+	}
+	if policy.Label == "" {
+		policy.Label = "archive-policy"
+	}
+	if policy.IssueTitle == "" {
+		policy.IssueTitle = "This project will be archived due to inactivity"
+	}
+	if policy.IssueDescription == "" {
+		policy.IssueDescription = fmt.Sprintf(
+			"This project has had no activity for at least %d month(s).  "+
+				"Unless there is new activity, it will be archived in %d day(s).",
+			policy.InactiveMonths, policy.GraceDays)
+	}
+}
+
+// Validate returns an error if the number of inactive months or grace
+// days is negative.
+func (policy *ArchivePolicy) Validate() error {
+	if policy.InactiveMonths < 0 {
+		return i18n.Errorf("invalid inactive-months: %v", policy.InactiveMonths)
+	}
+	if policy.GraceDays < 0 {
+		return i18n.Errorf("invalid grace-days: %v", policy.GraceDays)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+// This file provides the types and functions shared by the "branches"
+// commands that list, clean up, protect, and unprotect branches across
+// the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+// branchName returns the name that identifies the branch of the
+// project in the result.
+func branchName(p *gitlab.Project, name string) string {
+	return p.PathWithNamespace + ":" + name
+}
+
+// branchStates returns the comma-separated states of the branch (i.e.,
+// "default", "protected", and "merged") or "-" if it has none of them.
+func branchStates(b *gitlab.Branch) string {
+	var states []string
+	if b.Default {
+		states = append(states, "default")
+	}
+	if b.Protected {
+		states = append(states, "protected")
+	}
+	if b.Merged {
+		states = append(states, "merged")
+	}
+	if len(states) == 0 {
+		return "-"
+	}
+	return strings.Join(states, ",")
+}
+
+// branchCommittedAt returns the time of the last commit on the branch
+// or the zero time if it is not known.
+func branchCommittedAt(b *gitlab.Branch) time.Time {
+	if b.Commit == nil || b.Commit.CommittedDate == nil {
+		return time.Time{}
+	}
+	return *b.Commit.CommittedDate
+}
+
+// BranchAccessLevels maps the names accepted by --push-access-level
+// and --merge-access-level to the access levels that can be allowed
+// to push to or merge into a protected branch.
+var BranchAccessLevels = map[string]gitlab.AccessLevelValue{
+	"no-one":     gitlab.NoPermissions,
+	"developer":  gitlab.DeveloperPermissions,
+	"maintainer": gitlab.MaintainerPermissions,
+	"admin":      gitlab.AdminPermissions,
+}
+
+// ParseBranchAccessLevel returns the access level having the name.
+// Only the names in BranchAccessLevels are valid.
+func ParseBranchAccessLevel(name string) (gitlab.AccessLevelValue, error) {
+	level, ok := BranchAccessLevels[strings.ToLower(name)]
+	if !ok {
+		return 0, i18n.Errorf("%w: invalid branch access level: %q",
+			ErrInvalidOption, name)
+	}
+	return level, nil
+}
+
+// branchAccessLevelName returns the name of the access level as
+// accepted by ParseBranchAccessLevel().
+func branchAccessLevelName(level gitlab.AccessLevelValue) string {
+	for name, l := range BranchAccessLevels {
+		if l == level {
+			return name
+		}
+	}
+	return gitlab_util.AccessLevelName(level)
+}
+
+// BranchProtection holds the settings of a protected branch that the
+// "branches" commands manage.
+type BranchProtection struct {
+
+	// PushAccessLevel is the access level allowed to push.
+	PushAccessLevel gitlab.AccessLevelValue
+
+	// MergeAccessLevel is the access level allowed to merge.
+	MergeAccessLevel gitlab.AccessLevelValue
+
+	// AllowForcePush is whether force pushes are allowed.
+	AllowForcePush bool
+
+	// CodeOwnerApprovalRequired is whether changes to files with a
+	// code owner must be approved by the code owner.
+	CodeOwnerApprovalRequired bool
+}
+
+// NewBranchProtection returns the settings of the protected branch.
+// Only the role-based access levels are considered.  Access granted to
+// individual users or groups is ignored.
+func NewBranchProtection(b *gitlab.ProtectedBranch) *BranchProtection {
+	return &BranchProtection{
+		PushAccessLevel:           roleAccessLevel(b.PushAccessLevels),
+		MergeAccessLevel:          roleAccessLevel(b.MergeAccessLevels),
+		AllowForcePush:            b.AllowForcePush,
+		CodeOwnerApprovalRequired: b.CodeOwnerApprovalRequired,
+	}
+}
+
+// roleAccessLevel returns the role-based access level in ds or
+// gitlab.NoPermissions if there is none.
+func roleAccessLevel(ds []*gitlab.BranchAccessDescription) gitlab.AccessLevelValue {
+	for _, d := range ds {
+		if d.UserID == 0 && d.GroupID == 0 {
+			return d.AccessLevel
+		}
+	}
+	return gitlab.NoPermissions
+}
+
+// FindProtectedBranch returns the protection of the branch in the
+// project or nil if the branch is not protected.  The name can be a
+// wildcard (e.g., "release/*") which must match the name of the
+// protection exactly.
+func FindProtectedBranch(
+	ctx context.Context,
+	s gitlab_util.ProtectedBranchesLister, /* was *gitlab.ProtectedBranchesService */
+	p *gitlab.Project,
+	name string,
+) (*gitlab.ProtectedBranch, error) {
+	bs, err := gitlab_util.GetAllProtectedBranches(ctx, s, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("FindProtectedBranch: %w", err)
+	}
+	for _, b := range bs {
+		if b.Name == name {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+// diffBranchProtection prints the differences between the old and new
+// protection of the branch in the project to out.  If old is nil, the
+// branch is not protected yet.  If new is nil, the branch will be
+// unprotected.
+func diffBranchProtection(
+	out io.Writer,
+	p *gitlab.Project,
+	name string,
+	old *BranchProtection,
+	new *BranchProtection,
+) {
+	i18n.Fprintf(out, "    Branch %q in %q:\n", name, p.PathWithNamespace)
+	if (old == nil && new == nil) || (old != nil && new != nil && *old == *new) {
+		i18n.Fprintf(out, "        No changes.\n")
+		return
+	}
+	settings := func(b *BranchProtection) []string {
+		if b == nil {
+			return []string{"", "", "", ""}
+		}
+		return []string{
+			branchAccessLevelName(b.PushAccessLevel),
+			branchAccessLevelName(b.MergeAccessLevel),
+			fmt.Sprint(b.AllowForcePush),
+			fmt.Sprint(b.CodeOwnerApprovalRequired),
+		}
+	}
+	labels := []string{
+		"push-access-level",
+		"merge-access-level",
+		"allow-force-push",
+		"code-owner-approval",
+	}
+	olds, news := settings(old), settings(new)
+	for i, label := range labels {
+		if olds[i] == news[i] {
+			continue
+		}
+		if old != nil {
+			fmt.Fprintf(out, "        - %s: %s\n", label, olds[i])
+		}
+		if new != nil {
+			fmt.Fprintf(out, "        + %s: %s\n", label, news[i])
+		}
+	}
+}
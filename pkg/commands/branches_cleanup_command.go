@@ -0,0 +1,344 @@
+// This file provides the implementation for the "branches cleanup"
+// command which deletes the branches already merged into the default
+// branch across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// BranchesCleanupOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// BranchesCleanupOptions are the options needed by this command.
+type BranchesCleanupOptions struct {
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// Embed the options that control whether the remaining branches
+	// are deleted after a branch cannot be deleted.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// OlderThan is how long ago the last commit on a merged branch
+	// must have been for the branch to be deleted (e.g., "30d", "2w",
+	// or "12h").  Defaults to "" which deletes merged branches of any
+	// age.
+	OlderThan string `xml:"older-than"`
+}
+
+// Initialize initializes this BranchesCleanupOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *BranchesCleanupOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --older-than
+	flags.StringVar(&opts.OlderThan, "older-than", opts.OlderThan,
+		i18n.T("only delete merged branches whose last commit is older than "+
+			"this (e.g., 30d, 2w, or 12h)"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// BranchesCleanupCommand
+////////////////////////////////////////////////////////////////////////
+
+// BranchesCleanupCommand implements the "branches cleanup" command
+// which deletes the branches already merged into the default branch
+// across the projects in a group.
+type BranchesCleanupCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[BranchesCleanupOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *BranchesCleanupCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] branches cleanup [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Delete the branches of the selected projects that are already\n")
+	i18n.Fprintf(out, "    merged into the default branch.  The default branch and\n")
+	i18n.Fprintf(out, "    protected branches are never deleted.  If --older-than is\n")
+	i18n.Fprintf(out, "    set, only branches whose last commit is older are deleted.\n")
+	i18n.Fprintf(out, "    The branches are listed and must be confirmed unless the\n")
+	i18n.Fprintf(out, "    global --yes option is passed.  When deleting more branches\n")
+	i18n.Fprintf(out, "    than --confirm-threshold, --i-understand must be passed,\n")
+	i18n.Fprintf(out, "    and the group must be typed to confirm.  The command stops\n")
+	i18n.Fprintf(out, "    at the first branch that cannot be deleted unless\n")
+	i18n.Fprintf(out, "    --keep-going is passed.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Cleanup Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewBranchesCleanupCommand returns a new, initialized
+// BranchesCleanupCommand instance.
+func NewBranchesCleanupCommand(
+	name string,
+	opts *BranchesCleanupOptions,
+	session *Session,
+) *BranchesCleanupCommand {
+
+	// Create the new command.
+	cmd := &BranchesCleanupCommand{
+		GitlabCommand: GitlabCommand[BranchesCleanupOptions]{
+			BasicCommand: BasicCommand[BranchesCleanupOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectBranch is a branch of a project.
+type ProjectBranch struct {
+
+	// Project is the project that has the branch.
+	Project *gitlab.Project
+
+	// Branch is the branch.
+	Branch *gitlab.Branch
+}
+
+// IsMergedBranchToCleanUp returns whether the branch is deleted by
+// "branches cleanup" which is the case if it has been merged into the
+// default branch, it is neither the default branch nor protected, and
+// its last commit is before the cutoff.  If cutoff is the zero time,
+// the age of the branch does not matter.
+func IsMergedBranchToCleanUp(b *gitlab.Branch, cutoff time.Time) bool {
+	if !b.Merged || b.Default || b.Protected {
+		return false
+	}
+	if cutoff.IsZero() {
+		return true
+	}
+	committed := branchCommittedAt(b)
+	return !committed.IsZero() && committed.Before(cutoff)
+}
+
+// GetMergedBranchesToCleanUp returns the branches of the selected
+// projects for which IsMergedBranchToCleanUp() returns true.
+func GetMergedBranchesToCleanUp(
+	ctx context.Context,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	branches gitlab_util.BranchesLister, /* was *gitlab.BranchesService */
+	selector *ProjectSelectorOptions,
+	cutoff time.Time,
+) ([]*ProjectBranch, error) {
+	var result []*ProjectBranch
+	err := selector.ForEachProject(ctx, groups,
+		func(p *gitlab.Project) (bool, error) {
+			bs, err := gitlab_util.GetAllBranches(ctx, branches, p.ID)
+			if err != nil {
+				return false, err
+			}
+			for _, b := range bs {
+				if IsMergedBranchToCleanUp(b, cutoff) {
+					result = append(result, &ProjectBranch{Project: p, Branch: b})
+				}
+			}
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("GetMergedBranchesToCleanUp: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteBranch deletes the branch of the project.  If dryRun is true,
+// this function only prints what it would without actually doing it.
+func DeleteBranch(
+	ctx context.Context,
+	s gitlab_util.BranchDeleter, /* was *gitlab.BranchesService */
+	p *gitlab.Project,
+	name string,
+	dryRun bool,
+) error {
+	logging.Printf("- Deleting branch %q of %q ... ", name, p.PathWithNamespace)
+	if !dryRun {
+		_, err := s.DeleteBranch(p.ID, name,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("DeleteBranch: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// CleanupBranches deletes the branches stopping after the current
+// branch if interrupted.  If keepGoing is true, a branch that cannot
+// be deleted is recorded in the result, and the remaining branches
+// are still deleted.  Otherwise, no branch is deleted after the first
+// one that fails.  If dryRun is true, this function only prints what
+// it would do without actually doing it.
+func CleanupBranches(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.BranchDeleter, /* was *gitlab.BranchesService */
+	branches []*ProjectBranch,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, pb := range branches {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("CleanupBranches: %w", err)
+		}
+		name := branchName(pb.Project, pb.Branch.Name)
+		hook.OnItemStart(name)
+		err := DeleteBranch(ctx, s, pb.Project, pb.Branch.Name, dryRun)
+		if err != nil {
+			hook.OnError(name, err)
+			result.Fail(name, pb.Branch, err)
+			if !keepGoing {
+				return fmt.Errorf("CleanupBranches: %w", err)
+			}
+			continue
+		}
+		hook.OnItemDone(name)
+		result.Succeed(name, pb.Branch)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *BranchesCleanupCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	var cutoff time.Time
+	if cmd.options.OlderThan != "" {
+		age, err := ParseAge(cmd.options.OlderThan)
+		if err != nil {
+			return result, err
+		}
+		cutoff = time.Now().Add(-age)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Collect the merged branches.
+	logging.Printf("- Collecting merged branches ... ")
+	branches, err := GetMergedBranchesToCleanUp(ctx, cmd.client.Groups,
+		cmd.client.Branches, &cmd.options.ProjectSelectorOptions, cutoff)
+	if err != nil {
+		logging.Printf("Failed.\n")
+		return result, err
+	}
+	logging.Printf("Done.\n")
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, pb := range branches {
+			names = append(names, branchName(pb.Project, pb.Branch.Name))
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"), names,
+			cmd.options.Group, cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Delete the branches.
+	err = CleanupBranches(ctx, result, cmd.client.Branches, branches,
+		cmd.options.KeepGoing, cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not delete %d branch(es)")
+	return result, err
+}
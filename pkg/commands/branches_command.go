@@ -0,0 +1,159 @@
+// This file provides the implementation for the "branches" command
+// which provides branch related subcommands.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      BranchesCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// BranchesOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// BranchesOptions are the options needed by this command.
+type BranchesOptions struct {
+
+	// Options for the "branches cleanup" command.
+	BranchesCleanupOpts BranchesCleanupOptions `xml:"cleanup-options"`
+
+	// Options for the "branches list" command.
+	BranchesListOpts BranchesListOptions `xml:"list-options"`
+
+	// Options for the "branches protect" command.
+	BranchesProtectOpts BranchesProtectOptions `xml:"protect-options"`
+
+	// Options for the "branches unprotect" command.
+	BranchesUnprotectOpts BranchesUnprotectOptions `xml:"unprotect-options"`
+}
+
+// Initialize initializes this BranchesOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *BranchesOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// BranchesCommand
+////////////////////////////////////////////////////////////////////////
+
+// BranchesCommand provides subcommands for administering the branches
+// and protected branches of Gitlab projects.
+type BranchesCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[BranchesOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *BranchesCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] branches [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for administering branches and protected branches\n")
+	i18n.Fprintf(out, "    of Gitlab projects.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *BranchesCommand) addSubcmds(session *Session) {
+	cmd.subcmds["cleanup"] = NewBranchesCleanupCommand(
+		"cleanup", &cmd.options.BranchesCleanupOpts, session)
+	cmd.subcmds["list"] = NewBranchesListCommand(
+		"list", &cmd.options.BranchesListOpts, session)
+	cmd.subcmds["protect"] = NewBranchesProtectCommand(
+		"protect", &cmd.options.BranchesProtectOpts, session)
+	cmd.subcmds["unprotect"] = NewBranchesUnprotectCommand(
+		"unprotect", &cmd.options.BranchesUnprotectOpts, session)
+}
+
+// NewBranchesCommand returns a new, initialized BranchesCommand
+// instance having the specified name.
+func NewBranchesCommand(
+	name string,
+	opts *BranchesOptions,
+	session *Session,
+) *BranchesCommand {
+
+	// Create the new command.
+	cmd := &BranchesCommand{
+		ParentCommand: ParentCommand[BranchesOptions]{
+			BasicCommand: BasicCommand[BranchesOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *BranchesCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestBranchesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddProtectedBranch("foo/beta", "main")
+	session := NewSessionWithClient(server.Client(t))
+
+	// run runs the "branches" subcommand and returns its output and
+	// the names of the projects that succeeded.
+	run := func(args ...string) (string, []string) {
+		cmd := NewBranchesCommand("branches", &BranchesOptions{}, session)
+		out, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("branches %v: unexpected error: %v", args, err)
+		}
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return out, names
+	}
+
+	// Verify the commands.
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"protect", "--group", "foo", "--expr", "alpha|beta",
+			"--branch", "main", "--push-access-level", "developer"},
+			[]string{"foo/alpha", "foo/beta"}},
+		{[]string{"protect", "--group", "foo", "--expr", "alpha",
+			"--branch", "release/*", "--allow-force-push"},
+			[]string{"foo/alpha"}},
+		{[]string{"protect", "--group", "foo", "--expr", "beta",
+			"--branch", "main", "--code-owner-approval", "--dry-run"},
+			[]string{"foo/beta"}},
+		{[]string{"unprotect", "--group", "foo", "--expr", "alpha|beta",
+			"--branch", "main", "-n"},
+			[]string{"foo/alpha", "foo/beta"}},
+		{[]string{"unprotect", "--group", "foo", "--expr", "alpha|beta",
+			"--branch", "release/*"},
+			[]string{"foo/alpha"}},
+	}
+	for _, d := range data {
+		_, actual := run(d.args...)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("branches %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+	}
+
+	// Verify the protection.
+	expected := BranchProtection{
+		PushAccessLevel:  gitlab.DeveloperPermissions,
+		MergeAccessLevel: gitlab.MaintainerPermissions,
+	}
+	for _, project := range []string{"foo/alpha", "foo/beta"} {
+		b := server.ProtectedBranch(project, "main")
+		if b == nil || *NewBranchProtection(b) != expected {
+			t.Errorf("branches protect %s: expected=%v  actual=%v", project, expected, b)
+		}
+	}
+	if b := server.ProtectedBranch("foo/alpha", "release/*"); b != nil {
+		t.Errorf("branches unprotect: expected=%v  actual=%v", nil, b)
+	}
+
+	// Verify --diff only prints the changes.
+	out, _ := run("protect", "--group", "foo", "--expr", "beta",
+		"--branch", "main", "--push-access-level", "developer",
+		"--merge-access-level", "developer", "--diff")
+	for _, line := range []string{
+		"- merge-access-level: maintainer",
+		"+ merge-access-level: developer",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("branches protect --diff: expected %q in %q", line, out)
+		}
+	}
+	if strings.Contains(out, "push-access-level") {
+		t.Errorf("branches protect --diff: unexpected push-access-level in %q", out)
+	}
+	if b := server.ProtectedBranch("foo/beta", "main"); *NewBranchProtection(b) != expected {
+		t.Errorf("branches protect --diff: expected=%v  actual=%v", expected, b)
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"protect", "--group", "foo"},
+		{"protect", "--group", "foo", "--branch", "main", "--push-access-level", "owner"},
+		{"unprotect", "--group", "foo"},
+	} {
+		cmd := NewBranchesCommand("branches", &BranchesOptions{}, session)
+		_, err := cmd.Run(context.Background(), args)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("branches %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
+
+func TestBranchesListAndCleanupIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	now := time.Now()
+	old := now.AddDate(0, 0, -60)
+	recent := now.AddDate(0, 0, -1)
+	server.SetDefaultBranch("foo/alpha", "main")
+	server.AddBranch("foo/alpha", "main", false, recent)
+	server.AddBranch("foo/alpha", "feature/old", true, old)
+	server.AddBranch("foo/alpha", "feature/new", true, recent)
+	server.AddBranch("foo/alpha", "feature/wip", false, old)
+	server.AddBranch("foo/alpha", "release", true, old)
+	server.AddProtectedBranch("foo/alpha", "release")
+	server.AddBranch("foo/beta", "hotfix", true, old)
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Yes = true
+
+	// run runs the "branches" subcommand and returns its output and
+	// the names of the items that succeeded.
+	run := func(args ...string) (string, []string, error) {
+		cmd := NewBranchesCommand("branches", &BranchesOptions{}, session)
+		out, result, err := runCommand(t, cmd, args)
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return out, names, err
+	}
+
+	// List the merged branches.
+	out, listed, err := run("list", "--group", "foo", "--merged")
+	if err != nil {
+		t.Fatalf("branches list: unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "protected,merged") {
+		t.Errorf("branches list: missing states in output: %q", out)
+	}
+
+	// Clean up the merged branches with and without --dry-run.
+	_, dryRun, dryRunErr := run("cleanup", "--group", "foo", "--older-than", "30d", "-n")
+	dryRunBranches := server.Branches("foo/alpha")
+	_, cleaned, cleanErr := run("cleanup", "--group", "foo", "--older-than", "30d")
+	_, _, invalidErr := run("cleanup", "--group", "foo", "--older-than", "soon")
+
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"list", []string{"foo/alpha:feature/old", "foo/alpha:feature/new",
+			"foo/alpha:release", "foo/beta:hotfix"}, listed},
+		{"dry-run error", nil, dryRunErr},
+		{"dry-run", []string{"foo/alpha:feature/old", "foo/beta:hotfix"}, dryRun},
+		{"dry-run branches", []string{"main", "feature/old", "feature/new",
+			"feature/wip", "release"}, dryRunBranches},
+		{"cleanup error", nil, cleanErr},
+		{"cleanup", []string{"foo/alpha:feature/old", "foo/beta:hotfix"}, cleaned},
+		{"alpha", []string{"main", "feature/new", "feature/wip", "release"},
+			server.Branches("foo/alpha")},
+		{"beta", []string{}, server.Branches("foo/beta")},
+		{"invalid age", true, errors.Is(invalidErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("%s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Without --older-than, merged branches of any age are deleted.
+	_, cleaned, err = run("cleanup", "--group", "foo", "--expr", "alpha")
+	if err != nil || fmt.Sprint(cleaned) != "[foo/alpha:feature/new]" {
+		t.Errorf("cleanup any age: expected=%v  actual=%v (%v)",
+			"[foo/alpha:feature/new]", cleaned, err)
+	}
+}
@@ -0,0 +1,196 @@
+// This file provides the implementation for the "branches list"
+// command which lists the branches of the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// BranchesListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// BranchesListOptions are the options needed by this command.
+type BranchesListOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Merged controls whether only the branches already merged into
+	// the default branch are listed.  Defaults to false.
+	Merged bool `xml:"merged"`
+}
+
+// Initialize initializes this BranchesListOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *BranchesListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --merged
+	flags.BoolVar(&opts.Merged, "merged", opts.Merged,
+		i18n.T("only list branches already merged into the default branch"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// BranchesListCommand
+////////////////////////////////////////////////////////////////////////
+
+// BranchesListCommand implements the "branches list" command which
+// lists the branches of the projects in a group.
+type BranchesListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[BranchesListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *BranchesListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] branches list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the branches of the selected projects.  Each branch is\n")
+	i18n.Fprintf(out, "    printed as its project, name, date of its last commit, and\n")
+	i18n.Fprintf(out, "    whether it is the default branch, protected, or merged into\n")
+	i18n.Fprintf(out, "    the default branch.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewBranchesListCommand returns a new, initialized
+// BranchesListCommand instance.
+func NewBranchesListCommand(
+	name string,
+	opts *BranchesListOptions,
+	session *Session,
+) *BranchesListCommand {
+
+	// Create the new command.
+	cmd := &BranchesListCommand{
+		GitlabCommand: GitlabCommand[BranchesListOptions]{
+			BasicCommand: BasicCommand[BranchesListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *BranchesListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print the branches of each project.  For --output json, the
+	// branches are collected and printed together at the end.  A
+	// project whose branches cannot be listed is recorded as failed,
+	// and the remaining projects are still listed.
+	branches := []*gitlab.Branch{}
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			bs, err := gitlab_util.GetAllBranches(ctx, cmd.client.Branches, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, b := range bs {
+				if cmd.options.Merged && !b.Merged {
+					continue
+				}
+				if cmd.session.OutputJSON() {
+					branches = append(branches, b)
+				} else {
+					committed := "-"
+					if t := branchCommittedAt(b); !t.IsZero() {
+						committed = t.Format("2006-01-02")
+					}
+					fmt.Printf("%-40s  %-30s  %-10s  %s\n", p.PathWithNamespace,
+						b.Name, committed, branchStates(b))
+				}
+				result.Succeed(branchName(p, b.Name), b)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the branches as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, branches)
+		if err != nil {
+			return result, err
+		}
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not list the branches of %d project(s)", failed)
+	}
+	return result, nil
+}
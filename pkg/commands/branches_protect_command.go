@@ -0,0 +1,379 @@
+// This file provides the implementation for the "branches protect"
+// command which protects a branch or wildcard across the projects in a
+// group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// BranchesProtectOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// BranchesProtectOptions are the options needed by this command.
+type BranchesProtectOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// AllowForcePush is whether force pushes to the branch are
+	// allowed.  Defaults to false.
+	AllowForcePush bool `xml:"allow-force-push"`
+
+	// Branch is the name of the branch or a wildcard (e.g.,
+	// "release/*") to protect.  Defaults to "".
+	Branch string `xml:"branch"`
+
+	// CodeOwnerApproval is whether changes to files with a code
+	// owner must be approved by the code owner.  Defaults to false.
+	CodeOwnerApproval bool `xml:"code-owner-approval"`
+
+	// Diff should cause the command to print how the protection of
+	// each branch would change instead of changing it.  Defaults to
+	// false.
+	Diff bool `xml:"diff"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// MergeAccessLevel is the access level allowed to merge which is
+	// one of "no-one", "developer", "maintainer", or "admin".
+	// Defaults to "maintainer".
+	MergeAccessLevel string `xml:"merge-access-level"`
+
+	// PushAccessLevel is the access level allowed to push which is
+	// one of "no-one", "developer", "maintainer", or "admin".
+	// Defaults to "maintainer".
+	PushAccessLevel string `xml:"push-access-level"`
+}
+
+// Initialize initializes this BranchesProtectOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *BranchesProtectOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --allow-force-push
+	flags.BoolVar(&opts.AllowForcePush, "allow-force-push", opts.AllowForcePush,
+		i18n.T("whether force pushes to the branch are allowed"))
+
+	// --branch
+	flags.StringVar(&opts.Branch, "branch", opts.Branch,
+		i18n.T("name of the branch or wildcard (e.g., \"release/*\") to protect"))
+
+	// --code-owner-approval
+	flags.BoolVar(&opts.CodeOwnerApproval, "code-owner-approval", opts.CodeOwnerApproval,
+		i18n.T("whether changes to files with a code owner must be "+
+			"approved by the code owner"))
+
+	// --diff
+	flags.BoolVar(&opts.Diff, "diff", opts.Diff,
+		i18n.T("print how the protection of each branch would change "+
+			"instead of changing it"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --merge-access-level
+	if opts.MergeAccessLevel == "" {
+		opts.MergeAccessLevel = "maintainer"
+	}
+	flags.StringVar(&opts.MergeAccessLevel, "merge-access-level", opts.MergeAccessLevel,
+		i18n.T("access level allowed to merge which is \"no-one\", "+
+			"\"developer\", \"maintainer\", or \"admin\""))
+
+	// --push-access-level
+	if opts.PushAccessLevel == "" {
+		opts.PushAccessLevel = "maintainer"
+	}
+	flags.StringVar(&opts.PushAccessLevel, "push-access-level", opts.PushAccessLevel,
+		i18n.T("access level allowed to push which is \"no-one\", "+
+			"\"developer\", \"maintainer\", or \"admin\""))
+}
+
+////////////////////////////////////////////////////////////////////////
+// BranchesProtectCommand
+////////////////////////////////////////////////////////////////////////
+
+// BranchesProtectCommand implements the "branches protect" command
+// which protects a branch or wildcard across the projects in a group.
+type BranchesProtectCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[BranchesProtectOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *BranchesProtectCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] branches protect [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Protect --branch in the selected projects with the given\n")
+	i18n.Fprintf(out, "    settings.  Branches that are already protected are updated\n")
+	i18n.Fprintf(out, "    if their settings differ.  Use --diff to print the changes\n")
+	i18n.Fprintf(out, "    without making them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Protect Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewBranchesProtectCommand returns a new, initialized
+// BranchesProtectCommand instance.
+func NewBranchesProtectCommand(
+	name string,
+	opts *BranchesProtectOptions,
+	session *Session,
+) *BranchesProtectCommand {
+
+	// Create the new command.
+	cmd := &BranchesProtectCommand{
+		GitlabCommand: GitlabCommand[BranchesProtectOptions]{
+			BasicCommand: BasicCommand[BranchesProtectOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// newProtectBranchOptions returns the options that protect the branch
+// with the settings in want.
+func newProtectBranchOptions(
+	name string,
+	want *BranchProtection,
+) *gitlab.ProtectRepositoryBranchesOptions {
+	return &gitlab.ProtectRepositoryBranchesOptions{
+		Name:                      gitlab.Ptr(name),
+		PushAccessLevel:           gitlab.Ptr(want.PushAccessLevel),
+		MergeAccessLevel:          gitlab.Ptr(want.MergeAccessLevel),
+		AllowForcePush:            gitlab.Ptr(want.AllowForcePush),
+		CodeOwnerApprovalRequired: gitlab.Ptr(want.CodeOwnerApprovalRequired),
+	}
+}
+
+// newUpdateProtectedBranchOptions returns the options that update the
+// existing protection of a branch in place to have the settings in
+// want.
+func newUpdateProtectedBranchOptions(
+	existing *gitlab.ProtectedBranch,
+	want *BranchProtection,
+) *gitlab.UpdateProtectedBranchOptions {
+
+	// Replace the role-based access levels that differ.  Access
+	// granted to individual users or groups is kept.
+	replace := func(
+		ds []*gitlab.BranchAccessDescription,
+		level gitlab.AccessLevelValue,
+	) *[]*gitlab.BranchPermissionOptions {
+		if roleAccessLevel(ds) == level {
+			return nil
+		}
+		var result []*gitlab.BranchPermissionOptions
+		for _, d := range ds {
+			if d.UserID == 0 && d.GroupID == 0 {
+				result = append(result, &gitlab.BranchPermissionOptions{
+					ID:      gitlab.Ptr(d.ID),
+					Destroy: gitlab.Ptr(true),
+				})
+			}
+		}
+		result = append(result, &gitlab.BranchPermissionOptions{
+			AccessLevel: gitlab.Ptr(level),
+		})
+		return &result
+	}
+	return &gitlab.UpdateProtectedBranchOptions{
+		AllowForcePush:            gitlab.Ptr(want.AllowForcePush),
+		CodeOwnerApprovalRequired: gitlab.Ptr(want.CodeOwnerApprovalRequired),
+		AllowedToPush:             replace(existing.PushAccessLevels, want.PushAccessLevel),
+		AllowedToMerge:            replace(existing.MergeAccessLevels, want.MergeAccessLevel),
+	}
+}
+
+// ProtectBranch protects the branch of the project with the settings
+// in want.  If the branch is already protected with different
+// settings, the protection is updated in place so the branch is never
+// unprotected.  If dryRun is true, this function only prints what it
+// would without actually doing it.
+func ProtectBranch(
+	ctx context.Context,
+	s gitlab_util.ProtectedBranchesManager, /* was *gitlab.ProtectedBranchesService */
+	p *gitlab.Project,
+	existing *gitlab.ProtectedBranch,
+	name string,
+	want *BranchProtection,
+	dryRun bool,
+) error {
+	var err error
+	opts := gitlab.WithContext(gitlab_util.Uninterruptible(ctx))
+
+	// Protect the branch if it is not protected yet.
+	if existing == nil {
+		logging.Printf("- Protecting branch %q of %q ... ", name, p.PathWithNamespace)
+		if !dryRun {
+			_, _, err = s.ProtectRepositoryBranches(p.ID,
+				newProtectBranchOptions(name, want), opts)
+			if err != nil {
+				logging.Printf("Failed.\n")
+				return fmt.Errorf("ProtectBranch: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+		return nil
+	}
+
+	// Leave the branch alone if its settings already match.
+	if *NewBranchProtection(existing) == *want {
+		logging.Printf("- Branch %q of %q already protected.\n", name, p.PathWithNamespace)
+		return nil
+	}
+
+	// Update the protection in place.
+	logging.Printf("- Updating protection of branch %q of %q ... ", name, p.PathWithNamespace)
+	if !dryRun {
+		_, _, err = s.UpdateProtectedBranch(p.ID, name,
+			newUpdateProtectedBranchOptions(existing, want), opts)
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("ProtectBranch: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *BranchesProtectCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Branch == "" {
+		return result, i18n.Errorf("%w: branch not set", ErrInvalidOption)
+	}
+	pushAccessLevel, err := ParseBranchAccessLevel(cmd.options.PushAccessLevel)
+	if err != nil {
+		return result, err
+	}
+	mergeAccessLevel, err := ParseBranchAccessLevel(cmd.options.MergeAccessLevel)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Protect the branch in each project.  A project that fails is
+	// recorded in the result, and the remaining projects are still
+	// processed.
+	want := &BranchProtection{
+		PushAccessLevel:           pushAccessLevel,
+		MergeAccessLevel:          mergeAccessLevel,
+		AllowForcePush:            cmd.options.AllowForcePush,
+		CodeOwnerApprovalRequired: cmd.options.CodeOwnerApproval,
+	}
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			existing, err := FindProtectedBranch(
+				ctx, cmd.client.ProtectedBranches, p, cmd.options.Branch)
+			if err == nil {
+				if cmd.options.Diff {
+					var old *BranchProtection
+					if existing != nil {
+						old = NewBranchProtection(existing)
+					}
+					diffBranchProtection(os.Stdout, p, cmd.options.Branch, old, want)
+				} else {
+					err = ProtectBranch(ctx, cmd.client.ProtectedBranches,
+						p, existing, cmd.options.Branch, want, cmd.options.DryRun)
+				}
+			}
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			result.Succeed(p.PathWithNamespace, p)
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not protect branch in %d project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,243 @@
+// This file provides the implementation for the "branches unprotect"
+// command which unprotects a branch or wildcard across the projects in a
+// group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// BranchesUnprotectOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// BranchesUnprotectOptions are the options needed by this command.
+type BranchesUnprotectOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Branch is the name of the branch or wildcard (e.g.,
+	// "release/*") to unprotect.  Defaults to "".
+	Branch string `xml:"branch"`
+
+	// Diff should cause the command to print how the protection of
+	// each branch would change instead of changing it.  Defaults to
+	// false.
+	Diff bool `xml:"diff"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this BranchesUnprotectOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *BranchesUnprotectOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --branch
+	flags.StringVar(&opts.Branch, "branch", opts.Branch,
+		i18n.T("name of the branch or wildcard (e.g., \"release/*\") to unprotect"))
+
+	// --diff
+	flags.BoolVar(&opts.Diff, "diff", opts.Diff,
+		i18n.T("print how the protection of each branch would change "+
+			"instead of changing it"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// BranchesUnprotectCommand
+////////////////////////////////////////////////////////////////////////
+
+// BranchesUnprotectCommand implements the "branches unprotect" command
+// which unprotects a branch or wildcard across the projects in a
+// group.
+type BranchesUnprotectCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[BranchesUnprotectOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *BranchesUnprotectCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] branches unprotect [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Unprotect --branch in the selected projects.  Projects where\n")
+	i18n.Fprintf(out, "    the branch is not protected are skipped.  Use --diff to print\n")
+	i18n.Fprintf(out, "    the changes without making them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Unprotect Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewBranchesUnprotectCommand returns a new, initialized
+// BranchesUnprotectCommand instance.
+func NewBranchesUnprotectCommand(
+	name string,
+	opts *BranchesUnprotectOptions,
+	session *Session,
+) *BranchesUnprotectCommand {
+
+	// Create the new command.
+	cmd := &BranchesUnprotectCommand{
+		GitlabCommand: GitlabCommand[BranchesUnprotectOptions]{
+			BasicCommand: BasicCommand[BranchesUnprotectOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// UnprotectBranch unprotects the branch of the project.  If dryRun is
+// true, this function only prints what it would without actually doing
+// it.
+func UnprotectBranch(
+	ctx context.Context,
+	s gitlab_util.ProtectedBranchesManager, /* was *gitlab.ProtectedBranchesService */
+	p *gitlab.Project,
+	name string,
+	dryRun bool,
+) error {
+	logging.Printf("- Unprotecting branch %q of %q ... ", name, p.PathWithNamespace)
+	if !dryRun {
+		_, err := s.UnprotectRepositoryBranches(p.ID, name,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("UnprotectBranch: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *BranchesUnprotectCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Branch == "" {
+		return result, i18n.Errorf("%w: branch not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Unprotect the branch in each project.  A project that fails is
+	// recorded in the result, and the remaining projects are still
+	// processed.  Projects where the branch is not protected are
+	// skipped.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			existing, err := FindProtectedBranch(
+				ctx, cmd.client.ProtectedBranches, p, cmd.options.Branch)
+			if err == nil && existing == nil {
+				logging.Printf("- Branch %q of %q not protected.\n",
+					cmd.options.Branch, p.PathWithNamespace)
+				hook.OnItemDone(p.PathWithNamespace)
+				return true, nil
+			}
+			if err == nil {
+				if cmd.options.Diff {
+					diffBranchProtection(os.Stdout, p, cmd.options.Branch,
+						NewBranchProtection(existing), nil)
+				} else {
+					err = UnprotectBranch(ctx, cmd.client.ProtectedBranches,
+						p, cmd.options.Branch, cmd.options.DryRun)
+				}
+			}
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			result.Succeed(p.PathWithNamespace, p)
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not unprotect branch in %d project(s)", failed)
+	}
+	return result, nil
+}
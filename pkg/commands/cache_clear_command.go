@@ -0,0 +1,140 @@
+// This file provides the implementation for the "cache clear" command
+// which removes the cached IDs of groups so they are searched for
+// again.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+)
+
+////////////////////////////////////////////////////////////////////////
+// CacheClearOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// CacheClearOptions are the options needed by this command.
+type CacheClearOptions struct {
+}
+
+// Initialize initializes this CacheClearOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *CacheClearOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// CacheClearCommand
+////////////////////////////////////////////////////////////////////////
+
+// CacheClearCommand implements the "cache clear" command which
+// removes the cached IDs of groups.
+type CacheClearCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[CacheClearOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *CacheClearCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] cache clear [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Remove the cached IDs of groups in the --cache-file so\n")
+	i18n.Fprintf(out, "    the groups are searched for again.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Clear Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewCacheClearCommand returns a new, initialized CacheClearCommand
+// instance.
+func NewCacheClearCommand(
+	name string,
+	opts *CacheClearOptions,
+	session *Session,
+) *CacheClearCommand {
+
+	// Create the new command.
+	cmd := &CacheClearCommand{
+		GitlabCommand: GitlabCommand[CacheClearOptions]{
+			BasicCommand: BasicCommand[CacheClearOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *CacheClearCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	cache, err := cmd.session.globalOpts.LookupCache()
+	if err != nil {
+		return result, err
+	}
+	if cache == nil {
+		return result, i18n.Errorf("%w: cache file not set", ErrInvalidOption)
+	}
+
+	// Clear the cache and the groups memoized by this invocation.
+	logging.Printf("- Clearing cache %q ... ", cache.FileName())
+	err = cache.Clear()
+	if err != nil {
+		logging.Printf("Failed.\n")
+		result.Fail(cache.FileName(), nil, err)
+		return result, err
+	}
+	gitlab_util.DefaultGroupMemo.Clear()
+	logging.Printf("Done.\n")
+	result.Succeed(cache.FileName(), nil)
+
+	return result, nil
+}
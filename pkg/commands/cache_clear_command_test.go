@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestCacheClearIntegration(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "lookup-cache.xml")
+	err := gitlab_util.NewLookupCache(fileName, time.Hour).Put(
+		"https://gitlab.com/", "group", "foo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// run runs "cache clear" with the global options.
+	run := func(opts *GlobalOptions) error {
+		session := NewSession(opts)
+		cmd := NewCacheCommand("cache", &CacheOptions{}, session)
+		_, _, err = runCommand(t, cmd, []string{"clear"})
+		return err
+	}
+
+	// Verify the cache file is removed.
+	err = run(&GlobalOptions{CacheFileName: fileName, CacheTTL: "1h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(fileName); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("cache clear: expected=%v  actual=%v", os.ErrNotExist, err)
+	}
+
+	// Verify the invalid options.
+	for _, opts := range []*GlobalOptions{
+		{CacheTTL: "1h"},
+		{CacheFileName: fileName, CacheTTL: "tomorrow"},
+		{CacheFileName: fileName, CacheTTL: "-1h"},
+	} {
+		err := run(opts)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("cache clear %+v: expected=%v  actual=%v", opts, ErrInvalidOption, err)
+		}
+	}
+}
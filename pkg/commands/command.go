@@ -0,0 +1,481 @@
+// This file defines the common interfaces and structs used by commands.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/audit"
+	"github.com/jalitriver/gitlab-cmds/pkg/authinfo"
+	"github.com/jalitriver/gitlab-cmds/pkg/config_path"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Errors
+////////////////////////////////////////////////////////////////////////
+
+var (
+	// ErrInvalidOption is returned (wrapped) when an option is
+	// missing or has an invalid value.
+	ErrInvalidOption = errors.New("invalid option")
+
+	// ErrInvalidSubcommand is returned (wrapped) when a subcommand is
+	// missing or unknown.
+	ErrInvalidSubcommand = errors.New("invalid subcommand")
+
+	// ErrVerificationFailed is returned (wrapped) when "migrate
+	// verify" finds discrepancies.
+	ErrVerificationFailed = errors.New("verification failed")
+
+	// ErrNotConfirmed is returned (wrapped) when the user does not
+	// confirm a destructive operation.
+	ErrNotConfirmed = errors.New("not confirmed")
+
+	// ErrAuthentication is returned (wrapped) when the authentication
+	// information cannot be loaded.
+	ErrAuthentication = errors.New("authentication failed")
+)
+
+////////////////////////////////////////////////////////////////////////
+// Runner
+////////////////////////////////////////////////////////////////////////
+
+// Runner defines the interface for running commands.
+type Runner interface {
+
+	// Run runs the command as specified by its arguments.  The
+	// context can be used to cancel the command or to set a
+	// timeout.  It is passed to every Gitlab API call.  The returned
+	// Result holds the items the command processed.  It can be
+	// non-nil even if an error is returned in which case it holds
+	// the items processed before the error occurred.
+	Run(ctx context.Context, args []string) (*Result, error)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Session
+////////////////////////////////////////////////////////////////////////
+
+// Session holds the state shared by all commands for a single
+// invocation of the program.  Most importantly, it lazily creates the
+// Gitlab communications client the first time a command needs it so
+// that commands which do not talk to Gitlab (e.g., when the user only
+// wants help or when the command-line options are invalid) do not
+// require valid authentication information.
+type Session struct {
+
+	// globalOpts points to the global options which must be fully
+	// initialized from options.xml and the command-line before
+	// Client() is called.
+	globalOpts *GlobalOptions
+
+	// client is the Gitlab communications client which is created
+	// on first use.
+	client *gitlab.Client
+
+	// clientErr is the error, if any, that occurred when creating
+	// the client.
+	clientErr error
+
+	// clientOnce ensures the client is created at most once.
+	clientOnce sync.Once
+
+	// ignoreAuthEnv causes the authentication information to always
+	// be loaded from the file instead of from the GITLAB_TOKEN (etc.)
+	// environment variables.  It is set for sessions that talk to a
+	// second Gitlab instance for which the token in the environment
+	// is not valid.
+	ignoreAuthEnv bool
+}
+
+// NewSession returns a new Session that will use the global options
+// to create the Gitlab client.
+func NewSession(globalOpts *GlobalOptions) *Session {
+	return &Session{
+		globalOpts: globalOpts,
+	}
+}
+
+// NewSessionWithClient returns a new Session that uses an existing
+// Gitlab client instead of creating one from the authentication
+// information in the auth.xml file.  This is useful for programs that
+// use this package as a library and already have a client.  The
+// global options are empty except for the base URL which is taken
+// from the client.
+func NewSessionWithClient(client *gitlab.Client) *Session {
+	s := &Session{
+		globalOpts: &GlobalOptions{},
+		client:     client,
+	}
+	if client != nil {
+		s.globalOpts.BaseURL = client.BaseURL().String()
+	}
+	s.clientOnce.Do(func() {})
+	return s
+}
+
+// OutputJSON returns true if list commands should print their results
+// as JSON instead of human-readable text.
+func (s *Session) OutputJSON() bool {
+	return s.globalOpts.Output == OutputJSON
+}
+
+// AssumeYes returns true if destructive commands should proceed
+// without asking for confirmation.
+func (s *Session) AssumeYes() bool {
+	return s.globalOpts.Yes
+}
+
+// Client returns the Gitlab client creating it from the
+// authentication information in the environment or the auth.xml file
+// on first use.
+func (s *Session) Client() (*gitlab.Client, error) {
+	s.clientOnce.Do(func() {
+		s.client, s.clientErr = s.createClient()
+	})
+	return s.client, s.clientErr
+}
+
+// Names of the backends from which the authentication information is
+// loaded.
+const (
+
+	// AuthBackendFile loads the authentication information from the
+	// auth.xml file.
+	AuthBackendFile = "file"
+
+	// AuthBackendKeyring loads the token from the OS credential store
+	// without needing the auth.xml file.
+	AuthBackendKeyring = "keyring"
+)
+
+// LoadAuthInfo loads the authentication information from the
+// environment unless ignoreEnv is true or the authentication
+// information was selected explicitly (e.g., with --profile).
+// Otherwise, it loads the authentication information from the backend
+// selected by the global options.
+func LoadAuthInfo(opts *GlobalOptions, ignoreEnv bool) (authinfo.AuthInfo, error) {
+	if !ignoreEnv && !opts.authSelected {
+		if authInfo := authinfo.LoadFromEnv(); authInfo != nil {
+			return authInfo, nil
+		}
+	}
+	if opts.AuthBackend == AuthBackendKeyring {
+		keyringInfo := authinfo.NewKeyringInfo("", "")
+		authInfo, err := keyringInfo.Load()
+		if err != nil {
+			return nil, i18n.Errorf(
+				"LoadAuthInfo: Unable to load authentication information "+
+					"from %v: %w", &keyringInfo, err)
+		}
+		return authInfo, nil
+	}
+	authInfo, err := authinfo.LoadProfile(
+		config_path.Find(opts.AuthFileName), opts.AuthProfile)
+	if err != nil {
+		return nil, i18n.Errorf(
+			"LoadAuthInfo: Unable to load authentication information "+
+				"from file %v: %w", opts.AuthFileName, err)
+	}
+	return authInfo, nil
+}
+
+// createClient creates the Gitlab client from the authentication
+// information in the environment, the auth.xml file, or the OS
+// credential store.
+func (s *Session) createClient() (*gitlab.Client, error) {
+
+	// Load the authentication information.
+	authInfo, err := LoadAuthInfo(s.globalOpts, s.ignoreAuthEnv)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuthentication, err)
+	}
+
+	// Create the Gitlab client based on the authentication
+	// information provided by the user.  The client retries requests
+	// that fail because of rate limiting or transient server errors.
+	options := []gitlab.ClientOptionFunc{
+		gitlab.WithBaseURL(s.globalOpts.BaseURL),
+	}
+	options = append(options, gitlab_util.RetryOptions(
+		s.globalOpts.MaxRetries, s.globalOpts.RateLimit)...)
+
+	// Request larger pages than the Gitlab default so that listing
+	// many items takes fewer requests.
+	if s.globalOpts.PerPage > 0 {
+		options = append(options, gitlab_util.PerPageOption(s.globalOpts.PerPage))
+	}
+
+	// Trace the requests and responses at the debug level, and record
+	// the changes in the audit log if requested.
+	var transport http.RoundTripper
+	if logging.Enabled(slog.LevelDebug) {
+		transport = &logging.Transport{}
+	}
+	if s.globalOpts.AuditLog != "" {
+		auditTransport, err := audit.NewTransport(transport, s.globalOpts.AuditLog)
+		if err != nil {
+			return nil, i18n.Errorf("%w: unable to open audit log: %w",
+				ErrInvalidOption, err)
+		}
+		transport = auditTransport
+	}
+	if transport != nil {
+		options = append(options, gitlab.WithHTTPClient(&http.Client{Transport: transport}))
+	}
+	client, err := authInfo.CreateGitlabClient(options...)
+	if err != nil {
+		return nil, fmt.Errorf("CreateGitlabClient: %w", err)
+	}
+
+	// Cache the IDs of the groups across invocations if requested.
+	// The entries are scoped by the base URL because the same path
+	// can refer to different groups on different instances.
+	cache, err := s.globalOpts.LookupCache()
+	if err != nil {
+		return nil, err
+	}
+	if cache != nil {
+		gitlab_util.DefaultGroupMemo.SetLookupCache(
+			client.Groups, cache, client.BaseURL().String())
+	}
+
+	return client, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// BasicCommand
+////////////////////////////////////////////////////////////////////////
+
+// Command holds common data needed for each command.  The
+// parameterized type T should be the Options struct for the command.
+// For example, BasicCommand[FooOptions] configures this command to
+// work with the options for the "foo" command.  Also see
+// [GitlabCommand] and [ParentCommand].
+type BasicCommand[T any] struct {
+
+	// Name is the name of this command.
+	name string
+
+	// Flagset is used for parsing the command-line flags specific to
+	// this command.
+	flags *flag.FlagSet
+
+	// Options are the options that control how the command runs.
+	// Note that it is tempting to embed the options directly in this
+	// struct or even to allocate the options on the heap.  However,
+	// the way it works is the options are embedded in the single
+	// large "Options" data structure in global_command.go so that all
+	// of the options can be read from a single options.xml file.
+	// Thus, this pointer is actually just a pointer into the large
+	// "Options" data structure in global_command.go.
+	options *T
+}
+
+// parseFlags parses the command-line arguments for a command that
+// does not accept positional arguments.  Because the "flag" package
+// stops parsing at the first positional argument, any flags after it
+// would otherwise be silently ignored so positional arguments are
+// reported as an error instead.
+func (cmd *BasicCommand[T]) parseFlags(args []string) error {
+	err := cmd.flags.Parse(args)
+	if err != nil {
+		return err
+	}
+	if cmd.flags.NArg() > 0 {
+		return i18n.Errorf("%w: unexpected argument: %q",
+			ErrInvalidOption, cmd.flags.Arg(0))
+	}
+	return nil
+}
+
+// hoistFlags returns a copy of args with the flags defined in flags
+// (and their values) moved to the front so they can be parsed by
+// flags even if the user put them after a subcommand.  Flags named in
+// exclude are left in place (e.g., "h" and "help" so that "glcmds
+// projects list -h" still shows the help for "projects list").
+// Arguments after "--" are never moved.
+func hoistFlags(flags *flag.FlagSet, args []string, exclude ...string) []string {
+	var hoisted []string
+	var rest []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Stop at the end of the flags.
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+
+		// Determine the flag name (without leading dashes or trailing
+		// value) if the argument is a flag.
+		name := ""
+		hasValue := false
+		if len(arg) > 1 && arg[0] == '-' {
+			name = strings.TrimPrefix(arg[1:], "-")
+			name, _, hasValue = strings.Cut(name, "=")
+		}
+
+		// Leave the argument in place if it is not one of our flags.
+		f := flags.Lookup(name)
+		if name == "" || f == nil || slices.Contains(exclude, name) {
+			rest = append(rest, arg)
+			continue
+		}
+
+		// Move the flag and, for non-boolean flags specified without
+		// "=", the value that follows it.
+		hoisted = append(hoisted, arg)
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		if !hasValue && !(ok && boolFlag.IsBoolFlag()) && i+1 < len(args) {
+			i++
+			hoisted = append(hoisted, args[i])
+		}
+	}
+
+	return append(hoisted, rest...)
+}
+
+////////////////////////////////////////////////////////////////////////
+// GitlabCommand
+////////////////////////////////////////////////////////////////////////
+
+// GitlabCommand is a BasicCommand with a Gitlab communications
+// client.  The parameterized type T should be the Options struct for
+// the command.  For example, GitlabCommand[ProjectListOptions]
+// configures this command to work with the options for the "project
+// list" command.  To keep commands unit testable, the work
+// done by a command should be factored out into functions that accept
+// the narrow service interfaces defined in gitlab_util (e.g.,
+// [gitlab_util.ProjectDeleter]) instead of the client itself so the
+// functions can be tested with stubs.  See [DeleteProjects()] for an
+// example.
+type GitlabCommand[T any] struct {
+
+	// Embed BasicCommand members.
+	BasicCommand[T]
+
+	// session is used to lazily create the Gitlab communications
+	// client.
+	session *Session
+
+	// client is the Gitlab communications client which is nil until
+	// connect() is called.
+	client *gitlab.Client
+}
+
+// connect sets cmd.client to the Gitlab communications client
+// creating the client if necessary.  Commands must call connect()
+// before using cmd.client, but they should only do so after parsing
+// and validating their options so that help and validation errors do
+// not require valid authentication information.
+func (cmd *GitlabCommand[T]) connect() error {
+	client, err := cmd.session.Client()
+	if err != nil {
+		return err
+	}
+	cmd.client = client
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// ParentCommand
+////////////////////////////////////////////////////////////////////////
+
+// ParentCommand is a BasicCommand with a subcommand map that maps the
+// name of subcommands to their Runner.  The parameterized type T
+// should be the Options struct for the command.  For example,
+// ParentCommand[ProjectOptions] configures this command to work with
+// the options for the "project" command.
+type ParentCommand[T any] struct {
+
+	// Embed BasicCommand members.
+	BasicCommand[T]
+
+	// subcmds maps from command name to Runner for the command
+	subcmds map[string]Runner
+
+	// aliases maps from an alternate command name to the name of the
+	// command in subcmds (e.g., "project" to "projects").
+	aliases map[string]string
+}
+
+// AddAlias adds alias as an alternate name for the subcommand name so
+// that both names dispatch to the same Runner.
+func (p *ParentCommand[T]) AddAlias(alias string, name string) {
+	if p.aliases == nil {
+		p.aliases = make(map[string]string)
+	}
+	p.aliases[alias] = name
+}
+
+// SortedAliases returns the sorted aliases for the subcommand name.
+func (p *ParentCommand[T]) SortedAliases(name string) []string {
+	var result []string
+	for alias, n := range p.aliases {
+		if n == name {
+			result = append(result, alias)
+		}
+	}
+	slices.Sort(result)
+	return result
+}
+
+// DispatchSubcommand dispatches the subcommand specified by the name
+// args[0] using the remaining arguments are arguments for the
+// subcommand.
+func (p *ParentCommand[T]) DispatchSubcommand(
+	ctx context.Context,
+	args []string,
+) (*Result, error) {
+
+	// Determine which subcommand the user specified.
+	if len(args) < 1 {
+		return nil, i18n.Errorf("%w: no subcommand specified", ErrInvalidSubcommand)
+	}
+	subcmd := args[0]
+
+	// Resolve aliases to the real subcommand name.
+	if name, ok := p.aliases[subcmd]; ok {
+		subcmd = name
+	}
+
+	// Find the runner for the subcommand.
+	runner, ok := p.subcmds[subcmd]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubcommand, subcmd)
+	}
+
+	// Run the subcommand.
+	return runner.Run(ctx, args[1:])
+}
+
+// SortedCommandNames returns a slice that holds the sorted command names.
+func (cmd *ParentCommand[T]) SortedCommandNames() []string {
+
+	var result []string
+
+	// Collect all the command names.
+	for k := range cmd.subcmds {
+		result = append(result, k)
+	}
+
+	// Sort the keys.
+	slices.Sort(result)
+
+	return result
+}
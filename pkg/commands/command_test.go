@@ -0,0 +1,240 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestHoistFlags(t *testing.T) {
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+
+	data := Data{
+		{
+			args:     []string{"projects", "list", "--group", "foo"},
+			expected: []string{"projects", "list", "--group", "foo"},
+		},
+		{
+			args:     []string{"projects", "list", "--base-url", "x", "-r"},
+			expected: []string{"--base-url", "x", "projects", "list", "-r"},
+		},
+		{
+			args:     []string{"projects", "--base-url=x", "list", "--show-options"},
+			expected: []string{"--base-url=x", "--show-options", "projects", "list"},
+		},
+		{
+			args:     []string{"projects", "list", "-h", "--help"},
+			expected: []string{"projects", "list", "-h", "--help"},
+		},
+		{
+			args:     []string{"projects", "--", "--base-url", "x"},
+			expected: []string{"projects", "--", "--base-url", "x"},
+		},
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	opts := &GlobalOptions{}
+	opts.Initialize(flags)
+
+	for _, d := range data {
+		actual := hoistFlags(flags, d.args, "h", "help")
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("%v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+	}
+}
+
+// recordingEventHook records the events it receives.
+type recordingEventHook struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (h *recordingEventHook) record(event string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.events = append(h.events, event)
+}
+
+func (h *recordingEventHook) OnItemStart(name string) { h.record("start " + name) }
+func (h *recordingEventHook) OnItemDone(name string)  { h.record("done " + name) }
+func (h *recordingEventHook) OnError(name string, err error) {
+	h.record("error " + name)
+}
+func (h *recordingEventHook) OnPage(page int, totalPages int, count int) {
+	h.record(fmt.Sprintf("page %d/%d %d", page, totalPages, count))
+}
+
+func TestEventHookIntegration(t *testing.T) {
+	_, session := newFakeSession(t)
+	session.globalOpts.Yes = true
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+	hook := &recordingEventHook{}
+	ctx := gitlab_util.WithEventHook(context.Background(), hook)
+
+	// Delete the test projects in the top-level group.
+	var err error
+	captureStdout(t, func() {
+		_, err = cmd.Run(ctx, []string{"delete", "--group", "foo", "--expr", "/test-"})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the events.
+	expected := []string{
+		"page 1/2 2",
+		"page 2/2 1",
+		"start foo/test-gamma",
+		"done foo/test-gamma",
+	}
+	if !slices.Equal(hook.events, expected) {
+		t.Errorf("events: expected=%v  actual=%v", expected, hook.events)
+	}
+}
+
+// interruptingEventHook cancels the context when the first item is
+// started which simulates the user pressing Ctrl-C in the middle of
+// processing the item.
+type interruptingEventHook struct {
+	gitlab_util.NopEventHook
+	cancel context.CancelFunc
+}
+
+func (h *interruptingEventHook) OnItemStart(name string) { h.cancel() }
+
+func TestInterruptIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	session.globalOpts.Yes = true
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = gitlab_util.WithEventHook(ctx, &interruptingEventHook{cancel: cancel})
+
+	// Interrupt deleting the projects in the top-level group.  The
+	// project being deleted when interrupted must still be deleted,
+	// but the remaining projects must not be.
+	var err error
+	captureStdout(t, func() {
+		_, err = cmd.Run(ctx, []string{"delete", "--group", "foo"})
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("projects delete: expected context.Canceled: %v", err)
+	}
+	expected := []string{"foo/beta", "foo/test-gamma", "foo/bar/delta", "foo/bar/test-epsilon"}
+	if actual := server.Projects(); !slices.Equal(actual, expected) {
+		t.Errorf("projects delete: expected=%v  actual=%v", expected, actual)
+	}
+}
+
+func TestAuthEnvIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	for _, name := range []string{"GITLAB_TOKEN", "GITLAB_PRIVATE_TOKEN", "GITLAB_OAUTH_TOKEN"} {
+		t.Setenv(name, "")
+	}
+
+	// run runs "projects list" with a session that must create its
+	// own client but whose auth.xml file does not exist.
+	run := func() error {
+		session := NewSession(&GlobalOptions{
+			AuthFileName: filepath.Join(t.TempDir(), "auth.xml"),
+			BaseURL:      server.URL,
+		})
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		_, _, err := runCommand(t, cmd, []string{"list", "--group", "foo"})
+		return err
+	}
+
+	// Verify the missing auth.xml file is reported.
+	if err := run(); err == nil {
+		t.Errorf("projects list without GITLAB_TOKEN: expected error")
+	}
+
+	// Verify auth.xml is not needed when the token is in the
+	// environment.
+	t.Setenv("GITLAB_TOKEN", "token-1234567890")
+	if err := run(); err != nil {
+		t.Errorf("projects list with GITLAB_TOKEN: unexpected error: %v", err)
+	}
+}
+
+func TestAuthEnvPrecedenceIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	dir := t.TempDir()
+	authFileName := filepath.Join(dir, "auth.xml")
+	err := os.WriteFile(authFileName, []byte(`<AuthInfo>
+  <private-token>file-token</private-token>
+  <profile name="prod">
+    <private-token>prod-token</private-token>
+  </profile>
+</AuthInfo>
+`), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	optionsFileName := filepath.Join(dir, "options.xml")
+	err = os.WriteFile(optionsFileName, []byte(`<options>
+  <profile name="prod">
+    <base-url>`+server.URL+`</base-url>
+    <auth-file-name>`+authFileName+`</auth-file-name>
+    <auth-profile>prod</auth-profile>
+  </profile>
+</options>
+`), 0o600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"GITLAB_PRIVATE_TOKEN", "GITLAB_OAUTH_TOKEN"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("GITLAB_TOKEN", "env-token")
+
+	// run runs "projects list" through the global command with the
+	// global arguments and returns the token sent to Gitlab.
+	run := func(globalArgs ...string) (string, error) {
+		cmd := NewGlobalCommand("glcmds", "0.0.0")
+		args := append([]string{"--options", optionsFileName, "--base-url", server.URL},
+			globalArgs...)
+		args = append(args, "projects", "list", "--group", "foo")
+		_, _, err := runCommand(t, cmd, args)
+		tokens := server.RequestTokens()
+		if len(tokens) == 0 {
+			return "", err
+		}
+		return tokens[len(tokens)-1], err
+	}
+
+	// Verify the token in the environment is only used when the
+	// authentication information is not selected explicitly.
+	type Data []struct {
+		args     []string
+		expected string
+	}
+	data := Data{
+		{[]string{}, "env-token"},
+		{[]string{"--profile", "prod"}, "prod-token"},
+		{[]string{"--auth", authFileName}, "file-token"},
+		{[]string{"--auth", authFileName, "--auth-profile", "prod"}, "prod-token"},
+		{[]string{"--auth", authFileName, "--auth-backend", "file"}, "file-token"},
+	}
+	for _, d := range data {
+		actual, err := run(d.args...)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", d.args, err)
+		}
+		if actual != d.expected {
+			t.Errorf("%v: expected=%q  actual=%q", d.args, d.expected, actual)
+		}
+	}
+}
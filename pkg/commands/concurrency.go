@@ -0,0 +1,43 @@
+// This file provides the options shared by bulk commands that perform
+// their per-project API calls in parallel.
+
+package commands
+
+import (
+	"flag"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// ConcurrencyOptions control how many per-project API calls a bulk
+// command performs in parallel.  Because the struct is embedded, its
+// XML elements appear directly in the options of the embedding command
+// in the options.xml file.
+type ConcurrencyOptions struct {
+
+	// Concurrency is the maximum number of projects processed in
+	// parallel.  Defaults to 1 which processes the projects one at a
+	// time in order.
+	Concurrency int `xml:"concurrency"`
+}
+
+// Initialize initializes this ConcurrencyOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *ConcurrencyOptions) Initialize(flags *flag.FlagSet) {
+
+	// --concurrency
+	if opts.Concurrency == 0 {
+		opts.Concurrency = 1
+	}
+	flags.IntVar(&opts.Concurrency, "concurrency", opts.Concurrency,
+		i18n.T("maximum number of projects to process in parallel"))
+}
+
+// Validate returns an error if the concurrency is not positive.
+func (opts *ConcurrencyOptions) Validate() error {
+	if opts.Concurrency < 1 {
+		return i18n.Errorf("%w: invalid concurrency: %d",
+			ErrInvalidOption, opts.Concurrency)
+	}
+	return nil
+}
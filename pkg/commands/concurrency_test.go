@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProjectsConcurrencyIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+
+	// Create random projects in parallel.
+	_, _, err := runCommand(t, cmd, []string{"create-random", "--parent-group", "foo/bar",
+		"--project-base-name", "random", "--project-count", "6", "--concurrency", "4"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	count := 0
+	for _, p := range server.Projects() {
+		if strings.HasPrefix(p, "foo/bar/random-") {
+			count++
+		}
+	}
+	if count != 6 {
+		t.Errorf("projects create-random --concurrency: expected=%d  actual=%d", 6, count)
+	}
+
+	// Delete all the projects in parallel but fail the deletion of
+	// one of them.  With --keep-going, the failure must not stop the
+	// other deletions.
+	beta := server.Project("foo/beta")
+	server.InjectError(http.MethodDelete, fmt.Sprintf("/projects/%d", beta.ID),
+		http.StatusForbidden, 1)
+	deleteCmd := NewProjectsDeleteCommand("delete", &ProjectsDeleteOptions{}, session)
+	deleteCmd.confirmIn = strings.NewReader("foo\n")
+	output, result, err := runCommand(t, deleteCmd, []string{"--group", "foo", "-r",
+		"--concurrency", "3", "--keep-going", "--i-understand"})
+	if err == nil || err.Error() != "could not delete 1 project(s)" {
+		t.Fatalf("projects delete --concurrency: unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "- Deleting project: \"foo/beta\" ... Failed.\n") {
+		t.Errorf("projects delete --concurrency: missing failure in output: %q", output)
+	}
+	if !strings.Contains(output, "\nFailures:\n") ||
+		!strings.Contains(output, "\nfoo/beta  ") {
+		t.Errorf("projects delete --concurrency: missing summary in output: %q", output)
+	}
+	var failed []string
+	for _, item := range result.Failed() {
+		failed = append(failed, item.Name)
+	}
+	if !slices.Equal(failed, []string{"foo/beta"}) {
+		t.Errorf("projects delete --concurrency failed: expected=%v  actual=%v",
+			[]string{"foo/beta"}, failed)
+	}
+	if len(result.Succeeded()) != 10 {
+		t.Errorf("projects delete --concurrency succeeded: expected=%d  actual=%d",
+			10, len(result.Succeeded()))
+	}
+	if actual := server.Projects(); !slices.Equal(actual, []string{"foo/beta"}) {
+		t.Errorf("projects delete --concurrency: expected=%v  actual=%v",
+			[]string{"foo/beta"}, actual)
+	}
+
+	// Verify an invalid concurrency is rejected.
+	deleteCmd = NewProjectsDeleteCommand("delete", &ProjectsDeleteOptions{}, session)
+	_, err = deleteCmd.Run(context.Background(), []string{"--group", "foo", "--concurrency", "0"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("projects delete --concurrency 0: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+}
@@ -0,0 +1,145 @@
+// This file provides the options shared by commands that delete (or
+// block or revoke) many items at once so that mass deletions must be
+// confirmed the same way everywhere.
+
+package commands
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// ConfirmationPreviewSize is the number of items listed before a
+// deletion is confirmed.
+const ConfirmationPreviewSize = 10
+
+// ConfirmationOptions control how a deletion must be confirmed.  The
+// items are always listed, and the user must confirm the deletion.  If
+// a deletion matches more items than the threshold, the user must also
+// pass --i-understand and type the name of the group (or a generated
+// token) like Gitlab does for its own destructive actions.
+// The global --yes option skips the confirmation for scripts.  Because
+// the struct is embedded, its XML elements appear directly in the
+// options of the embedding command in the options.xml file.
+type ConfirmationOptions struct {
+
+	// ConfirmThreshold is the number of items a deletion can match
+	// before the name of what is being deleted must be typed to
+	// confirm it.  Defaults to 10.
+	ConfirmThreshold int `xml:"confirm-threshold"`
+
+	// IUnderstand acknowledges that a deletion matching more items
+	// than the threshold is intended.  Defaults to false.
+	IUnderstand bool `xml:"i-understand"`
+}
+
+// Initialize initializes this ConfirmationOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *ConfirmationOptions) Initialize(flags *flag.FlagSet) {
+
+	// --confirm-threshold
+	if opts.ConfirmThreshold == 0 {
+		opts.ConfirmThreshold = 10
+	}
+	flags.IntVar(&opts.ConfirmThreshold, "confirm-threshold", opts.ConfirmThreshold,
+		i18n.T("number of items above which the command must be confirmed "+
+			"by typing the group or a generated token"))
+
+	// --i-understand
+	flags.BoolVar(&opts.IUnderstand, "i-understand", opts.IUnderstand,
+		i18n.T("acknowledge acting on more items than the confirmation threshold"))
+}
+
+// Confirm prints the number of items and the first
+// ConfirmationPreviewSize names with the verb (e.g., "delete") and asks
+// the user to confirm by typing "y" which is read from in.  If the number of
+// items exceeds the threshold, --i-understand must also have been
+// passed, and the user must type the expected string instead.  If
+// expected is empty, a token is generated for the user to type
+// instead.  If in is nil, os.Stdin is used which must be a terminal.
+// Nothing is asked if assumeYes is true (i.e., the global --yes option
+// was passed) or if there are no items.
+func (opts *ConfirmationOptions) Confirm(
+	in io.Reader,
+	verb string,
+	names []string,
+	expected string,
+	assumeYes bool,
+) error {
+	if len(names) == 0 {
+		return nil
+	}
+	PrintConfirmationPreview(verb, names)
+	if assumeYes {
+		return nil
+	}
+	if len(names) <= opts.ConfirmThreshold {
+		return promptConfirmation(in, "y")
+	}
+	if !opts.IUnderstand {
+		return i18n.Errorf(
+			"%w: %d items exceed the confirmation threshold of %d; "+
+				"pass --i-understand to proceed",
+			ErrNotConfirmed, len(names), opts.ConfirmThreshold)
+	}
+	if expected == "" {
+		expected = "delete-" + uuid.NewString()[:8]
+	}
+	return promptConfirmation(in, expected)
+}
+
+// PrintConfirmationPreview prints the number of items the verb (e.g.,
+// "delete") is about to be applied to and the first
+// ConfirmationPreviewSize names so the user can see what would be
+// changed before confirming.
+func PrintConfirmationPreview(verb string, names []string) {
+	i18n.Printf("About to %s %d item(s):\n", verb, len(names))
+	for i, name := range names {
+		if i == ConfirmationPreviewSize {
+			i18n.Printf("  ... and %d more\n", len(names)-i)
+			break
+		}
+		fmt.Printf("  %s\n", name)
+	}
+}
+
+// promptConfirmation asks the user to type the expected string which
+// is read from in.  If in is nil, os.Stdin is used which must be a
+// terminal.
+func promptConfirmation(in io.Reader, expected string) error {
+	if in == nil {
+		fi, err := os.Stdin.Stat()
+		if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+			return i18n.Errorf(
+				"%w: must be confirmed interactively or with the global --yes option",
+				ErrNotConfirmed)
+		}
+		in = os.Stdin
+	}
+	i18n.Printf("Type %q to confirm: ", expected)
+	ok, err := readConfirmation(in, expected)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return i18n.Errorf("%w: expected %q", ErrNotConfirmed, expected)
+	}
+	return nil
+}
+
+// readConfirmation reads a line from in and returns true if it is the
+// expected string.
+func readConfirmation(in io.Reader, expected string) (bool, error) {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("readConfirmation: %w", err)
+	}
+	return strings.TrimSpace(line) == expected, nil
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestConfirmBulkCommandsIntegration(t *testing.T) {
+	type Data []struct {
+		name string
+		cmd  func(session *Session, in io.Reader) Runner
+		args []string
+		verb string
+	}
+	data := Data{
+		{"tags delete",
+			func(session *Session, in io.Reader) Runner {
+				cmd := NewTagsDeleteCommand("delete", &TagsDeleteOptions{}, session)
+				cmd.confirmIn = in
+				return cmd
+			},
+			[]string{"--group", "foo", "--tag", "v1.0.0"},
+			"delete"},
+		{"hooks delete",
+			func(session *Session, in io.Reader) Runner {
+				cmd := NewHooksDeleteCommand("delete", &HooksDeleteOptions{}, session)
+				cmd.confirmIn = in
+				return cmd
+			},
+			[]string{"--group", "foo", "--url", "https://example.com/hook"},
+			"delete"},
+		{"users block",
+			func(session *Session, in io.Reader) Runner {
+				cmd := NewUsersBlockCommand("block", &UsersBlockOptions{}, session)
+				cmd.confirmIn = in
+				return cmd
+			},
+			[]string{"--users", "aberns"},
+			"block"},
+	}
+	for _, d := range data {
+		server, session := newFakeSession(t)
+		server.AddTag("foo/beta", "v1.0.0")
+		server.AddProjectHook("foo/alpha", "https://example.com/hook", http.StatusOK)
+
+		// Verify nothing is changed if the confirmation is declined.
+		output, result, err := runCommand(t,
+			d.cmd(session, strings.NewReader("n\n")), d.args)
+		if !errors.Is(err, ErrNotConfirmed) || result.Processed() != 0 {
+			t.Errorf("%s: declined: expected=%v and 0 processed  actual=%v and %d processed",
+				d.name, ErrNotConfirmed, err, result.Processed())
+		}
+		if !strings.Contains(output, "About to "+d.verb+" 1 item(s):") {
+			t.Errorf("%s: declined: missing preview in output: %q", d.name, output)
+		}
+
+		// Verify the item is changed once confirmed.
+		_, result, err = runCommand(t,
+			d.cmd(session, strings.NewReader("y\n")), d.args)
+		if err != nil || len(result.Succeeded()) != 1 {
+			t.Errorf("%s: confirmed: expected 1 succeeded: err=%v  succeeded=%d",
+				d.name, err, len(result.Succeeded()))
+		}
+	}
+}
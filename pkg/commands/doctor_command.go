@@ -0,0 +1,251 @@
+// This file provides the implementation for the "doctor" command
+// which prints diagnostic information that helps to troubleshoot
+// problems with the configuration or with the connection to Gitlab.
+
+package commands
+
+import (
+	"context"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/authinfo"
+	"github.com/jalitriver/gitlab-cmds/pkg/config_path"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// DoctorOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// DoctorOptions are the options needed by this command.
+type DoctorOptions struct {
+
+	// Offline controls whether to skip the checks that need to talk
+	// to Gitlab.  Defaults to false.
+	Offline bool `xml:"offline"`
+}
+
+// Initialize initializes this DoctorOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *DoctorOptions) Initialize(flags *flag.FlagSet) {
+
+	// --offline
+	flags.BoolVar(&opts.Offline, "offline", opts.Offline,
+		i18n.T("skip the checks that need to talk to Gitlab"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// DoctorCommand
+////////////////////////////////////////////////////////////////////////
+
+// DoctorCommand implements the "doctor" command which prints the
+// resolved configuration file paths, the effective options, the
+// authentication method (redacted), and information about the Gitlab
+// server including connectivity, latency, and rate limit status.
+type DoctorCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[DoctorOptions]
+
+	// allOpts are all the options which are printed as the effective
+	// options.
+	allOpts *Options
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *DoctorCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] doctor [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Print diagnostic information for troubleshooting.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Doctor Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewDoctorCommand returns a new, initialized DoctorCommand instance.
+func NewDoctorCommand(
+	name string,
+	opts *DoctorOptions,
+	allOpts *Options,
+	session *Session,
+) *DoctorCommand {
+
+	// Create the new command.
+	cmd := &DoctorCommand{
+		GitlabCommand: GitlabCommand[DoctorOptions]{
+			BasicCommand: BasicCommand[DoctorOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+		allOpts: allOpts,
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// printConfigFile prints the resolved path of the configuration file
+// and whether it exists.
+func printConfigFile(result *Result, label string, name string) {
+	if name == "" {
+		i18n.Printf("  %s: (none)\n", label)
+		result.Succeed(label, nil)
+		return
+	}
+	path := config_path.Find(name)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if _, err := os.Stat(path); err != nil {
+		i18n.Printf("  %s: %s (not found)\n", label, path)
+		result.Fail(label, path, err)
+		return
+	}
+	i18n.Printf("  %s: %s\n", label, path)
+	result.Succeed(label, path)
+}
+
+// Run is the entry point for this command.
+func (cmd *DoctorCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+	globalOpts := cmd.session.globalOpts
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Print the configuration files.
+	i18n.Printf("Configuration Files:\n")
+	optionsFiles := globalOpts.OptionsFiles()
+	if len(optionsFiles) == 0 {
+		printConfigFile(result, "options.xml", "")
+	}
+	for _, name := range optionsFiles {
+		printConfigFile(result, "options.xml", name)
+	}
+	printConfigFile(result, "auth.xml", globalOpts.AuthFileName)
+	i18n.Printf("  Search directories:\n")
+	for _, dir := range config_path.SearchDirs() {
+		fmt.Printf("    %s\n", dir)
+	}
+	fmt.Printf("\n")
+
+	// Print the authentication method.
+	i18n.Printf("Authentication:\n")
+	authInfo, err := LoadAuthInfo(globalOpts, false)
+	if err != nil {
+		i18n.Printf("  Method: unknown (%v)\n", err)
+		result.Fail("authentication", nil, err)
+	} else {
+		i18n.Printf("  Method: %v\n", authInfo)
+		if !globalOpts.authSelected && authinfo.LoadFromEnv() != nil {
+			i18n.Printf("  Source: environment\n")
+		} else if globalOpts.AuthBackend == AuthBackendKeyring {
+			keyringInfo := authinfo.NewKeyringInfo("", "")
+			i18n.Printf("  Source: %v\n", &keyringInfo)
+		} else {
+			i18n.Printf("  Source: %s\n", config_path.Find(globalOpts.AuthFileName))
+			if globalOpts.AuthProfile != "" {
+				i18n.Printf("  Profile: %s\n", globalOpts.AuthProfile)
+			}
+		}
+		result.Succeed("authentication", nil)
+	}
+	fmt.Printf("\n")
+
+	// Print the effective options.
+	i18n.Printf("Effective Options:\n")
+	encoder := xml.NewEncoder(os.Stdout)
+	encoder.Indent("  ", "  ")
+	err = encoder.Encode(cmd.allOpts)
+	if err != nil {
+		return result, err
+	}
+	fmt.Printf("\n\n")
+
+	// Print information about the server.
+	i18n.Printf("Server:\n")
+	if globalOpts.Profile != "" {
+		i18n.Printf("  Profile: %s\n", globalOpts.Profile)
+	}
+	i18n.Printf("  Base URL: %s\n", globalOpts.BaseURL)
+	if cmd.options.Offline {
+		i18n.Printf("  Skipped (offline).\n")
+	} else if err = cmd.connect(); err != nil {
+		i18n.Printf("  Connection: failed (%v)\n", err)
+		result.Fail("server", nil, err)
+	} else {
+		info, err := gitlab_util.GetServerInfo(
+			ctx, cmd.client.Version, cmd.client.Metadata, cmd.client.License)
+		if err != nil {
+			i18n.Printf("  Connection: failed (%v)\n", err)
+			result.Fail("server", nil, err)
+		} else {
+			edition := i18n.T("Community Edition")
+			if info.Enterprise {
+				edition = i18n.T("Enterprise Edition")
+			}
+			i18n.Printf("  Connection: ok\n")
+			i18n.Printf("  Latency: %v\n", info.Latency.Round(time.Millisecond))
+			i18n.Printf("  Version: %s (%s)\n", info.Version, info.Revision)
+			i18n.Printf("  Edition: %s\n", edition)
+			i18n.Printf("  Tier: %s\n", info.Plan)
+			if info.RateLimit.Reported {
+				i18n.Printf("  Rate Limit: %d of %d remaining (resets %v)\n",
+					info.RateLimit.Remaining,
+					info.RateLimit.Limit,
+					info.RateLimit.Reset.Format(time.RFC3339))
+			} else {
+				i18n.Printf("  Rate Limit: not reported\n")
+			}
+			result.Succeed("server", info)
+		}
+	}
+	fmt.Printf("\n")
+
+	// Report whether any checks failed.
+	if failed := result.Failed(); len(failed) > 0 {
+		return result, i18n.Errorf("%d check(s) failed", len(failed))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDoctorIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.Enterprise = true
+	server.LicensePlan = "premium"
+	session := NewSessionWithClient(server.Client(t))
+	cmd := NewDoctorCommand("doctor", &DoctorOptions{}, &Options{}, session)
+
+	// Run the diagnostics.  Only the server check is expected to
+	// succeed because there are no configuration files.
+	output, result, err := runCommand(t, cmd, []string{})
+	if err == nil {
+		t.Fatalf("doctor: expected error")
+	}
+
+	// Verify the server information was reported.
+	for _, expected := range []string{
+		"Version: 16.11.0 (fake)",
+		"Edition: Enterprise Edition",
+		"Tier: premium",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("doctor: expected=%q  actual=%q", expected, output)
+		}
+	}
+	var succeeded []string
+	for _, item := range result.Succeeded() {
+		succeeded = append(succeeded, item.Name)
+	}
+	if !slices.Contains(succeeded, "server") {
+		t.Errorf("doctor: expected=%v  actual=%v", "server", succeeded)
+	}
+}
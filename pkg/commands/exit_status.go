@@ -0,0 +1,131 @@
+// This file provides the exit codes of the program and the report
+// printed when a command fails so that wrapping automation can branch
+// on the kind of failure without scraping the error message.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// Exit codes of the program.  Each kind of failure has its own code.
+const (
+
+	// ExitOK means the command succeeded.
+	ExitOK = 0
+
+	// ExitFailure means the command failed for a reason not covered
+	// by the more specific exit codes below.
+	ExitFailure = 1
+
+	// ExitInvalidOption means an option or subcommand is missing or
+	// has an invalid value.
+	ExitInvalidOption = 2
+
+	// ExitAuthFailure means the authentication information could not
+	// be loaded or Gitlab denied access.
+	ExitAuthFailure = 3
+
+	// ExitNotFound means a group, project, user, or other resource
+	// does not exist.
+	ExitNotFound = 4
+
+	// ExitPartialFailure means the command ran to the end, but one or
+	// more of the items it processed failed.
+	ExitPartialFailure = 5
+
+	// ExitRateLimited means Gitlab kept rejecting requests with 429
+	// Too Many Requests even after they were retried.
+	ExitRateLimited = 6
+
+	// ExitInterrupted means the command was interrupted (e.g., by
+	// Ctrl-C).  It is the exit code the shell uses for SIGINT.
+	ExitInterrupted = 130
+)
+
+// ExitStatus describes how the program exits after a command fails.
+type ExitStatus struct {
+
+	// Code is the exit code of the program (e.g., ExitNotFound).
+	Code int
+
+	// Kind names the kind of failure for machine-readable error
+	// reports (e.g., "not_found").
+	Kind string
+}
+
+// ExitStatusOf returns the exit status for the error returned by a
+// command along with its result.  The error is checked first so that,
+// for example, a command that stops because a group does not exist
+// exits with ExitNotFound even if some items already failed.
+func ExitStatusOf(result *Result, err error) ExitStatus {
+	switch {
+	case err == nil:
+		return ExitStatus{ExitOK, "ok"}
+	case errors.Is(err, context.Canceled):
+		return ExitStatus{ExitInterrupted, "interrupted"}
+	case errors.Is(err, ErrInvalidOption), errors.Is(err, ErrInvalidSubcommand):
+		return ExitStatus{ExitInvalidOption, "invalid_option"}
+	case errors.Is(err, ErrAuthentication), errors.Is(err, gitlab_util.ErrPermissionDenied):
+		return ExitStatus{ExitAuthFailure, "auth_failure"}
+	case errors.Is(err, gitlab_util.ErrRateLimited):
+		return ExitStatus{ExitRateLimited, "rate_limited"}
+	case errors.Is(err, gitlab_util.ErrNotFound),
+		errors.Is(err, gitlab_util.ErrGroupNotFound),
+		errors.Is(err, gitlab_util.ErrUserNotFound):
+		return ExitStatus{ExitNotFound, "not_found"}
+	case len(result.Failed()) > 0:
+		return ExitStatus{ExitPartialFailure, "partial_failure"}
+	}
+	return ExitStatus{ExitFailure, "failure"}
+}
+
+// errorReport is the machine-readable error report printed for
+// --error-format json.
+type errorReport struct {
+	Error    string            `json:"error"`
+	Kind     string            `json:"kind"`
+	ExitCode int               `json:"exit_code"`
+	Failed   []errorReportItem `json:"failed,omitempty"`
+}
+
+// errorReportItem is an item that failed in an errorReport.
+type errorReportItem struct {
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// WriteError writes the error returned by a command to the output
+// writer in the format which is either OutputText or OutputJSON and
+// returns the exit code of the program.  For OutputJSON, the report
+// also lists the items that failed.
+func WriteError(out io.Writer, format string, result *Result, err error) int {
+	status := ExitStatusOf(result, err)
+	if format != OutputJSON {
+		if status.Code == ExitInterrupted {
+			i18n.Fprintf(out, "\n*** Error: interrupted\n\n")
+		} else {
+			i18n.Fprintf(out, "\n*** Error: %v\n\n", err)
+		}
+		return status.Code
+	}
+	report := errorReport{
+		Error:    err.Error(),
+		Kind:     status.Kind,
+		ExitCode: status.Code,
+	}
+	for _, item := range result.Failed() {
+		report.Failed = append(report.Failed,
+			errorReportItem{Name: item.Name, Error: item.Err.Error()})
+	}
+	if writeErr := writeJSON(out, report); writeErr != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	return status.Code
+}
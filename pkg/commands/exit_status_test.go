@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestExitStatusIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	session.globalOpts.Yes = true
+
+	// run runs the "hooks delete" command with the arguments and
+	// returns the exit code and the error written as JSON.
+	run := func(args ...string) (int, string) {
+		var result *Result
+		var err error
+		cmd := NewHooksDeleteCommand("delete", &HooksDeleteOptions{}, session)
+		_, result, err = runCommand(t, cmd, args)
+		if err == nil {
+			return ExitOK, ""
+		}
+		var out strings.Builder
+		code := WriteError(&out, OutputJSON, result, err)
+		return code, out.String()
+	}
+
+	// Verify each kind of failure has its own exit code.
+	server.AddProjectHook("foo/alpha", "https://example.com/hook", http.StatusOK)
+	server.AddProjectHook("foo/beta", "https://example.com/hook", http.StatusOK)
+	server.InjectError("DELETE", fmt.Sprintf("/projects/%d/hooks", server.Project("foo/beta").ID),
+		http.StatusInternalServerError, 100)
+	type Data []struct {
+		name     string
+		args     []string
+		expected int
+		kind     string
+	}
+	data := Data{
+		{"invalid option", []string{"--group", "foo"}, ExitInvalidOption, `"invalid_option"`},
+		{"not found", []string{"--group", "nope", "--url", "https://example.com/hook"},
+			ExitNotFound, `"not_found"`},
+		{"partial failure", []string{"--group", "foo", "--url", "https://example.com/hook"},
+			ExitPartialFailure, `"name": "foo/beta:https://example.com/hook"`},
+		{"ok", []string{"--group", "foo", "--url", "https://example.com/other"}, ExitOK, ""},
+	}
+	for _, d := range data {
+		code, output := run(d.args...)
+		if code != d.expected || !strings.Contains(output, d.kind) {
+			t.Errorf("%s: expected=%d %s  actual=%d %s", d.name, d.expected, d.kind, code, output)
+		}
+	}
+
+	// Verify the kind of failure is also classified for errors that
+	// do not come from a command.
+	errs := []struct {
+		err      error
+		expected int
+	}{
+		{context.Canceled, ExitInterrupted},
+		{fmt.Errorf("%w: no token", ErrAuthentication), ExitAuthFailure},
+		{fmt.Errorf("%w: 403", gitlab_util.ErrPermissionDenied), ExitAuthFailure},
+		{fmt.Errorf("%w: 429", gitlab_util.ErrRateLimited), ExitRateLimited},
+		{errors.New("boom"), ExitFailure},
+	}
+	for _, d := range errs {
+		if actual := ExitStatusOf(nil, d.err).Code; actual != d.expected {
+			t.Errorf("ExitStatusOf(%v): expected=%d  actual=%d", d.err, d.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,1099 @@
+// This file defines GlobalCommand which is the parent of all other commands.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand generator as demonstrated in
+//      GlobalCommand.addSubcmdGenerators().
+
+package commands
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/config_path"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_schema"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Options
+////////////////////////////////////////////////////////////////////////
+
+// Options is the top-level structure that holds all the options read
+// from options.xml and from the command-line.  It is used by
+// GlobalCommand when configuring its subcommands.  Each member of
+// Options represents a subcommand that can be directly invoked by
+// GlobalCommand.  For example, if a subcommand is invoked by another
+// subcommand (e.g. "glcmds projects list"), the subcommand options
+// (i.e., ProjectsListOptions) will be present in their parent
+// subcommand options (i.e., ProjectsOptions) which in turn will be
+// present in this data structure (i.e. Options).
+type Options struct {
+
+	// Name of the root XML element.
+	XMLName xml.Name `xml:"options"`
+
+	// Global Options
+	GlobalOpts GlobalOptions `xml:"global-options"`
+
+	// Profiles are the named sets of global options selected by
+	// --profile.
+	Profiles []Profile `xml:"profile"`
+
+	// Options for the "access-review" command.
+	AccessReviewOpts AccessReviewOptions `xml:"access-review-options"`
+
+	// Options for the "api" command.
+	APIOpts APIOptions `xml:"api-options"`
+
+	// Options for the "apply" command.
+	ApplyOpts ApplyOptions `xml:"apply-options"`
+
+	// Options for the "branches" command.
+	BranchesOpts BranchesOptions `xml:"branches-options"`
+
+	// Options for the "cache" command.
+	CacheOpts CacheOptions `xml:"cache-options"`
+
+	// Options for the "doctor" command.
+	DoctorOpts DoctorOptions `xml:"doctor-options"`
+
+	// Options for the "graphql" command.
+	GraphQLOpts GraphQLOptions `xml:"graphql-options"`
+
+	// Options for the "groups" command.
+	GroupsOpts GroupsOptions `xml:"groups-options"`
+
+	// Options for the "hooks" command.
+	HooksOpts HooksOptions `xml:"hooks-options"`
+
+	// Options for the "issues" command.
+	IssuesOpts IssuesOptions `xml:"issues-options"`
+
+	// Options for the "keyring" command.
+	KeyringOpts KeyringOptions `xml:"keyring-options"`
+
+	// Options for the "members" command.
+	MembersOpts MembersOptions `xml:"members-options"`
+
+	// Options for the "migrate" command.
+	MigrateOpts MigrateOptions `xml:"migrate-options"`
+
+	// Options for the "mr" command.
+	MROpts MROptions `xml:"mr-options"`
+
+	// Options for the "notifications" command.
+	NotificationsOpts NotificationsOptions `xml:"notifications-options"`
+
+	// Options for the "pipelines" command.
+	PipelinesOpts PipelinesOptions `xml:"pipelines-options"`
+
+	// Options for the "projects" command.
+	ProjectsOpts ProjectsOptions `xml:"projects-options"`
+
+	// Options for the "releases" command.
+	ReleasesOpts ReleasesOptions `xml:"releases-options"`
+
+	// Options for the "runners" command.
+	RunnersOpts RunnersOptions `xml:"runners-options"`
+
+	// Options for the "search" command.
+	SearchOpts SearchOptions `xml:"search-options"`
+
+	// Options for the "seed" command.
+	SeedOpts SeedOptions `xml:"seed-options"`
+
+	// Options for the "snippets" command.
+	SnippetsOpts SnippetsOptions `xml:"snippets-options"`
+
+	// Options for the "tags" command.
+	TagsOpts TagsOptions `xml:"tags-options"`
+
+	// Options for the "tokens" command.
+	TokensOpts TokensOptions `xml:"tokens-options"`
+
+	// Options for the "undo" command.
+	UndoOpts UndoOptions `xml:"undo-options"`
+
+	// Options for the "users" command.
+	UsersOpts UsersOptions `xml:"users-options"`
+
+	// Options for the "variables" command.
+	VariablesOpts VariablesOptions `xml:"variables-options"`
+
+	// Options for the "wipe" command.
+	WipeOpts WipeOptions `xml:"wipe-options"`
+}
+
+// optionsIncludes holds the <include> elements of an options file.
+// They are read separately from the Options struct so that they are
+// not carried over to --show-options or from one file to the next.
+type optionsIncludes struct {
+
+	// Name of the root XML element.
+	XMLName xml.Name `xml:"options"`
+
+	// Includes are the names of the options files to load before the
+	// file that includes them.
+	Includes []string `xml:"include"`
+}
+
+// LoadFromXMLFile loads options from the XML file.  Options already
+// loaded are overridden only by the elements present in the file so
+// several files can be layered.  Note that list elements like
+// <users> are appended to instead of overridden.  Files named by
+// <include> elements are loaded (in order) before the file that
+// includes them so that the including file can override them.
+// Relative include file names are relative to the directory of the
+// including file.  Files with a ".yaml", ".yml", or ".json" extension
+// are read as YAML or JSON having the same nested structure as the
+// XML where a list like <users> is written as a sequence.
+func (opts *Options) LoadFromXMLFile(fname string) error {
+	return opts.loadFromXMLFile(fname, nil)
+}
+
+// optionsSchema describes every element allowed in an options file
+// which is the options plus the <include> elements.
+type optionsSchema struct {
+	Options
+	Includes []string `xml:"include"`
+}
+
+// loadFromXMLFile is the implementation of LoadFromXMLFile() where
+// loading are the files currently being loaded which is used to detect
+// include cycles.
+func (opts *Options) loadFromXMLFile(fname string, loading []string) error {
+
+	// Detect include cycles.
+	abs, err := filepath.Abs(fname)
+	if err != nil {
+		return fmt.Errorf("LoadFromXMLFile: %w", err)
+	}
+	if slices.Contains(loading, abs) {
+		return i18n.Errorf("LoadFromXMLFile: %v: include cycle", fname)
+	}
+	loading = append(loading, abs)
+
+	// Try to read the options.xml file.
+	content, err := os.ReadFile(fname)
+	if err != nil {
+		return fmt.Errorf("LoadFromXMLFile: %w", err)
+	}
+
+	// Convert YAML and JSON to the equivalent XML.
+	switch strings.ToLower(filepath.Ext(fname)) {
+	case ".yaml", ".yml", ".json":
+		content, err = xml_schema.ConvertYAML(fname, content, &optionsSchema{})
+		if err != nil {
+			return i18n.Errorf("LoadFromXMLFile: %w", err)
+		}
+	}
+
+	// Validate the options.xml file so that misspelled or misplaced
+	// elements are reported instead of silently ignored.
+	err = xml_schema.Validate(fname, content, &optionsSchema{})
+	if err != nil {
+		return i18n.Errorf("LoadFromXMLFile: %w", err)
+	}
+
+	// Load the included files.
+	var includes optionsIncludes
+	err = xml.Unmarshal(content, &includes)
+	if err != nil {
+		return i18n.Errorf("LoadFromXMLFile: %v: %w", fname, err)
+	}
+	for _, include := range includes.Includes {
+		include = strings.TrimSpace(include)
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(fname), include)
+		}
+		err = opts.loadFromXMLFile(include, loading)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Try to parse the options.xml file.
+	err = xml.Unmarshal(content, opts)
+	if err != nil {
+		return i18n.Errorf("LoadFromXMLFile: %v: %w", fname, err)
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// GlobalOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure (above) so that all of the options
+// can be read from a single options.xml file.  Because we want the
+// main "Options" data structure (above) to be lean, we factor out our
+// options into their own data structure.
+//
+
+// GlobalOptions are the options needed by this command.
+type GlobalOptions struct {
+
+	// AuditLog is the name of the JSON Lines file to which each
+	// change made through the Gitlab REST API is appended along with
+	// who made it, when, and the entity before and after the change.
+	// Defaults to "" which does not record the changes.
+	AuditLog string `xml:"audit-log"`
+
+	// AuthBackend is where the authentication information is loaded
+	// from which is either "file" for the auth.xml file or "keyring"
+	// for a token in the OS credential store stored by the "keyring
+	// set" command.  Defaults to "file".
+	AuthBackend string `xml:"auth-backend"`
+
+	// AuthFileName is an alternative file name for auth.xml which
+	// holds authentication information like an OAuth token or
+	// personal access token.  If it does not have a directory
+	// component, it is searched for in the current directory and
+	// then in the per-user configuration directories.  Defaults to
+	// "auth.xml".
+	AuthFileName string `xml:"auth-file-name"`
+
+	// AuthProfile is the name of the profile in the auth.xml file
+	// that holds the authentication information.  Defaults to "" which
+	// uses the authentication information outside of the profiles.
+	AuthProfile string `xml:"auth-profile"`
+
+	// BaseURL is the base URL for connecting to Gitlab REST
+	// endpoints.  It does not include the "api/v4" part.  Defaults to
+	// "https://gitlab.com/".
+	BaseURL string `xml:"base-url"`
+
+	// CacheFileName is the name of the file that caches the IDs of
+	// groups found by their full path so later invocations do not
+	// have to search for them again.  If it does not have a directory
+	// component, it is placed in the per-user cache directory (e.g.,
+	// ~/.cache/glcmds).  Defaults to "" which only caches the groups
+	// in memory for the current invocation.
+	CacheFileName string `xml:"cache-file-name"`
+
+	// CacheTTL is how long a cached group is used before it is
+	// searched for again in the form accepted by time.ParseDuration()
+	// (e.g., "12h").  Defaults to "24h".
+	CacheTTL string `xml:"cache-ttl"`
+
+	// ErrorFormat is the format in which the error is printed to
+	// stderr when a command fails which is either "text" for a
+	// human-readable message or "json" for a machine-readable report
+	// that includes the kind of failure and the exit code.  Defaults
+	// to "text".
+	ErrorFormat string `xml:"error-format"`
+
+	// Help is whether the user wants help.  Defaults to false.
+	Help bool `xml:"help"`
+
+	// LogLevel is the minimum level of the messages that are logged
+	// which is one of "debug", "info", "warn", or "error".  At the
+	// "debug" level, HTTP requests and responses are traced.  At the
+	// "warn" level and above, progress messages are not printed.
+	// Defaults to "info".
+	LogLevel string `xml:"log-level"`
+
+	// MaxRetries is the number of times a request that failed with
+	// 429 Too Many Requests or a transient 5xx status is retried with
+	// exponential backoff.  Defaults to 5.
+	MaxRetries int `xml:"max-retries"`
+
+	// OptionsFileNames are alternative file names for options.xml.
+	// The files are loaded in order so later files override earlier
+	// ones which allows a personal file to be layered over a shared
+	// one.  Note that the user can only change this option on the
+	// command line, not in the options.xml file (because it leads to
+	// circular logic having the user specify the location of the
+	// options.xml file in the options.xml file).  If a file name does
+	// not have a directory component, it is searched for in the
+	// current directory and then in the per-user configuration
+	// directories.  Defaults to "options.xml".
+	OptionsFileNames string_slice.StringSlice `xml:"-"`
+
+	// Profile is the name of the profile in the options.xml file
+	// whose options override these global options.  Defaults to ""
+	// which selects no profile.
+	Profile string `xml:"profile"`
+
+	// Output is the format in which list commands print their
+	// results which is either "text" for human-readable text or
+	// "json" for machine-readable JSON.  Defaults to "text".
+	Output string `xml:"output"`
+
+	// PerPage is the number of items requested on each page of a list
+	// from Gitlab which must be from 1 to 100.  Larger pages mean
+	// fewer requests.  Defaults to 100.
+	PerPage int `xml:"per-page"`
+
+	// Quiet is shorthand for a LogLevel of "warn" which suppresses
+	// the progress messages.  Defaults to false.
+	Quiet bool `xml:"quiet"`
+
+	// RateLimit is the maximum number of requests per second sent to
+	// Gitlab.  Defaults to 0 which means the rate limit advertised by
+	// Gitlab is used.
+	RateLimit float64 `xml:"rate-limit"`
+
+	// ShowOptions is whether to print options as XML and immediately
+	// exit.  Defaults to false.
+	ShowOptions bool `xml:"-"`
+
+	// Verbose is shorthand for a LogLevel of "debug".  Defaults to
+	// false.
+	Verbose bool `xml:"verbose"`
+
+	// Version is whether the user wants the version.  Defaults to false.
+	Version bool `xml:"version"`
+
+	// Yes is whether destructive commands proceed without asking for
+	// confirmation which is useful for scripts.  It does not apply to
+	// the "wipe" command which always asks.  Defaults to false.
+	Yes bool `xml:"yes"`
+
+	// authSelected is whether the authentication information was
+	// selected explicitly with --profile, --auth, --auth-backend, or
+	// --auth-profile in which case the GITLAB_TOKEN (etc.)
+	// environment variables are ignored.
+	authSelected bool
+}
+
+// Initialize initializes this GlobalOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *GlobalOptions) Initialize(flags *flag.FlagSet) {
+
+	// Set default values that differ from the zero defaults.
+	opts.AuthBackend = AuthBackendFile
+	opts.AuthFileName = "auth.xml"
+	opts.BaseURL = "https://gitlab.com/"
+	opts.CacheTTL = gitlab_util.DefaultLookupCacheTTL.String()
+	opts.ErrorFormat = OutputText
+	opts.LogLevel = logging.LevelInfo
+	opts.MaxRetries = gitlab_util.DefaultMaxRetries
+	opts.Output = OutputText
+	opts.PerPage = gitlab_util.DefaultPerPage
+
+	// --audit-log
+	flags.StringVar(&opts.AuditLog, "audit-log", opts.AuditLog,
+		i18n.T("name of the JSON Lines file to which each change made "+
+			"through Gitlab is appended (default is to not record changes)"))
+
+	// --auth
+	flags.StringVar(&opts.AuthFileName, "auth", opts.AuthFileName,
+		i18n.T("name of XML file with authentication information"))
+
+	// --auth-backend
+	flags.StringVar(&opts.AuthBackend, "auth-backend", opts.AuthBackend,
+		i18n.T("where authentication information is loaded from which is "+
+			"\"file\" for the --auth file or \"keyring\" for the OS credential store"))
+
+	// --auth-profile
+	flags.StringVar(&opts.AuthProfile, "auth-profile", opts.AuthProfile,
+		i18n.T("name of the profile in the --auth file with the "+
+			"authentication information"))
+
+	// --base-url
+	flags.StringVar(&opts.BaseURL, "base-url", opts.BaseURL,
+		i18n.T("base URL for Gitlab REST endpoints which should not include "+
+			"the \"api/v4\" suffix"))
+
+	// --cache-file
+	flags.StringVar(&opts.CacheFileName, "cache-file", opts.CacheFileName,
+		i18n.T("name of the file that caches the IDs of groups across "+
+			"invocations (default is to only cache them in memory)"))
+
+	// --cache-ttl
+	flags.StringVar(&opts.CacheTTL, "cache-ttl", opts.CacheTTL,
+		i18n.T("how long a cached group is used before it is searched "+
+			"for again (e.g., \"12h\")"))
+
+	// --error-format
+	flags.StringVar(&opts.ErrorFormat, "error-format", opts.ErrorFormat,
+		i18n.T("format of the error printed when a command fails which "+
+			"is \"text\" or \"json\""))
+
+	// -h
+	flags.BoolVar(&opts.Help, "h", opts.Help,
+		i18n.T("show help"))
+
+	// --help
+	flags.BoolVar(&opts.Help, "help", opts.Help,
+		i18n.T("show help"))
+
+	// --log-level
+	flags.StringVar(&opts.LogLevel, "log-level", opts.LogLevel,
+		i18n.T("minimum level of the logged messages which is \"debug\", "+
+			"\"info\", \"warn\", or \"error\""))
+
+	// --max-retries
+	flags.IntVar(&opts.MaxRetries, "max-retries", opts.MaxRetries,
+		i18n.T("number of times a request that failed with 429 or a "+
+			"transient 5xx status is retried"))
+
+	// --options
+	flags.Var(&opts.OptionsFileNames, "options",
+		i18n.T("name of XML, YAML, or JSON file with default options which "+
+			"can be given more than once to layer files (default \"options.xml\")"))
+
+	// --output
+	flags.StringVar(&opts.Output, "output", opts.Output,
+		i18n.T("output format of list commands which is \"text\" or \"json\""))
+
+	// --per-page
+	flags.IntVar(&opts.PerPage, "per-page", opts.PerPage,
+		i18n.T("number of items requested on each page of a list from "+
+			"Gitlab which must be from 1 to 100"))
+
+	// --profile
+	flags.StringVar(&opts.Profile, "profile", opts.Profile,
+		i18n.T("name of the profile in the --options file whose base URL "+
+			"and authentication settings are used"))
+
+	// --quiet
+	flags.BoolVar(&opts.Quiet, "quiet", opts.Quiet,
+		i18n.T("suppress progress messages (same as --log-level warn)"))
+
+	// --rate-limit
+	flags.Float64Var(&opts.RateLimit, "rate-limit", opts.RateLimit,
+		i18n.T("maximum number of requests per second sent to Gitlab "+
+			"(default is the rate limit advertised by Gitlab)"))
+
+	// --show-options
+	flags.BoolVar(&opts.ShowOptions, "show-options", opts.ShowOptions,
+		i18n.T("show options"))
+
+	// --verbose
+	flags.BoolVar(&opts.Verbose, "verbose", opts.Verbose,
+		i18n.T("log debug messages and trace HTTP requests "+
+			"(same as --log-level debug)"))
+
+	// -v
+	flags.BoolVar(&opts.Version, "v", opts.Version,
+		i18n.T("show version"))
+
+	// --version
+	flags.BoolVar(&opts.Version, "version", opts.Version,
+		i18n.T("show version"))
+
+	// -y
+	flags.BoolVar(&opts.Yes, "y", opts.Yes,
+		i18n.T("proceed with destructive commands other than wipe without asking for confirmation"))
+
+	// --yes
+	flags.BoolVar(&opts.Yes, "yes", opts.Yes,
+		i18n.T("proceed with destructive commands other than wipe without asking for confirmation"))
+}
+
+// EffectiveLogLevel returns the log level selected by LogLevel,
+// Quiet, and Verbose.  It is an error to set both Quiet and Verbose.
+func (opts *GlobalOptions) EffectiveLogLevel() (slog.Level, error) {
+	if opts.Quiet && opts.Verbose {
+		return slog.LevelInfo, i18n.Errorf("%w: both quiet and verbose set",
+			ErrInvalidOption)
+	}
+	if opts.Quiet {
+		return slog.LevelWarn, nil
+	}
+	if opts.Verbose {
+		return slog.LevelDebug, nil
+	}
+	level, err := logging.ParseLevel(opts.LogLevel)
+	if err != nil {
+		return slog.LevelInfo, i18n.Errorf("%w: invalid log level: %q",
+			ErrInvalidOption, opts.LogLevel)
+	}
+	return level, nil
+}
+
+// EffectiveCacheTTL returns how long a cached group is used as
+// selected by CacheTTL.
+func (opts *GlobalOptions) EffectiveCacheTTL() (time.Duration, error) {
+	ttl, err := time.ParseDuration(opts.CacheTTL)
+	if err != nil || ttl < 0 {
+		return 0, i18n.Errorf("%w: invalid cache TTL: %q",
+			ErrInvalidOption, opts.CacheTTL)
+	}
+	return ttl, nil
+}
+
+// LookupCache returns the cache for the IDs of groups stored in
+// CacheFileName or nil if CacheFileName is not set.
+func (opts *GlobalOptions) LookupCache() (*gitlab_util.LookupCache, error) {
+	if opts.CacheFileName == "" {
+		return nil, nil
+	}
+	ttl, err := opts.EffectiveCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	return gitlab_util.NewLookupCache(
+		config_path.CachePath(opts.CacheFileName), ttl), nil
+}
+
+// OptionsFiles returns the names of the options files in the order
+// they are loaded.  Empty names are skipped so --options "" disables
+// loading options.xml.
+func (opts *GlobalOptions) OptionsFiles() []string {
+	if len(opts.OptionsFileNames) == 0 {
+		return []string{"options.xml"}
+	}
+	var result []string
+	for _, name := range opts.OptionsFileNames {
+		if name != "" {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// GetOptionsXMLFileNames returns the locations of the options.xml
+// files as specified on the command-line arguments or, if not set as
+// a command-line argument, the default location.  If a file name does
+// not have a directory component, it is searched for as described in
+// [config_path.Find()].
+func GetOptionsXMLFileNames(args []string) ([]string, error) {
+	var err error
+
+	// Create a local set of options.
+	opts := new(Options)
+
+	// Create a local flag.FlagSet to parse the command-line
+	// arguments.  Its output is discarded because any errors are
+	// returned to the caller.
+	flags := flag.NewFlagSet("local", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	// Set up the hard-coded defaults for the GlobalOptions and
+	// prepare to parse the command-line arguments.
+	opts.GlobalOpts.Initialize(flags)
+
+	// Parse the command-line options to determine the correct
+	// location of the options.xml file.  Remember, the location of
+	// the options.xml file is necessarily not stored in the
+	// options.xml.  The location can only be altered on the
+	// command-line.
+	err = flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, name := range opts.GlobalOpts.OptionsFiles() {
+		result = append(result, config_path.Find(name))
+	}
+	return result, nil
+}
+
+// Peek at the global options which helps to resolve two circular
+// dependencies.  Values for program options come from the following
+// three locations in increasing order of priority:
+//
+//  1. from the Initialize() calls for each specific data structure
+//     which establishes defaults that are hard-coded
+//
+//  2. from the options.xml file
+//
+//  3. from the command-line
+//
+// The first circular dependency is that we need to create all of the
+// subcommands which call Initialize() to establish the hard-coded
+// defaults, but we cannot create the subcommands until after parsing
+// options.xml and the command-line to determine the correct set of
+// parameters to pass into the subcommands.
+//
+// The second circular dependency is that we need to read from
+// options.xml before parsing the command-line arguments to establish
+// defaults for the program options, but we also need to parse the
+// command-line arguments first to determine if the user specified an
+// alternative location for the options.xml file.
+func PeakAtGlobalOptions(args []string) (*GlobalOptions, error) {
+	var err error
+
+	// Create a local set of options.
+	opts := new(Options)
+
+	// Create a local flag.FlagSet for our local options.  Its output
+	// is discarded because any errors are returned to the caller.
+	flags := flag.NewFlagSet("local", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	// Set up the hard-coded defaults for the GlobalOptions and
+	// prepare to parse the command-line arguments.
+	opts.GlobalOpts.Initialize(flags)
+
+	// Determine the location of the options.xml file.  This breaks
+	// the second circular dependency described in the comment for this
+	// function because GetOptionsXMLFileNames() uses a different
+	// Options instance to parse the command-line options.
+	optionsFileNames, err := GetOptionsXMLFileNames(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the options from the XML file to override the hard-coded
+	// defaults.  Note that we quash the error that results if the XML
+	// file does not exist so that --help and --version can be
+	// processed.  The error for the XML options file being missing
+	// will still be reported but later when we load the options for
+	// real instead of just peaking at the global options.
+	for _, optionsFileName := range optionsFileNames {
+		err = opts.LoadFromXMLFile(optionsFileName)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+			} else {
+				return nil, err
+			}
+		}
+	}
+
+	// Parse of the command-line options to override options.xml.
+	err = flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opts.GlobalOpts, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// GlobalCommand
+////////////////////////////////////////////////////////////////////////
+
+// GlobalCommand is used to parse the global command-line arguments
+// and invoke the first subcommand.
+type GlobalCommand struct {
+
+	// Embed the ParentCommand members.
+	ParentCommand[GlobalOptions]
+
+	// allOpts is the master structure that holds all of the options
+	// which can be read from options.xml or the command-line.  For
+	// example, the GlobalOptions instance used by this program is at
+	// allOpts.GlobalOpts.  These options all need to be in a single
+	// data structure in order to easily use Go's XML parser.
+	allOpts *Options
+
+	// generators is a slice of functions that generate the runnable
+	// subcommands.  (This has nothing to do with Python-style
+	// generators.)  See the comments for addSubcmdGenerators().
+	generators map[string]func(session *Session) Runner
+
+	// session is shared by all subcommands and lazily creates the
+	// Gitlab client when a subcommand first needs it.
+	session *Session
+
+	// version is the program version needed for the --version option.
+	version string
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *GlobalCommand) Usage(out io.Writer, err error) {
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Usage: %s [global_options] subcmd [subcmd_options]\n", cmd.name)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Commands for administering a Gitlab server.  Where possible\n")
+	i18n.Fprintf(out, "    command nesting mirrors the nesting in Gitlab's REST API.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Global Options:\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "  Global options can appear before or after the subcommands\n")
+	i18n.Fprintf(out, "  except for --output which must appear before them.\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+
+	// If the subcommands have not been populated yet, populate them
+	// with nil Runners so we can at least print their names before
+	// exiting.
+	if len(cmd.subcmds) == 0 {
+		for cmdName := range cmd.generators {
+			cmd.subcmds[cmdName] = nil
+		}
+	}
+
+	// Print the subcommand names along with their aliases.
+	for _, subcmd := range cmd.SortedCommandNames() {
+		aliases := cmd.SortedAliases(subcmd)
+		if len(aliases) == 0 {
+			fmt.Fprintf(out, "  %s\n", subcmd)
+		} else {
+			i18n.Fprintf(out, "  %s (alias: %s)\n",
+				subcmd, strings.Join(aliases, ", "))
+		}
+	}
+	fmt.Fprintf(out, "\n")
+
+}
+
+// AddSubcommandGenerators adds the subcommands generators for the
+// global command.  A generator in this context is just a function
+// that creates the subcommand Runnable.  The reason for this is that
+// Usage() can be called very early before the subcommands can be
+// instantiated, but the Usage() command needs a list of subcommands
+// which it can always get from the cmd.generators.
+func (cmd *GlobalCommand) addSubcmdGenerators() {
+	cmd.generators["access-review"] = func(session *Session) Runner {
+		return NewAccessReviewCommand(
+			"access-review", &cmd.allOpts.AccessReviewOpts, session)
+	}
+	cmd.generators["api"] = func(session *Session) Runner {
+		return NewAPICommand(
+			"api", &cmd.allOpts.APIOpts, session)
+	}
+	cmd.generators["apply"] = func(session *Session) Runner {
+		return NewApplyCommand(
+			"apply", &cmd.allOpts.ApplyOpts, session)
+	}
+	cmd.generators["branches"] = func(session *Session) Runner {
+		return NewBranchesCommand(
+			"branches", &cmd.allOpts.BranchesOpts, session)
+	}
+	cmd.generators["cache"] = func(session *Session) Runner {
+		return NewCacheCommand(
+			"cache", &cmd.allOpts.CacheOpts, session)
+	}
+	cmd.generators["doctor"] = func(session *Session) Runner {
+		return NewDoctorCommand(
+			"doctor", &cmd.allOpts.DoctorOpts, cmd.allOpts, session)
+	}
+	cmd.generators["graphql"] = func(session *Session) Runner {
+		return NewGraphQLCommand(
+			"graphql", &cmd.allOpts.GraphQLOpts, session)
+	}
+	cmd.generators["groups"] = func(session *Session) Runner {
+		return NewGroupsCommand(
+			"groups", &cmd.allOpts.GroupsOpts, session)
+	}
+	cmd.generators["hooks"] = func(session *Session) Runner {
+		return NewHooksCommand(
+			"hooks", &cmd.allOpts.HooksOpts, session)
+	}
+	cmd.generators["issues"] = func(session *Session) Runner {
+		return NewIssuesCommand(
+			"issues", &cmd.allOpts.IssuesOpts, session)
+	}
+	cmd.generators["keyring"] = func(session *Session) Runner {
+		return NewKeyringCommand(
+			"keyring", &cmd.allOpts.KeyringOpts, session)
+	}
+	cmd.generators["members"] = func(session *Session) Runner {
+		return NewMembersCommand(
+			"members", &cmd.allOpts.MembersOpts, session)
+	}
+	cmd.generators["migrate"] = func(session *Session) Runner {
+		return NewMigrateCommand(
+			"migrate", &cmd.allOpts.MigrateOpts, session)
+	}
+	cmd.generators["mr"] = func(session *Session) Runner {
+		return NewMRCommand(
+			"mr", &cmd.allOpts.MROpts, session)
+	}
+	cmd.generators["notifications"] = func(session *Session) Runner {
+		return NewNotificationsCommand(
+			"notifications", &cmd.allOpts.NotificationsOpts, session)
+	}
+	cmd.generators["pipelines"] = func(session *Session) Runner {
+		return NewPipelinesCommand(
+			"pipelines", &cmd.allOpts.PipelinesOpts, session)
+	}
+	cmd.generators["projects"] = func(session *Session) Runner {
+		return NewProjectsCommand(
+			"projects", &cmd.allOpts.ProjectsOpts, session)
+	}
+	cmd.generators["releases"] = func(session *Session) Runner {
+		return NewReleasesCommand(
+			"releases", &cmd.allOpts.ReleasesOpts, session)
+	}
+	cmd.generators["runners"] = func(session *Session) Runner {
+		return NewRunnersCommand(
+			"runners", &cmd.allOpts.RunnersOpts, session)
+	}
+	cmd.generators["search"] = func(session *Session) Runner {
+		return NewSearchCommand(
+			"search", &cmd.allOpts.SearchOpts, session)
+	}
+	cmd.generators["seed"] = func(session *Session) Runner {
+		return NewSeedCommand(
+			"seed", &cmd.allOpts.SeedOpts, session)
+	}
+	cmd.generators["snippets"] = func(session *Session) Runner {
+		return NewSnippetsCommand(
+			"snippets", &cmd.allOpts.SnippetsOpts, session)
+	}
+	cmd.generators["tags"] = func(session *Session) Runner {
+		return NewTagsCommand(
+			"tags", &cmd.allOpts.TagsOpts, session)
+	}
+	cmd.generators["tokens"] = func(session *Session) Runner {
+		return NewTokensCommand(
+			"tokens", &cmd.allOpts.TokensOpts, session)
+	}
+	cmd.generators["undo"] = func(session *Session) Runner {
+		return NewUndoCommand(
+			"undo", &cmd.allOpts.UndoOpts, session)
+	}
+	cmd.generators["users"] = func(session *Session) Runner {
+		return NewUsersCommand(
+			"users", &cmd.allOpts.UsersOpts, session)
+	}
+	cmd.generators["variables"] = func(session *Session) Runner {
+		return NewVariablesCommand(
+			"variables", &cmd.allOpts.VariablesOpts, session)
+	}
+	cmd.generators["wipe"] = func(session *Session) Runner {
+		return NewWipeCommand(
+			"wipe", &cmd.allOpts.WipeOpts, session)
+	}
+}
+
+// generateSubcmds generates the subcommands from the list of
+// generators created by addSubcmdGenerators().  See the comments for
+// addSubcmdGenerators().
+func (cmd *GlobalCommand) generateSubcmds() {
+	for cmdName, g := range cmd.generators {
+		cmd.subcmds[cmdName] = g(cmd.session)
+	}
+}
+
+// NewGlobalCommand returns a new, initialized GlobalCommand instance
+// having the specified name.
+func NewGlobalCommand(name string, version string) *GlobalCommand {
+
+	// Create the master data structure which holds all the options.
+	// These options all need to be in a single data structure in
+	// order to easily use Go's XML parser.
+	allOpts := new(Options)
+
+	// Create the new command.
+	cmd := &GlobalCommand{
+		ParentCommand: ParentCommand[GlobalOptions]{
+			BasicCommand: BasicCommand[GlobalOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: &allOpts.GlobalOpts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+		allOpts:    allOpts,
+		generators: make(map[string]func(session *Session) Runner),
+		session:    NewSession(&allOpts.GlobalOpts),
+		version:    version,
+	}
+
+	// Set up the function that prints the global usage when a
+	// problem is detected when parsing command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommand generators from which the subcommands will
+	// be generated.
+	cmd.addSubcmdGenerators()
+
+	// Add the singular forms as aliases so that, for example,
+	// "project list" and "projects list" run the same command.
+	cmd.AddAlias("branch", "branches")
+	cmd.AddAlias("group", "groups")
+	cmd.AddAlias("hook", "hooks")
+	cmd.AddAlias("issue", "issues")
+	cmd.AddAlias("member", "members")
+	cmd.AddAlias("notification", "notifications")
+	cmd.AddAlias("pipeline", "pipelines")
+	cmd.AddAlias("project", "projects")
+	cmd.AddAlias("release", "releases")
+	cmd.AddAlias("runner", "runners")
+	cmd.AddAlias("snippet", "snippets")
+	cmd.AddAlias("tag", "tags")
+	cmd.AddAlias("token", "tokens")
+	cmd.AddAlias("user", "users")
+	cmd.AddAlias("variable", "variables")
+
+	return cmd
+}
+
+// ReportError writes the error returned by Run() to the output writer
+// in the format selected by --error-format and returns the exit code
+// for the program.  See WriteError().
+func (cmd *GlobalCommand) ReportError(out io.Writer, result *Result, err error) int {
+	return WriteError(out, cmd.options.ErrorFormat, result, err)
+}
+
+// Run is the entry point for this command.
+func (cmd *GlobalCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Move global options that appear after the subcommands to the
+	// front so the user can put them anywhere.  The help options are
+	// left in place so they apply to the subcommand they follow,
+	// --output is left in place because several subcommands use it
+	// for the name of their output file.
+	args = hoistFlags(cmd.flags, args, "h", "help", "output")
+
+	// Peek at the global options which helps to resolve two circular
+	// dependencies.  See the comments at PeekAtGlobalOptions() for more.
+	globalOpts, err := PeakAtGlobalOptions(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report errors in the requested format even if they occur
+	// before the command line is parsed for real below.
+	cmd.options.ErrorFormat = globalOpts.ErrorFormat
+
+	// Print help if requested by the user.
+	if globalOpts.Help {
+		cmd.Usage(os.Stdout, nil)
+		return nil, nil
+	}
+
+	// Print the version if requested by the user.
+	if globalOpts.Version {
+		fmt.Printf("%s v%s\n", cmd.name, cmd.version)
+		return nil, nil
+	}
+
+	// Generate the subcommands.  This establishes hard-coded defaults
+	// for the options.  Note that the subcommands do not need the
+	// Gitlab client yet.  The client is lazily created by cmd.session
+	// from the fully initialized global options only when a
+	// subcommand actually needs to talk to Gitlab.  This allows help
+	// and local validation of options to work without valid
+	// authentication information.
+	cmd.generateSubcmds()
+
+	// Load options from XML file.  This overrides the hard-coded
+	// defaults.  This also breaks the second circular dependency
+	// described in the comments for PeakAtGlobalOptions() by using
+	// the location of options.xml from the light-weight globalOpts
+	// returned by PeekAtGlobalOptions().
+	for _, optionsFileName := range globalOpts.OptionsFiles() {
+		err = cmd.allOpts.LoadFromXMLFile(config_path.Find(optionsFileName))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Parse the command-line arguments.  This overrides options.xml
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the selected profile.  This overrides the global options
+	// from options.xml but not those given on the command line.
+	if cmd.options.Profile != "" {
+		profile, err := cmd.allOpts.FindProfile(cmd.options.Profile)
+		if err != nil {
+			return nil, err
+		}
+		cmd.options.ApplyProfile(profile, cmd.flags)
+	}
+
+	// Authentication information that was selected explicitly takes
+	// precedence over the GITLAB_TOKEN (etc.) environment variables
+	// so a token exported for one instance is never sent to another.
+	cmd.options.authSelected = cmd.options.Profile != ""
+	cmd.flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "auth", "auth-backend", "auth-profile":
+			cmd.options.authSelected = true
+		}
+	})
+
+	// Validate the options.
+	if cmd.options.AuthBackend != AuthBackendFile &&
+		cmd.options.AuthBackend != AuthBackendKeyring {
+		return nil, i18n.Errorf("%w: invalid authentication backend: %q",
+			ErrInvalidOption, cmd.options.AuthBackend)
+	}
+	if cmd.options.Output != OutputText && cmd.options.Output != OutputJSON {
+		return nil, i18n.Errorf("%w: invalid output format: %q",
+			ErrInvalidOption, cmd.options.Output)
+	}
+	if cmd.options.ErrorFormat != OutputText && cmd.options.ErrorFormat != OutputJSON {
+		format := cmd.options.ErrorFormat
+		cmd.options.ErrorFormat = OutputText
+		return nil, i18n.Errorf("%w: invalid error format: %q",
+			ErrInvalidOption, format)
+	}
+	if cmd.options.MaxRetries < 0 {
+		return nil, i18n.Errorf("%w: invalid maximum number of retries: %d",
+			ErrInvalidOption, cmd.options.MaxRetries)
+	}
+	if cmd.options.PerPage < 1 || cmd.options.PerPage > gitlab_util.MaxPerPage {
+		return nil, i18n.Errorf("%w: invalid number of items per page: %d",
+			ErrInvalidOption, cmd.options.PerPage)
+	}
+	if cmd.options.RateLimit < 0 {
+		return nil, i18n.Errorf("%w: invalid rate limit: %v",
+			ErrInvalidOption, cmd.options.RateLimit)
+	}
+	level, err := cmd.options.EffectiveLogLevel()
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := cmd.options.EffectiveCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+	gitlab_util.DefaultGroupMemo.TTL = ttl
+
+	// Set up logging.  The event hook logs the progress of
+	// long-running operations in addition to calling the hook, if
+	// any, attached by the caller.
+	logging.Setup(os.Stderr, level)
+	ctx = gitlab_util.WithEventHook(ctx,
+		logging.NewEventHook(gitlab_util.EventHookFromContext(ctx)))
+
+	// Show options if requested.
+	if cmd.options.ShowOptions {
+		encoder := xml.NewEncoder(os.Stdout)
+		encoder.Indent("", "  ")
+		err = encoder.Encode(cmd.allOpts)
+		if err != nil {
+			return nil, err
+		}
+		_, err = fmt.Println()
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
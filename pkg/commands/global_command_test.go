@@ -0,0 +1,365 @@
+package commands
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_schema"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestLoadFromXMLFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		fname := filepath.Join(dir, name)
+		err := os.WriteFile(fname, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return fname
+	}
+	write("team.xml", `
+<options>
+  <global-options>
+    <base-url>https://gitlab.example.com/</base-url>
+    <auth-file-name>team-auth.xml</auth-file-name>
+  </global-options>
+  <wipe-options>
+    <prefix>team-</prefix>
+  </wipe-options>
+</options>`)
+	personal := write("personal.xml", `
+<options>
+  <include>team.xml</include>
+  <global-options>
+    <auth-file-name>my-auth.xml</auth-file-name>
+  </global-options>
+</options>`)
+	override := write("override.xml", `
+<options>
+  <wipe-options>
+    <prefix>mine-</prefix>
+  </wipe-options>
+</options>`)
+	cycle := write("cycle.xml", `
+<options>
+  <include>cycle.xml</include>
+</options>`)
+
+	// Verify the included file is overridden by the including file
+	// and that later files override earlier files.
+	opts := new(Options)
+	for _, fname := range []string{personal, override} {
+		err := opts.LoadFromXMLFile(fname)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	type Data []struct {
+		name     string
+		expected string
+		actual   string
+	}
+	data := Data{
+		{"base-url", "https://gitlab.example.com/", opts.GlobalOpts.BaseURL},
+		{"auth-file-name", "my-auth.xml", opts.GlobalOpts.AuthFileName},
+		{"prefix", "mine-", opts.WipeOpts.Prefix},
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("LoadFromXMLFile: %s: expected=%q  actual=%q",
+				d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify include cycles are detected.
+	err := new(Options).LoadFromXMLFile(cycle)
+	if err == nil {
+		t.Errorf("LoadFromXMLFile: expected include cycle error")
+	}
+}
+
+func TestLoadFromXMLFileValidation(t *testing.T) {
+
+	// Verify the example options file is valid.
+	err := new(Options).LoadFromXMLFile(filepath.Join("..", "..", "options.xml.example"))
+	if err != nil {
+		t.Errorf("LoadFromXMLFile: unexpected error: %v", err)
+	}
+
+	// Verify a misspelled element is reported with its position.
+	fname := filepath.Join(t.TempDir(), "options.xml")
+	err = os.WriteFile(fname, []byte(`<options>
+  <global-options>
+    <base-ulr>https://gitlab.example.com/</base-ulr>
+  </global-options>
+</options>`), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = new(Options).LoadFromXMLFile(fname)
+	if !errors.Is(err, xml_schema.ErrInvalid) {
+		t.Fatalf("LoadFromXMLFile: expected ErrInvalid: %v", err)
+	}
+	expected := fname + ":3:5: unknown element <base-ulr> in <global-options>"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("LoadFromXMLFile: expected=%q  actual=%q", expected, err.Error())
+	}
+}
+
+func TestLoadFromYAMLFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content string) string {
+		fname := filepath.Join(dir, name)
+		err := os.WriteFile(fname, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return fname
+	}
+	write("team.json", `{
+  "global-options": {
+    "base-url": "https://gitlab.example.com/",
+    "auth-file-name": "team-auth.xml"
+  },
+  "wipe-options": {"prefix": "team-"}
+}`)
+	personal := write("personal.yaml", `
+include:
+  - team.json
+global-options:
+  auth-file-name: my-auth.xml
+users-options:
+  list-options:
+    users: [aberns, bcrocket]
+`)
+	override := write("override.xml", `
+<options>
+  <wipe-options>
+    <prefix>mine-</prefix>
+  </wipe-options>
+</options>`)
+	misspelled := write("misspelled.yml", `
+global-options:
+  base-ulr: https://gitlab.example.com/
+`)
+
+	// Verify YAML, JSON, and XML files can be layered.
+	opts := new(Options)
+	for _, fname := range []string{personal, override} {
+		err := opts.LoadFromXMLFile(fname)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	type Data []struct {
+		name     string
+		expected string
+		actual   string
+	}
+	data := Data{
+		{"base-url", "https://gitlab.example.com/", opts.GlobalOpts.BaseURL},
+		{"auth-file-name", "my-auth.xml", opts.GlobalOpts.AuthFileName},
+		{"prefix", "mine-", opts.WipeOpts.Prefix},
+		{"users", "aberns,bcrocket", strings.Join(opts.UsersOpts.UsersListOpts.Users, ",")},
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("LoadFromXMLFile: %s: expected=%q  actual=%q",
+				d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify a misspelled key is reported with its position.
+	err := new(Options).LoadFromXMLFile(misspelled)
+	if !errors.Is(err, xml_schema.ErrInvalid) {
+		t.Fatalf("LoadFromXMLFile: expected ErrInvalid: %v", err)
+	}
+	expected := misspelled + ":3:3: unknown element <base-ulr> in <global-options>"
+	if !strings.Contains(err.Error(), expected) {
+		t.Errorf("LoadFromXMLFile: expected=%q  actual=%q", expected, err.Error())
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "options.xml")
+	err := os.WriteFile(fname, []byte(`
+<options>
+  <global-options>
+    <base-url>https://gitlab.com/</base-url>
+    <profile>prod</profile>
+  </global-options>
+  <profile name="prod">
+    <base-url>https://gitlab.prod.example.com/</base-url>
+    <auth-profile>prod</auth-profile>
+  </profile>
+  <profile name="staging">
+    <base-url>https://gitlab.staging.example.com/</base-url>
+    <auth-file-name>staging-auth.xml</auth-file-name>
+  </profile>
+</options>`), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	load := func(args ...string) *GlobalOptions {
+		opts := new(Options)
+		flags := flag.NewFlagSet("test", flag.ContinueOnError)
+		opts.GlobalOpts.Initialize(flags)
+		err := opts.LoadFromXMLFile(fname)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err = flags.Parse(args)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		profile, err := opts.FindProfile(opts.GlobalOpts.Profile)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		opts.GlobalOpts.ApplyProfile(profile, flags)
+		return &opts.GlobalOpts
+	}
+
+	// Verify the profile overrides options.xml but not the command
+	// line.
+	prod := load()
+	staging := load("--profile", "staging")
+	explicit := load("--profile", "staging", "--base-url", "https://localhost/")
+	type Data []struct {
+		name     string
+		expected string
+		actual   string
+	}
+	data := Data{
+		{"prod base-url", "https://gitlab.prod.example.com/", prod.BaseURL},
+		{"prod auth-profile", "prod", prod.AuthProfile},
+		{"prod auth-file-name", "auth.xml", prod.AuthFileName},
+		{"staging base-url", "https://gitlab.staging.example.com/", staging.BaseURL},
+		{"staging auth-profile", "", staging.AuthProfile},
+		{"staging auth-file-name", "staging-auth.xml", staging.AuthFileName},
+		{"explicit base-url", "https://localhost/", explicit.BaseURL},
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("ApplyProfile: %s: expected=%q  actual=%q",
+				d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify an unknown profile is reported.
+	_, err = new(Options).FindProfile("bogus")
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("FindProfile: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+}
+
+func TestUsageIntegration(t *testing.T) {
+	type Data []struct {
+		args []string
+		help bool
+	}
+
+	data := Data{
+		{args: []string{"--options", "", "--help"}, help: false},
+		{args: []string{"--options", "", "projects", "list", "-h"}, help: true},
+		{args: []string{"--options", "", "projects", "list", "--bogus"}, help: false},
+		{args: []string{"--options", "", "bogus"}, help: false},
+		{args: []string{"--options", "", "projects", "list", "x", "--group", "foo"}, help: false},
+	}
+
+	// Usage is printed to os.Stderr for these, so discard it.
+	stderr := os.Stderr
+	os.Stderr, _ = os.Open(os.DevNull)
+	defer func() { os.Stderr = stderr }()
+
+	for i, d := range data {
+		cmd := NewGlobalCommand("glcmds", "0.0.0")
+		_, _, err := runCommand(t, cmd, d.args)
+		actual := errors.Is(err, flag.ErrHelp)
+		if actual != d.help {
+			t.Errorf("%v: expected=%v  actual=%v", d.args, d.help, actual)
+		}
+		if i > 1 && err == nil {
+			t.Errorf("%v: expected error", d.args)
+		}
+	}
+}
+
+func TestAliasIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	cmd := NewGlobalCommand("glcmds", "0.0.0")
+	cmd.session = NewSessionWithClient(server.Client(t))
+
+	// Verify "project" dispatches to the "projects" command.  This
+	// also verifies global options can follow the subcommands.
+	output, _, err := runCommand(t, cmd, []string{
+		"project", "list", "--group", "foo", "--options", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"foo/alpha", "foo/beta", "foo/test-gamma"}
+	actual := strings.Fields(output)
+	if !slices.Equal(actual, expected) {
+		t.Errorf("project list: expected=%v  actual=%v", expected, actual)
+	}
+}
+
+func TestLoggingIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	t.Setenv("GITLAB_TOKEN", "token-1234567890")
+	t.Setenv("GITLAB_CMDS_TEST_VALUE", "value")
+	t.Cleanup(func() { logging.Setup(os.Stderr, slog.LevelInfo) })
+
+	// run runs the global command with the logging options and
+	// returns its output.
+	run := func(logArgs ...string) (string, error) {
+		cmd := NewGlobalCommand("glcmds", "0.0.0")
+		args := append([]string{"--options", "", "--base-url", server.URL}, logArgs...)
+		args = append(args, "variables", "set", "--group", "foo",
+			"--expr", "alpha", "--key", "K", "--value-env", "GITLAB_CMDS_TEST_VALUE")
+		out, _, err := runCommand(t, cmd, args)
+		return out, err
+	}
+
+	// Verify the progress messages are only printed without --quiet.
+	type Data []struct {
+		args     []string
+		expected string
+	}
+	data := Data{
+		{[]string{}, "- Setting variable \"K\" in \"foo/alpha\" ... Done.\n"},
+		{[]string{"--quiet"}, ""},
+		{[]string{"--log-level", "error"}, ""},
+	}
+	for _, d := range data {
+		actual, err := run(d.args...)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", d.args, err)
+		}
+		if actual != d.expected {
+			t.Errorf("%v: expected=%q  actual=%q", d.args, d.expected, actual)
+		}
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"--quiet", "--verbose"},
+		{"--log-level", "bogus"},
+	} {
+		_, err := run(args...)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("%v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
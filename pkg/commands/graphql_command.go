@@ -0,0 +1,235 @@
+// This file provides the implementation for the "graphql" command
+// which sends an arbitrary query to the GraphQL API and prints the
+// JSON response.
+
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+)
+
+////////////////////////////////////////////////////////////////////////
+// GraphQLOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// GraphQLOptions are the options needed by this command.
+type GraphQLOptions struct {
+
+	// Fields are the "key=value" variables of the query.  They take
+	// precedence over the variables in the variables file.  Defaults
+	// to empty.
+	Fields string_slice.StringSlice `xml:"fields>field"`
+
+	// InputFileName is the name of the file with the query or "-" for
+	// stdin.  Defaults to "-".
+	InputFileName string `xml:"input-file-name"`
+
+	// VariablesFileName is the name of the file with a JSON object
+	// holding the variables of the query.  Defaults to "".
+	VariablesFileName string `xml:"variables-file-name"`
+}
+
+// Initialize initializes this GraphQLOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *GraphQLOptions) Initialize(flags *flag.FlagSet) {
+
+	// -f
+	flags.Var(&opts.Fields, "f",
+		i18n.T("comma-separated list of key=value variables of the query"))
+
+	// --field
+	flags.Var(&opts.Fields, "field",
+		i18n.T("comma-separated list of key=value variables of the query"))
+
+	// --input
+	if opts.InputFileName == "" {
+		opts.InputFileName = "-"
+	}
+	flags.StringVar(&opts.InputFileName, "input", opts.InputFileName,
+		i18n.T("file with the query or - for stdin"))
+
+	// --variables
+	flags.StringVar(&opts.VariablesFileName, "variables", opts.VariablesFileName,
+		i18n.T("file with a JSON object holding the variables of the query"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// GraphQLCommand
+////////////////////////////////////////////////////////////////////////
+
+// GraphQLCommand implements the "graphql" command which sends an
+// arbitrary query to the GraphQL API.
+type GraphQLCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[GraphQLOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *GraphQLCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] graphql [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Send the query read from the --input file (or stdin) to\n")
+	i18n.Fprintf(out, "    the GraphQL API and print the JSON response.  Variables\n")
+	i18n.Fprintf(out, "    are read from the --variables file and the --field\n")
+	i18n.Fprintf(out, "    options.  Field values that are JSON numbers, true,\n")
+	i18n.Fprintf(out, "    false, or null are sent as is; all other values are sent\n")
+	i18n.Fprintf(out, "    as strings.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "GraphQL Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewGraphQLCommand returns a new, initialized GraphQLCommand instance.
+func NewGraphQLCommand(
+	name string,
+	opts *GraphQLOptions,
+	session *Session,
+) *GraphQLCommand {
+
+	// Create the new command.
+	cmd := &GraphQLCommand{
+		GitlabCommand: GitlabCommand[GraphQLOptions]{
+			BasicCommand: BasicCommand[GraphQLOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// LoadGraphQLVariables returns the variables of the query from the
+// file, if any, with the fields added.
+func LoadGraphQLVariables(fileName string, fields [][2]string) (map[string]any, error) {
+	variables := map[string]any{}
+	if fileName != "" {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(content, &variables)
+		if err != nil {
+			return nil, i18n.Errorf("%w: variables are not a JSON object: %v",
+				ErrInvalidOption, err)
+		}
+	}
+	for _, f := range fields {
+		variables[f[0]] = apiFieldValue(f[1])
+	}
+	return variables, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *GraphQLCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.InputFileName == "" {
+		return result, i18n.Errorf("%w: input not set", ErrInvalidOption)
+	}
+	fields, err := ParseAPIFields(cmd.options.Fields)
+	if err != nil {
+		return result, err
+	}
+
+	// Read the query and its variables.
+	var query []byte
+	if cmd.options.InputFileName == "-" {
+		query, err = io.ReadAll(os.Stdin)
+	} else {
+		query, err = os.ReadFile(cmd.options.InputFileName)
+	}
+	if err != nil {
+		return result, err
+	}
+	variables, err := LoadGraphQLVariables(cmd.options.VariablesFileName, fields)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Send the query.
+	name := cmd.options.InputFileName
+	resp, err := gitlab_util.DoGraphQL(ctx, cmd.client,
+		&gitlab_util.GraphQLRequest{Query: string(query), Variables: variables})
+	if err != nil {
+		result.Fail(name, nil, err)
+		return result, err
+	}
+
+	// Print the response including any errors so partial results are
+	// not lost.
+	output := map[string]any{"data": resp.Data}
+	if len(resp.Errors) > 0 {
+		output["errors"] = resp.Errors
+	}
+	response, err := json.Marshal(output)
+	if err != nil {
+		return result, err
+	}
+	err = PrintAPIResponse(os.Stdout, response)
+	if err != nil {
+		return result, err
+	}
+	if len(resp.Errors) > 0 {
+		err = i18n.Errorf("GraphQL query returned %d error(s)", len(resp.Errors))
+		result.Fail(name, resp, err)
+		return result, err
+	}
+	result.Succeed(name, resp)
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/xanzy/go-gitlab"
+)
+
+// TestGraphQLIntegration tests sending raw queries with the "graphql"
+// command.  The fake Gitlab server does not model the GraphQL API so
+// this test uses its own server which echoes the variables.
+func TestGraphQLIntegration(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req gitlab_util.GraphQLRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.Query, "bogus") {
+			fmt.Fprintf(w, `{"data": null, "errors": [{"message": "bogus field"}]}`)
+			return
+		}
+		variables, _ := json.Marshal(req.Variables)
+		fmt.Fprintf(w, `{"data": {"echo": %s}}`, variables)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		fileName := filepath.Join(dir, name)
+		err := os.WriteFile(fileName, []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return fileName
+	}
+	run := func(args ...string) (string, error) {
+		session := NewSessionWithClient(client)
+		cmd := NewGraphQLCommand("graphql", &GraphQLOptions{}, session)
+		out, _, err := runCommand(t, cmd, args)
+		return out, err
+	}
+	compact := func(out string) string {
+		var buf bytes.Buffer
+		err := json.Compact(&buf, []byte(out))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return buf.String()
+	}
+
+	// Send queries with variables from a file and from fields.
+	query := writeFile("query.graphql", "query($first: Int) { echo }")
+	variables := writeFile("variables.json", `{"first": 1, "group": "foo"}`)
+	out, err := run("--input", query, "--variables", variables, "-f", "first=2,path=foo/bar")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	echoed := compact(out)
+	out, queryErr := run("--input", writeFile("bogus.graphql", "{ bogus }"))
+	errored := compact(out)
+	_, invalidErr := run("--input", query, "-f", "first")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"variables", `{"data":{"echo":{"first":2,"group":"foo","path":"foo/bar"}}}`, echoed},
+		{"errors", `{"data":null,"errors":[{"message":"bogus field"}]}`, errored},
+		{"query error", true, queryErr != nil},
+		{"invalid field", true, errors.Is(invalidErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("graphql %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
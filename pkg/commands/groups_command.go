@@ -1,12 +1,12 @@
-// This file provides the implementation for the "projects" command
-// which provides project related subcommands.
+// This file provides the implementation for the "groups" command
+// which provides group related subcommands.
 //
 // If you need to add a new subcommand, do the following:
 //
 //   1) Create the new subcommand similar to
-//      cmd/internal/commands/projects_command.go if the subcommand
+//      pkg/commands/projects_command.go if the subcommand
 //      will have its own set of subcommands or similar to
-//      cmd/internal/commands/projects_list_command.go if the
+//      pkg/commands/projects_list_command.go if the
 //      subcommand will actually do something.
 //
 //   2) Add the resulting new options struct to the Options struct
@@ -14,22 +14,23 @@
 //      file.
 //
 //   3) Add the new subcommand as demonstrated in
-//      ProjectsCommand.addSubcmds().
+//      GroupsCommand.addSubcmds().
 
 package commands
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/xanzy/go-gitlab"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
 )
 
 ////////////////////////////////////////////////////////////////////////
-// ProjectsOptions
+// GroupsOptions
 ////////////////////////////////////////////////////////////////////////
 
 //
@@ -41,48 +42,48 @@ import (
 // lean, we factor out our options into their own data structure.
 //
 
-// ProjectsOptions are the options needed by this command.
-type ProjectsOptions struct {
-	ProjectsApprovalRulesOpts ProjectsApprovalRulesOptions `xml:"approval-rules-options"`
+// GroupsOptions are the options needed by this command.
+type GroupsOptions struct {
+	GroupsCreateRandomOpts GroupsCreateRandomOptions `xml:"create-random-options"`
 
-	ProjectsCreateRandomOpts ProjectsCreateRandomOptions `xml:"create-random-options"`
+	// Options for the "groups report" command.
+	GroupsReportOpts GroupsReportOptions `xml:"report-options"`
 
-	ProjectsDeleteOpts ProjectsDeleteOptions `xml:"delete-options"`
-
-	ProjectsListOpts ProjectsListOptions `xml:"list-options"`
+	// Options for the "groups set-avatar" command.
+	GroupsSetAvatarOpts GroupsSetAvatarOptions `xml:"set-avatar-options"`
 }
 
-// Initialize initializes this ProjectsOptions instance so it can be
+// Initialize initializes this GroupsOptions instance so it can be
 // used with the "flag" package to parse the command-line arguments.
-func (opts *ProjectsOptions) Initialize(flags *flag.FlagSet) {
+func (opts *GroupsOptions) Initialize(flags *flag.FlagSet) {
 	// empty
 }
 
 ////////////////////////////////////////////////////////////////////////
-// ProjectsCommand
+// GroupsCommand
 ////////////////////////////////////////////////////////////////////////
 
-// ProjectsCommand provides subcommands for Gitlab project related
+// GroupsCommand provides subcommands for Gitlab group related
 // maintenance.
-type ProjectsCommand struct {
+type GroupsCommand struct {
 
 	// Embed the Command members.
-	ParentCommand[ProjectsOptions]
+	ParentCommand[GroupsOptions]
 }
 
 // Usage prints the main usage message to the output writer.  If
 // err is not nil, it will be printed before the main output.
-func (cmd *ProjectsCommand) Usage(out io.Writer, err error) {
+func (cmd *GroupsCommand) Usage(out io.Writer, err error) {
 	basename := filepath.Base(os.Args[0])
 	if err != nil {
 		fmt.Fprintf(out, "%v\n", err)
 	}
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out,
-		"Usage: %s [global_options] projects [subcmd]\n",
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] groups [subcmd]\n",
 		basename)
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out, "    Command for administering a Gitlab projects.\n")
+	i18n.Fprintf(out, "    Command for administering Gitlab groups.\n")
 	fmt.Fprintf(out, "\n")
 	fmt.Fprintf(out, "Subcommands:\n")
 	fmt.Fprintf(out, "\n")
@@ -90,66 +91,61 @@ func (cmd *ProjectsCommand) Usage(out io.Writer, err error) {
 		fmt.Fprintf(out, "  %s\n", subcmd)
 	}
 	fmt.Fprintf(out, "\n")
-	if out == os.Stderr {
-		os.Exit(1)
-	}
-	os.Exit(0)
 }
 
 // addSubcmds adds the subcommands for this command.
-func (cmd *ProjectsCommand) addSubcmds(client *gitlab.Client) {
-	cmd.subcmds["approval-rules"] = NewProjectsApprovalRulesCommand(
-		"approval-rules", &cmd.options.ProjectsApprovalRulesOpts, client)
-	cmd.subcmds["create-random"] = NewProjectsCreateRandomCommand(
-		"create-random", &cmd.options.ProjectsCreateRandomOpts, client)
-	cmd.subcmds["delete"] = NewProjectsDeleteCommand(
-		"delete", &cmd.options.ProjectsDeleteOpts, client)
-	cmd.subcmds["list"] = NewProjectsListCommand(
-		"list", &cmd.options.ProjectsListOpts, client)
+func (cmd *GroupsCommand) addSubcmds(session *Session) {
+	cmd.subcmds["create-random"] = NewGroupsCreateRandomCommand(
+		"create-random", &cmd.options.GroupsCreateRandomOpts, session)
+	cmd.subcmds["report"] = NewGroupsReportCommand(
+		"report", &cmd.options.GroupsReportOpts, session)
+	cmd.subcmds["set-avatar"] = NewGroupsSetAvatarCommand(
+		"set-avatar", &cmd.options.GroupsSetAvatarOpts, session)
 }
 
-// NewProjectsCommand returns a new, initialized ProjectsCommand
+// NewGroupsCommand returns a new, initialized GroupsCommand
 // instance having the specified name.
-func NewProjectsCommand(
+func NewGroupsCommand(
 	name string,
-	opts *ProjectsOptions,
-	client *gitlab.Client,
-) *ProjectsCommand {
+	opts *GroupsOptions,
+	session *Session,
+) *GroupsCommand {
 
 	// Create the new command.
-	cmd := &ProjectsCommand{
-		ParentCommand: ParentCommand[ProjectsOptions]{
-			BasicCommand: BasicCommand[ProjectsOptions]{
+	cmd := &GroupsCommand{
+		ParentCommand: ParentCommand[GroupsOptions]{
+			BasicCommand: BasicCommand[GroupsOptions]{
 				name:    name,
-				flags:   flag.NewFlagSet(name, flag.ExitOnError),
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
 				options: opts,
 			},
 			subcmds: make(map[string]Runner),
 		},
 	}
 
-	// Set up the function that prints the global usage and exits.
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
 	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
 
 	// Initialize our command-line options.
 	cmd.options.Initialize(cmd.flags)
 
 	// Add the subcommands.
-	cmd.addSubcmds(client)
+	cmd.addSubcmds(session)
 
 	return cmd
 }
 
 // Run is the entry point for this command.
-func (cmd *ProjectsCommand) Run(args []string) error {
+func (cmd *GroupsCommand) Run(ctx context.Context, args []string) (*Result, error) {
 	var err error
 
 	// Parse command-line arguments.
 	err = cmd.flags.Parse(args)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Dispatch the subcommand specified by the remaining arguments.
-	return cmd.DispatchSubcommand(cmd.flags.Args())
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
 }
@@ -0,0 +1,321 @@
+// This file provides the implementation for the "groups create-random"
+// command which creates random groups en masse.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// GroupsCreateRandomOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// GroupsCreateRandomOptions are the options needed by this command.
+type GroupsCreateRandomOptions struct {
+
+	// Depth is the number of levels of groups to create below the
+	// parent group.  Defaults to 1.
+	Depth uint64 `xml:"depth"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// GroupBaseName is the base name all new groups will have.  The
+	// full name for the group will include random characters after
+	// the base name.  Defaults to "".
+	GroupBaseName string `xml:"group-base-name"`
+
+	// GroupCount is the number of groups to create in the parent
+	// group and, if Depth is greater than 1, in each new group that
+	// is less than Depth levels below the parent group.  Defaults to
+	// 0.
+	GroupCount uint64 `xml:"group-count"`
+
+	// ParentGroup is the group where groups will be created.  The
+	// parent group must already exist.  Defaults to "".
+	ParentGroup string `xml:"parent-group"`
+}
+
+// Initialize initializes this GroupsCreateRandomOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *GroupsCreateRandomOptions) Initialize(flags *flag.FlagSet) {
+
+	// Set defaults.
+	if opts.Depth == 0 {
+		opts.Depth = 1
+	}
+
+	// --depth
+	flags.Uint64Var(&opts.Depth, "depth", opts.Depth,
+		i18n.T("number of levels of new groups below the parent group"))
+
+	// -n
+	flags.BoolVar(
+		&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --group-base-name
+	flags.StringVar(&opts.GroupBaseName, "group-base-name", opts.GroupBaseName,
+		i18n.T("base name for new groups"))
+
+	// --group-count
+	flags.Uint64Var(&opts.GroupCount, "group-count", opts.GroupCount,
+		i18n.T("number of new groups to create in each group"))
+
+	// --parent-group
+	flags.StringVar(&opts.ParentGroup, "parent-group", opts.ParentGroup,
+		i18n.T("parent group for new groups"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// GroupsCreateRandomCommand
+////////////////////////////////////////////////////////////////////////
+
+// GroupsCreateRandomCommand implements the "groups create-random"
+// command which creates random groups en masse.
+type GroupsCreateRandomCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[GroupsCreateRandomOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *GroupsCreateRandomCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] groups create-random [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create groups en masse with random names.  If --depth is greater\n")
+	i18n.Fprintf(out, "    than 1, --group-count groups are also created in each new group\n")
+	i18n.Fprintf(out, "    until the tree is --depth levels deep.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create-Random Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewGroupsCreateRandomCommand returns a new, initialized
+// GroupsCreateRandomCommand instance.
+func NewGroupsCreateRandomCommand(
+	name string,
+	opts *GroupsCreateRandomOptions,
+	session *Session,
+) *GroupsCreateRandomCommand {
+
+	// Create the new command.
+	cmd := &GroupsCreateRandomCommand{
+		GitlabCommand: GitlabCommand[GroupsCreateRandomOptions]{
+			BasicCommand: BasicCommand[GroupsCreateRandomOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// GroupsCreateRandomService is an abstraction of
+// gitlab.GroupsService which finds and creates groups.
+type GroupsCreateRandomService interface {
+	gitlab_util.GroupFinder
+	gitlab_util.GroupCreator
+}
+
+// CreateRandomGroup creates a group in the parent group.  The name of
+// the group is a combination of the group base name and a UUID.  If
+// dryRun is true, this function only prints what it would do without
+// actually doing it, and the returned group only has its FullPath
+// set.
+func CreateRandomGroup(
+	ctx context.Context,
+	s gitlab_util.GroupCreator, /* was *gitlab.GroupsService */
+	parentGroup *gitlab.Group,
+	groupBaseName string,
+	dryRun bool,
+) (*gitlab.Group, error) {
+
+	// Create UUID and use it as the suffix for the new group name.
+	suffix := uuid.NewString()
+	relativePath := groupBaseName + "-" + suffix
+	g := &gitlab.Group{FullPath: parentGroup.FullPath + "/" + relativePath}
+
+	// Set up options for creating the group.
+	opts := gitlab.CreateGroupOptions{
+		Name:        gitlab.Ptr(relativePath),
+		Path:        gitlab.Ptr(relativePath),
+		ParentID:    gitlab.Ptr(parentGroup.ID),
+		Description: gitlab.Ptr("Test Group"),
+	}
+
+	// Create the group.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(g.FullPath)
+	logging.Printf("- Creating group: %q ... ", g.FullPath)
+	if !dryRun {
+		created, _, err := s.CreateGroup(&opts,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			err = fmt.Errorf(
+				"CreateGroup: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(g.FullPath, err)
+			return g, err
+		}
+		g = created
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(g.FullPath)
+
+	return g, nil
+}
+
+// createRandomSubgroups creates groupCount groups in the parent group
+// and then recursively creates groupCount groups in each new group
+// until the tree is depth levels deep.
+func createRandomSubgroups(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.GroupCreator, /* was *gitlab.GroupsService */
+	parentGroup *gitlab.Group,
+	groupBaseName string,
+	groupCount uint64,
+	depth uint64,
+	dryRun bool,
+) error {
+	if depth == 0 {
+		return nil
+	}
+	for i := uint64(0); i < groupCount; i++ {
+		g, err := CreateRandomGroup(ctx, s, parentGroup, groupBaseName, dryRun)
+		if err != nil {
+			result.Fail(g.FullPath, nil, err)
+			return err
+		}
+		result.Succeed(g.FullPath, nil)
+		err = createRandomSubgroups(
+			ctx, result, s, g, groupBaseName, groupCount, depth-1, dryRun)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateRandomGroups creates the specified number of groups in the
+// parent group.  If depth is greater than 1, the same number of
+// groups is also created in each new group until the tree is depth
+// levels deep.  The name of each group is a combination of the group
+// base name and a UUID.  If dryRun is true, this function only prints
+// what it would do without actually doing it.
+func CreateRandomGroups(
+	ctx context.Context,
+	result *Result,
+	s GroupsCreateRandomService, /* was *gitlab.GroupsService */
+	parentGroup string,
+	groupBaseName string,
+	groupCount uint64,
+	depth uint64,
+	dryRun bool,
+) error {
+
+	// Get the parent group ID.
+	logging.Printf("- Searching for ID for parent group %q ... ", parentGroup)
+	g, err := gitlab_util.FindExactGroup(ctx, s, parentGroup)
+	if err != nil {
+		return err
+	}
+	logging.Printf("Done.\n")
+
+	// Create the groups.
+	return createRandomSubgroups(
+		ctx, result, s, g, groupBaseName, groupCount, depth, dryRun)
+}
+
+// Run is the entry point for this command.
+func (cmd *GroupsCreateRandomCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.ParentGroup == "" {
+		return result, i18n.Errorf("%w: invalid parent group: %q",
+			ErrInvalidOption, cmd.options.ParentGroup)
+	} else if cmd.options.GroupBaseName == "" {
+		return result, i18n.Errorf("%w: invalid group base name: %q",
+			ErrInvalidOption, cmd.options.GroupBaseName)
+	} else if cmd.options.GroupCount == 0 {
+		return result, i18n.Errorf("%w: invalid group count: %v",
+			ErrInvalidOption, cmd.options.GroupCount)
+	} else if cmd.options.Depth == 0 {
+		return result, i18n.Errorf("%w: invalid depth: %v",
+			ErrInvalidOption, cmd.options.Depth)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Create random groups.
+	err = CreateRandomGroups(
+		ctx,
+		result,
+		cmd.client.Groups,
+		cmd.options.ParentGroup,
+		cmd.options.GroupBaseName,
+		cmd.options.GroupCount,
+		cmd.options.Depth,
+		cmd.options.DryRun)
+	return result, err
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupsCreateRandomIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	cmd := NewGroupsCommand("groups", &GroupsOptions{}, session)
+
+	// Create two levels of random groups.
+	_, _, err := runCommand(t, cmd, []string{"create-random", "--parent-group", "foo/bar",
+		"--group-base-name", "random", "--group-count", "2", "--depth", "2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the groups were created at each level.
+	counts := make(map[int]int)
+	for _, g := range server.Groups() {
+		if strings.HasPrefix(g, "foo/bar/random-") {
+			counts[strings.Count(g, "/random-")]++
+		}
+	}
+	type Data []struct {
+		level    int
+		expected int
+	}
+	data := Data{
+		{1, 2},
+		{2, 4},
+		{3, 0},
+	}
+	for _, d := range data {
+		if counts[d.level] != d.expected {
+			t.Errorf("groups create-random level %d: expected=%d  actual=%d",
+				d.level, d.expected, counts[d.level])
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// This file provides the implementation for the "groups report
+// owners" command which reports how many distinct Owners and
+// Maintainers each group and project has so resources that depend on
+// a single owner can be found.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// GroupsReportOwnersOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// GroupsReportOwnersOptions are the options needed by this command.
+type GroupsReportOwnersOptions struct {
+
+	// FlaggedOnly should cause the command to only report the groups
+	// and projects that have at most one Owner.  Defaults to false.
+	FlaggedOnly bool `xml:"flagged-only"`
+
+	// Group is the full path of the root group beneath which the
+	// groups and projects are reported.  Defaults to "".
+	Group string `xml:"group"`
+}
+
+// Initialize initializes this GroupsReportOwnersOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *GroupsReportOwnersOptions) Initialize(flags *flag.FlagSet) {
+
+	// --flagged-only
+	flags.BoolVar(&opts.FlaggedOnly, "flagged-only", opts.FlaggedOnly,
+		i18n.T("only report groups and projects with at most one Owner"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the root group beneath which groups and projects are reported"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// GroupsReportOwnersCommand
+////////////////////////////////////////////////////////////////////////
+
+// GroupsReportOwnersCommand implements the "groups report owners"
+// command which reports the number of Owners and Maintainers.
+type GroupsReportOwnersCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[GroupsReportOwnersOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *GroupsReportOwnersCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] groups report owners [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the number of distinct Owners and Maintainers of the\n")
+	i18n.Fprintf(out, "    root group and of each group and project beneath it including\n")
+	i18n.Fprintf(out, "    inherited members.  Groups and projects with a single Owner\n")
+	i18n.Fprintf(out, "    or without any Owner are flagged.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Owners Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewGroupsReportOwnersCommand returns a new, initialized
+// GroupsReportOwnersCommand instance.
+func NewGroupsReportOwnersCommand(
+	name string,
+	opts *GroupsReportOwnersOptions,
+	session *Session,
+) *GroupsReportOwnersCommand {
+
+	// Create the new command.
+	cmd := &GroupsReportOwnersCommand{
+		GitlabCommand: GitlabCommand[GroupsReportOwnersOptions]{
+			BasicCommand: BasicCommand[GroupsReportOwnersOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// OwnerConcentration is the number of Owners and Maintainers of a
+// group or project.
+type OwnerConcentration struct {
+
+	// Type is either "group" or "project".
+	Type string
+
+	// Path is the full path of the group or project.
+	Path string
+
+	// Owners is the number of distinct users with the Owner role.
+	Owners int
+
+	// Maintainers is the number of distinct users with the Maintainer
+	// role.
+	Maintainers int
+}
+
+// Flag returns "no-owner" if the group or project does not have any
+// Owner, "single-owner" if it has exactly one Owner, and "" otherwise.
+func (c *OwnerConcentration) Flag() string {
+	switch c.Owners {
+	case 0:
+		return "no-owner"
+	case 1:
+		return "single-owner"
+	}
+	return ""
+}
+
+// GetOwnerConcentrations returns the number of Owners and Maintainers
+// of each group or project.
+func GetOwnerConcentrations(sources []*MembershipSource) []*OwnerConcentration {
+	var result []*OwnerConcentration
+	for _, source := range sources {
+		c := &OwnerConcentration{Type: source.Type, Path: source.Path}
+		owners := make(map[int]bool)
+		maintainers := make(map[int]bool)
+		for _, m := range source.Memberships {
+			switch m.AccessLevel {
+			case gitlab.OwnerPermissions:
+				owners[m.UserID] = true
+			case gitlab.MaintainerPermissions:
+				maintainers[m.UserID] = true
+			}
+		}
+		c.Owners = len(owners)
+		c.Maintainers = len(maintainers)
+		result = append(result, c)
+	}
+	return result
+}
+
+// PrintOwnerConcentrations prints the number of Owners and Maintainers
+// of each group or project as a table.
+func PrintOwnerConcentrations(out io.Writer, concentrations []*OwnerConcentration) {
+	row := func(owners, maintainers, flag, sourceType, path string) {
+		fmt.Fprintf(out, "%6s  %11s  %-12s  %-7s  %s\n",
+			owners, maintainers, flag, sourceType, path)
+	}
+	row(i18n.T("OWNERS"), i18n.T("MAINTAINERS"), i18n.T("FLAG"),
+		i18n.T("TYPE"), i18n.T("PATH"))
+	for _, c := range concentrations {
+		row(fmt.Sprint(c.Owners), fmt.Sprint(c.Maintainers), c.Flag(),
+			c.Type, c.Path)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *GroupsReportOwnersCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Count the Owners and Maintainers.
+	sources, err := GetAllMembershipSources(ctx, result, AccessReviewServices{
+		Groups:         cmd.client.Groups,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}, cmd.options.Group)
+	if err != nil {
+		return result, err
+	}
+	concentrations := GetOwnerConcentrations(sources)
+	if cmd.options.FlaggedOnly {
+		var flagged []*OwnerConcentration
+		for _, c := range concentrations {
+			if c.Flag() != "" {
+				flagged = append(flagged, c)
+			}
+		}
+		concentrations = flagged
+	}
+
+	// Print the report.
+	PrintOwnerConcentrations(os.Stdout, concentrations)
+	return result, nil
+}
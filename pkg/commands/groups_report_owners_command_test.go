@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestGroupsReportOwnersIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	server.AddUser("cdavis", "Carol Davis", "cdavis@example.com")
+	server.AddGroupMember("foo", "aberns", gitlab.OwnerPermissions)
+	server.AddGroupMember("foo/bar", "bcrocket", gitlab.OwnerPermissions)
+	server.AddGroupMember("foo/bar", "cdavis", gitlab.MaintainerPermissions)
+	server.AddProjectMember("foo/alpha", "bcrocket", gitlab.MaintainerPermissions)
+	cmd := NewGroupsCommand("groups", &GroupsOptions{}, session)
+
+	// Report the flagged groups and projects.
+	out, _, err := runCommand(t, cmd, []string{"report", "owners",
+		"--group", "foo", "--flagged-only"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the report.
+	var actual []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+		actual = append(actual, strings.Join(strings.Fields(line), " "))
+	}
+	expected := []string{
+		"1 0 single-owner group foo",
+		"1 1 single-owner project foo/alpha",
+		"1 0 single-owner project foo/beta",
+		"1 0 single-owner project foo/test-gamma",
+	}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("groups report owners: expected=%v  actual=%v", expected, actual)
+	}
+}
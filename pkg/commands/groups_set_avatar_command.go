@@ -0,0 +1,236 @@
+// This file provides the implementation for the "groups set-avatar"
+// command which uploads an avatar image to the selected groups.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// GroupsSetAvatarOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// GroupsSetAvatarOptions are the options needed by this command.
+type GroupsSetAvatarOptions struct {
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Expr is the regular expression that filters the groups by their
+	// full path.  Defaults to "".
+	Expr string `xml:"expr"`
+
+	// FileName is the name of the local image file that is uploaded
+	// as the avatar.  Defaults to "".
+	FileName string `xml:"file-name"`
+
+	// Group is the group whose avatar is set.  Defaults to "".
+	Group string `xml:"group"`
+
+	// Recursive controls whether the avatars of the descendants of
+	// the group are also set.  Defaults to false.
+	Recursive bool `xml:"recursive"`
+}
+
+// Initialize initializes this GroupsSetAvatarOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *GroupsSetAvatarOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --expr
+	flags.StringVar(&opts.Expr, "expr", opts.Expr,
+		i18n.T("regular expression that selects groups"))
+
+	// --file
+	flags.StringVar(&opts.FileName, "file", opts.FileName,
+		i18n.T("local image file that is uploaded as the avatar"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("group whose avatar is set which can be the full path or the group ID"))
+
+	// -r
+	flags.BoolVar(&opts.Recursive, "r", opts.Recursive,
+		i18n.T("whether to also set the avatars of the descendants of the group"))
+
+	// --recursive
+	flags.BoolVar(&opts.Recursive, "recursive", opts.Recursive,
+		i18n.T("whether to also set the avatars of the descendants of the group"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// GroupsSetAvatarCommand
+////////////////////////////////////////////////////////////////////////
+
+// GroupsSetAvatarCommand implements the "groups set-avatar" command
+// which uploads an avatar to the selected groups.
+type GroupsSetAvatarCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[GroupsSetAvatarOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *GroupsSetAvatarCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] groups set-avatar [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Upload the image in the --file as the avatar of the\n")
+	i18n.Fprintf(out, "    --group and, if --recursive is set, its descendants\n")
+	i18n.Fprintf(out, "    whose full path matches --expr.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Set-Avatar Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewGroupsSetAvatarCommand returns a new, initialized
+// GroupsSetAvatarCommand instance.
+func NewGroupsSetAvatarCommand(
+	name string,
+	opts *GroupsSetAvatarOptions,
+	session *Session,
+) *GroupsSetAvatarCommand {
+
+	// Create the new command.
+	cmd := &GroupsSetAvatarCommand{
+		GitlabCommand: GitlabCommand[GroupsSetAvatarOptions]{
+			BasicCommand: BasicCommand[GroupsSetAvatarOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SetGroupAvatar uploads the image having the file name and content as
+// the avatar of the group.  If dryRun is true, this function only
+// prints what it would without actually doing it.
+func SetGroupAvatar(
+	ctx context.Context,
+	s gitlab_util.GroupAvatarUploader, /* was *gitlab.GroupsService */
+	g *gitlab.Group,
+	fileName string,
+	content []byte,
+	dryRun bool,
+) error {
+	logging.Printf("- Setting avatar of %q ... ", g.FullPath)
+	if !dryRun {
+		_, _, err := s.UploadAvatar(g.ID, bytes.NewReader(content), fileName,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf(
+				"SetGroupAvatar: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *GroupsSetAvatarCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	if cmd.options.FileName == "" {
+		return result, i18n.Errorf("%w: file not set", ErrInvalidOption)
+	}
+
+	// Read the image.
+	content, err := os.ReadFile(cmd.options.FileName)
+	if err != nil {
+		return result, err
+	}
+	fileName := filepath.Base(cmd.options.FileName)
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Get the groups.
+	groups, err := gitlab_util.GetAllGroups(ctx, cmd.client.Groups,
+		cmd.options.Group, cmd.options.Expr, cmd.options.Recursive)
+	if err != nil {
+		return result, err
+	}
+
+	// Set the avatars.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, g := range groups {
+		hook.OnItemStart(g.FullPath)
+		err = SetGroupAvatar(ctx, cmd.client.Groups, g, fileName, content,
+			cmd.options.DryRun)
+		if err != nil {
+			hook.OnError(g.FullPath, err)
+			result.Fail(g.FullPath, g, err)
+			return result, err
+		}
+		hook.OnItemDone(g.FullPath)
+		result.Succeed(g.FullPath, g)
+	}
+
+	return result, nil
+}
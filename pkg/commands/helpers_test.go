@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+)
+
+// captureStdout returns everything written to os.Stdout while f runs.
+func captureStdout(t *testing.T, f func()) string {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	done := make(chan string)
+	go func() {
+		buf, _ := io.ReadAll(r)
+		done <- string(buf)
+	}()
+	f()
+	w.Close()
+	return <-done
+}
+
+// newFakeServer returns a fake Gitlab server populated with a small
+// group hierarchy.  The page size is small so that pagination is
+// exercised.
+func newFakeServer(t *testing.T) *fake_gitlab.Server {
+	server := fake_gitlab.NewServer(t)
+	server.PerPage = 2
+	server.AddGroup("foo")
+	server.AddGroup("foo/bar")
+	server.AddProject("foo", "alpha")
+	server.AddProject("foo", "beta")
+	server.AddProject("foo", "test-gamma")
+	server.AddProject("foo/bar", "delta")
+	server.AddProject("foo/bar", "test-epsilon")
+	server.AddUser("aberns", "Alice Berns", "aberns@example.com")
+	server.AddUser("bcrocket", "Bob Crocket", "bcrocket@example.com")
+	return server
+}
+
+// newFakeSession returns the fake Gitlab server created by
+// newFakeServer() along with a session whose client talks to it.
+func newFakeSession(t *testing.T) (*fake_gitlab.Server, *Session) {
+	server := newFakeServer(t)
+	return server, NewSessionWithClient(server.Client(t))
+}
+
+// runCommand runs the command with the arguments and returns what it
+// printed to stdout along with its result and error.
+func runCommand(t *testing.T, cmd Runner, args []string) (string, *Result, error) {
+	var result *Result
+	var err error
+	output := captureStdout(t, func() {
+		result, err = cmd.Run(context.Background(), args)
+	})
+	return output, result, err
+}
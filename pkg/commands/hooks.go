@@ -0,0 +1,131 @@
+// This file provides the types and functions shared by the "hooks"
+// subcommands that list, apply, and delete the webhooks of the
+// projects in a group.
+
+package commands
+
+import (
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+// HookEvents are the names of the webhook events accepted by --events.
+var HookEvents = []string{
+	"confidential_issues",
+	"confidential_note",
+	"deployment",
+	"issues",
+	"job",
+	"merge_requests",
+	"note",
+	"pipeline",
+	"push",
+	"releases",
+	"tag_push",
+	"wiki_page",
+}
+
+// ProjectHooksManager is an abstraction of gitlab.ProjectsService
+// which lists, adds, edits, and deletes the webhooks of projects.
+type ProjectHooksManager interface {
+	gitlab_util.ProjectHooksLister
+	gitlab_util.ProjectHookAdder
+	gitlab_util.ProjectHookEditor
+	gitlab_util.ProjectHookDeleter
+}
+
+// ValidateHookEvents returns an error if any of the events is not in
+// HookEvents.
+func ValidateHookEvents(events []string) error {
+	for _, event := range events {
+		if !slices.Contains(HookEvents, event) {
+			return i18n.Errorf("%w: invalid webhook event: %q",
+				ErrInvalidOption, event)
+		}
+	}
+	return nil
+}
+
+// hookEventFlags returns pointers to the event flags of the webhook
+// by the names in HookEvents.
+func hookEventFlags(h *gitlab.ProjectHook) map[string]*bool {
+	return map[string]*bool{
+		"confidential_issues": &h.ConfidentialIssuesEvents,
+		"confidential_note":   &h.ConfidentialNoteEvents,
+		"deployment":          &h.DeploymentEvents,
+		"issues":              &h.IssuesEvents,
+		"job":                 &h.JobEvents,
+		"merge_requests":      &h.MergeRequestsEvents,
+		"note":                &h.NoteEvents,
+		"pipeline":            &h.PipelineEvents,
+		"push":                &h.PushEvents,
+		"releases":            &h.ReleasesEvents,
+		"tag_push":            &h.TagPushEvents,
+		"wiki_page":           &h.WikiPageEvents,
+	}
+}
+
+// hookEventNames returns the names of the events that trigger the
+// webhook in the order of HookEvents.
+func hookEventNames(h *gitlab.ProjectHook) []string {
+	flags := hookEventFlags(h)
+	var result []string
+	for _, event := range HookEvents {
+		if *flags[event] {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// NewDesiredHook returns a webhook for the URL that is triggered by
+// exactly the events.
+func NewDesiredHook(url string, events []string, sslVerification bool) *gitlab.ProjectHook {
+	h := &gitlab.ProjectHook{
+		URL:                   url,
+		EnableSSLVerification: sslVerification,
+	}
+	flags := hookEventFlags(h)
+	for _, event := range events {
+		*flags[event] = true
+	}
+	return h
+}
+
+// sameHookSettings returns true if the webhooks have the same URL,
+// events, and SSL verification.
+func sameHookSettings(a *gitlab.ProjectHook, b *gitlab.ProjectHook) bool {
+	return a.URL == b.URL &&
+		a.EnableSSLVerification == b.EnableSSLVerification &&
+		slices.Equal(hookEventNames(a), hookEventNames(b))
+}
+
+// hookOptions returns the options that give a webhook the settings of
+// h.  Every event flag is set so events that are not wanted are
+// disabled.  If token is not empty, it becomes the secret token of the
+// webhook.
+func hookOptions(h *gitlab.ProjectHook, token string) *gitlab.EditProjectHookOptions {
+	result := &gitlab.EditProjectHookOptions{
+		URL:                      gitlab.Ptr(h.URL),
+		EnableSSLVerification:    gitlab.Ptr(h.EnableSSLVerification),
+		ConfidentialIssuesEvents: gitlab.Ptr(h.ConfidentialIssuesEvents),
+		ConfidentialNoteEvents:   gitlab.Ptr(h.ConfidentialNoteEvents),
+		DeploymentEvents:         gitlab.Ptr(h.DeploymentEvents),
+		IssuesEvents:             gitlab.Ptr(h.IssuesEvents),
+		JobEvents:                gitlab.Ptr(h.JobEvents),
+		MergeRequestsEvents:      gitlab.Ptr(h.MergeRequestsEvents),
+		NoteEvents:               gitlab.Ptr(h.NoteEvents),
+		PipelineEvents:           gitlab.Ptr(h.PipelineEvents),
+		PushEvents:               gitlab.Ptr(h.PushEvents),
+		ReleasesEvents:           gitlab.Ptr(h.ReleasesEvents),
+		TagPushEvents:            gitlab.Ptr(h.TagPushEvents),
+		WikiPageEvents:           gitlab.Ptr(h.WikiPageEvents),
+	}
+	if token != "" {
+		result.Token = gitlab.Ptr(token)
+	}
+	return result
+}
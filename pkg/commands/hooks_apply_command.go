@@ -0,0 +1,302 @@
+// This file provides the implementation for the "hooks apply" command
+// which creates or updates a webhook in each of the projects in a
+// group so, for example, an audit webhook can be rolled out to every
+// project at once.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// HooksApplyOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// HooksApplyOptions are the options needed by this command.
+type HooksApplyOptions struct {
+
+	// Embed the options that control whether the webhook is applied
+	// to the remaining projects after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Events are the events (e.g., "push" or "merge_requests") that
+	// trigger the webhook.  Events that are not listed are disabled.
+	// Defaults to no events.
+	Events string_slice.StringSlice `xml:"events>event"`
+
+	// InsecureSSL disables the verification of the SSL certificate of
+	// the URL.  Defaults to false.
+	InsecureSSL bool `xml:"insecure-ssl"`
+
+	// SecretFileName is the name of the file holding the secret token
+	// of the webhook.  The secret is read from a file so it does not
+	// show up in the process list or the options.xml file.  Defaults
+	// to "" which leaves the secret of existing webhooks unchanged.
+	SecretFileName string `xml:"secret-file-name"`
+
+	// URL is the URL of the webhook.  Defaults to "".
+	URL string `xml:"url"`
+}
+
+// Initialize initializes this HooksApplyOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *HooksApplyOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --events
+	flags.Var(&opts.Events, "events",
+		i18n.T("comma-separated list of the events (e.g., \"push\" or "+
+			"\"merge_requests\") that trigger the webhook"))
+
+	// --insecure-ssl
+	flags.BoolVar(&opts.InsecureSSL, "insecure-ssl", opts.InsecureSSL,
+		i18n.T("disable the verification of the SSL certificate of the URL"))
+
+	// --secret
+	flags.StringVar(&opts.SecretFileName, "secret", opts.SecretFileName,
+		i18n.T("name of the file holding the secret token of the webhook"))
+
+	// --url
+	flags.StringVar(&opts.URL, "url", opts.URL,
+		i18n.T("URL of the webhook"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// HooksApplyCommand
+////////////////////////////////////////////////////////////////////////
+
+// HooksApplyCommand implements the "hooks apply" command which creates
+// or updates a webhook in each of the projects in a group.
+type HooksApplyCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[HooksApplyOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *HooksApplyCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] hooks apply [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create a webhook for --url triggered by --events in each of\n")
+	i18n.Fprintf(out, "    the selected projects.  Webhooks that already exist for the\n")
+	i18n.Fprintf(out, "    URL are updated if their settings differ.  Because Gitlab\n")
+	i18n.Fprintf(out, "    never returns the secret token, existing webhooks are always\n")
+	i18n.Fprintf(out, "    updated when --secret is given.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Apply Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewHooksApplyCommand returns a new, initialized HooksApplyCommand
+// instance.
+func NewHooksApplyCommand(
+	name string,
+	opts *HooksApplyOptions,
+	session *Session,
+) *HooksApplyCommand {
+
+	// Create the new command.
+	cmd := &HooksApplyCommand{
+		GitlabCommand: GitlabCommand[HooksApplyOptions]{
+			BasicCommand: BasicCommand[HooksApplyOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ApplyHook gives each webhook of the project for the URL of want the
+// settings of want or, if there is no such webhook, creates one.  If
+// secret is not empty, it becomes the secret token of the webhooks.
+// If dryRun is true, this function only prints what it would do
+// without actually doing it.
+func ApplyHook(
+	ctx context.Context,
+	s ProjectHooksManager, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	want *gitlab.ProjectHook,
+	secret string,
+	dryRun bool,
+) error {
+	hs, err := gitlab_util.GetAllProjectHooks(ctx, s, p.ID)
+	if err != nil {
+		return fmt.Errorf("ApplyHook: %w", err)
+	}
+	opts := hookOptions(want, secret)
+	reqOpts := gitlab.WithContext(gitlab_util.Uninterruptible(ctx))
+
+	// Update the existing webhooks.
+	found := false
+	for _, h := range hs {
+		if h.URL != want.URL {
+			continue
+		}
+		found = true
+		if secret == "" && sameHookSettings(h, want) {
+			logging.Printf("- Webhook %d in %q already up to date.\n",
+				h.ID, p.PathWithNamespace)
+			continue
+		}
+		logging.Printf("- Updating webhook %d in %q ... ", h.ID, p.PathWithNamespace)
+		if !dryRun {
+			_, _, err = s.EditProjectHook(p.ID, h.ID, opts, reqOpts)
+			if err != nil {
+				logging.Printf("Failed.\n")
+				return fmt.Errorf("ApplyHook: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+	}
+	if found {
+		return nil
+	}
+
+	// Create the webhook.
+	logging.Printf("- Adding webhook for %q to %q ... ", want.URL, p.PathWithNamespace)
+	if !dryRun {
+		addOpts := gitlab.AddProjectHookOptions(*opts)
+		_, _, err = s.AddProjectHook(p.ID, &addOpts, reqOpts)
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("ApplyHook: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *HooksApplyCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.URL == "" {
+		return result, i18n.Errorf("%w: url not set", ErrInvalidOption)
+	}
+	if len(cmd.options.Events) == 0 {
+		return result, i18n.Errorf("%w: events not set", ErrInvalidOption)
+	}
+	err = ValidateHookEvents(cmd.options.Events)
+	if err != nil {
+		return result, err
+	}
+
+	// Read the secret.
+	var secret string
+	if cmd.options.SecretFileName != "" {
+		secret, err = ReadSecret(cmd.options.SecretFileName)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Apply the webhook to each project.  A project that fails is
+	// recorded in the result.  If --keep-going is set, the remaining
+	// projects are still processed.
+	want := NewDesiredHook(cmd.options.URL, cmd.options.Events, !cmd.options.InsecureSSL)
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			name := p.PathWithNamespace + ":" + want.URL
+			hook.OnItemStart(name)
+			err := ApplyHook(ctx, cmd.client.Projects, p, want, secret, cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(name, err)
+				result.Fail(name, p, err)
+				return cmd.options.Continue(err)
+			}
+			hook.OnItemDone(name)
+			result.Succeed(name, p)
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err, "could not apply the webhook to %d project(s)")
+	return result, err
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestHooksApplyIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	session.globalOpts.Yes = true
+	alpha := server.AddProjectHook("foo/alpha", "https://audit.example.com/hook", http.StatusOK)
+	server.AddProjectHook("foo/alpha", "https://chat.example.com/hook", http.StatusOK)
+	secret := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := func(args ...string) string {
+		cmd := NewHooksCommand("hooks", &HooksOptions{}, session)
+		out, _, err := runCommand(t, cmd, append(args,
+			"--group", "foo", "--recursive",
+			"--url", "https://audit.example.com/hook"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+	urls := func(project string) []string {
+		var result []string
+		for _, h := range server.ProjectHooks(project) {
+			result = append(result, h.URL)
+		}
+		return result
+	}
+
+	// Apply the webhook with and without --dry-run.
+	run("apply", "--events", "push,merge_requests", "--dry-run")
+	dryRun := urls("foo/bar/delta")
+	run("apply", "--events", "push,merge_requests", "--secret", secret)
+	hooks := server.ProjectHooks("foo/bar/delta")
+	var delta *gitlab.ProjectHook
+	if len(hooks) == 1 {
+		delta = hooks[0]
+	} else {
+		t.Fatalf("hooks apply: expected 1 webhook in foo/bar/delta: %v", hooks)
+	}
+	listed := run("list")
+	updated := server.ProjectHooks("foo/alpha")[0]
+	createdSecret := server.HookToken(delta.ID)
+	updatedSecret := server.HookToken(alpha.ID)
+
+	// Applying the same settings again without a secret does nothing.
+	again := run("apply", "--events", "push,merge_requests")
+
+	// Delete the webhook with and without --dry-run.
+	run("delete", "--dry-run")
+	deleteDryRun := urls("foo/bar/delta")
+	run("delete")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"dry run", "[]", dryRun},
+		{"created events", "[merge_requests push]", hookEventNames(delta)},
+		{"created secret", "s3cr3t", createdSecret},
+		{"created ssl", true, delta.EnableSSLVerification},
+		{"updated id", alpha.ID, updated.ID},
+		{"updated events", "[merge_requests push]", hookEventNames(updated)},
+		{"updated secret", "s3cr3t", updatedSecret},
+		{"listed", true, strings.Contains(listed,
+			"https://audit.example.com/hook  merge_requests,push")},
+		{"up to date", strings.Count(listed, "https://audit.example.com/hook"),
+			strings.Count(again, "already up to date")},
+		{"delete dry run", "[https://audit.example.com/hook]", deleteDryRun},
+		{"deleted alpha", "[https://chat.example.com/hook]", urls("foo/alpha")},
+		{"deleted delta", "[]", urls("foo/bar/delta")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("hooks %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"apply", "--group", "foo", "--events", "push"},
+		{"apply", "--group", "foo", "--url", "https://audit.example.com/hook"},
+		{"apply", "--group", "foo", "--url", "https://audit.example.com/hook",
+			"--events", "bogus"},
+		{"delete", "--group", "foo"},
+	} {
+		cmd := NewHooksCommand("hooks", &HooksOptions{}, session)
+		_, err := cmd.Run(context.Background(), args)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("hooks %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
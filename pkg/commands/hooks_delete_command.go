@@ -0,0 +1,300 @@
+// This file provides the implementation for the "hooks delete" command
+// which deletes the webhooks for a URL from the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// HooksDeleteOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// HooksDeleteOptions are the options needed by this command.
+type HooksDeleteOptions struct {
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// Embed the options that control whether the remaining webhooks
+	// are deleted after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// URL is the URL of the webhooks to delete.  Defaults to "".
+	URL string `xml:"url"`
+}
+
+// Initialize initializes this HooksDeleteOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *HooksDeleteOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --url
+	flags.StringVar(&opts.URL, "url", opts.URL,
+		i18n.T("URL of the webhooks to delete"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// HooksDeleteCommand
+////////////////////////////////////////////////////////////////////////
+
+// HooksDeleteCommand implements the "hooks delete" command which
+// deletes the webhooks for a URL from the projects in a group.
+type HooksDeleteCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[HooksDeleteOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *HooksDeleteCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] hooks delete [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Delete every webhook for --url from the selected projects.\n")
+	i18n.Fprintf(out, "    The webhooks are listed and must be confirmed unless the\n")
+	i18n.Fprintf(out, "    global --yes option is passed.  When deleting more webhooks\n")
+	i18n.Fprintf(out, "    than --confirm-threshold, --i-understand must be passed,\n")
+	i18n.Fprintf(out, "    and the group must be typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Delete Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewHooksDeleteCommand returns a new, initialized HooksDeleteCommand
+// instance.
+func NewHooksDeleteCommand(
+	name string,
+	opts *HooksDeleteOptions,
+	session *Session,
+) *HooksDeleteCommand {
+
+	// Create the new command.
+	cmd := &HooksDeleteCommand{
+		GitlabCommand: GitlabCommand[HooksDeleteOptions]{
+			BasicCommand: BasicCommand[HooksDeleteOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectHook is a webhook of a project.
+type ProjectHook struct {
+
+	// Project is the project that has the webhook.
+	Project *gitlab.Project
+
+	// Hook is the webhook.
+	Hook *gitlab.ProjectHook
+}
+
+// DeleteHook deletes the webhook of the project.  If dryRun is true,
+// this function only prints what it would do without actually doing
+// it.
+func DeleteHook(
+	ctx context.Context,
+	s gitlab_util.ProjectHookDeleter, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	h *gitlab.ProjectHook,
+	dryRun bool,
+) error {
+	logging.Printf("- Deleting webhook %d in %q ... ", h.ID, p.PathWithNamespace)
+	if !dryRun {
+		_, err := s.DeleteProjectHook(p.ID, h.ID,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("DeleteHook: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// DeleteHooks deletes the webhooks stopping after the current webhook
+// if interrupted.  If keepGoing is true, a webhook that cannot be
+// deleted is recorded in the result, and the remaining webhooks are
+// still deleted.  Otherwise, no webhook is deleted after the first one
+// that fails.  If dryRun is true, this function only prints what it
+// would do without actually doing it.
+func DeleteHooks(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.ProjectHookDeleter, /* was *gitlab.ProjectsService */
+	hooks []*ProjectHook,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, ph := range hooks {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("DeleteHooks: %w", err)
+		}
+		name := hookName(ph)
+		hook.OnItemStart(name)
+		err := DeleteHook(ctx, s, ph.Project, ph.Hook, dryRun)
+		if err != nil {
+			hook.OnError(name, err)
+			result.Fail(name, ph.Hook, err)
+			if !keepGoing {
+				return fmt.Errorf("DeleteHooks: %w", err)
+			}
+			continue
+		}
+		hook.OnItemDone(name)
+		result.Succeed(name, ph.Hook)
+	}
+	return nil
+}
+
+// hookName returns the name of the webhook used in the result.
+func hookName(ph *ProjectHook) string {
+	return ph.Project.PathWithNamespace + ":" + ph.Hook.URL
+}
+
+// Run is the entry point for this command.
+func (cmd *HooksDeleteCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.URL == "" {
+		return result, i18n.Errorf("%w: url not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Collect the webhooks for the URL.  If --keep-going is set, a
+	// project whose webhooks cannot be read is recorded in the result,
+	// and the remaining projects are still searched.
+	var hooks []*ProjectHook
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hs, err := gitlab_util.GetAllProjectHooks(ctx, cmd.client.Projects, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return cmd.options.Continue(err)
+			}
+			for _, h := range hs {
+				if h.URL == cmd.options.URL {
+					hooks = append(hooks, &ProjectHook{Project: p, Hook: h})
+				}
+			}
+			return true, nil
+		})
+	if err != nil {
+		err = cmd.options.Finish(os.Stdout, result, err, "could not delete %d webhook(s)")
+		return result, err
+	}
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, ph := range hooks {
+			names = append(names, hookName(ph))
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"), names,
+			cmd.options.Group, cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Delete the webhooks.
+	err = DeleteHooks(ctx, result, cmd.client.Projects, hooks,
+		cmd.options.KeepGoing, cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not delete %d webhook(s)")
+	return result, err
+}
@@ -0,0 +1,193 @@
+// This file provides the implementation for the "hooks list"
+// command which lists the webhooks of the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// HooksListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// HooksListOptions are the options needed by this command.
+type HooksListOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// URL is the URL of the webhooks to list.  Defaults to "" which
+	// matches any URL.
+	URL string `xml:"url"`
+}
+
+// Initialize initializes this HooksListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *HooksListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --url
+	flags.StringVar(&opts.URL, "url", opts.URL,
+		i18n.T("URL of the webhooks to list"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// HooksListCommand
+////////////////////////////////////////////////////////////////////////
+
+// HooksListCommand implements the "hooks list" command which lists the
+// webhooks of the projects in a group.
+type HooksListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[HooksListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *HooksListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] hooks list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the webhooks of the selected projects.  Each webhook is\n")
+	i18n.Fprintf(out, "    printed as its project, ID, URL, and the events that trigger\n")
+	i18n.Fprintf(out, "    it.  Secret tokens are never printed because Gitlab never\n")
+	i18n.Fprintf(out, "    returns them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewHooksListCommand returns a new, initialized
+// HooksListCommand instance.
+func NewHooksListCommand(
+	name string,
+	opts *HooksListOptions,
+	session *Session,
+) *HooksListCommand {
+
+	// Create the new command.
+	cmd := &HooksListCommand{
+		GitlabCommand: GitlabCommand[HooksListOptions]{
+			BasicCommand: BasicCommand[HooksListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *HooksListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print the webhooks of each project.  For --output json, the
+	// webhooks are collected and printed together at the end.  A
+	// project whose webhooks cannot be listed is recorded as failed,
+	// and the remaining projects are still listed.
+	hooks := []*gitlab.ProjectHook{}
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hs, err := gitlab_util.GetAllProjectHooks(ctx, cmd.client.Projects, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, h := range hs {
+				if cmd.options.URL != "" && h.URL != cmd.options.URL {
+					continue
+				}
+				if cmd.session.OutputJSON() {
+					hooks = append(hooks, h)
+				} else {
+					fmt.Printf("%-40s  %-6d  %s  %s\n", p.PathWithNamespace,
+						h.ID, h.URL, strings.Join(hookEventNames(h), ","))
+				}
+				result.Succeed(p.PathWithNamespace+":"+h.URL, h)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the webhooks as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, hooks)
+		if err != nil {
+			return result, err
+		}
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not list the webhooks of %d project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,320 @@
+// This file provides the implementation for the "hooks rotate-secret"
+// command which updates the secret token of every webhook that points
+// at a URL so rotating the secret on the receiving side does not
+// require editing the webhook of each project by hand.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// HooksRotateSecretOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// HooksRotateSecretOptions are the options needed by this command.
+type HooksRotateSecretOptions struct {
+
+	// Embed the options that control whether the remaining webhooks
+	// are updated after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// SecretFileName is the name of the file holding the new secret
+	// token.  The secret is read from a file so it does not show up
+	// in the process list or the options.xml file.  Leading and
+	// trailing white space is ignored.  Defaults to "".
+	SecretFileName string `xml:"secret-file-name"`
+
+	// URL is the URL of the webhooks whose secret token is updated.
+	// Defaults to "".
+	URL string `xml:"url"`
+}
+
+// Initialize initializes this HooksRotateSecretOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *HooksRotateSecretOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --secret
+	flags.StringVar(&opts.SecretFileName, "secret", opts.SecretFileName,
+		i18n.T("name of the file holding the new secret token"))
+
+	// --url
+	flags.StringVar(&opts.URL, "url", opts.URL,
+		i18n.T("URL of the webhooks whose secret token is updated"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// HooksRotateSecretCommand
+////////////////////////////////////////////////////////////////////////
+
+// HooksRotateSecretCommand implements the "hooks rotate-secret"
+// command which updates the secret token of webhooks.
+type HooksRotateSecretCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[HooksRotateSecretOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *HooksRotateSecretCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] hooks rotate-secret [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Update the secret token of every webhook of the selected\n")
+	i18n.Fprintf(out, "    projects that points at the --url to the secret held in the\n")
+	i18n.Fprintf(out, "    --secret file and print the affected projects.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Rotate-Secret Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewHooksRotateSecretCommand returns a new, initialized
+// HooksRotateSecretCommand instance.
+func NewHooksRotateSecretCommand(
+	name string,
+	opts *HooksRotateSecretOptions,
+	session *Session,
+) *HooksRotateSecretCommand {
+
+	// Create the new command.
+	cmd := &HooksRotateSecretCommand{
+		GitlabCommand: GitlabCommand[HooksRotateSecretOptions]{
+			BasicCommand: BasicCommand[HooksRotateSecretOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectHooksService is an abstraction of gitlab.ProjectsService
+// which lists and edits the webhooks of projects.
+type ProjectHooksService interface {
+	gitlab_util.ProjectHooksLister
+	gitlab_util.ProjectHookEditor
+}
+
+// HooksRotateSecretServices are the Gitlab services needed to rotate
+// the secret token of webhooks.
+type HooksRotateSecretServices struct {
+	Groups   gitlab_util.ProjectsInGroupLister /* was *gitlab.GroupsService */
+	Projects ProjectHooksService               /* was *gitlab.ProjectsService */
+}
+
+// ReadSecret returns the secret held in the file without leading and
+// trailing white space.
+func ReadSecret(fname string) (string, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return "", fmt.Errorf("ReadSecret: %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", i18n.Errorf("ReadSecret: empty secret in file: %q", fname)
+	}
+	return secret, nil
+}
+
+// RotateHookSecrets updates the secret token of each webhook of each
+// selected project that points at the URL and records each updated
+// webhook as a *gitlab.ProjectHook in the result.  If keepGoing is
+// true, a webhook that cannot be updated is recorded as failed in the
+// result, and the remaining webhooks are still updated.  Otherwise, no
+// webhook is updated after the first one that fails.  If dryRun is
+// true, this function only prints what it would without actually doing
+// it.
+func RotateHookSecrets(
+	ctx context.Context,
+	result *Result,
+	selector *ProjectSelectorOptions,
+	s HooksRotateSecretServices,
+	url string,
+	secret string,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hs, err := gitlab_util.GetAllProjectHooks(ctx, s.Projects, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				if !keepGoing {
+					return false, err
+				}
+				return true, nil
+			}
+			for _, h := range hs {
+				if h.URL != url {
+					continue
+				}
+				name := p.PathWithNamespace + ":" + h.URL
+				hook.OnItemStart(name)
+				logging.Printf("- Rotating secret of webhook %d in %q ... ",
+					h.ID, p.PathWithNamespace)
+				if !dryRun {
+
+					// Gitlab requires the URL when editing a webhook.
+					_, _, err = s.Projects.EditProjectHook(p.ID, h.ID,
+						&gitlab.EditProjectHookOptions{
+							URL:   gitlab.Ptr(h.URL),
+							Token: gitlab.Ptr(secret),
+						},
+						gitlab.WithContext(ctx))
+					if err != nil {
+						logging.Printf("Failed.\n")
+						err = fmt.Errorf(
+							"RotateHookSecrets: %w", gitlab_util.ClassifyError(err))
+						hook.OnError(name, err)
+						result.Fail(name, h, err)
+						if !keepGoing {
+							return false, err
+						}
+						continue
+					}
+				}
+				logging.Printf("Done.\n")
+				hook.OnItemDone(name)
+				result.Succeed(name, h)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("RotateHookSecrets: %w", err)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *HooksRotateSecretCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.URL == "" {
+		return result, i18n.Errorf("%w: url not set", ErrInvalidOption)
+	}
+	if cmd.options.SecretFileName == "" {
+		return result, i18n.Errorf("%w: secret not set", ErrInvalidOption)
+	}
+
+	// Read the secret.
+	secret, err := ReadSecret(cmd.options.SecretFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Rotate the secrets.
+	err = RotateHookSecrets(ctx, result, &cmd.options.ProjectSelectorOptions,
+		HooksRotateSecretServices{
+			Groups:   cmd.client.Groups,
+			Projects: cmd.client.Projects,
+		}, cmd.options.URL, secret, cmd.options.KeepGoing, cmd.options.DryRun)
+	if err != nil && !cmd.options.KeepGoing {
+		return result, err
+	}
+
+	// Print the affected projects.
+	fmt.Printf("\n")
+	i18n.Printf("Rotated the secret of %d webhook(s) in the following project(s):\n",
+		len(result.Succeeded()))
+	var previous string
+	for _, item := range result.Succeeded() {
+		path, _, _ := strings.Cut(item.Name, ":")
+		if path != previous {
+			fmt.Printf("  %s\n", path)
+			previous = path
+		}
+	}
+
+	err = cmd.options.Finish(os.Stdout, result, err,
+		"could not rotate the secret of %d webhook(s)")
+	return result, err
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHooksRotateSecretIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	alpha := server.AddProjectHook("foo/alpha", "https://ci.example.com/hook", http.StatusOK)
+	chat := server.AddProjectHook("foo/alpha", "https://chat.example.com/hook", http.StatusOK)
+	delta := server.AddProjectHook("foo/bar/delta", "https://ci.example.com/hook", http.StatusOK)
+	secret := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(secret, []byte("s3cr3t\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := func(args ...string) string {
+		cmd := NewHooksCommand("hooks", &HooksOptions{}, session)
+		out, _, err := runCommand(t, cmd, append([]string{
+			"rotate-secret", "--group", "foo", "--recursive",
+			"--url", "https://ci.example.com/hook", "--secret", secret,
+		}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+
+	// Rotate the secrets with and without --dry-run.
+	run("--dry-run")
+	dryRun := server.HookToken(alpha.ID)
+	out := run()
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"dry run", "", dryRun},
+		{"alpha", "s3cr3t", server.HookToken(alpha.ID)},
+		{"delta", "s3cr3t", server.HookToken(delta.ID)},
+		{"other url", "", server.HookToken(chat.ID)},
+		{"affected projects", true,
+			strings.HasSuffix(out, "  foo/alpha\n  foo/bar/delta\n")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("hooks rotate-secret %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
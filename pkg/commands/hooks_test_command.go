@@ -0,0 +1,255 @@
+// This file provides the implementation for the "hooks test" command
+// which triggers a test delivery for every webhook of the selected
+// projects and reports the endpoints that respond with errors so
+// broken integrations are found before they silently drop events.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// HooksTestOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// HooksTestOptions are the options needed by this command.
+type HooksTestOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Trigger is the event for which the test delivery is sent.
+	// Defaults to "push_events".
+	Trigger string `xml:"trigger"`
+}
+
+// Initialize initializes this HooksTestOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *HooksTestOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --trigger
+	if opts.Trigger == "" {
+		opts.Trigger = "push_events"
+	}
+	flags.StringVar(&opts.Trigger, "trigger", opts.Trigger,
+		i18n.T("event for which the test delivery is sent (e.g., push_events or merge_requests_events)"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// HooksTestCommand
+////////////////////////////////////////////////////////////////////////
+
+// HooksTestCommand implements the "hooks test" command which tests the
+// webhooks of each project.
+type HooksTestCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[HooksTestOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *HooksTestCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] hooks test [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Trigger a test delivery for every webhook of the selected\n")
+	i18n.Fprintf(out, "    projects and report the webhooks whose endpoints respond\n")
+	i18n.Fprintf(out, "    with an error.  The command fails if any webhook fails.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Test Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewHooksTestCommand returns a new, initialized HooksTestCommand
+// instance.
+func NewHooksTestCommand(
+	name string,
+	opts *HooksTestOptions,
+	session *Session,
+) *HooksTestCommand {
+
+	// Create the new command.
+	cmd := &HooksTestCommand{
+		GitlabCommand: GitlabCommand[HooksTestOptions]{
+			BasicCommand: BasicCommand[HooksTestOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// hookTriggers are the events for which Gitlab can send a test
+// delivery.
+var hookTriggers = []string{
+	"confidential_issues_events",
+	"confidential_note_events",
+	"emoji_events",
+	"issues_events",
+	"job_events",
+	"merge_requests_events",
+	"note_events",
+	"pipeline_events",
+	"push_events",
+	"releases_events",
+	"resource_access_token_events",
+	"tag_push_events",
+	"wiki_page_events",
+}
+
+// HookTest is the outcome of testing a webhook.
+type HookTest struct {
+
+	// Path is the full path of the project.
+	Path string
+
+	// Hook is the webhook that was tested.
+	Hook *gitlab.ProjectHook
+
+	// Err is the reason the test delivery failed or nil if it
+	// succeeded.
+	Err error
+}
+
+// TestHooks triggers a test delivery of the trigger event to each
+// webhook of each selected project and records the outcome of each
+// test as a *HookTest in the result.  The returned error is only for
+// problems selecting the projects; failed deliveries are only recorded
+// in the result.
+func TestHooks(
+	ctx context.Context,
+	result *Result,
+	selector *ProjectSelectorOptions,
+	client *gitlab.Client,
+	trigger string,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err := selector.ForEachProject(ctx, client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hs, err := gitlab_util.GetAllProjectHooks(ctx, client.Projects, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, h := range hs {
+				name := p.PathWithNamespace + ":" + h.URL
+				hook.OnItemStart(name)
+				t := &HookTest{Path: p.PathWithNamespace, Hook: h}
+				t.Err = gitlab_util.TestProjectHook(ctx, client, p.ID, h.ID, trigger)
+				if t.Err != nil {
+					logging.Printf("- Testing %q in %q ... Failed.\n",
+						h.URL, p.PathWithNamespace)
+					logging.Printf("  Error: %v\n", t.Err)
+					hook.OnError(name, t.Err)
+					result.Fail(name, t, t.Err)
+					continue
+				}
+				logging.Printf("- Testing %q in %q ... OK.\n",
+					h.URL, p.PathWithNamespace)
+				hook.OnItemDone(name)
+				result.Succeed(name, t)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("TestHooks: %w", err)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *HooksTestCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if !slices.Contains(hookTriggers, cmd.options.Trigger) {
+		return result, i18n.Errorf("%w: invalid trigger: %q",
+			ErrInvalidOption, cmd.options.Trigger)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Test the webhooks.
+	err = TestHooks(ctx, result, &cmd.options.ProjectSelectorOptions,
+		cmd.client, cmd.options.Trigger)
+	if err != nil {
+		return result, err
+	}
+
+	// Report whether any webhooks failed.
+	failed := len(result.Failed())
+	fmt.Printf("\n")
+	i18n.Printf("Tested %d webhook(s), %d failed.\n", result.Processed(), failed)
+	if failed > 0 {
+		return result, i18n.Errorf("%d webhook(s) failed", failed)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHooksTestIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	server.AddProjectHook("foo/alpha", "https://ci.example.com/hook", http.StatusOK)
+	server.AddProjectHook("foo/alpha", "https://chat.example.com/hook", http.StatusInternalServerError)
+	server.AddProjectHook("foo/bar/delta", "https://ci.example.com/hook", http.StatusNoContent)
+	cmd := NewHooksCommand("hooks", &HooksOptions{}, session)
+
+	// Test the webhooks.
+	_, result, err := runCommand(t, cmd, []string{"test",
+		"--group", "foo", "--recursive"})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	// Verify the results.
+	var succeeded, failed []string
+	for _, item := range result.Succeeded() {
+		succeeded = append(succeeded, item.Name)
+	}
+	for _, item := range result.Failed() {
+		failed = append(failed, item.Name)
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"succeeded", []string{
+			"foo/alpha:https://ci.example.com/hook",
+			"foo/bar/delta:https://ci.example.com/hook",
+		}, succeeded},
+		{"failed", []string{"foo/alpha:https://chat.example.com/hook"}, failed},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("hooks test %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
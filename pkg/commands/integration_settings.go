@@ -0,0 +1,136 @@
+// This file provides the settings file that holds the settings the
+// "projects integrations set" command applies to an integration.
+
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// IntegrationSettings are the settings of an integration.  The names
+// of the settings are the names of the parameters of the integration
+// in the Gitlab REST API (e.g., "webhook" and "channel" for Slack or
+// "url" and "username" for Jira) including the event flags (e.g.,
+// "push_events").  For example:
+//
+//	<integration-settings>
+//	  <setting name="webhook">https://hooks.slack.com/services/...</setting>
+//	  <setting name="channel">builds</setting>
+//	  <setting name="push_events">true</setting>
+//	</integration-settings>
+type IntegrationSettings struct {
+	XMLName xml.Name `xml:"integration-settings"`
+
+	// Settings are the settings in the order in which they appear in
+	// the file.
+	Settings []IntegrationSetting `xml:"setting"`
+}
+
+// IntegrationSetting is the name and value of a single setting.
+type IntegrationSetting struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+// LoadIntegrationSettings loads and validates the integration settings
+// from the file.  Leading and trailing white space is removed from
+// each value.
+func LoadIntegrationSettings(fileName string) (*IntegrationSettings, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("LoadIntegrationSettings: %w", err)
+	}
+	settings := &IntegrationSettings{}
+	err = xml.Unmarshal(data, settings)
+	if err != nil {
+		return nil, fmt.Errorf("LoadIntegrationSettings: %s: %w", fileName, err)
+	}
+	for i := range settings.Settings {
+		settings.Settings[i].Value = strings.TrimSpace(settings.Settings[i].Value)
+	}
+	err = settings.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("LoadIntegrationSettings: %s: %w", fileName, err)
+	}
+	return settings, nil
+}
+
+// Validate returns an error if there are no settings or if any
+// setting does not have a name or has the same name as another
+// setting.
+func (settings *IntegrationSettings) Validate() error {
+	if len(settings.Settings) == 0 {
+		return i18n.Errorf("no settings")
+	}
+	var names []string
+	for _, s := range settings.Settings {
+		if s.Name == "" {
+			return i18n.Errorf("setting without a name")
+		}
+		if slices.Contains(names, s.Name) {
+			return i18n.Errorf("duplicate setting: %q", s.Name)
+		}
+		names = append(names, s.Name)
+	}
+	return nil
+}
+
+// Map returns the settings as a map from the name to the value of
+// each setting.
+func (settings *IntegrationSettings) Map() map[string]string {
+	result := make(map[string]string)
+	for _, s := range settings.Settings {
+		result[s.Name] = s.Value
+	}
+	return result
+}
+
+// IntegrationSettingChange is the change to a single setting of an
+// integration.
+type IntegrationSettingChange struct {
+
+	// Name is the name of the setting.
+	Name string
+
+	// Old is the current value of the setting.  It is only valid if
+	// Known is true.
+	Old string
+
+	// Known is whether the current value is known.  Gitlab does not
+	// return secrets so their current value is never known.
+	Known bool
+
+	// New is the value to which the setting is changed.
+	New string
+}
+
+// DiffIntegrationSettings returns the changes needed to change the
+// existing settings of an integration which can be nil if the
+// integration has never been configured to the new settings.  A
+// setting whose current value is not known is always changed.  The
+// changes are in the same order as the new settings.
+func DiffIntegrationSettings(
+	existing map[string]string,
+	settings *IntegrationSettings,
+) []*IntegrationSettingChange {
+	var changes []*IntegrationSettingChange
+	for _, s := range settings.Settings {
+		old, known := existing[s.Name]
+		if known && old == s.Value {
+			continue
+		}
+		changes = append(changes, &IntegrationSettingChange{
+			Name:  s.Name,
+			Old:   old,
+			Known: known,
+			New:   s.Value,
+		})
+	}
+	return changes
+}
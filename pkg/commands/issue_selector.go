@@ -0,0 +1,238 @@
+// This file provides the options shared by the commands that operate
+// on a selection of issues across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+// IssueSelectorOptions select issues by the project they belong to,
+// their author, labels, and title.  They are embedded in the options
+// of the "issues" subcommands so the options have the same names and
+// meaning everywhere.  Because the struct is embedded, its XML
+// elements appear directly in the options of the embedding command in
+// the options.xml file.
+type IssueSelectorOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Author is the username of the author of the issues.  Defaults
+	// to "" which matches any author.
+	Author string `xml:"author"`
+
+	// Labels are the labels the issues must all have.  Defaults to no
+	// labels which matches any issue.
+	Labels string_slice.StringSlice `xml:"labels>label"`
+
+	// TitleExpr is the regular expression that filters the issues by
+	// their title.  Defaults to "" which matches any title.
+	TitleExpr string `xml:"title-expr"`
+}
+
+// Initialize initializes this IssueSelectorOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *IssueSelectorOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --author
+	flags.StringVar(&opts.Author, "author", opts.Author,
+		i18n.T("username of the author of the issues"))
+
+	// --labels
+	flags.Var(&opts.Labels, "labels",
+		i18n.T("comma-separated list of labels the issues must all have"))
+
+	// --title-expr
+	flags.StringVar(&opts.TitleExpr, "title-expr", opts.TitleExpr,
+		i18n.T("regular expression that selects issues by title"))
+}
+
+// Validate returns an error if the options cannot select any issues.
+func (opts *IssueSelectorOptions) Validate() error {
+	err := opts.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return err
+	}
+	_, err = regexp.Compile(opts.TitleExpr)
+	if err != nil {
+		return i18n.Errorf("%w: invalid title-expr: %q: %v",
+			ErrInvalidOption, opts.TitleExpr, err)
+	}
+	return nil
+}
+
+// ListOptions returns the options for listing the issues in the state
+// (e.g., "opened") which pass the filters Gitlab can apply.  If state
+// is "all", issues in any state are listed.  Callers can add more
+// filters (e.g., UpdatedBefore) to the returned options.
+func (opts *IssueSelectorOptions) ListOptions(state string) *gitlab.ListProjectIssuesOptions {
+	result := &gitlab.ListProjectIssuesOptions{
+		State: gitlab.Ptr(state),
+	}
+	if opts.Author != "" {
+		result.AuthorUsername = gitlab.Ptr(opts.Author)
+	}
+	if len(opts.Labels) > 0 {
+		labels := gitlab.LabelOptions(opts.Labels)
+		result.Labels = &labels
+	}
+	return result
+}
+
+// ForEachIssue calls f once for each selected issue in each selected
+// project that also passes the filters in listOpts which is usually
+// the return value of ListOptions().  If the issues of a project
+// cannot be listed, the failure is recorded in the result, and the
+// remaining projects are still processed.  The function f must return
+// true and no error to indicate that it wants to continue being called
+// with the remaining issues.  If f returns an error, it will be
+// forwarded to the caller as the error return value for this function.
+func (opts *IssueSelectorOptions) ForEachIssue(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	issues gitlab_util.ProjectIssuesLister, /* was *gitlab.IssuesService */
+	listOpts *gitlab.ListProjectIssuesOptions,
+	f func(p *gitlab.Project, issue *gitlab.Issue) (bool, error),
+) error {
+	titleExpr, err := regexp.Compile(opts.TitleExpr)
+	if err != nil {
+		return fmt.Errorf("ForEachIssue: %w", err)
+	}
+	err = opts.ForEachProject(ctx, groups,
+		func(p *gitlab.Project) (bool, error) {
+			is, err := gitlab_util.GetAllProjectIssuesWithOptions(
+				ctx, issues, p.ID, listOpts)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, issue := range is {
+				if !titleExpr.MatchString(issue.Title) {
+					continue
+				}
+				ok, err := f(p, issue)
+				if !ok || err != nil {
+					return ok, err
+				}
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("ForEachIssue: %w", err)
+	}
+	return nil
+}
+
+// ForEachIssueGraphQL is the same as ForEachIssue() except it uses
+// the GraphQL API which lists the issues of all the selected projects
+// in far fewer round trips.  Only the State, AuthorUsername, and
+// Labels fields of listOpts are used, and only the fields documented
+// for gitlab_util.ForEachIssueInGroupGraphQL() are set for the
+// projects and issues passed to f.  If the GraphQL API is unavailable
+// or the projects are also selected by a filter, this function falls
+// back to ForEachIssue().
+func (opts *IssueSelectorOptions) ForEachIssueGraphQL(
+	ctx context.Context,
+	result *Result,
+	client *gitlab.Client,
+	listOpts *gitlab.ListProjectIssuesOptions,
+	f func(p *gitlab.Project, issue *gitlab.Issue) (bool, error),
+) error {
+	if opts.filter != nil {
+		return opts.ForEachIssue(
+			ctx, result, client.Groups, client.Issues, listOpts, f)
+	}
+	titleExpr, err := regexp.Compile(opts.TitleExpr)
+	if err != nil {
+		return fmt.Errorf("ForEachIssueGraphQL: %w", err)
+	}
+	err = gitlab_util.ForEachIssueInGroupGraphQL(
+		ctx, client, opts.Group, opts.EffectiveExpr(), opts.Recursive,
+		listOpts,
+		func(p *gitlab.Project, issue *gitlab.Issue) (bool, error) {
+			if !titleExpr.MatchString(issue.Title) {
+				return true, nil
+			}
+			return f(p, issue)
+		})
+	if errors.Is(err, gitlab_util.ErrGraphQLUnavailable) {
+		return opts.ForEachIssue(
+			ctx, result, client.Groups, client.Issues, listOpts, f)
+	}
+	if err != nil {
+		return fmt.Errorf("ForEachIssueGraphQL: %w", err)
+	}
+	return nil
+}
+
+// issueName returns the name used to identify the issue in a Result
+// and in messages which is the usual Gitlab reference of the form
+// "group/project#iid".
+func issueName(p *gitlab.Project, issue *gitlab.Issue) string {
+	return fmt.Sprintf("%s#%d", p.PathWithNamespace, issue.IID)
+}
+
+// UpdateIssues calls update once for each issue selected by the
+// selector that also passes the filters in listOpts.  The verb (e.g.,
+// "Closing") is used in the progress messages.  If dryRun is true,
+// update is not called, and this function only prints what it would
+// do.  An issue that cannot be updated is recorded as failed in the
+// result.  If keepGoing is true, the remaining issues are still
+// updated.  Otherwise, no issue is updated after the first one that
+// fails.
+func UpdateIssues(
+	ctx context.Context,
+	result *Result,
+	selector *IssueSelectorOptions,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	issues gitlab_util.ProjectIssuesLister, /* was *gitlab.IssuesService */
+	listOpts *gitlab.ListProjectIssuesOptions,
+	verb string,
+	update func(p *gitlab.Project, issue *gitlab.Issue) error,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err := selector.ForEachIssue(ctx, result, groups, issues, listOpts,
+		func(p *gitlab.Project, issue *gitlab.Issue) (bool, error) {
+			name := issueName(p, issue)
+			hook.OnItemStart(name)
+			logging.Printf("- %s issue %q ... ", verb, name)
+			if !dryRun {
+				err := update(p, issue)
+				if err != nil {
+					logging.Printf("Failed.\n")
+					hook.OnError(name, err)
+					result.Fail(name, issue, err)
+					if !keepGoing {
+						return false, err
+					}
+					return true, nil
+				}
+			}
+			logging.Printf("Done.\n")
+			hook.OnItemDone(name)
+			result.Succeed(name, issue)
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("UpdateIssues: %w", err)
+	}
+	return nil
+}
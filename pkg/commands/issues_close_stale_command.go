@@ -0,0 +1,212 @@
+// This file provides the implementation for the "issues close-stale"
+// command which closes the opened issues that have had no activity
+// for a number of days across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// IssuesCloseStaleOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// IssuesCloseStaleOptions are the options needed by this command.
+type IssuesCloseStaleOptions struct {
+
+	// Embed the options that control whether the remaining issues
+	// are closed after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the issues.
+	IssueSelectorOptions
+
+	// Days is the number of days without activity after which an
+	// issue is stale.  Defaults to 90.
+	Days uint64 `xml:"days"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this IssuesCloseStaleOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *IssuesCloseStaleOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --test-expr, --title-expr
+	opts.IssueSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// --days
+	if opts.Days == 0 {
+		opts.Days = 90
+	}
+	flags.Uint64Var(&opts.Days, "days", opts.Days,
+		i18n.T("number of days without activity after which an issue is stale"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// IssuesCloseStaleCommand
+////////////////////////////////////////////////////////////////////////
+
+// IssuesCloseStaleCommand implements the "issues close-stale" command
+// which closes the opened issues that have had no activity for a
+// number of days across the projects in a group.
+type IssuesCloseStaleCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[IssuesCloseStaleOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *IssuesCloseStaleCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] issues close-stale [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Close the selected opened issues that have not been updated\n")
+	i18n.Fprintf(out, "    in the last --days days.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Close Stale Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewIssuesCloseStaleCommand returns a new, initialized
+// IssuesCloseStaleCommand instance.
+func NewIssuesCloseStaleCommand(
+	name string,
+	opts *IssuesCloseStaleOptions,
+	session *Session,
+) *IssuesCloseStaleCommand {
+
+	// Create the new command.
+	cmd := &IssuesCloseStaleCommand{
+		GitlabCommand: GitlabCommand[IssuesCloseStaleOptions]{
+			BasicCommand: BasicCommand[IssuesCloseStaleOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// CloseIssue closes the issue.
+func CloseIssue(
+	ctx context.Context,
+	s gitlab_util.IssueUpdater, /* was *gitlab.IssuesService */
+	p *gitlab.Project,
+	issue *gitlab.Issue,
+) error {
+	_, _, err := s.UpdateIssue(p.ID, issue.IID,
+		&gitlab.UpdateIssueOptions{
+			StateEvent: gitlab.Ptr("close"),
+		},
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("CloseIssue: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *IssuesCloseStaleCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.IssueSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Days == 0 {
+		return result, i18n.Errorf("%w: invalid days: %v",
+			ErrInvalidOption, cmd.options.Days)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Close the opened issues that have not been updated since the
+	// cutoff.
+	listOpts := cmd.options.ListOptions("opened")
+	listOpts.UpdatedBefore = gitlab.Ptr(
+		time.Now().AddDate(0, 0, -int(cmd.options.Days)))
+	err = UpdateIssues(
+		ctx, result, &cmd.options.IssueSelectorOptions,
+		cmd.client.Groups, cmd.client.Issues, listOpts, i18n.T("Closing stale"),
+		func(p *gitlab.Project, issue *gitlab.Issue) error {
+			return CloseIssue(ctx, cmd.client.Issues, p, issue)
+		},
+		cmd.options.KeepGoing,
+		cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not close %d issue(s)")
+	return result, err
+}
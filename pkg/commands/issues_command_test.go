@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIssuesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddProject("foo", "triage")
+	recent := time.Now().Add(-time.Hour)
+	old := time.Now().AddDate(0, 0, -100)
+	for _, d := range []struct {
+		project string
+		author  string
+		title   string
+		updated time.Time
+		labels  []string
+	}{
+		{"foo/alpha", "aberns", "Crash on start", old, []string{"bug"}},
+		{"foo/alpha", "bcrocket", "Add dark mode", recent, []string{"feature"}},
+		{"foo/beta", "aberns", "Typo in docs", old, []string{"docs"}},
+		{"foo/bar/delta", "bcrocket", "Flaky test", old, []string{"bug", "ci"}},
+	} {
+		server.AddIssue(d.project, d.author, d.title, d.updated, d.labels...)
+	}
+	session := NewSessionWithClient(server.Client(t))
+
+	// run runs the "issues" subcommand and returns the names of the
+	// issues that succeeded.
+	run := func(args ...string) []string {
+		cmd := NewIssuesCommand("issues", &IssuesOptions{}, session)
+		_, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("issues %v: unexpected error: %v", args, err)
+		}
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	// Verify the filters.
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"list", "--group", "foo", "-r"},
+			[]string{"foo/alpha#1", "foo/alpha#2", "foo/beta#1", "foo/bar/delta#1"}},
+		{[]string{"list", "--group", "foo", "-r", "--author", "bcrocket", "--labels", "bug"},
+			[]string{"foo/bar/delta#1"}},
+		{[]string{"list", "--group", "foo", "--title-expr", "^(Crash|Typo)"},
+			[]string{"foo/alpha#1", "foo/beta#1"}},
+		{[]string{"list", "--group", "foo", "--graphql", "--author", "aberns"},
+			[]string{"foo/alpha#1", "foo/beta#1"}},
+		{[]string{"close-stale", "--group", "foo", "-r", "--dry-run"},
+			[]string{"foo/alpha#1", "foo/beta#1", "foo/bar/delta#1"}},
+		{[]string{"relabel", "--group", "foo", "-r", "--labels", "bug",
+			"--add-labels", "defect", "--remove-labels", "bug"},
+			[]string{"foo/alpha#1", "foo/bar/delta#1"}},
+		{[]string{"move", "--group", "foo", "--expr", "beta|triage", "--to-project", "foo/triage"},
+			[]string{"foo/beta#1"}},
+		{[]string{"close-stale", "--group", "foo", "--days", "30"},
+			[]string{"foo/alpha#1"}},
+		{[]string{"list", "--group", "foo", "-r"},
+			[]string{"foo/alpha#2", "foo/bar/delta#1", "foo/triage#1"}},
+		{[]string{"list", "--group", "foo", "--state", "closed"},
+			[]string{"foo/alpha#1", "foo/beta#1"}},
+	}
+	for _, d := range data {
+		actual := run(d.args...)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("issues %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+	}
+
+	// Verify the labels.
+	type Labels []struct {
+		project  string
+		title    string
+		expected []string
+	}
+	labels := Labels{
+		{"foo/alpha", "Crash on start", []string{"defect"}},
+		{"foo/alpha", "Add dark mode", []string{"feature"}},
+		{"foo/bar/delta", "Flaky test", []string{"ci", "defect"}},
+		{"foo/triage", "Typo in docs", []string{"docs"}},
+	}
+	for _, d := range labels {
+		actual := server.IssueLabels(d.project, d.title)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("issues %s %q: expected=%v  actual=%v",
+				d.project, d.title, d.expected, actual)
+		}
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"list", "--group", "foo", "--state", "bogus"},
+		{"relabel", "--group", "foo"},
+		{"move", "--group", "foo"},
+	} {
+		cmd := NewIssuesCommand("issues", &IssuesOptions{}, session)
+		_, err := cmd.Run(context.Background(), args)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("issues %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
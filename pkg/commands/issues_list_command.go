@@ -0,0 +1,213 @@
+// This file provides the implementation for the "issues list" command
+// which lists the issues across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// IssuesListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// IssuesListOptions are the options needed by this command.
+type IssuesListOptions struct {
+
+	// Embed the options that select the issues.
+	IssueSelectorOptions
+
+	// GraphQL controls whether issues are listed using the GraphQL
+	// API instead of the REST API.  The GraphQL API needs far fewer
+	// round trips for large groups.  If the GraphQL API is
+	// unavailable, the REST API is used instead.  Defaults to false.
+	GraphQL bool `xml:"graphql"`
+
+	// State is the state of the issues to list which is one of
+	// "opened", "closed", or "all".  Defaults to "opened".
+	State string `xml:"state"`
+}
+
+// issueStates are the valid values for --state.
+var issueStates = []string{"opened", "closed", "all"}
+
+// Initialize initializes this IssuesListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *IssuesListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --test-expr, --title-expr
+	opts.IssueSelectorOptions.Initialize(flags)
+
+	// --graphql
+	flags.BoolVar(&opts.GraphQL, "graphql", opts.GraphQL,
+		i18n.T("whether to use the GraphQL API instead of the REST API "+
+			"falling back to the REST API if GraphQL is unavailable"))
+
+	// --state
+	if opts.State == "" {
+		opts.State = "opened"
+	}
+	flags.StringVar(&opts.State, "state", opts.State,
+		i18n.T("state of the issues to list which is one of "+
+			"\"opened\", \"closed\", or \"all\""))
+}
+
+////////////////////////////////////////////////////////////////////////
+// IssuesListCommand
+////////////////////////////////////////////////////////////////////////
+
+// IssuesListCommand implements the "issues list" command which lists
+// the issues across the projects in a group.
+type IssuesListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[IssuesListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *IssuesListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] issues list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the issues in the selected projects.  Each issue is\n")
+	i18n.Fprintf(out, "    printed as its reference, the date it was last updated, its\n")
+	i18n.Fprintf(out, "    author, and its title.  With --graphql, only some fields are\n")
+	i18n.Fprintf(out, "    printed for --output json because the GraphQL API only\n")
+	i18n.Fprintf(out, "    fetches the fields needed.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewIssuesListCommand returns a new, initialized IssuesListCommand
+// instance.
+func NewIssuesListCommand(
+	name string,
+	opts *IssuesListOptions,
+	session *Session,
+) *IssuesListCommand {
+
+	// Create the new command.
+	cmd := &IssuesListCommand{
+		GitlabCommand: GitlabCommand[IssuesListOptions]{
+			BasicCommand: BasicCommand[IssuesListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *IssuesListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.IssueSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if !slices.Contains(issueStates, cmd.options.State) {
+		return result, i18n.Errorf("%w: invalid state: %q",
+			ErrInvalidOption, cmd.options.State)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print each issue.  For --output json, the issues are collected
+	// and printed together at the end.
+	issues := []*gitlab.Issue{}
+	printIssue := func(p *gitlab.Project, issue *gitlab.Issue) (bool, error) {
+		if cmd.session.OutputJSON() {
+			issues = append(issues, issue)
+		} else {
+			author := ""
+			if issue.Author != nil {
+				author = issue.Author.Username
+			}
+			updated := ""
+			if issue.UpdatedAt != nil {
+				updated = issue.UpdatedAt.Format("2006-01-02")
+			}
+			fmt.Printf("%-40s  %-10s  %-16s  %s\n",
+				issueName(p, issue), updated, author, issue.Title)
+		}
+		result.Succeed(issueName(p, issue), issue)
+		return true, nil
+	}
+	listOpts := cmd.options.ListOptions(cmd.options.State)
+	if cmd.options.GraphQL {
+		err = cmd.options.ForEachIssueGraphQL(
+			ctx, result, cmd.client, listOpts, printIssue)
+	} else {
+		err = cmd.options.ForEachIssue(
+			ctx, result, cmd.client.Groups, cmd.client.Issues,
+			listOpts, printIssue)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Print the issues as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, issues)
+	}
+	return result, err
+}
@@ -0,0 +1,238 @@
+// This file provides the implementation for the "issues move" command
+// which moves the opened issues across the projects in a group to a
+// single project.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// IssuesMoveOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// IssuesMoveOptions are the options needed by this command.
+type IssuesMoveOptions struct {
+
+	// Embed the options that control whether the remaining issues
+	// are moved after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the issues.
+	IssueSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ToProject is the project to which the issues are moved which
+	// can be the full path or the project ID.  Defaults to "".
+	ToProject string `xml:"to-project"`
+}
+
+// Initialize initializes this IssuesMoveOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *IssuesMoveOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --test-expr, --title-expr
+	opts.IssueSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --to-project
+	flags.StringVar(&opts.ToProject, "to-project", opts.ToProject,
+		i18n.T("project to which the issues are moved which can be the full "+
+			"path or the project ID"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// IssuesMoveCommand
+////////////////////////////////////////////////////////////////////////
+
+// IssuesMoveCommand implements the "issues move" command which moves
+// the opened issues across the projects in a group to a single
+// project.
+type IssuesMoveCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[IssuesMoveOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *IssuesMoveCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] issues move [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Move the selected opened issues to --to-project.  Gitlab\n")
+	i18n.Fprintf(out, "    closes each original issue and links it to the new one.\n")
+	i18n.Fprintf(out, "    Issues already in --to-project are skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Move Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewIssuesMoveCommand returns a new, initialized IssuesMoveCommand
+// instance.
+func NewIssuesMoveCommand(
+	name string,
+	opts *IssuesMoveOptions,
+	session *Session,
+) *IssuesMoveCommand {
+
+	// Create the new command.
+	cmd := &IssuesMoveCommand{
+		GitlabCommand: GitlabCommand[IssuesMoveOptions]{
+			BasicCommand: BasicCommand[IssuesMoveOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// MoveIssue moves the issue from project p to project to and returns
+// the new issue.
+func MoveIssue(
+	ctx context.Context,
+	s gitlab_util.IssueMover, /* was *gitlab.IssuesService */
+	p *gitlab.Project,
+	issue *gitlab.Issue,
+	to *gitlab.Project,
+) (*gitlab.Issue, error) {
+	moved, _, err := s.MoveIssue(p.ID, issue.IID,
+		&gitlab.MoveIssueOptions{
+			ToProjectID: gitlab.Ptr(to.ID),
+		},
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return nil, fmt.Errorf("MoveIssue: %w", gitlab_util.ClassifyError(err))
+	}
+	return moved, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *IssuesMoveCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.IssueSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.ToProject == "" {
+		return result, i18n.Errorf("%w: to-project not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Find the project to which the issues are moved.
+	to, _, err := cmd.client.Projects.GetProject(
+		cmd.options.ToProject, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return result, fmt.Errorf("%q: %w",
+			cmd.options.ToProject, gitlab_util.ClassifyError(err))
+	}
+
+	// Move the issues.  A failed move is recorded in the result.  If
+	// --keep-going is set, the remaining issues are still moved.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachIssue(
+		ctx, result, cmd.client.Groups, cmd.client.Issues,
+		cmd.options.ListOptions("opened"),
+		func(p *gitlab.Project, issue *gitlab.Issue) (bool, error) {
+			// Skip the issues already in the target project
+			// including the ones just moved there.
+			if p.ID == to.ID {
+				return true, nil
+			}
+			name := issueName(p, issue)
+			hook.OnItemStart(name)
+			logging.Printf("- Moving issue %q to %q ... ",
+				name, to.PathWithNamespace)
+			if !cmd.options.DryRun {
+				moved, err := MoveIssue(ctx, cmd.client.Issues, p, issue, to)
+				if err != nil {
+					logging.Printf("Failed.\n")
+					hook.OnError(name, err)
+					result.Fail(name, issue, err)
+					return cmd.options.Continue(err)
+				}
+				logging.Printf("Done (%s#%d).\n", to.PathWithNamespace, moved.IID)
+			} else {
+				logging.Printf("Done.\n")
+			}
+			hook.OnItemDone(name)
+			result.Succeed(name, issue)
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err, "could not move %d issue(s)")
+	return result, err
+}
@@ -0,0 +1,223 @@
+// This file provides the implementation for the "issues relabel"
+// command which adds labels to and removes labels from the opened
+// issues across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// IssuesRelabelOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// IssuesRelabelOptions are the options needed by this command.
+type IssuesRelabelOptions struct {
+
+	// Embed the options that control whether the remaining issues
+	// are relabeled after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the issues.
+	IssueSelectorOptions
+
+	// AddLabels are the labels to add to the issues.  Defaults to no
+	// labels.
+	AddLabels string_slice.StringSlice `xml:"add-labels>label"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// RemoveLabels are the labels to remove from the issues.
+	// Defaults to no labels.
+	RemoveLabels string_slice.StringSlice `xml:"remove-labels>label"`
+}
+
+// Initialize initializes this IssuesRelabelOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *IssuesRelabelOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --test-expr, --title-expr
+	opts.IssueSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// --add-labels
+	flags.Var(&opts.AddLabels, "add-labels",
+		i18n.T("comma-separated list of labels to add to the issues"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --remove-labels
+	flags.Var(&opts.RemoveLabels, "remove-labels",
+		i18n.T("comma-separated list of labels to remove from the issues"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// IssuesRelabelCommand
+////////////////////////////////////////////////////////////////////////
+
+// IssuesRelabelCommand implements the "issues relabel" command which
+// adds labels to and removes labels from the opened issues across the
+// projects in a group.
+type IssuesRelabelCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[IssuesRelabelOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *IssuesRelabelCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] issues relabel [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Add --add-labels to and remove --remove-labels from the\n")
+	i18n.Fprintf(out, "    selected opened issues.  Use --labels to select the issues\n")
+	i18n.Fprintf(out, "    having the old labels when renaming a label.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Relabel Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewIssuesRelabelCommand returns a new, initialized
+// IssuesRelabelCommand instance.
+func NewIssuesRelabelCommand(
+	name string,
+	opts *IssuesRelabelOptions,
+	session *Session,
+) *IssuesRelabelCommand {
+
+	// Create the new command.
+	cmd := &IssuesRelabelCommand{
+		GitlabCommand: GitlabCommand[IssuesRelabelOptions]{
+			BasicCommand: BasicCommand[IssuesRelabelOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// RelabelIssue adds the labels in add to the issue and removes the
+// labels in remove from the issue.  Labels the issue already has are
+// not added again, and labels it does not have are ignored.
+func RelabelIssue(
+	ctx context.Context,
+	s gitlab_util.IssueUpdater, /* was *gitlab.IssuesService */
+	p *gitlab.Project,
+	issue *gitlab.Issue,
+	add []string,
+	remove []string,
+) error {
+	opts := gitlab.UpdateIssueOptions{}
+	if len(add) > 0 {
+		opts.AddLabels = gitlab.Ptr(gitlab.LabelOptions(add))
+	}
+	if len(remove) > 0 {
+		opts.RemoveLabels = gitlab.Ptr(gitlab.LabelOptions(remove))
+	}
+	_, _, err := s.UpdateIssue(p.ID, issue.IID, &opts,
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("RelabelIssue: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *IssuesRelabelCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.IssueSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if len(cmd.options.AddLabels) == 0 && len(cmd.options.RemoveLabels) == 0 {
+		return result, i18n.Errorf("%w: neither add-labels nor remove-labels set",
+			ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Relabel the issues.
+	err = UpdateIssues(
+		ctx, result, &cmd.options.IssueSelectorOptions,
+		cmd.client.Groups, cmd.client.Issues,
+		cmd.options.ListOptions("opened"), i18n.T("Relabeling"),
+		func(p *gitlab.Project, issue *gitlab.Issue) error {
+			return RelabelIssue(ctx, cmd.client.Issues, p, issue,
+				cmd.options.AddLabels, cmd.options.RemoveLabels)
+		},
+		cmd.options.KeepGoing,
+		cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not relabel %d issue(s)")
+	return result, err
+}
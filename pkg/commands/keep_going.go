@@ -0,0 +1,109 @@
+// This file provides the options shared by bulk commands that can
+// either stop at the first item that fails or keep going and report
+// the items that failed at the end.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// KeepGoingOptions control whether a bulk command stops at the first
+// item that fails.  Because the struct is embedded, its XML elements
+// appear directly in the options of the embedding command in the
+// options.xml file.
+type KeepGoingOptions struct {
+
+	// KeepGoing should cause the command to continue with the
+	// remaining items after an item fails and to print a summary of
+	// the items that failed at the end.  Defaults to false which stops
+	// the command at the first item that fails.
+	KeepGoing bool `xml:"keep-going"`
+}
+
+// Initialize initializes this KeepGoingOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *KeepGoingOptions) Initialize(flags *flag.FlagSet) {
+
+	// --keep-going
+	flags.BoolVar(&opts.KeepGoing, "keep-going", opts.KeepGoing,
+		i18n.T("continue with the remaining items after an item fails "+
+			"and print a summary of the failures at the end"))
+}
+
+// Continue returns whether an iterator should continue after an item
+// fails with err.  It lets the callbacks of the iterators in
+// gitlab_util return "opts.Continue(err)" after recording the failure.
+// If --keep-going is not set, err is returned so the iterator stops.
+func (opts *KeepGoingOptions) Continue(err error) (bool, error) {
+	if opts.KeepGoing {
+		return true, nil
+	}
+	return false, err
+}
+
+// Finish is called at the end of a bulk command with the error that
+// stopped it (if any).  If --keep-going is not set, err is returned
+// unchanged.  Otherwise, the items that failed are printed to the
+// output writer, and if any failed, an error whose message is format
+// applied to the number of failed items is returned instead of err
+// unless the command was interrupted.
+func (opts *KeepGoingOptions) Finish(
+	out io.Writer,
+	result *Result,
+	err error,
+	format string,
+) error {
+	if !opts.KeepGoing {
+		return err
+	}
+	PrintFailureSummary(out, result)
+	failed := len(result.Failed())
+	if failed == 0 || errors.Is(err, context.Canceled) {
+		return err
+	}
+	return i18n.Errorf(format, failed)
+}
+
+// forEachConcurrently calls gitlab_util.ForEachConcurrently() if
+// keepGoing is true and gitlab_util.ForEachConcurrentlyUntilError()
+// otherwise.
+func forEachConcurrently[T any](
+	ctx context.Context,
+	items []T,
+	concurrency int,
+	keepGoing bool,
+	f func(item T) error,
+) error {
+	if keepGoing {
+		return gitlab_util.ForEachConcurrently(ctx, items, concurrency, f)
+	}
+	return gitlab_util.ForEachConcurrentlyUntilError(ctx, items, concurrency, f)
+}
+
+// PrintFailureSummary prints the items that failed as a table.
+// Nothing is printed if no item failed.
+func PrintFailureSummary(out io.Writer, result *Result) {
+	failed := result.Failed()
+	if len(failed) == 0 {
+		return
+	}
+	row := func(name, err string) {
+		fmt.Fprintf(out, "%-40s  %s\n", name, err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Failures:\n")
+	fmt.Fprintf(out, "\n")
+	row(i18n.T("ITEM"), i18n.T("ERROR"))
+	for _, item := range failed {
+		row(item.Name, item.Err.Error())
+	}
+	fmt.Fprintf(out, "\n")
+}
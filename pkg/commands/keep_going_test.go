@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestKeepGoingIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	alpha := server.Project("foo/alpha")
+	run := func(args ...string) (string, *Result, error) {
+		cmd := NewProjectsDeleteCommand("delete", &ProjectsDeleteOptions{}, session)
+		cmd.confirmIn = strings.NewReader("y\n")
+		var result *Result
+		var err error
+		output := captureStdout(t, func() {
+			result, err = cmd.Run(context.Background(),
+				append([]string{"--group", "foo"}, args...))
+		})
+		return output, result, err
+	}
+
+	// Without --keep-going, no project is deleted after the first
+	// one that fails.
+	server.InjectError(http.MethodDelete, fmt.Sprintf("/projects/%d", alpha.ID),
+		http.StatusInternalServerError, 1)
+	output, result, err := run()
+	if err == nil || strings.Contains(output, "Failures:") {
+		t.Errorf("stop: expected error without summary: err=%v  output=%q", err, output)
+	}
+	if result.Processed() != 1 || len(server.Projects()) != 5 {
+		t.Errorf("stop: expected 1 processed and 5 projects: processed=%d  projects=%v",
+			result.Processed(), server.Projects())
+	}
+
+	// With --keep-going, the remaining projects are deleted, and the
+	// failure is summarized at the end.
+	server.InjectError(http.MethodDelete, fmt.Sprintf("/projects/%d", alpha.ID),
+		http.StatusInternalServerError, 1)
+	output, result, err = run("--keep-going")
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"error", "could not delete 1 project(s)", err},
+		{"processed", 3, result.Processed()},
+		{"projects", []string{"foo/alpha", "foo/bar/delta", "foo/bar/test-epsilon"},
+			server.Projects()},
+		{"summary", true, strings.Contains(output, "\nFailures:\n\nITEM  ")},
+		{"failed", true, strings.Contains(output, "\nfoo/alpha  ")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("%s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+	if ExitStatusOf(result, err).Code != ExitPartialFailure {
+		t.Errorf("exit status: expected=%d  actual=%d",
+			ExitPartialFailure, ExitStatusOf(result, err).Code)
+	}
+}
+
+func TestKeepGoingBulkCommandsIntegration(t *testing.T) {
+	t.Setenv("GITLAB_CMDS_TEST_TOKEN", "secret")
+	type Data []struct {
+		name    string
+		cmd     func(session *Session) Runner
+		args    []string
+		path    string
+		message string
+	}
+	data := Data{
+		{"variables set",
+			func(session *Session) Runner {
+				return NewVariablesCommand("variables", &VariablesOptions{}, session)
+			},
+			[]string{"set", "--group", "foo", "--key", "TOKEN",
+				"--value-env", "GITLAB_CMDS_TEST_TOKEN"},
+			"/projects/%[2]s/variables",
+			"could not set variable in 1 group(s) or project(s)"},
+		{"pipelines trigger",
+			func(session *Session) Runner {
+				return NewPipelinesCommand("pipelines", &PipelinesOptions{}, session)
+			},
+			[]string{"trigger", "--group", "foo"},
+			"/projects/%[1]d/pipeline",
+			"could not trigger 1 pipeline(s)"},
+	}
+	for _, d := range data {
+		server, session := newFakeSession(t)
+		alpha := server.Project("foo/alpha")
+		path := fmt.Sprintf(d.path, alpha.ID, alpha.PathWithNamespace)
+
+		// Without --keep-going, no project is processed after the
+		// first one that fails.
+		server.InjectError(http.MethodPost, path, http.StatusForbidden, 1)
+		output, result, err := runCommand(t, d.cmd(session), d.args)
+		if err == nil || strings.Contains(output, "Failures:") {
+			t.Errorf("%s: stop: expected error without summary: err=%v  output=%q",
+				d.name, err, output)
+		}
+		if result.Processed() != 1 {
+			t.Errorf("%s: stop: expected 1 processed: processed=%d",
+				d.name, result.Processed())
+		}
+
+		// With --keep-going, the remaining projects are processed, and
+		// the failure is summarized at the end.
+		server.InjectError(http.MethodPost, path, http.StatusForbidden, 1)
+		output, result, err = runCommand(t, d.cmd(session),
+			append(d.args, "--keep-going"))
+		if fmt.Sprint(err) != d.message {
+			t.Errorf("%s: keep going: expected=%v  actual=%v", d.name, d.message, err)
+		}
+		if result.Processed() != 3 || len(result.Failed()) != 1 {
+			t.Errorf("%s: keep going: expected 3 processed and 1 failed: "+
+				"processed=%d  failed=%d", d.name, result.Processed(), len(result.Failed()))
+		}
+		if !strings.Contains(output, "\nFailures:\n") {
+			t.Errorf("%s: keep going: missing summary in output: %q", d.name, output)
+		}
+	}
+}
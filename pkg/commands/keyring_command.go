@@ -0,0 +1,191 @@
+// This file provides the implementation for the "keyring" command
+// which provides subcommands that manage the token stored in the OS
+// credential store.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      KeyringCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/authinfo"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// KeyringOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// KeyringOptions are the options needed by this command.
+type KeyringOptions struct {
+
+	// Options for the "keyring delete" command.
+	KeyringDeleteOpts KeyringDeleteOptions `xml:"delete-options"`
+
+	// Options for the "keyring set" command.
+	KeyringSetOpts KeyringSetOptions `xml:"set-options"`
+}
+
+// Initialize initializes this KeyringOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *KeyringOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+// KeyringEntryOptions name the entry in the OS credential store that
+// holds the token.  They are embedded in the options of the "keyring"
+// subcommands.
+type KeyringEntryOptions struct {
+
+	// Service is the service name of the entry which must match
+	// <keyring-service> in auth.xml.  Defaults to "glcmds" which is
+	// also used by --auth-backend keyring.
+	Service string `xml:"service"`
+
+	// User is the user name of the entry which must match
+	// <keyring-user> in auth.xml.  Defaults to "default" which is
+	// also used by --auth-backend keyring.
+	User string `xml:"user"`
+}
+
+// Initialize initializes this KeyringEntryOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *KeyringEntryOptions) Initialize(flags *flag.FlagSet) {
+
+	// --service
+	if opts.Service == "" {
+		opts.Service = authinfo.DefaultKeyringService
+	}
+	flags.StringVar(&opts.Service, "service", opts.Service,
+		i18n.T("service name of the entry in the OS credential store"))
+
+	// --user
+	if opts.User == "" {
+		opts.User = authinfo.DefaultKeyringUser
+	}
+	flags.StringVar(&opts.User, "user", opts.User,
+		i18n.T("user name of the entry in the OS credential store"))
+}
+
+// KeyringInfo returns the entry in the OS credential store named by
+// the options.
+func (opts *KeyringEntryOptions) KeyringInfo() authinfo.KeyringInfo {
+	return authinfo.NewKeyringInfo(opts.Service, opts.User)
+}
+
+////////////////////////////////////////////////////////////////////////
+// KeyringCommand
+////////////////////////////////////////////////////////////////////////
+
+// KeyringCommand provides subcommands that manage the token stored in
+// the OS credential store.
+type KeyringCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[KeyringOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *KeyringCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] keyring [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for the token stored in the OS credential store.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *KeyringCommand) addSubcmds(session *Session) {
+	cmd.subcmds["delete"] = NewKeyringDeleteCommand(
+		"delete", &cmd.options.KeyringDeleteOpts, session)
+	cmd.subcmds["set"] = NewKeyringSetCommand(
+		"set", &cmd.options.KeyringSetOpts, session)
+}
+
+// NewKeyringCommand returns a new, initialized KeyringCommand instance
+// having the specified name.
+func NewKeyringCommand(
+	name string,
+	opts *KeyringOptions,
+	session *Session,
+) *KeyringCommand {
+
+	// Create the new command.
+	cmd := &KeyringCommand{
+		ParentCommand: ParentCommand[KeyringOptions]{
+			BasicCommand: BasicCommand[KeyringOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *KeyringCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
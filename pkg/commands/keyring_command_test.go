@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/authinfo"
+	"github.com/zalando/go-keyring"
+)
+
+func TestAuthKeyringIntegration(t *testing.T) {
+	keyring.MockInit()
+	server := newFakeServer(t)
+	for _, name := range []string{"GITLAB_TOKEN", "GITLAB_PRIVATE_TOKEN", "GITLAB_OAUTH_TOKEN"} {
+		t.Setenv(name, "")
+	}
+
+	// run runs "projects list" with a session that loads the token
+	// from the OS credential store.
+	run := func() error {
+		session := NewSession(&GlobalOptions{
+			AuthBackend: AuthBackendKeyring,
+			BaseURL:     server.URL,
+		})
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		_, _, err := runCommand(t, cmd, []string{"list", "--group", "foo"})
+		return err
+	}
+
+	// Verify the missing token is reported.
+	if err := run(); !errors.Is(err, authinfo.ErrKeyringTokenNotFound) {
+		t.Errorf("projects list without token: expected=%v  actual=%v",
+			authinfo.ErrKeyringTokenNotFound, err)
+	}
+
+	// Store the token.
+	setCmd := NewKeyringSetCommand("set", &KeyringSetOptions{}, NewSession(&GlobalOptions{}))
+	setCmd.tokenIn = strings.NewReader("token-1234567890\n")
+	_, _, err := runCommand(t, setCmd, nil)
+	if err != nil {
+		t.Fatalf("keyring set: unexpected error: %v", err)
+	}
+	if err := run(); err != nil {
+		t.Errorf("projects list with token: unexpected error: %v", err)
+	}
+
+	// Delete the token.
+	deleteCmd := NewKeyringCommand("keyring", &KeyringOptions{}, NewSession(&GlobalOptions{}))
+	_, _, err = runCommand(t, deleteCmd, []string{"delete"})
+	if err != nil {
+		t.Fatalf("keyring delete: unexpected error: %v", err)
+	}
+	if err := run(); !errors.Is(err, authinfo.ErrKeyringTokenNotFound) {
+		t.Errorf("projects list after delete: expected=%v  actual=%v",
+			authinfo.ErrKeyringTokenNotFound, err)
+	}
+
+	// Verify an empty token is rejected.
+	setCmd = NewKeyringSetCommand("set", &KeyringSetOptions{}, NewSession(&GlobalOptions{}))
+	setCmd.tokenIn = strings.NewReader("\n")
+	_, _, err = runCommand(t, setCmd, nil)
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("keyring set with empty token: expected=%v  actual=%v",
+			ErrInvalidOption, err)
+	}
+}
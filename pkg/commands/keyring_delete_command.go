@@ -0,0 +1,133 @@
+// This file provides the implementation for the "keyring delete"
+// command which deletes the token from the OS credential store.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+)
+
+////////////////////////////////////////////////////////////////////////
+// KeyringDeleteOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// KeyringDeleteOptions are the options needed by this command.
+type KeyringDeleteOptions struct {
+
+	// Embed the options that name the entry.
+	KeyringEntryOptions
+}
+
+// Initialize initializes this KeyringDeleteOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *KeyringDeleteOptions) Initialize(flags *flag.FlagSet) {
+
+	// --service, --user
+	opts.KeyringEntryOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// KeyringDeleteCommand
+////////////////////////////////////////////////////////////////////////
+
+// KeyringDeleteCommand implements the "keyring delete" command which
+// deletes the token from the OS credential store.
+type KeyringDeleteCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[KeyringDeleteOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *KeyringDeleteCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] keyring delete [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Delete the token from the OS credential store.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Delete Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewKeyringDeleteCommand returns a new, initialized
+// KeyringDeleteCommand instance.
+func NewKeyringDeleteCommand(
+	name string,
+	opts *KeyringDeleteOptions,
+	session *Session,
+) *KeyringDeleteCommand {
+
+	// Create the new command.
+	cmd := &KeyringDeleteCommand{
+		GitlabCommand: GitlabCommand[KeyringDeleteOptions]{
+			BasicCommand: BasicCommand[KeyringDeleteOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *KeyringDeleteCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Delete the token.
+	keyringInfo := cmd.options.KeyringInfo()
+	logging.Printf("- Deleting token from %v ... ", &keyringInfo)
+	err = keyringInfo.Delete()
+	if err != nil {
+		logging.Printf("Failed.\n")
+		result.Fail(keyringInfo.String(), nil, err)
+		return result, err
+	}
+	logging.Printf("Done.\n")
+	result.Succeed(keyringInfo.String(), nil)
+
+	return result, nil
+}
@@ -0,0 +1,172 @@
+// This file provides the implementation for the "keyring set" command
+// which stores a token in the OS credential store so that it does not
+// have to be stored in plaintext in auth.xml.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+)
+
+////////////////////////////////////////////////////////////////////////
+// KeyringSetOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// KeyringSetOptions are the options needed by this command.
+type KeyringSetOptions struct {
+
+	// Embed the options that name the entry.
+	KeyringEntryOptions
+}
+
+// Initialize initializes this KeyringSetOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *KeyringSetOptions) Initialize(flags *flag.FlagSet) {
+
+	// --service, --user
+	opts.KeyringEntryOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// KeyringSetCommand
+////////////////////////////////////////////////////////////////////////
+
+// KeyringSetCommand implements the "keyring set" command which stores
+// a token in the OS credential store.
+type KeyringSetCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[KeyringSetOptions]
+
+	// tokenIn is where the token is read from.  If nil, it is read
+	// from os.Stdin.
+	tokenIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *KeyringSetCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] keyring set [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Read a private or personal access token from stdin and store\n")
+	i18n.Fprintf(out, "    it in the OS credential store replacing the token already\n")
+	i18n.Fprintf(out, "    there.  The token is then used by --auth-backend keyring or\n")
+	i18n.Fprintf(out, "    by an auth.xml file with matching <keyring-service> and\n")
+	i18n.Fprintf(out, "    <keyring-user> elements.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Set Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewKeyringSetCommand returns a new, initialized KeyringSetCommand
+// instance.
+func NewKeyringSetCommand(
+	name string,
+	opts *KeyringSetOptions,
+	session *Session,
+) *KeyringSetCommand {
+
+	// Create the new command.
+	cmd := &KeyringSetCommand{
+		GitlabCommand: GitlabCommand[KeyringSetOptions]{
+			BasicCommand: BasicCommand[KeyringSetOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// readToken reads the token from the first line of in.  If in is nil,
+// the token is read from os.Stdin after prompting for it if os.Stdin
+// is a terminal.
+func readToken(in io.Reader) (string, error) {
+	if in == nil {
+		fi, err := os.Stdin.Stat()
+		if err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+			i18n.Printf("Token: ")
+		}
+		in = os.Stdin
+	}
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("readToken: %w", err)
+	}
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", i18n.Errorf("%w: empty token", ErrInvalidOption)
+	}
+	return token, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *KeyringSetCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Read the token.
+	token, err := readToken(cmd.tokenIn)
+	if err != nil {
+		return result, err
+	}
+
+	// Store the token.
+	keyringInfo := cmd.options.KeyringInfo()
+	logging.Printf("- Storing token in %v ... ", &keyringInfo)
+	err = keyringInfo.Store(token)
+	if err != nil {
+		logging.Printf("Failed.\n")
+		result.Fail(keyringInfo.String(), nil, err)
+		return result, err
+	}
+	logging.Printf("Done.\n")
+	result.Succeed(keyringInfo.String(), nil)
+
+	return result, nil
+}
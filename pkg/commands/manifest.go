@@ -0,0 +1,272 @@
+// This file provides the manifest file that describes the desired
+// state of the projects in a group which the "apply" command converges
+// Gitlab to.
+
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_schema"
+	"github.com/xanzy/go-gitlab"
+)
+
+// Manifest describes the desired state of the projects in a group.
+// Only what is listed is managed.  For example, members and protected
+// branches that are not listed are left alone.  For example:
+//
+//	<manifest>
+//	  <group>foo</group>
+//	  <projects>
+//	    <project>
+//	      <path>alpha</path>
+//	      <visibility>private</visibility>
+//	      <members>
+//	        <member>
+//	          <username>aberns</username>
+//	          <access-level>developer</access-level>
+//	        </member>
+//	      </members>
+//	    </project>
+//	  </projects>
+//	</manifest>
+type Manifest struct {
+	XMLName xml.Name `xml:"manifest"`
+
+	// Group is the full path of the group that holds the projects.
+	// The group must already exist.
+	Group string `xml:"group"`
+
+	// Projects are the projects in the group.
+	Projects []*ManifestProject `xml:"projects>project"`
+}
+
+// ManifestProject describes the desired state of a project.  Settings
+// that are empty or not set are left alone.
+type ManifestProject struct {
+
+	// Path is the path of the project relative to the group.  It can
+	// include subgroups (e.g., "bar/delta") which must already exist.
+	Path string `xml:"path"`
+
+	// Name is the name of the project.
+	Name string `xml:"name"`
+
+	// Description is the description of the project.
+	Description string `xml:"description"`
+
+	// Visibility is "private", "internal", or "public".
+	Visibility string `xml:"visibility"`
+
+	// DefaultBranch is the default branch of the project.
+	DefaultBranch string `xml:"default-branch"`
+
+	// OnlyAllowMergeIfPipelineSucceeds is whether merge requests can
+	// only be merged if their pipeline succeeds.
+	OnlyAllowMergeIfPipelineSucceeds *bool `xml:"only-allow-merge-if-pipeline-succeeds"`
+
+	// OnlyAllowMergeIfAllDiscussionsAreResolved is whether merge
+	// requests can only be merged if all of their discussions are
+	// resolved.
+	OnlyAllowMergeIfAllDiscussionsAreResolved *bool `xml:"only-allow-merge-if-all-discussions-are-resolved"`
+
+	// RemoveSourceBranchAfterMerge is whether the source branch of a
+	// merge request is removed after it is merged.
+	RemoveSourceBranchAfterMerge *bool `xml:"remove-source-branch-after-merge"`
+
+	// Members are the direct members of the project.
+	Members []*ManifestMember `xml:"members>member"`
+
+	// ProtectedBranches are the protected branches of the project.
+	ProtectedBranches []*ManifestProtectedBranch `xml:"protected-branches>protected-branch"`
+
+	// ApprovalRules are the approval rules of the project.
+	ApprovalRules []*ManifestApprovalRule `xml:"approval-rules>approval-rule"`
+}
+
+// ManifestMember describes a direct member of a project.
+type ManifestMember struct {
+
+	// Username is the username of the member.
+	Username string `xml:"username"`
+
+	// AccessLevel is the name of the access level (e.g., "developer")
+	// as accepted by ParseAccessLevel().
+	AccessLevel string `xml:"access-level"`
+}
+
+// ManifestProtectedBranch describes a protected branch of a project.
+type ManifestProtectedBranch struct {
+
+	// Name is the name of the branch which can be a wildcard (e.g.,
+	// "release/*").
+	Name string `xml:"name"`
+
+	// PushAccessLevel is the name of the access level allowed to push
+	// as accepted by ParseBranchAccessLevel().  Defaults to
+	// "maintainer".
+	PushAccessLevel string `xml:"push-access-level"`
+
+	// MergeAccessLevel is the name of the access level allowed to
+	// merge as accepted by ParseBranchAccessLevel().  Defaults to
+	// "maintainer".
+	MergeAccessLevel string `xml:"merge-access-level"`
+
+	// AllowForcePush is whether force pushes are allowed.
+	AllowForcePush bool `xml:"allow-force-push"`
+
+	// CodeOwnerApproval is whether changes to files with a code owner
+	// must be approved by the code owner.
+	CodeOwnerApproval bool `xml:"code-owner-approval"`
+}
+
+// ManifestApprovalRule describes an approval rule of a project.
+type ManifestApprovalRule struct {
+
+	// Name is the name of the approval rule.
+	Name string `xml:"name"`
+
+	// ApprovalsRequired is the number of approvals required.
+	ApprovalsRequired int `xml:"approvals-required"`
+
+	// Approvers are the usernames of the eligible approvers.
+	Approvers string_slice.StringSlice `xml:"approvers>approver"`
+}
+
+// LoadManifest loads and validates the manifest from the file.  Files
+// with a ".yaml", ".yml", or ".json" extension are read as YAML or
+// JSON having the same nested structure as the XML.
+func LoadManifest(fileName string) (*Manifest, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("LoadManifest: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml", ".json":
+		data, err = xml_schema.ConvertYAML(fileName, data, &Manifest{})
+		if err != nil {
+			return nil, fmt.Errorf("LoadManifest: %w", err)
+		}
+	}
+	err = xml_schema.Validate(fileName, data, &Manifest{})
+	if err != nil {
+		return nil, fmt.Errorf("LoadManifest: %w", err)
+	}
+	manifest := &Manifest{}
+	err = xml.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("LoadManifest: %s: %w", fileName, err)
+	}
+	manifest.SetDefaults()
+	err = manifest.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("LoadManifest: %s: %w", fileName, err)
+	}
+	return manifest, nil
+}
+
+// SetDefaults sets the defaults of the fields that are not set.
+func (manifest *Manifest) SetDefaults() {
+	for _, p := range manifest.Projects {
+		for _, b := range p.ProtectedBranches {
+			if b.PushAccessLevel == "" {
+				b.PushAccessLevel = "maintainer"
+			}
+			if b.MergeAccessLevel == "" {
+				b.MergeAccessLevel = "maintainer"
+			}
+		}
+	}
+}
+
+// Validate returns an error if the manifest is incomplete, has
+// duplicates, or has values that are not valid.
+func (manifest *Manifest) Validate() error {
+	if manifest.Group == "" {
+		return i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	var paths []string
+	for _, p := range manifest.Projects {
+		if p.Path == "" {
+			return i18n.Errorf("%w: project path not set", ErrInvalidOption)
+		}
+		if slices.Contains(paths, p.Path) {
+			return i18n.Errorf("%w: duplicate project: %q", ErrInvalidOption, p.Path)
+		}
+		paths = append(paths, p.Path)
+		err := p.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the project has duplicates or values
+// that are not valid.
+func (p *ManifestProject) Validate() error {
+	switch gitlab.VisibilityValue(p.Visibility) {
+	case "", gitlab.PrivateVisibility, gitlab.InternalVisibility, gitlab.PublicVisibility:
+	default:
+		return i18n.Errorf("%w: %s: invalid visibility: %q",
+			ErrInvalidOption, p.Path, p.Visibility)
+	}
+	var names []string
+	for _, m := range p.Members {
+		if m.Username == "" {
+			return i18n.Errorf("%w: %s: member username not set",
+				ErrInvalidOption, p.Path)
+		}
+		if slices.Contains(names, m.Username) {
+			return i18n.Errorf("%w: %s: duplicate member: %q",
+				ErrInvalidOption, p.Path, m.Username)
+		}
+		names = append(names, m.Username)
+		_, err := ParseAccessLevel(m.AccessLevel)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.Path, err)
+		}
+	}
+	names = nil
+	for _, b := range p.ProtectedBranches {
+		if b.Name == "" {
+			return i18n.Errorf("%w: %s: protected branch name not set",
+				ErrInvalidOption, p.Path)
+		}
+		if slices.Contains(names, b.Name) {
+			return i18n.Errorf("%w: %s: duplicate protected branch: %q",
+				ErrInvalidOption, p.Path, b.Name)
+		}
+		names = append(names, b.Name)
+		for _, level := range []string{b.PushAccessLevel, b.MergeAccessLevel} {
+			_, err := ParseBranchAccessLevel(level)
+			if err != nil {
+				return fmt.Errorf("%s: %w", p.Path, err)
+			}
+		}
+	}
+	names = nil
+	for _, rule := range p.ApprovalRules {
+		if rule.Name == "" {
+			return i18n.Errorf("%w: %s: approval rule name not set",
+				ErrInvalidOption, p.Path)
+		}
+		if slices.Contains(names, rule.Name) {
+			return i18n.Errorf("%w: %s: duplicate approval rule: %q",
+				ErrInvalidOption, p.Path, rule.Name)
+		}
+		names = append(names, rule.Name)
+		if rule.ApprovalsRequired < 0 {
+			return i18n.Errorf("%w: %s: invalid approvals-required: %d",
+				ErrInvalidOption, p.Path, rule.ApprovalsRequired)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// This file provides the options and functions shared by the
+// "members" commands that manage the members of a group or of a
+// selection of projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_users"
+	"github.com/xanzy/go-gitlab"
+)
+
+// AccessLevels maps the names accepted by --access-level to the
+// access levels that can be granted to members.
+var AccessLevels = map[string]gitlab.AccessLevelValue{
+	"guest":      gitlab.GuestPermissions,
+	"reporter":   gitlab.ReporterPermissions,
+	"developer":  gitlab.DeveloperPermissions,
+	"maintainer": gitlab.MaintainerPermissions,
+	"owner":      gitlab.OwnerPermissions,
+}
+
+// ParseAccessLevel returns the access level having the name.  Only the
+// names in AccessLevels are valid.
+func ParseAccessLevel(name string) (gitlab.AccessLevelValue, error) {
+	level, ok := AccessLevels[strings.ToLower(name)]
+	if !ok {
+		return 0, i18n.Errorf("%w: invalid access level: %q", ErrInvalidOption, name)
+	}
+	return level, nil
+}
+
+// MemberTargetOptions select whether the members of a group or of a
+// selection of projects are managed.  They are embedded in the options
+// of the "members" commands so the options have the same names and
+// meaning everywhere.
+type MemberTargetOptions struct {
+
+	// Embed the options that select the projects.  For the "group"
+	// scope, only the group is used.
+	ProjectSelectorOptions
+
+	// Scope is "group" to manage the members of the group or
+	// "projects" to manage the members of the selected projects.
+	// Defaults to "group".
+	Scope string `xml:"scope"`
+}
+
+// Initialize initializes this MemberTargetOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *MemberTargetOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --scope
+	if opts.Scope == "" {
+		opts.Scope = "group"
+	}
+	flags.StringVar(&opts.Scope, "scope", opts.Scope,
+		i18n.T("either \"group\" or \"projects\" to manage the members of the "+
+			"group or of the selected projects"))
+}
+
+// Validate returns an error if the options cannot select a group or
+// projects.
+func (opts *MemberTargetOptions) Validate() error {
+	err := opts.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains([]string{"group", "projects"}, opts.Scope) {
+		return i18n.Errorf("%w: invalid scope: %q", ErrInvalidOption, opts.Scope)
+	}
+	return nil
+}
+
+// MembersGroupsService is an abstraction of gitlab.GroupsService which
+// finds the group, walks the projects beneath it, and lists the
+// members of the group.
+type MembersGroupsService interface {
+	gitlab_util.GroupFinder
+	gitlab_util.ProjectsInGroupLister
+	gitlab_util.GroupMembersLister
+}
+
+// MembersProjectMembersService is an abstraction of
+// gitlab.ProjectMembersService which lists and manages the members of
+// projects.
+type MembersProjectMembersService interface {
+	gitlab_util.ProjectMembersLister
+	gitlab_util.ProjectMembersManager
+}
+
+// MembersServices are the Gitlab services needed to manage the members
+// of a group or of a selection of projects.
+type MembersServices struct {
+	Groups         MembersGroupsService            /* was *gitlab.GroupsService */
+	GroupMembers   gitlab_util.GroupMembersManager /* was *gitlab.GroupMembersService */
+	ProjectMembers MembersProjectMembersService    /* was *gitlab.ProjectMembersService */
+}
+
+// Membership returns the services needed to update the direct
+// memberships.
+func (s MembersServices) Membership() MembershipServices {
+	return MembershipServices{
+		GroupMembers:   s.GroupMembers,
+		ProjectMembers: s.ProjectMembers,
+	}
+}
+
+// GetMemberTargets returns the group or the selected projects
+// (depending on the scope of the options) with their direct and
+// inherited memberships sorted by username.
+func GetMemberTargets(
+	ctx context.Context,
+	s MembersServices,
+	opts *MemberTargetOptions,
+) ([]*MembershipSource, error) {
+	var targets []*MembershipSource
+
+	if opts.Scope == "group" {
+		g, err := gitlab_util.FindExactGroup(ctx, s.Groups, opts.Group)
+		if err != nil {
+			return nil, fmt.Errorf("GetMemberTargets: %w", err)
+		}
+		ms, err := GetGroupMemberships(ctx, s.Groups, g)
+		if err != nil {
+			return nil, fmt.Errorf("GetMemberTargets: %w", err)
+		}
+		targets = append(targets, &MembershipSource{
+			Type:        "group",
+			Path:        g.FullPath,
+			Memberships: ms,
+		})
+	} else {
+		projects, err := opts.GetAllProjects(ctx, s.Groups)
+		if err != nil {
+			return nil, fmt.Errorf("GetMemberTargets: %w", err)
+		}
+		for _, p := range projects {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			ms, err := GetProjectMemberships(ctx, s.ProjectMembers, p)
+			if err != nil {
+				return nil, fmt.Errorf("GetMemberTargets: %w", err)
+			}
+			targets = append(targets, &MembershipSource{
+				Type:        "project",
+				Path:        p.PathWithNamespace,
+				Memberships: ms,
+			})
+		}
+	}
+
+	for _, target := range targets {
+		slices.SortFunc(target.Memberships, func(a, b *Membership) int {
+			return strings.Compare(a.Username, b.Username)
+		})
+	}
+	return targets, nil
+}
+
+// FindDirectMembership returns the direct membership of the user in
+// the group or project or nil if the user is not a direct member.
+func FindDirectMembership(target *MembershipSource, userID int) *Membership {
+	for _, m := range target.Memberships {
+		if m.Direct && m.UserID == userID {
+			return m
+		}
+	}
+	return nil
+}
+
+// ReadMemberUsers reads the users from the XML file which has the same
+// format as the file written by "users list".
+func ReadMemberUsers(fname string) ([]*xml_users.XmlUser, error) {
+	if fname == "" {
+		return nil, i18n.Errorf("%w: users file not set", ErrInvalidOption)
+	}
+	users, err := xml_users.ReadUsers(fname)
+	if err != nil {
+		return nil, fmt.Errorf("ReadMemberUsers: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, i18n.Errorf("%w: no users in %q", ErrInvalidOption, fname)
+	}
+	return users, nil
+}
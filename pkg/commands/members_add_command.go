@@ -0,0 +1,307 @@
+// This file provides the implementation for the "members add" command
+// which adds users as members of a group or of a selection of
+// projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_users"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MembersAddOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MembersAddOptions are the options needed by this command.
+type MembersAddOptions struct {
+
+	// Embed the options that control whether the remaining members
+	// are added after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the group or projects.
+	MemberTargetOptions
+
+	// AccessLevel is the access level of the new members which is
+	// one of "guest", "reporter", "developer", "maintainer", or
+	// "owner".  Defaults to "".
+	AccessLevel string `xml:"access-level"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ExpiresAt is the date on which the new memberships expire.
+	// Defaults to the zero date which means they do not expire.
+	ExpiresAt date_arg.DateArg `xml:"expires-at"`
+
+	// UsersFileName is the name of the XML file holding the users to
+	// add which should contain the output of the "users list" or
+	// "members list" command.  Defaults to "".
+	UsersFileName string `xml:"users-file-name"`
+}
+
+// Initialize initializes this MembersAddOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *MembersAddOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --scope, --test-expr
+	opts.MemberTargetOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// --access-level
+	flags.StringVar(&opts.AccessLevel, "access-level", opts.AccessLevel,
+		i18n.T("access level of the new members which is one of guest, "+
+			"reporter, developer, maintainer, or owner"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --expires-at
+	flags.Var(&opts.ExpiresAt, "expires-at",
+		i18n.T("date on which the new memberships expire the form of which "+
+			"is YYYY/MM/DD or YYYY-MM-DD"))
+
+	// --users-file
+	flags.StringVar(&opts.UsersFileName, "users-file", opts.UsersFileName,
+		i18n.T("name of the XML file holding the users to add which should "+
+			"contain the output of the \"users list\" command"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MembersAddCommand
+////////////////////////////////////////////////////////////////////////
+
+// MembersAddCommand implements the "members add" command which adds
+// users as members of a group or of a selection of projects.
+type MembersAddCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MembersAddOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MembersAddCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] members add [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Add the users in --users-file as direct members with\n")
+	i18n.Fprintf(out, "    --access-level to the --group or, for --scope projects, to\n")
+	i18n.Fprintf(out, "    the projects beneath it whose full paths match --expr.\n")
+	i18n.Fprintf(out, "    Users who are already direct members are skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Add Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMembersAddCommand returns a new, initialized MembersAddCommand
+// instance.
+func NewMembersAddCommand(
+	name string,
+	opts *MembersAddOptions,
+	session *Session,
+) *MembersAddCommand {
+
+	// Create the new command.
+	cmd := &MembersAddCommand{
+		GitlabCommand: GitlabCommand[MembersAddOptions]{
+			BasicCommand: BasicCommand[MembersAddOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// AddMembership adds the user as a direct member of the group or
+// project with the access level.  If expiresAt is not empty, it is the
+// date in the form of YYYY-MM-DD on which the membership expires.  If
+// dryRun is true, this function only prints what it would without
+// actually doing it.
+func AddMembership(
+	ctx context.Context,
+	s MembershipServices,
+	target *MembershipSource,
+	user *xml_users.XmlUser,
+	level gitlab.AccessLevelValue,
+	expiresAt string,
+	dryRun bool,
+) error {
+	name := target.Path + ":" + user.Username
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- Adding %q to %q as %s ... ",
+		user.Username, target.Path, gitlab_util.AccessLevelName(level))
+	if !dryRun {
+		var err error
+		var expires *string
+		if expiresAt != "" {
+			expires = gitlab.Ptr(expiresAt)
+		}
+		if target.Type == "group" {
+			_, _, err = s.GroupMembers.AddGroupMember(target.Path,
+				&gitlab.AddGroupMemberOptions{
+					UserID:      gitlab.Ptr(user.ID),
+					AccessLevel: gitlab.Ptr(level),
+					ExpiresAt:   expires,
+				},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		} else {
+			_, _, err = s.ProjectMembers.AddProjectMember(target.Path,
+				&gitlab.AddProjectMemberOptions{
+					UserID:      user.ID,
+					AccessLevel: gitlab.Ptr(level),
+					ExpiresAt:   expires,
+				},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		}
+		if err != nil {
+			logging.Printf("Failed.\n")
+			err = fmt.Errorf(
+				"AddMembership: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(name, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *MembersAddCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MemberTargetOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	level, err := ParseAccessLevel(cmd.options.AccessLevel)
+	if err != nil {
+		return result, err
+	}
+	expiresAt := ""
+	if !time.Time(cmd.options.ExpiresAt).IsZero() {
+		expiresAt = cmd.options.ExpiresAt.String()
+	}
+	users, err := ReadMemberUsers(cmd.options.UsersFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the members of the group or projects.
+	s := MembersServices{
+		Groups:         cmd.client.Groups,
+		GroupMembers:   cmd.client.GroupMembers,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}
+	targets, err := GetMemberTargets(ctx, s, &cmd.options.MemberTargetOptions)
+	if err != nil {
+		return result, err
+	}
+
+	// Add each user who is not already a direct member stopping after
+	// the current user if interrupted.  If --keep-going is set, a user
+	// who cannot be added is recorded in the result, and the remaining
+	// users are still added.
+out:
+	for _, target := range targets {
+		for _, user := range users {
+			err = ctx.Err()
+			if err != nil {
+				break out
+			}
+			name := target.Path + ":" + user.Username
+			if FindDirectMembership(target, user.ID) != nil {
+				logging.Printf("- %q is already a member of %q.\n",
+					user.Username, target.Path)
+				result.Succeed(name, user)
+				continue
+			}
+			err = AddMembership(ctx, s.Membership(), target, user, level,
+				expiresAt, cmd.options.DryRun)
+			if err != nil {
+				result.Fail(name, user, err)
+				if !cmd.options.KeepGoing {
+					break out
+				}
+				continue
+			}
+			result.Succeed(name, user)
+		}
+	}
+
+	err = cmd.options.Finish(os.Stdout, result, err, "could not add %d member(s)")
+	return result, err
+}
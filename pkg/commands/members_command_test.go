@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_users"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestMembersIntegration(t *testing.T) {
+	run := func(server *fake_gitlab.Server, args ...string) (*Result, error) {
+		session := NewSessionWithClient(server.Client(t))
+		session.globalOpts.Yes = true
+		cmd := NewMembersCommand("members", &MembersOptions{}, session)
+		_, result, err := runCommand(t, cmd, args)
+		return result, err
+	}
+	names := func(result *Result) []string {
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	// Write the users file using "users list".
+	server := newFakeServer(t)
+	server.AddGroupMember("foo", "aberns", gitlab.OwnerPermissions)
+	server.AddProjectMember("foo/beta", "bcrocket", gitlab.GuestPermissions)
+	usersFileName := filepath.Join(t.TempDir(), "users.xml")
+	usersCmd := NewUsersCommand("users", &UsersOptions{},
+		NewSessionWithClient(server.Client(t)))
+	var err error
+	captureStdout(t, func() {
+		_, err = usersCmd.Run(context.Background(),
+			[]string{"list", "--users", "aberns,bcrocket", "-o", usersFileName})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// List the members of the group writing them to a file.
+	listFileName := filepath.Join(t.TempDir(), "members.xml")
+	listed, err := run(server, "list", "--group", "foo", "-o", listFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	listedUsers, err := xml_users.ReadUsers(listFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	projectsListed, err := run(server, "list", "--group", "foo",
+		"--scope", "projects", "--inherited")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Add the users to the group and to some of the projects.
+	dryRun := newFakeServer(t)
+	_, err = run(dryRun, "add", "--group", "foo", "--access-level", "developer",
+		"--users-file", usersFileName, "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	added, err := run(server, "add", "--group", "foo", "--access-level", "developer",
+		"--users-file", usersFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	skippedLevel := server.MemberAccessLevel("group", "foo", "aberns")
+	_, err = run(server, "add", "--group", "foo", "--scope", "projects",
+		"--expr", "alpha", "--access-level", "reporter",
+		"--expires-at", "2030-01-02", "--users-file", usersFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Update the access of the users in the projects.
+	_, err = run(server, "update-access", "--group", "foo", "--scope", "projects",
+		"--expr", "alpha|beta", "--access-level", "maintainer",
+		"--users-file", usersFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Remove the users from the group.
+	_, err = run(server, "remove", "--group", "foo", "--users-file", listFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, invalidLevelErr := run(newFakeServer(t), "add", "--group", "foo",
+		"--access-level", "admin", "--users-file", usersFileName)
+	_, noUsersErr := run(newFakeServer(t), "remove", "--group", "foo")
+	_, invalidScopeErr := run(newFakeServer(t), "list", "--group", "foo",
+		"--scope", "groups")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"list", []string{"foo:aberns"}, names(listed)},
+		{"list file", "aberns", listedUsers[0].Username},
+		{"list projects", 4, len(projectsListed.Succeeded())},
+		{"dry run", []string{}, dryRun.Members("group", "foo")},
+		{"add", []string{"foo:aberns", "foo:bcrocket"}, names(added)},
+		{"add skips members", gitlab.OwnerPermissions, skippedLevel},
+		{"add projects", []string{"aberns", "bcrocket"},
+			server.Members("project", "foo/alpha")},
+		{"add expires", "2030-01-02",
+			server.MemberExpiry("project", "foo/alpha", "bcrocket")},
+		{"add unmatched project", []string{"bcrocket"},
+			server.Members("project", "foo/beta")},
+		{"update access", gitlab.MaintainerPermissions,
+			server.MemberAccessLevel("project", "foo/alpha", "aberns")},
+		{"update access existing", gitlab.MaintainerPermissions,
+			server.MemberAccessLevel("project", "foo/beta", "bcrocket")},
+		{"remove", []string{"bcrocket"}, server.Members("group", "foo")},
+		{"invalid level", true, errors.Is(invalidLevelErr, ErrInvalidOption)},
+		{"no users", true, errors.Is(noUsersErr, ErrInvalidOption)},
+		{"invalid scope", true, errors.Is(invalidScopeErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("members %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
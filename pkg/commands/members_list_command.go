@@ -0,0 +1,238 @@
+// This file provides the implementation for the "members list"
+// command which lists the members of a group or of a selection of
+// projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_users"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MembersListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MembersListOptions are the options needed by this command.
+type MembersListOptions struct {
+
+	// Embed the options that select the group or projects.
+	MemberTargetOptions
+
+	// Inherited controls whether the members inherited from ancestor
+	// groups are also listed.  Defaults to false.
+	Inherited bool `xml:"inherited"`
+
+	// OutputFileName is the name of the XML output file to which the
+	// members are appended in the same format as "users list" so
+	// that the file can be passed to the other "members" commands.
+	// If empty, no output file is written.  Defaults to "".
+	OutputFileName string `xml:"output-file-name"`
+}
+
+// Initialize initializes this MembersListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *MembersListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --scope, --test-expr
+	opts.MemberTargetOptions.Initialize(flags)
+
+	// --inherited
+	flags.BoolVar(&opts.Inherited, "inherited", opts.Inherited,
+		i18n.T("whether members inherited from ancestor groups are also listed"))
+
+	// -o
+	flags.StringVar(&opts.OutputFileName, "o", opts.OutputFileName,
+		i18n.T("name of XML output file to which members will be appended"))
+
+	// --out
+	flags.StringVar(&opts.OutputFileName, "out", opts.OutputFileName,
+		i18n.T("name of XML output file to which members will be appended"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MembersListCommand
+////////////////////////////////////////////////////////////////////////
+
+// MembersListCommand implements the "members list" command which lists
+// the members of a group or of a selection of projects.
+type MembersListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MembersListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MembersListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] members list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the direct members of the --group or, for --scope\n")
+	i18n.Fprintf(out, "    projects, of the projects beneath it whose full paths match\n")
+	i18n.Fprintf(out, "    --expr.  Use -o to write the members to an XML file that can\n")
+	i18n.Fprintf(out, "    be passed to the other \"members\" commands.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMembersListCommand returns a new, initialized MembersListCommand
+// instance.
+func NewMembersListCommand(
+	name string,
+	opts *MembersListOptions,
+	session *Session,
+) *MembersListCommand {
+
+	// Create the new command.
+	cmd := &MembersListCommand{
+		GitlabCommand: GitlabCommand[MembersListOptions]{
+			BasicCommand: BasicCommand[MembersListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// PrintMemberships prints the memberships as a table.
+func PrintMemberships(out io.Writer, memberships []*Membership) {
+	row := func(sourceType, source, username, access, membership, expires string) {
+		fmt.Fprintf(out, "%-7s  %-30s  %-20s  %-10s  %-10s  %s\n",
+			sourceType, source, username, access, membership, expires)
+	}
+	row(i18n.T("TYPE"), i18n.T("SOURCE"), i18n.T("USERNAME"),
+		i18n.T("ACCESS"), i18n.T("MEMBERSHIP"), i18n.T("EXPIRES"))
+	for _, m := range memberships {
+		membership := "inherited"
+		if m.Direct {
+			membership = "direct"
+		}
+		expires := ""
+		if m.ExpiresAt != nil {
+			expires = m.ExpiresAt.String()
+		}
+		row(m.SourceType, m.Source, m.Username,
+			gitlab_util.AccessLevelName(m.AccessLevel), membership, expires)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *MembersListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MemberTargetOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the members of the group or projects.
+	targets, err := GetMemberTargets(ctx, MembersServices{
+		Groups:         cmd.client.Groups,
+		GroupMembers:   cmd.client.GroupMembers,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}, &cmd.options.MemberTargetOptions)
+	if err != nil {
+		return result, err
+	}
+	memberships := []*Membership{}
+	var users []*gitlab.User
+	seen := make(map[int]bool)
+	for _, target := range targets {
+		for _, m := range target.Memberships {
+			if !m.Direct && !cmd.options.Inherited {
+				continue
+			}
+			memberships = append(memberships, m)
+			result.Succeed(m.Source+":"+m.Username, m)
+			if !seen[m.UserID] {
+				seen[m.UserID] = true
+				users = append(users, &gitlab.User{
+					ID:       m.UserID,
+					Username: m.Username,
+					Name:     m.Name,
+				})
+			}
+		}
+	}
+
+	// Print the members.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, memberships)
+	} else {
+		PrintMemberships(os.Stdout, memberships)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Write the members to the XML file.
+	if cmd.options.OutputFileName != "" {
+		err = xml_users.WriteUsers(cmd.options.OutputFileName, users)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,253 @@
+// This file provides the implementation for the "members remove"
+// command which removes users from the members of a group or of a
+// selection of projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MembersRemoveOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MembersRemoveOptions are the options needed by this command.
+type MembersRemoveOptions struct {
+
+	// Embed the options that control when removing the members
+	// must be confirmed.
+	ConfirmationOptions
+
+	// Embed the options that control whether the remaining members
+	// are removed after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the group or projects.
+	MemberTargetOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// UsersFileName is the name of the XML file holding the users to
+	// remove which should contain the output of the "users list" or
+	// "members list" command.  Defaults to "".
+	UsersFileName string `xml:"users-file-name"`
+}
+
+// Initialize initializes this MembersRemoveOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *MembersRemoveOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --scope, --test-expr
+	opts.MemberTargetOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --users-file
+	flags.StringVar(&opts.UsersFileName, "users-file", opts.UsersFileName,
+		i18n.T("name of the XML file holding the users to remove which should "+
+			"contain the output of the \"users list\" command"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MembersRemoveCommand
+////////////////////////////////////////////////////////////////////////
+
+// MembersRemoveCommand implements the "members remove" command which
+// removes users from the members of a group or of a selection of
+// projects.
+type MembersRemoveCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MembersRemoveOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MembersRemoveCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] members remove [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Remove the direct memberships of the users in --users-file\n")
+	i18n.Fprintf(out, "    from the --group or, for --scope projects, from the projects\n")
+	i18n.Fprintf(out, "    beneath it whose full paths match --expr.  Users who are not\n")
+	i18n.Fprintf(out, "    direct members are skipped.  The memberships are listed and\n")
+	i18n.Fprintf(out, "    must be confirmed unless the global --yes option is passed.\n")
+	i18n.Fprintf(out, "    When removing more memberships than --confirm-threshold,\n")
+	i18n.Fprintf(out, "    --i-understand must be passed, and the group must be typed\n")
+	i18n.Fprintf(out, "    to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Remove Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMembersRemoveCommand returns a new, initialized
+// MembersRemoveCommand instance.
+func NewMembersRemoveCommand(
+	name string,
+	opts *MembersRemoveOptions,
+	session *Session,
+) *MembersRemoveCommand {
+
+	// Create the new command.
+	cmd := &MembersRemoveCommand{
+		GitlabCommand: GitlabCommand[MembersRemoveOptions]{
+			BasicCommand: BasicCommand[MembersRemoveOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *MembersRemoveCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MemberTargetOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	users, err := ReadMemberUsers(cmd.options.UsersFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the members of the group or projects.
+	s := MembersServices{
+		Groups:         cmd.client.Groups,
+		GroupMembers:   cmd.client.GroupMembers,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}
+	targets, err := GetMemberTargets(ctx, s, &cmd.options.MemberTargetOptions)
+	if err != nil {
+		return result, err
+	}
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, target := range targets {
+			for _, user := range users {
+				if FindDirectMembership(target, user.ID) != nil {
+					names = append(names, target.Path+":"+user.Username)
+				}
+			}
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("remove"), names,
+			cmd.options.Group, cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Remove each user who is a direct member stopping after the
+	// current user if interrupted.  If --keep-going is set, a member
+	// who cannot be removed is recorded in the result, and the
+	// remaining members are still removed.
+out:
+	for _, target := range targets {
+		for _, user := range users {
+			err = ctx.Err()
+			if err != nil {
+				break out
+			}
+			name := target.Path + ":" + user.Username
+			m := FindDirectMembership(target, user.ID)
+			if m == nil {
+				logging.Printf("- %q is not a direct member of %q.\n",
+					user.Username, target.Path)
+				result.Succeed(name, user)
+				continue
+			}
+			err = RemoveMembership(ctx, s.Membership(), m, cmd.options.DryRun)
+			if err != nil {
+				result.Fail(name, m, err)
+				if !cmd.options.KeepGoing {
+					break out
+				}
+				continue
+			}
+			result.Succeed(name, m)
+		}
+	}
+
+	err = cmd.options.Finish(os.Stdout, result, err, "could not remove %d member(s)")
+	return result, err
+}
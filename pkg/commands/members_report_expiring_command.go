@@ -0,0 +1,352 @@
+// This file provides the implementation for the "members report
+// expiring" command which reports the memberships that expire soon
+// and can extend or immediately expire them in bulk for contractor
+// access hygiene.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MembersReportExpiringOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MembersReportExpiringOptions are the options needed by this command.
+type MembersReportExpiringOptions struct {
+
+	// Days is the number of days within which a membership must
+	// expire to be reported.  Defaults to 30.
+	Days uint64 `xml:"days"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ExpireNow should cause the reported memberships to be removed
+	// immediately instead of when they expire.  Defaults to false.
+	ExpireNow bool `xml:"expire-now"`
+
+	// Extend is the number of days by which the expiry date of each
+	// reported membership is extended.  Defaults to 0 which means the
+	// memberships are only reported.
+	Extend uint64 `xml:"extend"`
+
+	// Group is the full path of the root group beneath which the
+	// memberships are reported.  Defaults to "".
+	Group string `xml:"group"`
+}
+
+// Initialize initializes this MembersReportExpiringOptions instance
+// so it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *MembersReportExpiringOptions) Initialize(flags *flag.FlagSet) {
+
+	// --days
+	if opts.Days == 0 {
+		opts.Days = 30
+	}
+	flags.Uint64Var(&opts.Days, "days", opts.Days,
+		i18n.T("number of days within which memberships must expire to be reported"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --expire-now
+	flags.BoolVar(&opts.ExpireNow, "expire-now", opts.ExpireNow,
+		i18n.T("remove the reported memberships now instead of when they expire"))
+
+	// --extend
+	flags.Uint64Var(&opts.Extend, "extend", opts.Extend,
+		i18n.T("number of days by which to extend the reported memberships"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the root group beneath which memberships are reported"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MembersReportExpiringCommand
+////////////////////////////////////////////////////////////////////////
+
+// MembersReportExpiringCommand implements the "members report
+// expiring" command which reports and updates expiring memberships.
+type MembersReportExpiringCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MembersReportExpiringOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MembersReportExpiringCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] members report expiring [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the direct memberships of the root group and of the\n")
+	i18n.Fprintf(out, "    groups and projects beneath it that expire within the given\n")
+	i18n.Fprintf(out, "    number of days.  With --extend, the expiry date of each\n")
+	i18n.Fprintf(out, "    reported membership is moved later by the given number of\n")
+	i18n.Fprintf(out, "    days.  With --expire-now, the reported memberships are\n")
+	i18n.Fprintf(out, "    removed immediately.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Expiring Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMembersReportExpiringCommand returns a new, initialized
+// MembersReportExpiringCommand instance.
+func NewMembersReportExpiringCommand(
+	name string,
+	opts *MembersReportExpiringOptions,
+	session *Session,
+) *MembersReportExpiringCommand {
+
+	// Create the new command.
+	cmd := &MembersReportExpiringCommand{
+		GitlabCommand: GitlabCommand[MembersReportExpiringOptions]{
+			BasicCommand: BasicCommand[MembersReportExpiringOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// MembershipServices are the Gitlab services needed to update the
+// direct memberships of groups and projects.
+type MembershipServices struct {
+	GroupMembers   gitlab_util.GroupMembersManager   /* was *gitlab.GroupMembersService */
+	ProjectMembers gitlab_util.ProjectMembersManager /* was *gitlab.ProjectMembersService */
+}
+
+// FindExpiringMemberships returns the direct memberships that expire
+// from today up to but not including the cutoff.
+func FindExpiringMemberships(memberships []*Membership, cutoff time.Time) []*Membership {
+	var result []*Membership
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, m := range memberships {
+		if !m.Direct || m.ExpiresAt == nil {
+			continue
+		}
+		expires := time.Time(*m.ExpiresAt)
+		if !expires.Before(today) && expires.Before(cutoff) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// ExtendMembership moves the expiry date of the direct membership
+// later by the number of days.  If dryRun is true, this function only
+// prints what it would without actually doing it.
+func ExtendMembership(
+	ctx context.Context,
+	s MembershipServices,
+	m *Membership,
+	days int,
+	dryRun bool,
+) error {
+	name := m.Source + ":" + m.Username
+	expires := time.Time(*m.ExpiresAt).AddDate(0, 0, days).Format("2006-01-02")
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- Extending membership of %q in %q to %s ... ",
+		m.Username, m.Source, expires)
+	if !dryRun {
+		var err error
+		if m.SourceType == "group" {
+			_, _, err = s.GroupMembers.EditGroupMember(m.Source, m.UserID,
+				&gitlab.EditGroupMemberOptions{ExpiresAt: gitlab.Ptr(expires)},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		} else {
+			_, _, err = s.ProjectMembers.EditProjectMember(m.Source, m.UserID,
+				&gitlab.EditProjectMemberOptions{ExpiresAt: gitlab.Ptr(expires)},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		}
+		if err != nil {
+			err = fmt.Errorf(
+				"ExtendMembership: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(name, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	return nil
+}
+
+// RemoveMembership removes the direct membership.  If dryRun is true,
+// this function only prints what it would without actually doing it.
+func RemoveMembership(
+	ctx context.Context,
+	s MembershipServices,
+	m *Membership,
+	dryRun bool,
+) error {
+	name := m.Source + ":" + m.Username
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- Removing membership of %q in %q ... ", m.Username, m.Source)
+	if !dryRun {
+		var err error
+		if m.SourceType == "group" {
+			_, err = s.GroupMembers.RemoveGroupMember(m.Source, m.UserID,
+				nil, gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		} else {
+			_, err = s.ProjectMembers.DeleteProjectMember(m.Source, m.UserID,
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		}
+		if err != nil {
+			err = fmt.Errorf(
+				"RemoveMembership: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(name, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	return nil
+}
+
+// PrintExpiringMemberships prints the expiring memberships as a table.
+func PrintExpiringMemberships(out io.Writer, memberships []*Membership) {
+	row := func(expires, access, sourceType, source, username string) {
+		fmt.Fprintf(out, "%-10s  %-10s  %-7s  %-30s  %s\n",
+			expires, access, sourceType, source, username)
+	}
+	row(i18n.T("EXPIRES"), i18n.T("ACCESS"), i18n.T("TYPE"),
+		i18n.T("SOURCE"), i18n.T("USERNAME"))
+	for _, m := range memberships {
+		row(m.ExpiresAt.String(), gitlab_util.AccessLevelName(m.AccessLevel),
+			m.SourceType, m.Source, m.Username)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *MembersReportExpiringCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	if cmd.options.Days == 0 {
+		return result, i18n.Errorf("%w: invalid days: %v",
+			ErrInvalidOption, cmd.options.Days)
+	}
+	if cmd.options.Extend > 0 && cmd.options.ExpireNow {
+		return result, i18n.Errorf("%w: --extend and --expire-now are mutually exclusive",
+			ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Find the expiring memberships.  The groups and projects that
+	// are visited are not part of the result.
+	memberships, err := GetAllMemberships(ctx, NewResult(), AccessReviewServices{
+		Groups:         cmd.client.Groups,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}, cmd.options.Group)
+	if err != nil {
+		return result, err
+	}
+	now := time.Now()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).
+		AddDate(0, 0, int(cmd.options.Days)+1)
+	expiring := FindExpiringMemberships(memberships, cutoff)
+
+	// Print the report.
+	PrintExpiringMemberships(os.Stdout, expiring)
+	if cmd.options.Extend == 0 && !cmd.options.ExpireNow {
+		for _, m := range expiring {
+			result.Succeed(m.Source+":"+m.Username, m)
+		}
+		return result, nil
+	}
+
+	// Update the memberships stopping after the current membership
+	// if interrupted.
+	fmt.Println()
+	services := MembershipServices{
+		GroupMembers:   cmd.client.GroupMembers,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}
+	for _, m := range expiring {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		name := m.Source + ":" + m.Username
+		if cmd.options.ExpireNow {
+			err = RemoveMembership(ctx, services, m, cmd.options.DryRun)
+		} else {
+			err = ExtendMembership(ctx, services, m,
+				int(cmd.options.Extend), cmd.options.DryRun)
+		}
+		if err != nil {
+			result.Fail(name, m, err)
+			return result, err
+		}
+		result.Succeed(name, m)
+	}
+
+	return result, nil
+}
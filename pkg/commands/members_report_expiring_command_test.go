@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestMembersReportExpiringIntegration(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	newServer := func() *fake_gitlab.Server {
+		server := newFakeServer(t)
+		server.AddGroupMember("foo", "aberns", gitlab.OwnerPermissions)
+		server.SetMemberExpiry("group", "foo", "aberns", today.AddDate(0, 0, 10))
+		server.AddGroupMember("foo/bar", "bcrocket", gitlab.DeveloperPermissions)
+		server.SetMemberExpiry("group", "foo/bar", "bcrocket", today.AddDate(0, 0, 5))
+		server.AddProjectMember("foo/alpha", "bcrocket", gitlab.DeveloperPermissions)
+		server.SetMemberExpiry("project", "foo/alpha", "bcrocket", today.AddDate(0, 0, 60))
+		return server
+	}
+	run := func(server *fake_gitlab.Server, args ...string) *Result {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewMembersCommand("members", &MembersOptions{}, session)
+		var err error
+		var result *Result
+		captureStdout(t, func() {
+			result, err = cmd.Run(context.Background(),
+				append([]string{"report", "expiring", "--group", "foo"}, args...))
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v: %v", args, err)
+		}
+		return result
+	}
+	names := func(result *Result) []string {
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	// Report, extend, and expire the memberships.
+	reported := run(newServer())
+	extended := newServer()
+	run(extended, "--extend", "30")
+	dryRun := newServer()
+	run(dryRun, "--expire-now", "--dry-run")
+	expired := newServer()
+	run(expired, "--expire-now")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"reported", []string{"foo:aberns", "foo/bar:bcrocket"}, names(reported)},
+		{"extended", today.AddDate(0, 0, 40).Format("2006-01-02"),
+			extended.MemberExpiry("group", "foo", "aberns")},
+		{"not extended", today.AddDate(0, 0, 60).Format("2006-01-02"),
+			extended.MemberExpiry("project", "foo/alpha", "bcrocket")},
+		{"dry run", []string{"aberns"}, dryRun.Members("group", "foo")},
+		{"expired", []string{}, expired.Members("group", "foo")},
+		{"not expired", []string{"bcrocket"}, expired.Members("project", "foo/alpha")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("members report expiring %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+// This file provides the implementation for the "members
+// update-access" command which changes the access level of members of
+// a group or of a selection of projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MembersUpdateAccessOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MembersUpdateAccessOptions are the options needed by this command.
+type MembersUpdateAccessOptions struct {
+
+	// Embed the options that control whether the remaining members
+	// are updated after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the group or projects.
+	MemberTargetOptions
+
+	// AccessLevel is the new access level of the members which is one
+	// of "guest", "reporter", "developer", "maintainer", or "owner".
+	// Defaults to "".
+	AccessLevel string `xml:"access-level"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// UsersFileName is the name of the XML file holding the users
+	// whose access level is changed which should contain the output
+	// of the "users list" or "members list" command.  Defaults to "".
+	UsersFileName string `xml:"users-file-name"`
+}
+
+// Initialize initializes this MembersUpdateAccessOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *MembersUpdateAccessOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --scope, --test-expr
+	opts.MemberTargetOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// --access-level
+	flags.StringVar(&opts.AccessLevel, "access-level", opts.AccessLevel,
+		i18n.T("new access level of the members which is one of guest, "+
+			"reporter, developer, maintainer, or owner"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --users-file
+	flags.StringVar(&opts.UsersFileName, "users-file", opts.UsersFileName,
+		i18n.T("name of the XML file holding the users whose access level is "+
+			"changed which should contain the output of the \"users list\" command"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MembersUpdateAccessCommand
+////////////////////////////////////////////////////////////////////////
+
+// MembersUpdateAccessCommand implements the "members update-access"
+// command which changes the access level of members of a group or of a
+// selection of projects.
+type MembersUpdateAccessCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MembersUpdateAccessOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MembersUpdateAccessCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] members update-access [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Change the access level of the direct memberships of the\n")
+	i18n.Fprintf(out, "    users in --users-file to --access-level in the --group or,\n")
+	i18n.Fprintf(out, "    for --scope projects, in the projects beneath it whose full\n")
+	i18n.Fprintf(out, "    paths match --expr.  Users who are not direct members are\n")
+	i18n.Fprintf(out, "    skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Update Access Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMembersUpdateAccessCommand returns a new, initialized
+// MembersUpdateAccessCommand instance.
+func NewMembersUpdateAccessCommand(
+	name string,
+	opts *MembersUpdateAccessOptions,
+	session *Session,
+) *MembersUpdateAccessCommand {
+
+	// Create the new command.
+	cmd := &MembersUpdateAccessCommand{
+		GitlabCommand: GitlabCommand[MembersUpdateAccessOptions]{
+			BasicCommand: BasicCommand[MembersUpdateAccessOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// UpdateMembershipAccess changes the access level of the direct
+// membership.  If dryRun is true, this function only prints what it
+// would without actually doing it.
+func UpdateMembershipAccess(
+	ctx context.Context,
+	s MembershipServices,
+	m *Membership,
+	level gitlab.AccessLevelValue,
+	dryRun bool,
+) error {
+	name := m.Source + ":" + m.Username
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- Changing access of %q in %q from %s to %s ... ",
+		m.Username, m.Source, gitlab_util.AccessLevelName(m.AccessLevel),
+		gitlab_util.AccessLevelName(level))
+	if !dryRun {
+		var err error
+		if m.SourceType == "group" {
+			_, _, err = s.GroupMembers.EditGroupMember(m.Source, m.UserID,
+				&gitlab.EditGroupMemberOptions{AccessLevel: gitlab.Ptr(level)},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		} else {
+			_, _, err = s.ProjectMembers.EditProjectMember(m.Source, m.UserID,
+				&gitlab.EditProjectMemberOptions{AccessLevel: gitlab.Ptr(level)},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		}
+		if err != nil {
+			logging.Printf("Failed.\n")
+			err = fmt.Errorf(
+				"UpdateMembershipAccess: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(name, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *MembersUpdateAccessCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MemberTargetOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	level, err := ParseAccessLevel(cmd.options.AccessLevel)
+	if err != nil {
+		return result, err
+	}
+	users, err := ReadMemberUsers(cmd.options.UsersFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the members of the group or projects.
+	s := MembersServices{
+		Groups:         cmd.client.Groups,
+		GroupMembers:   cmd.client.GroupMembers,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}
+	targets, err := GetMemberTargets(ctx, s, &cmd.options.MemberTargetOptions)
+	if err != nil {
+		return result, err
+	}
+
+	// Change the access level of each user who is a direct member
+	// stopping after the current user if interrupted.  If --keep-going
+	// is set, a member whose access cannot be changed is recorded in
+	// the result, and the remaining members are still changed.
+out:
+	for _, target := range targets {
+		for _, user := range users {
+			err = ctx.Err()
+			if err != nil {
+				break out
+			}
+			name := target.Path + ":" + user.Username
+			m := FindDirectMembership(target, user.ID)
+			if m == nil {
+				logging.Printf("- %q is not a direct member of %q.\n",
+					user.Username, target.Path)
+				result.Succeed(name, user)
+				continue
+			}
+			if m.AccessLevel == level {
+				logging.Printf("- Access of %q in %q already %s.\n",
+					m.Username, m.Source, gitlab_util.AccessLevelName(level))
+				result.Succeed(name, m)
+				continue
+			}
+			err = UpdateMembershipAccess(ctx, s.Membership(), m, level,
+				cmd.options.DryRun)
+			if err != nil {
+				result.Fail(name, m, err)
+				if !cmd.options.KeepGoing {
+					break out
+				}
+				continue
+			}
+			result.Succeed(name, m)
+		}
+	}
+
+	err = cmd.options.Finish(os.Stdout, result, err, "could not update the access of %d member(s)")
+	return result, err
+}
@@ -0,0 +1,1122 @@
+// This file provides the implementation for the "migrate group"
+// command which copies a group hierarchy including its subgroups,
+// projects, memberships, CI/CD variables, labels, and approval rules
+// from one Gitlab instance to another.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MigrateGroupOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MigrateGroupOptions are the options needed by this command.
+type MigrateGroupOptions struct {
+
+	// DestAuthFileName is the name of the file that holds the
+	// authentication information for the destination instance.
+	// Defaults to "dest-auth.xml".
+	DestAuthFileName string `xml:"dest-auth-file-name"`
+
+	// DestBaseURL is the URL of the destination instance.  Defaults
+	// to "".
+	DestBaseURL string `xml:"dest-base-url"`
+
+	// DestParent is the full path of the group on the destination
+	// instance under which the source group is created.  Defaults to
+	// "" which creates the group at the top level.
+	DestParent string `xml:"dest-parent"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Group is the full path of the group to migrate from the source
+	// instance.  Defaults to "".
+	Group string `xml:"group"`
+
+	// StateFileName is the name of the file that records the steps
+	// that have completed so an interrupted migration can be
+	// resumed.  Defaults to "migrate-state.xml".
+	StateFileName string `xml:"state-file-name"`
+
+	// Timeout is how long to wait for each project export or import
+	// to finish in the form accepted by time.ParseDuration() (e.g.,
+	// "30m").  Defaults to "1h".
+	Timeout string `xml:"timeout"`
+}
+
+// Initialize initializes this MigrateGroupOptions instance so it can
+// be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *MigrateGroupOptions) Initialize(flags *flag.FlagSet) {
+
+	// --dest-auth
+	if opts.DestAuthFileName == "" {
+		opts.DestAuthFileName = "dest-auth.xml"
+	}
+	flags.StringVar(&opts.DestAuthFileName, "dest-auth", opts.DestAuthFileName,
+		i18n.T("XML file with authentication information for the destination"))
+
+	// --dest-base-url
+	flags.StringVar(&opts.DestBaseURL, "dest-base-url", opts.DestBaseURL,
+		i18n.T("URL of the destination Gitlab instance"))
+
+	// --dest-parent
+	flags.StringVar(&opts.DestParent, "dest-parent", opts.DestParent,
+		i18n.T("full path of the destination group under which to create the group"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the source group to migrate"))
+
+	// --state-file
+	if opts.StateFileName == "" {
+		opts.StateFileName = "migrate-state.xml"
+	}
+	flags.StringVar(&opts.StateFileName, "state-file", opts.StateFileName,
+		i18n.T("file that records progress so the migration can be resumed"))
+
+	// --timeout
+	if opts.Timeout == "" {
+		opts.Timeout = "1h"
+	}
+	flags.StringVar(&opts.Timeout, "timeout", opts.Timeout,
+		i18n.T("how long to wait for each project export or import to "+
+			"finish (e.g., \"30m\")"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MigrateGroupCommand
+////////////////////////////////////////////////////////////////////////
+
+// MigrateGroupCommand implements the "migrate group" command which
+// copies a group hierarchy from one Gitlab instance to another.
+type MigrateGroupCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MigrateGroupOptions]
+
+	// destSession is the session for the destination instance.  It
+	// is created from the options when the command is run unless it
+	// has already been set.
+	destSession *Session
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MigrateGroupCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] migrate group [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Copies a group hierarchy to another Gitlab instance.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    The global options select the source instance.  The\n")
+	i18n.Fprintf(out, "    --dest-* options select the destination instance.\n")
+	i18n.Fprintf(out, "    Completed steps are recorded in the state file so\n")
+	i18n.Fprintf(out, "    running the command again resumes the migration.\n")
+	i18n.Fprintf(out, "    Each project export and import must finish within\n")
+	i18n.Fprintf(out, "    the --timeout.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Group Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMigrateGroupCommand returns a new, initialized
+// MigrateGroupCommand instance.
+func NewMigrateGroupCommand(
+	name string,
+	opts *MigrateGroupOptions,
+	session *Session,
+) *MigrateGroupCommand {
+
+	// Create the new command.
+	cmd := &MigrateGroupCommand{
+		GitlabCommand: GitlabCommand[MigrateGroupOptions]{
+			BasicCommand: BasicCommand[MigrateGroupOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *MigrateGroupCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	if cmd.destSession == nil && cmd.options.DestBaseURL == "" {
+		return result, i18n.Errorf("%w: dest-base-url not set", ErrInvalidOption)
+	}
+	timeout, err := time.ParseDuration(cmd.options.Timeout)
+	if err != nil || timeout <= 0 {
+		return result, i18n.Errorf("%w: invalid timeout: %q",
+			ErrInvalidOption, cmd.options.Timeout)
+	}
+
+	// Load the state.
+	state, err := LoadMigrationState(
+		cmd.options.StateFileName, cmd.options.Group, cmd.options.DestParent)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to the source instance.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to the destination instance.
+	if cmd.destSession == nil {
+		cmd.destSession = NewSession(&GlobalOptions{
+			AuthFileName: cmd.options.DestAuthFileName,
+			BaseURL:      cmd.options.DestBaseURL,
+		})
+
+		// The token in the environment (if any) is for the source
+		// instance, so the destination must use its own file.
+		cmd.destSession.ignoreAuthEnv = true
+	}
+	dest, err := cmd.destSession.Client()
+	if err != nil {
+		return result, err
+	}
+
+	// Migrate the group.
+	err = MigrateGroup(ctx, result, cmd.client, dest, state,
+		cmd.options.StateFileName, timeout, cmd.options.DryRun)
+	return result, err
+}
+
+////////////////////////////////////////////////////////////////////////
+// Group Migration
+////////////////////////////////////////////////////////////////////////
+
+// migratePollInterval is how long to wait between checks of the
+// status of a project export or import.
+var migratePollInterval = 5 * time.Second
+
+// groupMigrator holds the state needed while migrating a group.
+// Groups and projects on both instances are always referred to by
+// their full paths so that nothing needs to be looked up on the
+// destination instance during a dry run.
+type groupMigrator struct {
+	result        *Result
+	src           *gitlab.Client
+	dest          *gitlab.Client
+	state         *MigrationState
+	stateFileName string
+	timeout       time.Duration
+	dryRun        bool
+
+	// srcRoot is the full path of the source group.
+	srcRoot string
+
+	// userIDs maps usernames to user IDs on the destination
+	// instance.  A user ID of 0 means the user does not exist.
+	userIDs map[string]int
+
+	// warnings are printed after the current step finishes.
+	warnings []string
+}
+
+// MigrateGroup copies the source group in the state including its
+// subgroups, projects, memberships, CI/CD variables, labels, and
+// approval rules from the src instance to the state's destination
+// parent group on the dest instance.  Each step that completes is
+// recorded in the state which is saved to stateFileName.  Steps that
+// were already recorded are skipped which allows an interrupted
+// migration to be resumed.  Users are matched by username, and
+// memberships and approvers for users who do not exist on the
+// destination instance are skipped with a warning.  Each project
+// export and import that does not finish within the timeout fails the
+// migration.  If dryRun is true, this function only prints what it
+// would without actually doing it.
+func MigrateGroup(
+	ctx context.Context,
+	result *Result,
+	src *gitlab.Client,
+	dest *gitlab.Client,
+	state *MigrationState,
+	stateFileName string,
+	timeout time.Duration,
+	dryRun bool,
+) error {
+	m := &groupMigrator{
+		result:        result,
+		src:           src,
+		dest:          dest,
+		state:         state,
+		stateFileName: stateFileName,
+		timeout:       timeout,
+		dryRun:        dryRun,
+		userIDs:       make(map[string]int),
+	}
+
+	// Find the source group.
+	g, err := gitlab_util.FindExactGroup(ctx, src.Groups, state.SourceGroup)
+	if err != nil {
+		return fmt.Errorf("MigrateGroup: %w", err)
+	}
+
+	// Migrate the group.
+	m.srcRoot = g.FullPath
+	err = m.migrateGroup(ctx, g, state.DestParent)
+	if err != nil {
+		return fmt.Errorf("MigrateGroup: %w", err)
+	}
+
+	return nil
+}
+
+// step runs f unless the step has already been done.  After f
+// succeeds, the step is recorded in the state file.
+func (m *groupMigrator) step(
+	ctx context.Context,
+	key string,
+	description string,
+	f func() error,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	if m.state.IsDone(key) {
+		logging.Printf("- %s ... Skipped (already done).\n", description)
+		return nil
+	}
+	hook.OnItemStart(key)
+	logging.Printf("- %s ... ", description)
+	m.warnings = nil
+	if !m.dryRun {
+		err := f()
+		if err == nil {
+			m.state.MarkDone(key)
+			err = m.state.Save(m.stateFileName)
+		}
+		if err != nil {
+			hook.OnError(key, err)
+			m.result.Fail(key, nil, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	for _, w := range m.warnings {
+		i18n.Printf("  Warning: %s\n", w)
+	}
+	hook.OnItemDone(key)
+	m.result.Succeed(key, nil)
+	return nil
+}
+
+// warn records a warning that is printed after the current step
+// finishes.
+func (m *groupMigrator) warn(format string, a ...any) {
+	m.warnings = append(m.warnings, i18n.Sprintf(format, a...))
+}
+
+// joinPath joins the parent full path and the path.
+func joinPath(parent string, path string) string {
+	if parent == "" {
+		return path
+	}
+	return parent + "/" + path
+}
+
+// migrateGroup migrates the group and everything in it to the
+// destination parent group.
+func (m *groupMigrator) migrateGroup(
+	ctx context.Context,
+	g *gitlab.Group,
+	destParent string,
+) error {
+	destPath := joinPath(destParent, g.Path)
+
+	// Create the group.
+	err := m.step(ctx, "group:"+g.FullPath,
+		i18n.Sprintf("Creating group: %q", destPath),
+		func() error { return m.createGroup(ctx, g, destParent) })
+	if err != nil {
+		return err
+	}
+
+	// Copy the members.
+	err = m.step(ctx, "group-members:"+g.FullPath,
+		i18n.Sprintf("Copying members of group: %q", destPath),
+		func() error { return m.copyGroupMembers(ctx, g.FullPath, destPath) })
+	if err != nil {
+		return err
+	}
+
+	// Copy the variables.
+	err = m.step(ctx, "group-variables:"+g.FullPath,
+		i18n.Sprintf("Copying variables of group: %q", destPath),
+		func() error { return m.copyGroupVariables(ctx, g.FullPath, destPath) })
+	if err != nil {
+		return err
+	}
+
+	// Copy the labels.
+	err = m.step(ctx, "group-labels:"+g.FullPath,
+		i18n.Sprintf("Copying labels of group: %q", destPath),
+		func() error { return m.copyGroupLabels(ctx, g.FullPath, destPath) })
+	if err != nil {
+		return err
+	}
+
+	// Migrate the projects.
+	projects, err := gitlab_util.GetAllProjects(
+		ctx, m.src.Groups, g.FullPath, "", false)
+	if err != nil {
+		return err
+	}
+	for _, p := range projects {
+		err = m.migrateProject(ctx, p, destPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Migrate the subgroups.
+	subgroups, err := listSubgroups(ctx, m.src, g.FullPath)
+	if err != nil {
+		return err
+	}
+	for _, sg := range subgroups {
+		err = m.migrateGroup(ctx, sg, destPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateProject migrates the project and its settings to the
+// destination group.
+func (m *groupMigrator) migrateProject(
+	ctx context.Context,
+	p *gitlab.Project,
+	destGroup string,
+) error {
+	destPath := joinPath(destGroup, p.Path)
+
+	// Copy the project.
+	err := m.step(ctx, "project:"+p.PathWithNamespace,
+		i18n.Sprintf("Copying project: %q", destPath),
+		func() error { return m.copyProject(ctx, p, destGroup) })
+	if err != nil {
+		return err
+	}
+
+	// Copy the members.
+	err = m.step(ctx, "project-members:"+p.PathWithNamespace,
+		i18n.Sprintf("Copying members of project: %q", destPath),
+		func() error { return m.copyProjectMembers(ctx, p.PathWithNamespace, destPath) })
+	if err != nil {
+		return err
+	}
+
+	// Copy the variables.
+	err = m.step(ctx, "project-variables:"+p.PathWithNamespace,
+		i18n.Sprintf("Copying variables of project: %q", destPath),
+		func() error { return m.copyProjectVariables(ctx, p.PathWithNamespace, destPath) })
+	if err != nil {
+		return err
+	}
+
+	// Copy the approval rules.
+	err = m.step(ctx, "project-approval-rules:"+p.PathWithNamespace,
+		i18n.Sprintf("Copying approval rules of project: %q", destPath),
+		func() error { return m.copyApprovalRules(ctx, p.PathWithNamespace, destPath) })
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createGroup creates the group on the destination instance unless
+// it already exists.
+func (m *groupMigrator) createGroup(
+	ctx context.Context,
+	g *gitlab.Group,
+	destParent string,
+) error {
+
+	// Skip groups that already exist.
+	destPath := joinPath(destParent, g.Path)
+	_, _, err := m.dest.Groups.GetGroup(destPath, nil, gitlab.WithContext(ctx))
+	if err == nil {
+		m.warn("group %q already exists", destPath)
+		return nil
+	}
+	if err = gitlab_util.ClassifyError(err); !errors.Is(err, gitlab_util.ErrNotFound) {
+		return err
+	}
+
+	// Create the group.
+	opts := gitlab.CreateGroupOptions{
+		Name:        &g.Name,
+		Path:        &g.Path,
+		Description: &g.Description,
+		Visibility:  &g.Visibility,
+	}
+	if destParent != "" {
+		parent, _, err := m.dest.Groups.GetGroup(
+			destParent, nil, gitlab.WithContext(ctx))
+		if err != nil {
+			return gitlab_util.ClassifyError(err)
+		}
+		opts.ParentID = &parent.ID
+	}
+	_, _, err = m.dest.Groups.CreateGroup(&opts,
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	return gitlab_util.ClassifyError(err)
+}
+
+// copyProject exports the project from the source instance and
+// imports it into the destination group unless its import already
+// finished.  If the project is still being imported, this function
+// waits for the import to finish instead of starting another one.
+func (m *groupMigrator) copyProject(
+	ctx context.Context,
+	p *gitlab.Project,
+	destGroup string,
+) error {
+
+	// Check for a project that already exists.  A project that was
+	// not created by an import is left as is.  One whose import
+	// failed has to be deleted by hand because it still has the path.
+	destPath := joinPath(destGroup, p.Path)
+	existing, _, err := m.dest.ProjectImportExport.ImportStatus(
+		destPath, gitlab.WithContext(ctx))
+	if err == nil {
+		switch existing.ImportStatus {
+		case "finished":
+			m.warn("project %q already exists", destPath)
+			return nil
+		case "none":
+			m.warn("project %q already exists but was not imported", destPath)
+			return nil
+		case "failed":
+			return i18n.Errorf("import %q: status is %q; delete the project "+
+				"and run the migration again", destPath, existing.ImportStatus)
+		}
+		return m.waitForImport(ctx, existing.ID, destPath)
+	}
+	if err = gitlab_util.ClassifyError(err); !errors.Is(err, gitlab_util.ErrNotFound) {
+		return err
+	}
+
+	// Export the project.
+	_, err = m.src.ProjectImportExport.ScheduleExport(
+		p.ID, nil, gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return gitlab_util.ClassifyError(err)
+	}
+	err = pollMigrationStatus(ctx, m.timeout, func() (string, error) {
+		status, _, err := m.src.ProjectImportExport.ExportStatus(
+			p.ID, gitlab.WithContext(ctx))
+		if err != nil {
+			return "", gitlab_util.ClassifyError(err)
+		}
+		return status.ExportStatus, nil
+	})
+	if err != nil {
+		return fmt.Errorf("export %q: %w", p.PathWithNamespace, err)
+	}
+
+	// Download the export to a temporary file so it does not have
+	// to fit in memory.
+	archive, err := os.CreateTemp("", "gitlab-export-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	err = gitlab_util.DownloadProjectExport(ctx, m.src, p.ID, archive)
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	// Import the project.
+	status, err := gitlab_util.ImportProjectFromFile(
+		gitlab_util.Uninterruptible(ctx), m.dest, archive.Name(),
+		destGroup, p.Name, p.Path)
+	if err != nil {
+		return err
+	}
+	return m.waitForImport(ctx, status.ID, destPath)
+}
+
+// waitForImport waits for the import of the project having the ID on
+// the destination instance to finish.
+func (m *groupMigrator) waitForImport(
+	ctx context.Context,
+	id int,
+	destPath string,
+) error {
+	err := pollMigrationStatus(ctx, m.timeout, func() (string, error) {
+		status, _, err := m.dest.ProjectImportExport.ImportStatus(
+			id, gitlab.WithContext(ctx))
+		if err != nil {
+			return "", gitlab_util.ClassifyError(err)
+		}
+		return status.ImportStatus, nil
+	})
+	if err != nil {
+		return fmt.Errorf("import %q: %w", destPath, err)
+	}
+	return nil
+}
+
+// pollMigrationStatus calls getStatus until the export or import it
+// reports on has finished or failed.  If it has not finished within
+// the timeout, an error is returned.
+func pollMigrationStatus(
+	ctx context.Context,
+	timeout time.Duration,
+	getStatus func() (string, error),
+) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := getStatus()
+		if err != nil {
+			return err
+		}
+		switch status {
+		case "finished":
+			return nil
+		case "failed":
+			return i18n.Errorf("status is %q", status)
+		}
+		if !time.Now().Before(deadline) {
+			return i18n.Errorf("timed out after %v with status %q",
+				timeout, status)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(migratePollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// destUserID returns the ID of the user on the destination instance
+// that has the same username or 0 if there is no such user.
+func (m *groupMigrator) destUserID(ctx context.Context, username string) (int, error) {
+	if id, ok := m.userIDs[username]; ok {
+		return id, nil
+	}
+	users, _, err := m.dest.Users.ListUsers(
+		&gitlab.ListUsersOptions{Username: &username},
+		gitlab.WithContext(ctx))
+	if err != nil {
+		return 0, gitlab_util.ClassifyError(err)
+	}
+	id := 0
+	if len(users) > 0 {
+		id = users[0].ID
+	}
+	m.userIDs[username] = id
+	return id, nil
+}
+
+// copyGroupMembers copies the direct members of the group.
+func (m *groupMigrator) copyGroupMembers(
+	ctx context.Context,
+	srcPath string,
+	destPath string,
+) error {
+
+	// Get the members on both instances.
+	srcMembers, err := listGroupMembers(ctx, m.src, srcPath)
+	if err != nil {
+		return err
+	}
+	destMembers, err := listGroupMembers(ctx, m.dest, destPath)
+	if err != nil {
+		return err
+	}
+
+	// Add the missing members.
+	for _, member := range srcMembers {
+		if slices.ContainsFunc(destMembers, func(dm *gitlab.GroupMember) bool {
+			return dm.Username == member.Username
+		}) {
+			continue
+		}
+		id, err := m.destUserID(ctx, member.Username)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			m.warn("user %q not found on destination", member.Username)
+			continue
+		}
+		_, _, err = m.dest.GroupMembers.AddGroupMember(destPath,
+			&gitlab.AddGroupMemberOptions{
+				UserID:      &id,
+				AccessLevel: &member.AccessLevel,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			return gitlab_util.ClassifyError(err)
+		}
+	}
+
+	return nil
+}
+
+// copyProjectMembers copies the direct members of the project.
+func (m *groupMigrator) copyProjectMembers(
+	ctx context.Context,
+	srcPath string,
+	destPath string,
+) error {
+
+	// Get the members on both instances.
+	srcMembers, err := listProjectMembers(ctx, m.src, srcPath)
+	if err != nil {
+		return err
+	}
+	destMembers, err := listProjectMembers(ctx, m.dest, destPath)
+	if err != nil {
+		return err
+	}
+
+	// Add the missing members.
+	for _, member := range srcMembers {
+		if slices.ContainsFunc(destMembers, func(dm *gitlab.ProjectMember) bool {
+			return dm.Username == member.Username
+		}) {
+			continue
+		}
+		id, err := m.destUserID(ctx, member.Username)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			m.warn("user %q not found on destination", member.Username)
+			continue
+		}
+		_, _, err = m.dest.ProjectMembers.AddProjectMember(destPath,
+			&gitlab.AddProjectMemberOptions{
+				UserID:      id,
+				AccessLevel: &member.AccessLevel,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			return gitlab_util.ClassifyError(err)
+		}
+	}
+
+	return nil
+}
+
+// copyGroupVariables copies the CI/CD variables of the group.
+func (m *groupMigrator) copyGroupVariables(
+	ctx context.Context,
+	srcPath string,
+	destPath string,
+) error {
+
+	// Get the variables on both instances.
+	srcVariables, err := listGroupVariables(ctx, m.src, srcPath)
+	if err != nil {
+		return err
+	}
+	destVariables, err := listGroupVariables(ctx, m.dest, destPath)
+	if err != nil {
+		return err
+	}
+
+	// Create the missing variables.
+	for _, v := range srcVariables {
+		if slices.ContainsFunc(destVariables, func(dv *gitlab.GroupVariable) bool {
+			return dv.Key == v.Key && dv.EnvironmentScope == v.EnvironmentScope
+		}) {
+			continue
+		}
+		_, _, err = m.dest.GroupVariables.CreateVariable(destPath,
+			&gitlab.CreateGroupVariableOptions{
+				Key:              &v.Key,
+				Value:            &v.Value,
+				Description:      &v.Description,
+				EnvironmentScope: &v.EnvironmentScope,
+				Masked:           &v.Masked,
+				Protected:        &v.Protected,
+				Raw:              &v.Raw,
+				VariableType:     &v.VariableType,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			return gitlab_util.ClassifyError(err)
+		}
+	}
+
+	return nil
+}
+
+// copyProjectVariables copies the CI/CD variables of the project.
+func (m *groupMigrator) copyProjectVariables(
+	ctx context.Context,
+	srcPath string,
+	destPath string,
+) error {
+
+	// Get the variables on both instances.
+	srcVariables, err := listProjectVariables(ctx, m.src, srcPath)
+	if err != nil {
+		return err
+	}
+	destVariables, err := listProjectVariables(ctx, m.dest, destPath)
+	if err != nil {
+		return err
+	}
+
+	// Create the missing variables.
+	for _, v := range srcVariables {
+		if slices.ContainsFunc(destVariables, func(dv *gitlab.ProjectVariable) bool {
+			return dv.Key == v.Key && dv.EnvironmentScope == v.EnvironmentScope
+		}) {
+			continue
+		}
+		_, _, err = m.dest.ProjectVariables.CreateVariable(destPath,
+			&gitlab.CreateProjectVariableOptions{
+				Key:              &v.Key,
+				Value:            &v.Value,
+				Description:      &v.Description,
+				EnvironmentScope: &v.EnvironmentScope,
+				Masked:           &v.Masked,
+				Protected:        &v.Protected,
+				Raw:              &v.Raw,
+				VariableType:     &v.VariableType,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			return gitlab_util.ClassifyError(err)
+		}
+	}
+
+	return nil
+}
+
+// copyGroupLabels copies the labels defined directly on the group.
+func (m *groupMigrator) copyGroupLabels(
+	ctx context.Context,
+	srcPath string,
+	destPath string,
+) error {
+
+	// Get the labels on both instances.
+	srcLabels, err := listGroupLabels(ctx, m.src, srcPath)
+	if err != nil {
+		return err
+	}
+	destLabels, err := listGroupLabels(ctx, m.dest, destPath)
+	if err != nil {
+		return err
+	}
+
+	// Create the missing labels.
+	for _, l := range srcLabels {
+		if slices.ContainsFunc(destLabels, func(dl *gitlab.GroupLabel) bool {
+			return dl.Name == l.Name
+		}) {
+			continue
+		}
+		_, _, err = m.dest.GroupLabels.CreateGroupLabel(destPath,
+			&gitlab.CreateGroupLabelOptions{
+				Name:        &l.Name,
+				Color:       &l.Color,
+				Description: &l.Description,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			return gitlab_util.ClassifyError(err)
+		}
+	}
+
+	return nil
+}
+
+// copyApprovalRules copies the regular approval rules of the project.
+// Approvers are matched by username, and approver groups are mapped
+// to the corresponding migrated group if the group is part of the
+// migration or to the group with the same full path otherwise.
+func (m *groupMigrator) copyApprovalRules(
+	ctx context.Context,
+	srcPath string,
+	destPath string,
+) error {
+
+	// Get the rules on both instances.
+	srcRules, err := listApprovalRules(ctx, m.src, srcPath)
+	if err != nil {
+		return err
+	}
+	destRules, err := listApprovalRules(ctx, m.dest, destPath)
+	if err != nil {
+		return err
+	}
+
+	// Create the missing rules.
+	for _, rule := range srcRules {
+		if rule.RuleType != "regular" {
+			continue
+		}
+		if slices.ContainsFunc(destRules, func(dr *gitlab.ProjectApprovalRule) bool {
+			return dr.Name == rule.Name
+		}) {
+			continue
+		}
+
+		// Map the approvers.
+		userIDs := []int{}
+		for _, u := range rule.Users {
+			id, err := m.destUserID(ctx, u.Username)
+			if err != nil {
+				return err
+			}
+			if id == 0 {
+				m.warn("approver %q of rule %q not found on destination",
+					u.Username, rule.Name)
+				continue
+			}
+			userIDs = append(userIDs, id)
+		}
+		groupIDs := []int{}
+		for _, g := range rule.Groups {
+			path := mapGroupPath(m.srcRoot, m.state.DestParent, g.FullPath)
+			dg, _, err := m.dest.Groups.GetGroup(path, nil, gitlab.WithContext(ctx))
+			if err != nil {
+				m.warn("approver group %q of rule %q not found on destination",
+					path, rule.Name)
+				continue
+			}
+			groupIDs = append(groupIDs, dg.ID)
+		}
+
+		// Create the rule.
+		_, _, err = m.dest.Projects.CreateProjectApprovalRule(destPath,
+			&gitlab.CreateProjectLevelRuleOptions{
+				Name:              &rule.Name,
+				ApprovalsRequired: &rule.ApprovalsRequired,
+				UserIDs:           &userIDs,
+				GroupIDs:          &groupIDs,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			return gitlab_util.ClassifyError(err)
+		}
+	}
+
+	return nil
+}
+
+// mapGroupPath returns the full path on the destination instance of
+// the group having the full path srcPath on the source instance when
+// the group srcRoot is migrated to the group destParent.  Groups
+// outside srcRoot keep their full path.
+func mapGroupPath(srcRoot string, destParent string, srcPath string) string {
+	if srcPath != srcRoot && !strings.HasPrefix(srcPath, srcRoot+"/") {
+		return srcPath
+	}
+	base := srcRoot[strings.LastIndex(srcRoot, "/")+1:]
+	return joinPath(destParent, base+srcPath[len(srcRoot):])
+}
+
+////////////////////////////////////////////////////////////////////////
+// Listing
+////////////////////////////////////////////////////////////////////////
+
+// listSubgroups returns the direct subgroups of the group.
+func listSubgroups(
+	ctx context.Context,
+	client *gitlab.Client,
+	group string,
+) ([]*gitlab.Group, error) {
+	return gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.Group, *gitlab.Response, error) {
+			opts := gitlab.ListSubGroupsOptions{}
+			opts.Page = page
+			gs, resp, err := client.Groups.ListSubGroups(
+				group, &opts, gitlab.WithContext(ctx))
+			return gs, resp, gitlab_util.ClassifyError(err)
+		})
+}
+
+// listGroupMembers returns the direct members of the group.
+func listGroupMembers(
+	ctx context.Context,
+	client *gitlab.Client,
+	group string,
+) ([]*gitlab.GroupMember, error) {
+	return gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.GroupMember, *gitlab.Response, error) {
+			opts := gitlab.ListGroupMembersOptions{}
+			opts.Page = page
+			members, resp, err := client.Groups.ListGroupMembers(
+				group, &opts, gitlab.WithContext(ctx))
+			return members, resp, gitlab_util.ClassifyError(err)
+		})
+}
+
+// listProjectMembers returns the direct members of the project.
+func listProjectMembers(
+	ctx context.Context,
+	client *gitlab.Client,
+	project string,
+) ([]*gitlab.ProjectMember, error) {
+	return gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.ProjectMember, *gitlab.Response, error) {
+			opts := gitlab.ListProjectMembersOptions{}
+			opts.Page = page
+			members, resp, err := client.ProjectMembers.ListProjectMembers(
+				project, &opts, gitlab.WithContext(ctx))
+			return members, resp, gitlab_util.ClassifyError(err)
+		})
+}
+
+// listGroupVariables returns the CI/CD variables of the group.
+func listGroupVariables(
+	ctx context.Context,
+	client *gitlab.Client,
+	group string,
+) ([]*gitlab.GroupVariable, error) {
+	return gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.GroupVariable, *gitlab.Response, error) {
+			opts := gitlab.ListGroupVariablesOptions{}
+			opts.Page = page
+			vs, resp, err := client.GroupVariables.ListVariables(
+				group, &opts, gitlab.WithContext(ctx))
+			return vs, resp, gitlab_util.ClassifyError(err)
+		})
+}
+
+// listProjectVariables returns the CI/CD variables of the project.
+func listProjectVariables(
+	ctx context.Context,
+	client *gitlab.Client,
+	project string,
+) ([]*gitlab.ProjectVariable, error) {
+	return gitlab_util.GetAllProjectVariables(ctx, client.ProjectVariables, project)
+}
+
+// listGroupLabels returns the labels defined directly on the group.
+func listGroupLabels(
+	ctx context.Context,
+	client *gitlab.Client,
+	group string,
+) ([]*gitlab.GroupLabel, error) {
+	return gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.GroupLabel, *gitlab.Response, error) {
+			opts := gitlab.ListGroupLabelsOptions{
+				IncludeAncestorGroups: gitlab.Ptr(false),
+			}
+			opts.Page = page
+			ls, resp, err := client.GroupLabels.ListGroupLabels(
+				group, &opts, gitlab.WithContext(ctx))
+			return ls, resp, gitlab_util.ClassifyError(err)
+		})
+}
+
+// listApprovalRules returns the approval rules of the project.
+func listApprovalRules(
+	ctx context.Context,
+	client *gitlab.Client,
+	project string,
+) ([]*gitlab.ProjectApprovalRule, error) {
+	return gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.ProjectApprovalRule, *gitlab.Response, error) {
+			opts := gitlab.GetProjectApprovalRulesListsOptions{}
+			opts.Page = page
+			rules, resp, err := client.Projects.GetProjectApprovalRules(
+				project, &opts, gitlab.WithContext(ctx))
+			return rules, resp, gitlab_util.ClassifyError(err)
+		})
+}
+
+// listProtectedBranches returns the protected branches of the
+// project.
+func listProtectedBranches(
+	ctx context.Context,
+	client *gitlab.Client,
+	project string,
+) ([]*gitlab.ProtectedBranch, error) {
+	return gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.ProtectedBranch, *gitlab.Response, error) {
+			opts := gitlab.ListProtectedBranchesOptions{}
+			opts.Page = page
+			bs, resp, err := client.ProtectedBranches.ListProtectedBranches(
+				project, &opts, gitlab.WithContext(ctx))
+			return bs, resp, gitlab_util.ClassifyError(err)
+		})
+}
@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestMigrateGroupIntegration(t *testing.T) {
+	src := newFakeServer(t)
+	src.AddGroupMember("foo", "aberns", gitlab.MaintainerPermissions)
+	src.AddGroupMember("foo", "bcrocket", gitlab.DeveloperPermissions)
+	src.AddGroupVariable("foo", "TOKEN", "secret")
+	src.AddGroupLabel("foo/bar", "bug", "#ff0000")
+	src.AddProjectMember("foo/alpha", "aberns", gitlab.DeveloperPermissions)
+	src.AddProjectVariable("foo/bar/delta", &gitlab.ProjectVariable{Key: "MODE", Value: "ci"})
+	src.AddApprovalRule("foo/alpha", "reviewers", 1, "aberns", "bcrocket")
+	dest := fake_gitlab.NewServer(t)
+	dest.AddGroup("new")
+	dest.AddUser("aberns", "Alice Berns", "aberns@example.com")
+	session := NewSessionWithClient(src.Client(t))
+	stateFileName := filepath.Join(t.TempDir(), "state.xml")
+	args := []string{"--group", "foo", "--dest-parent", "new", "--state-file", stateFileName}
+
+	// migrate runs the migration and returns the names of the steps
+	// that were done.
+	migrate := func(args []string) []string {
+		cmd := NewMigrateGroupCommand("group", &MigrateGroupOptions{}, session)
+		cmd.destSession = NewSessionWithClient(dest.Client(t))
+		_, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("migrate group %v: unexpected error: %v", args, err)
+		}
+		var steps []string
+		for _, item := range result.Succeeded() {
+			steps = append(steps, item.Name)
+		}
+		return steps
+	}
+
+	// A dry run does not change anything.
+	migrate(append(args, "--dry-run"))
+	if actual := dest.Projects(); len(actual) != 0 {
+		t.Errorf("migrate group --dry-run: expected=%v  actual=%v", nil, actual)
+	}
+
+	// Migrate the group.
+	steps := migrate(args)
+	if len(steps) != 28 {
+		t.Errorf("migrate group steps: expected=%d  actual=%d", 28, len(steps))
+	}
+	expected := []string{"new/foo/alpha", "new/foo/bar/delta",
+		"new/foo/bar/test-epsilon", "new/foo/beta", "new/foo/test-gamma"}
+	actual := dest.Projects()
+	slices.Sort(actual)
+	if !slices.Equal(actual, expected) {
+		t.Errorf("migrate group projects: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Verify the settings were copied.  Only users that exist on the
+	// destination are copied.
+	type Data []struct {
+		name     string
+		expected []string
+		actual   []string
+	}
+	var approvers []string
+	for _, rule := range dest.ApprovalRules("new/foo/alpha") {
+		approvers = append(approvers, rule.Name)
+		for _, u := range rule.Users {
+			approvers = append(approvers, u.Username)
+		}
+	}
+	data := Data{
+		{"group members", []string{"aberns"}, dest.Members("group", "new/foo")},
+		{"project members", []string{"aberns"}, dest.Members("project", "new/foo/alpha")},
+		{"group variables", []string{"TOKEN"}, dest.Variables("group", "new/foo")},
+		{"project variables", []string{"MODE"}, dest.Variables("project", "new/foo/bar/delta")},
+		{"group labels", []string{"bug"}, dest.Labels("new/foo/bar")},
+		{"approval rules", []string{"reviewers", "aberns"}, approvers},
+	}
+	for _, d := range data {
+		if !slices.Equal(d.actual, d.expected) {
+			t.Errorf("migrate group %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+
+	// Running the migration again skips the completed steps.
+	if steps := migrate(args); len(steps) != 0 {
+		t.Errorf("migrate group again: expected=%v  actual=%v", nil, steps)
+	}
+}
+
+func TestMigrateGroupExistingProjectIntegration(t *testing.T) {
+	saved := migratePollInterval
+	migratePollInterval = time.Millisecond
+	defer func() { migratePollInterval = saved }()
+
+	// Projects that already exist on the destination are only skipped
+	// if they were not imported or their import finished.  Imports
+	// that are still running are waited on until the timeout.
+	type Data []struct {
+		status   string
+		expected string
+	}
+	data := Data{
+		{"finished", ""},
+		{"none", ""},
+		{"started", `import "new/foo/alpha": timed out after 20ms with status "started"`},
+		{"failed", `import "new/foo/alpha": status is "failed"`},
+	}
+	for _, d := range data {
+		src := newFakeServer(t)
+		dest := fake_gitlab.NewServer(t)
+		dest.AddGroup("new")
+		dest.AddGroup("new/foo")
+		dest.AddProject("new/foo", "alpha")
+		dest.SetImportStatus("new/foo/alpha", d.status)
+		cmd := NewMigrateGroupCommand("group", &MigrateGroupOptions{},
+			NewSessionWithClient(src.Client(t)))
+		cmd.destSession = NewSessionWithClient(dest.Client(t))
+		_, _, err := runCommand(t, cmd, []string{
+			"--group", "foo", "--dest-parent", "new", "--timeout", "20ms",
+			"--state-file", filepath.Join(t.TempDir(), "state.xml")})
+		actual := ""
+		if err != nil {
+			actual = err.Error()
+		}
+		if d.expected == "" && err != nil ||
+			d.expected != "" && !strings.Contains(actual, d.expected) {
+			t.Errorf("migrate group with %q import: expected=%q  actual=%q",
+				d.status, d.expected, actual)
+		}
+	}
+}
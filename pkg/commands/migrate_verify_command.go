@@ -0,0 +1,660 @@
+// This file provides the implementation for the "migrate verify"
+// command which compares a group on the source instance with the
+// migrated group on the destination instance and reports any
+// discrepancies.
+
+package commands
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/slice_util"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MigrateVerifyOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MigrateVerifyOptions are the options needed by this command.
+type MigrateVerifyOptions struct {
+
+	// DestAuthFileName is the name of the file that holds the
+	// authentication information for the destination instance.
+	// Defaults to "dest-auth.xml".
+	DestAuthFileName string `xml:"dest-auth-file-name"`
+
+	// DestBaseURL is the URL of the destination instance.  Defaults
+	// to "".
+	DestBaseURL string `xml:"dest-base-url"`
+
+	// DestParent is the full path of the group on the destination
+	// instance under which the source group was created.  Defaults
+	// to "" which means the group was created at the top level.
+	DestParent string `xml:"dest-parent"`
+
+	// Group is the full path of the migrated group on the source
+	// instance.  Defaults to "".
+	Group string `xml:"group"`
+
+	// OutputFileName is the name of the XML file to which the report
+	// is written.  If empty, no report is written, but there will
+	// still be logging to the console.  If set to "-", the report
+	// will be written to os.Stdout.
+	OutputFileName string `xml:"output-file-name"`
+}
+
+// Initialize initializes this MigrateVerifyOptions instance so it can
+// be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *MigrateVerifyOptions) Initialize(flags *flag.FlagSet) {
+
+	// --dest-auth
+	if opts.DestAuthFileName == "" {
+		opts.DestAuthFileName = "dest-auth.xml"
+	}
+	flags.StringVar(&opts.DestAuthFileName, "dest-auth", opts.DestAuthFileName,
+		i18n.T("XML file with authentication information for the destination"))
+
+	// --dest-base-url
+	flags.StringVar(&opts.DestBaseURL, "dest-base-url", opts.DestBaseURL,
+		i18n.T("URL of the destination Gitlab instance"))
+
+	// --dest-parent
+	flags.StringVar(&opts.DestParent, "dest-parent", opts.DestParent,
+		i18n.T("full path of the destination group under which the group was created"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the migrated source group"))
+
+	// -o
+	flags.StringVar(&opts.OutputFileName, "o", opts.OutputFileName,
+		i18n.T("XML file to which the report is written (\"-\" for stdout)"))
+
+	// --output
+	flags.StringVar(&opts.OutputFileName, "output", opts.OutputFileName,
+		i18n.T("XML file to which the report is written (\"-\" for stdout)"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MigrateVerifyCommand
+////////////////////////////////////////////////////////////////////////
+
+// MigrateVerifyCommand implements the "migrate verify" command which
+// compares a group on the source instance with the migrated group on
+// the destination instance.
+type MigrateVerifyCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MigrateVerifyOptions]
+
+	// destSession is the session for the destination instance.  It
+	// is created from the options when the command is run unless it
+	// has already been set.
+	destSession *Session
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MigrateVerifyCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] migrate verify [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Compares a migrated group with the original.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    The project counts, default branches, protected\n")
+	i18n.Fprintf(out, "    branches, members, variable keys, and approval\n")
+	i18n.Fprintf(out, "    rules are compared, and any discrepancies are\n")
+	i18n.Fprintf(out, "    reported.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Verify Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMigrateVerifyCommand returns a new, initialized
+// MigrateVerifyCommand instance.
+func NewMigrateVerifyCommand(
+	name string,
+	opts *MigrateVerifyOptions,
+	session *Session,
+) *MigrateVerifyCommand {
+
+	// Create the new command.
+	cmd := &MigrateVerifyCommand{
+		GitlabCommand: GitlabCommand[MigrateVerifyOptions]{
+			BasicCommand: BasicCommand[MigrateVerifyOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *MigrateVerifyCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	if cmd.destSession == nil && cmd.options.DestBaseURL == "" {
+		return result, i18n.Errorf("%w: dest-base-url not set", ErrInvalidOption)
+	}
+
+	// Connect to the source instance.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to the destination instance.
+	if cmd.destSession == nil {
+		cmd.destSession = NewSession(&GlobalOptions{
+			AuthFileName: cmd.options.DestAuthFileName,
+			BaseURL:      cmd.options.DestBaseURL,
+		})
+
+		// The token in the environment (if any) is for the source
+		// instance, so the destination must use its own file.
+		cmd.destSession.ignoreAuthEnv = true
+	}
+	dest, err := cmd.destSession.Client()
+	if err != nil {
+		return result, err
+	}
+
+	// Verify the migration.
+	err = VerifyMigration(ctx, result, cmd.client, dest,
+		cmd.options.Group, cmd.options.DestParent)
+	if err != nil && !errors.Is(err, ErrVerificationFailed) {
+		return result, err
+	}
+
+	// Save the report to the output file.
+	if cmd.options.OutputFileName != "" {
+		werr := WriteVerificationReport(cmd.options.OutputFileName,
+			cmd.options.Group, cmd.options.DestParent, result)
+		if werr != nil {
+			return result, werr
+		}
+	}
+
+	return result, err
+}
+
+////////////////////////////////////////////////////////////////////////
+// Verification
+////////////////////////////////////////////////////////////////////////
+
+// VerificationCheck is the outcome of comparing one aspect of a group
+// or project.  It is the value of each item in the Result returned by
+// VerifyMigration().
+type VerificationCheck struct {
+
+	// Name identifies the check (e.g., "project-members:foo/bar").
+	Name string `xml:"name,attr"`
+
+	// OK is whether the source and destination match.
+	OK bool `xml:"ok,attr"`
+
+	// Missing holds the items on the source instance that are
+	// missing on the destination instance.
+	Missing []string `xml:"missing"`
+
+	// Extra holds the items on the destination instance that are
+	// not on the source instance.
+	Extra []string `xml:"extra"`
+}
+
+// VerificationReport is the report written by "migrate verify".
+type VerificationReport struct {
+	XMLName     xml.Name             `xml:"verification-report"`
+	SourceGroup string               `xml:"source-group"`
+	DestParent  string               `xml:"dest-parent"`
+	Checks      []*VerificationCheck `xml:"checks>check"`
+}
+
+// WriteVerificationReport writes the checks in the result to the XML
+// file.  If fname is "-", the report is written to os.Stdout.
+func WriteVerificationReport(
+	fname string,
+	sourceGroup string,
+	destParent string,
+	result *Result,
+) error {
+	report := VerificationReport{
+		SourceGroup: sourceGroup,
+		DestParent:  destParent,
+	}
+	for _, item := range result.Items {
+		if check, ok := item.Value.(*VerificationCheck); ok {
+			report.Checks = append(report.Checks, check)
+		}
+	}
+
+	// Open the output file.
+	out := os.Stdout
+	if fname != "-" {
+		f, err := os.Create(fname)
+		if err != nil {
+			return fmt.Errorf("WriteVerificationReport: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Write the report.
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	err := encoder.Encode(&report)
+	if err == nil {
+		_, err = fmt.Fprintf(out, "\n")
+	}
+	if err != nil {
+		return fmt.Errorf("WriteVerificationReport: %w", err)
+	}
+	return nil
+}
+
+// migrationVerifier holds the state needed while verifying a
+// migration.
+type migrationVerifier struct {
+	result     *Result
+	src        *gitlab.Client
+	dest       *gitlab.Client
+	srcRoot    string
+	destParent string
+}
+
+// VerifyMigration compares the source group on the src instance
+// including its subgroups and projects with the group that it was
+// migrated to under destParent on the dest instance.  The project
+// counts, default branches, protected branches, direct members and
+// their access levels, CI/CD variable keys, and regular approval
+// rules are compared.  Each comparison is recorded in the result as
+// a *VerificationCheck.  If any comparison finds a discrepancy, an
+// error wrapping ErrVerificationFailed is returned after all the
+// comparisons have been made.
+func VerifyMigration(
+	ctx context.Context,
+	result *Result,
+	src *gitlab.Client,
+	dest *gitlab.Client,
+	sourceGroup string,
+	destParent string,
+) error {
+
+	// Find the source group.
+	g, err := gitlab_util.FindExactGroup(ctx, src.Groups, sourceGroup)
+	if err != nil {
+		return fmt.Errorf("VerifyMigration: %w", err)
+	}
+
+	// Verify the group.
+	v := &migrationVerifier{
+		result:     result,
+		src:        src,
+		dest:       dest,
+		srcRoot:    g.FullPath,
+		destParent: destParent,
+	}
+	err = v.verifyGroup(ctx, g, joinPath(destParent, g.Path))
+	if err != nil {
+		return fmt.Errorf("VerifyMigration: %w", err)
+	}
+
+	// Report the outcome.
+	failed := len(result.Failed())
+	i18n.Printf("Checked %d item(s), found %d discrepancy(ies).\n",
+		result.Processed(), failed)
+	if failed > 0 {
+		return i18n.Errorf("%w: %d discrepancy(ies) found",
+			ErrVerificationFailed, failed)
+	}
+
+	return nil
+}
+
+// compare compares the items from the source and destination
+// instances and records the outcome in the result.
+func (v *migrationVerifier) compare(
+	name string,
+	description string,
+	srcItems []string,
+	destItems []string,
+) {
+	check := &VerificationCheck{
+		Name:    name,
+		Missing: slice_util.SubtractSlice(srcItems, destItems),
+		Extra:   slice_util.SubtractSlice(destItems, srcItems),
+	}
+	slices.Sort(check.Missing)
+	slices.Sort(check.Extra)
+	check.OK = len(check.Missing) == 0 && len(check.Extra) == 0
+	logging.Printf("- Comparing %s ... ", description)
+	if check.OK {
+		logging.Printf("OK.\n")
+		v.result.Succeed(name, check)
+		return
+	}
+	logging.Printf("Mismatch.\n")
+	if len(check.Missing) > 0 {
+		logging.Printf("  Missing: %q\n", check.Missing)
+	}
+	if len(check.Extra) > 0 {
+		logging.Printf("  Extra: %q\n", check.Extra)
+	}
+	v.result.Fail(name, check, ErrVerificationFailed)
+}
+
+// exists checks whether the group or project exists on the
+// destination instance and records the outcome in the result.
+func (v *migrationVerifier) exists(
+	name string,
+	description string,
+	destPath string,
+	err error,
+) (bool, error) {
+	if err != nil {
+		err = gitlab_util.ClassifyError(err)
+		if !errors.Is(err, gitlab_util.ErrNotFound) {
+			return false, err
+		}
+		v.compare(name, description, []string{destPath}, nil)
+		return false, nil
+	}
+	v.compare(name, description, []string{destPath}, []string{destPath})
+	return true, nil
+}
+
+// verifyGroup compares the group and everything in it with the
+// destination group.
+func (v *migrationVerifier) verifyGroup(
+	ctx context.Context,
+	g *gitlab.Group,
+	destPath string,
+) error {
+
+	// Make sure the group exists.
+	_, _, err := v.dest.Groups.GetGroup(destPath, nil, gitlab.WithContext(ctx))
+	ok, err := v.exists("group:"+g.FullPath,
+		i18n.Sprintf("group: %q", destPath), destPath, err)
+	if err != nil || !ok {
+		return err
+	}
+
+	// Compare the members.
+	srcMembers, err := listGroupMembers(ctx, v.src, g.FullPath)
+	if err != nil {
+		return err
+	}
+	destMembers, err := listGroupMembers(ctx, v.dest, destPath)
+	if err != nil {
+		return err
+	}
+	v.compare("group-members:"+g.FullPath,
+		i18n.Sprintf("members of group: %q", destPath),
+		groupMemberStrings(srcMembers), groupMemberStrings(destMembers))
+
+	// Compare the variables.
+	srcVariables, err := listGroupVariables(ctx, v.src, g.FullPath)
+	if err != nil {
+		return err
+	}
+	destVariables, err := listGroupVariables(ctx, v.dest, destPath)
+	if err != nil {
+		return err
+	}
+	v.compare("group-variables:"+g.FullPath,
+		i18n.Sprintf("variables of group: %q", destPath),
+		groupVariableStrings(srcVariables), groupVariableStrings(destVariables))
+
+	// Compare the projects.
+	srcProjects, err := gitlab_util.GetAllProjects(
+		ctx, v.src.Groups, g.FullPath, "", false)
+	if err != nil {
+		return err
+	}
+	destProjects, err := gitlab_util.GetAllProjects(
+		ctx, v.dest.Groups, destPath, "", false)
+	if err != nil {
+		return err
+	}
+	v.compare("project-count:"+g.FullPath,
+		i18n.Sprintf("project count of group: %q", destPath),
+		[]string{strconv.Itoa(len(srcProjects))},
+		[]string{strconv.Itoa(len(destProjects))})
+	for _, p := range srcProjects {
+		err = v.verifyProject(ctx, p, joinPath(destPath, p.Path))
+		if err != nil {
+			return err
+		}
+	}
+
+	// Compare the subgroups.
+	subgroups, err := listSubgroups(ctx, v.src, g.FullPath)
+	if err != nil {
+		return err
+	}
+	for _, sg := range subgroups {
+		err = v.verifyGroup(ctx, sg, joinPath(destPath, sg.Path))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyProject compares the project with the destination project.
+func (v *migrationVerifier) verifyProject(
+	ctx context.Context,
+	p *gitlab.Project,
+	destPath string,
+) error {
+	name := p.PathWithNamespace
+
+	// Make sure the project exists.
+	dp, _, err := v.dest.Projects.GetProject(destPath, nil, gitlab.WithContext(ctx))
+	ok, err := v.exists("project:"+name,
+		i18n.Sprintf("project: %q", destPath), destPath, err)
+	if err != nil || !ok {
+		return err
+	}
+
+	// Compare the default branches.
+	v.compare("default-branch:"+name,
+		i18n.Sprintf("default branch of project: %q", destPath),
+		[]string{p.DefaultBranch}, []string{dp.DefaultBranch})
+
+	// Compare the protected branches.
+	srcBranches, err := listProtectedBranches(ctx, v.src, name)
+	if err != nil {
+		return err
+	}
+	destBranches, err := listProtectedBranches(ctx, v.dest, destPath)
+	if err != nil {
+		return err
+	}
+	v.compare("protected-branches:"+name,
+		i18n.Sprintf("protected branches of project: %q", destPath),
+		protectedBranchStrings(srcBranches), protectedBranchStrings(destBranches))
+
+	// Compare the members.
+	srcMembers, err := listProjectMembers(ctx, v.src, name)
+	if err != nil {
+		return err
+	}
+	destMembers, err := listProjectMembers(ctx, v.dest, destPath)
+	if err != nil {
+		return err
+	}
+	v.compare("project-members:"+name,
+		i18n.Sprintf("members of project: %q", destPath),
+		projectMemberStrings(srcMembers), projectMemberStrings(destMembers))
+
+	// Compare the variables.
+	srcVariables, err := listProjectVariables(ctx, v.src, name)
+	if err != nil {
+		return err
+	}
+	destVariables, err := listProjectVariables(ctx, v.dest, destPath)
+	if err != nil {
+		return err
+	}
+	v.compare("project-variables:"+name,
+		i18n.Sprintf("variables of project: %q", destPath),
+		projectVariableStrings(srcVariables), projectVariableStrings(destVariables))
+
+	// Compare the approval rules.  Approver groups that were part of
+	// the migration are mapped to their destination paths.
+	srcRules, err := listApprovalRules(ctx, v.src, name)
+	if err != nil {
+		return err
+	}
+	destRules, err := listApprovalRules(ctx, v.dest, destPath)
+	if err != nil {
+		return err
+	}
+	v.compare("project-approval-rules:"+name,
+		i18n.Sprintf("approval rules of project: %q", destPath),
+		approvalRuleStrings(srcRules, func(path string) string {
+			return mapGroupPath(v.srcRoot, v.destParent, path)
+		}),
+		approvalRuleStrings(destRules, func(path string) string {
+			return path
+		}))
+
+	return nil
+}
+
+// groupMemberStrings returns "username:access_level" for each member.
+func groupMemberStrings(members []*gitlab.GroupMember) []string {
+	var result []string
+	for _, m := range members {
+		result = append(result, fmt.Sprintf("%s:%d", m.Username, m.AccessLevel))
+	}
+	return result
+}
+
+// projectMemberStrings returns "username:access_level" for each
+// member.
+func projectMemberStrings(members []*gitlab.ProjectMember) []string {
+	var result []string
+	for _, m := range members {
+		result = append(result, fmt.Sprintf("%s:%d", m.Username, m.AccessLevel))
+	}
+	return result
+}
+
+// groupVariableStrings returns "key@environment_scope" for each
+// variable.  The values are deliberately not compared so they are
+// never printed.
+func groupVariableStrings(variables []*gitlab.GroupVariable) []string {
+	var result []string
+	for _, v := range variables {
+		result = append(result, v.Key+"@"+v.EnvironmentScope)
+	}
+	return result
+}
+
+// projectVariableStrings returns "key@environment_scope" for each
+// variable.  The values are deliberately not compared so they are
+// never printed.
+func projectVariableStrings(variables []*gitlab.ProjectVariable) []string {
+	var result []string
+	for _, v := range variables {
+		result = append(result, v.Key+"@"+v.EnvironmentScope)
+	}
+	return result
+}
+
+// protectedBranchStrings returns the name of each protected branch.
+func protectedBranchStrings(branches []*gitlab.ProtectedBranch) []string {
+	var result []string
+	for _, b := range branches {
+		result = append(result, b.Name)
+	}
+	return result
+}
+
+// approvalRuleStrings returns "name:approvals:users:groups" for each
+// regular approval rule where the users and groups are sorted and
+// comma separated.  The full path of each group is passed through
+// mapPath.
+func approvalRuleStrings(
+	rules []*gitlab.ProjectApprovalRule,
+	mapPath func(path string) string,
+) []string {
+	var result []string
+	for _, rule := range rules {
+		if rule.RuleType != "regular" {
+			continue
+		}
+		var users []string
+		for _, u := range rule.Users {
+			users = append(users, u.Username)
+		}
+		slices.Sort(users)
+		var groups []string
+		for _, g := range rule.Groups {
+			groups = append(groups, mapPath(g.FullPath))
+		}
+		slices.Sort(groups)
+		result = append(result, fmt.Sprintf("%s:%d:%s:%s",
+			rule.Name, rule.ApprovalsRequired,
+			strings.Join(users, ","), strings.Join(groups, ",")))
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestMigrateVerifyIntegration(t *testing.T) {
+	src := newFakeServer(t)
+	src.AddGroupMember("foo", "aberns", gitlab.MaintainerPermissions)
+	src.AddGroupVariable("foo/bar", "TOKEN", "secret")
+	src.AddApprovalRule("foo/alpha", "reviewers", 1, "aberns")
+	src.AddProtectedBranch("foo/alpha", "main")
+	dest := fake_gitlab.NewServer(t)
+	dest.AddUser("aberns", "Alice Berns", "aberns@example.com")
+	session := NewSessionWithClient(src.Client(t))
+	dir := t.TempDir()
+
+	// Migrate the group.  Protected branches are not copied by the
+	// fake import.
+	var err error
+	captureStdout(t, func() {
+		state := &MigrationState{SourceGroup: "foo"}
+		err = MigrateGroup(context.Background(), NewResult(),
+			src.Client(t), dest.Client(t), state,
+			filepath.Join(dir, "state.xml"), time.Minute, false)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// verify runs the verification and returns the names of the
+	// failed checks.
+	reportFileName := filepath.Join(dir, "report.xml")
+	verify := func() ([]string, error) {
+		cmd := NewMigrateVerifyCommand("verify", &MigrateVerifyOptions{}, session)
+		cmd.destSession = NewSessionWithClient(dest.Client(t))
+		var err error
+		var result *Result
+		captureStdout(t, func() {
+			result, err = cmd.Run(context.Background(),
+				[]string{"--group", "foo", "--output", reportFileName})
+		})
+		var failed []string
+		for _, item := range result.Failed() {
+			failed = append(failed, item.Name)
+		}
+		return failed, err
+	}
+
+	// The missing protected branch is reported.
+	failed, err := verify()
+	if !errors.Is(err, ErrVerificationFailed) {
+		t.Errorf("migrate verify: expected=%v  actual=%v", ErrVerificationFailed, err)
+	}
+	expected := []string{"protected-branches:foo/alpha"}
+	if !slices.Equal(failed, expected) {
+		t.Errorf("migrate verify: expected=%v  actual=%v", expected, failed)
+	}
+
+	// After fixing the discrepancy, verification succeeds.
+	dest.AddProtectedBranch("foo/alpha", "main")
+	failed, err = verify()
+	if err != nil || len(failed) != 0 {
+		t.Errorf("migrate verify: expected=%v  actual=%v %v", nil, failed, err)
+	}
+
+	// Verify the report was written.
+	data, err := os.ReadFile(reportFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		`<source-group>foo</source-group>`,
+		`<check name="project-approval-rules:foo/alpha" ok="true">`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("migrate verify report: expected=%q  actual=%q",
+				expected, data)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// This file provides the state file that makes "migrate group"
+// resumable.  Each step of the migration that completes successfully
+// is recorded in the state file so that running the migration again
+// after a failure skips the steps that were already done.
+
+package commands
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// MigrationState is the state of a group migration as persisted in
+// the state file.
+type MigrationState struct {
+	XMLName xml.Name `xml:"migration-state"`
+
+	// SourceGroup is the full path of the group being migrated on
+	// the source instance.
+	SourceGroup string `xml:"source-group"`
+
+	// DestParent is the full path of the group on the destination
+	// instance under which the source group is created.  An empty
+	// string means the group is created at the top level.
+	DestParent string `xml:"dest-parent"`
+
+	// Completed holds the keys of the steps that have completed.
+	Completed []string `xml:"completed>step"`
+}
+
+// LoadMigrationState loads the migration state from the file.  If the
+// file does not exist, a new state for the source group and
+// destination parent is returned.  An error is returned if the file
+// holds the state of a different migration.
+func LoadMigrationState(
+	fileName string,
+	sourceGroup string,
+	destParent string,
+) (*MigrationState, error) {
+	state := &MigrationState{
+		SourceGroup: sourceGroup,
+		DestParent:  destParent,
+	}
+
+	// Read the state file if it exists.
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LoadMigrationState: %w", err)
+	}
+	err = xml.Unmarshal(data, state)
+	if err != nil {
+		return nil, fmt.Errorf("LoadMigrationState: %s: %w", fileName, err)
+	}
+
+	// Make sure the state is for the same migration.
+	if state.SourceGroup != sourceGroup || state.DestParent != destParent {
+		return nil, i18n.Errorf(
+			"%w: state file %q is for migrating %q to %q",
+			ErrInvalidOption, fileName, state.SourceGroup, state.DestParent)
+	}
+
+	return state, nil
+}
+
+// Save atomically writes the migration state to the file by first
+// writing a temporary file in the same directory and then renaming
+// it.
+func (state *MigrationState) Save(fileName string) error {
+	data, err := xml.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("MigrationState.Save: %w", err)
+	}
+	data = append(data, '\n')
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".*")
+	if err != nil {
+		return fmt.Errorf("MigrationState.Save: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("MigrationState.Save: %w", err)
+	}
+	err = os.Rename(tmp.Name(), fileName)
+	if err != nil {
+		return fmt.Errorf("MigrationState.Save: %w", err)
+	}
+	return nil
+}
+
+// IsDone returns whether the step has completed.
+func (state *MigrationState) IsDone(step string) bool {
+	return slices.Contains(state.Completed, step)
+}
+
+// MarkDone records that the step has completed.
+func (state *MigrationState) MarkDone(step string) {
+	if !state.IsDone(step) {
+		state.Completed = append(state.Completed, step)
+	}
+}
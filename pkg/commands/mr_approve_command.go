@@ -0,0 +1,193 @@
+// This file provides the implementation for the "mr approve" command
+// which approves the opened merge requests across the projects in a
+// group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MRApproveOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MRApproveOptions are the options needed by this command.
+type MRApproveOptions struct {
+
+	// Embed the options that control whether the remaining merge
+	// requests are approved after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the merge requests.
+	MRSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this MRApproveOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *MRApproveOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --target-branch, --test-expr, --title-expr
+	opts.MRSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MRApproveCommand
+////////////////////////////////////////////////////////////////////////
+
+// MRApproveCommand implements the "mr approve" command which approves
+// the opened merge requests across the projects in a group.
+type MRApproveCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MRApproveOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MRApproveCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] mr approve [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Approve the selected opened merge requests as the user\n")
+	i18n.Fprintf(out, "    in auth.xml.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Approve Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMRApproveCommand returns a new, initialized MRApproveCommand
+// instance.
+func NewMRApproveCommand(
+	name string,
+	opts *MRApproveOptions,
+	session *Session,
+) *MRApproveCommand {
+
+	// Create the new command.
+	cmd := &MRApproveCommand{
+		GitlabCommand: GitlabCommand[MRApproveOptions]{
+			BasicCommand: BasicCommand[MRApproveOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ApproveMergeRequest approves the merge request.  If the SHA of the
+// head of the merge request is known, Gitlab only approves the merge
+// request if nothing has been pushed to it since it was listed.
+func ApproveMergeRequest(
+	ctx context.Context,
+	s gitlab_util.MergeRequestApprover, /* was *gitlab.MergeRequestApprovalsService */
+	p *gitlab.Project,
+	mr *gitlab.MergeRequest,
+) error {
+	opts := &gitlab.ApproveMergeRequestOptions{}
+	if mr.SHA != "" {
+		opts.SHA = gitlab.Ptr(mr.SHA)
+	}
+	_, _, err := s.ApproveMergeRequest(p.ID, mr.IID, opts,
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("ApproveMergeRequest: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *MRApproveCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MRSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Approve the merge requests.
+	err = UpdateMergeRequests(
+		ctx, result, &cmd.options.MRSelectorOptions,
+		cmd.client.Groups, cmd.client.MergeRequests, i18n.T("Approving"),
+		func(p *gitlab.Project, mr *gitlab.MergeRequest) error {
+			return ApproveMergeRequest(ctx, cmd.client.MergeRequestApprovals, p, mr)
+		},
+		cmd.options.KeepGoing,
+		cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not approve %d merge request(s)")
+	return result, err
+}
@@ -0,0 +1,190 @@
+// This file provides the implementation for the "mr close" command
+// which closes the opened merge requests across the projects in a
+// group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MRCloseOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MRCloseOptions are the options needed by this command.
+type MRCloseOptions struct {
+
+	// Embed the options that control whether the remaining merge
+	// requests are closed after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the merge requests.
+	MRSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this MRCloseOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *MRCloseOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --target-branch, --test-expr, --title-expr
+	opts.MRSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MRCloseCommand
+////////////////////////////////////////////////////////////////////////
+
+// MRCloseCommand implements the "mr close" command which closes the
+// opened merge requests across the projects in a group.
+type MRCloseCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MRCloseOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MRCloseCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] mr close [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Close the selected opened merge requests without merging\n")
+	i18n.Fprintf(out, "    them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Close Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMRCloseCommand returns a new, initialized MRCloseCommand
+// instance.
+func NewMRCloseCommand(
+	name string,
+	opts *MRCloseOptions,
+	session *Session,
+) *MRCloseCommand {
+
+	// Create the new command.
+	cmd := &MRCloseCommand{
+		GitlabCommand: GitlabCommand[MRCloseOptions]{
+			BasicCommand: BasicCommand[MRCloseOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// CloseMergeRequest closes the merge request without merging it.
+func CloseMergeRequest(
+	ctx context.Context,
+	s gitlab_util.MergeRequestUpdater, /* was *gitlab.MergeRequestsService */
+	p *gitlab.Project,
+	mr *gitlab.MergeRequest,
+) error {
+	_, _, err := s.UpdateMergeRequest(p.ID, mr.IID,
+		&gitlab.UpdateMergeRequestOptions{
+			StateEvent: gitlab.Ptr("close"),
+		},
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("CloseMergeRequest: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *MRCloseCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MRSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Close the merge requests.
+	err = UpdateMergeRequests(
+		ctx, result, &cmd.options.MRSelectorOptions,
+		cmd.client.Groups, cmd.client.MergeRequests, i18n.T("Closing"),
+		func(p *gitlab.Project, mr *gitlab.MergeRequest) error {
+			return CloseMergeRequest(ctx, cmd.client.MergeRequests, p, mr)
+		},
+		cmd.options.KeepGoing,
+		cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not close %d merge request(s)")
+	return result, err
+}
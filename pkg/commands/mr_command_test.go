@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMRIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	created := time.Now().Add(-time.Hour)
+	for _, d := range []struct {
+		project string
+		author  string
+		title   string
+		labels  []string
+	}{
+		{"foo/alpha", "renovate", "Update module x to v2", []string{"deps"}},
+		{"foo/alpha", "aberns", "Add feature", nil},
+		{"foo/beta", "renovate", "Update module y to v3", []string{"deps"}},
+		{"foo/bar/delta", "renovate", "Update module z to v1", []string{"deps", "major"}},
+	} {
+		mr := server.AddMergeRequest(d.project, d.author, created, nil)
+		server.SetMergeRequest(mr, d.title, "main", d.labels...)
+	}
+	session := NewSessionWithClient(server.Client(t))
+
+	// run runs the "mr" subcommand and returns the names of the
+	// merge requests that succeeded.
+	run := func(args ...string) []string {
+		cmd := NewMRCommand("mr", &MROptions{}, session)
+		_, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("mr %v: unexpected error: %v", args, err)
+		}
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	// Verify the filters.
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"list", "--group", "foo", "-r"},
+			[]string{"foo/alpha!1", "foo/alpha!2", "foo/beta!1", "foo/bar/delta!1"}},
+		{[]string{"list", "--group", "foo", "-r", "--author", "renovate", "--labels", "deps,major"},
+			[]string{"foo/bar/delta!1"}},
+		{[]string{"list", "--group", "foo", "--title-expr", "^Update"},
+			[]string{"foo/alpha!1", "foo/beta!1"}},
+		{[]string{"list", "--group", "foo", "--target-branch", "develop"}, nil},
+		{[]string{"list", "--group", "foo", "-r", "--graphql", "--labels", "deps"},
+			[]string{"foo/alpha!1", "foo/beta!1", "foo/bar/delta!1"}},
+	}
+	for _, d := range data {
+		actual := run(d.args...)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("mr %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+	}
+
+	// Verify approving, merging, and closing.
+	run("approve", "--group", "foo", "-r", "--labels", "deps")
+	run("merge", "--group", "foo", "--author", "renovate", "--dry-run")
+	run("merge", "--group", "foo", "--author", "renovate")
+	run("merge", "--group", "foo", "-r", "--labels", "major", "--when-pipeline-succeeds")
+	run("close", "--group", "foo", "--expr", "alpha")
+	type States []struct {
+		project  string
+		expected []string
+	}
+	states := States{
+		{"foo/alpha", []string{"1:merged+approved", "2:closed"}},
+		{"foo/beta", []string{"1:merged+approved"}},
+		{"foo/bar/delta", []string{"1:opened+auto+approved"}},
+	}
+	for _, d := range states {
+		actual := server.MergeRequestStates(d.project)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("mr %s: expected=%v  actual=%v", d.project, d.expected, actual)
+		}
+	}
+
+	// Verify merged merge requests are only listed for their state.
+	actual := run("list", "--group", "foo", "-r", "--state", "merged")
+	expected := []string{"foo/alpha!1", "foo/beta!1"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("mr list --state merged: expected=%v  actual=%v", expected, actual)
+	}
+	cmd := NewMRCommand("mr", &MROptions{}, session)
+	_, err := cmd.Run(context.Background(), []string{"list", "--group", "foo", "--state", "bogus"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("mr list --state bogus: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+}
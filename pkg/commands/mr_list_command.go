@@ -0,0 +1,206 @@
+// This file provides the implementation for the "mr list" command
+// which lists the merge requests across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MRListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MRListOptions are the options needed by this command.
+type MRListOptions struct {
+
+	// Embed the options that select the merge requests.
+	MRSelectorOptions
+
+	// GraphQL controls whether merge requests are listed using the GraphQL
+	// API instead of the REST API.  The GraphQL API needs far fewer
+	// round trips for large groups.  If the GraphQL API is
+	// unavailable, the REST API is used instead.  Defaults to false.
+	GraphQL bool `xml:"graphql"`
+
+	// State is the state of the merge requests to list which is one
+	// of "opened", "closed", "locked", "merged", or "all".  Defaults
+	// to "opened".
+	State string `xml:"state"`
+}
+
+// mrStates are the valid values for --state.
+var mrStates = []string{"opened", "closed", "locked", "merged", "all"}
+
+// Initialize initializes this MRListOptions instance so it can be used
+// with the "flag" package to parse the command-line arguments.
+func (opts *MRListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --target-branch, --test-expr, --title-expr
+	opts.MRSelectorOptions.Initialize(flags)
+
+	// --graphql
+	flags.BoolVar(&opts.GraphQL, "graphql", opts.GraphQL,
+		i18n.T("whether to use the GraphQL API instead of the REST API "+
+			"falling back to the REST API if GraphQL is unavailable"))
+
+	// --state
+	if opts.State == "" {
+		opts.State = "opened"
+	}
+	flags.StringVar(&opts.State, "state", opts.State,
+		i18n.T("state of the merge requests to list which is one of "+
+			"\"opened\", \"closed\", \"locked\", \"merged\", or \"all\""))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MRListCommand
+////////////////////////////////////////////////////////////////////////
+
+// MRListCommand implements the "mr list" command which lists the merge
+// requests across the projects in a group.
+type MRListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MRListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MRListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] mr list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the merge requests in the selected projects.  Each\n")
+	i18n.Fprintf(out, "    merge request is printed as its reference, author, and title.\n")
+	i18n.Fprintf(out, "    With --graphql, only some fields are printed for --output\n")
+	i18n.Fprintf(out, "    json because the GraphQL API only fetches the fields needed.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMRListCommand returns a new, initialized MRListCommand instance.
+func NewMRListCommand(
+	name string,
+	opts *MRListOptions,
+	session *Session,
+) *MRListCommand {
+
+	// Create the new command.
+	cmd := &MRListCommand{
+		GitlabCommand: GitlabCommand[MRListOptions]{
+			BasicCommand: BasicCommand[MRListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *MRListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MRSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if !slices.Contains(mrStates, cmd.options.State) {
+		return result, i18n.Errorf("%w: invalid state: %q",
+			ErrInvalidOption, cmd.options.State)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print each merge request.  For --output json, the merge
+	// requests are collected and printed together at the end.
+	mrs := []*gitlab.MergeRequest{}
+	printMR := func(p *gitlab.Project, mr *gitlab.MergeRequest) (bool, error) {
+		if cmd.session.OutputJSON() {
+			mrs = append(mrs, mr)
+		} else {
+			author := ""
+			if mr.Author != nil {
+				author = mr.Author.Username
+			}
+			fmt.Printf("%-40s  %-16s  %s\n", mrName(p, mr), author, mr.Title)
+		}
+		result.Succeed(mrName(p, mr), mr)
+		return true, nil
+	}
+	if cmd.options.GraphQL {
+		err = cmd.options.ForEachMergeRequestGraphQL(
+			ctx, result, cmd.client, cmd.options.State, printMR)
+	} else {
+		err = cmd.options.ForEachMergeRequest(
+			ctx, result, cmd.client.Groups, cmd.client.MergeRequests,
+			cmd.options.State, printMR)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Print the merge requests as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, mrs)
+	}
+	return result, err
+}
@@ -0,0 +1,238 @@
+// This file provides the implementation for the "mr merge" command
+// which merges the opened merge requests across the projects in a
+// group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MRMergeOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MRMergeOptions are the options needed by this command.
+type MRMergeOptions struct {
+
+	// Embed the options that control whether the remaining merge
+	// requests are merged after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the merge requests.
+	MRSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// RemoveSourceBranch controls whether the source branch is
+	// removed after merging.  Defaults to false.
+	RemoveSourceBranch bool `xml:"remove-source-branch"`
+
+	// Squash controls whether the commits are squashed into a single
+	// commit when merging.  Defaults to false.
+	Squash bool `xml:"squash"`
+
+	// WhenPipelineSucceeds controls whether the merge requests are
+	// set to be merged when their pipelines succeed instead of being
+	// merged immediately.  Defaults to false.
+	WhenPipelineSucceeds bool `xml:"when-pipeline-succeeds"`
+}
+
+// Initialize initializes this MRMergeOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *MRMergeOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --author, --expr, --group, --ignore-case, --labels,
+	// -r, --recursive, --target-branch, --test-expr, --title-expr
+	opts.MRSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --remove-source-branch
+	flags.BoolVar(&opts.RemoveSourceBranch, "remove-source-branch", opts.RemoveSourceBranch,
+		i18n.T("whether to remove the source branch after merging"))
+
+	// --squash
+	flags.BoolVar(&opts.Squash, "squash", opts.Squash,
+		i18n.T("whether to squash the commits into a single commit when merging"))
+
+	// --when-pipeline-succeeds
+	flags.BoolVar(&opts.WhenPipelineSucceeds, "when-pipeline-succeeds", opts.WhenPipelineSucceeds,
+		i18n.T("whether to merge when the pipeline succeeds instead of immediately"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MRMergeCommand
+////////////////////////////////////////////////////////////////////////
+
+// MRMergeCommand implements the "mr merge" command which merges the
+// opened merge requests across the projects in a group.
+type MRMergeCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MRMergeOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MRMergeCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] mr merge [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Merge the selected opened merge requests.  With\n")
+	i18n.Fprintf(out, "    --when-pipeline-succeeds, each merge request is set to be\n")
+	i18n.Fprintf(out, "    merged when its pipeline succeeds instead of immediately.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Merge Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMRMergeCommand returns a new, initialized MRMergeCommand
+// instance.
+func NewMRMergeCommand(
+	name string,
+	opts *MRMergeOptions,
+	session *Session,
+) *MRMergeCommand {
+
+	// Create the new command.
+	cmd := &MRMergeCommand{
+		GitlabCommand: GitlabCommand[MRMergeOptions]{
+			BasicCommand: BasicCommand[MRMergeOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// AcceptMergeRequestOptions returns the options for merging the merge
+// request.  If the SHA of the head of the merge request is known,
+// Gitlab only merges the merge request if nothing has been pushed to
+// it since it was listed.
+func (opts *MRMergeOptions) AcceptMergeRequestOptions(
+	mr *gitlab.MergeRequest,
+) *gitlab.AcceptMergeRequestOptions {
+	result := &gitlab.AcceptMergeRequestOptions{}
+	if mr.SHA != "" {
+		result.SHA = gitlab.Ptr(mr.SHA)
+	}
+	if opts.RemoveSourceBranch {
+		result.ShouldRemoveSourceBranch = gitlab.Ptr(true)
+	}
+	if opts.Squash {
+		result.Squash = gitlab.Ptr(true)
+	}
+	if opts.WhenPipelineSucceeds {
+		result.MergeWhenPipelineSucceeds = gitlab.Ptr(true)
+	}
+	return result
+}
+
+// MergeMergeRequest merges the merge request using the options.
+func MergeMergeRequest(
+	ctx context.Context,
+	s gitlab_util.MergeRequestAccepter, /* was *gitlab.MergeRequestsService */
+	p *gitlab.Project,
+	mr *gitlab.MergeRequest,
+	opts *gitlab.AcceptMergeRequestOptions,
+) error {
+	_, _, err := s.AcceptMergeRequest(p.ID, mr.IID, opts,
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("MergeMergeRequest: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *MRMergeCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.MRSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Merge the merge requests.
+	err = UpdateMergeRequests(
+		ctx, result, &cmd.options.MRSelectorOptions,
+		cmd.client.Groups, cmd.client.MergeRequests, i18n.T("Merging"),
+		func(p *gitlab.Project, mr *gitlab.MergeRequest) error {
+			return MergeMergeRequest(ctx, cmd.client.MergeRequests, p, mr,
+				cmd.options.AcceptMergeRequestOptions(mr))
+		},
+		cmd.options.KeepGoing,
+		cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not merge %d merge request(s)")
+	return result, err
+}
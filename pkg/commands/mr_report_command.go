@@ -1,12 +1,12 @@
-// This file provides the implementation for the "users" command
-// which provides user related subcommands.
+// This file provides the implementation for the "mr report" command
+// which provides subcommands that report on merge requests.
 //
 // If you need to add a new subcommand, do the following:
 //
 //   1) Create the new subcommand similar to
-//      cmd/internal/commands/projects_command.go if the subcommand
+//      pkg/commands/projects_command.go if the subcommand
 //      will have its own set of subcommands or similar to
-//      cmd/internal/commands/projects_list_command.go if the
+//      pkg/commands/projects_list_command.go if the
 //      subcommand will actually do something.
 //
 //   2) Add the resulting new options struct to the Options struct
@@ -14,22 +14,23 @@
 //      file.
 //
 //   3) Add the new subcommand as demonstrated in
-//      UsersCommand.addSubcmds().
+//      MRCommand.addSubcmds().
 
 package commands
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/xanzy/go-gitlab"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
 )
 
 ////////////////////////////////////////////////////////////////////////
-// UsersOptions
+// MRReportOptions
 ////////////////////////////////////////////////////////////////////////
 
 //
@@ -41,42 +42,44 @@ import (
 // lean, we factor out our options into their own data structure.
 //
 
-// UsersOptions are the options needed by this command.
-type UsersOptions struct {
-	UsersListOpts UsersListOptions `xml:"list-options"`
+// MRReportOptions are the options needed by this command.
+type MRReportOptions struct {
+
+	// Options for the "mr report lead-time" command.
+	MRReportLeadTimeOpts MRReportLeadTimeOptions `xml:"lead-time-options"`
 }
 
-// Initialize initializes this UsersOptions instance so it can be
+// Initialize initializes this MRReportOptions instance so it can be
 // used with the "flag" package to parse the command-line arguments.
-func (opts *UsersOptions) Initialize(flags *flag.FlagSet) {
+func (opts *MRReportOptions) Initialize(flags *flag.FlagSet) {
 	// empty
 }
 
 ////////////////////////////////////////////////////////////////////////
-// UsersCommand
+// MRReportCommand
 ////////////////////////////////////////////////////////////////////////
 
-// UsersCommand provides subcommands for Gitlab project related
-// maintenance.
-type UsersCommand struct {
+// MRReportCommand provides subcommands that report on Gitlab merge
+// requests.
+type MRReportCommand struct {
 
 	// Embed the Command members.
-	ParentCommand[UsersOptions]
+	ParentCommand[MRReportOptions]
 }
 
 // Usage prints the main usage message to the output writer.  If
 // err is not nil, it will be printed before the main output.
-func (cmd *UsersCommand) Usage(out io.Writer, err error) {
+func (cmd *MRReportCommand) Usage(out io.Writer, err error) {
 	basename := filepath.Base(os.Args[0])
 	if err != nil {
 		fmt.Fprintf(out, "%v\n", err)
 	}
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out,
-		"Usage: %s [global_options] users [subcmd]\n",
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] mr report [subcmd]\n",
 		basename)
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out, "    Command for administering a Gitlab users.\n")
+	i18n.Fprintf(out, "    Command for reporting on Gitlab merge requests.\n")
 	fmt.Fprintf(out, "\n")
 	fmt.Fprintf(out, "Subcommands:\n")
 	fmt.Fprintf(out, "\n")
@@ -84,60 +87,57 @@ func (cmd *UsersCommand) Usage(out io.Writer, err error) {
 		fmt.Fprintf(out, "  %s\n", subcmd)
 	}
 	fmt.Fprintf(out, "\n")
-	if out == os.Stderr {
-		os.Exit(1)
-	}
-	os.Exit(0)
 }
 
 // addSubcmds adds the subcommands for this command.
-func (cmd *UsersCommand) addSubcmds(client *gitlab.Client) {
-	cmd.subcmds["list"] = NewUsersListCommand(
-		"list", &cmd.options.UsersListOpts, client)
+func (cmd *MRReportCommand) addSubcmds(session *Session) {
+	cmd.subcmds["lead-time"] = NewMRReportLeadTimeCommand(
+		"lead-time", &cmd.options.MRReportLeadTimeOpts, session)
 }
 
-// NewUsersCommand returns a new, initialized UsersCommand
+// NewMRReportCommand returns a new, initialized MRReportCommand
 // instance having the specified name.
-func NewUsersCommand(
+func NewMRReportCommand(
 	name string,
-	opts *UsersOptions,
-	client *gitlab.Client,
-) *UsersCommand {
+	opts *MRReportOptions,
+	session *Session,
+) *MRReportCommand {
 
 	// Create the new command.
-	cmd := &UsersCommand{
-		ParentCommand: ParentCommand[UsersOptions]{
-			BasicCommand: BasicCommand[UsersOptions]{
+	cmd := &MRReportCommand{
+		ParentCommand: ParentCommand[MRReportOptions]{
+			BasicCommand: BasicCommand[MRReportOptions]{
 				name:    name,
-				flags:   flag.NewFlagSet(name, flag.ExitOnError),
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
 				options: opts,
 			},
 			subcmds: make(map[string]Runner),
 		},
 	}
 
-	// Set up the function that prints the global usage and exits.
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
 	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
 
 	// Initialize our command-line options.
 	cmd.options.Initialize(cmd.flags)
 
 	// Add the subcommands.
-	cmd.addSubcmds(client)
+	cmd.addSubcmds(session)
 
 	return cmd
 }
 
 // Run is the entry point for this command.
-func (cmd *UsersCommand) Run(args []string) error {
+func (cmd *MRReportCommand) Run(ctx context.Context, args []string) (*Result, error) {
 	var err error
 
 	// Parse command-line arguments.
 	err = cmd.flags.Parse(args)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Dispatch the subcommand specified by the remaining arguments.
-	return cmd.DispatchSubcommand(cmd.flags.Args())
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
 }
@@ -0,0 +1,381 @@
+// This file provides the implementation for the "mr report lead-time"
+// command which reports the time to first review and the time to
+// merge of the merge requests in each project so DORA-style metrics
+// can be pulled from Gitlab directly.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// MRReportLeadTimeOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// MRReportLeadTimeOptions are the options needed by this command.
+type MRReportLeadTimeOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Since is the first day of the date range in which the merge
+	// requests were merged.  Defaults to 30 days before Until.
+	Since date_arg.DateArg `xml:"since"`
+
+	// Until is the last day of the date range in which the merge
+	// requests were merged.  Defaults to today.
+	Until date_arg.DateArg `xml:"until"`
+}
+
+// Initialize initializes this MRReportLeadTimeOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *MRReportLeadTimeOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --since
+	flags.Var(&opts.Since, "since",
+		i18n.T("first day on which merge requests were merged the form of "+
+			"which is YYYY/MM/DD or YYYY-MM-DD (defaults to 30 days before --until)"))
+
+	// --until
+	flags.Var(&opts.Until, "until",
+		i18n.T("last day on which merge requests were merged the form of "+
+			"which is YYYY/MM/DD or YYYY-MM-DD (defaults to today)"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// MRReportLeadTimeCommand
+////////////////////////////////////////////////////////////////////////
+
+// MRReportLeadTimeCommand implements the "mr report lead-time" command
+// which reports lead-time percentiles for each project.
+type MRReportLeadTimeCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[MRReportLeadTimeOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *MRReportLeadTimeCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] mr report lead-time [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the 50th, 75th, and 90th percentiles of the time to\n")
+	i18n.Fprintf(out, "    first review and the time to merge of the merge requests\n")
+	i18n.Fprintf(out, "    merged in each selected project from --since through --until.\n")
+	i18n.Fprintf(out, "    The first review is the first comment or approval by someone\n")
+	i18n.Fprintf(out, "    other than the author.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Lead-Time Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewMRReportLeadTimeCommand returns a new, initialized
+// MRReportLeadTimeCommand instance.
+func NewMRReportLeadTimeCommand(
+	name string,
+	opts *MRReportLeadTimeOptions,
+	session *Session,
+) *MRReportLeadTimeCommand {
+
+	// Create the new command.
+	cmd := &MRReportLeadTimeCommand{
+		GitlabCommand: GitlabCommand[MRReportLeadTimeOptions]{
+			BasicCommand: BasicCommand[MRReportLeadTimeOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// MRReportLeadTimeServices are the Gitlab services needed to find the
+// lead times of merge requests.
+type MRReportLeadTimeServices struct {
+	Groups        gitlab_util.ProjectsInGroupLister      /* was *gitlab.GroupsService */
+	MergeRequests gitlab_util.ProjectMergeRequestsLister /* was *gitlab.MergeRequestsService */
+	Notes         gitlab_util.MergeRequestNotesLister    /* was *gitlab.NotesService */
+}
+
+// LeadTimes are the lead times of the merge requests merged in a
+// project.  Both slices are sorted from shortest to longest.
+type LeadTimes struct {
+
+	// Path is the full path of the project.
+	Path string
+
+	// Merged is the number of merged merge requests.
+	Merged int
+
+	// FirstReview holds the time from creation to the first review of
+	// each merge request that was reviewed.
+	FirstReview []time.Duration
+
+	// Merge holds the time from creation to merge of each merge
+	// request.
+	Merge []time.Duration
+}
+
+// leadTimePercentiles are the percentiles that are reported.
+var leadTimePercentiles = []int{50, 75, 90}
+
+// Percentile returns the pth percentile of the sorted durations using
+// the nearest-rank method.  It returns false if there are no
+// durations.
+func Percentile(sorted []time.Duration, p int) (time.Duration, bool) {
+	if len(sorted) == 0 {
+		return 0, false
+	}
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1], true
+}
+
+// FormatLeadTime formats the duration in days and hours, hours and
+// minutes, or minutes depending on its magnitude.
+func FormatLeadTime(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd%dh", d/(24*time.Hour), (d%(24*time.Hour))/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh%dm", d/time.Hour, (d%time.Hour)/time.Minute)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// FirstReview returns the note that is the first review of the merge
+// request which is the first comment or approval by someone other
+// than the author.  The notes must be sorted from oldest to newest.
+// It returns nil if the merge request has not been reviewed.
+func FirstReview(mr *gitlab.MergeRequest, notes []*gitlab.Note) *gitlab.Note {
+	for _, note := range notes {
+		if note.CreatedAt == nil {
+			continue
+		}
+		if mr.Author != nil && note.Author.Username == mr.Author.Username {
+			continue
+		}
+		if note.System && !strings.HasPrefix(note.Body, "approved") {
+			continue
+		}
+		return note
+	}
+	return nil
+}
+
+// FindLeadTimes returns the lead times of the merge requests merged
+// from start up to but not including end in each selected project
+// that has merged merge requests.
+func FindLeadTimes(
+	ctx context.Context,
+	result *Result,
+	selector *ProjectSelectorOptions,
+	s MRReportLeadTimeServices,
+	start time.Time,
+	end time.Time,
+) ([]*LeadTimes, error) {
+	var leadTimes []*LeadTimes
+
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+
+			// Merging a merge request updates it so only the merge
+			// requests updated since the start need to be checked.
+			mrs, err := gitlab_util.GetAllProjectMergeRequests(
+				ctx, s.MergeRequests, p.ID, start)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+
+			// Find the lead times of the merge requests merged in the
+			// date range.
+			lt := &LeadTimes{Path: p.PathWithNamespace}
+			for _, mr := range mrs {
+				if mr.CreatedAt == nil || mr.MergedAt == nil ||
+					mr.MergedAt.Before(start) || !mr.MergedAt.Before(end) {
+					continue
+				}
+				lt.Merged++
+				lt.Merge = append(lt.Merge, mr.MergedAt.Sub(*mr.CreatedAt))
+				notes, err := gitlab_util.GetAllMergeRequestNotes(
+					ctx, s.Notes, p.ID, mr.IID)
+				if err != nil {
+					result.Fail(p.PathWithNamespace, p, err)
+					return true, nil
+				}
+				if review := FirstReview(mr, notes); review != nil {
+					lt.FirstReview = append(lt.FirstReview,
+						review.CreatedAt.Sub(*mr.CreatedAt))
+				}
+			}
+			result.Succeed(p.PathWithNamespace, p)
+			if lt.Merged == 0 {
+				return true, nil
+			}
+			slices.Sort(lt.FirstReview)
+			slices.Sort(lt.Merge)
+			leadTimes = append(leadTimes, lt)
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindLeadTimes: %w", err)
+	}
+
+	return leadTimes, nil
+}
+
+// PrintLeadTimes prints the lead-time percentiles of each project as a
+// table followed by the percentiles across all of the projects.
+func PrintLeadTimes(out io.Writer, leadTimes []*LeadTimes) {
+	row := func(columns ...string) {
+		for _, c := range columns[:len(columns)-1] {
+			fmt.Fprintf(out, "%10s  ", c)
+		}
+		fmt.Fprintf(out, "%s\n", columns[len(columns)-1])
+	}
+	percentiles := func(columns []string, sorted []time.Duration) []string {
+		for _, p := range leadTimePercentiles {
+			if d, ok := Percentile(sorted, p); ok {
+				columns = append(columns, FormatLeadTime(d))
+			} else {
+				columns = append(columns, "-")
+			}
+		}
+		return columns
+	}
+
+	// Print the header.
+	header := []string{i18n.T("MERGED")}
+	for _, p := range leadTimePercentiles {
+		header = append(header, i18n.Sprintf("REVIEW P%d", p))
+	}
+	for _, p := range leadTimePercentiles {
+		header = append(header, i18n.Sprintf("MERGE P%d", p))
+	}
+	row(append(header, i18n.T("PROJECT"))...)
+
+	// Print each project while collecting the totals.
+	total := &LeadTimes{}
+	for _, lt := range leadTimes {
+		columns := []string{fmt.Sprint(lt.Merged)}
+		columns = percentiles(columns, lt.FirstReview)
+		columns = percentiles(columns, lt.Merge)
+		row(append(columns, lt.Path)...)
+		total.Merged += lt.Merged
+		total.FirstReview = append(total.FirstReview, lt.FirstReview...)
+		total.Merge = append(total.Merge, lt.Merge...)
+	}
+
+	// Print the totals.
+	slices.Sort(total.FirstReview)
+	slices.Sort(total.Merge)
+	columns := []string{fmt.Sprint(total.Merged)}
+	columns = percentiles(columns, total.FirstReview)
+	columns = percentiles(columns, total.Merge)
+	row(append(columns, i18n.Sprintf("(total of %d project(s))", len(leadTimes)))...)
+}
+
+// Run is the entry point for this command.
+func (cmd *MRReportLeadTimeCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Determine the date range.
+	start, end := date_arg.Range(cmd.options.Since, cmd.options.Until, 30)
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if !start.Before(end) {
+		return result, i18n.Errorf("%w: invalid date range: %v to %v",
+			ErrInvalidOption, cmd.options.Since.String(), cmd.options.Until.String())
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Find the lead times.
+	leadTimes, err := FindLeadTimes(
+		ctx, result, &cmd.options.ProjectSelectorOptions,
+		MRReportLeadTimeServices{
+			Groups:        cmd.client.Groups,
+			MergeRequests: cmd.client.MergeRequests,
+			Notes:         cmd.client.Notes,
+		}, start, end)
+	if err != nil {
+		return result, err
+	}
+
+	// Print the report.
+	PrintLeadTimes(os.Stdout, leadTimes)
+	return result, nil
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestMRReportLeadTimeIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	at := func(d int, h int) time.Time {
+		return time.Date(2024, time.March, d, h, 0, 0, 0, time.Local)
+	}
+	mr := server.AddMergeRequest("foo/alpha", "aberns", at(1, 10), gitlab.Ptr(at(2, 10)))
+	server.AddMergeRequestNote(mr, "aberns", "ready for review", false, at(1, 11))
+	server.AddMergeRequestNote(mr, "bcrocket", "approved this merge request", true, at(1, 14))
+	mr = server.AddMergeRequest("foo/alpha", "bcrocket", at(3, 0), gitlab.Ptr(at(3, 12)))
+	server.AddMergeRequestNote(mr, "bcrocket", "requested review", true, at(3, 0))
+	server.AddMergeRequestNote(mr, "aberns", "looks good", false, at(3, 1))
+	server.AddMergeRequest("foo/beta", "aberns", at(1, 0), gitlab.Ptr(at(25, 0)))
+	server.AddMergeRequest("foo/beta", "aberns", at(2, 0), nil)
+	cmd := NewMRCommand("mr", &MROptions{}, session)
+
+	// Report the lead times.
+	out, _, err := runCommand(t, cmd, []string{
+		"report", "lead-time", "--group", "foo",
+		"--since", "2024-03-01", "--until", "2024-03-10"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the report.
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"rows", 3, len(lines)},
+	}
+	if len(lines) == 3 {
+		data = append(data, Data{
+			{"alpha", []string{"2", "1h0m", "4h0m", "4h0m", "12h0m", "1d0h", "1d0h", "foo/alpha"},
+				strings.Fields(lines[1])},
+			{"total", []string{"2", "1h0m", "4h0m", "4h0m", "12h0m", "1d0h", "1d0h"},
+				strings.Fields(lines[2])[:7]},
+		}...)
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("mr report lead-time %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
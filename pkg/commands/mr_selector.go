@@ -0,0 +1,248 @@
+// This file provides the options shared by the commands that operate
+// on a selection of merge requests across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+// MRSelectorOptions select merge requests by the project they belong
+// to, their author, labels, target branch, and title.  They are
+// embedded in the options of the "mr" subcommands so the options have
+// the same names and meaning everywhere.  Because the struct is
+// embedded, its XML elements appear directly in the options of the
+// embedding command in the options.xml file.
+type MRSelectorOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Author is the username of the author of the merge requests.
+	// Defaults to "" which matches any author.
+	Author string `xml:"author"`
+
+	// Labels are the labels the merge requests must all have.
+	// Defaults to no labels which matches any merge request.
+	Labels string_slice.StringSlice `xml:"labels>label"`
+
+	// TargetBranch is the branch the merge requests target.
+	// Defaults to "" which matches any branch.
+	TargetBranch string `xml:"target-branch"`
+
+	// TitleExpr is the regular expression that filters the merge
+	// requests by their title.  Defaults to "" which matches any
+	// title.
+	TitleExpr string `xml:"title-expr"`
+}
+
+// Initialize initializes this MRSelectorOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *MRSelectorOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --author
+	flags.StringVar(&opts.Author, "author", opts.Author,
+		i18n.T("username of the author of the merge requests"))
+
+	// --labels
+	flags.Var(&opts.Labels, "labels",
+		i18n.T("comma-separated list of labels the merge requests must all have"))
+
+	// --target-branch
+	flags.StringVar(&opts.TargetBranch, "target-branch", opts.TargetBranch,
+		i18n.T("branch the merge requests target"))
+
+	// --title-expr
+	flags.StringVar(&opts.TitleExpr, "title-expr", opts.TitleExpr,
+		i18n.T("regular expression that selects merge requests by title"))
+}
+
+// Validate returns an error if the options cannot select any merge
+// requests.
+func (opts *MRSelectorOptions) Validate() error {
+	err := opts.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return err
+	}
+	_, err = regexp.Compile(opts.TitleExpr)
+	if err != nil {
+		return i18n.Errorf("%w: invalid title-expr: %q: %v",
+			ErrInvalidOption, opts.TitleExpr, err)
+	}
+	return nil
+}
+
+// ListOptions returns the options for listing the merge requests in
+// the state (e.g., "opened") which pass the filters Gitlab can apply.
+// If state is "all", merge requests in any state are listed.
+func (opts *MRSelectorOptions) ListOptions(state string) *gitlab.ListProjectMergeRequestsOptions {
+	result := &gitlab.ListProjectMergeRequestsOptions{
+		State: gitlab.Ptr(state),
+	}
+	if opts.Author != "" {
+		result.AuthorUsername = gitlab.Ptr(opts.Author)
+	}
+	if len(opts.Labels) > 0 {
+		labels := gitlab.LabelOptions(opts.Labels)
+		result.Labels = &labels
+	}
+	if opts.TargetBranch != "" {
+		result.TargetBranch = gitlab.Ptr(opts.TargetBranch)
+	}
+	return result
+}
+
+// ForEachMergeRequest calls f once for each selected merge request in
+// the state (e.g., "opened") in each selected project.  If the merge
+// requests of a project cannot be listed, the failure is recorded in
+// the result, and the remaining projects are still processed.  The
+// function f must return true and no error to indicate that it wants
+// to continue being called with the remaining merge requests.  If f
+// returns an error, it will be forwarded to the caller as the error
+// return value for this function.
+func (opts *MRSelectorOptions) ForEachMergeRequest(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	mergeRequests gitlab_util.ProjectMergeRequestsLister, /* was *gitlab.MergeRequestsService */
+	state string,
+	f func(p *gitlab.Project, mr *gitlab.MergeRequest) (bool, error),
+) error {
+	titleExpr, err := regexp.Compile(opts.TitleExpr)
+	if err != nil {
+		return fmt.Errorf("ForEachMergeRequest: %w", err)
+	}
+	err = opts.ForEachProject(ctx, groups,
+		func(p *gitlab.Project) (bool, error) {
+			mrs, err := gitlab_util.GetAllProjectMergeRequestsWithOptions(
+				ctx, mergeRequests, p.ID, opts.ListOptions(state))
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, mr := range mrs {
+				if !titleExpr.MatchString(mr.Title) {
+					continue
+				}
+				ok, err := f(p, mr)
+				if !ok || err != nil {
+					return ok, err
+				}
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("ForEachMergeRequest: %w", err)
+	}
+	return nil
+}
+
+// ForEachMergeRequestGraphQL is the same as ForEachMergeRequest()
+// except it uses the GraphQL API which lists the merge requests of all
+// the selected projects in far fewer round trips.  Only the fields
+// documented for gitlab_util.ForEachMergeRequestInGroupGraphQL() are
+// set for the projects and merge requests passed to f.  If the GraphQL
+// API is unavailable or the projects are also selected by a filter,
+// this function falls back to ForEachMergeRequest().
+func (opts *MRSelectorOptions) ForEachMergeRequestGraphQL(
+	ctx context.Context,
+	result *Result,
+	client *gitlab.Client,
+	state string,
+	f func(p *gitlab.Project, mr *gitlab.MergeRequest) (bool, error),
+) error {
+	if opts.filter != nil {
+		return opts.ForEachMergeRequest(
+			ctx, result, client.Groups, client.MergeRequests, state, f)
+	}
+	titleExpr, err := regexp.Compile(opts.TitleExpr)
+	if err != nil {
+		return fmt.Errorf("ForEachMergeRequestGraphQL: %w", err)
+	}
+	err = gitlab_util.ForEachMergeRequestInGroupGraphQL(
+		ctx, client, opts.Group, opts.EffectiveExpr(), opts.Recursive,
+		opts.ListOptions(state),
+		func(p *gitlab.Project, mr *gitlab.MergeRequest) (bool, error) {
+			if !titleExpr.MatchString(mr.Title) {
+				return true, nil
+			}
+			return f(p, mr)
+		})
+	if errors.Is(err, gitlab_util.ErrGraphQLUnavailable) {
+		return opts.ForEachMergeRequest(
+			ctx, result, client.Groups, client.MergeRequests, state, f)
+	}
+	if err != nil {
+		return fmt.Errorf("ForEachMergeRequestGraphQL: %w", err)
+	}
+	return nil
+}
+
+// mrName returns the name used to identify the merge request in a
+// Result and in messages which is the usual Gitlab reference of the
+// form "group/project!iid".
+func mrName(p *gitlab.Project, mr *gitlab.MergeRequest) string {
+	return fmt.Sprintf("%s!%d", p.PathWithNamespace, mr.IID)
+}
+
+// UpdateMergeRequests calls update once for each opened merge request
+// selected by the selector.  The verb (e.g., "Approving") is used in
+// the progress messages.  If dryRun is true, update is not called, and
+// this function only prints what it would do.  A merge request that
+// cannot be updated is recorded as failed in the result.  If
+// keepGoing is true, the remaining merge requests are still updated.
+// Otherwise, no merge request is updated after the first one that
+// fails.
+func UpdateMergeRequests(
+	ctx context.Context,
+	result *Result,
+	selector *MRSelectorOptions,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	mergeRequests gitlab_util.ProjectMergeRequestsLister, /* was *gitlab.MergeRequestsService */
+	verb string,
+	update func(p *gitlab.Project, mr *gitlab.MergeRequest) error,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err := selector.ForEachMergeRequest(ctx, result, groups, mergeRequests, "opened",
+		func(p *gitlab.Project, mr *gitlab.MergeRequest) (bool, error) {
+			name := mrName(p, mr)
+			hook.OnItemStart(name)
+			logging.Printf("- %s merge request %q ... ", verb, name)
+			if !dryRun {
+				err := update(p, mr)
+				if err != nil {
+					logging.Printf("Failed.\n")
+					hook.OnError(name, err)
+					result.Fail(name, mr, err)
+					if !keepGoing {
+						return false, err
+					}
+					return true, nil
+				}
+			}
+			logging.Printf("Done.\n")
+			hook.OnItemDone(name)
+			result.Succeed(name, mr)
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("UpdateMergeRequests: %w", err)
+	}
+	return nil
+}
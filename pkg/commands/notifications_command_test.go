@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestNotificationsIntegration(t *testing.T) {
+	run := func(server *fake_gitlab.Server, args ...string) error {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewNotificationsCommand("notifications", &NotificationsOptions{}, session)
+		_, _, err := runCommand(t, cmd, append([]string{"set"}, args...))
+		return err
+	}
+
+	// Silence a subgroup with and without --dry-run.
+	dryRun := newFakeServer(t)
+	err := run(dryRun, "--group", "foo", "--recursive", "--expr", "^foo/bar",
+		"--level", "disabled", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	silenced := newFakeServer(t)
+	err = run(silenced, "--group", "foo", "--recursive", "--expr", "^foo/bar",
+		"--level", "disabled")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Set the level of other users for the projects only.
+	sudo := newFakeServer(t)
+	err = run(sudo, "--group", "foo", "--level", "watch", "--scope", "projects",
+		"--users", "aberns,bcrocket")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unknownErr := run(newFakeServer(t), "--group", "foo", "--level", "watch",
+		"--users", "nobody")
+	invalidErr := run(newFakeServer(t), "--group", "foo", "--level", "custom")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"dry run", "global", dryRun.NotificationLevel("group", "foo/bar", "")},
+		{"unmatched group", "global", silenced.NotificationLevel("group", "foo", "")},
+		{"subgroup", "disabled", silenced.NotificationLevel("group", "foo/bar", "")},
+		{"subgroup project", "disabled",
+			silenced.NotificationLevel("project", "foo/bar/test-epsilon", "")},
+		{"unmatched project", "global", silenced.NotificationLevel("project", "foo/alpha", "")},
+		{"sudo", "watch watch", sudo.NotificationLevel("project", "foo/alpha", "aberns") +
+			" " + sudo.NotificationLevel("project", "foo/beta", "bcrocket")},
+		{"sudo caller", "global", sudo.NotificationLevel("project", "foo/alpha", "")},
+		{"sudo scope", "global", sudo.NotificationLevel("group", "foo", "aberns")},
+		{"sudo not recursive", "global",
+			sudo.NotificationLevel("project", "foo/bar/delta", "aberns")},
+		{"unknown user", true, errors.Is(unknownErr, gitlab_util.ErrNotFound)},
+		{"invalid level", true, errors.Is(invalidErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("notifications set %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// This file provides the implementation for the "notifications set"
+// command which sets the notification level of the caller or of other
+// users for each selected group and project.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// NotificationsSetOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// NotificationsSetOptions are the options needed by this command.
+type NotificationsSetOptions struct {
+
+	// Embed the options that select the projects.  The same options
+	// also select the groups.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Level is the notification level which is one of "disabled",
+	// "participating", "watch", "global", or "mention".  Defaults to
+	// "".
+	Level string `xml:"level"`
+
+	// Scope is "projects" to only set the level for the selected
+	// projects, "groups" to only set the level for the selected
+	// groups, or "all" to set the level for both.  Defaults to "all".
+	Scope string `xml:"scope"`
+
+	// Users are the usernames of the users whose notification level
+	// is set.  Setting the level for other users requires an
+	// administrator.  If empty, the level of the caller is set.
+	// Defaults to empty.
+	Users string_slice.StringSlice `xml:"users>user"`
+}
+
+// Initialize initializes this NotificationsSetOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *NotificationsSetOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --level
+	flags.StringVar(&opts.Level, "level", opts.Level,
+		i18n.T("notification level which is one of disabled, participating, "+
+			"watch, global, or mention"))
+
+	// --scope
+	if opts.Scope == "" {
+		opts.Scope = "all"
+	}
+	flags.StringVar(&opts.Scope, "scope", opts.Scope,
+		i18n.T("either \"projects\", \"groups\", or \"all\" to set the level "+
+			"for the selected projects, groups, or both"))
+
+	// --users
+	flags.Var(&opts.Users, "users",
+		i18n.T("comma-separated list of usernames whose level is set instead "+
+			"of the caller's which requires an administrator"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// NotificationsSetCommand
+////////////////////////////////////////////////////////////////////////
+
+// NotificationsSetCommand implements the "notifications set" command
+// which sets the notification level for groups and projects.
+type NotificationsSetCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[NotificationsSetOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *NotificationsSetCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] notifications set [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Set the notification level of the caller for the --group,\n")
+	i18n.Fprintf(out, "    for its subgroups if --recursive, and for the projects\n")
+	i18n.Fprintf(out, "    beneath them whose full paths match --expr.  Use --users\n")
+	i18n.Fprintf(out, "    to set the level of other users instead which requires an\n")
+	i18n.Fprintf(out, "    administrator.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Set Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewNotificationsSetCommand returns a new, initialized
+// NotificationsSetCommand instance.
+func NewNotificationsSetCommand(
+	name string,
+	opts *NotificationsSetOptions,
+	session *Session,
+) *NotificationsSetCommand {
+
+	// Create the new command.
+	cmd := &NotificationsSetCommand{
+		GitlabCommand: GitlabCommand[NotificationsSetOptions]{
+			BasicCommand: BasicCommand[NotificationsSetOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// NotificationsGroupsService is an abstraction of gitlab.GroupsService
+// which walks the groups and projects beneath a group.
+type NotificationsGroupsService interface {
+	gitlab_util.ProjectsInGroupLister
+	gitlab_util.SubgroupsLister
+}
+
+// NotificationsSetServices are the Gitlab services needed to set the
+// notification levels.
+type NotificationsSetServices struct {
+	Groups               NotificationsGroupsService              /* was *gitlab.GroupsService */
+	NotificationSettings gitlab_util.NotificationSettingsManager /* was *gitlab.NotificationSettingsService */
+}
+
+// NotificationTarget is a group or project whose notification level is
+// set.
+type NotificationTarget struct {
+
+	// Kind is either "group" or "project".
+	Kind string
+
+	// ID is the ID of the group or project.
+	ID int
+
+	// Path is the full path of the group or project.
+	Path string
+}
+
+// ParseNotificationLevel returns the notification level having the
+// name.  Only the levels in gitlab_util.NotificationLevels are valid.
+func ParseNotificationLevel(name string) (gitlab.NotificationLevelValue, error) {
+	for _, level := range gitlab_util.NotificationLevels {
+		if level.String() == name {
+			return level, nil
+		}
+	}
+	return 0, i18n.Errorf("%w: invalid level: %q", ErrInvalidOption, name)
+}
+
+// GetNotificationTargets returns the groups and projects selected by
+// the options for the scope which is "projects", "groups", or "all".
+// The selected groups are the group of the options and, if the
+// options are recursive, its subgroups except for those whose full
+// paths do not match the regular expression of the options.  Groups
+// precede projects.
+func GetNotificationTargets(
+	ctx context.Context,
+	s NotificationsGroupsService,
+	opts *ProjectSelectorOptions,
+	scope string,
+) ([]*NotificationTarget, error) {
+	var result []*NotificationTarget
+
+	// Get the groups.
+	if scope != "projects" {
+		groups, err := gitlab_util.GetAllGroups(
+			ctx, s, opts.Group, opts.EffectiveExpr(), opts.Recursive)
+		if err != nil {
+			return nil, fmt.Errorf("GetNotificationTargets: %w", err)
+		}
+		for _, g := range groups {
+			result = append(result, &NotificationTarget{
+				Kind: "group",
+				ID:   g.ID,
+				Path: g.FullPath,
+			})
+		}
+	}
+
+	// Get the projects.
+	if scope != "groups" {
+		projects, err := opts.GetAllProjects(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("GetNotificationTargets: %w", err)
+		}
+		for _, p := range projects {
+			result = append(result, &NotificationTarget{
+				Kind: "project",
+				ID:   p.ID,
+				Path: p.PathWithNamespace,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// SetNotificationLevel sets the notification level of the user for the
+// group or project unless it is already set.  If user is empty, the
+// level of the caller is set.  If dryRun is true, this function only
+// prints what it would without actually doing it.
+func SetNotificationLevel(
+	ctx context.Context,
+	s gitlab_util.NotificationSettingsManager, /* was *gitlab.NotificationSettingsService */
+	target *NotificationTarget,
+	user string,
+	level gitlab.NotificationLevelValue,
+	dryRun bool,
+) error {
+	options := []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)}
+	if user != "" {
+		options = append(options, gitlab.WithSudo(user))
+	}
+
+	// Get the current level.
+	get, update := s.GetSettingsForProject, s.UpdateSettingsForProject
+	if target.Kind == "group" {
+		get, update = s.GetSettingsForGroup, s.UpdateSettingsForGroup
+	}
+	settings, _, err := get(target.ID, options...)
+	if err != nil {
+		return fmt.Errorf(
+			"SetNotificationLevel: %w", gitlab_util.ClassifyError(err))
+	}
+	if settings.Level == level {
+		if user == "" {
+			logging.Printf("- Notification level for %q already %q.\n",
+				target.Path, level.String())
+		} else {
+			logging.Printf("- Notification level of %q for %q already %q.\n",
+				user, target.Path, level.String())
+		}
+		return nil
+	}
+
+	// Set the level.
+	if user == "" {
+		logging.Printf("- Setting notification level for %q to %q ... ",
+			target.Path, level.String())
+	} else {
+		logging.Printf("- Setting notification level of %q for %q to %q ... ",
+			user, target.Path, level.String())
+	}
+	if !dryRun {
+		_, _, err = update(target.ID,
+			&gitlab.NotificationSettingsOptions{
+				Level: gitlab.NotificationLevel(level),
+			},
+			options...)
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf(
+				"SetNotificationLevel: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *NotificationsSetCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	level, err := ParseNotificationLevel(cmd.options.Level)
+	if err != nil {
+		return result, err
+	}
+	if !slices.Contains([]string{"all", "groups", "projects"}, cmd.options.Scope) {
+		return result, i18n.Errorf("%w: invalid scope: %q",
+			ErrInvalidOption, cmd.options.Scope)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the groups and projects.
+	s := NotificationsSetServices{
+		Groups:               cmd.client.Groups,
+		NotificationSettings: cmd.client.NotificationSettings,
+	}
+	targets, err := GetNotificationTargets(ctx, s.Groups,
+		&cmd.options.ProjectSelectorOptions, cmd.options.Scope)
+	if err != nil {
+		return result, err
+	}
+
+	// Set the level of each user for each group and project.  An
+	// empty user is the caller.
+	users := []string(cmd.options.Users)
+	if len(users) == 0 {
+		users = []string{""}
+	}
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, user := range users {
+		for _, target := range targets {
+			name := target.Path
+			if user != "" {
+				name = user + ":" + target.Path
+			}
+			hook.OnItemStart(name)
+			err = SetNotificationLevel(ctx, s.NotificationSettings, target,
+				user, level, cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(name, err)
+				result.Fail(name, target, err)
+				return result, err
+			}
+			hook.OnItemDone(name)
+			result.Succeed(name, target)
+		}
+	}
+
+	return result, nil
+}
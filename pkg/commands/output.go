@@ -0,0 +1,31 @@
+// This file provides the helpers used by list commands to print their
+// results in the format selected by the global --output option.
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Output formats for the global --output option.
+const (
+
+	// OutputText prints human-readable text.
+	OutputText = "text"
+
+	// OutputJSON prints machine-readable JSON.
+	OutputJSON = "json"
+)
+
+// writeJSON writes v to the output writer as indented JSON followed by
+// a newline so the output can be piped into tools like jq.
+func writeJSON(out io.Writer, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("writeJSON: %w", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", b)
+	return err
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestOutputJSONIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddApprovalRule("foo/alpha", "reviewers", 1, "aberns")
+	server.AddSnippet("foo/alpha", "Setup", "setup.sh", "make setup\n")
+	server.AddPushMirror("foo/alpha", "https://github.com/acme/alpha.git", "finished", nil)
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Output = OutputJSON
+
+	// run runs the command and decodes its JSON output into v.
+	run := func(cmd Runner, args []string, v any) {
+		output, _, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("%v: unexpected error: %v", args, err)
+		}
+		err = json.Unmarshal([]byte(output), v)
+		if err != nil {
+			t.Fatalf("%v: invalid JSON: %v: %q", args, err, output)
+		}
+	}
+
+	// Verify projects list.
+	var projects []*gitlab.Project
+	run(NewProjectsCommand("projects", &ProjectsOptions{}, session),
+		[]string{"list", "--group", "foo", "--expr", "test-", "-r"}, &projects)
+	var paths []string
+	for _, p := range projects {
+		paths = append(paths, p.PathWithNamespace)
+	}
+	expected := []string{"foo/test-gamma", "foo/bar/test-epsilon"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("projects list: expected=%v  actual=%v", expected, paths)
+	}
+
+	// Verify an empty list is printed as an empty array.
+	projects = nil
+	run(NewProjectsCommand("projects", &ProjectsOptions{}, session),
+		[]string{"list", "--group", "foo", "--expr", "nothing"}, &projects)
+	if projects == nil || len(projects) != 0 {
+		t.Errorf("projects list: expected empty array: %v", projects)
+	}
+
+	// Verify users list.
+	var users []*gitlab.User
+	run(NewUsersCommand("users", &UsersOptions{}, session),
+		[]string{"list", "--users", "bcrocket"}, &users)
+	if len(users) != 1 || users[0].Username != "bcrocket" {
+		t.Errorf("users list: unexpected users: %v", users)
+	}
+
+	// Verify approval-rules list.
+	var rules []approvalRulesJSON
+	run(NewProjectsCommand("projects", &ProjectsOptions{}, session),
+		[]string{"approval-rules", "list", "--group", "foo", "--expr", "alpha"}, &rules)
+	if len(rules) != 1 || rules[0].Project != "foo/alpha" ||
+		len(rules[0].Rules) != 1 || rules[0].Rules[0].Name != "reviewers" {
+		t.Errorf("approval-rules list: unexpected rules: %+v", rules)
+	}
+
+	// Verify snippets list.
+	var snippets []*ProjectSnippet
+	run(NewSnippetsCommand("snippets", &SnippetsOptions{}, session),
+		[]string{"list", "--group", "foo", "--expr", "alpha"}, &snippets)
+	if len(snippets) != 1 || snippets[0].Path != "foo/alpha" ||
+		snippets[0].Title != "Setup" {
+		t.Errorf("snippets list: unexpected snippets: %+v", snippets)
+	}
+
+	// Verify mirrors list.
+	var mirrors []*Mirror
+	run(NewProjectsCommand("projects", &ProjectsOptions{}, session),
+		[]string{"mirrors", "list", "--group", "foo", "--expr", "alpha"}, &mirrors)
+	if len(mirrors) != 1 || mirrors[0].Path != "foo/alpha" ||
+		mirrors[0].Direction != "push" ||
+		mirrors[0].URL != "https://github.com/acme/alpha.git" {
+		t.Errorf("mirrors list: unexpected mirrors: %+v", mirrors)
+	}
+}
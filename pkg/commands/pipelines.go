@@ -0,0 +1,72 @@
+// This file provides the helpers shared by the "pipelines"
+// subcommands.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+// pipelineName returns the name used to identify the pipeline in a
+// Result and in messages which is the same as the path of the pipeline
+// in the Gitlab web interface.
+func pipelineName(p *gitlab.Project, pipelineID int) string {
+	return fmt.Sprintf("%s/-/pipelines/%d", p.PathWithNamespace, pipelineID)
+}
+
+// ParsePipelineVariables parses the "KEY=VALUE" pipeline variables.
+func ParsePipelineVariables(variables []string) ([]*gitlab.PipelineVariableOptions, error) {
+	var result []*gitlab.PipelineVariableOptions
+	for _, variable := range variables {
+		k, v, ok := strings.Cut(variable, "=")
+		if !ok || k == "" {
+			return nil, i18n.Errorf("%w: invalid variable: %q",
+				ErrInvalidOption, variable)
+		}
+		result = append(result, &gitlab.PipelineVariableOptions{
+			Key:   gitlab.Ptr(k),
+			Value: gitlab.Ptr(v),
+		})
+	}
+	return result, nil
+}
+
+// UpdatePipeline calls update for the pipeline of the project.  The
+// verb (e.g., "Retrying") is used in the progress message.  If dryRun
+// is true, update is not called, and this function only prints what
+// it would do.  The outcome is recorded in the result, and the error of
+// a failed update is also returned.
+func UpdatePipeline(
+	ctx context.Context,
+	result *Result,
+	p *gitlab.Project,
+	pipeline *gitlab.PipelineInfo,
+	verb string,
+	update func() error,
+	dryRun bool,
+) error {
+	name := pipelineName(p, pipeline.ID)
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- %s pipeline %q (%s on %q) ... ", verb, name, pipeline.Status, pipeline.Ref)
+	if !dryRun {
+		err := update()
+		if err != nil {
+			logging.Printf("Failed.\n")
+			hook.OnError(name, err)
+			result.Fail(name, pipeline, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	result.Succeed(name, pipeline)
+	return nil
+}
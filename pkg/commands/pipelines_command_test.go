@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestPipelinesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddPipeline("foo/alpha", "main", "success")
+	failed := server.AddPipeline("foo/alpha", "main", "failed")
+	latest := server.AddPipeline("foo/alpha", "feature", "failed")
+	server.AddPipeline("foo/beta", "main", "running")
+	server.AddPipeline("foo/beta", "main", "pending")
+	server.AddPipeline("foo/beta", "feature", "running")
+	server.AddPipeline("foo/test-gamma", "main", "success")
+	session := NewSessionWithClient(server.Client(t))
+
+	// run runs the "pipelines" subcommand and returns the names of
+	// the items that succeeded.
+	run := func(args ...string) []string {
+		cmd := NewPipelinesCommand("pipelines", &PipelinesOptions{}, session)
+		_, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("pipelines %v: unexpected error: %v", args, err)
+		}
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	// Verify the failed pipelines are listed from newest to oldest.
+	actual := run("list", "--group", "foo", "--status", "failed")
+	expected := []string{
+		fmt.Sprintf("foo/alpha/-/pipelines/%d", latest.ID),
+		fmt.Sprintf("foo/alpha/-/pipelines/%d", failed.ID),
+	}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("pipelines list: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Verify only the latest failed pipeline is retried and nothing
+	// changes for a dry run.
+	run("retry", "--group", "foo", "--dry-run")
+	run("retry", "--group", "foo")
+	expected = []string{"main:success", "main:failed", "feature:running"}
+	if actual := server.Pipelines("foo/alpha"); !slices.Equal(actual, expected) {
+		t.Errorf("pipelines retry: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Verify the running and pending pipelines for the ref are
+	// canceled.
+	run("cancel", "--group", "foo", "--ref", "main")
+	expected = []string{"main:canceled", "main:canceled", "feature:running"}
+	if actual := server.Pipelines("foo/beta"); !slices.Equal(actual, expected) {
+		t.Errorf("pipelines cancel: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Verify pipelines are triggered for the default branch with the
+	// variables.
+	run("trigger", "--group", "foo", "--expr", "gamma",
+		"--variable", "DEPLOY_ENV=staging", "--variable", "DEBUG=")
+	expected = []string{"main:success", "main:pending DEPLOY_ENV=staging DEBUG="}
+	if actual := server.Pipelines("foo/test-gamma"); !slices.Equal(actual, expected) {
+		t.Errorf("pipelines trigger: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Verify an invalid variable is rejected.
+	cmd := NewPipelinesCommand("pipelines", &PipelinesOptions{}, session)
+	_, err := cmd.Run(context.Background(),
+		[]string{"trigger", "--group", "foo", "--variable", "DEPLOY_ENV"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("pipelines trigger --variable DEPLOY_ENV: expected=%v  actual=%v",
+			ErrInvalidOption, err)
+	}
+}
@@ -0,0 +1,209 @@
+// This file provides the implementation for the "pipelines list"
+// command which lists the pipelines of the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// PipelinesListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// PipelinesListOptions are the options needed by this command.
+type PipelinesListOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Ref is the branch or tag of the pipelines to list.  Defaults
+	// to "" which matches any ref.
+	Ref string `xml:"ref"`
+
+	// Status is the status (e.g., "failed" or "running") of the
+	// pipelines to list.  Defaults to "" which matches any status.
+	Status string `xml:"status"`
+}
+
+// Initialize initializes this PipelinesListOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *PipelinesListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --ref
+	flags.StringVar(&opts.Ref, "ref", opts.Ref,
+		i18n.T("branch or tag of the pipelines to list"))
+
+	// --status
+	flags.StringVar(&opts.Status, "status", opts.Status,
+		i18n.T("status (e.g., \"failed\" or \"running\") of the pipelines to list"))
+}
+
+// ListOptions returns the options for listing the pipelines selected
+// by the ref and status.
+func (opts *PipelinesListOptions) ListOptions() *gitlab.ListProjectPipelinesOptions {
+	result := &gitlab.ListProjectPipelinesOptions{}
+	if opts.Ref != "" {
+		result.Ref = gitlab.Ptr(opts.Ref)
+	}
+	if opts.Status != "" {
+		result.Status = gitlab.Ptr(gitlab.BuildStateValue(opts.Status))
+	}
+	return result
+}
+
+////////////////////////////////////////////////////////////////////////
+// PipelinesListCommand
+////////////////////////////////////////////////////////////////////////
+
+// PipelinesListCommand implements the "pipelines list" command which
+// lists the pipelines of the projects in a group.
+type PipelinesListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[PipelinesListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *PipelinesListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] pipelines list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the pipelines of the selected projects from newest to\n")
+	i18n.Fprintf(out, "    oldest.  Each pipeline is printed as its path, status, and ref.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewPipelinesListCommand returns a new, initialized
+// PipelinesListCommand instance.
+func NewPipelinesListCommand(
+	name string,
+	opts *PipelinesListOptions,
+	session *Session,
+) *PipelinesListCommand {
+
+	// Create the new command.
+	cmd := &PipelinesListCommand{
+		GitlabCommand: GitlabCommand[PipelinesListOptions]{
+			BasicCommand: BasicCommand[PipelinesListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *PipelinesListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print the pipelines of each project.  For --output json, the
+	// pipelines are collected and printed together at the end.  A
+	// project whose pipelines cannot be listed is recorded as failed,
+	// and the remaining projects are still listed.
+	pipelines := []*gitlab.PipelineInfo{}
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			ps, err := gitlab_util.GetAllProjectPipelines(
+				ctx, cmd.client.Pipelines, p.ID, cmd.options.ListOptions())
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, pipeline := range ps {
+				if cmd.session.OutputJSON() {
+					pipelines = append(pipelines, pipeline)
+				} else {
+					fmt.Printf("%-50s  %-10s  %s\n",
+						pipelineName(p, pipeline.ID), pipeline.Status, pipeline.Ref)
+				}
+				result.Succeed(pipelineName(p, pipeline.ID), pipeline)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the pipelines as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, pipelines)
+		if err != nil {
+			return result, err
+		}
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not list the pipelines of %d project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,218 @@
+// This file provides the implementation for the "pipelines retry"
+// command which retries the latest failed pipeline of each of the
+// projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// PipelinesRetryOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// PipelinesRetryOptions are the options needed by this command.
+type PipelinesRetryOptions struct {
+
+	// Embed the options that control whether the remaining pipelines
+	// are retried after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Ref is the branch or tag of the pipelines to retry.  Defaults
+	// to "" which matches any ref.
+	Ref string `xml:"ref"`
+}
+
+// Initialize initializes this PipelinesRetryOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *PipelinesRetryOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --ref
+	flags.StringVar(&opts.Ref, "ref", opts.Ref,
+		i18n.T("branch or tag of the pipelines to retry"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// PipelinesRetryCommand
+////////////////////////////////////////////////////////////////////////
+
+// PipelinesRetryCommand implements the "pipelines retry" command which
+// retries the latest failed pipeline of each of the projects in a
+// group.
+type PipelinesRetryCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[PipelinesRetryOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *PipelinesRetryCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] pipelines retry [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Retry the failed jobs of the latest failed pipeline of each\n")
+	i18n.Fprintf(out, "    of the selected projects.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Retry Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewPipelinesRetryCommand returns a new, initialized
+// PipelinesRetryCommand instance.
+func NewPipelinesRetryCommand(
+	name string,
+	opts *PipelinesRetryOptions,
+	session *Session,
+) *PipelinesRetryCommand {
+
+	// Create the new command.
+	cmd := &PipelinesRetryCommand{
+		GitlabCommand: GitlabCommand[PipelinesRetryOptions]{
+			BasicCommand: BasicCommand[PipelinesRetryOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// RetryPipeline retries the failed jobs of the pipeline.
+func RetryPipeline(
+	ctx context.Context,
+	s gitlab_util.PipelineRetrier, /* was *gitlab.PipelinesService */
+	p *gitlab.Project,
+	pipeline *gitlab.PipelineInfo,
+) error {
+	_, _, err := s.RetryPipelineBuild(p.ID, pipeline.ID,
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("RetryPipeline: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *PipelinesRetryCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Retry the latest failed pipeline of each project.  Projects
+	// without a failed pipeline are skipped.  A project whose
+	// pipelines cannot be listed or retried is recorded as failed.  If
+	// --keep-going is set, the remaining projects are still retried.
+	listOpts := &gitlab.ListProjectPipelinesOptions{
+		Status: gitlab.Ptr(gitlab.Failed),
+	}
+	if cmd.options.Ref != "" {
+		listOpts.Ref = gitlab.Ptr(cmd.options.Ref)
+	}
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			pipeline, err := gitlab_util.GetLatestProjectPipeline(
+				ctx, cmd.client.Pipelines, p.ID, listOpts)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return cmd.options.Continue(err)
+			}
+			if pipeline == nil {
+				return true, nil
+			}
+			err = UpdatePipeline(ctx, result, p, pipeline, i18n.T("Retrying"),
+				func() error {
+					return RetryPipeline(ctx, cmd.client.Pipelines, p, pipeline)
+				},
+				cmd.options.DryRun)
+			if err != nil {
+				return cmd.options.Continue(err)
+			}
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err, "could not retry %d pipeline(s)")
+	return result, err
+}
@@ -0,0 +1,244 @@
+// This file provides the implementation for the "pipelines trigger"
+// command which runs a new pipeline in each of the projects in a
+// group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// PipelinesTriggerOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// PipelinesTriggerOptions are the options needed by this command.
+type PipelinesTriggerOptions struct {
+
+	// Embed the options that control whether pipelines are triggered
+	// in the remaining projects after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Ref is the branch or tag for which the pipelines are run.
+	// Defaults to "" which means the default branch of each project.
+	Ref string `xml:"ref"`
+
+	// Variables are the "KEY=VALUE" variables passed to the
+	// pipelines.  Defaults to empty.
+	Variables string_slice.StringSlice `xml:"variables>variable"`
+}
+
+// Initialize initializes this PipelinesTriggerOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *PipelinesTriggerOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --ref
+	flags.StringVar(&opts.Ref, "ref", opts.Ref,
+		i18n.T("branch or tag for which the pipelines are run "+
+			"(default is the default branch of each project)"))
+
+	// --variable
+	flags.Var(&opts.Variables, "variable",
+		i18n.T("KEY=VALUE variable passed to the pipelines which can be "+
+			"given multiple times"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// PipelinesTriggerCommand
+////////////////////////////////////////////////////////////////////////
+
+// PipelinesTriggerCommand implements the "pipelines trigger" command
+// which runs a new pipeline in each of the projects in a group.
+type PipelinesTriggerCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[PipelinesTriggerOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *PipelinesTriggerCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] pipelines trigger [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Run a new pipeline for the ref in each of the selected\n")
+	i18n.Fprintf(out, "    projects.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Trigger Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewPipelinesTriggerCommand returns a new, initialized
+// PipelinesTriggerCommand instance.
+func NewPipelinesTriggerCommand(
+	name string,
+	opts *PipelinesTriggerOptions,
+	session *Session,
+) *PipelinesTriggerCommand {
+
+	// Create the new command.
+	cmd := &PipelinesTriggerCommand{
+		GitlabCommand: GitlabCommand[PipelinesTriggerOptions]{
+			BasicCommand: BasicCommand[PipelinesTriggerOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// TriggerPipeline runs a new pipeline for the ref in the project
+// passing it the variables.  If ref is empty, the pipeline is run for
+// the default branch of the project.  If dryRun is true, this function
+// only prints what it would do without actually doing it.
+func TriggerPipeline(
+	ctx context.Context,
+	s gitlab_util.PipelineCreator, /* was *gitlab.PipelinesService */
+	p *gitlab.Project,
+	ref string,
+	variables []*gitlab.PipelineVariableOptions,
+	dryRun bool,
+) (*gitlab.Pipeline, error) {
+	if ref == "" {
+		ref = p.DefaultBranch
+	}
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(p.PathWithNamespace)
+	logging.Printf("- Triggering pipeline for %q on %q ... ", p.PathWithNamespace, ref)
+	if dryRun {
+		logging.Printf("Done.\n")
+		hook.OnItemDone(p.PathWithNamespace)
+		return nil, nil
+	}
+	opts := &gitlab.CreatePipelineOptions{
+		Ref: gitlab.Ptr(ref),
+	}
+	if len(variables) > 0 {
+		opts.Variables = &variables
+	}
+	pipeline, _, err := s.CreatePipeline(p.ID, opts,
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		logging.Printf("Failed.\n")
+		err = fmt.Errorf("TriggerPipeline: %w", gitlab_util.ClassifyError(err))
+		hook.OnError(p.PathWithNamespace, err)
+		return nil, err
+	}
+	logging.Printf("Done.  Pipeline: %d\n", pipeline.ID)
+	hook.OnItemDone(p.PathWithNamespace)
+	return pipeline, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *PipelinesTriggerCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	variables, err := ParsePipelineVariables(cmd.options.Variables)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Trigger a pipeline in each project.  A project in which the
+	// pipeline cannot be triggered is recorded as failed.  If
+	// --keep-going is set, the remaining projects are still triggered.
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			pipeline, err := TriggerPipeline(ctx, cmd.client.Pipelines, p,
+				cmd.options.Ref, variables, cmd.options.DryRun)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return cmd.options.Continue(err)
+			}
+			result.Succeed(p.PathWithNamespace, pipeline)
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err, "could not trigger %d pipeline(s)")
+	return result, err
+}
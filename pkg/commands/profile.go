@@ -0,0 +1,62 @@
+// This file provides the named profiles which let a single options.xml
+// file hold the connection settings for several Gitlab instances.  A
+// profile is selected with the global --profile option.
+
+package commands
+
+import (
+	"flag"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// Profile holds the global options needed to connect to one Gitlab
+// instance.  Options that are empty leave the global option
+// unchanged.  Options given explicitly on the command line override
+// the profile.
+type Profile struct {
+
+	// Name is the name by which the profile is selected.
+	Name string `xml:"name,attr"`
+
+	// AuthBackend overrides GlobalOptions.AuthBackend.
+	AuthBackend string `xml:"auth-backend"`
+
+	// AuthFileName overrides GlobalOptions.AuthFileName.
+	AuthFileName string `xml:"auth-file-name"`
+
+	// AuthProfile overrides GlobalOptions.AuthProfile.
+	AuthProfile string `xml:"auth-profile"`
+
+	// BaseURL overrides GlobalOptions.BaseURL.
+	BaseURL string `xml:"base-url"`
+}
+
+// FindProfile returns the profile with the name.  If more than one
+// profile has the name (e.g., because options files are layered), the
+// last one wins.
+func (opts *Options) FindProfile(name string) (*Profile, error) {
+	for i := len(opts.Profiles) - 1; i >= 0; i-- {
+		if opts.Profiles[i].Name == name {
+			return &opts.Profiles[i], nil
+		}
+	}
+	return nil, i18n.Errorf("%w: unknown profile: %q", ErrInvalidOption, name)
+}
+
+// ApplyProfile overrides the global options with the options set in
+// the profile except for those given explicitly in the flags which
+// must already have been parsed.
+func (opts *GlobalOptions) ApplyProfile(profile *Profile, flags *flag.FlagSet) {
+	explicit := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	apply := func(field *string, value string, flagName string) {
+		if value != "" && !explicit[flagName] {
+			*field = value
+		}
+	}
+	apply(&opts.AuthBackend, profile.AuthBackend, "auth-backend")
+	apply(&opts.AuthFileName, profile.AuthFileName, "auth")
+	apply(&opts.AuthProfile, profile.AuthProfile, "auth-profile")
+	apply(&opts.BaseURL, profile.BaseURL, "base-url")
+}
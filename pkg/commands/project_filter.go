@@ -0,0 +1,121 @@
+// This file provides the options shared by commands that filter the
+// selected projects by their last activity, archived state, and
+// visibility.
+
+package commands
+
+import (
+	"flag"
+	"slices"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+// projectVisibilities are the valid values of --visibility.
+var projectVisibilities = []string{"private", "internal", "public"}
+
+// ProjectFilterOptions filter the selected projects by their
+// metadata so commands can target projects like "public projects
+// without activity for two years" without matching their names.  They
+// are embedded in the options of the commands that support them.  The
+// options must be validated by Validate() before Matches() is called.
+type ProjectFilterOptions struct {
+
+	// Archived selects only the archived projects if true and only
+	// the projects that are not archived if false.  Defaults to nil
+	// which selects both.
+	Archived *bool `xml:"archived"`
+
+	// LastActivityBefore selects only the projects whose last
+	// activity is before the date (e.g., "2024-01-31") or longer ago
+	// than the age (e.g., "2y" or "90d").  Defaults to "" which
+	// selects projects regardless of their last activity.
+	LastActivityBefore string `xml:"last-activity-before"`
+
+	// Visibility selects only the projects with the visibility which
+	// is one of "private", "internal", or "public".  Defaults to ""
+	// which selects projects regardless of their visibility.
+	Visibility string `xml:"visibility"`
+
+	// cutoff is the time parsed from LastActivityBefore by
+	// Validate() or the zero time if it is not set.
+	cutoff time.Time
+}
+
+// Initialize initializes this ProjectFilterOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *ProjectFilterOptions) Initialize(flags *flag.FlagSet) {
+
+	// --archived
+	flags.BoolFunc("archived",
+		i18n.T("only select archived projects"),
+		func(string) error {
+			opts.Archived = gitlab.Ptr(true)
+			return nil
+		})
+
+	// --last-activity-before
+	flags.StringVar(&opts.LastActivityBefore, "last-activity-before", opts.LastActivityBefore,
+		i18n.T("only select projects whose last activity is before the date "+
+			"(e.g., \"2024-01-31\") or longer ago than the age (e.g., \"2y\" or \"90d\")"))
+
+	// --no-archived
+	flags.BoolFunc("no-archived",
+		i18n.T("only select projects that are not archived"),
+		func(string) error {
+			opts.Archived = gitlab.Ptr(false)
+			return nil
+		})
+
+	// --visibility
+	flags.StringVar(&opts.Visibility, "visibility", opts.Visibility,
+		i18n.T("only select projects with the visibility which is \"private\", "+
+			"\"internal\", or \"public\""))
+}
+
+// Validate returns an error if the options are invalid.  It also
+// computes the cutoff for LastActivityBefore relative to now.
+func (opts *ProjectFilterOptions) Validate(now time.Time) error {
+	opts.cutoff = time.Time{}
+	if opts.LastActivityBefore != "" {
+		var date date_arg.DateArg
+		if date.Set(opts.LastActivityBefore) == nil {
+			opts.cutoff = time.Time(date)
+		} else {
+			age, err := ParseAge(opts.LastActivityBefore)
+			if err != nil {
+				return i18n.Errorf("%w: invalid last-activity-before: %q",
+					ErrInvalidOption, opts.LastActivityBefore)
+			}
+			opts.cutoff = now.Add(-age)
+		}
+	}
+	if opts.Visibility != "" && !slices.Contains(projectVisibilities, opts.Visibility) {
+		return i18n.Errorf("%w: invalid visibility: %q",
+			ErrInvalidOption, opts.Visibility)
+	}
+	return nil
+}
+
+// Matches returns true if the project passes the filter.  Projects
+// without any recorded activity are treated as inactive.  It is safe
+// to call on a nil *ProjectFilterOptions which matches all projects.
+func (opts *ProjectFilterOptions) Matches(p *gitlab.Project) bool {
+	if opts == nil {
+		return true
+	}
+	if opts.Archived != nil && p.Archived != *opts.Archived {
+		return false
+	}
+	if opts.Visibility != "" && string(p.Visibility) != opts.Visibility {
+		return false
+	}
+	if !opts.cutoff.IsZero() &&
+		p.LastActivityAt != nil && !p.LastActivityAt.Before(opts.cutoff) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestProjectFilterIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	old := time.Now().AddDate(-3, 0, 0)
+	server.SetVisibility("foo/alpha", gitlab.PublicVisibility)
+	server.SetLastActivity("foo/alpha", old)
+	server.SetVisibility("foo/beta", gitlab.PublicVisibility)
+	server.SetLastActivity("foo/beta", time.Now().AddDate(0, 0, -1))
+	server.SetLastActivity("foo/test-gamma", old)
+	server.SetVisibility("foo/bar/delta", gitlab.PublicVisibility)
+	server.SetVisibility("foo/bar/test-epsilon", gitlab.PublicVisibility)
+	server.SetLastActivity("foo/bar/test-epsilon", old)
+	server.SetArchived("foo/bar/test-epsilon", true)
+	run := func(args ...string) (string, error) {
+		session := NewSessionWithClient(server.Client(t))
+		session.globalOpts.Yes = true
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		output, _, err := runCommand(t, cmd, args)
+		return output, err
+	}
+	list := func(args ...string) []string {
+		output, err := run(append([]string{"list", "--group", "foo", "-r"}, args...)...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v: %v", args, err)
+		}
+		return strings.Fields(output)
+	}
+
+	// List the public projects without activity for two years
+	// (including those that never had any activity).
+	stale := list("--visibility", "public", "--last-activity-before", "2y")
+	unarchived := list("--visibility", "public", "--last-activity-before", "2y",
+		"--no-archived")
+	byDate := list("--last-activity-before", old.AddDate(0, 0, 1).Format("2006-01-02"),
+		"--visibility", "private")
+
+	// Archive the stale public projects.
+	_, err := run("archive", "--group", "foo", "-r", "--visibility", "public",
+		"--last-activity-before", "2y", "--test-expr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testExpr := list("--archived")
+	_, err = run("archive", "--group", "foo", "-r", "--visibility", "public",
+		"--last-activity-before", "2y")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	archived := list("--archived")
+
+	// Delete the private projects.
+	_, err = run("delete", "--group", "foo", "-r", "--visibility", "private")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, invalidErr := run("archive", "--group", "foo", "--visibility", "secret")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"stale", []string{"foo/alpha", "foo/bar/delta", "foo/bar/test-epsilon"}, stale},
+		{"unarchived", []string{"foo/alpha", "foo/bar/delta"}, unarchived},
+		{"by date", []string{"foo/test-gamma"}, byDate},
+		{"test expr", []string{"foo/bar/test-epsilon"}, testExpr},
+		{"archived", []string{"foo/alpha", "foo/bar/delta", "foo/bar/test-epsilon"}, archived},
+		{"deleted",
+			[]string{"foo/alpha", "foo/beta", "foo/bar/delta", "foo/bar/test-epsilon"},
+			server.Projects()},
+		{"invalid visibility", true, errors.Is(invalidErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("project filter %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
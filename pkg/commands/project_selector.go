@@ -0,0 +1,191 @@
+// This file provides the options shared by commands that operate on a
+// selection of projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectSelectorOptions select projects by group, recursion, and
+// regular expression.  They are embedded in the options of the
+// commands that operate on a selection of projects so the options have
+// the same names and meaning everywhere.  Because the struct is
+// embedded, its XML elements appear directly in the options of the
+// embedding command in the options.xml file.
+type ProjectSelectorOptions struct {
+
+	// Anchored controls whether Expr must match the full path of
+	// the project instead of any part of it.  Defaults to false.
+	Anchored bool `xml:"anchored"`
+
+	// Expr is the regular expression that filters the projects by
+	// their full path.  Defaults to "".
+	Expr string `xml:"expr"`
+
+	// Group from which projects will be selected.  Defaults to "".
+	Group string `xml:"group"`
+
+	// IgnoreCase controls whether Expr matches case-insensitively.
+	// Defaults to false.
+	IgnoreCase bool `xml:"ignore-case"`
+
+	// Recursive controls whether the projects are selected
+	// recursively.  Defaults to false.
+	Recursive bool `xml:"recursive"`
+
+	// TestExpr should cause the command to only print the selected
+	// projects instead of running.  It can only be set on the command
+	// line so that it is never left on by accident.  Defaults to
+	// false.
+	TestExpr bool `xml:"-"`
+
+	// filter further filters the selected projects if not nil.  See
+	// SetFilter().
+	filter *ProjectFilterOptions
+}
+
+// SetFilter causes Validate() to also validate the filter and causes
+// the projects selected by ForEachProject(), GetAllProjects(), and
+// PrintSelectedProjects() to also be filtered by it.  It is called by
+// the commands that embed ProjectFilterOptions next to these options.
+func (opts *ProjectSelectorOptions) SetFilter(filter *ProjectFilterOptions) {
+	opts.filter = filter
+}
+
+// Initialize initializes this ProjectSelectorOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectSelectorOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored
+	flags.BoolVar(&opts.Anchored, "anchored", opts.Anchored,
+		i18n.T("whether --expr must match the full path of the project instead of any part of it"))
+
+	// --expr
+	flags.StringVar(&opts.Expr, "expr", opts.Expr,
+		i18n.T("regular expression that selects projects by full path "+
+			"which can start with inline flags like (?i)"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("group from which to select projects which can be the full path or the group ID"))
+
+	// --ignore-case
+	flags.BoolVar(&opts.IgnoreCase, "ignore-case", opts.IgnoreCase,
+		i18n.T("whether --expr matches case-insensitively"))
+
+	// -r
+	flags.BoolVar(&opts.Recursive, "r", opts.Recursive,
+		i18n.T("whether to recursively select projects"))
+
+	// --recursive
+	flags.BoolVar(&opts.Recursive, "recursive", opts.Recursive,
+		i18n.T("whether to recursively select projects"))
+
+	// --test-expr
+	flags.BoolVar(&opts.TestExpr, "test-expr", opts.TestExpr,
+		i18n.T("only print the selected projects instead of running the command"))
+}
+
+// EffectiveExpr returns Expr modified by Anchored and IgnoreCase.  An
+// empty Expr still matches all projects.
+func (opts *ProjectSelectorOptions) EffectiveExpr() string {
+	expr := opts.Expr
+	if expr == "" {
+		return ""
+	}
+	if opts.Anchored {
+		expr = "^(?:" + expr + ")$"
+	}
+	if opts.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	return expr
+}
+
+// Validate returns an error if the options cannot select any
+// projects.
+func (opts *ProjectSelectorOptions) Validate() error {
+	if opts.Group == "" {
+		return i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	_, err := regexp.Compile(opts.EffectiveExpr())
+	if err != nil {
+		return i18n.Errorf("%w: invalid expr: %q: %v",
+			ErrInvalidOption, opts.Expr, err)
+	}
+	if opts.filter != nil {
+		return opts.filter.Validate(time.Now())
+	}
+	return nil
+}
+
+// ForEachProject calls f once for each selected project.  The
+// function f must return true and no error to indicate that it wants
+// to continue being called with the remaining projects.  If f returns
+// an error, it will be forwarded to the caller as the error return
+// value for this function.
+func (opts *ProjectSelectorOptions) ForEachProject(
+	ctx context.Context,
+	s gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	f func(p *gitlab.Project) (bool, error),
+) error {
+	err := gitlab_util.ForEachProjectInGroup(
+		ctx, s, opts.Group, opts.EffectiveExpr(), opts.Recursive,
+		func(g *gitlab.Group, p *gitlab.Project) (bool, error) {
+			if !opts.filter.Matches(p) {
+				return true, nil
+			}
+			return f(p)
+		})
+	if err != nil {
+		return fmt.Errorf("ForEachProject: %w", err)
+	}
+	return nil
+}
+
+// GetAllProjects returns all the selected projects.  See
+// gitlab_util.GetAllProjects() for when to prefer this function over
+// ForEachProject().
+func (opts *ProjectSelectorOptions) GetAllProjects(
+	ctx context.Context,
+	s gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+) ([]*gitlab.Project, error) {
+	ps, err := gitlab_util.GetAllProjects(
+		ctx, s, opts.Group, opts.EffectiveExpr(), opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(ps, func(p *gitlab.Project) bool {
+		return !opts.filter.Matches(p)
+	}), nil
+}
+
+// PrintSelectedProjects prints the full path of each selected project
+// for --test-expr so the selection can be checked before running a
+// command that changes the projects.
+func (opts *ProjectSelectorOptions) PrintSelectedProjects(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+) error {
+	err := opts.ForEachProject(ctx, s, func(p *gitlab.Project) (bool, error) {
+		fmt.Println(p.PathWithNamespace)
+		result.Succeed(p.PathWithNamespace, p)
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("PrintSelectedProjects: %w", err)
+	}
+	return nil
+}
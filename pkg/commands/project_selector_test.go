@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProjectSelectorIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"--expr", "TEST"}, nil},
+		{[]string{"--expr", "TEST", "--ignore-case"},
+			[]string{"foo/test-gamma", "foo/bar/test-epsilon"}},
+		{[]string{"--expr", "(?i)TEST"},
+			[]string{"foo/test-gamma", "foo/bar/test-epsilon"}},
+		{[]string{"--expr", "test-.*", "--anchored"}, nil},
+		{[]string{"--expr", "foo/test-.*", "--anchored"}, []string{"foo/test-gamma"}},
+		{[]string{"--anchored"},
+			[]string{"foo/alpha", "foo/beta", "foo/test-gamma", "foo/bar/delta", "foo/bar/test-epsilon"}},
+	}
+	for _, d := range data {
+
+		// Use --test-expr with a destructive command to verify it
+		// only prints the selected projects.
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		var result *Result
+		var err error
+		output := captureStdout(t, func() {
+			result, err = cmd.Run(context.Background(),
+				append([]string{"delete", "--group", "foo", "-r", "--test-expr"}, d.args...))
+		})
+		if err != nil {
+			t.Fatalf("projects delete %v: unexpected error: %v", d.args, err)
+		}
+		var actual []string
+		for _, item := range result.Succeeded() {
+			actual = append(actual, item.Name)
+		}
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("projects delete %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+		if !slices.Equal(strings.Fields(output), d.expected) {
+			t.Errorf("projects delete %v: unexpected output: %q", d.args, output)
+		}
+		if len(server.Projects()) != 5 {
+			t.Fatalf("projects delete %v: projects were deleted: %v", d.args, server.Projects())
+		}
+	}
+
+	// Verify an invalid expression is rejected.
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+	_, err := cmd.Run(context.Background(),
+		[]string{"delete", "--group", "foo", "--expr", "(", "--test-expr"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("projects delete invalid expr: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+}
@@ -4,9 +4,9 @@
 // If you need to add a new subcommand, do the following:
 //
 //   1) Create the new subcommand similar to
-//      cmd/internal/commands/projects_command.go if the subcommand
+//      pkg/commands/projects_command.go if the subcommand
 //      will have its own set of subcommands or similar to
-//      cmd/internal/commands/projects_list_command.go if the
+//      pkg/commands/projects_list_command.go if the
 //      subcommand will actually do something.
 //
 //   2) Add the resulting new options struct to the Options struct
@@ -19,13 +19,14 @@
 package commands
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/xanzy/go-gitlab"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
 )
 
 ////////////////////////////////////////////////////////////////////////
@@ -77,11 +78,11 @@ func (cmd *ProjectsApprovalRulesCommand) Usage(out io.Writer, err error) {
 		fmt.Fprintf(out, "%v\n", err)
 	}
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out,
+	i18n.Fprintf(out,
 		"Usage: %s [global_options] projects approval-rules [subcmd]\n",
 		basename)
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out, "    Command for administering approval rules for Gitlab projects.\n")
+	i18n.Fprintf(out, "    Command for administering approval rules for Gitlab projects.\n")
 	fmt.Fprintf(out, "\n")
 	fmt.Fprintf(out, "Subcommands:\n")
 	fmt.Fprintf(out, "\n")
@@ -89,18 +90,14 @@ func (cmd *ProjectsApprovalRulesCommand) Usage(out io.Writer, err error) {
 		fmt.Fprintf(out, "  %s\n", subcmd)
 	}
 	fmt.Fprintf(out, "\n")
-	if out == os.Stderr {
-		os.Exit(1)
-	}
-	os.Exit(0)
 }
 
 // addSubcmds adds the subcommands for this command.
-func (cmd *ProjectsApprovalRulesCommand) addSubcmds(client *gitlab.Client) {
+func (cmd *ProjectsApprovalRulesCommand) addSubcmds(session *Session) {
 	cmd.subcmds["list"] = NewProjectsApprovalRulesListCommand(
-		"list", &cmd.options.ProjectsApprovalRulesListOpts, client)
+		"list", &cmd.options.ProjectsApprovalRulesListOpts, session)
 	cmd.subcmds["update"] = NewProjectsApprovalRulesUpdateCommand(
-		"update", &cmd.options.ProjectsApprovalRulesUpdateOpts, client)
+		"update", &cmd.options.ProjectsApprovalRulesUpdateOpts, session)
 }
 
 // NewProjectsApprovalRulesCommand returns a new, initialized
@@ -108,7 +105,7 @@ func (cmd *ProjectsApprovalRulesCommand) addSubcmds(client *gitlab.Client) {
 func NewProjectsApprovalRulesCommand(
 	name string,
 	opts *ProjectsApprovalRulesOptions,
-	client *gitlab.Client,
+	session *Session,
 ) *ProjectsApprovalRulesCommand {
 
 	// Create the new command.
@@ -116,35 +113,36 @@ func NewProjectsApprovalRulesCommand(
 		ParentCommand: ParentCommand[ProjectsApprovalRulesOptions]{
 			BasicCommand: BasicCommand[ProjectsApprovalRulesOptions]{
 				name:    name,
-				flags:   flag.NewFlagSet(name, flag.ExitOnError),
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
 				options: opts,
 			},
 			subcmds: make(map[string]Runner),
 		},
 	}
 
-	// Set up the function that prints the global usage and exits.
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
 	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
 
 	// Initialize our command-line options.
 	cmd.options.Initialize(cmd.flags)
 
 	// Add the subcommands.
-	cmd.addSubcmds(client)
+	cmd.addSubcmds(session)
 
 	return cmd
 }
 
 // Run is the entry point for this command.
-func (cmd *ProjectsApprovalRulesCommand) Run(args []string) error {
+func (cmd *ProjectsApprovalRulesCommand) Run(ctx context.Context, args []string) (*Result, error) {
 	var err error
 
 	// Parse command-line arguments.
 	err = cmd.flags.Parse(args)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Dispatch the subcommand specified by the remaining arguments.
-	return cmd.DispatchSubcommand(cmd.flags.Args())
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
 }
@@ -6,13 +6,15 @@
 package commands
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 
-	"github.com/jalitriver/gitlab-cmds/cmd/internal/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -51,20 +53,20 @@ func (opts *ProjectsApprovalRulesListOptions) Initialize(flags *flag.FlagSet) {
 
 	// --expr
 	flags.StringVar(&opts.Expr, "expr", opts.Expr,
-		"regular expression that selects projects for which approval "+
-			"rules will be listed")
+		i18n.T("regular expression that selects projects for which approval "+
+			"rules will be listed"))
 
 	// --group
 	flags.StringVar(&opts.Group, "group", opts.Group,
-		"group to list which can be the full path or the group ID")
+		i18n.T("group to list which can be the full path or the group ID"))
 
 	// -r
 	flags.BoolVar(&opts.Recursive, "r", opts.Recursive,
-		"whether to recursively find projects")
+		i18n.T("whether to recursively find projects"))
 
 	// --recursive
 	flags.BoolVar(&opts.Recursive, "recursive", opts.Recursive,
-		"whether to recursively find projects")
+		i18n.T("whether to recursively find projects"))
 }
 
 ////////////////////////////////////////////////////////////////////////
@@ -89,21 +91,17 @@ func (cmd *ProjectsApprovalRulesListCommand) Usage(out io.Writer, err error) {
 		fmt.Fprintf(out, "%v\n", err)
 	}
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out,
+	i18n.Fprintf(out,
 		"Usage: %s [global_options] projects approval-rules list [subcmd_options]\n",
 		basename)
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out, "    List approval rules on projects found recursively.\n")
+	i18n.Fprintf(out, "    List approval rules on projects found recursively.\n")
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out, "List Options:\n")
+	i18n.Fprintf(out, "List Options:\n")
 	fmt.Fprintf(out, "\n")
 	cmd.flags.SetOutput(out)
 	cmd.flags.PrintDefaults()
 	fmt.Fprintf(out, "\n")
-	if out == os.Stderr {
-		os.Exit(1)
-	}
-	os.Exit(0)
 }
 
 // NewProjectsApprovalRulesListCommand returns a new, initialized
@@ -111,7 +109,7 @@ func (cmd *ProjectsApprovalRulesListCommand) Usage(out io.Writer, err error) {
 func NewProjectsApprovalRulesListCommand(
 	name string,
 	opts *ProjectsApprovalRulesListOptions,
-	client *gitlab.Client,
+	session *Session,
 ) *ProjectsApprovalRulesListCommand {
 
 	// Create the new command.
@@ -119,14 +117,15 @@ func NewProjectsApprovalRulesListCommand(
 		GitlabCommand: GitlabCommand[ProjectsApprovalRulesListOptions]{
 			BasicCommand: BasicCommand[ProjectsApprovalRulesListOptions]{
 				name:    name,
-				flags:   flag.NewFlagSet(name, flag.ExitOnError),
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
 				options: opts,
 			},
-			client: client,
+			session: session,
 		},
 	}
 
-	// Set up the function that prints the global usage and exits.
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
 	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
 
 	// Initialize our command-line options.
@@ -136,33 +135,84 @@ func NewProjectsApprovalRulesListCommand(
 }
 
 // Run is the entry point for this command.
-func (cmd *ProjectsApprovalRulesListCommand) Run(args []string) error {
+func (cmd *ProjectsApprovalRulesListCommand) Run(ctx context.Context, args []string) (*Result, error) {
 	var err error
+	result := NewResult()
 
 	// Parse command-line arguments.
-	err = cmd.flags.Parse(args)
+	err = cmd.parseFlags(args)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	// Validate the options.
 	if cmd.options.Group == "" {
-		return fmt.Errorf("group not set")
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
 	}
 
-	// Print each approval rule for each project.
-	return gitlab_util.ForEachProjectInGroup(
+	// Print each approval rule for each project.  For --output json,
+	// the approval rules are collected and printed together at the
+	// end.
+	projects := []approvalRulesJSON{}
+	err = gitlab_util.ForEachProjectInGroup(
+		ctx,
 		cmd.client.Groups,
 		cmd.options.Group,
 		cmd.options.Expr,
 		cmd.options.Recursive,
 		func(g *gitlab.Group, p *gitlab.Project) (bool, error) {
-			fmt.Printf("%v\n", p.PathWithNamespace)
-			return true, gitlab_util.ForEachApprovalRuleInProject(
+			entry := approvalRulesJSON{
+				Project: p.PathWithNamespace,
+				Rules:   []*gitlab.ProjectApprovalRule{},
+			}
+			if !cmd.session.OutputJSON() {
+				fmt.Printf("%v\n", p.PathWithNamespace)
+			}
+			err := gitlab_util.ForEachApprovalRuleInProject(
+				ctx,
 				cmd.client.Projects, p,
 				func(rule *gitlab.ProjectApprovalRule) (bool, error) {
-					fmt.Printf("    %v\n", gitlab_util.ApprovalRuleToString(rule))
+					if cmd.session.OutputJSON() {
+						entry.Rules = append(entry.Rules, rule)
+					} else {
+						fmt.Printf("    %v\n", gitlab_util.ApprovalRuleToString(rule))
+					}
+					result.Succeed(approvalRuleName(p, rule), rule)
 					return true, nil
 				})
+			projects = append(projects, entry)
+			return true, err
 		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the approval rules as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, projects)
+	}
+	return result, err
+}
+
+// approvalRulesJSON is the JSON printed for each project for
+// --output json.
+type approvalRulesJSON struct {
+
+	// Project is the full path of the project.
+	Project string `json:"project"`
+
+	// Rules are the approval rules of the project.
+	Rules []*gitlab.ProjectApprovalRule `json:"rules"`
+}
+
+// approvalRuleName returns the name used to identify the approval
+// rule in a Result.
+func approvalRuleName(p *gitlab.Project, rule *gitlab.ProjectApprovalRule) string {
+	return fmt.Sprintf("%s:%d", p.PathWithNamespace, rule.ID)
 }
@@ -0,0 +1,426 @@
+// This file provides the implementation for the command "projects
+// approval-rules update" which updates approval rules in all projects
+// recursively found in a group where the projects are selected by a
+// regular expression.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/slice_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_users"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsApprovalRulesUpdateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsApprovalRulesUpdateOptions are the options needed by this command.
+type ProjectsApprovalRulesUpdateOptions struct {
+
+	// ApproversFileName is the name of the XML file holding the list
+	// of allowed approvers which should contain the output of the
+	// "glmcds users list" command which is the serialization of an
+	// [xml_users.XmlUsers] instance.
+	ApproversFileName string `xml:"approvers-file-name"`
+
+	// Embed the options that control how many projects are updated
+	// in parallel.
+	ConcurrencyOptions
+
+	// Diff should cause the command to print the approvers that would
+	// be removed from and added to each approval rule instead of
+	// updating the approval rules.  Defaults to false.
+	Diff bool `xml:"diff"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Expr is the regular expression that filters the projects.
+	// Defaults to "".
+	Expr string `xml:"expr"`
+
+	// Group for which projects will be updated.  Defaults to "".
+	Group string `xml:"group"`
+
+	// Embed the options that control whether the remaining approval
+	// rules are updated after a rule cannot be updated.
+	KeepGoingOptions
+
+	// Recursive controls whether the projects are found recursively.
+	// Defaults to false.
+	Recursive bool `xml:"recursive"`
+
+	// UndoFile is the file to which the undo plan is written so the
+	// updates can be undone by the "undo" command.  Defaults to ""
+	// which means no undo file is written.
+	UndoFile string `xml:"undo-file"`
+}
+
+// Initialize initializes this ProjectsApprovalRulesUpdateOptions
+// instance so it can be used with the "flag" package to parse the
+// command-line arguments.
+func (opts *ProjectsApprovalRulesUpdateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --approvers
+	flags.StringVar(&opts.ApproversFileName, "approvers", opts.ApproversFileName,
+		i18n.T("name of the XML file holding the list of allowed approvers which "+
+			"should contain the output of the \"glmcds users list\" command"))
+
+	// --concurrency
+	opts.ConcurrencyOptions.Initialize(flags)
+
+	// --diff
+	flags.BoolVar(&opts.Diff, "diff", opts.Diff,
+		i18n.T("print the approvers that would be removed from and added to "+
+			"each approval rule instead of updating the approval rules"))
+
+	// -n
+	flags.BoolVar(
+		&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --expr
+	flags.StringVar(&opts.Expr, "expr", opts.Expr,
+		i18n.T("regular expression that selects projects for which approval "+
+			"rules will be updated"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("group to update which can be the full path or the group ID"))
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -r
+	flags.BoolVar(&opts.Recursive, "r", opts.Recursive,
+		i18n.T("whether to recursively find projects"))
+
+	// --recursive
+	flags.BoolVar(&opts.Recursive, "recursive", opts.Recursive,
+		i18n.T("whether to recursively find projects"))
+
+	// --undo-file
+	flags.StringVar(&opts.UndoFile, "undo-file", opts.UndoFile,
+		i18n.T("file to which the undo plan is written for the \"undo\" command"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsApprovalRulesUpdateCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsApprovalRulesUpdateCommand implements the command "projects
+// approval-rules update" which updates approval rules in all projects
+// recursively found in a group where the projects are selected by a
+// regular expression.
+type ProjectsApprovalRulesUpdateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsApprovalRulesUpdateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsApprovalRulesUpdateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects approval-rules update [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Update approval rules on projects found recursively.\n")
+	i18n.Fprintf(out, "    Rules that already have the approvers are skipped.  With\n")
+	i18n.Fprintf(out, "    --diff, only the approvers that would be removed (-) and\n")
+	i18n.Fprintf(out, "    added (+) are printed for each rule.  The command stops at\n")
+	i18n.Fprintf(out, "    the first rule that cannot be updated unless --keep-going\n")
+	i18n.Fprintf(out, "    is passed in which case the rules that failed are listed\n")
+	i18n.Fprintf(out, "    at the end.  If --undo-file is set, the approvers of each\n")
+	i18n.Fprintf(out, "    rule before it is updated are recorded in it so the\n")
+	i18n.Fprintf(out, "    \"undo\" command can restore them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Update Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsApprovalRulesUpdateCommand returns a new, initialized
+// ProjectsApprovalRulesUpdateCommand instance.
+func NewProjectsApprovalRulesUpdateCommand(
+	name string,
+	opts *ProjectsApprovalRulesUpdateOptions,
+	session *Session,
+) *ProjectsApprovalRulesUpdateCommand {
+
+	// Create the new command.
+	cmd := &ProjectsApprovalRulesUpdateCommand{
+		GitlabCommand: GitlabCommand[ProjectsApprovalRulesUpdateOptions]{
+			BasicCommand: BasicCommand[ProjectsApprovalRulesUpdateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// diffApprovalRule prints the usernames of the approvers that would be
+// removed from (-) and added to (+) the approval rule if its approvers
+// were replaced by the sorted targetApproverUsernames.  The output is
+// written to out.
+func diffApprovalRule(
+	out io.Writer,
+	rule *gitlab.ProjectApprovalRule,
+	targetApproverUsernames []string,
+) {
+	oldApproverUsernames := gitlab_util.GetApprovalRuleUsernames(rule)
+	removedUsernames :=
+		slice_util.SubtractSlice(oldApproverUsernames, targetApproverUsernames)
+	addedUsernames :=
+		slice_util.SubtractSlice(targetApproverUsernames, oldApproverUsernames)
+	i18n.Fprintf(out, "    Rule %d (%q):\n", rule.ID, rule.Name)
+	if len(removedUsernames) == 0 && len(addedUsernames) == 0 {
+		i18n.Fprintf(out, "        No changes.\n")
+		return
+	}
+	for _, username := range removedUsernames {
+		fmt.Fprintf(out, "        - %s\n", username)
+	}
+	for _, username := range addedUsernames {
+		fmt.Fprintf(out, "        + %s\n", username)
+	}
+}
+
+// updateApprovalRule updates the approval rule for the project to
+// have the same values as before except with a new list of user IDs.
+// This function is designed to be the callback for
+// [ForEachApprovalRuleInProject()].  The progress messages are
+// written to out.  The update actually happens only if dryRun is not
+// set.
+func updateApprovalRule(
+	ctx context.Context,
+	out io.Writer,
+	s gitlab_util.ApprovalRuleUpdater, /* was *gitlab.ProjectsService */
+	projectID int,
+	rule *gitlab.ProjectApprovalRule,
+	targetUserIDs []int,
+	targetApproverUsernames []string,
+	dryRun bool,
+) error {
+	var err error
+	var newRule *gitlab.ProjectApprovalRule
+	var newApproverUsernames []string
+	var oldApproverUsernames []string
+
+	// Get the old list approvers.
+	oldApproverUsernames = gitlab_util.GetApprovalRuleUsernames(rule)
+
+	// Try to update the approval rule but only if this is not a dry
+	// run and only if the new list of approvers is not the same as
+	// the old list of approvers.
+	i18n.Fprintf(out, "    Updating rule %d (%q) ...\n", rule.ID, rule.Name)
+	if slices.Equal(targetApproverUsernames, oldApproverUsernames) {
+		i18n.Fprintf(out, "        Skipped.  Same approvers: %q\n",
+			oldApproverUsernames)		
+	} else {
+
+		// Update the approval rule if this is not a dry run.
+		if !dryRun {
+			newRule, err = gitlab_util.UpdateApprovalRule(
+				ctx, s, projectID, rule, targetUserIDs)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Print more information about the update to give the user
+		// confidence the correct update occurred or would have
+		// occurred if this is a dry run.
+		if !dryRun {
+			if newRule == nil {
+				return i18n.Errorf("UpdateApprovalRule() returned empty new rule")
+			}
+			newApproverUsernames = gitlab_util.GetApprovalRuleUsernames(newRule)
+		} else {
+			newApproverUsernames = targetApproverUsernames
+		}
+		if !slices.Equal(newApproverUsernames, targetApproverUsernames) {
+			return i18n.Errorf(
+				"new approvers (%q) not equal to target approvers (%q)",
+				newApproverUsernames, targetApproverUsernames)
+		}
+		removedUsernames :=
+			slice_util.SubtractSlice(oldApproverUsernames, newApproverUsernames)
+		i18n.Fprintf(out, "        Removed approvers (delta): %q\n", removedUsernames)
+		addedUsernames :=
+			slice_util.SubtractSlice(newApproverUsernames, oldApproverUsernames)
+		i18n.Fprintf(out, "        Added approvers (delta): %q\n", addedUsernames)
+	}
+
+	i18n.Fprintf(out, "        Done.\n")
+
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsApprovalRulesUpdateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+	var approvers []*xml_users.XmlUser
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.ApproversFileName == "" {
+		return result, i18n.Errorf("%w: approvers file name not set", ErrInvalidOption)
+	}
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	err = cmd.options.ConcurrencyOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Load list of approvers.
+	approvers, err = xml_users.ReadUsers(cmd.options.ApproversFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Get the sorted list of user IDs and usernames for the approvers.
+	var approverIDs []int
+	var approverUsernames []string
+	for _, approver := range approvers {
+		approverIDs = append(approverIDs, approver.ID)
+		approverUsernames = append(approverUsernames, approver.Username)
+	}
+	// Duplicates are removed so a rule whose approvers are already
+	// correct compares equal and is skipped.
+	slices.Sort(approverIDs)
+	approverIDs = slices.Compact(approverIDs)
+	slices.Sort(approverUsernames)
+	approverUsernames = slices.Compact(approverUsernames)
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Get the projects to update.
+	projects, err := gitlab_util.GetAllProjects(
+		ctx,
+		cmd.client.Groups,
+		cmd.options.Group,
+		cmd.options.Expr,
+		cmd.options.Recursive)
+	if err != nil {
+		return result, err
+	}
+
+	// Update each approval rule for each project using up to
+	// concurrency goroutines.  The output for each project is
+	// buffered and printed all at once so the output for different
+	// projects does not interleave.  Unless --keep-going is set, a
+	// rule that cannot be updated stops the remaining rules and
+	// projects from being updated.  Each rule is recorded in the undo
+	// plan before it is updated.
+	var stdoutMutex sync.Mutex
+	plan := NewUndoPlan("projects approval-rules update")
+	err = forEachConcurrently(ctx, projects, cmd.options.Concurrency,
+		cmd.options.KeepGoing,
+		func(p *gitlab.Project) error {
+			var out bytes.Buffer
+			defer func() {
+				stdoutMutex.Lock()
+				defer stdoutMutex.Unlock()
+				os.Stdout.Write(out.Bytes())
+			}()
+			fmt.Fprintf(&out, "%v\n", p.PathWithNamespace)
+			return gitlab_util.ForEachApprovalRuleInProject(
+				ctx,
+				cmd.client.Projects,
+				p,
+				func(rule *gitlab.ProjectApprovalRule) (bool, error) {
+					name := approvalRuleName(p, rule)
+					hook := gitlab_util.EventHookFromContext(ctx)
+					hook.OnItemStart(name)
+					if cmd.options.Diff {
+						diffApprovalRule(&out, rule, approverUsernames)
+						hook.OnItemDone(name)
+						result.Succeed(name, rule)
+						return true, nil
+					}
+					if !cmd.options.DryRun && !slices.Equal(
+						gitlab_util.GetApprovalRuleUsernames(rule), approverUsernames) {
+						plan.AddApprovalRule(p, rule)
+					}
+					err := updateApprovalRule(
+						ctx,
+						&out,
+						cmd.client.Projects,
+						p.ID,
+						rule,
+						approverIDs,
+						approverUsernames,
+						cmd.options.DryRun)
+					if err != nil {
+						hook.OnError(name, err)
+						result.Fail(name, rule, err)
+						return cmd.options.Continue(err)
+					}
+					hook.OnItemDone(name)
+					result.Succeed(name, rule)
+					return true, nil
+				})
+		})
+	err = saveUndoPlan(plan, cmd.options.UndoFile, err)
+	err = cmd.options.Finish(os.Stdout, result, err,
+		"could not update %d approval rule(s)")
+	return result, err
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestProjectsApprovalRulesDiffIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddApprovalRule("foo/alpha", "reviewers", 1, "aberns")
+	server.AddApprovalRule("foo/beta", "reviewers", 1, "bcrocket")
+	session := NewSessionWithClient(server.Client(t))
+
+	// Write the approvers file using "users list".
+	approversFileName := filepath.Join(t.TempDir(), "users.xml")
+	var err error
+	captureStdout(t, func() {
+		_, err = NewUsersCommand("users", &UsersOptions{}, session).Run(
+			context.Background(),
+			[]string{"list", "--users", "bcrocket", "-o", approversFileName})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Print the diff.
+	var result *Result
+	output := captureStdout(t, func() {
+		result, err = NewProjectsCommand("projects", &ProjectsOptions{}, session).Run(
+			context.Background(),
+			[]string{"approval-rules", "update", "--group", "foo",
+				"--expr", "alpha|beta", "--approvers", approversFileName, "--diff"})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the diff.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"rules", 2, len(result.Succeeded())},
+		{"alpha removed", true, strings.Contains(output,
+			fmt.Sprintf("foo/alpha\n    Rule %d (\"reviewers\"):\n        - aberns\n        + bcrocket\n",
+				server.ApprovalRules("foo/alpha")[0].ID))},
+		{"beta no changes", true, strings.Contains(output,
+			fmt.Sprintf("foo/beta\n    Rule %d (\"reviewers\"):\n        No changes.\n",
+				server.ApprovalRules("foo/beta")[0].ID))},
+		{"alpha unchanged", "[aberns]",
+			gitlab_util.GetApprovalRuleUsernames(server.ApprovalRules("foo/alpha")[0])},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("%s: expected=%v  actual=%v\n%s", d.name, d.expected, d.actual, output)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// This file provides the implementation for the "projects archive"
+// command which archives the selected projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsArchiveOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsArchiveOptions are the options needed by this command.
+type ProjectsArchiveOptions struct {
+
+	// Embed the options that control how many projects are archived
+	// in parallel.
+	ConcurrencyOptions
+
+	// Embed the options that control whether the remaining projects
+	// are archived after a project cannot be archived.
+	KeepGoingOptions
+
+	// Embed the options that filter the selected projects.
+	ProjectFilterOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this ProjectsArchiveOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsArchiveOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --archived, --last-activity-before, --no-archived, --visibility
+	opts.ProjectFilterOptions.Initialize(flags)
+	opts.ProjectSelectorOptions.SetFilter(&opts.ProjectFilterOptions)
+
+	// --concurrency
+	opts.ConcurrencyOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsArchiveCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsArchiveCommand implements the "projects archive" command
+// which archives the selected projects.
+type ProjectsArchiveCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsArchiveOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsArchiveCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects archive [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Archive the selected projects.  Besides --expr, the\n")
+	i18n.Fprintf(out, "    projects can be selected by --last-activity-before and\n")
+	i18n.Fprintf(out, "    --visibility (e.g., public projects without activity for\n")
+	i18n.Fprintf(out, "    two years).  Projects that are already archived are\n")
+	i18n.Fprintf(out, "    skipped.  The command stops at the first project that\n")
+	i18n.Fprintf(out, "    fails unless --keep-going is passed in which case the\n")
+	i18n.Fprintf(out, "    projects that failed are listed at the end.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Archive Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsArchiveCommand returns a new, initialized
+// ProjectsArchiveCommand instance.
+func NewProjectsArchiveCommand(
+	name string,
+	opts *ProjectsArchiveOptions,
+	session *Session,
+) *ProjectsArchiveCommand {
+
+	// Create the new command.
+	cmd := &ProjectsArchiveCommand{
+		GitlabCommand: GitlabCommand[ProjectsArchiveOptions]{
+			BasicCommand: BasicCommand[ProjectsArchiveOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ArchiveProject archives the project unless it is already archived.
+// If dryRun is true, this function only prints what it would without
+// actually doing it.
+func ArchiveProject(
+	ctx context.Context,
+	s gitlab_util.ProjectArchiver, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	dryRun bool,
+) error {
+	if p.Archived {
+		logging.Printf("- Project %q already archived.\n", p.PathWithNamespace)
+		return nil
+	}
+
+	// The progress line is printed all at once after the project is
+	// archived so the lines do not interleave when projects are
+	// archived in parallel.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(p.PathWithNamespace)
+	if !dryRun {
+		_, _, err := s.ArchiveProject(p.ID,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("- Archiving project %q ... Failed.\n", p.PathWithNamespace)
+			err = fmt.Errorf("ArchiveProject: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(p.PathWithNamespace, err)
+			return err
+		}
+	}
+	logging.Printf("- Archiving project %q ... Done.\n", p.PathWithNamespace)
+	hook.OnItemDone(p.PathWithNamespace)
+	return nil
+}
+
+// ArchiveProjects archives all the projects in a group (recursively
+// or not) for each project whose full path name matches the regular
+// expression and that passes the filter (which can be nil).  An empty
+// regular expression matches any string.  Up to concurrency projects
+// are archived in parallel.  If keepGoing is true, a project that
+// cannot be archived does not stop the remaining projects from being
+// archived, and all of the errors are returned together.  Otherwise,
+// no project is archived after the first one that fails.  If dryRun
+// is true, this function only prints what it would without actually
+// doing it.
+func ArchiveProjects(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	projects gitlab_util.ProjectArchiver, /* was *gitlab.ProjectsService */
+	group string,
+	expr string,
+	recursive bool,
+	filter *ProjectFilterOptions,
+	concurrency int,
+	keepGoing bool,
+	dryRun bool,
+) error {
+
+	// Collect projects.
+	logging.Printf("- Collecting projects ... ")
+	ps, err := gitlab_util.GetAllProjects(
+		ctx, groups, group, expr, recursive)
+	if err != nil {
+		return fmt.Errorf("ArchiveProjects: %w", err)
+	}
+	ps = slices.DeleteFunc(ps, func(p *gitlab.Project) bool {
+		return !filter.Matches(p)
+	})
+	logging.Printf("Done.\n")
+
+	// Archive projects using up to concurrency goroutines.
+	err = forEachConcurrently(ctx, ps, concurrency, keepGoing,
+		func(p *gitlab.Project) error {
+			err := ArchiveProject(ctx, projects, p, dryRun)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return err
+			}
+			result.Succeed(p.PathWithNamespace, p)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("ArchiveProjects: %w", err)
+	}
+
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsArchiveCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	err = cmd.options.ConcurrencyOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Archive projects.
+	err = ArchiveProjects(
+		ctx,
+		result,
+		cmd.client.Groups,
+		cmd.client.Projects,
+		cmd.options.Group,
+		cmd.options.EffectiveExpr(),
+		cmd.options.Recursive,
+		&cmd.options.ProjectFilterOptions,
+		cmd.options.Concurrency,
+		cmd.options.KeepGoing,
+		cmd.options.DryRun)
+	return result, cmd.options.Finish(os.Stdout, result, err,
+		"could not archive %d project(s)")
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProjectsArchiveKeepGoingIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+
+	// run runs "projects archive" with the arguments and returns its
+	// output.
+	run := func(args ...string) (string, *Result, error) {
+		cmd := NewProjectsArchiveCommand("archive", &ProjectsArchiveOptions{}, session)
+		var result *Result
+		var err error
+		output := captureStdout(t, func() {
+			result, err = cmd.Run(context.Background(),
+				append([]string{"--group", "foo", "-r"}, args...))
+		})
+		return output, result, err
+	}
+	archived := func() []string {
+		var result []string
+		for _, path := range server.Projects() {
+			if server.Project(path).Archived {
+				result = append(result, path)
+			}
+		}
+		return result
+	}
+
+	// Verify the command stops at the first project that fails.
+	server.InjectError("POST", "/projects/", http.StatusInternalServerError, 1)
+	_, result, err := run()
+	if err == nil {
+		t.Errorf("projects archive: expected error")
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"fail-fast failed", 1, len(result.Failed())},
+		{"fail-fast archived", []string(nil), archived()},
+	}
+
+	// Verify --keep-going archives the remaining projects and lists
+	// the failure at the end.
+	server.InjectError("POST", "/projects/", http.StatusInternalServerError, 1)
+	output, result, err := run("--keep-going", "--concurrency", "2")
+	data = append(data, Data{
+		{"keep-going error", true,
+			err != nil && strings.Contains(err.Error(), "could not archive 1 project(s)")},
+		{"keep-going summary", true, strings.Contains(output, "\nFailures:\n")},
+		{"keep-going failed", 1, len(result.Failed())},
+		{"keep-going archived", 4, len(archived())},
+	}...)
+
+	// Verify an invalid concurrency is rejected.
+	_, _, err = run("--concurrency", "0")
+	data = append(data, Data{
+		{"invalid concurrency", true, errors.Is(err, ErrInvalidOption)},
+	}...)
+
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("projects archive %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// This file provides the implementation for the "projects" command
+// which provides project related subcommands.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      ProjectsCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsOptions are the options needed by this command.
+type ProjectsOptions struct {
+	ProjectsApprovalRulesOpts ProjectsApprovalRulesOptions `xml:"approval-rules-options"`
+
+	ProjectsArchiveOpts ProjectsArchiveOptions `xml:"archive-options"`
+
+	ProjectsCopyMetadataOpts ProjectsCopyMetadataOptions `xml:"copy-metadata-options"`
+
+	ProjectsCreateOpts ProjectsCreateOptions `xml:"create-options"`
+
+	ProjectsCreateRandomOpts ProjectsCreateRandomOptions `xml:"create-random-options"`
+
+	ProjectsDeleteOpts ProjectsDeleteOptions `xml:"delete-options"`
+
+	ProjectsEnforceArchivePolicyOpts ProjectsEnforceArchivePolicyOptions `xml:"enforce-archive-policy-options"`
+
+	ProjectsIntegrationsOpts ProjectsIntegrationsOptions `xml:"integrations-options"`
+
+	ProjectsListOpts ProjectsListOptions `xml:"list-options"`
+
+	ProjectsMirrorsOpts ProjectsMirrorsOptions `xml:"mirrors-options"`
+
+	ProjectsPurgeTrashOpts ProjectsPurgeTrashOptions `xml:"purge-trash-options"`
+
+	ProjectsReportOpts ProjectsReportOptions `xml:"report-options"`
+
+	ProjectsScaffoldOpts ProjectsScaffoldOptions `xml:"scaffold-options"`
+
+	ProjectsSetAvatarOpts ProjectsSetAvatarOptions `xml:"set-avatar-options"`
+
+	ProjectsSyncIssueTemplatesOpts ProjectsSyncIssueTemplatesOptions `xml:"sync-issue-templates-options"`
+
+	ProjectsSyncMRTemplatesOpts ProjectsSyncMRTemplatesOptions `xml:"sync-mr-templates-options"`
+
+	ProjectsTransferOpts ProjectsTransferOptions `xml:"transfer-options"`
+
+	ProjectsVariablesOpts ProjectsVariablesOptions `xml:"variables-options"`
+}
+
+// Initialize initializes this ProjectsOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *ProjectsOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsCommand provides subcommands for Gitlab project related
+// maintenance.
+type ProjectsCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[ProjectsOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *ProjectsCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for administering a Gitlab projects.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *ProjectsCommand) addSubcmds(session *Session) {
+	cmd.subcmds["approval-rules"] = NewProjectsApprovalRulesCommand(
+		"approval-rules", &cmd.options.ProjectsApprovalRulesOpts, session)
+	cmd.subcmds["archive"] = NewProjectsArchiveCommand(
+		"archive", &cmd.options.ProjectsArchiveOpts, session)
+	cmd.subcmds["copy-metadata"] = NewProjectsCopyMetadataCommand(
+		"copy-metadata", &cmd.options.ProjectsCopyMetadataOpts, session)
+	cmd.subcmds["create"] = NewProjectsCreateCommand(
+		"create", &cmd.options.ProjectsCreateOpts, session)
+	cmd.subcmds["create-random"] = NewProjectsCreateRandomCommand(
+		"create-random", &cmd.options.ProjectsCreateRandomOpts, session)
+	cmd.subcmds["delete"] = NewProjectsDeleteCommand(
+		"delete", &cmd.options.ProjectsDeleteOpts, session)
+	cmd.subcmds["enforce-archive-policy"] = NewProjectsEnforceArchivePolicyCommand(
+		"enforce-archive-policy", &cmd.options.ProjectsEnforceArchivePolicyOpts, session)
+	cmd.subcmds["integrations"] = NewProjectsIntegrationsCommand(
+		"integrations", &cmd.options.ProjectsIntegrationsOpts, session)
+	cmd.subcmds["list"] = NewProjectsListCommand(
+		"list", &cmd.options.ProjectsListOpts, session)
+	cmd.subcmds["mirrors"] = NewProjectsMirrorsCommand(
+		"mirrors", &cmd.options.ProjectsMirrorsOpts, session)
+	cmd.subcmds["purge-trash"] = NewProjectsPurgeTrashCommand(
+		"purge-trash", &cmd.options.ProjectsPurgeTrashOpts, session)
+	cmd.subcmds["report"] = NewProjectsReportCommand(
+		"report", &cmd.options.ProjectsReportOpts, session)
+	cmd.subcmds["scaffold"] = NewProjectsScaffoldCommand(
+		"scaffold", &cmd.options.ProjectsScaffoldOpts, session)
+	cmd.subcmds["set-avatar"] = NewProjectsSetAvatarCommand(
+		"set-avatar", &cmd.options.ProjectsSetAvatarOpts, session)
+	cmd.subcmds["sync-issue-templates"] = NewProjectsSyncIssueTemplatesCommand(
+		"sync-issue-templates", &cmd.options.ProjectsSyncIssueTemplatesOpts, session)
+	cmd.subcmds["sync-mr-templates"] = NewProjectsSyncMRTemplatesCommand(
+		"sync-mr-templates", &cmd.options.ProjectsSyncMRTemplatesOpts, session)
+	cmd.subcmds["transfer"] = NewProjectsTransferCommand(
+		"transfer", &cmd.options.ProjectsTransferOpts, session)
+	cmd.subcmds["variables"] = NewProjectsVariablesCommand(
+		"variables", &cmd.options.ProjectsVariablesOpts, session)
+}
+
+// NewProjectsCommand returns a new, initialized ProjectsCommand
+// instance having the specified name.
+func NewProjectsCommand(
+	name string,
+	opts *ProjectsOptions,
+	session *Session,
+) *ProjectsCommand {
+
+	// Create the new command.
+	cmd := &ProjectsCommand{
+		ParentCommand: ParentCommand[ProjectsOptions]{
+			BasicCommand: BasicCommand[ProjectsOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
@@ -0,0 +1,447 @@
+// This file provides the implementation for the "projects
+// copy-metadata" command which copies the labels, milestones, and
+// issue boards of a template project to the selected projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsCopyMetadataOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsCopyMetadataOptions are the options needed by this command.
+type ProjectsCopyMetadataOptions struct {
+
+	// Embed the options that select the target projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// From is the template project whose metadata is copied which
+	// can be the full path or the project ID.  Defaults to "".
+	From string `xml:"from"`
+}
+
+// Initialize initializes this ProjectsCopyMetadataOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsCopyMetadataOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --from
+	flags.StringVar(&opts.From, "from", opts.From,
+		i18n.T("template project whose metadata is copied which can be the full path or the project ID"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsCopyMetadataCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsCopyMetadataCommand implements the "projects copy-metadata"
+// command which copies the labels, milestones, and issue boards of a
+// template project to the selected projects.
+type ProjectsCopyMetadataCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsCopyMetadataOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsCopyMetadataCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects copy-metadata [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Copies the labels, milestones, and issue boards of the\n")
+	i18n.Fprintf(out, "    --from project to the projects selected by --group,\n")
+	i18n.Fprintf(out, "    --expr, and --recursive.  Metadata that already exists\n")
+	i18n.Fprintf(out, "    (by name) in a target project is left alone.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Copy Metadata Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsCopyMetadataCommand returns a new, initialized
+// ProjectsCopyMetadataCommand instance.
+func NewProjectsCopyMetadataCommand(
+	name string,
+	opts *ProjectsCopyMetadataOptions,
+	session *Session,
+) *ProjectsCopyMetadataCommand {
+
+	// Create the new command.
+	cmd := &ProjectsCopyMetadataCommand{
+		GitlabCommand: GitlabCommand[ProjectsCopyMetadataOptions]{
+			BasicCommand: BasicCommand[ProjectsCopyMetadataOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectMetadata holds the metadata of a project that is copied by
+// CopyProjectMetadata().
+type ProjectMetadata struct {
+	Labels     []*gitlab.Label
+	Milestones []*gitlab.Milestone
+	Boards     []*gitlab.IssueBoard
+}
+
+// ProjectMetadataServices holds the services needed to copy project
+// metadata.
+type ProjectMetadataServices struct {
+	Labels     gitlab_util.LabelsManager      /* was *gitlab.LabelsService */
+	Milestones gitlab_util.MilestonesManager  /* was *gitlab.MilestonesService */
+	Boards     gitlab_util.IssueBoardsManager /* was *gitlab.IssueBoardsService */
+}
+
+// GetProjectMetadata returns the metadata of the project.  Only the
+// labels defined directly on the project are returned because
+// inherited group labels are available to every project in the group.
+func GetProjectMetadata(
+	ctx context.Context,
+	s *ProjectMetadataServices,
+	project interface{},
+) (*ProjectMetadata, error) {
+	var err error
+	var result ProjectMetadata
+	result.Labels, err = gitlab_util.GetAllLabels(ctx, s.Labels, project, false)
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectMetadata: %w", err)
+	}
+	result.Milestones, err = gitlab_util.GetAllMilestones(ctx, s.Milestones, project)
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectMetadata: %w", err)
+	}
+	result.Boards, err = gitlab_util.GetAllIssueBoards(ctx, s.Boards, project)
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectMetadata: %w", err)
+	}
+	return &result, nil
+}
+
+// copyMetadataItem prints and records copying one item of metadata
+// unless exists is true in which case the item is skipped.  The
+// function f does the actual copying unless dryRun is true.
+func copyMetadataItem(
+	ctx context.Context,
+	result *Result,
+	p *gitlab.Project,
+	kind string,
+	itemName string,
+	exists bool,
+	dryRun bool,
+	f func() error,
+) error {
+	name := p.PathWithNamespace + ":" + kind + ":" + itemName
+	if exists {
+		logging.Printf("- Skipping %s %q in project %q (already exists).\n",
+			i18n.T(kind), itemName, p.PathWithNamespace)
+		return nil
+	}
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- Copying %s %q to project %q ... ",
+		i18n.T(kind), itemName, p.PathWithNamespace)
+	if !dryRun {
+		err := f()
+		if err != nil {
+			err = fmt.Errorf(
+				"CopyProjectMetadata: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(name, err)
+			result.Fail(name, p, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	result.Succeed(name, p)
+	return nil
+}
+
+// CopyMetadataToProject copies the metadata to the project.  Labels
+// and milestones are matched by name, and issue boards are matched by
+// name and have any missing label lists added.  If dryRun is true,
+// this function only prints what it would without actually doing it.
+func CopyMetadataToProject(
+	ctx context.Context,
+	result *Result,
+	s *ProjectMetadataServices,
+	p *gitlab.Project,
+	metadata *ProjectMetadata,
+	dryRun bool,
+) error {
+
+	// Get the existing metadata.  Inherited labels are included so
+	// board lists can use them.
+	labels, err := gitlab_util.GetAllLabels(ctx, s.Labels, p.ID, true)
+	if err != nil {
+		return fmt.Errorf("CopyMetadataToProject: %w", err)
+	}
+	milestones, err := gitlab_util.GetAllMilestones(ctx, s.Milestones, p.ID)
+	if err != nil {
+		return fmt.Errorf("CopyMetadataToProject: %w", err)
+	}
+	boards, err := gitlab_util.GetAllIssueBoards(ctx, s.Boards, p.ID)
+	if err != nil {
+		return fmt.Errorf("CopyMetadataToProject: %w", err)
+	}
+
+	// Copy the labels.
+	for _, l := range metadata.Labels {
+		exists := slices.ContainsFunc(labels, func(e *gitlab.Label) bool {
+			return e.Name == l.Name
+		})
+		err = copyMetadataItem(ctx, result, p, "label", l.Name, exists, dryRun,
+			func() error {
+				created, _, err := s.Labels.CreateLabel(p.ID,
+					&gitlab.CreateLabelOptions{
+						Name:        &l.Name,
+						Color:       &l.Color,
+						Description: &l.Description,
+						Priority:    &l.Priority,
+					},
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+				if err == nil {
+					labels = append(labels, created)
+				}
+				return err
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Copy the milestones.
+	for _, m := range metadata.Milestones {
+		exists := slices.ContainsFunc(milestones, func(e *gitlab.Milestone) bool {
+			return e.Title == m.Title
+		})
+		err = copyMetadataItem(ctx, result, p, "milestone", m.Title, exists, dryRun,
+			func() error {
+				_, _, err := s.Milestones.CreateMilestone(p.ID,
+					&gitlab.CreateMilestoneOptions{
+						Title:       &m.Title,
+						Description: &m.Description,
+						StartDate:   m.StartDate,
+						DueDate:     m.DueDate,
+					},
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+				return err
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Copy the boards.
+	for _, b := range metadata.Boards {
+		i := slices.IndexFunc(boards, func(e *gitlab.IssueBoard) bool {
+			return e.Name == b.Name
+		})
+		var board *gitlab.IssueBoard
+		if i >= 0 {
+			board = boards[i]
+		}
+		err = copyMetadataItem(ctx, result, p, "board", b.Name, false, dryRun,
+			func() error {
+				var err error
+				if board == nil {
+					board, _, err = s.Boards.CreateIssueBoard(p.ID,
+						&gitlab.CreateIssueBoardOptions{Name: &b.Name},
+						gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+					if err != nil {
+						return err
+					}
+				}
+				return copyBoardLists(ctx, s, p, labels, b, board)
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyBoardLists adds the label lists of the template board that are
+// missing from the board.  Lists for labels that do not exist in the
+// project are skipped.
+func copyBoardLists(
+	ctx context.Context,
+	s *ProjectMetadataServices,
+	p *gitlab.Project,
+	labels []*gitlab.Label,
+	template *gitlab.IssueBoard,
+	board *gitlab.IssueBoard,
+) error {
+	for _, list := range template.Lists {
+		if list.Label == nil {
+			continue
+		}
+		if slices.ContainsFunc(board.Lists, func(e *gitlab.BoardList) bool {
+			return e.Label != nil && e.Label.Name == list.Label.Name
+		}) {
+			continue
+		}
+		i := slices.IndexFunc(labels, func(l *gitlab.Label) bool {
+			return l.Name == list.Label.Name
+		})
+		if i < 0 {
+			continue
+		}
+		_, _, err := s.Boards.CreateIssueBoardList(p.ID, board.ID,
+			&gitlab.CreateIssueBoardListOptions{LabelID: &labels[i].ID},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CopyProjectMetadata copies the labels, milestones, and issue boards
+// of the from project to each project selected by the selector except
+// the from project itself.  If dryRun is true, this function only
+// prints what it would without actually doing it.
+func CopyProjectMetadata(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	s *ProjectMetadataServices,
+	from string,
+	selector *ProjectSelectorOptions,
+	dryRun bool,
+) error {
+
+	// Collect the metadata to copy.
+	logging.Printf("- Collecting metadata from project %q ... ", from)
+	metadata, err := GetProjectMetadata(ctx, s, from)
+	if err != nil {
+		return fmt.Errorf("CopyProjectMetadata: %w", err)
+	}
+	logging.Printf("Done.\n")
+
+	// Copy the metadata to each selected project.
+	err = selector.ForEachProject(ctx, groups, func(p *gitlab.Project) (bool, error) {
+		if p.PathWithNamespace == from || strconv.Itoa(p.ID) == from {
+			return true, nil
+		}
+		err := CopyMetadataToProject(ctx, result, s, p, metadata, dryRun)
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("CopyProjectMetadata: %w", err)
+	}
+
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsCopyMetadataCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.From == "" {
+		return result, i18n.Errorf("%w: from not set", ErrInvalidOption)
+	}
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Copy the metadata.
+	err = CopyProjectMetadata(
+		ctx,
+		result,
+		cmd.client.Groups,
+		&ProjectMetadataServices{
+			Labels:     cmd.client.Labels,
+			Milestones: cmd.client.Milestones,
+			Boards:     cmd.client.Boards,
+		},
+		cmd.options.From,
+		&cmd.options.ProjectSelectorOptions,
+		cmd.options.DryRun)
+	return result, err
+}
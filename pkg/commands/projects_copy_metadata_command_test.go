@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProjectsCopyMetadataIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddProjectLabel("foo/alpha", "bug", "#ff0000")
+	server.AddProjectLabel("foo/alpha", "feature", "#00ff00")
+	server.AddMilestone("foo/alpha", "v1")
+	server.AddBoard("foo/alpha", "Development", "bug", "feature")
+	server.AddProjectLabel("foo/beta", "bug", "#ff0000")
+	server.AddBoard("foo/beta", "Development", "bug")
+	session := NewSessionWithClient(server.Client(t))
+
+	// Copy the metadata twice to make sure the second time does not
+	// duplicate anything.
+	for i := 0; i < 2; i++ {
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		_, _, err := runCommand(t, cmd, []string{"copy-metadata",
+			"--from", "foo/alpha", "--group", "foo"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Verify the metadata was copied.
+	type Data []struct {
+		name     string
+		expected []string
+		actual   []string
+	}
+	data := Data{}
+	for _, p := range []string{"foo/beta", "foo/test-gamma"} {
+		data = append(data, Data{
+			{p + " labels", []string{"bug", "feature"}, server.ProjectLabels(p)},
+			{p + " milestones", []string{"v1"}, server.Milestones(p)},
+			{p + " boards", []string{"Development:bug,feature"}, server.Boards(p)},
+		}...)
+	}
+	for _, d := range data {
+		if !slices.Equal(d.actual, d.expected) {
+			t.Errorf("projects copy-metadata %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// This file provides the implementation for the "projects create"
+// command which creates a single project.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsCreateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsCreateOptions are the options needed by this command.
+type ProjectsCreateOptions struct {
+
+	// DefaultBranch is the default branch of the new project.
+	// Defaults to "" which means Gitlab's default is used.
+	DefaultBranch string `xml:"default-branch"`
+
+	// Description is the description of the new project.  Defaults
+	// to "".
+	Description string `xml:"description"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Name is the human-readable name of the new project.  Defaults
+	// to "" which means the name is the same as the path.
+	Name string `xml:"name"`
+
+	// ParentGroup is the group where the project will be created.
+	// The parent group must already exist.  Defaults to "".
+	ParentGroup string `xml:"parent-group"`
+
+	// Path is the path of the new project relative to ParentGroup.
+	// Defaults to "".
+	Path string `xml:"path"`
+
+	// Visibility is the visibility of the new project which is
+	// "private", "internal", or "public".  Defaults to "private".
+	Visibility string `xml:"visibility"`
+}
+
+// Initialize initializes this ProjectsCreateOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *ProjectsCreateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --default-branch
+	flags.StringVar(&opts.DefaultBranch, "default-branch", opts.DefaultBranch,
+		i18n.T("default branch of the new project (default is Gitlab's default)"))
+
+	// --description
+	flags.StringVar(&opts.Description, "description", opts.Description,
+		i18n.T("description of the new project"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("name of the new project (default is the path)"))
+
+	// --parent-group
+	flags.StringVar(&opts.ParentGroup, "parent-group", opts.ParentGroup,
+		i18n.T("parent group for the new project"))
+
+	// --path
+	flags.StringVar(&opts.Path, "path", opts.Path,
+		i18n.T("path of the new project relative to the parent group"))
+
+	// --visibility
+	if opts.Visibility == "" {
+		opts.Visibility = string(gitlab.PrivateVisibility)
+	}
+	flags.StringVar(&opts.Visibility, "visibility", opts.Visibility,
+		i18n.T("visibility of the new project which is private, internal, or public"))
+}
+
+// Validate returns an error if the options are not valid.
+func (opts *ProjectsCreateOptions) Validate() error {
+	visibilities := []string{
+		string(gitlab.PrivateVisibility),
+		string(gitlab.InternalVisibility),
+		string(gitlab.PublicVisibility),
+	}
+	if opts.ParentGroup == "" {
+		return i18n.Errorf("%w: invalid parent group: %q",
+			ErrInvalidOption, opts.ParentGroup)
+	} else if opts.Path == "" {
+		return i18n.Errorf("%w: invalid path: %q",
+			ErrInvalidOption, opts.Path)
+	} else if !slices.Contains(visibilities, opts.Visibility) {
+		return i18n.Errorf("%w: invalid visibility: %q",
+			ErrInvalidOption, opts.Visibility)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsCreateCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsCreateCommand implements the "projects create" command which
+// creates a single project.
+type ProjectsCreateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsCreateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsCreateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects create [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create the project --path in --parent-group.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsCreateCommand returns a new, initialized
+// ProjectsCreateCommand instance.
+func NewProjectsCreateCommand(
+	name string,
+	opts *ProjectsCreateOptions,
+	session *Session,
+) *ProjectsCreateCommand {
+
+	// Create the new command.
+	cmd := &ProjectsCreateCommand{
+		GitlabCommand: GitlabCommand[ProjectsCreateOptions]{
+			BasicCommand: BasicCommand[ProjectsCreateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// CreateProject creates the project described by opts.  The new
+// project is returned or nil if dryRun is true in which case this
+// function only prints what it would do without actually doing it.
+func CreateProject(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.GroupFinder, /* was *gitlab.GroupsService */
+	projects gitlab_util.ProjectCreator, /* was *gitlab.ProjectsService */
+	opts *ProjectsCreateOptions,
+	dryRun bool,
+) (*gitlab.Project, error) {
+
+	// Get the parent group.
+	logging.Printf("- Searching for ID for parent group %q ... ", opts.ParentGroup)
+	g, err := gitlab_util.FindExactGroup(ctx, groups, opts.ParentGroup)
+	if err != nil {
+		return nil, fmt.Errorf("CreateProject: %w", err)
+	}
+	logging.Printf("Done.\n")
+	fullPath := g.FullPath + "/" + opts.Path
+
+	// Set up the options for creating the project.  Options that are
+	// not set are left to Gitlab's defaults.
+	createOpts := gitlab.CreateProjectOptions{
+		NamespaceID: gitlab.Ptr(g.ID),
+		Path:        gitlab.Ptr(opts.Path),
+		Visibility:  gitlab.Ptr(gitlab.VisibilityValue(opts.Visibility)),
+	}
+	if opts.Name != "" {
+		createOpts.Name = gitlab.Ptr(opts.Name)
+	}
+	if opts.DefaultBranch != "" {
+		createOpts.DefaultBranch = gitlab.Ptr(opts.DefaultBranch)
+	}
+	if opts.Description != "" {
+		createOpts.Description = gitlab.Ptr(opts.Description)
+	}
+
+	// Create the project.
+	var p *gitlab.Project
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(fullPath)
+	logging.Printf("- Creating project %q ... ", fullPath)
+	if !dryRun {
+		p, _, err = projects.CreateProject(&createOpts,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			err = fmt.Errorf("CreateProject: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(fullPath, err)
+			result.Fail(fullPath, nil, err)
+			return nil, err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(fullPath)
+	result.Succeed(fullPath, p)
+
+	return p, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsCreateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Create the project.
+	p, err := CreateProject(ctx, result, cmd.client.Groups, cmd.client.Projects,
+		cmd.options, cmd.options.DryRun)
+	if err != nil {
+		return result, err
+	}
+	if p != nil {
+		logging.Printf("- Project URL: %s\n", p.WebURL)
+	}
+
+	return result, nil
+}
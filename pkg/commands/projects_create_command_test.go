@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProjectsCreateIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+
+	// run runs "projects create" with a fresh command because the
+	// options persist between runs.
+	run := func(args ...string) error {
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		_, _, err := runCommand(t, cmd, append([]string{"create"}, args...))
+		return err
+	}
+
+	// Verify nothing is created for --dry-run.
+	err := run("--parent-group", "foo/bar", "--path", "svc", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if server.Project("foo/bar/svc") != nil {
+		t.Errorf("projects create --dry-run: project created")
+	}
+
+	// Create the project.
+	err = run("--parent-group", "foo/bar", "--path", "svc", "--name", "Service",
+		"--description", "The service", "--visibility", "internal",
+		"--default-branch", "trunk")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := server.Project("foo/bar/svc")
+	if p == nil {
+		t.Fatalf("projects create: project not created")
+	}
+	type Data []struct {
+		name     string
+		expected string
+		actual   string
+	}
+	data := Data{
+		{"name", "Service", p.Name},
+		{"description", "The service", p.Description},
+		{"visibility", "internal", string(p.Visibility)},
+		{"default-branch", "trunk", p.DefaultBranch},
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("projects create: %s: expected=%q  actual=%q",
+				d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify creating the same project again fails.
+	err = run("--parent-group", "foo/bar", "--path", "svc")
+	if err == nil {
+		t.Errorf("projects create: expected error for existing project")
+	}
+
+	// Verify invalid options are rejected.
+	for _, args := range [][]string{
+		{"--path", "svc"},
+		{"--parent-group", "foo/bar"},
+		{"--parent-group", "foo/bar", "--path", "svc2", "--visibility", "secret"},
+	} {
+		err = run(args...)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("projects create %v: expected ErrInvalidOption: %v", args, err)
+		}
+	}
+}
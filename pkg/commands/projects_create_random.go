@@ -4,6 +4,7 @@
 package commands
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
@@ -11,7 +12,9 @@ import (
 	"path/filepath"
 
 	"github.com/google/uuid"
-	"github.com/jalitriver/gitlab-cmds/cmd/internal/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
 	"github.com/xanzy/go-gitlab"
 )
 
@@ -31,6 +34,10 @@ import (
 // ProjectsCreateRandomOptions are the options needed by this command.
 type ProjectsCreateRandomOptions struct {
 
+	// Embed the options that control how many projects are created
+	// in parallel.
+	ConcurrencyOptions
+
 	// DryRun should cause the command to print what it would do
 	// instead of actually doing it.  Defaults to false.
 	DryRun bool `xml:"dry-run"`
@@ -53,26 +60,29 @@ type ProjectsCreateRandomOptions struct {
 // arguments.
 func (opts *ProjectsCreateRandomOptions) Initialize(flags *flag.FlagSet) {
 
+	// --concurrency
+	opts.ConcurrencyOptions.Initialize(flags)
+
 	// -n
 	flags.BoolVar(
 		&opts.DryRun, "n", opts.DryRun,
-		"print what it would do instead of actually doing it")
+		i18n.T("print what it would do instead of actually doing it"))
 
 	// --dry-run
 	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
-		"print what it would do instead of actually doing it")
+		i18n.T("print what it would do instead of actually doing it"))
 
 	// --parent-group
 	flags.StringVar(&opts.ParentGroup, "parent-group", opts.ParentGroup,
-		"parent group for new projects")
+		i18n.T("parent group for new projects"))
 
 	// --project-base-name
 	flags.StringVar(&opts.ProjectBaseName, "project-base-name", opts.ProjectBaseName,
-		"base name for new projects")
+		i18n.T("base name for new projects"))
 
 	// --project-count
 	flags.Uint64Var(&opts.ProjectCount, "project-count", opts.ProjectCount,
-		"number of new projects to create")
+		i18n.T("number of new projects to create"))
 }
 
 ////////////////////////////////////////////////////////////////////////
@@ -95,21 +105,17 @@ func (cmd *ProjectsCreateRandomCommand) Usage(out io.Writer, err error) {
 		fmt.Fprintf(out, "%v\n", err)
 	}
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out,
+	i18n.Fprintf(out,
 		"Usage: %s [global_options] projects create-random [subcmd_options]\n",
 		basename)
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out, "    Create projects en masse with random names.\n")
+	i18n.Fprintf(out, "    Create projects en masse with random names.\n")
 	fmt.Fprintf(out, "\n")
-	fmt.Fprintf(out, "Create-Random Options:\n")
+	i18n.Fprintf(out, "Create-Random Options:\n")
 	fmt.Fprintf(out, "\n")
 	cmd.flags.SetOutput(out)
 	cmd.flags.PrintDefaults()
 	fmt.Fprintf(out, "\n")
-	if out == os.Stderr {
-		os.Exit(1)
-	}
-	os.Exit(0)
 }
 
 // NewProjectsCreateRandomCommand returns a new, initialized
@@ -117,7 +123,7 @@ func (cmd *ProjectsCreateRandomCommand) Usage(out io.Writer, err error) {
 func NewProjectsCreateRandomCommand(
 	name string,
 	opts *ProjectsCreateRandomOptions,
-	client *gitlab.Client,
+	session *Session,
 ) *ProjectsCreateRandomCommand {
 
 	// Create the new command.
@@ -125,14 +131,15 @@ func NewProjectsCreateRandomCommand(
 		GitlabCommand: GitlabCommand[ProjectsCreateRandomOptions]{
 			BasicCommand: BasicCommand[ProjectsCreateRandomOptions]{
 				name:    name,
-				flags:   flag.NewFlagSet(name, flag.ExitOnError),
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
 				options: opts,
 			},
-			client: client,
+			session: session,
 		},
 	}
 
-	// Set up the function that prints the global usage and exits.
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
 	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
 
 	// Initialize our command-line options.
@@ -147,11 +154,12 @@ func NewProjectsCreateRandomCommand(
 // and a UUID.  If dryRun is true, this function only prints what it
 // would without actually doing it.
 func CreateRandomProject(
-	client *gitlab.Client,
+	ctx context.Context,
+	s gitlab_util.ProjectCreator, /* was *gitlab.ProjectsService */
 	parentGroup *gitlab.Group,
 	projectBaseName string,
 	dryRun bool,
-) error {
+) (string, error) {
 
 	// Create UUID and use it as the suffix for the new project name.
 	suffix := uuid.NewString()
@@ -168,74 +176,118 @@ func CreateRandomProject(
 		Visibility:           gitlab.Ptr(gitlab.PublicVisibility),
 	}
 
-	// Create the project.
-	fmt.Printf("- Creating project: %q ... ", fullPath)
+	// Create the project.  The progress line is printed all at once
+	// after the project is created so the lines do not interleave
+	// when projects are created in parallel.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(fullPath)
 	if !dryRun {
-		_, _, err := client.Projects.CreateProject(&opts)
+		_, _, err := s.CreateProject(&opts,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
 		if err != nil {
-			return fmt.Errorf("CreateProject: %w", err)
+			logging.Printf("- Creating project: %q ... Failed.\n", fullPath)
+			err = fmt.Errorf(
+				"CreateProject: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(fullPath, err)
+			return fullPath, err
 		}
 	}
-	fmt.Printf("Done.\n")
+	logging.Printf("- Creating project: %q ... Done.\n", fullPath)
+	hook.OnItemDone(fullPath)
 
-	return nil
+	return fullPath, nil
 }
 
 // CreateRandomProjects creates the specified number of projects in the
 // parent group.  The name of each project is a combination of the
-// project base name and a UUID.  If dryRun is true, this function
-// only prints what it would without actually doing it.
+// project base name and a UUID.  Up to concurrency projects are
+// created in parallel.  A project that cannot be created does not stop
+// the remaining projects from being created, and all of the errors are
+// returned together.  If dryRun is true, this function only prints
+// what it would without actually doing it.
 func CreateRandomProjects(
-	client *gitlab.Client,
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.GroupFinder, /* was *gitlab.GroupsService */
+	projects gitlab_util.ProjectCreator, /* was *gitlab.ProjectsService */
 	parentGroup string,
 	projectBaseName string,
 	projectCount uint64,
+	concurrency int,
 	dryRun bool,
 ) error {
 
 	// Get the parent group ID.
-	fmt.Printf("- Searching for ID for parent group %q ... ", parentGroup)
-	g, err := gitlab_util.FindExactGroup(client.Groups, parentGroup)
+	logging.Printf("- Searching for ID for parent group %q ... ", parentGroup)
+	g, err := gitlab_util.FindExactGroup(ctx, groups, parentGroup)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Done.\n")
-
-	// Create each project.
-	for i := uint64(0); i < projectCount; i++ {
-		err := CreateRandomProject(client, g, projectBaseName, dryRun)
-		if err != nil {
-			return err
-		}
+	logging.Printf("Done.\n")
+
+	// Create each project using up to concurrency goroutines.
+	indexes := make([]uint64, projectCount)
+	err = gitlab_util.ForEachConcurrently(ctx, indexes, concurrency,
+		func(uint64) error {
+			fullPath, err := CreateRandomProject(
+				ctx, projects, g, projectBaseName, dryRun)
+			if err != nil {
+				result.Fail(fullPath, nil, err)
+				return err
+			}
+			result.Succeed(fullPath, nil)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("CreateRandomProjects: %w", err)
 	}
 
 	return nil
 }
 
 // Run is the entry point for this command.
-func (cmd *ProjectsCreateRandomCommand) Run(args []string) error {
+func (cmd *ProjectsCreateRandomCommand) Run(ctx context.Context, args []string) (*Result, error) {
 	var err error
+	result := NewResult()
 
 	// Parse command-line arguments.
-	err = cmd.flags.Parse(args)
+	err = cmd.parseFlags(args)
 	if err != nil {
-		return err
+		return result, err
 	}
 
 	// Validate the options.
+	err = cmd.options.ConcurrencyOptions.Validate()
+	if err != nil {
+		return result, err
+	}
 	if cmd.options.ParentGroup == "" {
-		return fmt.Errorf("invalid parent group: %q", cmd.options.ParentGroup)
+		return result, i18n.Errorf("%w: invalid parent group: %q",
+			ErrInvalidOption, cmd.options.ParentGroup)
 	} else if cmd.options.ProjectBaseName == "" {
-		return fmt.Errorf("invalid project base name: %q", cmd.options.ProjectBaseName)
+		return result, i18n.Errorf("%w: invalid project base name: %q",
+			ErrInvalidOption, cmd.options.ProjectBaseName)
 	} else if cmd.options.ProjectCount == 0 {
-		return fmt.Errorf("invalid project count: %v", cmd.options.ProjectCount)
+		return result, i18n.Errorf("%w: invalid project count: %v",
+			ErrInvalidOption, cmd.options.ProjectCount)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
 	}
 
 	// Create random projects.
-	return CreateRandomProjects(
-		cmd.client,
+	err = CreateRandomProjects(
+		ctx,
+		result,
+		cmd.client.Groups,
+		cmd.client.Projects,
 		cmd.options.ParentGroup,
 		cmd.options.ProjectBaseName,
 		cmd.options.ProjectCount,
+		cmd.options.Concurrency,
 		cmd.options.DryRun)
+	return result, err
 }
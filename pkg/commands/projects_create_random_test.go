@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProjectsCreateRandomIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+
+	// Create the random projects.
+	_, _, err := runCommand(t, cmd, []string{"create-random", "--parent-group", "foo/bar",
+		"--project-base-name", "random", "--project-count", "3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the projects were created.
+	count := 0
+	for _, p := range server.Projects() {
+		if strings.HasPrefix(p, "foo/bar/random-") {
+			count++
+		}
+	}
+	if count != 3 {
+		t.Errorf("projects create-random: expected=%d  actual=%d", 3, count)
+	}
+}
@@ -0,0 +1,530 @@
+// This file provides the implementation for the "projects delete"
+// command which optionally deletes projects recursively (or not)
+// whose name matchs a regular expression.  Instead of deleting the
+// projects, the command can move them to a trash group from which
+// they are deleted later by the "projects purge-trash" command.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsDeleteOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsDeleteOptions are the options needed by this command.
+type ProjectsDeleteOptions struct {
+
+	// Embed the options that control how many projects are deleted
+	// in parallel.
+	ConcurrencyOptions
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// Embed the options that control whether the remaining projects
+	// are deleted after a project cannot be deleted.
+	KeepGoingOptions
+
+	// Embed the options that filter the selected projects.
+	ProjectFilterOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// TrashGroup is the group to which the projects are transferred
+	// (and then archived) instead of being deleted.  Defaults to ""
+	// which means the projects are deleted.
+	TrashGroup string `xml:"trash-group"`
+
+	// UndoFile is the file to which the undo plan is written so the
+	// deletion can be undone by the "undo" command.  Defaults to ""
+	// which means no undo file is written.
+	UndoFile string `xml:"undo-file"`
+}
+
+// Initialize initializes this ProjectsDeleteOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *ProjectsDeleteOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --archived, --last-activity-before, --no-archived, --visibility
+	opts.ProjectFilterOptions.Initialize(flags)
+	opts.ProjectSelectorOptions.SetFilter(&opts.ProjectFilterOptions)
+
+	// --concurrency
+	opts.ConcurrencyOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --trash-group
+	flags.StringVar(&opts.TrashGroup, "trash-group", opts.TrashGroup,
+		i18n.T("group to which projects are moved and archived instead of being deleted"))
+
+	// --undo-file
+	flags.StringVar(&opts.UndoFile, "undo-file", opts.UndoFile,
+		i18n.T("file to which the undo plan is written for the \"undo\" command"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsDeleteCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsDeleteCommand implements the "projects delete" command which
+// optionally recursively deletes projects in a group where the
+// deleted projects are selected by a regular expression.
+type ProjectsDeleteCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsDeleteOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsDeleteCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects delete [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Deletes projects recursively.  The projects can also be\n")
+	i18n.Fprintf(out, "    selected by --last-activity-before, --archived or\n")
+	i18n.Fprintf(out, "    --no-archived, and --visibility.  If --trash-group is set,\n")
+	i18n.Fprintf(out, "    the projects are transferred to the trash group and\n")
+	i18n.Fprintf(out, "    archived instead so they can be restored until they are\n")
+	i18n.Fprintf(out, "    deleted by the \"projects purge-trash\" command.  The\n")
+	i18n.Fprintf(out, "    projects are listed and must be confirmed unless the global\n")
+	i18n.Fprintf(out, "    --yes option is passed.  When deleting more projects than\n")
+	i18n.Fprintf(out, "    --confirm-threshold, --i-understand must be passed, and the\n")
+	i18n.Fprintf(out, "    group must be typed to confirm.  The command stops at the\n")
+	i18n.Fprintf(out, "    first project that fails unless --keep-going is passed in\n")
+	i18n.Fprintf(out, "    which case the projects that failed are listed at the end.\n")
+	i18n.Fprintf(out, "    If --undo-file is set, the projects are recorded in it so\n")
+	i18n.Fprintf(out, "    the \"undo\" command can move them back or recreate them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Delete Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsDeleteCommand returns a new, initialized
+// ProjectsDeleteCommand instance.
+func NewProjectsDeleteCommand(
+	name string,
+	opts *ProjectsDeleteOptions,
+	session *Session,
+) *ProjectsDeleteCommand {
+
+	// Create the new command.
+	cmd := &ProjectsDeleteCommand{
+		GitlabCommand: GitlabCommand[ProjectsDeleteOptions]{
+			BasicCommand: BasicCommand[ProjectsDeleteOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// DeleteProject deletes the project.  If dryRun is true, this
+// function only prints what it would without actually doing it.
+func DeleteProject(
+	ctx context.Context,
+	s gitlab_util.ProjectDeleter, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	dryRun bool,
+) error {
+	// The progress line is printed all at once after the project is
+	// deleted so the lines do not interleave when projects are
+	// deleted in parallel.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(p.PathWithNamespace)
+	if !dryRun {
+		_, err := s.DeleteProject(p.ID,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("- Deleting project: %q ... Failed.\n", p.PathWithNamespace)
+			err = fmt.Errorf(
+				"DeleteProject: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(p.PathWithNamespace, err)
+			return err
+		}
+	}
+	logging.Printf("- Deleting project: %q ... Done.\n", p.PathWithNamespace)
+	hook.OnItemDone(p.PathWithNamespace)
+	return nil
+}
+
+// ProjectPaths returns the full paths of the projects.
+func ProjectPaths(ps []*gitlab.Project) []string {
+	var result []string
+	for _, p := range ps {
+		result = append(result, p.PathWithNamespace)
+	}
+	return result
+}
+
+// DeleteProjects deletes all the projects in a group (recursively or
+// not) for each project whose full path name matches the regular
+// expression and that passes the filter (which can be nil).  An empty
+// regular expression matches any string.  If confirm is not nil, it
+// is called with the full paths of the projects before any are
+// deleted, and nothing is deleted if it returns an error.  Up to
+// concurrency projects are deleted in parallel.  If keepGoing is
+// true, a project that cannot be deleted does not stop the remaining
+// projects from being deleted, and all of the errors are returned
+// together.  Otherwise, no project is deleted after the first one
+// that fails.  If dryRun is true, this function only prints what it
+// would without actually doing it.
+func DeleteProjects(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	projects gitlab_util.ProjectDeleter, /* was *gitlab.ProjectsService */
+	group string,
+	expr string,
+	recursive bool,
+	filter *ProjectFilterOptions,
+	confirm func(names []string) error,
+	concurrency int,
+	keepGoing bool,
+	dryRun bool,
+) error {
+
+	// Collect projects.
+	logging.Printf("- Collecting projects ... ")
+	ps, err := gitlab_util.GetAllProjects(
+		ctx, groups, group, expr, recursive)
+	if err != nil {
+		return fmt.Errorf("DeleteProjects: %w", err)
+	}
+	ps = slices.DeleteFunc(ps, func(p *gitlab.Project) bool {
+		return !filter.Matches(p)
+	})
+	logging.Printf("Done.\n")
+
+	// Ask for confirmation.
+	if confirm != nil && !dryRun {
+		err = confirm(ProjectPaths(ps))
+		if err != nil {
+			return fmt.Errorf("DeleteProjects: %w", err)
+		}
+	}
+
+	// Delete projects using up to concurrency goroutines.
+	err = forEachConcurrently(ctx, ps, concurrency, keepGoing,
+		func(p *gitlab.Project) error {
+			err := DeleteProject(ctx, projects, p, dryRun)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return err
+			}
+			result.Succeed(p.PathWithNamespace, p)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("DeleteProjects: %w", err)
+	}
+
+	return nil
+}
+
+// trashTopicPrefix is the prefix of the topic that records when a
+// project was moved to the trash group.  It is followed by the time in
+// the trashTopicLayout format.
+const trashTopicPrefix = "trashed-"
+
+// trashTopicLayout is the layout of the time in the trash topic.
+const trashTopicLayout = "20060102T150405Z"
+
+// TrashTopic returns the topic that records that a project was moved
+// to the trash group at the time.
+func TrashTopic(t time.Time) string {
+	return trashTopicPrefix + t.UTC().Format(trashTopicLayout)
+}
+
+// TrashedAt returns the time the project was moved to the trash group
+// as recorded by its trash topic.  If the project does not have a
+// trash topic, the second return value is false.
+func TrashedAt(p *gitlab.Project) (time.Time, bool) {
+	for _, topic := range p.Topics {
+		if s, ok := strings.CutPrefix(topic, trashTopicPrefix); ok {
+			t, err := time.Parse(trashTopicLayout, s)
+			if err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ProjectTrasher is the set of gitlab.ProjectsService methods needed
+// to move projects to the trash group.
+type ProjectTrasher interface {
+	gitlab_util.ProjectArchiver
+	gitlab_util.ProjectEditor
+	gitlab_util.ProjectTransferrer
+}
+
+// TrashProject transfers the project to the trash group, records the
+// time in its topics, and archives it.  If dryRun is true, this
+// function only prints what it would without actually doing it.
+func TrashProject(
+	ctx context.Context,
+	s ProjectTrasher, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	trash *gitlab.Group,
+	now time.Time,
+	dryRun bool,
+) error {
+	// The progress line is printed all at once after the project is
+	// moved so the lines do not interleave when projects are moved in
+	// parallel.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(p.PathWithNamespace)
+	if !dryRun {
+		// The three calls are not interrupted so the project is never
+		// left in the trash group without its trash topic.
+		mctx := gitlab_util.Uninterruptible(ctx)
+		_, _, err := s.TransferProject(p.ID,
+			&gitlab.TransferProjectOptions{Namespace: trash.ID},
+			gitlab.WithContext(mctx))
+		if err == nil {
+			topics := append(p.Topics[:len(p.Topics):len(p.Topics)], TrashTopic(now))
+			_, _, err = s.EditProject(p.ID,
+				&gitlab.EditProjectOptions{Topics: &topics},
+				gitlab.WithContext(mctx))
+		}
+		if err == nil {
+			_, _, err = s.ArchiveProject(p.ID, gitlab.WithContext(mctx))
+		}
+		if err != nil {
+			logging.Printf("- Moving project %q to %q ... Failed.\n",
+				p.PathWithNamespace, trash.FullPath)
+			err = fmt.Errorf(
+				"TrashProject: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(p.PathWithNamespace, err)
+			return err
+		}
+	}
+	logging.Printf("- Moving project %q to %q ... Done.\n",
+		p.PathWithNamespace, trash.FullPath)
+	hook.OnItemDone(p.PathWithNamespace)
+	return nil
+}
+
+// TrashProjects is the same as DeleteProjects() except the projects
+// are moved to the trash group (which can be the group ID or its full
+// path) instead of being deleted.  Projects already in the trash group
+// or its subgroups are skipped.
+func TrashProjects(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	projects ProjectTrasher, /* was *gitlab.ProjectsService */
+	group string,
+	expr string,
+	recursive bool,
+	filter *ProjectFilterOptions,
+	trashGroup string,
+	concurrency int,
+	keepGoing bool,
+	dryRun bool,
+) error {
+
+	// Find the trash group.
+	trash, err := gitlab_util.FindExactGroup(ctx, groups, trashGroup)
+	if err != nil {
+		return fmt.Errorf("TrashProjects: %w", err)
+	}
+
+	// Collect projects.
+	logging.Printf("- Collecting projects ... ")
+	ps, err := gitlab_util.GetAllProjects(
+		ctx, groups, group, expr, recursive)
+	if err != nil {
+		return fmt.Errorf("TrashProjects: %w", err)
+	}
+	logging.Printf("Done.\n")
+
+	// Move projects to the trash group using up to concurrency
+	// goroutines.
+	now := time.Now()
+	ps = slices.DeleteFunc(ps, func(p *gitlab.Project) bool {
+		return strings.HasPrefix(p.PathWithNamespace, trash.FullPath+"/") ||
+			!filter.Matches(p)
+	})
+	err = forEachConcurrently(ctx, ps, concurrency, keepGoing,
+		func(p *gitlab.Project) error {
+			err := TrashProject(ctx, projects, p, trash, now, dryRun)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return err
+			}
+			result.Succeed(p.PathWithNamespace, p)
+			return nil
+		})
+	if err != nil {
+		return fmt.Errorf("TrashProjects: %w", err)
+	}
+
+	return nil
+}
+
+// writeUndoFile writes the projects that were processed to the undo
+// file (if any) and returns err unless the undo file cannot be
+// written.  Projects that failed are included because they may have
+// been partially changed, and undoing a project that was not changed
+// does nothing.
+func (cmd *ProjectsDeleteCommand) writeUndoFile(result *Result, err error) error {
+	if cmd.options.DryRun {
+		return err
+	}
+	plan := NewUndoPlan("projects delete")
+	for _, item := range result.Items {
+		if p, ok := item.Value.(*gitlab.Project); ok {
+			plan.AddProject(p, cmd.options.TrashGroup)
+		}
+	}
+	return saveUndoPlan(plan, cmd.options.UndoFile, err)
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsDeleteCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	err = cmd.options.ConcurrencyOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Move projects to the trash group.
+	if cmd.options.TrashGroup != "" {
+		err = TrashProjects(
+			ctx,
+			result,
+			cmd.client.Groups,
+			cmd.client.Projects,
+			cmd.options.Group,
+			cmd.options.EffectiveExpr(),
+			cmd.options.Recursive,
+			&cmd.options.ProjectFilterOptions,
+			cmd.options.TrashGroup,
+			cmd.options.Concurrency,
+			cmd.options.KeepGoing,
+			cmd.options.DryRun)
+		err = cmd.writeUndoFile(result, err)
+		return result, cmd.options.Finish(os.Stdout, result, err,
+			"could not move %d project(s) to the trash group")
+	}
+
+	// Delete projects.
+	err = DeleteProjects(
+		ctx,
+		result,
+		cmd.client.Groups,
+		cmd.client.Projects,
+		cmd.options.Group,
+		cmd.options.EffectiveExpr(),
+		cmd.options.Recursive,
+		&cmd.options.ProjectFilterOptions,
+		func(names []string) error {
+			return cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"), names,
+				cmd.options.Group, cmd.session.AssumeYes())
+		},
+		cmd.options.Concurrency,
+		cmd.options.KeepGoing,
+		cmd.options.DryRun)
+	err = cmd.writeUndoFile(result, err)
+	return result, cmd.options.Finish(os.Stdout, result, err,
+		"could not delete %d project(s)")
+}
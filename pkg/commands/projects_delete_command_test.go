@@ -0,0 +1,331 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Stubs
+////////////////////////////////////////////////////////////////////////
+
+type GitlabGroupsServiceStub struct {
+	groups   []*gitlab.Group
+	projects map[int][]*gitlab.Project
+}
+
+func (s *GitlabGroupsServiceStub) GetGroup(
+	gid interface{},
+	opt *gitlab.GetGroupOptions,
+	options ...gitlab.RequestOptionFunc,
+) (*gitlab.Group, *gitlab.Response, error) {
+	for _, g := range s.groups {
+		if g.ID == gid {
+			return g, &gitlab.Response{}, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("group not found: %v", gid)
+}
+
+func (s *GitlabGroupsServiceStub) ListGroups(
+	opt *gitlab.ListGroupsOptions,
+	options ...gitlab.RequestOptionFunc,
+) ([]*gitlab.Group, *gitlab.Response, error) {
+	return s.groups, &gitlab.Response{}, nil
+}
+
+func (s *GitlabGroupsServiceStub) ListGroupProjects(
+	gid interface{},
+	opt *gitlab.ListGroupProjectsOptions,
+	options ...gitlab.RequestOptionFunc,
+) ([]*gitlab.Project, *gitlab.Response, error) {
+	return s.projects[gid.(int)], &gitlab.Response{}, nil
+}
+
+type GitlabProjectsServiceStub struct {
+	deleted []int
+}
+
+func (s *GitlabProjectsServiceStub) DeleteProject(
+	pid interface{},
+	options ...gitlab.RequestOptionFunc,
+) (*gitlab.Response, error) {
+	s.deleted = append(s.deleted, pid.(int))
+	return &gitlab.Response{}, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestDeleteProjects(t *testing.T) {
+	groups := GitlabGroupsServiceStub{
+		groups: []*gitlab.Group{
+			{ID: 1, FullPath: "foo"},
+		},
+		projects: map[int][]*gitlab.Project{
+			1: {
+				{ID: 10, PathWithNamespace: "foo/keep"},
+				{ID: 11, PathWithNamespace: "foo/test-a"},
+				{ID: 12, PathWithNamespace: "foo/test-b"},
+			},
+		},
+	}
+
+	type Data []struct {
+		dryRun   bool
+		expected []int
+	}
+
+	data := Data{
+		{
+			dryRun:   true,
+			expected: nil,
+		},
+		{
+			dryRun:   false,
+			expected: []int{11, 12},
+		},
+	}
+
+	for _, d := range data {
+		projects := GitlabProjectsServiceStub{}
+		err := DeleteProjects(context.Background(), nil, &groups, &projects, "foo", "test-", false, nil, nil, 1, false, d.dryRun)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(projects.deleted, d.expected) {
+			t.Errorf("DeleteProjects: dryRun=%v  expected=%v  actual=%v",
+				d.dryRun, d.expected, projects.deleted)
+		}
+	}
+}
+
+func TestProjectsDeleteIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	session.globalOpts.Yes = true
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+
+	// Delete the test projects.
+	_, result, err := runCommand(t, cmd, []string{"delete", "--group", "foo", "-r", "--expr", "/test-"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the result holds the deleted projects.
+	var deleted []string
+	for _, item := range result.Succeeded() {
+		deleted = append(deleted, item.Name)
+	}
+	slices.Sort(deleted)
+	expectedDeleted := []string{"foo/bar/test-epsilon", "foo/test-gamma"}
+	if !slices.Equal(deleted, expectedDeleted) {
+		t.Errorf("projects delete result: expected=%v  actual=%v",
+			expectedDeleted, deleted)
+	}
+
+	// Verify only the test projects were deleted.
+	expected := []string{"foo/alpha", "foo/beta", "foo/bar/delta"}
+	actual := server.Projects()
+	if !slices.Equal(actual, expected) {
+		t.Errorf("projects delete: expected=%v  actual=%v", expected, actual)
+	}
+}
+
+func TestProjectsTrashIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddGroup("trash")
+	run := func(args ...string) error {
+		session := NewSessionWithClient(server.Client(t))
+		session.globalOpts.Yes = true
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		_, _, err := runCommand(t, cmd, args)
+		return err
+	}
+
+	// Move the test projects to the trash group.
+	err := run("delete", "--group", "foo", "--expr", "test", "-r",
+		"--trash-group", "trash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trashed := server.Projects()
+	_, hasTopic := TrashedAt(server.Project("trash/test-gamma"))
+
+	// Purging the trash should not delete the recently trashed
+	// projects until they are older than --older-than.
+	err = run("purge-trash", "--trash-group", "trash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	notPurged := server.Projects()
+	server.SetTopics("trash/test-gamma",
+		TrashTopic(time.Now().AddDate(0, 0, -31)))
+	err = run("purge-trash", "--trash-group", "trash", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dryRun := server.Projects()
+	err = run("purge-trash", "--trash-group", "trash")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	purged := server.Projects()
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"trashed",
+			[]string{"foo/alpha", "foo/beta", "trash/test-gamma", "foo/bar/delta", "trash/test-epsilon"},
+			trashed},
+		{"archived", true, server.Project("trash/test-epsilon").Archived},
+		{"topic", true, hasTopic},
+		{"not purged", trashed, notPurged},
+		{"dry run", trashed, dryRun},
+		{"purged",
+			[]string{"foo/alpha", "foo/beta", "foo/bar/delta", "trash/test-epsilon"},
+			purged},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("projects trash %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
+
+func TestProjectsDeleteConfirmationIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+
+	// run runs "projects delete" for the test projects with a
+	// confirmation threshold of 1 so that they must be confirmed.
+	run := func(confirmation string, args ...string) error {
+		cmd := NewProjectsDeleteCommand("delete", &ProjectsDeleteOptions{}, session)
+		cmd.confirmIn = strings.NewReader(confirmation)
+		var err error
+		captureStdout(t, func() {
+			_, err = cmd.Run(context.Background(),
+				append([]string{"--group", "foo", "-r", "--expr", "test",
+					"--confirm-threshold", "1"}, args...))
+		})
+		return err
+	}
+
+	type Data []struct {
+		name         string
+		confirmation string
+		args         []string
+		expected     error
+		projects     int
+	}
+	data := Data{
+		{"without --i-understand", "foo\n", nil, ErrNotConfirmed, 5},
+		{"wrong confirmation", "test\n", []string{"--i-understand"}, ErrNotConfirmed, 5},
+		{"dry run", "", []string{"--dry-run"}, nil, 5},
+		{"declined", "n\n", []string{"--confirm-threshold", "10"}, ErrNotConfirmed, 5},
+		{"confirmed", "foo\n", []string{"--i-understand"}, nil, 3},
+	}
+	for _, d := range data {
+		err := run(d.confirmation, d.args...)
+		if !errors.Is(err, d.expected) {
+			t.Errorf("projects delete %s: expected=%v  actual=%v",
+				d.name, d.expected, err)
+		}
+		if len(server.Projects()) != d.projects {
+			t.Errorf("projects delete %s: expected=%d projects  actual=%v",
+				d.name, d.projects, server.Projects())
+		}
+	}
+}
+
+func TestProjectsDeleteAssumeYesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	for i := 0; i < ConfirmationPreviewSize+2; i++ {
+		server.AddProject("foo/bar", fmt.Sprintf("test-%02d", i))
+	}
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Yes = true
+
+	// Delete the test projects without a confirmation.
+	cmd := NewProjectsDeleteCommand("delete", &ProjectsDeleteOptions{}, session)
+	var err error
+	output := captureStdout(t, func() {
+		_, err = cmd.Run(context.Background(),
+			[]string{"--group", "foo/bar", "--expr", "test-", "--confirm-threshold", "1"})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the preview was printed and the projects were deleted.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"count", true, strings.Contains(output,
+			fmt.Sprintf("About to delete %d item(s):", ConfirmationPreviewSize+3))},
+		{"first", true, strings.Contains(output, "  foo/bar/test-00\n")},
+		{"more", true, strings.Contains(output, "  ... and 3 more\n")},
+		{"projects", []string{"foo/alpha", "foo/beta", "foo/test-gamma", "foo/bar/delta"},
+			server.Projects()},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("projects delete %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
+
+func TestProjectsDeleteYesHoistedIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	for i := 0; i < ConfirmationPreviewSize+2; i++ {
+		server.AddProject("foo/bar", fmt.Sprintf("test-%02d", i))
+	}
+	t.Setenv("GITLAB_TOKEN", "token-1234567890")
+
+	// run runs "projects delete" over the confirmation threshold
+	// through the global command with the extra arguments.
+	run := func(args ...string) error {
+		cmd := NewGlobalCommand("glcmds", "0.0.0")
+		all := []string{"--options", "", "--base-url", server.URL,
+			"projects", "delete", "--group", "foo/bar",
+			"--expr", "test-", "--confirm-threshold", "1"}
+		_, _, err := runCommand(t, cmd, append(all, args...))
+		return err
+	}
+
+	// Verify the deletion is not confirmed without --yes because
+	// stdin is not a terminal.
+	err := run()
+	if !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("projects delete: unexpected error: %v", err)
+	}
+	if n := len(server.Projects()); n != 5+ConfirmationPreviewSize+2 {
+		t.Errorf("projects delete: expected=%v  actual=%v",
+			5+ConfirmationPreviewSize+2, n)
+	}
+
+	// Verify -y after the command is hoisted as the global option and
+	// skips the confirmation.
+	err = run("-y")
+	if err != nil {
+		t.Fatalf("projects delete -y: unexpected error: %v", err)
+	}
+	expected := []string{"foo/alpha", "foo/beta", "foo/test-gamma", "foo/bar/delta"}
+	if actual := server.Projects(); fmt.Sprint(expected) != fmt.Sprint(actual) {
+		t.Errorf("projects delete -y: expected=%v  actual=%v", expected, actual)
+	}
+}
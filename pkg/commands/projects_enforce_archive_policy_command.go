@@ -0,0 +1,348 @@
+// This file provides the implementation for the "projects
+// enforce-archive-policy" command which warns about and then archives
+// projects that have been inactive for longer than the policy allows.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsEnforceArchivePolicyOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsEnforceArchivePolicyOptions are the options needed by this
+// command.
+type ProjectsEnforceArchivePolicyOptions struct {
+
+	// Embed the options that control whether the remaining projects
+	// are checked after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// PolicyFileName is the name of the XML file that describes the
+	// archive policy.  Defaults to "".
+	PolicyFileName string `xml:"policy-file-name"`
+}
+
+// Initialize initializes this ProjectsEnforceArchivePolicyOptions
+// instance so it can be used with the "flag" package to parse the
+// command-line arguments.
+func (opts *ProjectsEnforceArchivePolicyOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --policy
+	flags.StringVar(&opts.PolicyFileName, "policy", opts.PolicyFileName,
+		i18n.T("XML file that describes the archive policy"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsEnforceArchivePolicyCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsEnforceArchivePolicyCommand implements the "projects
+// enforce-archive-policy" command which archives inactive projects.
+type ProjectsEnforceArchivePolicyCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsEnforceArchivePolicyOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsEnforceArchivePolicyCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects enforce-archive-policy [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Enforce the archive policy in the --policy file on the\n")
+	i18n.Fprintf(out, "    selected projects.  A project without activity for the\n")
+	i18n.Fprintf(out, "    number of months in the policy gets a warning issue.  If\n")
+	i18n.Fprintf(out, "    the project is still inactive when the command is run\n")
+	i18n.Fprintf(out, "    again after the grace period, it is archived.  If there\n")
+	i18n.Fprintf(out, "    has been activity, the warning is closed instead.  Run\n")
+	i18n.Fprintf(out, "    this command regularly (e.g., from a scheduled pipeline).\n")
+	i18n.Fprintf(out, "    The command stops at the first project that fails unless\n")
+	i18n.Fprintf(out, "    --keep-going is passed in which case the projects that\n")
+	i18n.Fprintf(out, "    failed are listed at the end.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Enforce-Archive-Policy Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsEnforceArchivePolicyCommand returns a new, initialized
+// ProjectsEnforceArchivePolicyCommand instance.
+func NewProjectsEnforceArchivePolicyCommand(
+	name string,
+	opts *ProjectsEnforceArchivePolicyOptions,
+	session *Session,
+) *ProjectsEnforceArchivePolicyCommand {
+
+	// Create the new command.
+	cmd := &ProjectsEnforceArchivePolicyCommand{
+		GitlabCommand: GitlabCommand[ProjectsEnforceArchivePolicyOptions]{
+			BasicCommand: BasicCommand[ProjectsEnforceArchivePolicyOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ArchivePolicyIssuesService is the set of gitlab.IssuesService
+// methods needed to file and close warnings.
+type ArchivePolicyIssuesService interface {
+	gitlab_util.IssueCreator
+	gitlab_util.IssueUpdater
+	gitlab_util.ProjectIssuesLister
+}
+
+// ArchivePolicyServices are the Gitlab services needed to enforce the
+// archive policy.
+type ArchivePolicyServices struct {
+	Groups   gitlab_util.ProjectsInGroupLister /* was *gitlab.GroupsService */
+	Issues   ArchivePolicyIssuesService        /* was *gitlab.IssuesService */
+	Projects gitlab_util.ProjectArchiver       /* was *gitlab.ProjectsService */
+}
+
+// archivePolicyActivitySlack is how long after the warning was filed
+// activity is attributed to filing the warning itself.  Gitlab only
+// updates the last activity of a project periodically.
+const archivePolicyActivitySlack = time.Hour
+
+// The actions taken by EnforceArchivePolicy().
+const (
+	ArchivePolicyNone     = ""
+	ArchivePolicyWarned   = "warned"
+	ArchivePolicyPending  = "pending"
+	ArchivePolicyArchived = "archived"
+	ArchivePolicyResumed  = "resumed"
+)
+
+// EnforceArchivePolicy enforces the policy on the project as of now
+// and returns the action that was taken which is one of the
+// ArchivePolicy* constants.  If dryRun is true, this function only
+// prints what it would without actually doing it.
+func EnforceArchivePolicy(
+	ctx context.Context,
+	s ArchivePolicyServices,
+	policy *ArchivePolicy,
+	p *gitlab.Project,
+	now time.Time,
+	dryRun bool,
+) (string, error) {
+
+	// Archived projects are already in compliance.
+	if p.Archived {
+		return ArchivePolicyNone, nil
+	}
+
+	// Find the warning, if any.
+	warnings, err := gitlab_util.GetAllProjectIssues(
+		ctx, s.Issues, p.ID, "opened", []string{policy.Label})
+	if err != nil {
+		return ArchivePolicyNone, fmt.Errorf("EnforceArchivePolicy: %w", err)
+	}
+
+	// File a warning if the project is inactive.
+	if len(warnings) == 0 {
+		cutoff := now.AddDate(0, -policy.InactiveMonths, 0)
+		if !IsDormantProject(p, cutoff) {
+			return ArchivePolicyNone, nil
+		}
+		logging.Printf("- Filing warning in %q ... ", p.PathWithNamespace)
+		if !dryRun {
+			labels := gitlab.LabelOptions{policy.Label}
+			_, _, err = s.Issues.CreateIssue(p.ID,
+				&gitlab.CreateIssueOptions{
+					Title:       gitlab.Ptr(policy.IssueTitle),
+					Description: gitlab.Ptr(policy.IssueDescription),
+					Labels:      &labels,
+				},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			if err != nil {
+				logging.Printf("Failed.\n")
+				return ArchivePolicyNone, fmt.Errorf(
+					"EnforceArchivePolicy: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+		return ArchivePolicyWarned, nil
+	}
+	warning := warnings[0]
+	warned := now
+	if warning.CreatedAt != nil {
+		warned = *warning.CreatedAt
+	}
+
+	// Close the warning if there has been activity since it was filed.
+	if p.LastActivityAt != nil &&
+		p.LastActivityAt.After(warned.Add(archivePolicyActivitySlack)) {
+		logging.Printf("- Closing warning in %q ... ", p.PathWithNamespace)
+		if !dryRun {
+			_, _, err = s.Issues.UpdateIssue(p.ID, warning.IID,
+				&gitlab.UpdateIssueOptions{StateEvent: gitlab.Ptr("close")},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			if err != nil {
+				logging.Printf("Failed.\n")
+				return ArchivePolicyNone, fmt.Errorf(
+					"EnforceArchivePolicy: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+		return ArchivePolicyResumed, nil
+	}
+
+	// Wait for the grace period to end.
+	if now.Before(warned.AddDate(0, 0, policy.GraceDays)) {
+		return ArchivePolicyPending, nil
+	}
+
+	// Archive the project.  The warning is left open as a record of
+	// why the project was archived.
+	logging.Printf("- Archiving %q ... ", p.PathWithNamespace)
+	if !dryRun {
+		_, _, err = s.Projects.ArchiveProject(p.ID,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return ArchivePolicyNone, fmt.Errorf(
+				"EnforceArchivePolicy: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return ArchivePolicyArchived, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsEnforceArchivePolicyCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.PolicyFileName == "" {
+		return result, i18n.Errorf("%w: policy not set", ErrInvalidOption)
+	}
+
+	// Load the policy.
+	policy, err := LoadArchivePolicy(cmd.options.PolicyFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Enforce the policy.  With --keep-going, failures for one project
+	// do not stop the policy from being enforced on the remaining
+	// projects.
+	s := ArchivePolicyServices{
+		Groups:   cmd.client.Groups,
+		Issues:   cmd.client.Issues,
+		Projects: cmd.client.Projects,
+	}
+	hook := gitlab_util.EventHookFromContext(ctx)
+	now := time.Now()
+	err = cmd.options.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			action, err := EnforceArchivePolicy(
+				ctx, s, policy, p, now, cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return cmd.options.Continue(err)
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			if action != ArchivePolicyNone {
+				result.Succeed(p.PathWithNamespace, action)
+			}
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err,
+		"could not enforce the archive policy on %d project(s)")
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProjectsEnforceArchivePolicyIntegration(t *testing.T) {
+	policyFileName := filepath.Join(t.TempDir(), "archive-policy.xml")
+	err := os.WriteFile(policyFileName, []byte(`
+<archive-policy>
+  <inactive-months>6</inactive-months>
+  <grace-days>0</grace-days>
+</archive-policy>`), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := newFakeServer(t)
+	server.SetLastActivity("foo/alpha", time.Now().AddDate(-1, 0, 0))
+	server.SetLastActivity("foo/beta", time.Now())
+	run := func(args ...string) (*Result, error) {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		var result *Result
+		var err error
+		captureStdout(t, func() {
+			result, err = cmd.Run(context.Background(),
+				append([]string{"enforce-archive-policy", "--group", "foo",
+					"--expr", "^foo/(alpha|beta|test-gamma)$", "--policy", policyFileName},
+					args...))
+		})
+		return result, err
+	}
+	actions := func(result *Result) []string {
+		var actions []string
+		for _, name := range []string{"foo/alpha", "foo/beta", "foo/test-gamma"} {
+			action := "-"
+			for _, item := range result.Succeeded() {
+				if item.Name == name {
+					action = fmt.Sprint(item.Value)
+				}
+			}
+			actions = append(actions, action)
+		}
+		return actions
+	}
+
+	// Run once with --dry-run and then for real to file the warnings.
+	result, err := run("--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dryRun := actions(result)
+	dryRunIssues := server.OpenIssues("foo/alpha")
+	result, err = run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	firstRun := actions(result)
+
+	// Resume activity in one project and run again to archive the
+	// other.
+	server.SetLastActivity("foo/test-gamma", time.Now().Add(2*time.Hour))
+	result, err = run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secondRun := actions(result)
+	result, err = run()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	thirdRun := actions(result)
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"dry run", []string{"warned", "-", "warned"}, dryRun},
+		{"dry run issues", []string(nil), dryRunIssues},
+		{"first run", []string{"warned", "-", "warned"}, firstRun},
+		{"second run", []string{"archived", "-", "resumed"}, secondRun},
+		{"third run", []string{"-", "-", "-"}, thirdRun},
+		{"archived", true, server.Project("foo/alpha").Archived},
+		{"not archived", false, server.Project("foo/test-gamma").Archived},
+		{"warning", []string{"This project will be archived due to inactivity"},
+			server.OpenIssues("foo/alpha")},
+		{"closed warning", []string(nil), server.OpenIssues("foo/test-gamma")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("projects enforce-archive-policy %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
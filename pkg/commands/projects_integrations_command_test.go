@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+)
+
+func TestProjectsIntegrationsIntegration(t *testing.T) {
+	settings := map[string]string{
+		"webhook":     "https://hooks.slack.com/services/T0/B0/X0",
+		"channel":     "builds",
+		"push_events": "true",
+	}
+	newServer := func() *fake_gitlab.Server {
+		server := newFakeServer(t)
+		server.SetIntegration("foo/alpha", "slack", settings)
+		server.SetIntegration("foo/beta", "slack", map[string]string{
+			"webhook":     "https://hooks.slack.com/services/T0/B0/X0",
+			"channel":     "old",
+			"push_events": "false",
+		})
+		return server
+	}
+	fileName := filepath.Join(t.TempDir(), "slack.xml")
+	err := os.WriteFile(fileName, []byte(`<integration-settings>
+  <setting name="webhook">https://hooks.slack.com/services/T0/B0/X0</setting>
+  <setting name="channel">builds</setting>
+  <setting name="push_events">true</setting>
+</integration-settings>
+`), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := func(server *fake_gitlab.Server, args ...string) string {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		out, _, err := runCommand(t, cmd, append([]string{
+			"integrations", "set", "--name", "slack", "--settings", fileName,
+			"--group", "foo", "--expr", "/(alpha|beta|test-gamma)$"}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+
+	// Set the integration with and without --dry-run.
+	dryRun := newServer()
+	dryRunOut := run(dryRun, "--dry-run")
+	applied := newServer()
+	run(applied)
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"already set", true, strings.Contains(dryRunOut,
+			`- Integration "slack" of "foo/alpha" already set.`)},
+		{"diff", true, strings.Contains(dryRunOut, `    channel: "old" -> "builds"`)},
+		{"unknown", true, strings.Contains(dryRunOut, `    active: "?" -> "true"`)},
+		{"masked", false, strings.Contains(dryRunOut, "hooks.slack.com")},
+		{"dry run", "old", dryRun.Integration("foo/beta", "slack")["channel"]},
+		{"dry run new", map[string]string(nil), dryRun.Integration("foo/test-gamma", "slack")},
+		{"existing", settings["channel"], applied.Integration("foo/alpha", "slack")["channel"]},
+		{"changed", "builds true", applied.Integration("foo/beta", "slack")["channel"] +
+			" " + applied.Integration("foo/beta", "slack")["push_events"]},
+		{"new", "builds true", applied.Integration("foo/test-gamma", "slack")["channel"] +
+			" " + applied.Integration("foo/test-gamma", "slack")["active"]},
+		{"unselected", map[string]string(nil), applied.Integration("foo/bar/delta", "slack")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("projects integrations set %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+// This file provides the implementation for the "projects integrations
+// set" command which configures an integration such as Slack
+// notifications or Jira with the same settings in each selected
+// project.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsIntegrationsSetOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsIntegrationsSetOptions are the options needed by this
+// command.
+type ProjectsIntegrationsSetOptions struct {
+
+	// Embed the options that control whether the remaining projects
+	// are configured after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Name is the name of the integration as used in the Gitlab REST
+	// API (e.g., "slack" or "jira").  Defaults to "".
+	Name string `xml:"name"`
+
+	// SettingsFileName is the name of the XML file holding the
+	// [IntegrationSettings].  Defaults to "".
+	SettingsFileName string `xml:"settings-file-name"`
+}
+
+// Initialize initializes this ProjectsIntegrationsSetOptions instance
+// so it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsIntegrationsSetOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("name of the integration as used in the Gitlab REST API (e.g., slack or jira)"))
+
+	// --settings
+	flags.StringVar(&opts.SettingsFileName, "settings", opts.SettingsFileName,
+		i18n.T("name of the XML file holding the settings of the integration"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsIntegrationsSetCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsIntegrationsSetCommand implements the "projects integrations
+// set" command which configures an integration in each project.
+type ProjectsIntegrationsSetCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsIntegrationsSetOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsIntegrationsSetCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects integrations set [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Configure and activate the --name integration with the\n")
+	i18n.Fprintf(out, "    settings in the --settings file in each selected project\n")
+	i18n.Fprintf(out, "    printing the settings that change.  The settings file has\n")
+	i18n.Fprintf(out, "    the following form where the names of the settings are the\n")
+	i18n.Fprintf(out, "    parameters of the integration in the Gitlab REST API:\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "        <integration-settings>\n")
+	fmt.Fprintf(out, "          <setting name=\"webhook\">https://...</setting>\n")
+	fmt.Fprintf(out, "          <setting name=\"push_events\">true</setting>\n")
+	fmt.Fprintf(out, "        </integration-settings>\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    The command stops at the first project that fails unless\n")
+	i18n.Fprintf(out, "    --keep-going is passed in which case the projects that\n")
+	i18n.Fprintf(out, "    failed are listed at the end.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Set Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsIntegrationsSetCommand returns a new, initialized
+// ProjectsIntegrationsSetCommand instance.
+func NewProjectsIntegrationsSetCommand(
+	name string,
+	opts *ProjectsIntegrationsSetOptions,
+	session *Session,
+) *ProjectsIntegrationsSetCommand {
+
+	// Create the new command.
+	cmd := &ProjectsIntegrationsSetCommand{
+		GitlabCommand: GitlabCommand[ProjectsIntegrationsSetOptions]{
+			BasicCommand: BasicCommand[ProjectsIntegrationsSetOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// secretSettingWords are the words that mark the name of a setting as
+// a secret whose value is never printed.
+var secretSettingWords = []string{"key", "password", "secret", "token", "webhook"}
+
+// isSecretSetting returns true if the value of the setting should
+// never be printed.
+func isSecretSetting(name string) bool {
+	for _, word := range secretSettingWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintIntegrationSettingChanges prints each change as "name: old ->
+// new".  Unknown values are printed as "?", and secrets are masked.
+func PrintIntegrationSettingChanges(out io.Writer, changes []*IntegrationSettingChange) {
+	for _, c := range changes {
+		old, value := c.Old, c.New
+		if !c.Known {
+			old = "?"
+		}
+		if isSecretSetting(c.Name) {
+			if c.Known {
+				old = "********"
+			}
+			value = "********"
+		}
+		fmt.Fprintf(out, "    %s: %q -> %q\n", c.Name, old, value)
+	}
+}
+
+// SetIntegration configures and activates the integration of the
+// project with the settings unless the integration is already active
+// and has the settings.  The settings that change are printed.  It
+// returns true if the integration was changed.  If dryRun is true,
+// this function only prints what it would without actually doing it.
+func SetIntegration(
+	ctx context.Context,
+	client *gitlab.Client,
+	p *gitlab.Project,
+	slug string,
+	settings *IntegrationSettings,
+	dryRun bool,
+) (bool, error) {
+
+	// Find the changes.
+	existing, err := gitlab_util.GetProjectIntegration(ctx, client, p.ID, slug)
+	if err != nil {
+		return false, fmt.Errorf("SetIntegration: %w", err)
+	}
+	changes := DiffIntegrationSettings(existing, settings)
+	if existing["active"] != "true" {
+		changes = append([]*IntegrationSettingChange{{
+			Name:  "active",
+			Old:   existing["active"],
+			Known: existing != nil,
+			New:   "true",
+		}}, changes...)
+	}
+	if len(changes) == 0 {
+		logging.Printf("- Integration %q of %q already set.\n", slug, p.PathWithNamespace)
+		return false, nil
+	}
+
+	// Set the integration.
+	logging.Printf("- Setting integration %q of %q ... ", slug, p.PathWithNamespace)
+	if !dryRun {
+		err = gitlab_util.SetProjectIntegration(ctx, client, p.ID, slug, settings.Map())
+		if err != nil {
+			return false, fmt.Errorf("SetIntegration: %w", err)
+		}
+	}
+	logging.Printf("Done.\n")
+	PrintIntegrationSettingChanges(os.Stdout, changes)
+	return true, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsIntegrationsSetCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Name == "" {
+		return result, i18n.Errorf("%w: name not set", ErrInvalidOption)
+	}
+	if cmd.options.SettingsFileName == "" {
+		return result, i18n.Errorf("%w: settings not set", ErrInvalidOption)
+	}
+
+	// Load the settings.
+	settings, err := LoadIntegrationSettings(cmd.options.SettingsFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Set the integration of each project.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			_, err := SetIntegration(ctx, cmd.client, p, cmd.options.Name,
+				settings, cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return cmd.options.Continue(err)
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			result.Succeed(p.PathWithNamespace, p)
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err,
+		"could not set the integration of %d project(s)")
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,387 @@
+// This file provides the implementation for the "projects list"
+// command which optionally recursively lists projects in a group
+// where the listed projects are selected by a regular expression.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsListOptions are the options needed by this command.
+type ProjectsListOptions struct {
+
+	// Embed the options that filter the listed projects.
+	ProjectFilterOptions
+
+	// Expr is the regular expression that filters the projects.
+	// Defaults to "".
+	Expr string `xml:"expr"`
+
+	// Fields are the columns (e.g., "visibility" or "storage-size")
+	// printed before the full path of each project.  See
+	// [ProjectListFields] for the valid fields.  Defaults to no
+	// fields which means only the full paths are printed.
+	Fields string_slice.StringSlice `xml:"fields>field"`
+
+	// GraphQL controls whether projects are listed using the GraphQL
+	// API instead of the REST API.  The GraphQL API needs far fewer
+	// round trips for large groups.  If the GraphQL API is
+	// unavailable, the REST API is used instead.  Defaults to false.
+	GraphQL bool `xml:"graphql"`
+
+	// Group for which projects will be listed.  Defaults to "".
+	Group string `xml:"group"`
+
+	// Recursive controls whether the projects are listed recursively.
+	// Defaults to false.
+	Recursive bool `xml:"recursive"`
+}
+
+// Initialize initializes this ProjectsListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *ProjectsListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --archived, --last-activity-before, --no-archived, --visibility
+	opts.ProjectFilterOptions.Initialize(flags)
+
+	// --expr
+	flags.StringVar(&opts.Expr, "expr", opts.Expr,
+		i18n.T("regular expression that selects projects to list"))
+
+	// --fields
+	flags.Var(&opts.Fields, "fields",
+		i18n.T("comma-separated list of the columns to print before the path "+
+			"which can be ")+strings.Join(ProjectListFields, ", "))
+
+	// --graphql
+	flags.BoolVar(&opts.GraphQL, "graphql", opts.GraphQL,
+		i18n.T("whether to use the GraphQL API instead of the REST API "+
+			"falling back to the REST API if GraphQL is unavailable"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("group to list which can be the full path or the group ID"))
+
+	// -r
+	flags.BoolVar(&opts.Recursive, "r", opts.Recursive,
+		i18n.T("whether to recursively list projects"))
+
+	// --recursive
+	flags.BoolVar(&opts.Recursive, "recursive", opts.Recursive,
+		i18n.T("whether to recursively list projects"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsListCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsListCommand implements the "projects list" command which
+// optionally recursively lists projects in a group where the listed
+// projects are selected by a regular expression.
+type ProjectsListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List projects recursively.  By default, only the full path\n")
+	i18n.Fprintf(out, "    of each project is printed.  Use --fields to also print\n")
+	i18n.Fprintf(out, "    columns like the visibility or last activity in an aligned\n")
+	i18n.Fprintf(out, "    table.  The \"storage-size\" field needs one more request\n")
+	i18n.Fprintf(out, "    per project to read its statistics which requires at least\n")
+	i18n.Fprintf(out, "    the Reporter role.  Fields the GraphQL API does not return\n")
+	i18n.Fprintf(out, "    cause --graphql to be ignored as do --last-activity-before\n")
+	i18n.Fprintf(out, "    and --visibility which filter the listed projects along\n")
+	i18n.Fprintf(out, "    with --archived and --no-archived.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsListCommand returns a new, initialized
+// ProjectsListCommand instance.
+func NewProjectsListCommand(
+	name string,
+	opts *ProjectsListOptions,
+	session *Session,
+) *ProjectsListCommand {
+
+	// Create the new command.
+	cmd := &ProjectsListCommand{
+		GitlabCommand: GitlabCommand[ProjectsListOptions]{
+			BasicCommand: BasicCommand[ProjectsListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectListFields are the valid values of --fields in the order in
+// which they are listed in the usage.
+var ProjectListFields = []string{
+	"id",
+	"default-branch",
+	"visibility",
+	"last-activity",
+	"archived",
+	"stars",
+	"open-issues",
+	"storage-size",
+}
+
+// projectListColumn describes how a field selected by --fields is
+// printed.
+type projectListColumn struct {
+
+	// heading is the (untranslated) heading of the column.
+	heading string
+
+	// right is whether the column is aligned to the right.
+	right bool
+
+	// graphQL is whether the GraphQL API returns the field.
+	graphQL bool
+
+	// value returns the value of the field for the project.
+	value func(p *gitlab.Project) string
+}
+
+// projectListColumns maps each of the ProjectListFields to its
+// column.
+var projectListColumns = map[string]projectListColumn{
+	"id": {"ID", true, true, func(p *gitlab.Project) string {
+		return strconv.Itoa(p.ID)
+	}},
+	"default-branch": {"DEFAULT BRANCH", false, false, func(p *gitlab.Project) string {
+		return p.DefaultBranch
+	}},
+	"visibility": {"VISIBILITY", false, false, func(p *gitlab.Project) string {
+		return string(p.Visibility)
+	}},
+	"last-activity": {"LAST ACTIVITY", false, false, func(p *gitlab.Project) string {
+		if p.LastActivityAt == nil {
+			return "-"
+		}
+		return p.LastActivityAt.Format("2006-01-02")
+	}},
+	"archived": {"ARCHIVED", false, true, func(p *gitlab.Project) string {
+		return strconv.FormatBool(p.Archived)
+	}},
+	"stars": {"STARS", true, false, func(p *gitlab.Project) string {
+		return strconv.Itoa(p.StarCount)
+	}},
+	"open-issues": {"OPEN ISSUES", true, false, func(p *gitlab.Project) string {
+		return strconv.Itoa(p.OpenIssuesCount)
+	}},
+	"storage-size": {"STORAGE", true, false, func(p *gitlab.Project) string {
+		if p.Statistics == nil {
+			return "-"
+		}
+		return FormatSize(p.Statistics.StorageSize)
+	}},
+}
+
+// ValidateProjectListFields returns an error if any of the fields is
+// not one of the ProjectListFields.
+func ValidateProjectListFields(fields []string) error {
+	for _, field := range fields {
+		if _, ok := projectListColumns[field]; !ok {
+			return i18n.Errorf("%w: invalid field: %q", ErrInvalidOption, field)
+		}
+	}
+	return nil
+}
+
+// PrintProjectTable prints the fields of each project followed by its
+// full path as a table whose columns are aligned.  The fields must
+// have been validated by ValidateProjectListFields().
+func PrintProjectTable(out io.Writer, fields []string, projects []*gitlab.Project) {
+
+	// Format the cells and measure the width of each column.
+	rows := [][]string{{}}
+	for _, field := range fields {
+		rows[0] = append(rows[0], i18n.T(projectListColumns[field].heading))
+	}
+	for _, p := range projects {
+		var row []string
+		for _, field := range fields {
+			row = append(row, projectListColumns[field].value(p))
+		}
+		rows = append(rows, row)
+	}
+	widths := make([]int, len(fields))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	// Print the rows with the path in the last column.
+	for i, row := range rows {
+		var line strings.Builder
+		for j, cell := range row {
+			if projectListColumns[fields[j]].right {
+				fmt.Fprintf(&line, "%*s  ", widths[j], cell)
+			} else {
+				fmt.Fprintf(&line, "%-*s  ", widths[j], cell)
+			}
+		}
+		if i == 0 {
+			line.WriteString(i18n.T("PROJECT"))
+		} else {
+			line.WriteString(projects[i-1].PathWithNamespace)
+		}
+		fmt.Fprintf(out, "%s\n", line.String())
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	fields := cmd.options.Fields
+	err = ValidateProjectListFields(fields)
+	if err != nil {
+		return result, err
+	}
+	filter := &cmd.options.ProjectFilterOptions
+	err = filter.Validate(time.Now())
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Callback that prints each project.  For --output json or
+	// --fields, the projects are collected and printed together at the
+	// end so the columns can be aligned.  The statistics are read for
+	// each project only if they are needed.
+	projects := []*gitlab.Project{}
+	collect := cmd.session.OutputJSON() || len(fields) > 0
+	statistics := slices.Contains(fields, "storage-size")
+	printProject := func(g *gitlab.Group, p *gitlab.Project) (bool, error) {
+		if !filter.Matches(p) {
+			return true, nil
+		}
+		if statistics {
+			size, err := GetProjectSize(ctx, cmd.client.Projects, p)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return false, err
+			}
+			p.Statistics = &size.Statistics
+		}
+		if collect {
+			projects = append(projects, p)
+		} else {
+			fmt.Printf("%v\n", p.PathWithNamespace)
+		}
+		result.Succeed(p.PathWithNamespace, p)
+		return true, nil
+	}
+
+	// Print each project using the GraphQL API if requested and if it
+	// returns all of the fields and everything the filter needs.
+	// Otherwise, use the REST API.
+	graphQL := cmd.options.GraphQL && !slices.ContainsFunc(fields,
+		func(field string) bool { return !projectListColumns[field].graphQL }) &&
+		filter.LastActivityBefore == "" && filter.Visibility == ""
+	if graphQL {
+		err = gitlab_util.ForEachProjectInGroupGraphQL(
+			ctx,
+			cmd.client,
+			cmd.options.Group,
+			cmd.options.Expr,
+			cmd.options.Recursive,
+			printProject)
+	} else {
+		err = gitlab_util.ForEachProjectInGroup(
+			ctx,
+			cmd.client.Groups,
+			cmd.options.Group,
+			cmd.options.Expr,
+			cmd.options.Recursive,
+			printProject)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Print the projects as JSON or as a table.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, projects)
+	} else if len(fields) > 0 {
+		PrintProjectTable(os.Stdout, fields, projects)
+	}
+	return result, err
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestProjectsListIntegration(t *testing.T) {
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+
+	data := Data{
+		{
+			args:     []string{"list", "--group", "foo"},
+			expected: []string{"foo/alpha", "foo/beta", "foo/test-gamma"},
+		},
+		{
+			args: []string{"list", "--group", "foo", "--recursive"},
+			expected: []string{
+				"foo/alpha", "foo/beta", "foo/test-gamma",
+				"foo/bar/delta", "foo/bar/test-epsilon",
+			},
+		},
+		{
+			args:     []string{"list", "--group", "foo", "-r", "--expr", "test-"},
+			expected: []string{"foo/test-gamma", "foo/bar/test-epsilon"},
+		},
+	}
+
+	server := newFakeServer(t)
+	for _, d := range data {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		output, _, err := runCommand(t, cmd, d.args)
+		if err != nil {
+			t.Fatalf("unexpected error: %v: %v", d.args, err)
+		}
+		actual := strings.Fields(output)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("projects %v: expected=%v  actual=%v",
+				d.args, d.expected, actual)
+		}
+	}
+}
+
+func TestProjectsListFieldsIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.SetLastActivity("foo/alpha", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	server.SetStatistics("foo/alpha", gitlab.Statistics{StorageSize: 1536})
+	server.SetArchived("foo/beta", true)
+	run := func(args ...string) (string, error) {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		output, _, err := runCommand(t, cmd, args)
+		return output, err
+	}
+
+	// List the projects with extra fields.  The GraphQL API does not
+	// return the visibility so --graphql is ignored.
+	table, err := run("list", "--group", "foo", "--expr", "alpha|beta",
+		"--fields", "visibility,last-activity,archived,storage-size", "--graphql")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, invalidErr := run("list", "--group", "foo", "--fields", "bogus")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"table",
+			"VISIBILITY  LAST ACTIVITY  ARCHIVED  STORAGE  PROJECT\n" +
+				"private     2024-03-01     false     1.5 KiB  foo/alpha\n" +
+				"private     -              true          0 B  foo/beta\n",
+			table},
+		{"invalid field", true, errors.Is(invalidErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("projects list %s: expected=%q  actual=%q", d.name, d.expected, d.actual)
+		}
+	}
+}
+
+func TestProjectsListErrorIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+
+	// Fail the second page of projects.
+	server.InjectError(http.MethodGet, "/groups/1/projects", http.StatusInternalServerError, 1)
+
+	// Verify the error is reported.
+	_, _, err := runCommand(t, cmd, []string{"list", "--group", "foo"})
+	if err == nil {
+		t.Fatalf("projects list: expected error")
+	}
+}
@@ -0,0 +1,241 @@
+// This file provides the implementation for the "projects mirrors
+// check" command which reports the enabled push and pull mirrors whose
+// last update failed or that have not been updated successfully for a
+// while.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsCheckOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsMirrorsCheckOptions are the options needed by this command.
+type ProjectsMirrorsCheckOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// StaleHours is the number of hours after the last successful
+	// update of a mirror after which the mirror is stale.  Defaults
+	// to 24.
+	StaleHours uint64 `xml:"stale-hours"`
+}
+
+// Initialize initializes this ProjectsMirrorsCheckOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsMirrorsCheckOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --stale-hours
+	if opts.StaleHours == 0 {
+		opts.StaleHours = 24
+	}
+	flags.Uint64Var(&opts.StaleHours, "stale-hours", opts.StaleHours,
+		i18n.T("number of hours after the last successful update after which a mirror is stale"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsCheckCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsMirrorsCheckCommand implements the "projects mirrors check"
+// command which reports failed and stale mirrors.
+type ProjectsMirrorsCheckCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsMirrorsCheckOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsMirrorsCheckCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects mirrors check [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the enabled push and pull mirrors of the selected\n")
+	i18n.Fprintf(out, "    projects whose last update failed or whose last successful\n")
+	i18n.Fprintf(out, "    update is older than --stale-hours.  The command fails if\n")
+	i18n.Fprintf(out, "    any mirror is reported.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Check Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsMirrorsCheckCommand returns a new, initialized
+// ProjectsMirrorsCheckCommand instance.
+func NewProjectsMirrorsCheckCommand(
+	name string,
+	opts *ProjectsMirrorsCheckOptions,
+	session *Session,
+) *ProjectsMirrorsCheckCommand {
+
+	// Create the new command.
+	cmd := &ProjectsMirrorsCheckCommand{
+		GitlabCommand: GitlabCommand[ProjectsMirrorsCheckOptions]{
+			BasicCommand: BasicCommand[ProjectsMirrorsCheckOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// MirrorProblem returns "failed" if the last update of the mirror
+// failed, "stale" if the mirror has not been updated successfully
+// since the cutoff, and "" otherwise.  Disabled mirrors never have a
+// problem.
+func MirrorProblem(m *Mirror, cutoff time.Time) string {
+	switch {
+	case !m.Enabled:
+		return ""
+	case m.UpdateStatus == "failed":
+		return "failed"
+	case m.LastSuccessfulUpdateAt == nil || m.LastSuccessfulUpdateAt.Before(cutoff):
+		return "stale"
+	}
+	return ""
+}
+
+// CheckMirrors records each enabled mirror in the result as a
+// *Mirror.  Mirrors that have a problem are recorded as failures.
+// The mirrors that have a problem are returned.
+func CheckMirrors(result *Result, mirrors []*Mirror, cutoff time.Time) []*Mirror {
+	var problems []*Mirror
+	for _, m := range mirrors {
+		if !m.Enabled {
+			continue
+		}
+		problem := MirrorProblem(m, cutoff)
+		if problem == "" {
+			result.Succeed(m.Name(), m)
+			continue
+		}
+		result.Fail(m.Name(), m, i18n.Errorf("mirror %s", problem))
+		problems = append(problems, m)
+	}
+	return problems
+}
+
+// PrintMirrorProblems prints the mirrors that have a problem as a
+// table.  The error of the last update is printed below each failed
+// mirror.
+func PrintMirrorProblems(out io.Writer, mirrors []*Mirror, cutoff time.Time) {
+	row := func(problem, direction, lastSuccess, path, url string) {
+		fmt.Fprintf(out, "%-7s  %-9s  %-16s  %-30s  %s\n",
+			problem, direction, lastSuccess, path, url)
+	}
+	row(i18n.T("PROBLEM"), i18n.T("DIRECTION"), i18n.T("LAST SUCCESS"),
+		i18n.T("PROJECT"), i18n.T("URL"))
+	for _, m := range mirrors {
+		row(MirrorProblem(m, cutoff), m.Direction,
+			formatMirrorTime(m.LastSuccessfulUpdateAt), m.Path, m.URL)
+		if m.LastError != "" {
+			fmt.Fprintf(out, "  %s\n", m.LastError)
+		}
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsMirrorsCheckCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.StaleHours == 0 {
+		return result, i18n.Errorf("%w: invalid stale hours: %v",
+			ErrInvalidOption, cmd.options.StaleHours)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the mirrors.
+	mirrors, err := GetAllMirrors(ctx, result, &cmd.options.ProjectSelectorOptions,
+		ProjectsMirrorsServices{
+			Groups:         cmd.client.Groups,
+			ProjectMirrors: cmd.client.ProjectMirrors,
+			Projects:       cmd.client.Projects,
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Check the mirrors.
+	cutoff := time.Now().Add(-time.Duration(cmd.options.StaleHours) * time.Hour)
+	problems := CheckMirrors(result, mirrors, cutoff)
+
+	// Print the report.
+	PrintMirrorProblems(os.Stdout, problems, cutoff)
+	if len(problems) > 0 {
+		return result, i18n.Errorf("%d mirror(s) failed or are stale", len(problems))
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not get the mirrors of %d project(s)", failed)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,153 @@
+// This file provides the implementation for the "projects mirrors"
+// command which provides push and pull mirror related subcommands.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      ProjectsCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsMirrorsOptions are the options needed by this command.
+type ProjectsMirrorsOptions struct {
+
+	// Options for the "projects mirrors check" command.
+	ProjectsMirrorsCheckOpts ProjectsMirrorsCheckOptions `xml:"check-options"`
+
+	// Options for the "projects mirrors list" command.
+	ProjectsMirrorsListOpts ProjectsMirrorsListOptions `xml:"list-options"`
+
+	// Options for the "projects mirrors set" command.
+	ProjectsMirrorsSetOpts ProjectsMirrorsSetOptions `xml:"set-options"`
+}
+
+// Initialize initializes this ProjectsMirrorsOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *ProjectsMirrorsOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsMirrorsCommand provides subcommands for administering the
+// push and pull mirrors of Gitlab projects.
+type ProjectsMirrorsCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[ProjectsMirrorsOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *ProjectsMirrorsCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects mirrors [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for administering push and pull mirrors of Gitlab projects.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *ProjectsMirrorsCommand) addSubcmds(session *Session) {
+	cmd.subcmds["check"] = NewProjectsMirrorsCheckCommand(
+		"check", &cmd.options.ProjectsMirrorsCheckOpts, session)
+	cmd.subcmds["list"] = NewProjectsMirrorsListCommand(
+		"list", &cmd.options.ProjectsMirrorsListOpts, session)
+	cmd.subcmds["set"] = NewProjectsMirrorsSetCommand(
+		"set", &cmd.options.ProjectsMirrorsSetOpts, session)
+}
+
+// NewProjectsMirrorsCommand returns a new, initialized
+// ProjectsMirrorsCommand instance having the specified name.
+func NewProjectsMirrorsCommand(
+	name string,
+	opts *ProjectsMirrorsOptions,
+	session *Session,
+) *ProjectsMirrorsCommand {
+
+	// Create the new command.
+	cmd := &ProjectsMirrorsCommand{
+		ParentCommand: ParentCommand[ProjectsMirrorsOptions]{
+			BasicCommand: BasicCommand[ProjectsMirrorsOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsMirrorsCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
@@ -0,0 +1,320 @@
+// This file provides the implementation for the "projects mirrors
+// list" command which lists the push and pull mirrors of the selected
+// projects along with their update status.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsMirrorsListOptions are the options needed by this command.
+type ProjectsMirrorsListOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+}
+
+// Initialize initializes this ProjectsMirrorsListOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsMirrorsListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsListCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsMirrorsListCommand implements the "projects mirrors list"
+// command which lists the mirrors of each project.
+type ProjectsMirrorsListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsMirrorsListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsMirrorsListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects mirrors list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the push and pull mirrors of the selected projects\n")
+	i18n.Fprintf(out, "    along with their update status and the time of their last\n")
+	i18n.Fprintf(out, "    successful update.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsMirrorsListCommand returns a new, initialized
+// ProjectsMirrorsListCommand instance.
+func NewProjectsMirrorsListCommand(
+	name string,
+	opts *ProjectsMirrorsListOptions,
+	session *Session,
+) *ProjectsMirrorsListCommand {
+
+	// Create the new command.
+	cmd := &ProjectsMirrorsListCommand{
+		GitlabCommand: GitlabCommand[ProjectsMirrorsListOptions]{
+			BasicCommand: BasicCommand[ProjectsMirrorsListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectsMirrorsServices are the Gitlab services needed to get the
+// mirrors of projects.
+type ProjectsMirrorsServices struct {
+	Groups         gitlab_util.ProjectsInGroupLister   /* was *gitlab.GroupsService */
+	ProjectMirrors gitlab_util.ProjectMirrorsLister    /* was *gitlab.ProjectMirrorService */
+	Projects       gitlab_util.PullMirrorDetailsGetter /* was *gitlab.ProjectsService */
+}
+
+// Mirror is a push or pull mirror of a project.
+type Mirror struct {
+
+	// Path is the full path of the project.
+	Path string `json:"project"`
+
+	// Direction is either "push" or "pull".
+	Direction string `json:"direction"`
+
+	// URL is the URL of the remote repository.  Gitlab masks any
+	// credentials in the URL.
+	URL string `json:"url"`
+
+	// Enabled is whether the mirror is updated.  Pull mirrors are
+	// always enabled.
+	Enabled bool `json:"enabled"`
+
+	// UpdateStatus is the status of the last update (e.g.,
+	// "finished" or "failed").
+	UpdateStatus string `json:"update_status"`
+
+	// LastError is the error of the last update if it failed.
+	LastError string `json:"last_error"`
+
+	// LastSuccessfulUpdateAt is the time of the last successful
+	// update or nil if the mirror has never been updated.
+	LastSuccessfulUpdateAt *time.Time `json:"last_successful_update_at"`
+}
+
+// Name returns the name used for the mirror in the result.
+func (m *Mirror) Name() string {
+	return m.Path + ":" + m.Direction + ":" + m.URL
+}
+
+// SameMirrorURL returns true if the URLs point at the same repository
+// ignoring any credentials which Gitlab masks when it returns the URL
+// of a mirror.
+func SameMirrorURL(a string, b string) bool {
+	strip := func(s string) string {
+		u, err := url.Parse(s)
+		if err != nil {
+			return s
+		}
+		u.User = nil
+		return u.String()
+	}
+	return strip(a) == strip(b)
+}
+
+// GetProjectMirrors returns the push mirrors and then the pull mirror
+// (if any) of the project.
+func GetProjectMirrors(
+	ctx context.Context,
+	s ProjectsMirrorsServices,
+	p *gitlab.Project,
+) ([]*Mirror, error) {
+	var mirrors []*Mirror
+
+	// Get the push mirrors.
+	pushMirrors, err := gitlab_util.GetAllProjectMirrors(ctx, s.ProjectMirrors, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectMirrors: %w", err)
+	}
+	for _, m := range pushMirrors {
+		mirrors = append(mirrors, &Mirror{
+			Path:                   p.PathWithNamespace,
+			Direction:              "push",
+			URL:                    m.URL,
+			Enabled:                m.Enabled,
+			UpdateStatus:           m.UpdateStatus,
+			LastError:              m.LastError,
+			LastSuccessfulUpdateAt: m.LastSuccessfulUpdateAt,
+		})
+	}
+
+	// Get the pull mirror.
+	if p.Mirror {
+		m, _, err := s.Projects.GetProjectPullMirrorDetails(p.ID, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf(
+				"GetProjectMirrors: %w", gitlab_util.ClassifyError(err))
+		}
+		mirrors = append(mirrors, &Mirror{
+			Path:                   p.PathWithNamespace,
+			Direction:              "pull",
+			URL:                    m.URL,
+			Enabled:                true,
+			UpdateStatus:           m.UpdateStatus,
+			LastError:              m.LastError,
+			LastSuccessfulUpdateAt: m.LastSuccessfulUpdateAt,
+		})
+	}
+
+	return mirrors, nil
+}
+
+// GetAllMirrors returns the mirrors of each selected project and
+// records each project in the result.
+func GetAllMirrors(
+	ctx context.Context,
+	result *Result,
+	selector *ProjectSelectorOptions,
+	s ProjectsMirrorsServices,
+) ([]*Mirror, error) {
+	var mirrors []*Mirror
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			ms, err := GetProjectMirrors(ctx, s, p)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			result.Succeed(p.PathWithNamespace, p)
+			mirrors = append(mirrors, ms...)
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("GetAllMirrors: %w", err)
+	}
+	return mirrors, nil
+}
+
+// formatMirrorTime formats the time of the last successful update of
+// a mirror or returns "-" if it is nil.
+func formatMirrorTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.UTC().Format("2006-01-02 15:04")
+}
+
+// PrintMirrors prints the mirrors as a table.
+func PrintMirrors(out io.Writer, mirrors []*Mirror) {
+	row := func(direction, enabled, status, lastSuccess, path, url string) {
+		fmt.Fprintf(out, "%-9s  %-7s  %-10s  %-16s  %-30s  %s\n",
+			direction, enabled, status, lastSuccess, path, url)
+	}
+	row(i18n.T("DIRECTION"), i18n.T("ENABLED"), i18n.T("STATUS"),
+		i18n.T("LAST SUCCESS"), i18n.T("PROJECT"), i18n.T("URL"))
+	for _, m := range mirrors {
+		row(m.Direction, fmt.Sprint(m.Enabled), m.UpdateStatus,
+			formatMirrorTime(m.LastSuccessfulUpdateAt), m.Path, m.URL)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsMirrorsListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the mirrors.
+	mirrors, err := GetAllMirrors(ctx, result, &cmd.options.ProjectSelectorOptions,
+		ProjectsMirrorsServices{
+			Groups:         cmd.client.Groups,
+			ProjectMirrors: cmd.client.ProjectMirrors,
+			Projects:       cmd.client.Projects,
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the mirrors.
+	if cmd.session.OutputJSON() {
+		if mirrors == nil {
+			mirrors = []*Mirror{}
+		}
+		err = writeJSON(os.Stdout, mirrors)
+		return result, err
+	}
+	PrintMirrors(os.Stdout, mirrors)
+	return result, nil
+}
@@ -0,0 +1,354 @@
+// This file provides the implementation for the "projects mirrors set"
+// command which configures a push or pull mirror for each selected
+// project.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsSetOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsMirrorsSetOptions are the options needed by this command.
+type ProjectsMirrorsSetOptions struct {
+
+	// Embed the options that control whether the remaining projects
+	// are configured after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Direction is either "push" to push to the remote repository or
+	// "pull" to pull from it.  Defaults to "push".
+	Direction string `xml:"direction"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// OnlyProtectedBranches controls whether only protected branches
+	// are mirrored.  Defaults to false.
+	OnlyProtectedBranches bool `xml:"only-protected-branches"`
+
+	// URL is the URL of the remote repository.  The "{path}" and
+	// "{full_path}" placeholders are replaced with the path and full
+	// path of each project.  Defaults to "".
+	URL string `xml:"url"`
+}
+
+// Initialize initializes this ProjectsMirrorsSetOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsMirrorsSetOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// --direction
+	if opts.Direction == "" {
+		opts.Direction = "push"
+	}
+	flags.StringVar(&opts.Direction, "direction", opts.Direction,
+		i18n.T("either \"push\" to push to the remote repository or \"pull\" to pull from it"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --only-protected-branches
+	flags.BoolVar(&opts.OnlyProtectedBranches, "only-protected-branches",
+		opts.OnlyProtectedBranches,
+		i18n.T("whether to only mirror protected branches"))
+
+	// --url
+	flags.StringVar(&opts.URL, "url", opts.URL,
+		i18n.T("URL of the remote repository in which {path} and {full_path} "+
+			"are replaced with the path and full path of each project"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsMirrorsSetCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsMirrorsSetCommand implements the "projects mirrors set"
+// command which configures a mirror for each project.
+type ProjectsMirrorsSetCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsMirrorsSetOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsMirrorsSetCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects mirrors set [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Configure each selected project to push to or pull from\n")
+	i18n.Fprintf(out, "    the remote repository at --url.  An existing push mirror\n")
+	i18n.Fprintf(out, "    for the same URL is enabled instead of adding another one.\n")
+	i18n.Fprintf(out, "    Credentials can be included in the URL.  The command stops\n")
+	i18n.Fprintf(out, "    at the first project that fails unless --keep-going is\n")
+	i18n.Fprintf(out, "    passed in which case the projects that failed are listed\n")
+	i18n.Fprintf(out, "    at the end.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Set Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsMirrorsSetCommand returns a new, initialized
+// ProjectsMirrorsSetCommand instance.
+func NewProjectsMirrorsSetCommand(
+	name string,
+	opts *ProjectsMirrorsSetOptions,
+	session *Session,
+) *ProjectsMirrorsSetCommand {
+
+	// Create the new command.
+	cmd := &ProjectsMirrorsSetCommand{
+		GitlabCommand: GitlabCommand[ProjectsMirrorsSetOptions]{
+			BasicCommand: BasicCommand[ProjectsMirrorsSetOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectMirrorsService is an abstraction of gitlab.ProjectMirrorService
+// which lists, adds, and edits the push mirrors of projects.
+type ProjectMirrorsService interface {
+	gitlab_util.ProjectMirrorsLister
+	gitlab_util.ProjectMirrorsManager
+}
+
+// ProjectsMirrorsSetServices are the Gitlab services needed to
+// configure the mirrors of projects.
+type ProjectsMirrorsSetServices struct {
+	ProjectMirrors ProjectMirrorsService     /* was *gitlab.ProjectMirrorService */
+	Projects       gitlab_util.ProjectEditor /* was *gitlab.ProjectsService */
+}
+
+// MirrorURL returns the URL of the remote repository for the project
+// by replacing the "{path}" and "{full_path}" placeholders in the
+// template with the path and full path of the project.
+func MirrorURL(template string, p *gitlab.Project) string {
+	return strings.NewReplacer(
+		"{path}", p.Path,
+		"{full_path}", p.PathWithNamespace,
+	).Replace(template)
+}
+
+// SetPushMirror configures the project to push to the remote
+// repository at the URL.  If the project already has a push mirror for
+// the URL, it is enabled instead of adding another one.  If dryRun is
+// true, this function only prints what it would without actually
+// doing it.
+func SetPushMirror(
+	ctx context.Context,
+	s ProjectsMirrorsSetServices,
+	p *gitlab.Project,
+	url string,
+	onlyProtectedBranches bool,
+	dryRun bool,
+) error {
+	mirrors, err := gitlab_util.GetAllProjectMirrors(ctx, s.ProjectMirrors, p.ID)
+	if err != nil {
+		return fmt.Errorf("SetPushMirror: %w", err)
+	}
+	for _, m := range mirrors {
+		if !SameMirrorURL(m.URL, url) {
+			continue
+		}
+		if m.Enabled && m.OnlyProtectedBranches == onlyProtectedBranches {
+			logging.Printf("- Push mirror of %q already set.\n", p.PathWithNamespace)
+			return nil
+		}
+		logging.Printf("- Enabling push mirror of %q ... ", p.PathWithNamespace)
+		if !dryRun {
+			_, _, err = s.ProjectMirrors.EditProjectMirror(p.ID, m.ID,
+				&gitlab.EditProjectMirrorOptions{
+					Enabled:               gitlab.Ptr(true),
+					OnlyProtectedBranches: gitlab.Ptr(onlyProtectedBranches),
+				},
+				gitlab.WithContext(ctx))
+			if err != nil {
+				return fmt.Errorf(
+					"SetPushMirror: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+		return nil
+	}
+	logging.Printf("- Adding push mirror to %q ... ", p.PathWithNamespace)
+	if !dryRun {
+		_, _, err = s.ProjectMirrors.AddProjectMirror(p.ID,
+			&gitlab.AddProjectMirrorOptions{
+				URL:                   gitlab.Ptr(url),
+				Enabled:               gitlab.Ptr(true),
+				OnlyProtectedBranches: gitlab.Ptr(onlyProtectedBranches),
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf(
+				"SetPushMirror: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// SetPullMirror configures the project to pull from the remote
+// repository at the URL.  If dryRun is true, this function only prints
+// what it would without actually doing it.
+func SetPullMirror(
+	ctx context.Context,
+	s ProjectsMirrorsSetServices,
+	p *gitlab.Project,
+	url string,
+	onlyProtectedBranches bool,
+	dryRun bool,
+) error {
+	if p.Mirror && SameMirrorURL(p.ImportURL, url) &&
+		p.OnlyMirrorProtectedBranches == onlyProtectedBranches {
+		logging.Printf("- Pull mirror of %q already set.\n", p.PathWithNamespace)
+		return nil
+	}
+	logging.Printf("- Setting pull mirror of %q ... ", p.PathWithNamespace)
+	if !dryRun {
+		_, _, err := s.Projects.EditProject(p.ID,
+			&gitlab.EditProjectOptions{
+				ImportURL:                   gitlab.Ptr(url),
+				Mirror:                      gitlab.Ptr(true),
+				OnlyMirrorProtectedBranches: gitlab.Ptr(onlyProtectedBranches),
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf(
+				"SetPullMirror: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsMirrorsSetCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Direction != "push" && cmd.options.Direction != "pull" {
+		return result, i18n.Errorf("%w: invalid direction: %q",
+			ErrInvalidOption, cmd.options.Direction)
+	}
+	if cmd.options.URL == "" {
+		return result, i18n.Errorf("%w: url not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Set the mirror of each project.
+	s := ProjectsMirrorsSetServices{
+		ProjectMirrors: cmd.client.ProjectMirrors,
+		Projects:       cmd.client.Projects,
+	}
+	set := SetPushMirror
+	if cmd.options.Direction == "pull" {
+		set = SetPullMirror
+	}
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			err := set(ctx, s, p, MirrorURL(cmd.options.URL, p),
+				cmd.options.OnlyProtectedBranches, cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return cmd.options.Continue(err)
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			result.Succeed(p.PathWithNamespace, p)
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err,
+		"could not set the mirror of %d project(s)")
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,283 @@
+// This file provides the implementation for the "projects purge-trash"
+// command which deletes the projects that were moved to the trash
+// group by "projects delete --trash-group" more than a given age ago.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsPurgeTrashOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsPurgeTrashOptions are the options needed by this command.
+type ProjectsPurgeTrashOptions struct {
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// OlderThan is how long projects must have been in the trash
+	// group before they are deleted (e.g., "30d", "2w", or "12h").
+	// Defaults to "30d".
+	OlderThan string `xml:"older-than"`
+
+	// TrashGroup is the group to which "projects delete" moved the
+	// projects.  Defaults to "".
+	TrashGroup string `xml:"trash-group"`
+}
+
+// Initialize initializes this ProjectsPurgeTrashOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsPurgeTrashOptions) Initialize(flags *flag.FlagSet) {
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --older-than
+	if opts.OlderThan == "" {
+		opts.OlderThan = "30d"
+	}
+	flags.StringVar(&opts.OlderThan, "older-than", opts.OlderThan,
+		i18n.T("how long projects must have been in the trash (e.g., 30d, 2w, or 12h)"))
+
+	// --trash-group
+	flags.StringVar(&opts.TrashGroup, "trash-group", opts.TrashGroup,
+		i18n.T("group to which \"projects delete\" moved the projects"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsPurgeTrashCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsPurgeTrashCommand implements the "projects purge-trash"
+// command which deletes projects that have been in the trash group
+// for longer than a given age.
+type ProjectsPurgeTrashCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsPurgeTrashOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsPurgeTrashCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects purge-trash [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Deletes the projects that were moved to the trash group by\n")
+	i18n.Fprintf(out, "    \"projects delete --trash-group\" longer ago than --older-than.\n")
+	i18n.Fprintf(out, "    Projects in the trash group that were not moved there by\n")
+	i18n.Fprintf(out, "    \"projects delete\" are left alone.  The projects are listed\n")
+	i18n.Fprintf(out, "    and must be confirmed unless the global --yes option is\n")
+	i18n.Fprintf(out, "    passed.  When deleting more projects than\n")
+	i18n.Fprintf(out, "    --confirm-threshold, --i-understand must be passed, and the\n")
+	i18n.Fprintf(out, "    trash group must be typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Purge Trash Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsPurgeTrashCommand returns a new, initialized
+// ProjectsPurgeTrashCommand instance.
+func NewProjectsPurgeTrashCommand(
+	name string,
+	opts *ProjectsPurgeTrashOptions,
+	session *Session,
+) *ProjectsPurgeTrashCommand {
+
+	// Create the new command.
+	cmd := &ProjectsPurgeTrashCommand{
+		GitlabCommand: GitlabCommand[ProjectsPurgeTrashOptions]{
+			BasicCommand: BasicCommand[ProjectsPurgeTrashOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ParseAge parses an age such as "30d", "2w", "2y" (where a year is
+// 365 days), or any duration accepted by time.ParseDuration() such as
+// "12h".
+func ParseAge(age string) (time.Duration, error) {
+	s := strings.TrimSpace(age)
+	for suffix, unit := range map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+		"y": 365 * 24 * time.Hour,
+	} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			value, err := strconv.Atoi(n)
+			if err != nil || value < 0 {
+				break
+			}
+			return time.Duration(value) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, i18n.Errorf("%w: invalid age: %q", ErrInvalidOption, age)
+	}
+	return d, nil
+}
+
+// PurgeTrash deletes the projects in the trash group (which can be the
+// group ID or its full path) and its subgroups that were moved there
+// before the cutoff.  If confirm is not nil, it is called with the
+// full paths of the projects before any are deleted, and nothing is
+// deleted if it returns an error.  If dryRun is true, this function
+// only prints what it would do without actually doing it.
+func PurgeTrash(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	projects gitlab_util.ProjectDeleter, /* was *gitlab.ProjectsService */
+	trashGroup string,
+	cutoff time.Time,
+	confirm func(names []string) error,
+	dryRun bool,
+) error {
+
+	// Collect projects.
+	logging.Printf("- Collecting projects ... ")
+	ps, err := gitlab_util.GetAllProjects(ctx, groups, trashGroup, "", true)
+	if err != nil {
+		return fmt.Errorf("PurgeTrash: %w", err)
+	}
+	logging.Printf("Done.\n")
+
+	// Select projects that have been in the trash long enough.
+	ps = slices.DeleteFunc(ps, func(p *gitlab.Project) bool {
+		trashedAt, ok := TrashedAt(p)
+		return !ok || !trashedAt.Before(cutoff)
+	})
+
+	// Ask for confirmation.
+	if confirm != nil && !dryRun {
+		err = confirm(ProjectPaths(ps))
+		if err != nil {
+			return fmt.Errorf("PurgeTrash: %w", err)
+		}
+	}
+
+	// Delete the projects stopping after the current project if
+	// interrupted.
+	for _, p := range ps {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("PurgeTrash: %w", err)
+		}
+		err = DeleteProject(ctx, projects, p, dryRun)
+		if err != nil {
+			result.Fail(p.PathWithNamespace, p, err)
+			return fmt.Errorf("PurgeTrash: %w", err)
+		}
+		result.Succeed(p.PathWithNamespace, p)
+	}
+
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsPurgeTrashCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.TrashGroup == "" {
+		return result, i18n.Errorf("%w: trash group not set", ErrInvalidOption)
+	}
+	age, err := ParseAge(cmd.options.OlderThan)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Purge the trash.
+	err = PurgeTrash(
+		ctx,
+		result,
+		cmd.client.Groups,
+		cmd.client.Projects,
+		cmd.options.TrashGroup,
+		time.Now().Add(-age),
+		func(names []string) error {
+			return cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"), names,
+				cmd.options.TrashGroup, cmd.session.AssumeYes())
+		},
+		cmd.options.DryRun)
+	return result, err
+}
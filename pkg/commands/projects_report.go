@@ -0,0 +1,151 @@
+// This file provides the functions shared by the "projects report"
+// subcommands for reporting how much storage projects use.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectSize holds the storage statistics of a project.
+type ProjectSize struct {
+
+	// Path is the full path of the project.
+	Path string
+
+	// Statistics are the storage statistics of the project.
+	Statistics gitlab.Statistics
+}
+
+// projectSizeColumns maps the values of --sort-by to the size they
+// select from the statistics.
+var projectSizeColumns = map[string]func(s *gitlab.Statistics) int64{
+	"storage":    func(s *gitlab.Statistics) int64 { return s.StorageSize },
+	"repository": func(s *gitlab.Statistics) int64 { return s.RepositorySize },
+	"artifacts":  func(s *gitlab.Statistics) int64 { return s.JobArtifactsSize },
+	"packages":   func(s *gitlab.Statistics) int64 { return s.PackagesSize },
+	"lfs":        func(s *gitlab.Statistics) int64 { return s.LFSObjectsSize },
+}
+
+// FormatSize returns the number of bytes as a human readable string
+// using binary units (e.g., "1.5 MiB").
+func FormatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes)
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := -1
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// ParseSize parses a size such as "512", "100 KiB", "1.5GiB", or
+// "2TiB" and returns the number of bytes.  Only binary units are
+// supported.
+func ParseSize(size string) (int64, error) {
+	s := strings.TrimSpace(size)
+	multiplier := int64(1)
+	for i, suffix := range []string{"KiB", "MiB", "GiB", "TiB", "PiB"} {
+		if strings.HasSuffix(s, suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, suffix))
+			multiplier = int64(1) << (10 * (i + 1))
+			break
+		}
+	}
+	s = strings.TrimSpace(strings.TrimSuffix(s, "B"))
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value < 0 {
+		return 0, i18n.Errorf("%w: invalid size: %q", ErrInvalidOption, size)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// GetProjectSize returns the storage statistics of the project.
+// Project statistics are only returned by Gitlab if the user has at
+// least the Reporter role in the project.
+func GetProjectSize(
+	ctx context.Context,
+	s gitlab_util.ProjectGetter, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+) (*ProjectSize, error) {
+	project, _, err := s.GetProject(
+		p.ID,
+		&gitlab.GetProjectOptions{Statistics: gitlab.Ptr(true)},
+		gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"GetProjectSize: %w", gitlab_util.ClassifyError(err))
+	}
+	result := &ProjectSize{Path: p.PathWithNamespace}
+	if project.Statistics != nil {
+		result.Statistics = *project.Statistics
+	}
+	return result, nil
+}
+
+// GetProjectSizes returns the storage statistics of each project
+// selected by the selector.
+func GetProjectSizes(
+	ctx context.Context,
+	result *Result,
+	selector *ProjectSelectorOptions,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	projects gitlab_util.ProjectGetter, /* was *gitlab.ProjectsService */
+) ([]*ProjectSize, error) {
+	var sizes []*ProjectSize
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err := selector.ForEachProject(ctx, groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			size, err := GetProjectSize(ctx, projects, p)
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return false, err
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			result.Succeed(p.PathWithNamespace, p)
+			sizes = append(sizes, size)
+			return true, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return sizes, nil
+}
+
+// SortProjectSizes sorts the project sizes from largest to smallest
+// by the size selected by sortBy breaking ties by path.  The sortBy
+// parameter must be one of "storage", "repository", "artifacts",
+// "packages", or "lfs".
+func SortProjectSizes(sizes []*ProjectSize, sortBy string) error {
+	column, ok := projectSizeColumns[sortBy]
+	if !ok {
+		return i18n.Errorf("%w: invalid sort-by: %q", ErrInvalidOption, sortBy)
+	}
+	slices.SortStableFunc(sizes, func(a, b *ProjectSize) int {
+		x := column(&a.Statistics)
+		y := column(&b.Statistics)
+		if x != y {
+			if x > y {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return nil
+}
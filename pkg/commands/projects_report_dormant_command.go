@@ -0,0 +1,285 @@
+// This file provides the implementation for the "projects report
+// dormant" command which reports the projects that have had no
+// activity for a number of months so they can be archived or deleted.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsReportDormantOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsReportDormantOptions are the options needed by this command.
+type ProjectsReportDormantOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Months is the number of months without activity after which a
+	// project is dormant.  Defaults to 12.
+	Months uint64 `xml:"months"`
+
+	// PathsOnly should cause the command to only print the full path
+	// of each dormant project so the output can be used by other
+	// commands and scripts.  Defaults to false.
+	PathsOnly bool `xml:"paths-only"`
+}
+
+// Initialize initializes this ProjectsReportDormantOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsReportDormantOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --months
+	if opts.Months == 0 {
+		opts.Months = 12
+	}
+	flags.Uint64Var(&opts.Months, "months", opts.Months,
+		i18n.T("number of months without activity after which a project is dormant"))
+
+	// --paths-only
+	flags.BoolVar(&opts.PathsOnly, "paths-only", opts.PathsOnly,
+		i18n.T("only print the full path of each dormant project"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsReportDormantCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsReportDormantCommand implements the "projects report size"
+// command which reports projects without recent activity.
+type ProjectsReportDormantCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsReportDormantOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsReportDormantCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects report dormant [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the selected projects without commits, issues, or merge\n")
+	i18n.Fprintf(out, "    request activity for the given number of months sorted from\n")
+	i18n.Fprintf(out, "    least to most recently active.  Archived projects are skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Dormant Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsReportDormantCommand returns a new, initialized
+// ProjectsReportDormantCommand instance.
+func NewProjectsReportDormantCommand(
+	name string,
+	opts *ProjectsReportDormantOptions,
+	session *Session,
+) *ProjectsReportDormantCommand {
+
+	// Create the new command.
+	cmd := &ProjectsReportDormantCommand{
+		GitlabCommand: GitlabCommand[ProjectsReportDormantOptions]{
+			BasicCommand: BasicCommand[ProjectsReportDormantOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// DormantProject is a project without recent activity.
+type DormantProject struct {
+
+	// Project is the dormant project.
+	Project *gitlab.Project
+
+	// Owner is the username of the owner of the project or, for
+	// projects owned by a group, the full path of the group.
+	Owner string
+}
+
+// ProjectOwner returns the username of the owner of the project or,
+// for projects owned by a group, the full path of the group.
+func ProjectOwner(p *gitlab.Project) string {
+	if p.Owner != nil {
+		return p.Owner.Username
+	}
+	if p.Namespace != nil {
+		return p.Namespace.FullPath
+	}
+	return ""
+}
+
+// IsDormantProject returns true if the project is not archived and
+// its last activity is before the cutoff or it has never had any
+// activity.
+func IsDormantProject(p *gitlab.Project, cutoff time.Time) bool {
+	if p.Archived {
+		return false
+	}
+	return p.LastActivityAt == nil || p.LastActivityAt.Before(cutoff)
+}
+
+// FindDormantProjects returns the projects selected by the selector
+// whose last activity (which includes commits, issues, and merge
+// requests) is before the cutoff sorted from least to most recently
+// active.  Projects that have never had any activity are dormant.
+// Archived projects are skipped because there is nothing more to do
+// with them.
+func FindDormantProjects(
+	ctx context.Context,
+	selector *ProjectSelectorOptions,
+	s gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	cutoff time.Time,
+) ([]*DormantProject, error) {
+	var result []*DormantProject
+	err := selector.ForEachProject(ctx, s,
+		func(p *gitlab.Project) (bool, error) {
+			if !IsDormantProject(p, cutoff) {
+				return true, nil
+			}
+			result = append(result, &DormantProject{
+				Project: p,
+				Owner:   ProjectOwner(p),
+			})
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindDormantProjects: %w", err)
+	}
+	slices.SortStableFunc(result, func(a, b *DormantProject) int {
+		x := a.Project.LastActivityAt
+		y := b.Project.LastActivityAt
+		switch {
+		case x == nil && y == nil:
+		case x == nil:
+			return -1
+		case y == nil:
+			return 1
+		case !x.Equal(*y):
+			return x.Compare(*y)
+		}
+		return strings.Compare(
+			a.Project.PathWithNamespace, b.Project.PathWithNamespace)
+	})
+	return result, nil
+}
+
+// PrintDormantProjects prints the dormant projects as a table with
+// the date of their last activity and their owner.
+func PrintDormantProjects(out io.Writer, dormant []*DormantProject) {
+	row := func(lastActivity, owner, path string) {
+		fmt.Fprintf(out, "%-13s  %-20s  %s\n", lastActivity, owner, path)
+	}
+	row(i18n.T("LAST ACTIVITY"), i18n.T("OWNER"), i18n.T("PROJECT"))
+	for _, d := range dormant {
+		lastActivity := i18n.T("never")
+		if d.Project.LastActivityAt != nil {
+			lastActivity = d.Project.LastActivityAt.Format("2006-01-02")
+		}
+		row(lastActivity, d.Owner, d.Project.PathWithNamespace)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsReportDormantCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Months == 0 {
+		return result, i18n.Errorf("%w: invalid months: %v",
+			ErrInvalidOption, cmd.options.Months)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Find the dormant projects.
+	cutoff := time.Now().AddDate(0, -int(cmd.options.Months), 0)
+	dormant, err := FindDormantProjects(
+		ctx, &cmd.options.ProjectSelectorOptions, cmd.client.Groups, cutoff)
+	if err != nil {
+		return result, err
+	}
+	for _, d := range dormant {
+		result.Succeed(d.Project.PathWithNamespace, d.Project)
+	}
+
+	// Print the report.
+	if cmd.options.PathsOnly {
+		for _, d := range dormant {
+			fmt.Printf("%s\n", d.Project.PathWithNamespace)
+		}
+		return result, nil
+	}
+	PrintDormantProjects(os.Stdout, dormant)
+	return result, nil
+}
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProjectsReportDormantIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	now := time.Now()
+	server.SetLastActivity("foo/alpha", now.AddDate(-2, 0, 0))
+	server.SetLastActivity("foo/beta", now.AddDate(0, -1, 0))
+	server.SetLastActivity("foo/bar/delta", now.AddDate(0, -13, 0))
+
+	// Report the dormant projects.
+	run := func(args ...string) string {
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		out, _, err := runCommand(t, cmd, append([]string{
+			"report", "dormant", "--group", "foo", "-r"}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+	table := run()
+	paths := run("--months", "18", "--paths-only")
+
+	// Verify the reports.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"active", false, strings.Contains(table, "foo/beta")},
+		{"owner", true, strings.Contains(table, "foo/bar  ")},
+		{"alpha date", true, strings.Contains(table,
+			now.AddDate(-2, 0, 0).Format("2006-01-02"))},
+		{"never", true, strings.Contains(table, "never")},
+		{"paths", "foo/bar/test-epsilon\nfoo/test-gamma\nfoo/alpha\n", paths},
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("projects report dormant %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// This file provides the implementation for the "projects report
+// lfs" command which reports how much LFS storage each project uses
+// so LFS storage migrations can be planned.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsReportLFSOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsReportLFSOptions are the options needed by this command.
+type ProjectsReportLFSOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Threshold is the LFS size above which projects are flagged.  It
+	// is a number of bytes optionally followed by a binary unit (e.g.,
+	// "500MiB" or "2GiB").  Defaults to "1GiB".
+	Threshold string `xml:"threshold"`
+}
+
+// Initialize initializes this ProjectsReportLFSOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsReportLFSOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --threshold
+	if opts.Threshold == "" {
+		opts.Threshold = "1GiB"
+	}
+	flags.StringVar(&opts.Threshold, "threshold", opts.Threshold,
+		i18n.T("LFS size above which projects are flagged (e.g., 500MiB or 2GiB)"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsReportLFSCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsReportLFSCommand implements the "projects report lfs"
+// command which reports how much LFS storage each project uses.
+type ProjectsReportLFSCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsReportLFSOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsReportLFSCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects report lfs [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the LFS storage used by each selected project sorted\n")
+	i18n.Fprintf(out, "    from largest to smallest and flag the projects exceeding the\n")
+	i18n.Fprintf(out, "    threshold.  Gitlab does not report the number of LFS objects\n")
+	i18n.Fprintf(out, "    in a project so only their total size is reported.  Reading\n")
+	i18n.Fprintf(out, "    project statistics requires at least the Reporter role in\n")
+	i18n.Fprintf(out, "    each project.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "LFS Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsReportLFSCommand returns a new, initialized
+// ProjectsReportLFSCommand instance.
+func NewProjectsReportLFSCommand(
+	name string,
+	opts *ProjectsReportLFSOptions,
+	session *Session,
+) *ProjectsReportLFSCommand {
+
+	// Create the new command.
+	cmd := &ProjectsReportLFSCommand{
+		GitlabCommand: GitlabCommand[ProjectsReportLFSOptions]{
+			BasicCommand: BasicCommand[ProjectsReportLFSOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// PrintProjectLFSSizes prints the LFS size of each project that uses
+// LFS flagging the projects whose LFS size exceeds the threshold.
+// The sizes should already be sorted by LFS size.
+func PrintProjectLFSSizes(out io.Writer, sizes []*ProjectSize, threshold int64) {
+	row := func(lfs, storage, share, status, path string) {
+		fmt.Fprintf(out, "%12s %12s %7s  %-9s %s\n",
+			lfs, storage, share, status, path)
+	}
+	row(i18n.T("LFS"), i18n.T("STORAGE"), i18n.T("SHARE"),
+		i18n.T("THRESHOLD"), i18n.T("PROJECT"))
+	var total int64
+	count := 0
+	flagged := 0
+	for _, size := range sizes {
+		s := &size.Statistics
+		if s.LFSObjectsSize == 0 {
+			continue
+		}
+		share := "-"
+		if s.StorageSize > 0 {
+			share = fmt.Sprintf("%.1f%%",
+				100*float64(s.LFSObjectsSize)/float64(s.StorageSize))
+		}
+		status := ""
+		if s.LFSObjectsSize > threshold {
+			status = i18n.T("exceeded")
+			flagged++
+		}
+		row(FormatSize(s.LFSObjectsSize), FormatSize(s.StorageSize),
+			share, status, size.Path)
+		total += s.LFSObjectsSize
+		count++
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "LFS objects use %s in %d of %d project(s).\n",
+		FormatSize(total), count, len(sizes))
+	i18n.Fprintf(out, "%d project(s) exceed the threshold of %s.\n",
+		flagged, FormatSize(threshold))
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsReportLFSCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	threshold, err := ParseSize(cmd.options.Threshold)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the size of each selected project.
+	sizes, err := GetProjectSizes(
+		ctx, result, &cmd.options.ProjectSelectorOptions,
+		cmd.client.Groups, cmd.client.Projects)
+	if err != nil {
+		return result, err
+	}
+
+	// Print the report.
+	err = SortProjectSizes(sizes, "lfs")
+	if err != nil {
+		return result, err
+	}
+	PrintProjectLFSSizes(os.Stdout, sizes, threshold)
+	return result, nil
+}
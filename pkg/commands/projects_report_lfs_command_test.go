@@ -0,0 +1,57 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestProjectsReportLFSIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	server.SetStatistics("foo/alpha", gitlab.Statistics{
+		StorageSize: 4 << 20, LFSObjectsSize: 1 << 20})
+	server.SetStatistics("foo/beta", gitlab.Statistics{
+		StorageSize: 5 << 10, RepositorySize: 5 << 10})
+	server.SetStatistics("foo/bar/delta", gitlab.Statistics{
+		StorageSize: 4 << 20, LFSObjectsSize: 3 << 20})
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+
+	// Report the LFS usage.
+	out, _, err := runCommand(t, cmd, []string{
+		"report", "lfs", "--group", "foo", "-r", "--threshold", "2MiB"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the report.
+	exceeded := func(path string) bool {
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasSuffix(line, " "+path) {
+				return strings.Contains(line, "exceeded")
+			}
+		}
+		return false
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"delta first", true,
+			strings.Index(out, "foo/bar/delta") < strings.Index(out, "foo/alpha")},
+		{"no lfs", false, strings.Contains(out, "foo/beta")},
+		{"delta share", true, strings.Contains(out, "75.0%")},
+		{"delta exceeded", true, exceeded("foo/bar/delta")},
+		{"alpha exceeded", false, exceeded("foo/alpha")},
+		{"total", true, strings.Contains(out, "LFS objects use 4.0 MiB in 2 of 5 project(s).")},
+		{"flagged", true, strings.Contains(out, "1 project(s) exceed the threshold of 2.0 MiB.")},
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("projects report lfs %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
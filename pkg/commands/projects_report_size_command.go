@@ -0,0 +1,214 @@
+// This file provides the implementation for the "projects report
+// size" command which reports how much storage each project uses so
+// the projects using the most storage can be found.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsReportSizeOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsReportSizeOptions are the options needed by this command.
+type ProjectsReportSizeOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Limit is the maximum number of projects to report.  Defaults to
+	// 0 which means all projects are reported.
+	Limit uint64 `xml:"limit"`
+
+	// SortBy is the size by which the projects are sorted from
+	// largest to smallest.  It must be one of "storage",
+	// "repository", "artifacts", "packages", or "lfs".  Defaults to
+	// "storage" which is the total storage used by the project.
+	SortBy string `xml:"sort-by"`
+}
+
+// Initialize initializes this ProjectsReportSizeOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsReportSizeOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --limit
+	flags.Uint64Var(&opts.Limit, "limit", opts.Limit,
+		i18n.T("maximum number of projects to report or 0 for all projects"))
+
+	// --sort-by
+	if opts.SortBy == "" {
+		opts.SortBy = "storage"
+	}
+	flags.StringVar(&opts.SortBy, "sort-by", opts.SortBy,
+		i18n.T("size to sort by which is one of "+
+			"storage, repository, artifacts, packages, or lfs"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsReportSizeCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsReportSizeCommand implements the "projects report size"
+// command which reports how much storage each project uses.
+type ProjectsReportSizeCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsReportSizeOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsReportSizeCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects report size [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the storage used by each selected project sorted from\n")
+	i18n.Fprintf(out, "    largest to smallest.  Reading project statistics requires at\n")
+	i18n.Fprintf(out, "    least the Reporter role in each project.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Size Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsReportSizeCommand returns a new, initialized
+// ProjectsReportSizeCommand instance.
+func NewProjectsReportSizeCommand(
+	name string,
+	opts *ProjectsReportSizeOptions,
+	session *Session,
+) *ProjectsReportSizeCommand {
+
+	// Create the new command.
+	cmd := &ProjectsReportSizeCommand{
+		GitlabCommand: GitlabCommand[ProjectsReportSizeOptions]{
+			BasicCommand: BasicCommand[ProjectsReportSizeOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// PrintProjectSizes prints the project sizes as a table followed by
+// the total of each column.
+func PrintProjectSizes(out io.Writer, sizes []*ProjectSize) {
+	row := func(storage, repository, artifacts, packages, lfs, path string) {
+		fmt.Fprintf(out, "%12s %12s %12s %12s %12s  %s\n",
+			storage, repository, artifacts, packages, lfs, path)
+	}
+	row(i18n.T("STORAGE"), i18n.T("REPOSITORY"), i18n.T("ARTIFACTS"),
+		i18n.T("PACKAGES"), i18n.T("LFS"), i18n.T("PROJECT"))
+	var total gitlab.Statistics
+	for _, size := range sizes {
+		s := &size.Statistics
+		row(FormatSize(s.StorageSize), FormatSize(s.RepositorySize),
+			FormatSize(s.JobArtifactsSize), FormatSize(s.PackagesSize),
+			FormatSize(s.LFSObjectsSize), size.Path)
+		total.StorageSize += s.StorageSize
+		total.RepositorySize += s.RepositorySize
+		total.JobArtifactsSize += s.JobArtifactsSize
+		total.PackagesSize += s.PackagesSize
+		total.LFSObjectsSize += s.LFSObjectsSize
+	}
+	row(FormatSize(total.StorageSize), FormatSize(total.RepositorySize),
+		FormatSize(total.JobArtifactsSize), FormatSize(total.PackagesSize),
+		FormatSize(total.LFSObjectsSize),
+		i18n.Sprintf("(total of %d project(s))", len(sizes)))
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsReportSizeCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if _, ok := projectSizeColumns[cmd.options.SortBy]; !ok {
+		return result, i18n.Errorf("%w: invalid sort-by: %q",
+			ErrInvalidOption, cmd.options.SortBy)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the size of each selected project.
+	sizes, err := GetProjectSizes(
+		ctx, result, &cmd.options.ProjectSelectorOptions,
+		cmd.client.Groups, cmd.client.Projects)
+	if err != nil {
+		return result, err
+	}
+
+	// Print the report.
+	err = SortProjectSizes(sizes, cmd.options.SortBy)
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Limit > 0 && uint64(len(sizes)) > cmd.options.Limit {
+		sizes = sizes[:cmd.options.Limit]
+	}
+	PrintProjectSizes(os.Stdout, sizes)
+	return result, nil
+}
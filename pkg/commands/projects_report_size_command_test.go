@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestProjectsReportSizeIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	server.SetStatistics("foo/alpha", gitlab.Statistics{
+		StorageSize: 3 << 20, RepositorySize: 2 << 20, LFSObjectsSize: 1 << 20})
+	server.SetStatistics("foo/beta", gitlab.Statistics{
+		StorageSize: 5 << 10, RepositorySize: 5 << 10})
+	server.SetStatistics("foo/bar/delta", gitlab.Statistics{
+		StorageSize: 10 << 20, JobArtifactsSize: 10 << 20})
+
+	// Report the projects using the most storage.
+	run := func(args ...string) string {
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		out, _, err := runCommand(t, cmd, append([]string{
+			"report", "size", "--group", "foo", "-r"}, args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+	bySize := run("--limit", "2")
+	byLFS := run("--sort-by", "lfs")
+
+	// Verify the order and contents of the reports.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"delta first", true,
+			strings.Index(bySize, "foo/bar/delta") < strings.Index(bySize, "foo/alpha")},
+		{"limit", false, strings.Contains(bySize, "foo/beta")},
+		{"delta size", true, strings.Contains(bySize, "10.0 MiB")},
+		{"total", true, strings.Contains(bySize, "13.0 MiB")},
+		{"alpha first by lfs", true,
+			strings.Index(byLFS, "foo/alpha") < strings.Index(byLFS, "foo/bar/delta")},
+		{"beta size", true, strings.Contains(byLFS, "5.0 KiB")},
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("projects report size %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,512 @@
+// This file provides the implementation for the "projects scaffold"
+// command which creates a project, seeds it with an initial commit
+// from a local template directory, and applies the standard settings,
+// branch protections, and approval rules in one shot.
+
+package commands
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsScaffoldOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsScaffoldOptions are the options needed by this command.
+type ProjectsScaffoldOptions struct {
+
+	// ApprovalRuleName is the name of the approval rule that is
+	// created if Approvals or Approvers is set.  Defaults to
+	// "Default".
+	ApprovalRuleName string `xml:"approval-rule-name"`
+
+	// Approvals is the number of approvals required by the approval
+	// rule.  Defaults to 0.
+	Approvals int `xml:"approvals"`
+
+	// Approvers are the usernames of the eligible approvers of the
+	// approval rule.
+	Approvers string_slice.StringSlice `xml:"approvers>approver"`
+
+	// DefaultBranch is the default branch of the new project which
+	// receives the initial commit.  Defaults to "main".
+	DefaultBranch string `xml:"default-branch"`
+
+	// Description is the description of the new project.  Defaults
+	// to "".
+	Description string `xml:"description"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Name is the path of the new project relative to ParentGroup.
+	// Defaults to "".
+	Name string `xml:"name"`
+
+	// OnlyAllowMergeIfAllDiscussionsAreResolved controls whether
+	// merge requests can only be merged after all discussions are
+	// resolved.  Defaults to false.
+	OnlyAllowMergeIfAllDiscussionsAreResolved bool `xml:"only-allow-merge-if-all-discussions-are-resolved"`
+
+	// OnlyAllowMergeIfPipelineSucceeds controls whether merge
+	// requests can only be merged after their pipeline succeeds.
+	// Defaults to false.
+	OnlyAllowMergeIfPipelineSucceeds bool `xml:"only-allow-merge-if-pipeline-succeeds"`
+
+	// ParentGroup is the group where the project will be created.
+	// The parent group must already exist.  Defaults to "".
+	ParentGroup string `xml:"parent-group"`
+
+	// ProtectedBranches are the branches to protect so only
+	// maintainers can push and developers can merge.  Defaults to
+	// only the default branch.
+	ProtectedBranches string_slice.StringSlice `xml:"protected-branches>branch"`
+
+	// RemoveSourceBranchAfterMerge controls whether the source branch
+	// of a merge request is removed by default after it is merged.
+	// Defaults to false.
+	RemoveSourceBranchAfterMerge bool `xml:"remove-source-branch-after-merge"`
+
+	// TemplateDir is the local directory whose files (e.g., README,
+	// CI configuration, and CODEOWNERS) are committed to the new
+	// project.  Defaults to "" which means no initial commit is made.
+	TemplateDir string `xml:"template-dir"`
+
+	// Visibility is the visibility of the new project which is
+	// "private", "internal", or "public".  Defaults to "private".
+	Visibility string `xml:"visibility"`
+}
+
+// Initialize initializes this ProjectsScaffoldOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsScaffoldOptions) Initialize(flags *flag.FlagSet) {
+
+	// --approval-rule-name
+	if opts.ApprovalRuleName == "" {
+		opts.ApprovalRuleName = "Default"
+	}
+	flags.StringVar(&opts.ApprovalRuleName, "approval-rule-name", opts.ApprovalRuleName,
+		i18n.T("name of the approval rule"))
+
+	// --approvals
+	flags.IntVar(&opts.Approvals, "approvals", opts.Approvals,
+		i18n.T("number of approvals required by the approval rule"))
+
+	// --approvers
+	flags.Var(&opts.Approvers, "approvers",
+		i18n.T("comma-separated usernames of the eligible approvers of the approval rule"))
+
+	// --default-branch
+	if opts.DefaultBranch == "" {
+		opts.DefaultBranch = "main"
+	}
+	flags.StringVar(&opts.DefaultBranch, "default-branch", opts.DefaultBranch,
+		i18n.T("default branch of the new project"))
+
+	// --description
+	flags.StringVar(&opts.Description, "description", opts.Description,
+		i18n.T("description of the new project"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("path of the new project relative to the parent group"))
+
+	// --only-allow-merge-if-all-discussions-are-resolved
+	flags.BoolVar(&opts.OnlyAllowMergeIfAllDiscussionsAreResolved,
+		"only-allow-merge-if-all-discussions-are-resolved",
+		opts.OnlyAllowMergeIfAllDiscussionsAreResolved,
+		i18n.T("whether merge requests can only be merged after all discussions are resolved"))
+
+	// --only-allow-merge-if-pipeline-succeeds
+	flags.BoolVar(&opts.OnlyAllowMergeIfPipelineSucceeds,
+		"only-allow-merge-if-pipeline-succeeds",
+		opts.OnlyAllowMergeIfPipelineSucceeds,
+		i18n.T("whether merge requests can only be merged after their pipeline succeeds"))
+
+	// --parent-group
+	flags.StringVar(&opts.ParentGroup, "parent-group", opts.ParentGroup,
+		i18n.T("parent group for the new project"))
+
+	// --protected-branches
+	flags.Var(&opts.ProtectedBranches, "protected-branches",
+		i18n.T("comma-separated branches to protect instead of the default branch"))
+
+	// --remove-source-branch-after-merge
+	flags.BoolVar(&opts.RemoveSourceBranchAfterMerge,
+		"remove-source-branch-after-merge",
+		opts.RemoveSourceBranchAfterMerge,
+		i18n.T("whether source branches are removed by default after merging"))
+
+	// --template-dir
+	flags.StringVar(&opts.TemplateDir, "template-dir", opts.TemplateDir,
+		i18n.T("local directory whose files are committed to the new project"))
+
+	// --visibility
+	if opts.Visibility == "" {
+		opts.Visibility = string(gitlab.PrivateVisibility)
+	}
+	flags.StringVar(&opts.Visibility, "visibility", opts.Visibility,
+		i18n.T("visibility of the new project which is private, internal, or public"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsScaffoldCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsScaffoldCommand implements the "projects scaffold" command
+// which creates a project, seeds it with an initial commit from a
+// local template directory, and applies the standard settings, branch
+// protections, and approval rules in one shot.
+type ProjectsScaffoldCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsScaffoldOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsScaffoldCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects scaffold [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Creates the project --name in --parent-group, commits the\n")
+	i18n.Fprintf(out, "    files in --template-dir to its default branch, protects\n")
+	i18n.Fprintf(out, "    its branches, and creates its approval rule.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Scaffold Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsScaffoldCommand returns a new, initialized
+// ProjectsScaffoldCommand instance.
+func NewProjectsScaffoldCommand(
+	name string,
+	opts *ProjectsScaffoldOptions,
+	session *Session,
+) *ProjectsScaffoldCommand {
+
+	// Create the new command.
+	cmd := &ProjectsScaffoldCommand{
+		GitlabCommand: GitlabCommand[ProjectsScaffoldOptions]{
+			BasicCommand: BasicCommand[ProjectsScaffoldOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectScaffoldServices are the Gitlab services needed to scaffold
+// a project.
+type ProjectScaffoldServices struct {
+	Groups            gitlab_util.GroupFinder     /* was *gitlab.GroupsService */
+	Projects          ProjectScaffolder           /* was *gitlab.ProjectsService */
+	Commits           gitlab_util.CommitCreator   /* was *gitlab.CommitsService */
+	ProtectedBranches gitlab_util.BranchProtector /* was *gitlab.ProtectedBranchesService */
+	Users             gitlab_util.UserFinder      /* was *gitlab.UsersService */
+}
+
+// ProjectScaffolder is an abstraction of gitlab.ProjectsService which
+// creates projects and their approval rules.
+type ProjectScaffolder interface {
+	gitlab_util.ProjectCreator
+	gitlab_util.ApprovalRuleCreator
+}
+
+// ReadScaffoldFiles returns a commit action that creates each regular
+// file found recursively in the directory.  The ".git" directory is
+// skipped.  Files that are not valid UTF-8 are base64 encoded.
+func ReadScaffoldFiles(dir string) ([]*gitlab.CommitActionOptions, error) {
+	var result []*gitlab.CommitActionOptions
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		action := &gitlab.CommitActionOptions{
+			Action:   gitlab.Ptr(gitlab.FileCreate),
+			FilePath: gitlab.Ptr(filepath.ToSlash(rel)),
+			Content:  gitlab.Ptr(string(content)),
+		}
+		if !utf8.Valid(content) {
+			action.Content = gitlab.Ptr(base64.StdEncoding.EncodeToString(content))
+			action.Encoding = gitlab.Ptr("base64")
+		}
+		result = append(result, action)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ReadScaffoldFiles: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, i18n.Errorf(
+			"%w: no files found in %q", ErrInvalidOption, dir)
+	}
+	return result, nil
+}
+
+// ScaffoldProject creates the project described by opts, commits the
+// files to its default branch, protects its branches, and creates its
+// approval rule.  If dryRun is true, this function only prints what
+// it would without actually doing it.
+func ScaffoldProject(
+	ctx context.Context,
+	result *Result,
+	s *ProjectScaffoldServices,
+	opts *ProjectsScaffoldOptions,
+	files []*gitlab.CommitActionOptions,
+	dryRun bool,
+) error {
+
+	// Get the parent group.
+	logging.Printf("- Searching for ID for parent group %q ... ", opts.ParentGroup)
+	g, err := gitlab_util.FindExactGroup(ctx, s.Groups, opts.ParentGroup)
+	if err != nil {
+		return err
+	}
+	logging.Printf("Done.\n")
+	fullPath := g.FullPath + "/" + opts.Name
+
+	// Look up the approvers before creating anything so a typo does
+	// not leave a half-scaffolded project behind.
+	var approverIDs []int
+	for _, username := range opts.Approvers {
+		users, err := gitlab_util.FindUsers(ctx, s.Users, username, true, time.Time{})
+		if err != nil {
+			result.Fail(fullPath, nil, err)
+			return fmt.Errorf("ScaffoldProject: %w", err)
+		}
+		approverIDs = append(approverIDs, users[0].ID)
+	}
+
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(fullPath)
+	fail := func(err error) error {
+		err = fmt.Errorf("ScaffoldProject: %w", gitlab_util.ClassifyError(err))
+		hook.OnError(fullPath, err)
+		result.Fail(fullPath, nil, err)
+		return err
+	}
+
+	// Create the project with the standard settings.
+	logging.Printf("- Creating project %q ... ", fullPath)
+	var pid interface{} = fullPath
+	if !dryRun {
+		p, _, err := s.Projects.CreateProject(
+			&gitlab.CreateProjectOptions{
+				NamespaceID:   gitlab.Ptr(g.ID),
+				Path:          gitlab.Ptr(opts.Name),
+				DefaultBranch: gitlab.Ptr(opts.DefaultBranch),
+				Description:   gitlab.Ptr(opts.Description),
+				Visibility:    gitlab.Ptr(gitlab.VisibilityValue(opts.Visibility)),
+
+				OnlyAllowMergeIfAllDiscussionsAreResolved: gitlab.Ptr(
+					opts.OnlyAllowMergeIfAllDiscussionsAreResolved),
+				OnlyAllowMergeIfPipelineSucceeds: gitlab.Ptr(
+					opts.OnlyAllowMergeIfPipelineSucceeds),
+				RemoveSourceBranchAfterMerge: gitlab.Ptr(
+					opts.RemoveSourceBranchAfterMerge),
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return fail(err)
+		}
+		pid = p.ID
+	}
+	logging.Printf("Done.\n")
+
+	// Seed the repository.
+	if len(files) > 0 {
+		logging.Printf("- Committing %d file(s) to branch %q ... ",
+			len(files), opts.DefaultBranch)
+		if !dryRun {
+			_, _, err := s.Commits.CreateCommit(pid,
+				&gitlab.CreateCommitOptions{
+					Branch:        gitlab.Ptr(opts.DefaultBranch),
+					CommitMessage: gitlab.Ptr("Initial commit"),
+					Actions:       files,
+				},
+				gitlab.WithContext(ctx))
+			if err != nil {
+				return fail(err)
+			}
+		}
+		logging.Printf("Done.\n")
+	}
+
+	// Protect the branches.  Gitlab may already protect the default
+	// branch when the project is created which is not an error.
+	branches := opts.ProtectedBranches
+	if len(branches) == 0 {
+		branches = string_slice.StringSlice{opts.DefaultBranch}
+	}
+	for _, branch := range branches {
+		logging.Printf("- Protecting branch %q ... ", branch)
+		if !dryRun {
+			_, resp, err := s.ProtectedBranches.ProtectRepositoryBranches(pid,
+				&gitlab.ProtectRepositoryBranchesOptions{
+					Name:             gitlab.Ptr(branch),
+					PushAccessLevel:  gitlab.Ptr(gitlab.MaintainerPermissions),
+					MergeAccessLevel: gitlab.Ptr(gitlab.DeveloperPermissions),
+				},
+				gitlab.WithContext(ctx))
+			if err != nil && (resp == nil || resp.StatusCode != http.StatusConflict) {
+				return fail(err)
+			}
+		}
+		logging.Printf("Done.\n")
+	}
+
+	// Create the approval rule.
+	if opts.Approvals > 0 || len(approverIDs) > 0 {
+		logging.Printf("- Creating approval rule %q ... ", opts.ApprovalRuleName)
+		if !dryRun {
+			_, _, err := s.Projects.CreateProjectApprovalRule(pid,
+				&gitlab.CreateProjectLevelRuleOptions{
+					Name:              gitlab.Ptr(opts.ApprovalRuleName),
+					ApprovalsRequired: gitlab.Ptr(opts.Approvals),
+					UserIDs:           &approverIDs,
+				},
+				gitlab.WithContext(ctx))
+			if err != nil {
+				return fail(err)
+			}
+		}
+		logging.Printf("Done.\n")
+	}
+
+	hook.OnItemDone(fullPath)
+	result.Succeed(fullPath, nil)
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsScaffoldCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	switch {
+	case cmd.options.ParentGroup == "":
+		return result, i18n.Errorf("%w: parent-group not set", ErrInvalidOption)
+	case cmd.options.Name == "":
+		return result, i18n.Errorf("%w: name not set", ErrInvalidOption)
+	case cmd.options.DefaultBranch == "":
+		return result, i18n.Errorf("%w: default-branch not set", ErrInvalidOption)
+	case cmd.options.Approvals < 0:
+		return result, i18n.Errorf("%w: invalid approvals: %v",
+			ErrInvalidOption, cmd.options.Approvals)
+	}
+	switch gitlab.VisibilityValue(cmd.options.Visibility) {
+	case gitlab.PrivateVisibility, gitlab.InternalVisibility, gitlab.PublicVisibility:
+	default:
+		return result, i18n.Errorf("%w: invalid visibility: %q",
+			ErrInvalidOption, cmd.options.Visibility)
+	}
+
+	// Read the template files.
+	var files []*gitlab.CommitActionOptions
+	if cmd.options.TemplateDir != "" {
+		files, err = ReadScaffoldFiles(cmd.options.TemplateDir)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Scaffold the project.
+	err = ScaffoldProject(
+		ctx,
+		result,
+		&ProjectScaffoldServices{
+			Groups:            cmd.client.Groups,
+			Projects:          cmd.client.Projects,
+			Commits:           cmd.client.Commits,
+			ProtectedBranches: cmd.client.ProtectedBranches,
+			Users:             cmd.client.Users,
+		},
+		cmd.options,
+		files,
+		cmd.options.DryRun)
+	return result, err
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestProjectsScaffoldIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+
+	// Create the template directory.
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"README.md":       "# Service\n",
+		".gitlab-ci.yml":  "stages: [test]\n",
+		"docs/CODEOWNERS": "* @aberns\n",
+		"logo.bin":        "\xff\xfe\x00",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err == nil {
+			err = os.WriteFile(path, []byte(content), 0644)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Scaffold the project.
+	cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+	_, _, err := runCommand(t, cmd, []string{"scaffold",
+		"--parent-group", "foo/bar", "--name", "svc",
+		"--template-dir", dir, "--default-branch", "trunk",
+		"--only-allow-merge-if-pipeline-succeeds",
+		"--approvals", "2", "--approvers", "aberns,bcrocket"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the project.
+	p := server.Project("foo/bar/svc")
+	if p == nil {
+		t.Fatalf("projects scaffold: project not created")
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"default branch", "trunk", p.DefaultBranch},
+		{"visibility", gitlab.PrivateVisibility, p.Visibility},
+		{"pipeline must succeed", true, p.OnlyAllowMergeIfPipelineSucceeds},
+		{"commits", 1, server.Commits("foo/bar/svc")},
+		{"protected branches", "[trunk]", fmt.Sprint(server.ProtectedBranches("foo/bar/svc"))},
+	}
+	for _, name := range []string{"README.md", ".gitlab-ci.yml", "docs/CODEOWNERS", "logo.bin"} {
+		content, _ := server.File("foo/bar/svc", name)
+		expected, _ := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		data = append(data, Data{{name, string(expected), content}}...)
+	}
+	rules := server.ApprovalRules("foo/bar/svc")
+	if len(rules) != 1 {
+		t.Fatalf("projects scaffold approval rules: expected=1  actual=%v", len(rules))
+	}
+	data = append(data, Data{
+		{"approval rule name", "Default", rules[0].Name},
+		{"approvals", 2, rules[0].ApprovalsRequired},
+		{"approvers", "[aberns bcrocket]",
+			fmt.Sprint(gitlab_util.GetApprovalRuleUsernames(rules[0]))},
+	}...)
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("projects scaffold %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
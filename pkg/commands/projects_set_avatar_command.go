@@ -0,0 +1,233 @@
+// This file provides the implementation for the "projects set-avatar"
+// command which uploads an avatar image to the selected projects.
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsSetAvatarOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsSetAvatarOptions are the options needed by this command.
+type ProjectsSetAvatarOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Archived controls whether only archived projects are selected.
+	// Defaults to false.
+	Archived bool `xml:"archived"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// FileName is the name of the local image file that is uploaded
+	// as the avatar.  Defaults to "".
+	FileName string `xml:"file-name"`
+}
+
+// Initialize initializes this ProjectsSetAvatarOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsSetAvatarOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --archived
+	flags.BoolVar(&opts.Archived, "archived", opts.Archived,
+		i18n.T("whether to select only archived projects"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --file
+	flags.StringVar(&opts.FileName, "file", opts.FileName,
+		i18n.T("local image file that is uploaded as the avatar"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsSetAvatarCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsSetAvatarCommand implements the "projects set-avatar"
+// command which uploads an avatar to the selected projects.
+type ProjectsSetAvatarCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsSetAvatarOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsSetAvatarCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects set-avatar [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Upload the image in the --file as the avatar of each of\n")
+	i18n.Fprintf(out, "    the selected projects.  If --archived is set, only\n")
+	i18n.Fprintf(out, "    archived projects are selected.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Set-Avatar Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsSetAvatarCommand returns a new, initialized
+// ProjectsSetAvatarCommand instance.
+func NewProjectsSetAvatarCommand(
+	name string,
+	opts *ProjectsSetAvatarOptions,
+	session *Session,
+) *ProjectsSetAvatarCommand {
+
+	// Create the new command.
+	cmd := &ProjectsSetAvatarCommand{
+		GitlabCommand: GitlabCommand[ProjectsSetAvatarOptions]{
+			BasicCommand: BasicCommand[ProjectsSetAvatarOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SetProjectAvatar uploads the image having the file name and content
+// as the avatar of the project.  If dryRun is true, this function only
+// prints what it would without actually doing it.
+func SetProjectAvatar(
+	ctx context.Context,
+	s gitlab_util.ProjectAvatarUploader, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	fileName string,
+	content []byte,
+	dryRun bool,
+) error {
+	logging.Printf("- Setting avatar of %q ... ", p.PathWithNamespace)
+	if !dryRun {
+		_, _, err := s.UploadAvatar(p.ID, bytes.NewReader(content), fileName,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf(
+				"SetProjectAvatar: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsSetAvatarCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.FileName == "" {
+		return result, i18n.Errorf("%w: file not set", ErrInvalidOption)
+	}
+
+	// Read the image.
+	content, err := os.ReadFile(cmd.options.FileName)
+	if err != nil {
+		return result, err
+	}
+	fileName := filepath.Base(cmd.options.FileName)
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the projects.
+	projects, err := cmd.options.GetAllProjects(ctx, cmd.client.Groups)
+	if err != nil {
+		return result, err
+	}
+
+	// Set the avatars.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, p := range projects {
+		if cmd.options.Archived && !p.Archived {
+			continue
+		}
+		hook.OnItemStart(p.PathWithNamespace)
+		err = SetProjectAvatar(ctx, cmd.client.Projects, p, fileName, content,
+			cmd.options.DryRun)
+		if err != nil {
+			hook.OnError(p.PathWithNamespace, err)
+			result.Fail(p.PathWithNamespace, p, err)
+			return result, err
+		}
+		hook.OnItemDone(p.PathWithNamespace)
+		result.Succeed(p.PathWithNamespace, p)
+	}
+
+	return result, nil
+}
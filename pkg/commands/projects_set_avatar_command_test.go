@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/internal/fake_gitlab"
+)
+
+func TestSetAvatarIntegration(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "grey.png")
+	err := os.WriteFile(fileName, []byte("fake-png"), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := func(server *fake_gitlab.Server, kind string, args ...string) error {
+		session := NewSessionWithClient(server.Client(t))
+		var cmd Runner
+		if kind == "groups" {
+			cmd = NewGroupsCommand("groups", &GroupsOptions{}, session)
+		} else {
+			cmd = NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		}
+		var err error
+		captureStdout(t, func() {
+			_, err = cmd.Run(context.Background(),
+				append([]string{"set-avatar", "--file", fileName}, args...))
+		})
+		return err
+	}
+
+	// Brand the archived projects with and without --dry-run.
+	dryRun := newFakeServer(t)
+	dryRun.SetArchived("foo/beta", true)
+	err = run(dryRun, "projects", "--group", "foo", "-r", "--archived", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := newFakeServer(t)
+	server.SetArchived("foo/beta", true)
+	server.SetArchived("foo/bar/delta", true)
+	err = run(server, "projects", "--group", "foo", "-r", "--archived")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Set the avatars of the subgroups only.
+	err = run(server, "groups", "--group", "foo", "-r", "--expr", "^foo/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	missingErr := run(server, "groups", "--group", "foo", "--file", "")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"dry run", "", dryRun.Avatar("project", "foo/beta")},
+		{"archived", "fake-png", server.Avatar("project", "foo/beta")},
+		{"archived subgroup project", "fake-png", server.Avatar("project", "foo/bar/delta")},
+		{"not archived", "", server.Avatar("project", "foo/alpha")},
+		{"avatar url", true,
+			strings.HasSuffix(server.Project("foo/beta").AvatarURL, "/grey.png")},
+		{"subgroup", "fake-png", server.Avatar("group", "foo/bar")},
+		{"unmatched group", "", server.Avatar("group", "foo")},
+		{"missing file", true, errors.Is(missingErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("set-avatar %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
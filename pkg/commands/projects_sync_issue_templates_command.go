@@ -0,0 +1,223 @@
+// This file provides the implementation for the "projects
+// sync-issue-templates" command which pushes a set of issue
+// templates from a local directory to the selected projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsSyncIssueTemplatesOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsSyncIssueTemplatesOptions are the options needed by this
+// command.
+type ProjectsSyncIssueTemplatesOptions struct {
+
+	// Embed the options that select the target projects.
+	ProjectSelectorOptions
+
+	// Branch is the branch to which the templates are committed.
+	// Defaults to "" which means the default branch of each project.
+	Branch string `xml:"branch"`
+
+	// CommitMessage is the message of the commit that updates the
+	// templates.  Defaults to "Update issue templates".
+	CommitMessage string `xml:"commit-message"`
+
+	// Diff should cause the command to only print which templates are
+	// missing or out of date in each project.  Defaults to false.
+	Diff bool `xml:"diff"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// TemplatesDir is the local directory holding the *.md issue
+	// templates.  Defaults to "".
+	TemplatesDir string `xml:"templates-dir"`
+}
+
+// Initialize initializes this ProjectsSyncIssueTemplatesOptions instance
+// so it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsSyncIssueTemplatesOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --branch
+	flags.StringVar(&opts.Branch, "branch", opts.Branch,
+		i18n.T("branch to commit to instead of the default branch of each project"))
+
+	// --commit-message
+	if opts.CommitMessage == "" {
+		opts.CommitMessage = "Update issue templates"
+	}
+	flags.StringVar(&opts.CommitMessage, "commit-message", opts.CommitMessage,
+		i18n.T("message of the commit that updates the templates"))
+
+	// --diff
+	flags.BoolVar(&opts.Diff, "diff", opts.Diff,
+		i18n.T("only print which templates are missing or out of date"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --templates-dir
+	flags.StringVar(&opts.TemplatesDir, "templates-dir", opts.TemplatesDir,
+		i18n.T("local directory holding the *.md issue templates"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsSyncIssueTemplatesCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsSyncIssueTemplatesCommand implements the "projects
+// sync-issue-templates" command which pushes a set of issue
+// templates from a local directory to the selected projects.
+type ProjectsSyncIssueTemplatesCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsSyncIssueTemplatesOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsSyncIssueTemplatesCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects sync-issue-templates [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Commits the *.md files in --templates-dir to %s\n",
+		IssueTemplatesDir)
+	i18n.Fprintf(out, "    in each project selected by --group, --expr, and\n")
+	i18n.Fprintf(out, "    --recursive.  Templates that are already up to date are\n")
+	i18n.Fprintf(out, "    left alone, and templates that only exist in a project\n")
+	i18n.Fprintf(out, "    are never deleted.  Use --diff to see which projects have\n")
+	i18n.Fprintf(out, "    missing or out of date templates without writing anything.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Sync Issue Templates Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsSyncIssueTemplatesCommand returns a new, initialized
+// ProjectsSyncIssueTemplatesCommand instance.
+func NewProjectsSyncIssueTemplatesCommand(
+	name string,
+	opts *ProjectsSyncIssueTemplatesOptions,
+	session *Session,
+) *ProjectsSyncIssueTemplatesCommand {
+
+	// Create the new command.
+	cmd := &ProjectsSyncIssueTemplatesCommand{
+		GitlabCommand: GitlabCommand[ProjectsSyncIssueTemplatesOptions]{
+			BasicCommand: BasicCommand[ProjectsSyncIssueTemplatesOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsSyncIssueTemplatesCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.TemplatesDir == "" {
+		return result, i18n.Errorf("%w: templates-dir not set", ErrInvalidOption)
+	}
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Read the templates.
+	templates, err := ReadRepositoryTemplates(cmd.options.TemplatesDir)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Sync the templates to each selected project.
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			err := SyncRepositoryTemplates(
+				ctx,
+				result,
+				cmd.client.RepositoryFiles,
+				cmd.client.Commits,
+				p,
+				IssueTemplatesDir,
+				templates,
+				cmd.options.Branch,
+				cmd.options.CommitMessage,
+				cmd.options.Diff,
+				cmd.options.DryRun)
+			return err == nil, err
+		})
+	return result, err
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProjectsSyncIssueTemplatesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddFile("foo/alpha", ".gitlab/issue_templates/Bug.md", "old")
+	server.AddFile("foo/beta", ".gitlab/issue_templates/Bug.md", "bug")
+	session := NewSessionWithClient(server.Client(t))
+
+	// Create the template.
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "Bug.md"), []byte("bug"), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	run := func(args ...string) string {
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		out, _, err := runCommand(t, cmd, append([]string{
+			"sync-issue-templates", "--templates-dir", dir, "--group", "foo"},
+			args...))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+
+	// Verify --diff reports the outdated and missing templates
+	// without writing anything.
+	out := run("--diff")
+	for _, expected := range []string{
+		"outdated: Bug.md",
+		"missing:  Bug.md",
+		`"foo/beta" are up to date`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("projects sync-issue-templates --diff: expected=%q  actual=%q",
+				expected, out)
+		}
+	}
+	if actual := server.Commits("foo/alpha"); actual != 0 {
+		t.Errorf("projects sync-issue-templates --diff commits: expected=0  actual=%v",
+			actual)
+	}
+
+	// Sync the templates and verify the differences are gone.
+	run()
+	for _, p := range []string{"foo/alpha", "foo/beta", "foo/test-gamma"} {
+		actual, _ := server.File(p, IssueTemplatesDir+"/Bug.md")
+		if actual != "bug" {
+			t.Errorf("projects sync-issue-templates %s: expected=%q  actual=%q",
+				p, "bug", actual)
+		}
+	}
+	out = run("--diff")
+	if strings.Contains(out, "Bug.md") {
+		t.Errorf("projects sync-issue-templates --diff: unexpected output %q", out)
+	}
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectsSyncMRTemplatesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddFile("foo/alpha", ".gitlab/merge_request_templates/Default.md", "old")
+	server.AddFile("foo/beta", ".gitlab/merge_request_templates/Bug.md", "bug")
+	session := NewSessionWithClient(server.Client(t))
+
+	// Create the templates.
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"Bug.md":     "bug",
+		"Default.md": "default",
+		"notes.txt":  "ignored",
+	} {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Sync the templates twice to make sure the second time does not
+	// create any commits.
+	for i := 0; i < 2; i++ {
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		_, _, err := runCommand(t, cmd, []string{"sync-mr-templates",
+			"--templates-dir", dir, "--group", "foo"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Verify the templates were synced in a single commit per
+	// project.
+	type Data []struct {
+		project  string
+		commits  int
+		expected map[string]string
+	}
+	data := Data{
+		{"foo/alpha", 1, map[string]string{"Bug.md": "bug", "Default.md": "default"}},
+		{"foo/beta", 1, map[string]string{"Bug.md": "bug", "Default.md": "default"}},
+		{"foo/test-gamma", 1, map[string]string{"Bug.md": "bug", "Default.md": "default"}},
+		{"foo/bar/delta", 0, map[string]string{}},
+	}
+	for _, d := range data {
+		if actual := server.Commits(d.project); actual != d.commits {
+			t.Errorf("projects sync-mr-templates %s commits: expected=%v  actual=%v",
+				d.project, d.commits, actual)
+		}
+		for name, expected := range d.expected {
+			actual, _ := server.File(d.project, MergeRequestTemplatesDir+"/"+name)
+			if actual != expected {
+				t.Errorf("projects sync-mr-templates %s %s: expected=%q  actual=%q",
+					d.project, name, expected, actual)
+			}
+		}
+	}
+	if _, ok := server.File("foo/alpha", MergeRequestTemplatesDir+"/notes.txt"); ok {
+		t.Errorf("projects sync-mr-templates: unexpected notes.txt")
+	}
+}
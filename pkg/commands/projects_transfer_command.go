@@ -0,0 +1,274 @@
+// This file provides the implementation for the "projects transfer"
+// command which moves the projects in a group to another group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsTransferOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsTransferOptions are the options needed by this command.
+type ProjectsTransferOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ToGroup is the full path or ID of the group to which the
+	// projects are moved.  Defaults to "".
+	ToGroup string `xml:"to-group"`
+}
+
+// Initialize initializes this ProjectsTransferOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsTransferOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --to-group
+	flags.StringVar(&opts.ToGroup, "to-group", opts.ToGroup,
+		i18n.T("group to which the projects are moved which can be the "+
+			"full path or the group ID"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsTransferCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsTransferCommand implements the "projects transfer" command
+// which moves the projects in a group to another group.
+type ProjectsTransferCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsTransferOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsTransferCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects transfer [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Move the selected projects to --to-group.  Projects already\n")
+	i18n.Fprintf(out, "    in --to-group are skipped.  After the projects are moved,\n")
+	i18n.Fprintf(out, "    each one is looked up by its old path to verify that Gitlab\n")
+	i18n.Fprintf(out, "    redirects the old path to the new one.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Transfer Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsTransferCommand returns a new, initialized
+// ProjectsTransferCommand instance.
+func NewProjectsTransferCommand(
+	name string,
+	opts *ProjectsTransferOptions,
+	session *Session,
+) *ProjectsTransferCommand {
+
+	// Create the new command.
+	cmd := &ProjectsTransferCommand{
+		GitlabCommand: GitlabCommand[ProjectsTransferOptions]{
+			BasicCommand: BasicCommand[ProjectsTransferOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// TransferProject moves the project to the group and returns the moved
+// project.  If dryRun is true, this function only prints what it would
+// do without actually doing it, and the project is returned unchanged.
+func TransferProject(
+	ctx context.Context,
+	s gitlab_util.ProjectTransferrer, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	to *gitlab.Group,
+	dryRun bool,
+) (*gitlab.Project, error) {
+	logging.Printf("- Moving project %q to %q ... ", p.PathWithNamespace, to.FullPath)
+	if dryRun {
+		logging.Printf("Done.\n")
+		return p, nil
+	}
+	moved, _, err := s.TransferProject(p.ID,
+		&gitlab.TransferProjectOptions{Namespace: to.ID},
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		logging.Printf("Failed.\n")
+		return nil, fmt.Errorf("TransferProject: %w", gitlab_util.ClassifyError(err))
+	}
+	logging.Printf("Done.\n")
+	return moved, nil
+}
+
+// VerifyRedirect verifies that the old path of the moved project
+// redirects to it by looking up the project by the old path.
+func VerifyRedirect(
+	ctx context.Context,
+	s gitlab_util.ProjectGetter, /* was *gitlab.ProjectsService */
+	oldPath string,
+	moved *gitlab.Project,
+) error {
+	logging.Printf("- Verifying redirect from %q ... ", oldPath)
+	p, _, err := s.GetProject(oldPath, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		logging.Printf("Failed.\n")
+		return fmt.Errorf("VerifyRedirect: %q: %w", oldPath, gitlab_util.ClassifyError(err))
+	}
+	if p.ID != moved.ID || p.PathWithNamespace != moved.PathWithNamespace {
+		logging.Printf("Failed.\n")
+		return i18n.Errorf("%q redirects to %q instead of %q",
+			oldPath, p.PathWithNamespace, moved.PathWithNamespace)
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsTransferCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.ToGroup == "" {
+		return result, i18n.Errorf("%w: destination group not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Find the destination group.
+	to, err := gitlab_util.FindExactGroup(ctx, cmd.client.Groups, cmd.options.ToGroup)
+	if err != nil {
+		return result, err
+	}
+
+	// Get all of the projects before moving any of them so the moved
+	// projects do not shift the pages still being read.
+	projects, err := cmd.options.GetAllProjects(ctx, cmd.client.Groups)
+	if err != nil {
+		return result, err
+	}
+
+	// Move the projects.  A project that cannot be moved is recorded
+	// in the result, and the remaining projects are still moved.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	moved := make(map[string]*gitlab.Project)
+	var oldPaths []string
+	for _, p := range projects {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if p.Namespace != nil && p.Namespace.ID == to.ID {
+			logging.Printf("- Project %q already in %q.\n", p.PathWithNamespace, to.FullPath)
+			continue
+		}
+		hook.OnItemStart(p.PathWithNamespace)
+		m, err := TransferProject(ctx, cmd.client.Projects, p, to, cmd.options.DryRun)
+		if err != nil {
+			hook.OnError(p.PathWithNamespace, err)
+			result.Fail(p.PathWithNamespace, p, err)
+			continue
+		}
+		hook.OnItemDone(p.PathWithNamespace)
+		moved[p.PathWithNamespace] = m
+		oldPaths = append(oldPaths, p.PathWithNamespace)
+	}
+
+	// Verify that the old path of each moved project redirects to
+	// its new path.  Nothing was moved for --dry-run, so there is
+	// nothing to verify.
+	for _, oldPath := range oldPaths {
+		m := moved[oldPath]
+		if !cmd.options.DryRun {
+			err = VerifyRedirect(ctx, cmd.client.Projects, oldPath, m)
+			if err != nil {
+				result.Fail(oldPath, m, err)
+				continue
+			}
+		}
+		result.Succeed(oldPath, m)
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not transfer %d project(s)", failed)
+	}
+	return result, nil
+}
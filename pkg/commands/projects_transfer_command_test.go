@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProjectsTransferIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddGroup("archive")
+	session := NewSessionWithClient(server.Client(t))
+
+	// run runs the "projects transfer" command with the arguments.
+	run := func(args ...string) error {
+		var err error
+		cmd := NewProjectsTransferCommand("transfer", &ProjectsTransferOptions{}, session)
+		_, _, err = runCommand(t, cmd, args)
+		return err
+	}
+
+	// Verify a dry run does not move the projects.
+	err := run("--group", "foo", "-r", "--expr", "test-", "--to-group", "archive", "-n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"foo/alpha", "foo/beta", "foo/test-gamma", "foo/bar/delta", "foo/bar/test-epsilon"}
+	if actual := server.Projects(); !slices.Equal(expected, actual) {
+		t.Errorf("projects transfer -n: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Move the test projects and verify the old paths redirect.
+	err = run("--group", "foo", "-r", "--expr", "test-", "--to-group", "archive")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = []string{"foo/alpha", "foo/beta", "archive/test-gamma", "foo/bar/delta", "archive/test-epsilon"}
+	if actual := server.Projects(); !slices.Equal(expected, actual) {
+		t.Errorf("projects transfer: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Verify a project whose old path does not redirect is reported
+	// even though it was moved.
+	server.InjectError("GET", "/projects/foo/alpha", http.StatusNotFound, 1)
+	err = run("--group", "foo", "--expr", "alpha", "--to-group", "archive")
+	if err == nil || !strings.Contains(err.Error(), "could not transfer 1 project(s)") {
+		t.Errorf("projects transfer: unexpected error: %v", err)
+	}
+	if p := server.Project("archive/alpha"); p == nil {
+		t.Errorf("projects transfer: expected archive/alpha to exist")
+	}
+
+	// Verify the invalid options.
+	err = run("--group", "foo")
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("projects transfer: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+}
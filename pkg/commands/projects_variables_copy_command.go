@@ -0,0 +1,315 @@
+// This file provides the implementation for the "projects variables
+// copy" command which copies the CI/CD variables of one project to the
+// selected projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsVariablesCopyOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ProjectsVariablesCopyOptions are the options needed by this command.
+type ProjectsVariablesCopyOptions struct {
+
+	// Embed the options that select the target projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// From is the project whose variables are copied which can be
+	// the full path or the project ID.  Defaults to "".
+	From string `xml:"from"`
+
+	// SkipExisting controls whether variables that already exist in
+	// a target project are left alone instead of being overwritten.
+	// Defaults to false.
+	SkipExisting bool `xml:"skip-existing"`
+}
+
+// Initialize initializes this ProjectsVariablesCopyOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ProjectsVariablesCopyOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --from
+	flags.StringVar(&opts.From, "from", opts.From,
+		i18n.T("project whose variables are copied which can be the full path or the project ID"))
+
+	// --skip-existing
+	flags.BoolVar(&opts.SkipExisting, "skip-existing", opts.SkipExisting,
+		i18n.T("whether to leave existing variables alone instead of overwriting them"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ProjectsVariablesCopyCommand
+////////////////////////////////////////////////////////////////////////
+
+// ProjectsVariablesCopyCommand implements the "projects variables
+// copy" command which copies the CI/CD variables of one project to
+// the selected projects.
+type ProjectsVariablesCopyCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ProjectsVariablesCopyOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ProjectsVariablesCopyCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] projects variables copy [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Copies the CI/CD variables of the --from project to the\n")
+	i18n.Fprintf(out, "    projects selected by --group, --expr, and --recursive.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Copy Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewProjectsVariablesCopyCommand returns a new, initialized
+// ProjectsVariablesCopyCommand instance.
+func NewProjectsVariablesCopyCommand(
+	name string,
+	opts *ProjectsVariablesCopyOptions,
+	session *Session,
+) *ProjectsVariablesCopyCommand {
+
+	// Create the new command.
+	cmd := &ProjectsVariablesCopyCommand{
+		GitlabCommand: GitlabCommand[ProjectsVariablesCopyOptions]{
+			BasicCommand: BasicCommand[ProjectsVariablesCopyOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// CopyProjectVariable copies the variable to the project.  If the
+// project already has a variable with the same key and environment
+// scope, it is overwritten unless skipExisting is true.  If dryRun is
+// true, this function only prints what it would without actually
+// doing it.
+func CopyProjectVariable(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.ProjectVariablesManager, /* was *gitlab.ProjectVariablesService */
+	p *gitlab.Project,
+	existing []*gitlab.ProjectVariable,
+	v *gitlab.ProjectVariable,
+	skipExisting bool,
+	dryRun bool,
+) error {
+	name := p.PathWithNamespace + ":" + v.Key
+	exists := slices.ContainsFunc(existing, func(e *gitlab.ProjectVariable) bool {
+		return e.Key == v.Key && e.EnvironmentScope == v.EnvironmentScope
+	})
+
+	// Skip existing variables if requested.
+	if exists && skipExisting {
+		logging.Printf("- Skipping variable %q in project %q (already exists).\n",
+			v.Key, p.PathWithNamespace)
+		return nil
+	}
+
+	// Create or update the variable.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- Copying variable %q to project %q ... ",
+		v.Key, p.PathWithNamespace)
+	if !dryRun {
+		var err error
+		if exists {
+			_, _, err = s.UpdateVariable(p.ID, v.Key,
+				&gitlab.UpdateProjectVariableOptions{
+					Value:            &v.Value,
+					Description:      &v.Description,
+					EnvironmentScope: &v.EnvironmentScope,
+					Filter:           &gitlab.VariableFilter{EnvironmentScope: v.EnvironmentScope},
+					Masked:           &v.Masked,
+					Protected:        &v.Protected,
+					Raw:              &v.Raw,
+					VariableType:     &v.VariableType,
+				},
+				gitlab.WithContext(ctx))
+		} else {
+			_, _, err = s.CreateVariable(p.ID,
+				&gitlab.CreateProjectVariableOptions{
+					Key:              &v.Key,
+					Value:            &v.Value,
+					Description:      &v.Description,
+					EnvironmentScope: &v.EnvironmentScope,
+					Masked:           &v.Masked,
+					Protected:        &v.Protected,
+					Raw:              &v.Raw,
+					VariableType:     &v.VariableType,
+				},
+				gitlab.WithContext(ctx))
+		}
+		if err != nil {
+			err = fmt.Errorf(
+				"CopyProjectVariable: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(name, err)
+			result.Fail(name, p, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	result.Succeed(name, p)
+	return nil
+}
+
+// CopyProjectVariables copies the CI/CD variables of the from project
+// to each project selected by the selector except the from project
+// itself.  If skipExisting is true, variables that already exist in a
+// target project are left alone instead of being overwritten.  If
+// dryRun is true, this function only prints what it would without
+// actually doing it.
+func CopyProjectVariables(
+	ctx context.Context,
+	result *Result,
+	groups gitlab_util.ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	variables gitlab_util.ProjectVariablesManager, /* was *gitlab.ProjectVariablesService */
+	from string,
+	selector *ProjectSelectorOptions,
+	skipExisting bool,
+	dryRun bool,
+) error {
+
+	// Collect the variables to copy.
+	logging.Printf("- Collecting variables from project %q ... ", from)
+	vs, err := gitlab_util.GetAllProjectVariables(ctx, variables, from)
+	if err != nil {
+		return fmt.Errorf("CopyProjectVariables: %w", err)
+	}
+	logging.Printf("Done.\n")
+
+	// Copy the variables to each selected project.
+	err = selector.ForEachProject(ctx, groups, func(p *gitlab.Project) (bool, error) {
+		if p.PathWithNamespace == from || strconv.Itoa(p.ID) == from {
+			return true, nil
+		}
+		existing, err := gitlab_util.GetAllProjectVariables(ctx, variables, p.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, v := range vs {
+			err = CopyProjectVariable(
+				ctx, result, variables, p, existing, v, skipExisting, dryRun)
+			if err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("CopyProjectVariables: %w", err)
+	}
+
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ProjectsVariablesCopyCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.From == "" {
+		return result, i18n.Errorf("%w: from not set", ErrInvalidOption)
+	}
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Copy the variables.
+	err = CopyProjectVariables(
+		ctx,
+		result,
+		cmd.client.Groups,
+		cmd.client.ProjectVariables,
+		cmd.options.From,
+		&cmd.options.ProjectSelectorOptions,
+		cmd.options.SkipExisting,
+		cmd.options.DryRun)
+	return result, err
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestProjectsVariablesCopyIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddProjectVariable("foo/alpha", &gitlab.ProjectVariable{
+		Key: "TOKEN", Value: "secret", EnvironmentScope: "*",
+		Masked: true, Protected: true,
+	})
+	server.AddProjectVariable("foo/alpha", &gitlab.ProjectVariable{
+		Key: "MODE", Value: "ci", EnvironmentScope: "*",
+	})
+	server.AddProjectVariable("foo/beta", &gitlab.ProjectVariable{
+		Key: "MODE", Value: "old", EnvironmentScope: "*",
+	})
+	session := NewSessionWithClient(server.Client(t))
+
+	type Data []struct {
+		args     []string
+		project  string
+		key      string
+		expected string
+	}
+
+	data := Data{
+		{
+			args:     []string{"--skip-existing"},
+			project:  "foo/beta",
+			key:      "MODE",
+			expected: "old",
+		},
+		{
+			args:     []string{"--skip-existing"},
+			project:  "foo/beta",
+			key:      "TOKEN",
+			expected: "secret",
+		},
+		{
+			args:     []string{},
+			project:  "foo/beta",
+			key:      "MODE",
+			expected: "ci",
+		},
+		{
+			args:     []string{},
+			project:  "foo/test-gamma",
+			key:      "TOKEN",
+			expected: "secret",
+		},
+	}
+
+	for _, d := range data {
+		cmd := NewProjectsCommand("projects", &ProjectsOptions{}, session)
+		args := append([]string{"variables", "copy",
+			"--from", "foo/alpha", "--group", "foo"}, d.args...)
+		_, _, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("projects %v: unexpected error: %v", args, err)
+		}
+		v := server.Variable(d.project, d.key)
+		if v == nil || v.Value != d.expected {
+			t.Errorf("projects %v: %s %s: expected=%v  actual=%v",
+				args, d.project, d.key, d.expected, v)
+		}
+	}
+
+	// Verify the flags were copied.
+	v := server.Variable("foo/test-gamma", "TOKEN")
+	if v == nil || !v.Masked || !v.Protected {
+		t.Errorf("projects variables copy flags: expected=%v  actual=%v",
+			"masked and protected", v)
+	}
+}
@@ -0,0 +1,46 @@
+// This file provides the helpers shared by the "releases"
+// subcommands.
+
+package commands
+
+import (
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+// releaseName returns the name used to identify the release in a
+// Result and in messages which is the full path of the project and the
+// tag of the release separated by a colon.
+func releaseName(p *gitlab.Project, tag string) string {
+	return p.PathWithNamespace + ":" + tag
+}
+
+// findRelease returns the release for the tag or nil if there is no
+// such release.
+func findRelease(releases []*gitlab.Release, tag string) *gitlab.Release {
+	for _, r := range releases {
+		if r.TagName == tag {
+			return r
+		}
+	}
+	return nil
+}
+
+// ParseAssetLinks parses the "NAME=URL" asset links of a release.
+func ParseAssetLinks(links []string) ([]*gitlab.ReleaseAssetLinkOptions, error) {
+	var result []*gitlab.ReleaseAssetLinkOptions
+	for _, link := range links {
+		name, url, ok := strings.Cut(link, "=")
+		if !ok || name == "" || url == "" {
+			return nil, i18n.Errorf("%w: invalid asset link: %q",
+				ErrInvalidOption, link)
+		}
+		result = append(result, &gitlab.ReleaseAssetLinkOptions{
+			Name: gitlab.Ptr(name),
+			URL:  gitlab.Ptr(url),
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReleasesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddRelease("foo/beta", "v1.0.0", "Beta 1.0.0")
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Yes = true
+
+	// run runs the "releases" subcommand with the arguments and
+	// returns its output.
+	run := func(args ...string) (string, error) {
+		var err error
+		cmd := NewReleasesCommand("releases", &ReleasesOptions{}, session)
+		output, _, err := runCommand(t, cmd, args)
+		return output, err
+	}
+
+	// tags returns the tags of the releases of the project.
+	tags := func(project string) []string {
+		var result []string
+		for _, r := range server.Releases(project) {
+			result = append(result, r.TagName)
+		}
+		return result
+	}
+
+	// Create a release in the projects that are not test projects
+	// which skips foo/beta because it already has the release.
+	_, err := run("create", "--group", "foo", "-r", "--expr", "/(alpha|beta|delta)$",
+		"--tag", "v1.0.0", "--name", "Release 1.0.0", "--description", "First release.",
+		"--asset-link", "binary=https://example.com/bin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	alpha := server.Releases("foo/alpha")
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"alpha", []string{"v1.0.0"}, tags("foo/alpha")},
+		{"beta", []string{"v1.0.0"}, tags("foo/beta")},
+		{"beta name", "Beta 1.0.0", server.Releases("foo/beta")[0].Name},
+		{"delta", []string{"v1.0.0"}, tags("foo/bar/delta")},
+		{"name", "Release 1.0.0", alpha[0].Name},
+		{"description", "First release.", alpha[0].Description},
+		{"link", "binary https://example.com/bin",
+			alpha[0].Assets.Links[0].Name + " " + alpha[0].Assets.Links[0].URL},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("releases create %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the releases are listed.
+	output, err := run("list", "--group", "foo", "-r", "--tag", "v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := strings.Count(output, "v1.0.0"); n != 3 {
+		t.Errorf("releases list: expected=%v  actual=%v", 3, n)
+	}
+
+	// Verify a dry run does not delete the releases.
+	_, err = run("delete", "--group", "foo", "--tag", "v1.0.0", "-n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual := tags("foo/alpha"); !slices.Equal(actual, []string{"v1.0.0"}) {
+		t.Errorf("releases delete -n: expected=%v  actual=%v", []string{"v1.0.0"}, actual)
+	}
+
+	// Verify no release is deleted after the first one that fails
+	// unless --keep-going is set in which case the failure is
+	// summarized at the end.
+	failPath := fmt.Sprintf("/projects/%d/releases/", server.Project("foo/alpha").ID)
+	server.InjectError(http.MethodDelete, failPath, http.StatusForbidden, 1)
+	_, stopErr := run("delete", "--group", "foo", "--tag", "v1.0.0")
+	stopped := tags("foo/beta")
+	server.InjectError(http.MethodDelete, failPath, http.StatusForbidden, 1)
+	output, keepGoingErr := run("delete", "--group", "foo", "--tag", "v1.0.0",
+		"--keep-going")
+	data = Data{
+		{"stop error", true, stopErr != nil},
+		{"stop beta", []string{"v1.0.0"}, stopped},
+		{"keep-going error", "could not delete 1 release(s)", keepGoingErr},
+		{"keep-going summary", true, strings.Contains(output, "\nFailures:\n")},
+		{"keep-going alpha", []string{"v1.0.0"}, tags("foo/alpha")},
+		{"keep-going beta", []string(nil), tags("foo/beta")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("releases delete %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Delete the releases from the projects directly in foo.
+	_, err = run("delete", "--group", "foo", "--tag", "v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data = Data{
+		{"alpha", []string(nil), tags("foo/alpha")},
+		{"beta", []string(nil), tags("foo/beta")},
+		{"delta", []string{"v1.0.0"}, tags("foo/bar/delta")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("releases delete %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"create", "--group", "foo"},
+		{"create", "--group", "foo", "--tag", "v2", "--asset-link", "binary"},
+		{"delete", "--group", "foo"},
+	} {
+		_, err = run(args...)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("releases %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
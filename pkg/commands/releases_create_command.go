@@ -0,0 +1,282 @@
+// This file provides the implementation for the "releases create"
+// command which creates a release in each of the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ReleasesCreateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ReleasesCreateOptions are the options needed by this command.
+type ReleasesCreateOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// AssetLinks are the "NAME=URL" links added to the assets of the
+	// releases.  Defaults to empty.
+	AssetLinks string_slice.StringSlice `xml:"asset-links>asset-link"`
+
+	// Description is the description of the releases which can be
+	// Markdown.  Defaults to "".
+	Description string `xml:"description"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Name is the name of the releases.  Defaults to "" which means
+	// Gitlab uses the tag as the name.
+	Name string `xml:"name"`
+
+	// Ref is the branch or commit from which the tag is created if
+	// the tag does not exist yet.  Defaults to "" which means the tag
+	// must already exist.
+	Ref string `xml:"ref"`
+
+	// Tag is the tag of the releases.  Defaults to "".
+	Tag string `xml:"tag"`
+}
+
+// Initialize initializes this ReleasesCreateOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ReleasesCreateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --asset-link
+	flags.Var(&opts.AssetLinks, "asset-link",
+		i18n.T("NAME=URL link added to the assets of the releases which "+
+			"can be given multiple times"))
+
+	// --description
+	flags.StringVar(&opts.Description, "description", opts.Description,
+		i18n.T("description of the releases"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("name of the releases (default is the tag)"))
+
+	// --ref
+	flags.StringVar(&opts.Ref, "ref", opts.Ref,
+		i18n.T("branch or commit from which the tag is created if it "+
+			"does not exist"))
+
+	// --tag
+	flags.StringVar(&opts.Tag, "tag", opts.Tag,
+		i18n.T("tag of the releases"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ReleasesCreateCommand
+////////////////////////////////////////////////////////////////////////
+
+// ReleasesCreateCommand implements the "releases create" command which
+// creates a release in each of the projects in a group.
+type ReleasesCreateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ReleasesCreateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ReleasesCreateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] releases create [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create a release for --tag in each of the selected projects.\n")
+	i18n.Fprintf(out, "    If the tag does not exist yet, it is created from --ref.\n")
+	i18n.Fprintf(out, "    Projects that already have a release for the tag are\n")
+	i18n.Fprintf(out, "    skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewReleasesCreateCommand returns a new, initialized
+// ReleasesCreateCommand instance.
+func NewReleasesCreateCommand(
+	name string,
+	opts *ReleasesCreateOptions,
+	session *Session,
+) *ReleasesCreateCommand {
+
+	// Create the new command.
+	cmd := &ReleasesCreateCommand{
+		GitlabCommand: GitlabCommand[ReleasesCreateOptions]{
+			BasicCommand: BasicCommand[ReleasesCreateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// CreateRelease creates the release in the project.  If dryRun is
+// true, this function only prints what it would do without actually
+// doing it.
+func CreateRelease(
+	ctx context.Context,
+	s gitlab_util.ReleaseCreator, /* was *gitlab.ReleasesService */
+	p *gitlab.Project,
+	opts *gitlab.CreateReleaseOptions,
+	dryRun bool,
+) (*gitlab.Release, error) {
+	release := &gitlab.Release{TagName: *opts.TagName}
+	logging.Printf("- Creating release %q ... ", releaseName(p, *opts.TagName))
+	if !dryRun {
+		var err error
+		release, _, err = s.CreateRelease(p.ID, opts,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return nil, fmt.Errorf("CreateRelease: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return release, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ReleasesCreateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Tag == "" {
+		return result, i18n.Errorf("%w: tag not set", ErrInvalidOption)
+	}
+	links, err := ParseAssetLinks(cmd.options.AssetLinks)
+	if err != nil {
+		return result, err
+	}
+	opts := &gitlab.CreateReleaseOptions{
+		TagName: gitlab.Ptr(cmd.options.Tag),
+	}
+	if cmd.options.Name != "" {
+		opts.Name = gitlab.Ptr(cmd.options.Name)
+	}
+	if cmd.options.Description != "" {
+		opts.Description = gitlab.Ptr(cmd.options.Description)
+	}
+	if cmd.options.Ref != "" {
+		opts.Ref = gitlab.Ptr(cmd.options.Ref)
+	}
+	if len(links) > 0 {
+		opts.Assets = &gitlab.ReleaseAssetsOptions{Links: links}
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Create the release in each project.  A project in which the
+	// release cannot be created is recorded in the result, and the
+	// remaining projects are still processed.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			name := releaseName(p, cmd.options.Tag)
+			rs, err := gitlab_util.GetAllProjectReleases(ctx, cmd.client.Releases, p.ID)
+			if err != nil {
+				result.Fail(name, p, err)
+				return true, nil
+			}
+			if findRelease(rs, cmd.options.Tag) != nil {
+				logging.Printf("- Release %q already exists.\n", name)
+				return true, nil
+			}
+			hook.OnItemStart(name)
+			release, err := CreateRelease(ctx, cmd.client.Releases, p, opts,
+				cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(name, err)
+				result.Fail(name, p, err)
+				return true, nil
+			}
+			hook.OnItemDone(name)
+			result.Succeed(name, release)
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not create %d release(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,298 @@
+// This file provides the implementation for the "releases delete"
+// command which deletes the release for a tag from the projects in a
+// group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ReleasesDeleteOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ReleasesDeleteOptions are the options needed by this command.
+type ReleasesDeleteOptions struct {
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// Embed the options that control whether the remaining releases
+	// are deleted after a release cannot be deleted.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Tag is the tag of the releases to delete.  Defaults to "".
+	Tag string `xml:"tag"`
+}
+
+// Initialize initializes this ReleasesDeleteOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *ReleasesDeleteOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --tag
+	flags.StringVar(&opts.Tag, "tag", opts.Tag,
+		i18n.T("tag of the releases to delete"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ReleasesDeleteCommand
+////////////////////////////////////////////////////////////////////////
+
+// ReleasesDeleteCommand implements the "releases delete" command which
+// deletes the release for a tag from the projects in a group.
+type ReleasesDeleteCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ReleasesDeleteOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ReleasesDeleteCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] releases delete [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Delete the release for --tag from the selected projects.  The\n")
+	i18n.Fprintf(out, "    tag itself is kept.  Projects without a release for the tag\n")
+	i18n.Fprintf(out, "    are skipped.  The releases are listed and must be confirmed\n")
+	i18n.Fprintf(out, "    unless the global --yes option is passed.  When deleting\n")
+	i18n.Fprintf(out, "    more releases than --confirm-threshold, --i-understand must\n")
+	i18n.Fprintf(out, "    be passed, and the group must be typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Delete Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewReleasesDeleteCommand returns a new, initialized
+// ReleasesDeleteCommand instance.
+func NewReleasesDeleteCommand(
+	name string,
+	opts *ReleasesDeleteOptions,
+	session *Session,
+) *ReleasesDeleteCommand {
+
+	// Create the new command.
+	cmd := &ReleasesDeleteCommand{
+		GitlabCommand: GitlabCommand[ReleasesDeleteOptions]{
+			BasicCommand: BasicCommand[ReleasesDeleteOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectRelease is a release of a project.
+type ProjectRelease struct {
+
+	// Project is the project that has the release.
+	Project *gitlab.Project
+
+	// Release is the release.
+	Release *gitlab.Release
+}
+
+// DeleteRelease deletes the release for the tag from the project.  If
+// dryRun is true, this function only prints what it would do without
+// actually doing it.
+func DeleteRelease(
+	ctx context.Context,
+	s gitlab_util.ReleaseDeleter, /* was *gitlab.ReleasesService */
+	p *gitlab.Project,
+	tag string,
+	dryRun bool,
+) error {
+	logging.Printf("- Deleting release %q ... ", releaseName(p, tag))
+	if !dryRun {
+		_, _, err := s.DeleteRelease(p.ID, tag,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("DeleteRelease: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// DeleteReleases deletes the releases stopping after the current
+// release if interrupted.  If keepGoing is true, a release that cannot
+// be deleted is recorded in the result, and the remaining releases are
+// still deleted.  Otherwise, no release is deleted after the first one
+// that fails.  If dryRun is true, this function only prints what it
+// would do without actually doing it.
+func DeleteReleases(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.ReleaseDeleter, /* was *gitlab.ReleasesService */
+	releases []*ProjectRelease,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, pr := range releases {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("DeleteReleases: %w", err)
+		}
+		name := releaseName(pr.Project, pr.Release.TagName)
+		hook.OnItemStart(name)
+		err := DeleteRelease(ctx, s, pr.Project, pr.Release.TagName, dryRun)
+		if err != nil {
+			hook.OnError(name, err)
+			result.Fail(name, pr.Release, err)
+			if !keepGoing {
+				return fmt.Errorf("DeleteReleases: %w", err)
+			}
+			continue
+		}
+		hook.OnItemDone(name)
+		result.Succeed(name, pr.Release)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *ReleasesDeleteCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Tag == "" {
+		return result, i18n.Errorf("%w: tag not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Collect the projects that have a release for the tag.  If
+	// --keep-going is set, a project whose releases cannot be read is
+	// recorded in the result, and the remaining projects are still
+	// searched.
+	var releases []*ProjectRelease
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			rs, err := gitlab_util.GetAllProjectReleases(ctx, cmd.client.Releases, p.ID)
+			if err != nil {
+				result.Fail(releaseName(p, cmd.options.Tag), p, err)
+				return cmd.options.Continue(err)
+			}
+			if release := findRelease(rs, cmd.options.Tag); release != nil {
+				releases = append(releases,
+					&ProjectRelease{Project: p, Release: release})
+			}
+			return true, nil
+		})
+	if err != nil {
+		err = cmd.options.Finish(os.Stdout, result, err, "could not delete %d release(s)")
+		return result, err
+	}
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, pr := range releases {
+			names = append(names, releaseName(pr.Project, pr.Release.TagName))
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"), names,
+			cmd.options.Group, cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Delete the releases.
+	err = DeleteReleases(ctx, result, cmd.client.Releases, releases,
+		cmd.options.KeepGoing, cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not delete %d release(s)")
+	return result, err
+}
@@ -0,0 +1,190 @@
+// This file provides the implementation for the "releases list"
+// command which lists the releases of the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ReleasesListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// ReleasesListOptions are the options needed by this command.
+type ReleasesListOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Tag is the tag of the releases to list.  Defaults to "" which
+	// matches any tag.
+	Tag string `xml:"tag"`
+}
+
+// Initialize initializes this ReleasesListOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *ReleasesListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --tag
+	flags.StringVar(&opts.Tag, "tag", opts.Tag,
+		i18n.T("tag of the releases to list"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// ReleasesListCommand
+////////////////////////////////////////////////////////////////////////
+
+// ReleasesListCommand implements the "releases list" command which
+// lists the releases of the projects in a group.
+type ReleasesListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[ReleasesListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *ReleasesListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] releases list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the releases of the selected projects.  Each release is\n")
+	i18n.Fprintf(out, "    printed as its project, tag, and name.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewReleasesListCommand returns a new, initialized
+// ReleasesListCommand instance.
+func NewReleasesListCommand(
+	name string,
+	opts *ReleasesListOptions,
+	session *Session,
+) *ReleasesListCommand {
+
+	// Create the new command.
+	cmd := &ReleasesListCommand{
+		GitlabCommand: GitlabCommand[ReleasesListOptions]{
+			BasicCommand: BasicCommand[ReleasesListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *ReleasesListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print the releases of each project.  For --output json, the
+	// releases are collected and printed together at the end.  A
+	// project whose releases cannot be listed is recorded as failed,
+	// and the remaining projects are still listed.
+	releases := []*gitlab.Release{}
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			rs, err := gitlab_util.GetAllProjectReleases(ctx, cmd.client.Releases, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, r := range rs {
+				if cmd.options.Tag != "" && r.TagName != cmd.options.Tag {
+					continue
+				}
+				if cmd.session.OutputJSON() {
+					releases = append(releases, r)
+				} else {
+					fmt.Printf("%-40s  %-20s  %s\n", p.PathWithNamespace,
+						r.TagName, r.Name)
+				}
+				result.Succeed(releaseName(p, r.TagName), r)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the releases as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, releases)
+		if err != nil {
+			return result, err
+		}
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not list the releases of %d project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,208 @@
+// This file provides the functions shared by the commands that keep
+// description templates (e.g., merge request and issue templates) in
+// the repositories of projects in sync with a local directory.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+// MergeRequestTemplatesDir is the directory in a repository where
+// Gitlab looks for merge request description templates.
+const MergeRequestTemplatesDir = ".gitlab/merge_request_templates"
+
+// IssueTemplatesDir is the directory in a repository where Gitlab
+// looks for issue description templates.
+const IssueTemplatesDir = ".gitlab/issue_templates"
+
+// RepositoryTemplate is a description template.
+type RepositoryTemplate struct {
+
+	// Name is the file name of the template (e.g., "Default.md").
+	Name string
+
+	// Content is the content of the template.
+	Content string
+}
+
+// RepositoryTemplateChange is a template that is missing or out of
+// date in a project.
+type RepositoryTemplateChange struct {
+
+	// Template is the template from the local directory.
+	Template *RepositoryTemplate
+
+	// Path is the path of the template in the repository.
+	Path string
+
+	// Exists is true if the template exists in the repository but is
+	// out of date and false if it is missing.
+	Exists bool
+}
+
+// ReadRepositoryTemplates returns the *.md templates in the directory
+// sorted by name.
+func ReadRepositoryTemplates(dir string) ([]*RepositoryTemplate, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return nil, fmt.Errorf("ReadRepositoryTemplates: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, i18n.Errorf(
+			"%w: no *.md templates found in %q", ErrInvalidOption, dir)
+	}
+	slices.Sort(paths)
+	var result []*RepositoryTemplate
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ReadRepositoryTemplates: %w", err)
+		}
+		result = append(result, &RepositoryTemplate{
+			Name:    filepath.Base(path),
+			Content: string(content),
+		})
+	}
+	return result, nil
+}
+
+// DiffRepositoryTemplates returns the templates that are missing or
+// out of date in repoDir (e.g., IssueTemplatesDir) of the repository
+// of the project on the branch.
+func DiffRepositoryTemplates(
+	ctx context.Context,
+	files gitlab_util.RepositoryFileGetter, /* was *gitlab.RepositoryFilesService */
+	p *gitlab.Project,
+	repoDir string,
+	templates []*RepositoryTemplate,
+	branch string,
+) ([]*RepositoryTemplateChange, error) {
+	var result []*RepositoryTemplateChange
+	for _, t := range templates {
+		path := repoDir + "/" + t.Name
+		content, found, err := gitlab_util.GetFileContent(
+			ctx, files, p.ID, path, branch)
+		if err != nil {
+			return nil, fmt.Errorf("DiffRepositoryTemplates: %w", err)
+		}
+		if found && content == t.Content {
+			continue
+		}
+		result = append(result, &RepositoryTemplateChange{
+			Template: t,
+			Path:     path,
+			Exists:   found,
+		})
+	}
+	return result, nil
+}
+
+// SyncRepositoryTemplates commits the templates that are missing or
+// out of date in repoDir (e.g., IssueTemplatesDir) to the project in a
+// single commit on the branch.  If branch is empty, the default branch
+// of the project is used.  If diffOnly is true, this function only
+// prints which templates are missing or out of date.  If dryRun is
+// true, this function only prints what it would without actually
+// doing it.
+func SyncRepositoryTemplates(
+	ctx context.Context,
+	result *Result,
+	files gitlab_util.RepositoryFileGetter, /* was *gitlab.RepositoryFilesService */
+	commits gitlab_util.CommitCreator, /* was *gitlab.CommitsService */
+	p *gitlab.Project,
+	repoDir string,
+	templates []*RepositoryTemplate,
+	branch string,
+	commitMessage string,
+	diffOnly bool,
+	dryRun bool,
+) error {
+
+	// Skip projects without a repository.
+	if branch == "" {
+		branch = p.DefaultBranch
+	}
+	if branch == "" {
+		logging.Printf("- Skipping project %q (empty repository).\n",
+			p.PathWithNamespace)
+		return nil
+	}
+
+	// Determine which templates need to be created or updated.
+	changes, err := DiffRepositoryTemplates(
+		ctx, files, p, repoDir, templates, branch)
+	if err != nil {
+		result.Fail(p.PathWithNamespace, p, err)
+		return fmt.Errorf("SyncRepositoryTemplates: %w", err)
+	}
+	if len(changes) == 0 {
+		logging.Printf("- Templates in %q of project %q are up to date.\n",
+			repoDir, p.PathWithNamespace)
+		return nil
+	}
+
+	// Print the differences if requested.
+	if diffOnly {
+		logging.Printf("- Templates in %q of project %q differ:\n",
+			repoDir, p.PathWithNamespace)
+		for _, c := range changes {
+			if c.Exists {
+				logging.Printf("    outdated: %s\n", c.Template.Name)
+			} else {
+				logging.Printf("    missing:  %s\n", c.Template.Name)
+			}
+		}
+		return nil
+	}
+
+	// Build the commit actions.
+	var actions []*gitlab.CommitActionOptions
+	for _, c := range changes {
+		action := gitlab.FileCreate
+		if c.Exists {
+			action = gitlab.FileUpdate
+		}
+		actions = append(actions, &gitlab.CommitActionOptions{
+			Action:   gitlab.Ptr(action),
+			FilePath: gitlab.Ptr(c.Path),
+			Content:  gitlab.Ptr(c.Template.Content),
+		})
+	}
+
+	// Commit the templates.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(p.PathWithNamespace)
+	if !dryRun {
+		_, _, err := commits.CreateCommit(p.ID,
+			&gitlab.CreateCommitOptions{
+				Branch:        &branch,
+				CommitMessage: &commitMessage,
+				Actions:       actions,
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			logging.Printf("- Updating %d template(s) in %q of project %q ... Failed.\n",
+				len(actions), repoDir, p.PathWithNamespace)
+			err = fmt.Errorf(
+				"SyncRepositoryTemplates: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(p.PathWithNamespace, err)
+			result.Fail(p.PathWithNamespace, p, err)
+			return err
+		}
+	}
+	logging.Printf("- Updating %d template(s) in %q of project %q ... Done.\n",
+		len(actions), repoDir, p.PathWithNamespace)
+	hook.OnItemDone(p.PathWithNamespace)
+	result.Succeed(p.PathWithNamespace, p)
+	return nil
+}
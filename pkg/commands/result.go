@@ -0,0 +1,115 @@
+// This file provides the implementation for the structured result
+// returned by commands.
+
+package commands
+
+import (
+	"sync"
+)
+
+////////////////////////////////////////////////////////////////////////
+// ItemResult
+////////////////////////////////////////////////////////////////////////
+
+// ItemResult is the result of processing a single item (e.g., a
+// project, user, or approval rule) by a command.
+type ItemResult struct {
+
+	// Name identifies the item (e.g., the full path of a project).
+	Name string
+
+	// Value is the Gitlab object for the item (e.g., *gitlab.Project)
+	// if available.  It can be nil.
+	Value any
+
+	// Err is the reason processing the item failed or nil if
+	// processing the item succeeded.
+	Err error
+}
+
+////////////////////////////////////////////////////////////////////////
+// Result
+////////////////////////////////////////////////////////////////////////
+
+// Result is the structured result returned by Runner.Run() so that
+// programs embedding this package get real data instead of having to
+// scrape stdout.  All methods are safe to call on a nil *Result which
+// lets helper functions record items without requiring the caller to
+// collect them.  All methods are also safe for concurrent use so bulk
+// operations can record items from multiple goroutines.
+type Result struct {
+
+	// Items holds the result for each item processed in the order
+	// in which it was processed.
+	Items []ItemResult
+
+	// mutex protects Items while the command is running.
+	mutex sync.Mutex
+}
+
+// NewResult returns a new, empty Result.
+func NewResult() *Result {
+	return &Result{}
+}
+
+// Succeed records that processing the item succeeded.
+func (r *Result) Succeed(name string, value any) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Items = append(r.Items, ItemResult{Name: name, Value: value})
+}
+
+// Fail records that processing the item failed because of err.
+func (r *Result) Fail(name string, value any, err error) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Items = append(r.Items, ItemResult{Name: name, Value: value, Err: err})
+}
+
+// Processed returns the number of items processed.
+func (r *Result) Processed() int {
+	if r == nil {
+		return 0
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.Items)
+}
+
+// Succeeded returns the items that were processed successfully.
+func (r *Result) Succeeded() []ItemResult {
+	var result []ItemResult
+	if r == nil {
+		return result
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, item := range r.Items {
+		if item.Err == nil {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// Failed returns the items that failed to be processed.
+func (r *Result) Failed() []ItemResult {
+	var result []ItemResult
+	if r == nil {
+		return result
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, item := range r.Items {
+		if item.Err != nil {
+			result = append(result, item)
+		}
+	}
+	return result
+}
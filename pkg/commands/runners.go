@@ -0,0 +1,219 @@
+// This file provides the options and functions shared by the
+// "runners" subcommands which operate on the CI/CD runners of the
+// instance, a group, or a project.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+// RunnerTypes are the valid values for --type.
+var RunnerTypes = []string{"instance_type", "group_type", "project_type"}
+
+// RunnerSelectorOptions select runners by their scope, type, status,
+// description, and ID.  They are embedded in the options of the
+// "runners" subcommands so the options have the same names and meaning
+// everywhere.  Because the struct is embedded, its XML elements appear
+// directly in the options of the embedding command in the options.xml
+// file.
+type RunnerSelectorOptions struct {
+
+	// DescriptionExpr is the regular expression that filters the
+	// runners by their description.  Defaults to "" which matches any
+	// description.
+	DescriptionExpr string `xml:"description-expr"`
+
+	// Group is the full path of the group whose runners are selected
+	// instead of all runners of the instance.  Defaults to "".
+	Group string `xml:"group"`
+
+	// IDs are the IDs of the runners to select.  Defaults to no IDs
+	// which matches any runner.
+	IDs string_slice.StringSlice `xml:"ids>id"`
+
+	// Project is the full path of the project whose runners are
+	// selected instead of all runners of the instance.  Defaults to
+	// "".
+	Project string `xml:"project"`
+
+	// Status is the status (e.g., "online" or "offline") of the
+	// runners to select.  Defaults to "" which matches any status.
+	Status string `xml:"status"`
+
+	// Type is the type of the runners to select which is
+	// "instance_type", "group_type", or "project_type".  Defaults to
+	// "" which matches any type.
+	Type string `xml:"type"`
+}
+
+// Initialize initializes this RunnerSelectorOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *RunnerSelectorOptions) Initialize(flags *flag.FlagSet) {
+
+	// --description-expr
+	flags.StringVar(&opts.DescriptionExpr, "description-expr", opts.DescriptionExpr,
+		i18n.T("regular expression that selects runners by description"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the group whose runners are selected "+
+			"instead of all runners of the instance"))
+
+	// --ids
+	flags.Var(&opts.IDs, "ids",
+		i18n.T("comma-separated list of the IDs of the runners to select"))
+
+	// --project
+	flags.StringVar(&opts.Project, "project", opts.Project,
+		i18n.T("full path of the project whose runners are selected "+
+			"instead of all runners of the instance"))
+
+	// --status
+	flags.StringVar(&opts.Status, "status", opts.Status,
+		i18n.T("status (e.g., \"online\" or \"offline\") of the runners to select"))
+
+	// --type
+	flags.StringVar(&opts.Type, "type", opts.Type,
+		i18n.T("type of the runners to select which is \"instance_type\", "+
+			"\"group_type\", or \"project_type\""))
+}
+
+// Validate returns an error if the options cannot select any runners.
+func (opts *RunnerSelectorOptions) Validate() error {
+	if opts.Group != "" && opts.Project != "" {
+		return i18n.Errorf("%w: both group and project set", ErrInvalidOption)
+	}
+	if opts.Type != "" && !slices.Contains(RunnerTypes, opts.Type) {
+		return i18n.Errorf("%w: invalid runner type: %q",
+			ErrInvalidOption, opts.Type)
+	}
+	for _, id := range opts.IDs {
+		_, err := strconv.Atoi(id)
+		if err != nil {
+			return i18n.Errorf("%w: invalid runner ID: %q", ErrInvalidOption, id)
+		}
+	}
+	_, err := regexp.Compile(opts.DescriptionExpr)
+	if err != nil {
+		return i18n.Errorf("%w: invalid description-expr: %q: %v",
+			ErrInvalidOption, opts.DescriptionExpr, err)
+	}
+	return nil
+}
+
+// RunnersLister is an abstraction of gitlab.RunnersService which lists
+// the runners of the instance, a group, or a project.
+type RunnersLister interface {
+	gitlab_util.AllRunnersLister
+	gitlab_util.GroupRunnersLister
+	gitlab_util.ProjectRunnersLister
+}
+
+// RunnerAssigner is an abstraction of gitlab.RunnersService which
+// lists the runners of a project and assigns runners to projects.
+type RunnerAssigner interface {
+	gitlab_util.ProjectRunnersLister
+	gitlab_util.ProjectRunnerEnabler
+}
+
+// GetSelectedRunners returns the runners selected by the options.
+// The runners of --group or --project are listed if either is set.
+// Otherwise, all runners of the instance are listed which requires
+// administrator access.
+func (opts *RunnerSelectorOptions) GetSelectedRunners(
+	ctx context.Context,
+	s RunnersLister, /* was *gitlab.RunnersService */
+) ([]*gitlab.Runner, error) {
+	var err error
+	var runners []*gitlab.Runner
+
+	// Let Gitlab filter by type and status.
+	var runnerType, status *string
+	if opts.Type != "" {
+		runnerType = gitlab.Ptr(opts.Type)
+	}
+	if opts.Status != "" {
+		status = gitlab.Ptr(opts.Status)
+	}
+	switch {
+	case opts.Group != "":
+		runners, err = gitlab_util.GetAllGroupRunners(ctx, s, opts.Group,
+			&gitlab.ListGroupsRunnersOptions{Type: runnerType, Status: status})
+	case opts.Project != "":
+		runners, err = gitlab_util.GetAllProjectRunners(ctx, s, opts.Project,
+			&gitlab.ListProjectRunnersOptions{Type: runnerType, Status: status})
+	default:
+		runners, err = gitlab_util.GetAllRunners(ctx, s,
+			&gitlab.ListRunnersOptions{Type: runnerType, Status: status})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("GetSelectedRunners: %w", err)
+	}
+
+	// Filter by description and ID.
+	descriptionExpr, err := regexp.Compile(opts.DescriptionExpr)
+	if err != nil {
+		return nil, fmt.Errorf("GetSelectedRunners: %w", err)
+	}
+	result := []*gitlab.Runner{}
+	for _, runner := range runners {
+		if !descriptionExpr.MatchString(runner.Description) {
+			continue
+		}
+		if len(opts.IDs) > 0 && !slices.Contains(opts.IDs, strconv.Itoa(runner.ID)) {
+			continue
+		}
+		result = append(result, runner)
+	}
+	return result, nil
+}
+
+// runnerName returns the name used to identify the runner in a Result
+// and in messages.
+func runnerName(runner *gitlab.Runner) string {
+	return fmt.Sprintf("#%d", runner.ID)
+}
+
+// UpdateRunner calls update for the runner.  The verb (e.g., "Pausing")
+// is used in the progress message.  If dryRun is true, update is not
+// called, and this function only prints what it would do.  The outcome
+// is recorded in the result, and the error of a failed update is also
+// returned.
+func UpdateRunner(
+	ctx context.Context,
+	result *Result,
+	runner *gitlab.Runner,
+	verb string,
+	update func() error,
+	dryRun bool,
+) error {
+	name := runnerName(runner)
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- %s runner %s (%q) ... ", verb, name, runner.Description)
+	if !dryRun {
+		err := update()
+		if err != nil {
+			logging.Printf("Failed.\n")
+			hook.OnError(name, err)
+			result.Fail(name, runner, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	result.Succeed(name, runner)
+	return nil
+}
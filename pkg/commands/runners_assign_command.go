@@ -0,0 +1,232 @@
+// This file provides the implementation for the "runners assign"
+// command which assigns a CI/CD runner to the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// RunnersAssignOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// RunnersAssignOptions are the options needed by this command.
+type RunnersAssignOptions struct {
+
+	// Embed the options that control whether the runner is assigned
+	// to the remaining projects after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Runner is the ID of the runner to assign.  Defaults to 0.
+	Runner int `xml:"runner"`
+}
+
+// Initialize initializes this RunnersAssignOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *RunnersAssignOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --runner
+	flags.IntVar(&opts.Runner, "runner", opts.Runner,
+		i18n.T("ID of the runner to assign"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// RunnersAssignCommand
+////////////////////////////////////////////////////////////////////////
+
+// RunnersAssignCommand implements the "runners assign" command which
+// assigns a CI/CD runner to the projects in a group.
+type RunnersAssignCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[RunnersAssignOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *RunnersAssignCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] runners assign [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Assign --runner to the selected projects so their jobs can\n")
+	i18n.Fprintf(out, "    run on it.  Projects the runner is already assigned to are\n")
+	i18n.Fprintf(out, "    skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Assign Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewRunnersAssignCommand returns a new, initialized
+// RunnersAssignCommand instance.
+func NewRunnersAssignCommand(
+	name string,
+	opts *RunnersAssignOptions,
+	session *Session,
+) *RunnersAssignCommand {
+
+	// Create the new command.
+	cmd := &RunnersAssignCommand{
+		GitlabCommand: GitlabCommand[RunnersAssignOptions]{
+			BasicCommand: BasicCommand[RunnersAssignOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// AssignRunner assigns the runner having the ID to the project unless
+// it is already assigned.  If dryRun is true, this function only
+// prints what it would do without actually doing it.
+func AssignRunner(
+	ctx context.Context,
+	s RunnerAssigner, /* was *gitlab.RunnersService */
+	p *gitlab.Project,
+	runnerID int,
+	dryRun bool,
+) error {
+
+	// Skip the project if the runner is already assigned.
+	runners, err := gitlab_util.GetAllProjectRunners(
+		ctx, s, p.ID, &gitlab.ListProjectRunnersOptions{})
+	if err != nil {
+		return fmt.Errorf("AssignRunner: %w", err)
+	}
+	for _, runner := range runners {
+		if runner.ID == runnerID {
+			logging.Printf("- Runner #%d already assigned to %q.\n",
+				runnerID, p.PathWithNamespace)
+			return nil
+		}
+	}
+
+	// Assign the runner.
+	logging.Printf("- Assigning runner #%d to %q ... ", runnerID, p.PathWithNamespace)
+	if !dryRun {
+		_, _, err = s.EnableProjectRunner(p.ID,
+			&gitlab.EnableProjectRunnerOptions{RunnerID: runnerID},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("AssignRunner: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *RunnersAssignCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Runner <= 0 {
+		return result, i18n.Errorf("%w: runner not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Assign the runner to each project.  A project that fails is
+	// recorded in the result.  If --keep-going is set, the remaining
+	// projects are still processed.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			err := AssignRunner(ctx, cmd.client.Runners,
+				p, cmd.options.Runner, cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return cmd.options.Continue(err)
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			result.Succeed(p.PathWithNamespace, p)
+			return true, nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err, "could not assign the runner to %d project(s)")
+	return result, err
+}
@@ -0,0 +1,164 @@
+// This file provides the implementation for the "runners" command
+// which provides CI/CD runner related subcommands.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      RunnersCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// RunnersOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// RunnersOptions are the options needed by this command.
+type RunnersOptions struct {
+
+	// Options for the "runners assign" command.
+	RunnersAssignOpts RunnersAssignOptions `xml:"assign-options"`
+
+	// Options for the "runners list" command.
+	RunnersListOpts RunnersListOptions `xml:"list-options"`
+
+	// Options for the "runners pause" command.
+	RunnersPauseOpts RunnersPauseOptions `xml:"pause-options"`
+
+	// Options for the "runners remove" command.
+	RunnersRemoveOpts RunnersRemoveOptions `xml:"remove-options"`
+
+	// Options for the "runners resume" command.
+	RunnersResumeOpts RunnersResumeOptions `xml:"resume-options"`
+}
+
+// Initialize initializes this RunnersOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *RunnersOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// RunnersCommand
+////////////////////////////////////////////////////////////////////////
+
+// RunnersCommand provides subcommands for administering the CI/CD
+// runners of a Gitlab instance.
+type RunnersCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[RunnersOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *RunnersCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] runners [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for administering the CI/CD runners of a Gitlab\n")
+	i18n.Fprintf(out, "    instance.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *RunnersCommand) addSubcmds(session *Session) {
+	cmd.subcmds["assign"] = NewRunnersAssignCommand(
+		"assign", &cmd.options.RunnersAssignOpts, session)
+	cmd.subcmds["list"] = NewRunnersListCommand(
+		"list", &cmd.options.RunnersListOpts, session)
+	cmd.subcmds["pause"] = NewRunnersPauseCommand(
+		"pause", &cmd.options.RunnersPauseOpts, session)
+	cmd.subcmds["remove"] = NewRunnersRemoveCommand(
+		"remove", &cmd.options.RunnersRemoveOpts, session)
+	cmd.subcmds["resume"] = NewRunnersResumeCommand(
+		"resume", &cmd.options.RunnersResumeOpts, session)
+}
+
+// NewRunnersCommand returns a new, initialized RunnersCommand
+// instance having the specified name.
+func NewRunnersCommand(
+	name string,
+	opts *RunnersOptions,
+	session *Session,
+) *RunnersCommand {
+
+	// Create the new command.
+	cmd := &RunnersCommand{
+		ParentCommand: ParentCommand[RunnersOptions]{
+			BasicCommand: BasicCommand[RunnersOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *RunnersCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
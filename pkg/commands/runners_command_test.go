@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestRunnersIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	shared := server.AddRunner("shared-linux", "instance_type", "")
+	group := server.AddRunner("foo-docker", "group_type", "foo")
+	builder := server.AddRunner("alpha-builder", "project_type", "foo/alpha")
+	old := server.AddRunner("old-builder", "project_type", "foo/alpha")
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Yes = true
+	name := func(r *gitlab.Runner) string { return fmt.Sprintf("#%d", r.ID) }
+
+	// run runs the "runners" subcommand and returns the names of the
+	// runners or projects that succeeded.
+	run := func(args ...string) []string {
+		cmd := NewRunnersCommand("runners", &RunnersOptions{}, session)
+		_, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("runners %v: unexpected error: %v", args, err)
+		}
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	// Verify the commands.
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"list"},
+			[]string{name(shared), name(group), name(builder), name(old)}},
+		{[]string{"list", "--group", "foo"},
+			[]string{name(group)}},
+		{[]string{"list", "--project", "foo/alpha", "--description-expr", "^alpha"},
+			[]string{name(builder)}},
+		{[]string{"list", "--type", "project_type"},
+			[]string{name(builder), name(old)}},
+		{[]string{"pause", "--description-expr", "builder"},
+			[]string{name(builder), name(old)}},
+		{[]string{"pause", "--ids", fmt.Sprint(builder.ID)},
+			nil},
+		{[]string{"resume", "--ids", fmt.Sprint(builder.ID)},
+			[]string{name(builder)}},
+		{[]string{"remove", "--ids", fmt.Sprint(old.ID), "--dry-run"},
+			[]string{name(old)}},
+		{[]string{"remove", "--ids", fmt.Sprint(old.ID)},
+			[]string{name(old)}},
+		{[]string{"assign", "--group", "foo", "--expr", "alpha|beta",
+			"--runner", fmt.Sprint(builder.ID)},
+			[]string{"foo/alpha", "foo/beta"}},
+	}
+	for _, d := range data {
+		actual := run(d.args...)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("runners %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+	}
+
+	// Verify the runners.
+	if r := server.Runner(builder.ID); r == nil || r.Paused {
+		t.Errorf("runners resume: expected running runner, actual=%v", r)
+	}
+	if r := server.Runner(old.ID); r != nil {
+		t.Errorf("runners remove: expected=%v  actual=%v", nil, r)
+	}
+	expected := []string{"foo/alpha", "foo/beta"}
+	if actual := server.RunnerProjects(builder.ID); !slices.Equal(actual, expected) {
+		t.Errorf("runners assign: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Verify removing more runners than the threshold must be
+	// confirmed without the global --yes.
+	session.globalOpts.Yes = false
+	cmd := NewRunnersCommand("runners", &RunnersOptions{}, session)
+	args := []string{"remove", "--confirm-threshold", "1"}
+	_, err := cmd.Run(context.Background(), args)
+	if !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("runners %v: expected=%v  actual=%v", args, ErrNotConfirmed, err)
+	}
+	if r := server.Runner(shared.ID); r == nil {
+		t.Errorf("runners %v: runner removed without confirmation", args)
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"list", "--group", "foo", "--project", "foo/alpha"},
+		{"list", "--type", "bogus"},
+		{"pause", "--ids", "bogus"},
+		{"assign", "--group", "foo"},
+	} {
+		cmd := NewRunnersCommand("runners", &RunnersOptions{}, session)
+		_, err := cmd.Run(context.Background(), args)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("runners %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// This file provides the implementation for the "runners list"
+// command which lists the CI/CD runners of the instance, a group, or a
+// project.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// RunnersListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// RunnersListOptions are the options needed by this command.
+type RunnersListOptions struct {
+
+	// Embed the options that select the runners.
+	RunnerSelectorOptions
+}
+
+// Initialize initializes this RunnersListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *RunnersListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --description-expr, --group, --ids, --project, --status, --type
+	opts.RunnerSelectorOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// RunnersListCommand
+////////////////////////////////////////////////////////////////////////
+
+// RunnersListCommand implements the "runners list" command which lists
+// the CI/CD runners of the instance, a group, or a project.
+type RunnersListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[RunnersListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *RunnersListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] runners list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the selected runners of the instance, --group, or\n")
+	i18n.Fprintf(out, "    --project.  Each runner is printed as its ID, type, status,\n")
+	i18n.Fprintf(out, "    whether it is paused, and its description.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewRunnersListCommand returns a new, initialized
+// RunnersListCommand instance.
+func NewRunnersListCommand(
+	name string,
+	opts *RunnersListOptions,
+	session *Session,
+) *RunnersListCommand {
+
+	// Create the new command.
+	cmd := &RunnersListCommand{
+		GitlabCommand: GitlabCommand[RunnersListOptions]{
+			BasicCommand: BasicCommand[RunnersListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *RunnersListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Get the runners.
+	runners, err := cmd.options.GetSelectedRunners(ctx, cmd.client.Runners)
+	if err != nil {
+		return result, err
+	}
+
+	// Print the runners.  The token is never printed.
+	for _, runner := range runners {
+		runner.Token = ""
+		if !cmd.session.OutputJSON() {
+			paused := ""
+			if runner.Paused {
+				paused = i18n.T("paused")
+			}
+			fmt.Printf("%-8s  %-13s  %-15s  %-6s  %s\n", runnerName(runner),
+				runner.RunnerType, runner.Status, paused, runner.Description)
+		}
+		result.Succeed(runnerName(runner), runner)
+	}
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, runners)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,210 @@
+// This file provides the implementation for the "runners pause"
+// command which pauses CI/CD runners so they stop picking up new jobs.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// RunnersPauseOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// RunnersPauseOptions are the options needed by this command.
+type RunnersPauseOptions struct {
+
+	// Embed the options that control whether the remaining runners
+	// are paused after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the runners.
+	RunnerSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this RunnersPauseOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *RunnersPauseOptions) Initialize(flags *flag.FlagSet) {
+
+	// --description-expr, --group, --ids, --project, --status, --type
+	opts.RunnerSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// RunnersPauseCommand
+////////////////////////////////////////////////////////////////////////
+
+// RunnersPauseCommand implements the "runners pause" command which
+// pauses CI/CD runners so they stop picking up new jobs.
+type RunnersPauseCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[RunnersPauseOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *RunnersPauseCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] runners pause [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Pause the selected runners so they stop picking up new jobs.\n")
+	i18n.Fprintf(out, "    Runners that are already paused are skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Pause Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewRunnersPauseCommand returns a new, initialized
+// RunnersPauseCommand instance.
+func NewRunnersPauseCommand(
+	name string,
+	opts *RunnersPauseOptions,
+	session *Session,
+) *RunnersPauseCommand {
+
+	// Create the new command.
+	cmd := &RunnersPauseCommand{
+		GitlabCommand: GitlabCommand[RunnersPauseOptions]{
+			BasicCommand: BasicCommand[RunnersPauseOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// PauseRunners pauses or, if paused is false, resumes the runners.
+// Runners that are already in the requested state are skipped, and no
+// runner is changed after the current one if interrupted.  If keepGoing
+// is true, a runner that cannot be changed is recorded in the result,
+// and the remaining runners are still changed.  Otherwise, no runner is
+// changed after the first one that fails.  If dryRun is true, this
+// function only prints what it would do without actually doing it.
+func PauseRunners(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.RunnerUpdater, /* was *gitlab.RunnersService */
+	runners []*gitlab.Runner,
+	paused bool,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	verb, state := i18n.T("Pausing"), i18n.T("paused")
+	if !paused {
+		verb, state = i18n.T("Resuming"), i18n.T("running")
+	}
+	for _, runner := range runners {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("PauseRunners: %w", err)
+		}
+		if runner.Paused == paused {
+			logging.Printf("- Runner %s (%q) already %s.\n",
+				runnerName(runner), runner.Description, state)
+			continue
+		}
+		err := UpdateRunner(ctx, result, runner, verb,
+			func() error {
+				_, _, err := s.UpdateRunnerDetails(runner.ID,
+					&gitlab.UpdateRunnerDetailsOptions{Paused: gitlab.Ptr(paused)},
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+				if err != nil {
+					return fmt.Errorf("PauseRunners: %w", gitlab_util.ClassifyError(err))
+				}
+				return nil
+			},
+			dryRun)
+		if err != nil && !keepGoing {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *RunnersPauseCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Pause the runners.
+	runners, err := cmd.options.GetSelectedRunners(ctx, cmd.client.Runners)
+	if err != nil {
+		return result, err
+	}
+	err = PauseRunners(ctx, result, cmd.client.Runners, runners, true,
+		cmd.options.KeepGoing, cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not pause %d runner(s)")
+	return result, err
+}
@@ -0,0 +1,227 @@
+// This file provides the implementation for the "runners remove"
+// command which removes CI/CD runners.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// RunnersRemoveOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// RunnersRemoveOptions are the options needed by this command.
+type RunnersRemoveOptions struct {
+
+	// Embed the options that select the runners.
+	RunnerSelectorOptions
+
+	// Embed the options that control whether the remaining runners
+	// are removed after one fails.
+	KeepGoingOptions
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this RunnersRemoveOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *RunnersRemoveOptions) Initialize(flags *flag.FlagSet) {
+
+	// --description-expr, --group, --ids, --project, --status, --type
+	opts.RunnerSelectorOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// RunnersRemoveCommand
+////////////////////////////////////////////////////////////////////////
+
+// RunnersRemoveCommand implements the "runners remove" command which
+// removes CI/CD runners.
+type RunnersRemoveCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[RunnersRemoveOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *RunnersRemoveCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] runners remove [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Remove the selected runners.  The runners are listed and\n")
+	i18n.Fprintf(out, "    must be confirmed unless the global --yes option is passed.\n")
+	i18n.Fprintf(out, "    Removing more runners than --confirm-threshold must also be\n")
+	i18n.Fprintf(out, "    confirmed with --i-understand and by typing a generated\n")
+	i18n.Fprintf(out, "    token.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Remove Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewRunnersRemoveCommand returns a new, initialized
+// RunnersRemoveCommand instance.
+func NewRunnersRemoveCommand(
+	name string,
+	opts *RunnersRemoveOptions,
+	session *Session,
+) *RunnersRemoveCommand {
+
+	// Create the new command.
+	cmd := &RunnersRemoveCommand{
+		GitlabCommand: GitlabCommand[RunnersRemoveOptions]{
+			BasicCommand: BasicCommand[RunnersRemoveOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// RemoveRunners removes the runners stopping after the current runner
+// if interrupted.  If keepGoing is true, a runner that cannot be
+// removed is recorded in the result, and the remaining runners are
+// still removed.  Otherwise, no runner is removed after the first one
+// that fails.  If dryRun is true, this function only prints what it
+// would do without actually doing it.
+func RemoveRunners(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.RunnerRemover, /* was *gitlab.RunnersService */
+	runners []*gitlab.Runner,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	for _, runner := range runners {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("RemoveRunners: %w", err)
+		}
+		err := UpdateRunner(ctx, result, runner, i18n.T("Removing"),
+			func() error {
+				_, err := s.RemoveRunner(runner.ID,
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+				if err != nil {
+					return fmt.Errorf("RemoveRunners: %w", gitlab_util.ClassifyError(err))
+				}
+				return nil
+			},
+			dryRun)
+		if err != nil && !keepGoing {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *RunnersRemoveCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Get the runners.
+	runners, err := cmd.options.GetSelectedRunners(ctx, cmd.client.Runners)
+	if err != nil {
+		return result, err
+	}
+
+	// Ask for confirmation.  Because runners have no common name the
+	// user could type, a generated token must be typed instead.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, runner := range runners {
+			names = append(names, fmt.Sprintf("%s (%q)", runnerName(runner), runner.Description))
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("remove"), names, "",
+			cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Remove the runners.
+	err = RemoveRunners(ctx, result, cmd.client.Runners, runners,
+		cmd.options.KeepGoing, cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not remove %d runner(s)")
+	return result, err
+}
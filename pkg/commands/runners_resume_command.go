@@ -0,0 +1,161 @@
+// This file provides the implementation for the "runners resume"
+// command which resumes paused CI/CD runners so they pick up new jobs
+// again.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// RunnersResumeOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// RunnersResumeOptions are the options needed by this command.
+type RunnersResumeOptions struct {
+
+	// Embed the options that control whether the remaining runners
+	// are resumed after one fails.
+	KeepGoingOptions
+
+	// Embed the options that select the runners.
+	RunnerSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+}
+
+// Initialize initializes this RunnersResumeOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *RunnersResumeOptions) Initialize(flags *flag.FlagSet) {
+
+	// --description-expr, --group, --ids, --project, --status, --type
+	opts.RunnerSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// RunnersResumeCommand
+////////////////////////////////////////////////////////////////////////
+
+// RunnersResumeCommand implements the "runners resume" command which
+// resumes paused CI/CD runners so they pick up new jobs again.
+type RunnersResumeCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[RunnersResumeOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *RunnersResumeCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] runners resume [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Resume the selected paused runners so they pick up new jobs\n")
+	i18n.Fprintf(out, "    again.  Runners that are already running are skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Resume Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewRunnersResumeCommand returns a new, initialized
+// RunnersResumeCommand instance.
+func NewRunnersResumeCommand(
+	name string,
+	opts *RunnersResumeOptions,
+	session *Session,
+) *RunnersResumeCommand {
+
+	// Create the new command.
+	cmd := &RunnersResumeCommand{
+		GitlabCommand: GitlabCommand[RunnersResumeOptions]{
+			BasicCommand: BasicCommand[RunnersResumeOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *RunnersResumeCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Resume the runners.
+	runners, err := cmd.options.GetSelectedRunners(ctx, cmd.client.Runners)
+	if err != nil {
+		return result, err
+	}
+	err = PauseRunners(ctx, result, cmd.client.Runners, runners, false,
+		cmd.options.KeepGoing, cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not resume %d runner(s)")
+	return result, err
+}
@@ -0,0 +1,519 @@
+// This file provides the implementation for the "search" command which
+// searches the whole instance or a group for projects, issues, merge
+// requests, code, or commits using the Gitlab search API.
+
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// SearchOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// SearchOptions are the options needed by this command.
+type SearchOptions struct {
+
+	// Format is the format of the results which is either "table" or
+	// "csv".  Defaults to "table".
+	Format string `xml:"format"`
+
+	// Group is the group to search which can be the full path or the
+	// group ID.  If empty, the whole instance is searched.  Defaults
+	// to "".
+	Group string `xml:"group"`
+
+	// OutputFileName is the name of the file to which the results are
+	// written.  Defaults to "" which means the results are written to
+	// os.Stdout.
+	OutputFileName string `xml:"output-file-name"`
+
+	// Query is the text for which to search.  Defaults to "".
+	Query string `xml:"query"`
+
+	// Scope is what to search which is one of "projects", "issues",
+	// "merge_requests", "blobs" (i.e., code), or "commits".  Defaults
+	// to "projects".
+	Scope string `xml:"scope"`
+}
+
+// Initialize initializes this SearchOptions instance so it can be used
+// with the "flag" package to parse the command-line arguments.
+func (opts *SearchOptions) Initialize(flags *flag.FlagSet) {
+
+	// --format
+	if opts.Format == "" {
+		opts.Format = "table"
+	}
+	flags.StringVar(&opts.Format, "format", opts.Format,
+		i18n.T("format of the results which is either table or csv"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("group to search which can be the full path or the group ID "+
+			"instead of the whole instance"))
+
+	// -o
+	flags.StringVar(&opts.OutputFileName, "o", opts.OutputFileName,
+		i18n.T("file to which the results are written instead of stdout"))
+
+	// --output
+	flags.StringVar(&opts.OutputFileName, "output", opts.OutputFileName,
+		i18n.T("file to which the results are written instead of stdout"))
+
+	// --query
+	flags.StringVar(&opts.Query, "query", opts.Query,
+		i18n.T("text for which to search"))
+
+	// --scope
+	if opts.Scope == "" {
+		opts.Scope = "projects"
+	}
+	flags.StringVar(&opts.Scope, "scope", opts.Scope,
+		i18n.T("what to search which is one of projects, issues, "+
+			"merge_requests, blobs, or commits"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// SearchCommand
+////////////////////////////////////////////////////////////////////////
+
+// SearchCommand implements the "search" command which searches
+// Gitlab.
+type SearchCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[SearchOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *SearchCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] search [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Search the whole instance or the --group for the --query\n")
+	i18n.Fprintf(out, "    in the --scope which is one of projects, issues,\n")
+	i18n.Fprintf(out, "    merge_requests, blobs (i.e., code), or commits.  Searching\n")
+	i18n.Fprintf(out, "    blobs or commits outside of a project requires advanced\n")
+	i18n.Fprintf(out, "    search to be enabled on the server.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Search Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewSearchCommand returns a new, initialized SearchCommand instance.
+func NewSearchCommand(
+	name string,
+	opts *SearchOptions,
+	session *Session,
+) *SearchCommand {
+
+	// Create the new command.
+	cmd := &SearchCommand{
+		GitlabCommand: GitlabCommand[SearchOptions]{
+			BasicCommand: BasicCommand[SearchOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// searchScopes are the valid scopes of the search command.
+var searchScopes = []string{"projects", "issues", "merge_requests", "blobs", "commits"}
+
+// Searcher is an abstraction of the methods of gitlab.SearchService
+// that search the whole instance or a group in the scopes supported
+// by the search command.
+type Searcher interface {
+	Projects(
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Project, *gitlab.Response, error)
+	ProjectsByGroup(
+		gid interface{},
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Project, *gitlab.Response, error)
+	Issues(
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Issue, *gitlab.Response, error)
+	IssuesByGroup(
+		gid interface{},
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Issue, *gitlab.Response, error)
+	MergeRequests(
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.MergeRequest, *gitlab.Response, error)
+	MergeRequestsByGroup(
+		gid interface{},
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.MergeRequest, *gitlab.Response, error)
+	Blobs(
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Blob, *gitlab.Response, error)
+	BlobsByGroup(
+		gid interface{},
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Blob, *gitlab.Response, error)
+	Commits(
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Commit, *gitlab.Response, error)
+	CommitsByGroup(
+		gid interface{},
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Commit, *gitlab.Response, error)
+}
+
+// SearchHit is a single result of a search.
+type SearchHit struct {
+
+	// Project is the full path of the project in which the hit was
+	// found.
+	Project string
+
+	// Item identifies the hit within the project (e.g., "#12" for an
+	// issue, "!3" for a merge request, "path:line" for a blob, or the
+	// short ID for a commit).  It is empty for a project.
+	Item string
+
+	// Title is the title of the hit or, for a blob, the first line of
+	// the matching text.
+	Title string
+
+	// URL is the URL of the web page for the hit.
+	URL string
+}
+
+// ProjectMemo remembers projects by ID so each project is only
+// retrieved once.
+type ProjectMemo struct {
+	s        gitlab_util.ProjectGetter /* was *gitlab.ProjectsService */
+	projects map[int]*gitlab.Project
+}
+
+// NewProjectMemo returns a new, empty ProjectMemo.
+func NewProjectMemo(s gitlab_util.ProjectGetter /* was *gitlab.ProjectsService */) *ProjectMemo {
+	return &ProjectMemo{
+		s:        s,
+		projects: make(map[int]*gitlab.Project),
+	}
+}
+
+// GetProject returns the project having the ID.
+func (memo *ProjectMemo) GetProject(ctx context.Context, id int) (*gitlab.Project, error) {
+	if p, ok := memo.projects[id]; ok {
+		return p, nil
+	}
+	p, _, err := memo.s.GetProject(id, nil, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf(
+			"GetProject: %w", gitlab_util.ClassifyError(err))
+	}
+	memo.projects[id] = p
+	return p, nil
+}
+
+// searchIn returns the results of searching the group for the query
+// using the searchGroup method of gitlab.SearchService or, if group is
+// empty, of searching the whole instance using the searchAll method.
+func searchIn[T any](
+	ctx context.Context,
+	searchAll gitlab_util.SearchFunc[T],
+	searchGroup func(
+		gid interface{},
+		query string,
+		opt *gitlab.SearchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]T, *gitlab.Response, error),
+	group string,
+	query string,
+) ([]T, error) {
+	search := searchAll
+	if group != "" {
+		search = func(
+			query string,
+			opt *gitlab.SearchOptions,
+			options ...gitlab.RequestOptionFunc,
+		) ([]T, *gitlab.Response, error) {
+			return searchGroup(group, query, opt, options...)
+		}
+	}
+	return gitlab_util.GetAllSearchResults(ctx, search, query)
+}
+
+// firstLine returns the first non-blank line of the text without
+// leading and trailing white space.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Search returns the hits for the query in the scope (see
+// [SearchOptions]) within the group which can be the full path or the
+// group ID.  If group is empty, the whole instance is searched.
+func Search(
+	ctx context.Context,
+	search Searcher, /* was *gitlab.SearchService */
+	memo *ProjectMemo,
+	scope string,
+	group string,
+	query string,
+) ([]*SearchHit, error) {
+	var hits []*SearchHit
+
+	// projectOf returns the project having the ID.
+	projectOf := func(id int) (*gitlab.Project, error) {
+		p, err := memo.GetProject(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("Search: %w", err)
+		}
+		return p, nil
+	}
+
+	switch scope {
+	case "projects":
+		ps, err := searchIn(ctx, search.Projects, search.ProjectsByGroup, group, query)
+		if err != nil {
+			return nil, fmt.Errorf("Search: %w", err)
+		}
+		for _, p := range ps {
+			hits = append(hits, &SearchHit{
+				Project: p.PathWithNamespace,
+				Title:   p.Name,
+				URL:     p.WebURL,
+			})
+		}
+	case "issues":
+		issues, err := searchIn(ctx, search.Issues, search.IssuesByGroup, group, query)
+		if err != nil {
+			return nil, fmt.Errorf("Search: %w", err)
+		}
+		for _, issue := range issues {
+			p, err := projectOf(issue.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			hits = append(hits, &SearchHit{
+				Project: p.PathWithNamespace,
+				Item:    fmt.Sprintf("#%d", issue.IID),
+				Title:   issue.Title,
+				URL:     issue.WebURL,
+			})
+		}
+	case "merge_requests":
+		mrs, err := searchIn(ctx, search.MergeRequests, search.MergeRequestsByGroup, group, query)
+		if err != nil {
+			return nil, fmt.Errorf("Search: %w", err)
+		}
+		for _, mr := range mrs {
+			p, err := projectOf(mr.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			hits = append(hits, &SearchHit{
+				Project: p.PathWithNamespace,
+				Item:    fmt.Sprintf("!%d", mr.IID),
+				Title:   mr.Title,
+				URL:     mr.WebURL,
+			})
+		}
+	case "blobs":
+		blobs, err := searchIn(ctx, search.Blobs, search.BlobsByGroup, group, query)
+		if err != nil {
+			return nil, fmt.Errorf("Search: %w", err)
+		}
+		for _, blob := range blobs {
+			p, err := projectOf(blob.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			hit := &SearchHit{
+				Project: p.PathWithNamespace,
+				Item:    fmt.Sprintf("%s:%d", blob.Path, blob.Startline),
+				Title:   firstLine(blob.Data),
+			}
+			if p.WebURL != "" {
+				hit.URL = fmt.Sprintf("%s/-/blob/%s/%s#L%d", p.WebURL,
+					url.PathEscape(blob.Ref), blob.Path, blob.Startline)
+			}
+			hits = append(hits, hit)
+		}
+	case "commits":
+		commits, err := searchIn(ctx, search.Commits, search.CommitsByGroup, group, query)
+		if err != nil {
+			return nil, fmt.Errorf("Search: %w", err)
+		}
+		for _, c := range commits {
+			p, err := projectOf(c.ProjectID)
+			if err != nil {
+				return nil, err
+			}
+			hits = append(hits, &SearchHit{
+				Project: p.PathWithNamespace,
+				Item:    c.ShortID,
+				Title:   c.Title,
+				URL:     c.WebURL,
+			})
+		}
+	default:
+		return nil, i18n.Errorf("Search: %w: invalid scope: %q",
+			ErrInvalidOption, scope)
+	}
+
+	return hits, nil
+}
+
+// PrintSearchHits prints the hits as a table.
+func PrintSearchHits(out io.Writer, hits []*SearchHit) {
+	row := func(project, item, title string) {
+		fmt.Fprintf(out, "%-30s  %-20s  %s\n", project, item, title)
+	}
+	row(i18n.T("PROJECT"), i18n.T("ITEM"), i18n.T("TITLE"))
+	for _, h := range hits {
+		item := h.Item
+		if item == "" {
+			item = "-"
+		}
+		row(h.Project, item, h.Title)
+	}
+}
+
+// WriteSearchHitsCSV writes the hits as CSV with a header row.
+func WriteSearchHitsCSV(out io.Writer, hits []*SearchHit) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"project", "item", "title", "url"})
+	for _, h := range hits {
+		w.Write([]string{h.Project, h.Item, h.Title, h.URL})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("WriteSearchHitsCSV: %w", err)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *SearchCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Query == "" {
+		return result, i18n.Errorf("%w: query not set", ErrInvalidOption)
+	}
+	if !slices.Contains(searchScopes, cmd.options.Scope) {
+		return result, i18n.Errorf("%w: invalid scope: %q",
+			ErrInvalidOption, cmd.options.Scope)
+	}
+	if cmd.options.Format != "table" && cmd.options.Format != "csv" {
+		return result, i18n.Errorf("%w: invalid format: %q",
+			ErrInvalidOption, cmd.options.Format)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Search.
+	hits, err := Search(ctx, cmd.client.Search, NewProjectMemo(cmd.client.Projects),
+		cmd.options.Scope, cmd.options.Group, cmd.options.Query)
+	if err != nil {
+		return result, err
+	}
+	for _, h := range hits {
+		result.Succeed(h.Project+":"+h.Item, h)
+	}
+
+	// Open the output file.
+	out := io.Writer(os.Stdout)
+	if cmd.options.OutputFileName != "" {
+		f, err := os.Create(cmd.options.OutputFileName)
+		if err != nil {
+			return result, err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Write the results.
+	if cmd.options.Format == "csv" {
+		return result, WriteSearchHitsCSV(out, hits)
+	}
+	PrintSearchHits(out, hits)
+	return result, nil
+}
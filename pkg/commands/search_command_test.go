@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSearchIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddFile("foo/alpha", "main.go", "package main\n\n// TODO: remove legacyAPI\n")
+	server.AddFile("foo/beta", "main.go", "package main\n")
+	server.AddFile("foo/bar/delta", "lib/lib.go", "package lib\n\nfunc legacyAPI() {}\n")
+	mr := server.AddMergeRequest("foo/beta", "aberns", time.Now(), nil)
+	mr.Title = "Remove legacyAPI"
+	run := func(args ...string) (string, error) {
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewSearchCommand("search", &SearchOptions{}, session)
+		out, _, err := runCommand(t, cmd, args)
+		return out, err
+	}
+	fields := func(out string) []string {
+		var result []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			result = append(result, strings.Join(strings.Fields(line), " "))
+		}
+		return result
+	}
+
+	// Search each scope.
+	out, err := run("--query", "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	projects := fields(out)
+	out, err = run("--query", "legacyAPI", "--scope", "blobs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	blobs := fields(out)
+	out, err = run("--query", "legacyAPI", "--scope", "blobs", "--group", "foo/bar")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	groupBlobs := fields(out)
+	out, err = run("--query", "legacyAPI", "--scope", "merge_requests")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mrs := fields(out)
+
+	// Write CSV to a file.
+	fileName := filepath.Join(t.TempDir(), "hits.csv")
+	_, err = run("--query", "legacyAPI", "--scope", "blobs", "--format", "csv",
+		"-o", fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	csvContent, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, invalidErr := run("--query", "legacyAPI", "--scope", "wikis")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"projects", []string{"foo/test-gamma - test-gamma", "foo/bar/test-epsilon - test-epsilon"},
+			projects},
+		{"blobs", []string{
+			"foo/alpha main.go:3 // TODO: remove legacyAPI",
+			"foo/bar/delta lib/lib.go:3 func legacyAPI() {}",
+		}, blobs},
+		{"group", []string{"foo/bar/delta lib/lib.go:3 func legacyAPI() {}"}, groupBlobs},
+		{"merge requests", []string{"foo/beta !1 Remove legacyAPI"}, mrs},
+		{"csv", "project,item,title,url\n" +
+			"foo/alpha,main.go:3,// TODO: remove legacyAPI,\n" +
+			"foo/bar/delta,lib/lib.go:3,func legacyAPI() {},\n", string(csvContent)},
+		{"invalid scope", true, errors.Is(invalidErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("search %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+// This file provides the implementation for the "seed" command which
+// populates a test instance with a tree of random subgroups,
+// projects, users, issues, and merge requests described by a spec
+// file.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// SeedOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// SeedOptions are the options needed by this command.
+type SeedOptions struct {
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ParentGroup is the group under which everything is created.
+	// The parent group must already exist.  Defaults to "".
+	ParentGroup string `xml:"parent-group"`
+
+	// SpecFileName is the name of the XML file holding the
+	// [SeedSpec] that describes what is created.  Defaults to "".
+	SpecFileName string `xml:"spec-file-name"`
+}
+
+// Initialize initializes this SeedOptions instance so it can be used
+// with the "flag" package to parse the command-line arguments.
+func (opts *SeedOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --parent-group
+	flags.StringVar(&opts.ParentGroup, "parent-group", opts.ParentGroup,
+		i18n.T("group under which everything is created"))
+
+	// --spec
+	flags.StringVar(&opts.SpecFileName, "spec", opts.SpecFileName,
+		i18n.T("name of the XML file that describes what is created"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// SeedCommand
+////////////////////////////////////////////////////////////////////////
+
+// SeedCommand implements the "seed" command which populates a test
+// instance with a tree of random subgroups, projects, users, issues,
+// and merge requests described by a spec file.
+type SeedCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[SeedOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *SeedCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] seed [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Populates a test instance with random subgroups, projects,\n")
+	i18n.Fprintf(out, "    users, issues, and merge requests under --parent-group as\n")
+	i18n.Fprintf(out, "    described by the --spec file for load testing and demos.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Seed Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewSeedCommand returns a new, initialized SeedCommand instance.
+func NewSeedCommand(
+	name string,
+	opts *SeedOptions,
+	session *Session,
+) *SeedCommand {
+
+	// Create the new command.
+	cmd := &SeedCommand{
+		GitlabCommand: GitlabCommand[SeedOptions]{
+			BasicCommand: BasicCommand[SeedOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SeedServices are the Gitlab services needed to seed an instance.
+type SeedServices struct {
+	Groups        SeedGroupsService               /* was *gitlab.GroupsService */
+	Projects      gitlab_util.ProjectCreator      /* was *gitlab.ProjectsService */
+	Users         gitlab_util.UserCreator         /* was *gitlab.UsersService */
+	Issues        gitlab_util.IssueCreator        /* was *gitlab.IssuesService */
+	Commits       gitlab_util.CommitCreator       /* was *gitlab.CommitsService */
+	MergeRequests gitlab_util.MergeRequestCreator /* was *gitlab.MergeRequestsService */
+}
+
+// SeedGroupsService is an abstraction of gitlab.GroupsService which
+// finds and creates groups.
+type SeedGroupsService interface {
+	gitlab_util.GroupFinder
+	gitlab_util.GroupCreator
+}
+
+// seeder creates everything described by the spec.
+type seeder struct {
+	result *Result
+	s      *SeedServices
+	spec   *SeedSpec
+	dryRun bool
+}
+
+// item creates one item by calling f while printing the progress,
+// firing the event hooks, and recording the result.
+func (sd *seeder) item(
+	ctx context.Context,
+	name string,
+	desc string,
+	f func() error,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- %s %q ... ", desc, name)
+	if !sd.dryRun {
+		err := f()
+		if err != nil {
+			err = fmt.Errorf("Seed: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(name, err)
+			sd.result.Fail(name, nil, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	sd.result.Succeed(name, nil)
+	return nil
+}
+
+// seedUsers creates the users.
+func (sd *seeder) seedUsers(ctx context.Context) error {
+	for i := 0; i < sd.spec.Users; i++ {
+		username, err := CreateRandomUser(
+			ctx, sd.s.Users, sd.spec.BaseName, sd.dryRun)
+		if err != nil {
+			sd.result.Fail(username, nil, err)
+			return err
+		}
+		sd.result.Succeed(username, nil)
+	}
+	return nil
+}
+
+// seedProject creates a project in the group along with its issues
+// and merge requests.
+func (sd *seeder) seedProject(ctx context.Context, g *gitlab.Group) error {
+
+	// Create the project.
+	fullPath, err := CreateRandomProject(
+		ctx, sd.s.Projects, g, sd.spec.BaseName, sd.dryRun)
+	if err != nil {
+		sd.result.Fail(fullPath, nil, err)
+		return err
+	}
+	sd.result.Succeed(fullPath, nil)
+
+	// Create the issues.
+	for i := 1; i <= sd.spec.IssuesPerProject; i++ {
+		name := fmt.Sprintf("%s#%d", fullPath, i)
+		err := sd.item(ctx, name, i18n.T("Creating issue"), func() error {
+			_, _, err := sd.s.Issues.CreateIssue(fullPath,
+				&gitlab.CreateIssueOptions{
+					Title: gitlab.Ptr(fmt.Sprintf("Seed issue %d", i)),
+				},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Merge requests need a target branch with a commit, so seed the
+	// default branch first.
+	if sd.spec.MergeRequestsPerProject == 0 {
+		return nil
+	}
+	target := "main"
+	name := fullPath + ":" + target
+	err = sd.item(ctx, name, i18n.T("Creating branch"), func() error {
+		_, _, err := sd.s.Commits.CreateCommit(fullPath,
+			&gitlab.CreateCommitOptions{
+				Branch:        gitlab.Ptr(target),
+				CommitMessage: gitlab.Ptr("Initial commit"),
+				Actions: []*gitlab.CommitActionOptions{{
+					Action:   gitlab.Ptr(gitlab.FileCreate),
+					FilePath: gitlab.Ptr("README.md"),
+					Content:  gitlab.Ptr("# " + fullPath + "\n"),
+				}},
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// Create the merge requests each from its own branch.
+	for i := 1; i <= sd.spec.MergeRequestsPerProject; i++ {
+		source := fmt.Sprintf("seed-%d", i)
+		name := fmt.Sprintf("%s!%d", fullPath, i)
+		err := sd.item(ctx, name, i18n.T("Creating merge request"), func() error {
+			_, _, err := sd.s.Commits.CreateCommit(fullPath,
+				&gitlab.CreateCommitOptions{
+					Branch:        gitlab.Ptr(source),
+					StartBranch:   gitlab.Ptr(target),
+					CommitMessage: gitlab.Ptr(fmt.Sprintf("Add %s.txt", source)),
+					Actions: []*gitlab.CommitActionOptions{{
+						Action:   gitlab.Ptr(gitlab.FileCreate),
+						FilePath: gitlab.Ptr(source + ".txt"),
+						Content:  gitlab.Ptr(uuid.NewString() + "\n"),
+					}},
+				},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			if err != nil {
+				return err
+			}
+			_, _, err = sd.s.MergeRequests.CreateMergeRequest(fullPath,
+				&gitlab.CreateMergeRequestOptions{
+					Title:        gitlab.Ptr(fmt.Sprintf("Seed merge request %d", i)),
+					SourceBranch: gitlab.Ptr(source),
+					TargetBranch: gitlab.Ptr(target),
+				},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedGroup creates the projects in the group and, if the group is
+// less than depth levels below the parent group, its subgroups
+// recursively.
+func (sd *seeder) seedGroup(ctx context.Context, g *gitlab.Group, level int) error {
+
+	// Create the projects.
+	for i := 0; i < sd.spec.ProjectsPerGroup; i++ {
+		err := sd.seedProject(ctx, g)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the subgroups.
+	if level >= sd.spec.Depth {
+		return nil
+	}
+	for i := 0; i < sd.spec.SubgroupsPerGroup; i++ {
+		subgroup, err := CreateRandomGroup(
+			ctx, sd.s.Groups, g, sd.spec.BaseName, sd.dryRun)
+		if err != nil {
+			sd.result.Fail(subgroup.FullPath, nil, err)
+			return err
+		}
+		sd.result.Succeed(subgroup.FullPath, nil)
+		err = sd.seedGroup(ctx, subgroup, level+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Seed creates the users and the tree of subgroups, projects, issues,
+// and merge requests described by the spec under the parent group.
+// If dryRun is true, this function only prints what it would without
+// actually doing it.
+func Seed(
+	ctx context.Context,
+	result *Result,
+	s *SeedServices,
+	parentGroup string,
+	spec *SeedSpec,
+	dryRun bool,
+) error {
+
+	// Get the parent group.
+	logging.Printf("- Searching for ID for parent group %q ... ", parentGroup)
+	g, err := gitlab_util.FindExactGroup(ctx, s.Groups, parentGroup)
+	if err != nil {
+		return err
+	}
+	logging.Printf("Done.\n")
+
+	// Print what will be created.
+	projectCount := spec.ProjectCount()
+	logging.Printf("- Seeding %d user(s), %d group(s), %d project(s), "+
+		"%d issue(s), and %d merge request(s).\n",
+		spec.Users,
+		spec.GroupCount(),
+		projectCount,
+		projectCount*spec.IssuesPerProject,
+		projectCount*spec.MergeRequestsPerProject)
+
+	// Create everything.
+	sd := &seeder{
+		result: result,
+		s:      s,
+		spec:   spec,
+		dryRun: dryRun,
+	}
+	err = sd.seedUsers(ctx)
+	if err != nil {
+		return err
+	}
+	return sd.seedGroup(ctx, g, 0)
+}
+
+// Run is the entry point for this command.
+func (cmd *SeedCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.ParentGroup == "" {
+		return result, i18n.Errorf("%w: parent-group not set", ErrInvalidOption)
+	} else if cmd.options.SpecFileName == "" {
+		return result, i18n.Errorf("%w: spec not set", ErrInvalidOption)
+	}
+
+	// Load the spec.
+	spec, err := LoadSeedSpec(cmd.options.SpecFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Seed the instance.
+	err = Seed(
+		ctx,
+		result,
+		&SeedServices{
+			Groups:        cmd.client.Groups,
+			Projects:      cmd.client.Projects,
+			Users:         cmd.client.Users,
+			Issues:        cmd.client.Issues,
+			Commits:       cmd.client.Commits,
+			MergeRequests: cmd.client.MergeRequests,
+		},
+		cmd.options.ParentGroup,
+		spec,
+		cmd.options.DryRun)
+	return result, err
+}
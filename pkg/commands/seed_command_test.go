@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSeedIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	groupsBefore := len(server.Groups())
+	projectsBefore := server.Projects()
+	usersBefore := len(server.Users())
+
+	// Write the spec.
+	specFileName := filepath.Join(t.TempDir(), "seed.xml")
+	err := os.WriteFile(specFileName, []byte(`<seed-spec>
+  <base-name>demo</base-name>
+  <depth>2</depth>
+  <subgroups-per-group>2</subgroups-per-group>
+  <projects-per-group>1</projects-per-group>
+  <issues-per-project>2</issues-per-project>
+  <merge-requests-per-project>1</merge-requests-per-project>
+  <users>3</users>
+</seed-spec>`), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Seed the instance.
+	cmd := NewGlobalCommand("glcmds", "0.0.0")
+	cmd.session = session
+	out, _, err := runCommand(t, cmd, []string{
+		"--options", "", "seed", "--parent-group", "foo/bar", "--spec", specFileName})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify what was created.
+	var newProjects []string
+	for _, p := range server.Projects() {
+		if !slices.Contains(projectsBefore, p) {
+			newProjects = append(newProjects, p)
+		}
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"summary", true, strings.Contains(out,
+			"Seeding 3 user(s), 6 group(s), 7 project(s), 14 issue(s), and 7 merge request(s).")},
+		{"groups", groupsBefore + 6, len(server.Groups())},
+		{"projects", 7, len(newProjects)},
+		{"users", usersBefore + 3, len(server.Users())},
+	}
+	for _, p := range newProjects {
+		data = append(data, Data{
+			{p + " prefix", true, strings.HasPrefix(p, "foo/bar/")},
+			{p + " issues", 2, len(server.Issues(p))},
+			{p + " merge requests", "[seed-1->main]", fmt.Sprint(server.MergeRequests(p))},
+		}...)
+	}
+	for _, d := range data {
+		if d.actual != d.expected {
+			t.Errorf("seed %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// This file provides the spec file that describes the tree of random
+// groups, projects, users, issues, and merge requests created by the
+// "seed" command.
+
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// SeedSpec describes what the "seed" command creates.  The tree of
+// subgroups is Depth levels deep below the parent group with
+// SubgroupsPerGroup subgroups in each group, and every group in the
+// tree including the parent group gets ProjectsPerGroup projects.
+type SeedSpec struct {
+	XMLName xml.Name `xml:"seed-spec"`
+
+	// BaseName is the base name of everything that is created.  The
+	// full name includes random characters after the base name.
+	// Defaults to "seed".
+	BaseName string `xml:"base-name"`
+
+	// Depth is the number of levels of subgroups below the parent
+	// group.
+	Depth int `xml:"depth"`
+
+	// SubgroupsPerGroup is the number of subgroups created in each
+	// group that is less than Depth levels below the parent group.
+	SubgroupsPerGroup int `xml:"subgroups-per-group"`
+
+	// ProjectsPerGroup is the number of projects created in each
+	// group.
+	ProjectsPerGroup int `xml:"projects-per-group"`
+
+	// IssuesPerProject is the number of issues created in each
+	// project.
+	IssuesPerProject int `xml:"issues-per-project"`
+
+	// MergeRequestsPerProject is the number of merge requests created
+	// in each project.
+	MergeRequestsPerProject int `xml:"merge-requests-per-project"`
+
+	// Users is the number of users created.  Creating users requires
+	// an administrator token.
+	Users int `xml:"users"`
+}
+
+// LoadSeedSpec loads and validates the seed spec from the file.
+func LoadSeedSpec(fileName string) (*SeedSpec, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("LoadSeedSpec: %w", err)
+	}
+	spec := &SeedSpec{}
+	err = xml.Unmarshal(data, spec)
+	if err != nil {
+		return nil, fmt.Errorf("LoadSeedSpec: %s: %w", fileName, err)
+	}
+	if spec.BaseName == "" {
+		spec.BaseName = "seed"
+	}
+	err = spec.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("LoadSeedSpec: %s: %w", fileName, err)
+	}
+	return spec, nil
+}
+
+// Validate returns an error if any of the counts is negative.
+func (spec *SeedSpec) Validate() error {
+	for _, c := range []struct {
+		name  string
+		value int
+	}{
+		{"depth", spec.Depth},
+		{"subgroups-per-group", spec.SubgroupsPerGroup},
+		{"projects-per-group", spec.ProjectsPerGroup},
+		{"issues-per-project", spec.IssuesPerProject},
+		{"merge-requests-per-project", spec.MergeRequestsPerProject},
+		{"users", spec.Users},
+	} {
+		if c.value < 0 {
+			return i18n.Errorf("%w: invalid %s: %v", ErrInvalidOption, c.name, c.value)
+		}
+	}
+	return nil
+}
+
+// GroupCount returns the number of subgroups that will be created.
+func (spec *SeedSpec) GroupCount() int {
+	result := 0
+	level := 1
+	for i := 0; i < spec.Depth; i++ {
+		level *= spec.SubgroupsPerGroup
+		result += level
+	}
+	return result
+}
+
+// ProjectCount returns the number of projects that will be created.
+func (spec *SeedSpec) ProjectCount() int {
+	return (spec.GroupCount() + 1) * spec.ProjectsPerGroup
+}
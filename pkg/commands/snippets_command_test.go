@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestSnippetsIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	notes := server.AddSnippet("", "Notes", "notes.md", "# Notes\n")
+	setup := server.AddSnippet("foo/alpha", "Setup", "setup.sh", "make setup\n")
+	server.AddSnippet("foo/bar/delta", "Query", "../query.sql", "SELECT 1;\n")
+	run := func(args ...string) (string, error) {
+		session := NewSessionWithClient(server.Client(t))
+		session.globalOpts.Yes = true
+		cmd := NewSnippetsCommand("snippets", &SnippetsOptions{}, session)
+		out, _, err := runCommand(t, cmd, args)
+		return out, err
+	}
+	fields := func(out string) []string {
+		var result []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			result = append(result, strings.Join(strings.Fields(line), " "))
+		}
+		return result
+	}
+
+	// List the personal and project snippets.
+	out, err := run("list")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	personal := fields(out)
+	out, err = run("list", "--group", "foo", "--recursive")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	projects := fields(out)
+
+	// Export the project snippets.
+	dir := t.TempDir()
+	_, err = run("export", "--group", "foo", "--recursive", "--dir", dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err.Error()
+		}
+		return string(content)
+	}
+
+	// Create a personal and a project snippet.
+	fileName := filepath.Join(t.TempDir(), "todo.txt")
+	err = os.WriteFile(fileName, []byte("write tests\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = run("create", "--title", "Todo", "--file", fileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = run("create", "--title", "Todo", "--file", fileName,
+		"--project", "foo/beta", "--visibility", "internal")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, invalidErr := run("create", "--title", "Todo", "--file", fileName,
+		"--visibility", "secret")
+
+	// Delete snippets with and without --dry-run.
+	_, err = run("delete", "--id", fmt.Sprint(notes.ID), "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dryRun := server.Snippets("")
+	_, err = run("delete", "--id", fmt.Sprint(notes.ID))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = run("delete", "--id", fmt.Sprint(setup.ID), "--project", "foo/alpha")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, missingErr := run("delete", "--id", fmt.Sprint(setup.ID), "--project", "foo/alpha")
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"list personal", []string{fmt.Sprintf("%d private - notes.md Notes", notes.ID)},
+			personal},
+		{"list projects", []string{
+			fmt.Sprintf("%d private foo/alpha setup.sh Setup", setup.ID),
+			fmt.Sprintf("%d private foo/bar/delta ../query.sql Query", setup.ID+1),
+		}, projects},
+		{"export", "make setup\n",
+			read(filepath.Join("foo", "alpha", fmt.Sprint(setup.ID)+"-setup.sh"))},
+		{"export base name", "SELECT 1;\n",
+			read(filepath.Join("foo", "bar", "delta", fmt.Sprint(setup.ID+1)+"-query.sql"))},
+		{"invalid visibility", true, errors.Is(invalidErr, ErrInvalidOption)},
+		{"dry run", []string{"Notes", "Todo"}, dryRun},
+		{"personal", []string{"Todo"}, server.Snippets("")},
+		{"project", []string{"Todo"}, server.Snippets("foo/beta")},
+		{"deleted", []string{}, server.Snippets("foo/alpha")},
+		{"missing", true, errors.Is(missingErr, gitlab_util.ErrNotFound)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("snippets %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+// This file provides the implementation for the "snippets create"
+// command which creates a personal or project snippet from a local
+// file.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsCreateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// SnippetsCreateOptions are the options needed by this command.
+type SnippetsCreateOptions struct {
+
+	// Description is the description of the snippet.  Defaults to "".
+	Description string `xml:"description"`
+
+	// FileName is the name of the local file whose content and base
+	// name are used for the snippet.  Defaults to "".
+	FileName string `xml:"file-name"`
+
+	// Project is the full path or ID of the project to which the
+	// snippet belongs.  If empty, a personal snippet is created.
+	// Defaults to "".
+	Project string `xml:"project"`
+
+	// Title is the title of the snippet.  Defaults to "".
+	Title string `xml:"title"`
+
+	// Visibility is the visibility of the snippet which is one of
+	// "private", "internal", or "public".  Defaults to "private".
+	Visibility string `xml:"visibility"`
+}
+
+// Initialize initializes this SnippetsCreateOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *SnippetsCreateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --description
+	flags.StringVar(&opts.Description, "description", opts.Description,
+		i18n.T("description of the snippet"))
+
+	// --file
+	flags.StringVar(&opts.FileName, "file", opts.FileName,
+		i18n.T("name of the local file whose content and base name are used for the snippet"))
+
+	// --project
+	flags.StringVar(&opts.Project, "project", opts.Project,
+		i18n.T("full path or ID of the project to which the snippet belongs "+
+			"instead of creating a personal snippet"))
+
+	// --title
+	flags.StringVar(&opts.Title, "title", opts.Title,
+		i18n.T("title of the snippet"))
+
+	// --visibility
+	if opts.Visibility == "" {
+		opts.Visibility = "private"
+	}
+	flags.StringVar(&opts.Visibility, "visibility", opts.Visibility,
+		i18n.T("visibility of the snippet which is one of private, internal, or public"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsCreateCommand
+////////////////////////////////////////////////////////////////////////
+
+// SnippetsCreateCommand implements the "snippets create" command which
+// creates a snippet.
+type SnippetsCreateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[SnippetsCreateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *SnippetsCreateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] snippets create [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create a snippet from the --file in the --project or, if\n")
+	i18n.Fprintf(out, "    --project is not set, a personal snippet.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewSnippetsCreateCommand returns a new, initialized
+// SnippetsCreateCommand instance.
+func NewSnippetsCreateCommand(
+	name string,
+	opts *SnippetsCreateOptions,
+	session *Session,
+) *SnippetsCreateCommand {
+
+	// Create the new command.
+	cmd := &SnippetsCreateCommand{
+		GitlabCommand: GitlabCommand[SnippetsCreateOptions]{
+			BasicCommand: BasicCommand[SnippetsCreateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SnippetsCreateServices are the Gitlab services needed to create
+// snippets.
+type SnippetsCreateServices struct {
+	ProjectSnippets gitlab_util.ProjectSnippetCreator /* was *gitlab.ProjectSnippetsService */
+	Snippets        gitlab_util.SnippetCreator        /* was *gitlab.SnippetsService */
+}
+
+// snippetVisibilities are the valid visibilities of a snippet.
+var snippetVisibilities = []string{"private", "internal", "public"}
+
+// CreateSnippet creates a snippet having a single file with the name
+// and content in the project which can be the project ID or its full
+// path.  If project is empty, a personal snippet is created.
+func CreateSnippet(
+	ctx context.Context,
+	s SnippetsCreateServices,
+	project string,
+	title string,
+	description string,
+	visibility string,
+	fileName string,
+	content string,
+) (*gitlab.Snippet, error) {
+	files := []*gitlab.CreateSnippetFileOptions{{
+		FilePath: gitlab.Ptr(fileName),
+		Content:  gitlab.Ptr(content),
+	}}
+	var snippet *gitlab.Snippet
+	var err error
+	if project == "" {
+		snippet, _, err = s.Snippets.CreateSnippet(
+			&gitlab.CreateSnippetOptions{
+				Title:       gitlab.Ptr(title),
+				Description: gitlab.Ptr(description),
+				Visibility:  gitlab.Ptr(gitlab.VisibilityValue(visibility)),
+				Files:       &files,
+			},
+			gitlab.WithContext(ctx))
+	} else {
+		snippet, _, err = s.ProjectSnippets.CreateSnippet(project,
+			&gitlab.CreateProjectSnippetOptions{
+				Title:       gitlab.Ptr(title),
+				Description: gitlab.Ptr(description),
+				Visibility:  gitlab.Ptr(gitlab.VisibilityValue(visibility)),
+				Files:       &files,
+			},
+			gitlab.WithContext(ctx))
+	}
+	if err != nil {
+		return nil, fmt.Errorf(
+			"CreateSnippet: %w", gitlab_util.ClassifyError(err))
+	}
+	return snippet, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *SnippetsCreateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Title == "" {
+		return result, i18n.Errorf("%w: title not set", ErrInvalidOption)
+	}
+	if cmd.options.FileName == "" {
+		return result, i18n.Errorf("%w: file not set", ErrInvalidOption)
+	}
+	if !slices.Contains(snippetVisibilities, cmd.options.Visibility) {
+		return result, i18n.Errorf("%w: invalid visibility: %q",
+			ErrInvalidOption, cmd.options.Visibility)
+	}
+
+	// Read the content of the snippet.
+	content, err := os.ReadFile(cmd.options.FileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Create the snippet.
+	logging.Printf("- Creating snippet %q ... ", cmd.options.Title)
+	snippet, err := CreateSnippet(ctx,
+		SnippetsCreateServices{
+			ProjectSnippets: cmd.client.ProjectSnippets,
+			Snippets:        cmd.client.Snippets,
+		},
+		cmd.options.Project, cmd.options.Title, cmd.options.Description,
+		cmd.options.Visibility, filepath.Base(cmd.options.FileName),
+		string(content))
+	if err != nil {
+		logging.Printf("Failed.\n")
+		result.Fail(cmd.options.Title, nil, err)
+		return result, err
+	}
+	logging.Printf("Done.\n")
+	fmt.Printf("%s\n", snippet.WebURL)
+	result.Succeed(cmd.options.Title, snippet)
+
+	return result, nil
+}
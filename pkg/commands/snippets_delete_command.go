@@ -0,0 +1,240 @@
+// This file provides the implementation for the "snippets delete"
+// command which deletes a personal or project snippet.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsDeleteOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// SnippetsDeleteOptions are the options needed by this command.
+type SnippetsDeleteOptions struct {
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ID is the ID of the snippet.  Defaults to 0.
+	ID int `xml:"id"`
+
+	// Project is the full path or ID of the project to which the
+	// snippet belongs.  If empty, the snippet is a personal snippet.
+	// Defaults to "".
+	Project string `xml:"project"`
+}
+
+// Initialize initializes this SnippetsDeleteOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *SnippetsDeleteOptions) Initialize(flags *flag.FlagSet) {
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --id
+	flags.IntVar(&opts.ID, "id", opts.ID,
+		i18n.T("ID of the snippet"))
+
+	// --project
+	flags.StringVar(&opts.Project, "project", opts.Project,
+		i18n.T("full path or ID of the project to which the snippet belongs "+
+			"if it is not a personal snippet"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsDeleteCommand
+////////////////////////////////////////////////////////////////////////
+
+// SnippetsDeleteCommand implements the "snippets delete" command which
+// deletes a snippet.
+type SnippetsDeleteCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[SnippetsDeleteOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *SnippetsDeleteCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] snippets delete [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Delete the snippet having the --id from the --project or,\n")
+	i18n.Fprintf(out, "    if --project is not set, the personal snippet having the\n")
+	i18n.Fprintf(out, "    --id.  The deletion must be confirmed unless the global --yes\n")
+	i18n.Fprintf(out, "    option is passed.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Delete Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewSnippetsDeleteCommand returns a new, initialized
+// SnippetsDeleteCommand instance.
+func NewSnippetsDeleteCommand(
+	name string,
+	opts *SnippetsDeleteOptions,
+	session *Session,
+) *SnippetsDeleteCommand {
+
+	// Create the new command.
+	cmd := &SnippetsDeleteCommand{
+		GitlabCommand: GitlabCommand[SnippetsDeleteOptions]{
+			BasicCommand: BasicCommand[SnippetsDeleteOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SnippetsDeleteServices are the Gitlab services needed to delete
+// snippets.
+type SnippetsDeleteServices struct {
+	ProjectSnippets gitlab_util.ProjectSnippetDeleter /* was *gitlab.ProjectSnippetsService */
+	Snippets        gitlab_util.SnippetDeleter        /* was *gitlab.SnippetsService */
+}
+
+// DeleteSnippet deletes the snippet from the project which can be the
+// project ID or its full path.  If project is empty, the snippet is a
+// personal snippet.  If dryRun is true, this function only prints what
+// it would without actually doing it.
+func DeleteSnippet(
+	ctx context.Context,
+	s SnippetsDeleteServices,
+	project string,
+	id int,
+	dryRun bool,
+) error {
+	var err error
+	if project == "" {
+		logging.Printf("- Deleting snippet %d ... ", id)
+	} else {
+		logging.Printf("- Deleting snippet %d of %q ... ", id, project)
+	}
+	if !dryRun {
+		if project == "" {
+			_, err = s.Snippets.DeleteSnippet(id,
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		} else {
+			_, err = s.ProjectSnippets.DeleteSnippet(project, id,
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		}
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf(
+				"DeleteSnippet: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *SnippetsDeleteCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.ID <= 0 {
+		return result, i18n.Errorf("%w: invalid id: %v",
+			ErrInvalidOption, cmd.options.ID)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Ask for confirmation.
+	name := fmt.Sprint(cmd.options.ID)
+	if cmd.options.Project != "" {
+		name = cmd.options.Project + ":" + name
+	}
+	if !cmd.options.DryRun {
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"),
+			[]string{name}, "", cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Delete the snippet.
+	err = DeleteSnippet(ctx,
+		SnippetsDeleteServices{
+			ProjectSnippets: cmd.client.ProjectSnippets,
+			Snippets:        cmd.client.Snippets,
+		},
+		cmd.options.Project, cmd.options.ID, cmd.options.DryRun)
+	if err != nil {
+		result.Fail(name, cmd.options.ID, err)
+		return result, err
+	}
+	result.Succeed(name, cmd.options.ID)
+
+	return result, nil
+}
@@ -0,0 +1,238 @@
+// This file provides the implementation for the "snippets export"
+// command which saves the snippets of the selected projects to local
+// files, for example, before the projects are deleted.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsExportOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// SnippetsExportOptions are the options needed by this command.
+type SnippetsExportOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Dir is the local directory to which the snippets are exported.
+	// Defaults to "snippets".
+	Dir string `xml:"dir"`
+}
+
+// Initialize initializes this SnippetsExportOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *SnippetsExportOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --dir
+	if opts.Dir == "" {
+		opts.Dir = "snippets"
+	}
+	flags.StringVar(&opts.Dir, "dir", opts.Dir,
+		i18n.T("local directory to which the snippets are exported"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsExportCommand
+////////////////////////////////////////////////////////////////////////
+
+// SnippetsExportCommand implements the "snippets export" command which
+// exports project snippets to local files.
+type SnippetsExportCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[SnippetsExportOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *SnippetsExportCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] snippets export [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Export the snippets of the selected projects to the --dir\n")
+	i18n.Fprintf(out, "    directory.  The content of each snippet is written to\n")
+	i18n.Fprintf(out, "    <dir>/<project>/<id>-<file> where <project> is the full\n")
+	i18n.Fprintf(out, "    path of the project.  Run this before deleting projects\n")
+	i18n.Fprintf(out, "    because their snippets are deleted with them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Export Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewSnippetsExportCommand returns a new, initialized
+// SnippetsExportCommand instance.
+func NewSnippetsExportCommand(
+	name string,
+	opts *SnippetsExportOptions,
+	session *Session,
+) *SnippetsExportCommand {
+
+	// Create the new command.
+	cmd := &SnippetsExportCommand{
+		GitlabCommand: GitlabCommand[SnippetsExportOptions]{
+			BasicCommand: BasicCommand[SnippetsExportOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SnippetExportFileName returns the name of the local file relative to
+// the export directory to which the snippet is exported.  Only the
+// base name of the file of the snippet is used so the file cannot
+// escape the directory of the project.
+func SnippetExportFileName(s *ProjectSnippet) string {
+	fileName := s.FileName
+	if fileName == "" && len(s.Files) > 0 {
+		fileName = s.Files[0].Path
+	}
+	fileName = filepath.Base(fileName)
+	if fileName == "." || fileName == string(filepath.Separator) {
+		fileName = "snippet"
+	}
+	return filepath.Join(filepath.FromSlash(s.Path),
+		strconv.Itoa(s.ID)+"-"+fileName)
+}
+
+// ExportSnippet writes the content of the project snippet to its file
+// beneath the directory creating any missing directories.
+func ExportSnippet(
+	ctx context.Context,
+	s gitlab_util.ProjectSnippetContentGetter, /* was *gitlab.ProjectSnippetsService */
+	snippet *ProjectSnippet,
+	dir string,
+) error {
+	fileName := filepath.Join(dir, SnippetExportFileName(snippet))
+	logging.Printf("- Exporting snippet %d of %q to %q ... ",
+		snippet.ID, snippet.Path, fileName)
+	content, _, err := s.SnippetContent(snippet.Path, snippet.ID, gitlab.WithContext(ctx))
+	if err != nil {
+		logging.Printf("Failed.\n")
+		return fmt.Errorf("ExportSnippet: %w", gitlab_util.ClassifyError(err))
+	}
+	err = os.MkdirAll(filepath.Dir(fileName), 0755)
+	if err != nil {
+		logging.Printf("Failed.\n")
+		return fmt.Errorf("ExportSnippet: %w", err)
+	}
+	err = os.WriteFile(fileName, content, 0644)
+	if err != nil {
+		logging.Printf("Failed.\n")
+		return fmt.Errorf("ExportSnippet: %w", err)
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *SnippetsExportCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Dir == "" {
+		return result, i18n.Errorf("%w: dir not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the snippets.
+	snippets, err := GetAllProjectSnippets(ctx, result,
+		&cmd.options.ProjectSelectorOptions,
+		SnippetsServices{
+			Groups:          cmd.client.Groups,
+			ProjectSnippets: cmd.client.ProjectSnippets,
+			Snippets:        cmd.client.Snippets,
+		})
+	if err != nil {
+		return result, err
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not get the snippets of %d project(s)", failed)
+	}
+
+	// Export the snippets.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, snippet := range snippets {
+		hook.OnItemStart(snippet.Name())
+		err = ExportSnippet(ctx, cmd.client.ProjectSnippets, snippet, cmd.options.Dir)
+		if err != nil {
+			hook.OnError(snippet.Name(), err)
+			result.Fail(snippet.Name(), snippet, err)
+			return result, err
+		}
+		hook.OnItemDone(snippet.Name())
+		result.Succeed(snippet.Name(), snippet)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,257 @@
+// This file provides the implementation for the "snippets list"
+// command which lists the personal snippets of the caller or the
+// snippets of the selected projects.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// SnippetsListOptions are the options needed by this command.
+type SnippetsListOptions struct {
+
+	// Embed the options that select the projects.  If the group is
+	// not set, the personal snippets of the caller are listed instead.
+	ProjectSelectorOptions
+}
+
+// Initialize initializes this SnippetsListOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *SnippetsListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// SnippetsListCommand
+////////////////////////////////////////////////////////////////////////
+
+// SnippetsListCommand implements the "snippets list" command which
+// lists personal or project snippets.
+type SnippetsListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[SnippetsListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *SnippetsListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] snippets list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the snippets of the selected projects or, if --group\n")
+	i18n.Fprintf(out, "    is not set, the personal snippets of the caller.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewSnippetsListCommand returns a new, initialized
+// SnippetsListCommand instance.
+func NewSnippetsListCommand(
+	name string,
+	opts *SnippetsListOptions,
+	session *Session,
+) *SnippetsListCommand {
+
+	// Create the new command.
+	cmd := &SnippetsListCommand{
+		GitlabCommand: GitlabCommand[SnippetsListOptions]{
+			BasicCommand: BasicCommand[SnippetsListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SnippetsServices are the Gitlab services needed to list snippets.
+type SnippetsServices struct {
+	Groups          gitlab_util.ProjectsInGroupLister /* was *gitlab.GroupsService */
+	ProjectSnippets gitlab_util.ProjectSnippetsLister /* was *gitlab.ProjectSnippetsService */
+	Snippets        gitlab_util.SnippetsLister        /* was *gitlab.SnippetsService */
+}
+
+// ProjectSnippet is a snippet along with the full path of the project
+// to which it belongs.
+type ProjectSnippet struct {
+
+	// Embed the snippet.
+	*gitlab.Snippet
+
+	// Path is the full path of the project or "" for a personal
+	// snippet.
+	Path string `json:"project,omitempty"`
+}
+
+// Name returns the name used for the snippet in the result.
+func (s *ProjectSnippet) Name() string {
+	if s.Path == "" {
+		return strconv.Itoa(s.ID)
+	}
+	return s.Path + ":" + strconv.Itoa(s.ID)
+}
+
+// GetPersonalSnippets returns the personal snippets of the caller.
+func GetPersonalSnippets(
+	ctx context.Context,
+	s gitlab_util.SnippetsLister, /* was *gitlab.SnippetsService */
+) ([]*ProjectSnippet, error) {
+	snippets, err := gitlab_util.GetAllSnippets(ctx, s)
+	if err != nil {
+		return nil, fmt.Errorf("GetPersonalSnippets: %w", err)
+	}
+	var result []*ProjectSnippet
+	for _, snippet := range snippets {
+		result = append(result, &ProjectSnippet{Snippet: snippet})
+	}
+	return result, nil
+}
+
+// GetAllProjectSnippets returns the snippets of each selected project
+// and records each project in the result.
+func GetAllProjectSnippets(
+	ctx context.Context,
+	result *Result,
+	selector *ProjectSelectorOptions,
+	s SnippetsServices,
+) ([]*ProjectSnippet, error) {
+	var snippets []*ProjectSnippet
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			ss, err := gitlab_util.GetAllProjectSnippets(ctx, s.ProjectSnippets, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			result.Succeed(p.PathWithNamespace, p)
+			for _, snippet := range ss {
+				snippets = append(snippets, &ProjectSnippet{
+					Snippet: snippet,
+					Path:    p.PathWithNamespace,
+				})
+			}
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("GetAllProjectSnippets: %w", err)
+	}
+	return snippets, nil
+}
+
+// PrintSnippets prints the snippets as a table.  Personal snippets are
+// printed with "-" as the project.
+func PrintSnippets(out io.Writer, snippets []*ProjectSnippet) {
+	row := func(id, visibility, path, fileName, title string) {
+		fmt.Fprintf(out, "%-8s  %-10s  %-30s  %-20s  %s\n",
+			id, visibility, path, fileName, title)
+	}
+	row(i18n.T("ID"), i18n.T("VISIBILITY"), i18n.T("PROJECT"),
+		i18n.T("FILE"), i18n.T("TITLE"))
+	for _, s := range snippets {
+		path := s.Path
+		if path == "" {
+			path = "-"
+		}
+		row(strconv.Itoa(s.ID), s.Visibility, path, s.FileName, s.Title)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *SnippetsListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Get the snippets.
+	s := SnippetsServices{
+		Groups:          cmd.client.Groups,
+		ProjectSnippets: cmd.client.ProjectSnippets,
+		Snippets:        cmd.client.Snippets,
+	}
+	var snippets []*ProjectSnippet
+	if cmd.options.Group == "" {
+		snippets, err = GetPersonalSnippets(ctx, s.Snippets)
+	} else {
+		snippets, err = GetAllProjectSnippets(ctx, result,
+			&cmd.options.ProjectSelectorOptions, s)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Print the snippets.
+	if cmd.session.OutputJSON() {
+		if snippets == nil {
+			snippets = []*ProjectSnippet{}
+		}
+		err = writeJSON(os.Stdout, snippets)
+		return result, err
+	}
+	PrintSnippets(os.Stdout, snippets)
+	return result, nil
+}
@@ -0,0 +1,51 @@
+// This file provides the helpers shared by the "tags" subcommands.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/xanzy/go-gitlab"
+)
+
+// tagName returns the name used to identify the tag in a Result and in
+// messages which is the full path of the project and the name of the
+// tag separated by a colon.
+func tagName(p *gitlab.Project, tag string) string {
+	return p.PathWithNamespace + ":" + tag
+}
+
+// tagCreateAccessLevel returns the role-based access level allowed to
+// create the protected tag or gitlab.NoPermissions if there is none.
+// Access granted to individual users or groups is ignored.
+func tagCreateAccessLevel(t *gitlab.ProtectedTag) gitlab.AccessLevelValue {
+	for _, d := range t.CreateAccessLevels {
+		if d.UserID == 0 && d.GroupID == 0 {
+			return d.AccessLevel
+		}
+	}
+	return gitlab.NoPermissions
+}
+
+// FindProtectedTag returns the protection of the tag in the project or
+// nil if the tag is not protected.  The name can be a wildcard (e.g.,
+// "v*") which must match the name of the protection exactly.
+func FindProtectedTag(
+	ctx context.Context,
+	s gitlab_util.ProtectedTagsLister, /* was *gitlab.ProtectedTagsService */
+	p *gitlab.Project,
+	name string,
+) (*gitlab.ProtectedTag, error) {
+	ts, err := gitlab_util.GetAllProtectedTags(ctx, s, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("FindProtectedTag: %w", err)
+	}
+	for _, t := range ts {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
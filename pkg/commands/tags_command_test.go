@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestTagsIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddTag("foo/beta", "v1.0.0")
+	server.AddProtectedTag("foo/beta", "v*", gitlab.DeveloperPermissions)
+	server.AddProtectedTag("foo/bar/delta", "v*", gitlab.MaintainerPermissions)
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Yes = true
+
+	// run runs the "tags" subcommand with the arguments and returns
+	// its output.
+	run := func(args ...string) (string, error) {
+		var err error
+		cmd := NewTagsCommand("tags", &TagsOptions{}, session)
+		output, _, err := runCommand(t, cmd, args)
+		return output, err
+	}
+
+	// levels returns the create access levels of the protected tags
+	// of the project.
+	levels := func(project string) []string {
+		var result []string
+		for _, pt := range server.ProtectedTags(project) {
+			result = append(result,
+				pt.Name+"="+branchAccessLevelName(tagCreateAccessLevel(pt)))
+		}
+		return result
+	}
+
+	// Create a tag in the projects that are not test projects which
+	// skips foo/beta because it already has the tag.
+	_, err := run("create", "--group", "foo", "-r", "--expr", "/(alpha|beta|delta)$",
+		"--tag", "v1.0.0", "--ref", "main", "--message", "First release.")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"alpha", []string{"v1.0.0"}, server.Tags("foo/alpha")},
+		{"beta", []string{"v1.0.0"}, server.Tags("foo/beta")},
+		{"delta", []string{"v1.0.0"}, server.Tags("foo/bar/delta")},
+		{"gamma", []string(nil), server.Tags("foo/test-gamma")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("tags create %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the tags are listed and the tags matching a protected
+	// wildcard are reported as protected.
+	output, err := run("list", "--group", "foo", "-r")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data = Data{
+		{"count", 3, strings.Count(output, "v1.0.0")},
+		{"protected", 2, strings.Count(output, "protected")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("tags list %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify a dry run does not protect the tags.
+	_, err = run("protect", "--group", "foo", "-r", "--tag", "v*", "-n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actual := levels("foo/alpha"); actual != nil {
+		t.Errorf("tags protect -n: expected=%v  actual=%v", nil, actual)
+	}
+
+	// Protect the wildcard in all projects which adds the protection
+	// to foo/alpha, replaces the protection of foo/beta, and leaves
+	// foo/bar/delta alone.
+	_, err = run("protect", "--group", "foo", "-r", "--tag", "v*")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data = Data{
+		{"alpha", []string{"v*=maintainer"}, levels("foo/alpha")},
+		{"beta", []string{"v*=maintainer"}, levels("foo/beta")},
+		{"delta", []string{"v*=maintainer"}, levels("foo/bar/delta")},
+		{"epsilon", []string{"v*=maintainer"}, levels("foo/bar/test-epsilon")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("tags protect %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify no tag is deleted after the first one that fails unless
+	// --keep-going is set in which case the failure is summarized at
+	// the end.
+	alpha := server.Project("foo/alpha")
+	failPath := fmt.Sprintf("/projects/%d/repository/tags/", alpha.ID)
+	server.InjectError(http.MethodDelete, failPath, http.StatusForbidden, 1)
+	_, stopErr := run("delete", "--group", "foo", "--tag", "v1.0.0")
+	stopped := server.Tags("foo/beta")
+	server.InjectError(http.MethodDelete, failPath, http.StatusForbidden, 1)
+	output, keepGoingErr := run("delete", "--group", "foo", "--tag", "v1.0.0",
+		"--keep-going")
+	data = Data{
+		{"stop error", true, stopErr != nil},
+		{"stop beta", []string{"v1.0.0"}, stopped},
+		{"keep-going error", "could not delete 1 tag(s)", keepGoingErr},
+		{"keep-going summary", true, strings.Contains(output, "\nFailures:\n")},
+		{"keep-going alpha", []string{"v1.0.0"}, server.Tags("foo/alpha")},
+		{"keep-going beta", []string(nil), server.Tags("foo/beta")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("tags delete %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Delete the tags from the projects directly in foo.
+	_, err = run("delete", "--group", "foo", "--tag", "v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data = Data{
+		{"alpha", []string(nil), server.Tags("foo/alpha")},
+		{"beta", []string(nil), server.Tags("foo/beta")},
+		{"delta", []string{"v1.0.0"}, server.Tags("foo/bar/delta")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("tags delete %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"create", "--group", "foo", "--ref", "main"},
+		{"create", "--group", "foo", "--tag", "v2"},
+		{"delete", "--group", "foo"},
+		{"protect", "--group", "foo"},
+		{"protect", "--group", "foo", "--tag", "v*", "--create-access-level", "owner"},
+	} {
+		_, err = run(args...)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("tags %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
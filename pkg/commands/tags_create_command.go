@@ -0,0 +1,249 @@
+// This file provides the implementation for the "tags create" command
+// which creates a tag in each of the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TagsCreateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TagsCreateOptions are the options needed by this command.
+type TagsCreateOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Message is the message of the tags.  If set, annotated tags are
+	// created instead of lightweight tags.  Defaults to "".
+	Message string `xml:"message"`
+
+	// Ref is the branch or commit from which the tags are created.
+	// Defaults to "".
+	Ref string `xml:"ref"`
+
+	// Tag is the name of the tags.  Defaults to "".
+	Tag string `xml:"tag"`
+}
+
+// Initialize initializes this TagsCreateOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *TagsCreateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --message
+	flags.StringVar(&opts.Message, "message", opts.Message,
+		i18n.T("message which creates annotated tags instead of lightweight tags"))
+
+	// --ref
+	flags.StringVar(&opts.Ref, "ref", opts.Ref,
+		i18n.T("branch or commit from which the tags are created"))
+
+	// --tag
+	flags.StringVar(&opts.Tag, "tag", opts.Tag,
+		i18n.T("name of the tags"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// TagsCreateCommand
+////////////////////////////////////////////////////////////////////////
+
+// TagsCreateCommand implements the "tags create" command which creates
+// a tag in each of the projects in a group.
+type TagsCreateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TagsCreateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TagsCreateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tags create [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create --tag from --ref in each of the selected projects.\n")
+	i18n.Fprintf(out, "    Projects that already have the tag are skipped.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTagsCreateCommand returns a new, initialized TagsCreateCommand
+// instance.
+func NewTagsCreateCommand(
+	name string,
+	opts *TagsCreateOptions,
+	session *Session,
+) *TagsCreateCommand {
+
+	// Create the new command.
+	cmd := &TagsCreateCommand{
+		GitlabCommand: GitlabCommand[TagsCreateOptions]{
+			BasicCommand: BasicCommand[TagsCreateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// CreateTag creates the tag in the project.  If dryRun is true, this
+// function only prints what it would do without actually doing it.
+func CreateTag(
+	ctx context.Context,
+	s gitlab_util.TagCreator, /* was *gitlab.TagsService */
+	p *gitlab.Project,
+	opts *gitlab.CreateTagOptions,
+	dryRun bool,
+) (*gitlab.Tag, error) {
+	tag := &gitlab.Tag{Name: *opts.TagName}
+	logging.Printf("- Creating tag %q ... ", tagName(p, *opts.TagName))
+	if !dryRun {
+		var err error
+		tag, _, err = s.CreateTag(p.ID, opts,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return nil, fmt.Errorf("CreateTag: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return tag, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *TagsCreateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Tag == "" {
+		return result, i18n.Errorf("%w: tag not set", ErrInvalidOption)
+	}
+	if cmd.options.Ref == "" {
+		return result, i18n.Errorf("%w: ref not set", ErrInvalidOption)
+	}
+	opts := &gitlab.CreateTagOptions{
+		TagName: gitlab.Ptr(cmd.options.Tag),
+		Ref:     gitlab.Ptr(cmd.options.Ref),
+	}
+	if cmd.options.Message != "" {
+		opts.Message = gitlab.Ptr(cmd.options.Message)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Create the tag in each project.  A project in which the tag
+	// cannot be created is recorded in the result, and the remaining
+	// projects are still processed.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			name := tagName(p, cmd.options.Tag)
+			existing, err := gitlab_util.FindTag(ctx, cmd.client.Tags, p.ID,
+				cmd.options.Tag)
+			if err != nil {
+				result.Fail(name, p, err)
+				return true, nil
+			}
+			if existing != nil {
+				logging.Printf("- Tag %q already exists.\n", name)
+				return true, nil
+			}
+			hook.OnItemStart(name)
+			tag, err := CreateTag(ctx, cmd.client.Tags, p, opts, cmd.options.DryRun)
+			if err != nil {
+				hook.OnError(name, err)
+				result.Fail(name, p, err)
+				return true, nil
+			}
+			hook.OnItemDone(name)
+			result.Succeed(name, tag)
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not create %d tag(s)", failed)
+	}
+	return result, nil
+}
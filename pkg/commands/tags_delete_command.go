@@ -0,0 +1,295 @@
+// This file provides the implementation for the "tags delete" command
+// which deletes a tag from the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TagsDeleteOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TagsDeleteOptions are the options needed by this command.
+type TagsDeleteOptions struct {
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// Embed the options that control whether the remaining tags are
+	// deleted after a tag cannot be deleted.
+	KeepGoingOptions
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Tag is the name of the tags to delete.  Defaults to "".
+	Tag string `xml:"tag"`
+}
+
+// Initialize initializes this TagsDeleteOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *TagsDeleteOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --tag
+	flags.StringVar(&opts.Tag, "tag", opts.Tag,
+		i18n.T("name of the tags to delete"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// TagsDeleteCommand
+////////////////////////////////////////////////////////////////////////
+
+// TagsDeleteCommand implements the "tags delete" command which deletes
+// a tag from the projects in a group.
+type TagsDeleteCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TagsDeleteOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TagsDeleteCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tags delete [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Delete --tag from the selected projects.  Projects without\n")
+	i18n.Fprintf(out, "    the tag are skipped.  Gitlab refuses to delete protected\n")
+	i18n.Fprintf(out, "    tags unless the token is allowed to create them.  The tags\n")
+	i18n.Fprintf(out, "    are listed and must be confirmed unless the global --yes\n")
+	i18n.Fprintf(out, "    option is passed.  When deleting more tags than\n")
+	i18n.Fprintf(out, "    --confirm-threshold, --i-understand must be passed, and the\n")
+	i18n.Fprintf(out, "    group must be typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Delete Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTagsDeleteCommand returns a new, initialized TagsDeleteCommand
+// instance.
+func NewTagsDeleteCommand(
+	name string,
+	opts *TagsDeleteOptions,
+	session *Session,
+) *TagsDeleteCommand {
+
+	// Create the new command.
+	cmd := &TagsDeleteCommand{
+		GitlabCommand: GitlabCommand[TagsDeleteOptions]{
+			BasicCommand: BasicCommand[TagsDeleteOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProjectTag is a tag of a project.
+type ProjectTag struct {
+
+	// Project is the project that has the tag.
+	Project *gitlab.Project
+
+	// Tag is the tag.
+	Tag *gitlab.Tag
+}
+
+// DeleteTag deletes the tag from the project.  If dryRun is true, this
+// function only prints what it would do without actually doing it.
+func DeleteTag(
+	ctx context.Context,
+	s gitlab_util.TagDeleter, /* was *gitlab.TagsService */
+	p *gitlab.Project,
+	tag string,
+	dryRun bool,
+) error {
+	logging.Printf("- Deleting tag %q ... ", tagName(p, tag))
+	if !dryRun {
+		_, err := s.DeleteTag(p.ID, tag,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("DeleteTag: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// DeleteTags deletes the tags stopping after the current tag if
+// interrupted.  If keepGoing is true, a tag that cannot be deleted is
+// recorded in the result, and the remaining tags are still deleted.
+// Otherwise, no tag is deleted after the first one that fails.  If
+// dryRun is true, this function only prints what it would do without
+// actually doing it.
+func DeleteTags(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.TagDeleter, /* was *gitlab.TagsService */
+	tags []*ProjectTag,
+	keepGoing bool,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, pt := range tags {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("DeleteTags: %w", err)
+		}
+		name := tagName(pt.Project, pt.Tag.Name)
+		hook.OnItemStart(name)
+		err := DeleteTag(ctx, s, pt.Project, pt.Tag.Name, dryRun)
+		if err != nil {
+			hook.OnError(name, err)
+			result.Fail(name, pt.Tag, err)
+			if !keepGoing {
+				return fmt.Errorf("DeleteTags: %w", err)
+			}
+			continue
+		}
+		hook.OnItemDone(name)
+		result.Succeed(name, pt.Tag)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *TagsDeleteCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Tag == "" {
+		return result, i18n.Errorf("%w: tag not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Collect the projects that have the tag.  If --keep-going is
+	// set, a project whose tags cannot be read is recorded in the
+	// result, and the remaining projects are still searched.
+	var tags []*ProjectTag
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			tag, err := gitlab_util.FindTag(ctx, cmd.client.Tags, p.ID,
+				cmd.options.Tag)
+			if err != nil {
+				result.Fail(tagName(p, cmd.options.Tag), p, err)
+				return cmd.options.Continue(err)
+			}
+			if tag != nil {
+				tags = append(tags, &ProjectTag{Project: p, Tag: tag})
+			}
+			return true, nil
+		})
+	if err != nil {
+		err = cmd.options.Finish(os.Stdout, result, err, "could not delete %d tag(s)")
+		return result, err
+	}
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, pt := range tags {
+			names = append(names, tagName(pt.Project, pt.Tag.Name))
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"), names,
+			cmd.options.Group, cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Delete the tags.
+	err = DeleteTags(ctx, result, cmd.client.Tags, tags,
+		cmd.options.KeepGoing, cmd.options.DryRun)
+	err = cmd.options.Finish(os.Stdout, result, err, "could not delete %d tag(s)")
+	return result, err
+}
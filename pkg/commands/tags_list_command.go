@@ -0,0 +1,187 @@
+// This file provides the implementation for the "tags list" command
+// which lists the tags of the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TagsListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TagsListOptions are the options needed by this command.
+type TagsListOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+}
+
+// Initialize initializes this TagsListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *TagsListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// TagsListCommand
+////////////////////////////////////////////////////////////////////////
+
+// TagsListCommand implements the "tags list" command which lists the
+// tags of the projects in a group.
+type TagsListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TagsListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TagsListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tags list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the tags of the selected projects.  Each tag is printed\n")
+	i18n.Fprintf(out, "    as its project, name, commit, and whether it is protected.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTagsListCommand returns a new, initialized TagsListCommand
+// instance.
+func NewTagsListCommand(
+	name string,
+	opts *TagsListOptions,
+	session *Session,
+) *TagsListCommand {
+
+	// Create the new command.
+	cmd := &TagsListCommand{
+		GitlabCommand: GitlabCommand[TagsListOptions]{
+			BasicCommand: BasicCommand[TagsListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *TagsListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print the tags of each project.  For --output json, the tags are
+	// collected and printed together at the end.  A project whose
+	// tags cannot be listed is recorded as failed, and the remaining
+	// projects are still listed.
+	tags := []*gitlab.Tag{}
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			ts, err := gitlab_util.GetAllTags(ctx, cmd.client.Tags, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, t := range ts {
+				if cmd.session.OutputJSON() {
+					tags = append(tags, t)
+				} else {
+					commit := "-"
+					if t.Commit != nil && t.Commit.ShortID != "" {
+						commit = t.Commit.ShortID
+					}
+					protected := "-"
+					if t.Protected {
+						protected = "protected"
+					}
+					fmt.Printf("%-40s  %-20s  %-8s  %s\n", p.PathWithNamespace,
+						t.Name, commit, protected)
+				}
+				result.Succeed(tagName(p, t.Name), t)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the tags as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, tags)
+		if err != nil {
+			return result, err
+		}
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not list the tags of %d project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,266 @@
+// This file provides the implementation for the "tags protect" command
+// which protects a tag or wildcard across the projects in a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TagsProtectOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TagsProtectOptions are the options needed by this command.
+type TagsProtectOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// CreateAccessLevel is the access level allowed to create the
+	// tags which is one of "no-one", "developer", "maintainer", or
+	// "admin".  Defaults to "maintainer".
+	CreateAccessLevel string `xml:"create-access-level"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Tag is the name of the tag or a wildcard (e.g., "v*") to
+	// protect.  Defaults to "".
+	Tag string `xml:"tag"`
+}
+
+// Initialize initializes this TagsProtectOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *TagsProtectOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --create-access-level
+	if opts.CreateAccessLevel == "" {
+		opts.CreateAccessLevel = "maintainer"
+	}
+	flags.StringVar(&opts.CreateAccessLevel, "create-access-level", opts.CreateAccessLevel,
+		i18n.T("access level allowed to create the tags which is \"no-one\", "+
+			"\"developer\", \"maintainer\", or \"admin\""))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --tag
+	flags.StringVar(&opts.Tag, "tag", opts.Tag,
+		i18n.T("name of the tag or wildcard (e.g., \"v*\") to protect"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// TagsProtectCommand
+////////////////////////////////////////////////////////////////////////
+
+// TagsProtectCommand implements the "tags protect" command which
+// protects a tag or wildcard across the projects in a group.
+type TagsProtectCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TagsProtectOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TagsProtectCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tags protect [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Protect --tag in the selected projects so only\n")
+	i18n.Fprintf(out, "    --create-access-level can create matching tags.  Tags that\n")
+	i18n.Fprintf(out, "    are already protected with a different access level are\n")
+	i18n.Fprintf(out, "    unprotected and protected again.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Protect Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTagsProtectCommand returns a new, initialized TagsProtectCommand
+// instance.
+func NewTagsProtectCommand(
+	name string,
+	opts *TagsProtectOptions,
+	session *Session,
+) *TagsProtectCommand {
+
+	// Create the new command.
+	cmd := &TagsProtectCommand{
+		GitlabCommand: GitlabCommand[TagsProtectOptions]{
+			BasicCommand: BasicCommand[TagsProtectOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ProtectTag protects the tag of the project so only level can create
+// matching tags.  Because Gitlab cannot update a protected tag in
+// place, a tag that is already protected with a different access
+// level is unprotected and then protected again.  If dryRun is true,
+// this function only prints what it would without actually doing it.
+func ProtectTag(
+	ctx context.Context,
+	s gitlab_util.ProtectedTagsManager, /* was *gitlab.ProtectedTagsService */
+	p *gitlab.Project,
+	existing *gitlab.ProtectedTag,
+	name string,
+	level gitlab.AccessLevelValue,
+	dryRun bool,
+) error {
+	var err error
+	opts := gitlab.WithContext(gitlab_util.Uninterruptible(ctx))
+
+	// Leave the tag alone if its access level already matches.
+	if existing != nil && tagCreateAccessLevel(existing) == level {
+		logging.Printf("- Tag %q of %q already protected.\n", name, p.PathWithNamespace)
+		return nil
+	}
+
+	// Remove the existing protection.
+	if existing != nil {
+		logging.Printf("- Unprotecting tag %q of %q ... ", name, p.PathWithNamespace)
+		if !dryRun {
+			_, err = s.UnprotectRepositoryTags(p.ID, name, opts)
+			if err != nil {
+				logging.Printf("Failed.\n")
+				return fmt.Errorf("ProtectTag: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+	}
+
+	// Protect the tag.
+	logging.Printf("- Protecting tag %q of %q ... ", name, p.PathWithNamespace)
+	if !dryRun {
+		_, _, err = s.ProtectRepositoryTags(p.ID,
+			&gitlab.ProtectRepositoryTagsOptions{
+				Name:              gitlab.Ptr(name),
+				CreateAccessLevel: gitlab.Ptr(level),
+			}, opts)
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("ProtectTag: %w", gitlab_util.ClassifyError(err))
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *TagsProtectCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Tag == "" {
+		return result, i18n.Errorf("%w: tag not set", ErrInvalidOption)
+	}
+	level, err := ParseBranchAccessLevel(cmd.options.CreateAccessLevel)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Protect the tag in each project.  A project that fails is
+	// recorded in the result, and the remaining projects are still
+	// processed.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	err = cmd.options.ForEachProject(ctx, cmd.client.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			hook.OnItemStart(p.PathWithNamespace)
+			existing, err := FindProtectedTag(
+				ctx, cmd.client.ProtectedTags, p, cmd.options.Tag)
+			if err == nil {
+				err = ProtectTag(ctx, cmd.client.ProtectedTags,
+					p, existing, cmd.options.Tag, level, cmd.options.DryRun)
+			}
+			if err != nil {
+				hook.OnError(p.PathWithNamespace, err)
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			hook.OnItemDone(p.PathWithNamespace)
+			result.Succeed(p.PathWithNamespace, p)
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not protect tag in %d project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,577 @@
+// This file provides the options and functions shared by the "tokens"
+// subcommands which manage the deploy tokens and access tokens either
+// of the projects in a group or of the group itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+// TokenTypes are the values accepted by --type.
+var TokenTypes = []string{"access", "deploy"}
+
+// TokenSelectorOptions select the groups or projects whose tokens are
+// operated on and the type of the tokens.  They are embedded in the
+// options of the "tokens" subcommands so the options have the same
+// names and meaning everywhere.
+type TokenSelectorOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// GroupLevel controls whether the tokens of --group itself are
+	// used instead of the tokens of the selected projects.  Defaults
+	// to false.
+	GroupLevel bool `xml:"group-level"`
+
+	// Type is the type of the tokens which is either "access" or
+	// "deploy".  Defaults to "" which selects both types.
+	Type string `xml:"type"`
+}
+
+// Initialize initializes this TokenSelectorOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *TokenSelectorOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --group-level
+	flags.BoolVar(&opts.GroupLevel, "group-level", opts.GroupLevel,
+		i18n.T("whether to use the tokens of --group itself instead "+
+			"of the tokens of its projects"))
+
+	// --type
+	flags.StringVar(&opts.Type, "type", opts.Type,
+		i18n.T("type of the tokens which is either \"access\" or \"deploy\""))
+}
+
+// Validate returns an error if the options are invalid.
+func (opts *TokenSelectorOptions) Validate() error {
+	err := opts.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return err
+	}
+	if opts.Type != "" && !slices.Contains(TokenTypes, opts.Type) {
+		return i18n.Errorf("%w: invalid type: %q", ErrInvalidOption, opts.Type)
+	}
+	return nil
+}
+
+// TokensServices are the Gitlab services needed by the "tokens"
+// subcommands.
+type TokensServices struct {
+	Groups              gitlab_util.ProjectsInGroupLister      /* was *gitlab.GroupsService */
+	DeployTokens        gitlab_util.DeployTokensManager        /* was *gitlab.DeployTokensService */
+	GroupAccessTokens   gitlab_util.GroupAccessTokensManager   /* was *gitlab.GroupAccessTokensService */
+	ProjectAccessTokens gitlab_util.ProjectAccessTokensManager /* was *gitlab.ProjectAccessTokensService */
+}
+
+// TokenRecord describes a deploy token or access token without its
+// secret so tokens can be listed without leaking them.
+type TokenRecord struct {
+
+	// Owner is the full path of the group or project having the
+	// token.
+	Owner string `json:"owner"`
+
+	// GroupLevel is whether the owner is a group instead of a
+	// project.
+	GroupLevel bool `json:"-"`
+
+	// Type is either "access" or "deploy".
+	Type string `json:"type"`
+
+	// ID is the ID of the token.
+	ID int `json:"id"`
+
+	// Name is the name of the token.
+	Name string `json:"name"`
+
+	// Username is the username of a deploy token.
+	Username string `json:"username,omitempty"`
+
+	// Scopes are the scopes of the token.
+	Scopes []string `json:"scopes"`
+
+	// AccessLevel is the access level of an access token.
+	AccessLevel gitlab.AccessLevelValue `json:"access_level,omitempty"`
+
+	// ExpiresAt is the time at which the token expires or nil if it
+	// does not expire.
+	ExpiresAt *time.Time `json:"expires_at"`
+
+	// Active is whether the token is neither revoked nor expired.
+	Active bool `json:"active"`
+
+	// Revoked is whether the token has been revoked.
+	Revoked bool `json:"revoked"`
+}
+
+// deployTokenRecord returns the record of the deploy token of the
+// group or project.
+func deployTokenRecord(owner string, groupLevel bool, t *gitlab.DeployToken) *TokenRecord {
+	return &TokenRecord{
+		Owner:      owner,
+		GroupLevel: groupLevel,
+		Type:       "deploy",
+		ID:         t.ID,
+		Name:       t.Name,
+		Username:   t.Username,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		Active:     !t.Revoked && !t.Expired,
+		Revoked:    t.Revoked,
+	}
+}
+
+// accessTokenRecord returns the record of the access token of the
+// group or project.
+func accessTokenRecord(
+	owner string,
+	groupLevel bool,
+	id int,
+	name string,
+	scopes []string,
+	level gitlab.AccessLevelValue,
+	expiresAt *gitlab.ISOTime,
+	active bool,
+	revoked bool,
+) *TokenRecord {
+	var expires *time.Time
+	if expiresAt != nil {
+		expires = gitlab.Ptr(time.Time(*expiresAt))
+	}
+	return &TokenRecord{
+		Owner:       owner,
+		GroupLevel:  groupLevel,
+		Type:        "access",
+		ID:          id,
+		Name:        name,
+		Scopes:      scopes,
+		AccessLevel: level,
+		ExpiresAt:   expires,
+		Active:      active,
+		Revoked:     revoked,
+	}
+}
+
+// ListTokens returns the tokens of the type (or of both types if
+// tokenType is "") of the group or project having the full path.
+func ListTokens(
+	ctx context.Context,
+	s TokensServices,
+	groupLevel bool,
+	owner string,
+	tokenType string,
+) ([]*TokenRecord, error) {
+	var result []*TokenRecord
+
+	// Access tokens.
+	if tokenType == "" || tokenType == "access" {
+		if groupLevel {
+			ts, err := gitlab_util.GetAllGroupAccessTokens(ctx, s.GroupAccessTokens, owner)
+			if err != nil {
+				return nil, fmt.Errorf("ListTokens: %w", err)
+			}
+			for _, t := range ts {
+				result = append(result, accessTokenRecord(owner, true,
+					t.ID, t.Name, t.Scopes, t.AccessLevel, t.ExpiresAt, t.Active, t.Revoked))
+			}
+		} else {
+			ts, err := gitlab_util.GetAllProjectAccessTokens(ctx, s.ProjectAccessTokens, owner)
+			if err != nil {
+				return nil, fmt.Errorf("ListTokens: %w", err)
+			}
+			for _, t := range ts {
+				result = append(result, accessTokenRecord(owner, false,
+					t.ID, t.Name, t.Scopes, t.AccessLevel, t.ExpiresAt, t.Active, t.Revoked))
+			}
+		}
+	}
+
+	// Deploy tokens.
+	if tokenType == "" || tokenType == "deploy" {
+		var ts []*gitlab.DeployToken
+		var err error
+		if groupLevel {
+			ts, err = gitlab_util.GetAllGroupDeployTokens(ctx, s.DeployTokens, owner)
+		} else {
+			ts, err = gitlab_util.GetAllProjectDeployTokens(ctx, s.DeployTokens, owner)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ListTokens: %w", err)
+		}
+		for _, t := range ts {
+			result = append(result, deployTokenRecord(owner, groupLevel, t))
+		}
+	}
+
+	return result, nil
+}
+
+// ForEachTokenOwner calls f once for the group or for each project
+// selected by the selector with the full path of the group or
+// project.  The function f must return true and no error to indicate
+// that it wants to continue being called with the remaining projects.
+func ForEachTokenOwner(
+	ctx context.Context,
+	selector *TokenSelectorOptions,
+	s TokensServices,
+	f func(owner string) (bool, error),
+) error {
+	if selector.GroupLevel {
+		_, err := f(selector.Group)
+		return err
+	}
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			return f(p.PathWithNamespace)
+		})
+	if err != nil {
+		return fmt.Errorf("ForEachTokenOwner: %w", err)
+	}
+	return nil
+}
+
+// ForEachToken calls f once for each token of the type selected by
+// the selector of the groups or projects selected by the selector.  If
+// the tokens of a group or project cannot be listed, the failure is
+// recorded in the result, and the remaining projects are still
+// processed.  The function f must return true and no error to indicate
+// that it wants to continue being called with the remaining tokens.
+func ForEachToken(
+	ctx context.Context,
+	result *Result,
+	selector *TokenSelectorOptions,
+	s TokensServices,
+	f func(t *TokenRecord) (bool, error),
+) error {
+	err := ForEachTokenOwner(ctx, selector, s,
+		func(owner string) (bool, error) {
+			ts, err := ListTokens(ctx, s, selector.GroupLevel, owner, selector.Type)
+			if err != nil {
+				result.Fail(owner, owner, err)
+				return true, nil
+			}
+			for _, t := range ts {
+				ok, err := f(t)
+				if !ok || err != nil {
+					return ok, err
+				}
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("ForEachToken: %w", err)
+	}
+	return nil
+}
+
+// TokenSpec holds the settings of a new token.
+type TokenSpec struct {
+
+	// Name is the name of the token.
+	Name string
+
+	// Scopes are the scopes of the token.
+	Scopes []string
+
+	// AccessLevel is the access level of an access token.
+	AccessLevel gitlab.AccessLevelValue
+
+	// ExpiresAt is the date on which the token expires or the zero
+	// time if it does not expire.
+	ExpiresAt time.Time
+
+	// Username is the username of a deploy token or "" to let Gitlab
+	// choose it.
+	Username string
+}
+
+// CreateToken creates the token of the type in the group or project
+// having the full path and returns the new token and its secret.
+func CreateToken(
+	ctx context.Context,
+	s TokensServices,
+	groupLevel bool,
+	owner string,
+	tokenType string,
+	spec *TokenSpec,
+) (*TokenRecord, string, error) {
+	ctx = gitlab_util.Uninterruptible(ctx)
+	var expiresAt *time.Time
+	if !spec.ExpiresAt.IsZero() {
+		expiresAt = gitlab.Ptr(spec.ExpiresAt)
+	}
+	var username *string
+	if spec.Username != "" {
+		username = gitlab.Ptr(spec.Username)
+	}
+
+	switch {
+	case tokenType == "deploy" && groupLevel:
+		t, _, err := s.DeployTokens.CreateGroupDeployToken(owner,
+			&gitlab.CreateGroupDeployTokenOptions{
+				Name:      gitlab.Ptr(spec.Name),
+				ExpiresAt: expiresAt,
+				Username:  username,
+				Scopes:    gitlab.Ptr(spec.Scopes),
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, "", fmt.Errorf("CreateToken: %w", gitlab_util.ClassifyError(err))
+		}
+		return deployTokenRecord(owner, true, t), t.Token, nil
+
+	case tokenType == "deploy":
+		t, _, err := s.DeployTokens.CreateProjectDeployToken(owner,
+			&gitlab.CreateProjectDeployTokenOptions{
+				Name:      gitlab.Ptr(spec.Name),
+				ExpiresAt: expiresAt,
+				Username:  username,
+				Scopes:    gitlab.Ptr(spec.Scopes),
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, "", fmt.Errorf("CreateToken: %w", gitlab_util.ClassifyError(err))
+		}
+		return deployTokenRecord(owner, false, t), t.Token, nil
+
+	case groupLevel:
+		t, _, err := s.GroupAccessTokens.CreateGroupAccessToken(owner,
+			&gitlab.CreateGroupAccessTokenOptions{
+				Name:        gitlab.Ptr(spec.Name),
+				Scopes:      gitlab.Ptr(spec.Scopes),
+				AccessLevel: gitlab.Ptr(spec.AccessLevel),
+				ExpiresAt:   isoTimePtr(expiresAt),
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, "", fmt.Errorf("CreateToken: %w", gitlab_util.ClassifyError(err))
+		}
+		return accessTokenRecord(owner, true, t.ID, t.Name, t.Scopes,
+			t.AccessLevel, t.ExpiresAt, t.Active, t.Revoked), t.Token, nil
+
+	default:
+		t, _, err := s.ProjectAccessTokens.CreateProjectAccessToken(owner,
+			&gitlab.CreateProjectAccessTokenOptions{
+				Name:        gitlab.Ptr(spec.Name),
+				Scopes:      gitlab.Ptr(spec.Scopes),
+				AccessLevel: gitlab.Ptr(spec.AccessLevel),
+				ExpiresAt:   isoTimePtr(expiresAt),
+			},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, "", fmt.Errorf("CreateToken: %w", gitlab_util.ClassifyError(err))
+		}
+		return accessTokenRecord(owner, false, t.ID, t.Name, t.Scopes,
+			t.AccessLevel, t.ExpiresAt, t.Active, t.Revoked), t.Token, nil
+	}
+}
+
+// isoTimePtr returns the time as a *gitlab.ISOTime or nil if t is nil.
+func isoTimePtr(t *time.Time) *gitlab.ISOTime {
+	if t == nil {
+		return nil
+	}
+	return gitlab.Ptr(gitlab.ISOTime(*t))
+}
+
+// RotateToken replaces the token with a new token having the same
+// settings that expires at the time (or, if nil, at the time chosen
+// by Gitlab for access tokens and at the time the old token expires
+// for deploy tokens) and returns the new token and its secret.
+// Gitlab rotates access tokens itself, but deploy tokens cannot be
+// rotated, so a new deploy token is created before the old one is
+// deleted.
+func RotateToken(
+	ctx context.Context,
+	s TokensServices,
+	t *TokenRecord,
+	expiresAt *time.Time,
+) (*TokenRecord, string, error) {
+	ctx = gitlab_util.Uninterruptible(ctx)
+
+	// Access tokens.
+	if t.Type == "access" {
+		if t.GroupLevel {
+			nt, _, err := s.GroupAccessTokens.RotateGroupAccessToken(t.Owner, t.ID,
+				&gitlab.RotateGroupAccessTokenOptions{ExpiresAt: isoTimePtr(expiresAt)},
+				gitlab.WithContext(ctx))
+			if err != nil {
+				return nil, "", fmt.Errorf("RotateToken: %w", gitlab_util.ClassifyError(err))
+			}
+			return accessTokenRecord(t.Owner, true, nt.ID, nt.Name, nt.Scopes,
+				nt.AccessLevel, nt.ExpiresAt, nt.Active, nt.Revoked), nt.Token, nil
+		}
+		nt, _, err := s.ProjectAccessTokens.RotateProjectAccessToken(t.Owner, t.ID,
+			&gitlab.RotateProjectAccessTokenOptions{ExpiresAt: isoTimePtr(expiresAt)},
+			gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, "", fmt.Errorf("RotateToken: %w", gitlab_util.ClassifyError(err))
+		}
+		return accessTokenRecord(t.Owner, false, nt.ID, nt.Name, nt.Scopes,
+			nt.AccessLevel, nt.ExpiresAt, nt.Active, nt.Revoked), nt.Token, nil
+	}
+
+	// Deploy tokens.  Usernames chosen by Gitlab contain the ID of
+	// the token, so only usernames chosen by the user are kept.
+	spec := &TokenSpec{Name: t.Name, Scopes: t.Scopes}
+	if !strings.HasPrefix(t.Username, "gitlab+deploy-token-") {
+		spec.Username = t.Username
+	}
+	switch {
+	case expiresAt != nil:
+		spec.ExpiresAt = *expiresAt
+	case t.ExpiresAt != nil:
+		spec.ExpiresAt = *t.ExpiresAt
+	}
+	nt, secret, err := CreateToken(ctx, s, t.GroupLevel, t.Owner, "deploy", spec)
+	if err != nil {
+		return nil, "", fmt.Errorf("RotateToken: %w", err)
+	}
+	err = RevokeToken(ctx, s, t)
+	if err != nil {
+		return nt, secret, fmt.Errorf("RotateToken: %w", err)
+	}
+	return nt, secret, nil
+}
+
+// RevokeToken revokes the access token or deletes the deploy token.
+func RevokeToken(ctx context.Context, s TokensServices, t *TokenRecord) error {
+	var err error
+	opt := gitlab.WithContext(gitlab_util.Uninterruptible(ctx))
+	switch {
+	case t.Type == "deploy" && t.GroupLevel:
+		_, err = s.DeployTokens.DeleteGroupDeployToken(t.Owner, t.ID, opt)
+	case t.Type == "deploy":
+		_, err = s.DeployTokens.DeleteProjectDeployToken(t.Owner, t.ID, opt)
+	case t.GroupLevel:
+		_, err = s.GroupAccessTokens.RevokeGroupAccessToken(t.Owner, t.ID, opt)
+	default:
+		_, err = s.ProjectAccessTokens.RevokeProjectAccessToken(t.Owner, t.ID, opt)
+	}
+	if err != nil {
+		return fmt.Errorf("RevokeToken: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// ChangeToken prints the progress message for the verb (e.g.,
+// "Revoking") and the token with the name in the group or project
+// having the full path, calls change unless dryRun is true, and
+// records the outcome in the result.  A failed change is only
+// recorded so the remaining tokens are still changed.
+func ChangeToken(
+	ctx context.Context,
+	result *Result,
+	verb string,
+	tokenType string,
+	owner string,
+	name string,
+	change func() error,
+	dryRun bool,
+) {
+	item := owner + ":" + name
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(item)
+	logging.Printf("- %s %s token %q in %q ... ", verb, tokenType, name, owner)
+	if !dryRun {
+		err := change()
+		if err != nil {
+			logging.Printf("Failed.\n")
+			hook.OnError(item, err)
+			result.Fail(item, owner, err)
+			return
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(item)
+	result.Succeed(item, owner)
+}
+
+// TokenSecrets receives the secrets of new tokens.  Gitlab only
+// returns the secret of a token when it is created, so each secret is
+// either written to a file as soon as it is received or printed once
+// by Close().  Each secret is written as a line with the full path of
+// the group or project, the name of the token, and the secret
+// separated by tabs.
+type TokenSecrets struct {
+
+	// file is the file to which the secrets are written or nil if
+	// the secrets are printed by Close().
+	file *os.File
+
+	// lines are the lines printed by Close().
+	lines []string
+}
+
+// OpenTokenSecrets returns a new TokenSecrets instance that writes the
+// secrets to the file or, if fileName is "", prints them.  The file is
+// created with permissions that only allow the owner to read it.
+func OpenTokenSecrets(fileName string) (*TokenSecrets, error) {
+	result := &TokenSecrets{}
+	if fileName != "" {
+		f, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("OpenTokenSecrets: %w", err)
+		}
+
+		// OpenFile() does not change the permissions of a file that
+		// already exists.
+		err = f.Chmod(0o600)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("OpenTokenSecrets: %w", err)
+		}
+		result.file = f
+	}
+	return result, nil
+}
+
+// Add writes or remembers the secret of the token.
+func (ts *TokenSecrets) Add(t *TokenRecord, secret string) error {
+	line := fmt.Sprintf("%s\t%s\t%s\n", t.Owner, t.Name, secret)
+	if ts.file == nil {
+		ts.lines = append(ts.lines, line)
+		return nil
+	}
+	_, err := ts.file.WriteString(line)
+	if err != nil {
+		return fmt.Errorf("TokenSecrets.Add: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file or prints the secrets.
+func (ts *TokenSecrets) Close() error {
+	if ts.file != nil {
+		err := ts.file.Close()
+		if err != nil {
+			return fmt.Errorf("TokenSecrets.Close: %w", err)
+		}
+		return nil
+	}
+	if len(ts.lines) > 0 {
+		fmt.Printf("\n")
+		i18n.Printf("Save the following token(s) now because they are never shown again:\n")
+		for _, line := range ts.lines {
+			fmt.Print(line)
+		}
+	}
+	return nil
+}
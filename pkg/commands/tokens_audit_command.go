@@ -0,0 +1,260 @@
+// This file provides the implementation for the "tokens audit" command
+// which reports the deploy tokens and access tokens of the projects in
+// a group or of the group itself that expire within a number of days
+// or have already expired.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TokensAuditOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TokensAuditOptions are the options needed by this command.
+type TokensAuditOptions struct {
+
+	// Embed the options that select the groups or projects.
+	TokenSelectorOptions
+
+	// Days is the number of days within which a token must expire to
+	// be reported.  Tokens that have already expired are always
+	// reported.  Defaults to 30.
+	Days uint64 `xml:"days"`
+}
+
+// Initialize initializes this TokensAuditOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *TokensAuditOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr, --type
+	opts.TokenSelectorOptions.Initialize(flags)
+
+	// --days
+	if opts.Days == 0 {
+		opts.Days = 30
+	}
+	flags.Uint64Var(&opts.Days, "days", opts.Days,
+		i18n.T("number of days within which a token must expire to be reported"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// TokensAuditCommand
+////////////////////////////////////////////////////////////////////////
+
+// TokensAuditCommand implements the "tokens audit" command which
+// reports the tokens that expire soon or have already expired.
+type TokensAuditCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TokensAuditOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TokensAuditCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tokens audit [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the deploy tokens and access tokens of the selected\n")
+	i18n.Fprintf(out, "    projects or, with --group-level, of --group itself that\n")
+	i18n.Fprintf(out, "    expire within --days days or have already expired.  Revoked\n")
+	i18n.Fprintf(out, "    tokens and tokens that never expire are not reported.  The\n")
+	i18n.Fprintf(out, "    tokens are sorted by their expiry dates so the tokens that\n")
+	i18n.Fprintf(out, "    need attention first are printed first.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Audit Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTokensAuditCommand returns a new, initialized TokensAuditCommand
+// instance.
+func NewTokensAuditCommand(
+	name string,
+	opts *TokensAuditOptions,
+	session *Session,
+) *TokensAuditCommand {
+
+	// Create the new command.
+	cmd := &TokensAuditCommand{
+		GitlabCommand: GitlabCommand[TokensAuditOptions]{
+			BasicCommand: BasicCommand[TokensAuditOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ExpiringToken is a token reported by the "tokens audit" command.
+type ExpiringToken struct {
+
+	// Embed the token.
+	*TokenRecord
+
+	// DaysLeft is the number of days until the token expires which is
+	// zero or negative if it has already expired.
+	DaysLeft int `json:"days_left"`
+
+	// Expired is whether the token has already expired.
+	Expired bool `json:"expired"`
+}
+
+// FindExpiringTokens returns the tokens that are not revoked and that
+// expire before the cutoff or have already expired at the time now.
+// The tokens are sorted by their expiry dates.
+func FindExpiringTokens(
+	ctx context.Context,
+	result *Result,
+	selector *TokenSelectorOptions,
+	s TokensServices,
+	now time.Time,
+	cutoff time.Time,
+) ([]*ExpiringToken, error) {
+	expiring := []*ExpiringToken{}
+
+	// Gitlab stores the expiry date without a time, so the days left
+	// are counted from the start of today.
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	err := ForEachToken(ctx, result, selector, s,
+		func(t *TokenRecord) (bool, error) {
+			if t.Revoked || t.ExpiresAt == nil || !t.ExpiresAt.Before(cutoff) {
+				return true, nil
+			}
+			expiring = append(expiring, &ExpiringToken{
+				TokenRecord: t,
+				DaysLeft:    int(t.ExpiresAt.Sub(today).Hours() / 24),
+				Expired:     !t.Active || !t.ExpiresAt.After(now),
+			})
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindExpiringTokens: %w", err)
+	}
+	slices.SortStableFunc(expiring, func(a, b *ExpiringToken) int {
+		return a.ExpiresAt.Compare(*b.ExpiresAt)
+	})
+	return expiring, nil
+}
+
+// PrintExpiringTokens prints the report of the expiring tokens to the
+// output writer.
+func PrintExpiringTokens(out io.Writer, expiring []*ExpiringToken) {
+	row := func(expires, daysLeft, state, tokenType, owner, name string) {
+		fmt.Fprintf(out, "%-10s  %9s  %-8s  %-6s  %-40s  %s\n",
+			expires, daysLeft, state, tokenType, owner, name)
+	}
+	row(i18n.T("EXPIRES"), i18n.T("DAYS LEFT"), i18n.T("STATE"),
+		i18n.T("TYPE"), i18n.T("OWNER"), i18n.T("NAME"))
+	for _, t := range expiring {
+		state := i18n.T("expiring")
+		if t.Expired {
+			state = i18n.T("expired")
+		}
+		row(tokenExpiry(t.TokenRecord), fmt.Sprint(t.DaysLeft), state,
+			t.Type, t.Owner, t.Name)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *TokensAuditCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.TokenSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Find the expiring tokens.
+	now := time.Now()
+	expiring, err := FindExpiringTokens(
+		ctx, result, &cmd.options.TokenSelectorOptions,
+		TokensServices{
+			Groups:              cmd.client.Groups,
+			DeployTokens:        cmd.client.DeployTokens,
+			GroupAccessTokens:   cmd.client.GroupAccessTokens,
+			ProjectAccessTokens: cmd.client.ProjectAccessTokens,
+		},
+		now, now.AddDate(0, 0, int(cmd.options.Days)))
+	if err != nil {
+		return result, err
+	}
+	for _, t := range expiring {
+		result.Succeed(t.Owner+":"+t.Name, t)
+	}
+
+	// Print the report.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, expiring)
+		if err != nil {
+			return result, err
+		}
+	} else {
+		PrintExpiringTokens(os.Stdout, expiring)
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not audit the tokens of %d group(s) or project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokensAuditIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	server.AddAccessToken("project", "foo/alpha", "ci", today.AddDate(0, 0, 5))
+	server.AddAccessToken("project", "foo/alpha", "forever", time.Time{})
+	server.AddDeployToken("project", "foo/beta", "registry", today.AddDate(0, 0, -3))
+	server.AddDeployToken("project", "foo/bar/delta", "later", today.AddDate(0, 0, 60))
+	server.AddAccessToken("group", "foo", "bot", today.AddDate(0, 0, 10))
+
+	// run runs the "tokens audit" command with the arguments and
+	// returns the reported tokens as their owner, name, days left, and
+	// state.
+	run := func(args ...string) ([]string, error) {
+		var err error
+		session := NewSessionWithClient(server.Client(t))
+		cmd := NewTokensCommand("tokens", &TokensOptions{}, session)
+		out, _, err := runCommand(t, cmd, append([]string{"audit"}, args...))
+		var rows []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			f := strings.Fields(line)
+			rows = append(rows, strings.Join([]string{f[4], f[5], f[1], f[2]}, ":"))
+		}
+		return rows, err
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	var data Data
+	check := func(name string, expected any, actual any) {
+		data = append(data, Data{{name, expected, actual}}...)
+	}
+
+	// The default reports the tokens expiring within 30 days sorted by
+	// their expiry dates.
+	rows, err := run("--group", "foo", "--recursive")
+	check("default error", nil, err)
+	check("default", []string{"foo/beta:registry:-3:expired", "foo/alpha:ci:5:expiring"}, rows)
+
+	// More days report more tokens.
+	rows, err = run("--group", "foo", "--recursive", "--days", "90", "--type", "deploy")
+	check("days error", nil, err)
+	check("days", []string{"foo/beta:registry:-3:expired", "foo/bar/delta:later:60:expiring"}, rows)
+
+	// Zero days only reports the expired tokens.
+	rows, _ = run("--group", "foo", "--recursive", "--days", "0")
+	check("expired only", []string{"foo/beta:registry:-3:expired"}, rows)
+
+	// The tokens of the group itself.
+	rows, _ = run("--group", "foo", "--group-level")
+	check("group level", []string{"foo:bot:10:expiring"}, rows)
+
+	// Revoked tokens are not reported.
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Yes = true
+	cmd := NewTokensCommand("tokens", &TokensOptions{}, session)
+	captureStdout(t, func() {
+		_, err = cmd.Run(context.Background(),
+			[]string{"revoke", "--group", "foo", "--expr", "alpha", "--name", "ci"})
+	})
+	check("revoke error", nil, err)
+	rows, _ = run("--group", "foo", "--expr", "alpha")
+	check("revoked", []string(nil), rows)
+
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("tokens audit %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
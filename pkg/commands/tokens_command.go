@@ -0,0 +1,164 @@
+// This file provides the implementation for the "tokens" command
+// which provides deploy token and access token related subcommands.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      TokensCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TokensOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TokensOptions are the options needed by this command.
+type TokensOptions struct {
+
+	// Options for the "tokens audit" command.
+	TokensAuditOpts TokensAuditOptions `xml:"audit-options"`
+
+	// Options for the "tokens create" command.
+	TokensCreateOpts TokensCreateOptions `xml:"create-options"`
+
+	// Options for the "tokens list" command.
+	TokensListOpts TokensListOptions `xml:"list-options"`
+
+	// Options for the "tokens revoke" command.
+	TokensRevokeOpts TokensRevokeOptions `xml:"revoke-options"`
+
+	// Options for the "tokens rotate" command.
+	TokensRotateOpts TokensRotateOptions `xml:"rotate-options"`
+}
+
+// Initialize initializes this TokensOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *TokensOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// TokensCommand
+////////////////////////////////////////////////////////////////////////
+
+// TokensCommand provides subcommands for administering the deploy
+// tokens and access tokens of Gitlab groups and projects.
+type TokensCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[TokensOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *TokensCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tokens [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for administering deploy tokens and access tokens of\n")
+	i18n.Fprintf(out, "    Gitlab groups and projects.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *TokensCommand) addSubcmds(session *Session) {
+	cmd.subcmds["audit"] = NewTokensAuditCommand(
+		"audit", &cmd.options.TokensAuditOpts, session)
+	cmd.subcmds["create"] = NewTokensCreateCommand(
+		"create", &cmd.options.TokensCreateOpts, session)
+	cmd.subcmds["list"] = NewTokensListCommand(
+		"list", &cmd.options.TokensListOpts, session)
+	cmd.subcmds["revoke"] = NewTokensRevokeCommand(
+		"revoke", &cmd.options.TokensRevokeOpts, session)
+	cmd.subcmds["rotate"] = NewTokensRotateCommand(
+		"rotate", &cmd.options.TokensRotateOpts, session)
+}
+
+// NewTokensCommand returns a new, initialized TokensCommand
+// instance having the specified name.
+func NewTokensCommand(
+	name string,
+	opts *TokensOptions,
+	session *Session,
+) *TokensCommand {
+
+	// Create the new command.
+	cmd := &TokensCommand{
+		ParentCommand: ParentCommand[TokensOptions]{
+			BasicCommand: BasicCommand[TokensOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *TokensCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokensIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddAccessToken("project", "foo/alpha", "ci", time.Time{})
+	server.AddDeployToken("project", "foo/beta", "registry", time.Time{})
+	secretFileName := filepath.Join(t.TempDir(), "secrets.txt")
+
+	// run runs the "tokens" subcommand with the arguments and returns
+	// its output.
+	run := func(args ...string) (string, error) {
+		var err error
+		session := NewSessionWithClient(server.Client(t))
+		session.globalOpts.Yes = true
+		cmd := NewTokensCommand("tokens", &TokensOptions{}, session)
+		out, _, err := runCommand(t, cmd, args)
+		return out, err
+	}
+	tokens := func(kind string, fullPath string) []string {
+		return server.ActiveTokens(kind, fullPath)
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	var data Data
+	check := func(name string, expected any, actual any) {
+		data = append(data, Data{{name, expected, actual}}...)
+	}
+	mustRun := func(args ...string) string {
+		out, err := run(args...)
+		if err != nil {
+			t.Fatalf("tokens %v: unexpected error: %v", args, err)
+		}
+		return out
+	}
+
+	// List the tokens.
+	out := mustRun("list", "--group", "foo")
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	check("list rows", 2, len(lines))
+	check("list alpha", []string{"foo/alpha", "access"}, strings.Fields(lines[0])[:2])
+
+	// Create deploy tokens writing the secrets to the file.
+	mustRun("create", "--group", "foo", "--expr", "alpha|beta", "--type", "deploy",
+		"--name", "pull", "--scopes", "read_repository", "--expires-at", "2030-01-31",
+		"--secret-file", secretFileName)
+	info, err := os.Stat(secretFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secrets, _ := os.ReadFile(secretFileName)
+	check("secret file mode", os.FileMode(0o600), info.Mode().Perm())
+	check("secret file lines", 2, strings.Count(string(secrets), "\n"))
+	check("secret file content", true, strings.Contains(string(secrets), "foo/alpha\tpull\tgldt-secret-"))
+	check("create alpha", []string{"access:ci:", "deploy:pull:2030-01-31"}, tokens("project", "foo/alpha"))
+
+	// Creating the token again skips the projects that have it.
+	mustRun("create", "--group", "foo", "--expr", "beta", "--type", "deploy",
+		"--name", "pull", "--scopes", "read_repository")
+	check("create again", []string{"deploy:registry:", "deploy:pull:2030-01-31"}, tokens("project", "foo/beta"))
+
+	// Create a group access token printing the secret.
+	out = mustRun("create", "--group", "foo", "--group-level", "--type", "access",
+		"--name", "bot", "--scopes", "read_api", "--expires-at", "2030-06-30")
+	check("create group printed", true, strings.Contains(out, "foo\tbot\tglpat-secret-"))
+	check("create group", []string{"access:bot:2030-06-30"}, tokens("group", "foo"))
+
+	// Rotate an access token and a deploy token.
+	out = mustRun("rotate", "--group", "foo", "--name", "ci", "--expires-at", "2031-01-01")
+	check("rotate access printed", true, strings.Contains(out, "foo/alpha\tci\tglpat-secret-"))
+	check("rotate access", []string{"access:ci:2031-01-01", "deploy:pull:2030-01-31"},
+		tokens("project", "foo/alpha"))
+	out = mustRun("rotate", "--group", "foo", "--expr", "beta", "--type", "deploy", "--name", "pull")
+	check("rotate deploy printed", true, strings.Contains(out, "foo/beta\tpull\tgldt-secret-"))
+	check("rotate deploy", []string{"deploy:registry:", "deploy:pull:2030-01-31"}, tokens("project", "foo/beta"))
+
+	// Revoke the deploy tokens.
+	mustRun("revoke", "--group", "foo", "--name", "pull", "-n")
+	check("revoke dry-run", []string{"deploy:registry:", "deploy:pull:2030-01-31"}, tokens("project", "foo/beta"))
+	mustRun("revoke", "--group", "foo", "--name", "pull")
+	check("revoke", []string{"deploy:registry:"}, tokens("project", "foo/beta"))
+	check("revoke other", []string{"access:ci:2031-01-01"}, tokens("project", "foo/alpha"))
+
+	// Verify the invalid options.
+	_, err = run("create", "--group", "foo", "--name", "x", "--scopes", "api")
+	check("create type not set", true, errors.Is(err, ErrInvalidOption))
+	_, err = run("list", "--group", "foo", "--type", "ssh")
+	check("list invalid type", true, errors.Is(err, ErrInvalidOption))
+
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("tokens %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+// This file provides the implementation for the "tokens create"
+// command which creates a deploy token or access token in the
+// projects in a group or in the group itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TokensCreateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TokensCreateOptions are the options needed by this command.
+type TokensCreateOptions struct {
+
+	// Embed the options that select the groups or projects.
+	TokenSelectorOptions
+
+	// AccessLevel is the access level of an access token which is
+	// one of "guest", "reporter", "developer", "maintainer", or
+	// "owner".  It is ignored for deploy tokens.  Defaults to
+	// "maintainer".
+	AccessLevel string `xml:"access-level"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ExpiresAt is the date on which the token expires.  Defaults to
+	// not set which means deploy tokens do not expire and Gitlab
+	// chooses the expiry date of access tokens.
+	ExpiresAt date_arg.DateArg `xml:"expires-at"`
+
+	// Name is the name of the token.  Defaults to "".
+	Name string `xml:"name"`
+
+	// Scopes are the scopes of the token (e.g., "read_repository").
+	// Defaults to no scopes.
+	Scopes string_slice.StringSlice `xml:"scopes>scope"`
+
+	// SecretFileName is the name of the file to which the secrets of
+	// the new tokens are written.  The file is only readable by its
+	// owner.  Defaults to "" which means the secrets are printed
+	// once instead.
+	SecretFileName string `xml:"secret-file"`
+
+	// Username is the username of a deploy token.  It is ignored for
+	// access tokens.  Defaults to "" which lets Gitlab choose it.
+	Username string `xml:"username"`
+}
+
+// Initialize initializes this TokensCreateOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *TokensCreateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr, --type
+	opts.TokenSelectorOptions.Initialize(flags)
+
+	// --access-level
+	if opts.AccessLevel == "" {
+		opts.AccessLevel = "maintainer"
+	}
+	flags.StringVar(&opts.AccessLevel, "access-level", opts.AccessLevel,
+		i18n.T("access level of an access token which is one of guest, "+
+			"reporter, developer, maintainer, or owner"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --expires-at
+	flags.Var(&opts.ExpiresAt, "expires-at",
+		i18n.T("date on which the token expires the form of which is "+
+			"YYYY/MM/DD or YYYY-MM-DD"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("name of the token"))
+
+	// --scopes
+	flags.Var(&opts.Scopes, "scopes",
+		i18n.T("comma-separated list of scopes of the token"))
+
+	// --secret-file
+	flags.StringVar(&opts.SecretFileName, "secret-file", opts.SecretFileName,
+		i18n.T("file only readable by its owner to which the secrets are "+
+			"written instead of printing them"))
+
+	// --username
+	flags.StringVar(&opts.Username, "username", opts.Username,
+		i18n.T("username of a deploy token"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// TokensCreateCommand
+////////////////////////////////////////////////////////////////////////
+
+// TokensCreateCommand implements the "tokens create" command which
+// creates a deploy token or access token in the projects in a group
+// or in the group itself.
+type TokensCreateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TokensCreateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TokensCreateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tokens create [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create a --type token named --name with the --scopes in the\n")
+	i18n.Fprintf(out, "    selected projects or, with --group-level, in --group itself.\n")
+	i18n.Fprintf(out, "    Projects that already have an active token of the type with\n")
+	i18n.Fprintf(out, "    the name are skipped.  Gitlab only returns the secret of a\n")
+	i18n.Fprintf(out, "    token when it is created, so the secrets are printed once or\n")
+	i18n.Fprintf(out, "    written to --secret-file.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTokensCreateCommand returns a new, initialized
+// TokensCreateCommand instance.
+func NewTokensCreateCommand(
+	name string,
+	opts *TokensCreateOptions,
+	session *Session,
+) *TokensCreateCommand {
+
+	// Create the new command.
+	cmd := &TokensCreateCommand{
+		GitlabCommand: GitlabCommand[TokensCreateOptions]{
+			BasicCommand: BasicCommand[TokensCreateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *TokensCreateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.TokenSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Type == "" {
+		return result, i18n.Errorf("%w: type not set", ErrInvalidOption)
+	}
+	if cmd.options.Name == "" {
+		return result, i18n.Errorf("%w: name not set", ErrInvalidOption)
+	}
+	if len(cmd.options.Scopes) == 0 {
+		return result, i18n.Errorf("%w: scopes not set", ErrInvalidOption)
+	}
+	level, err := ParseAccessLevel(cmd.options.AccessLevel)
+	if err != nil {
+		return result, err
+	}
+	spec := &TokenSpec{
+		Name:        cmd.options.Name,
+		Scopes:      cmd.options.Scopes,
+		AccessLevel: level,
+		ExpiresAt:   time.Time(cmd.options.ExpiresAt),
+		Username:    cmd.options.Username,
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Open the file for the secrets before creating any token so a
+	// secret is never lost because the file cannot be written.
+	secrets, err := OpenTokenSecrets(cmd.options.SecretFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Create the token in the group or each project.
+	s := TokensServices{
+		Groups:              cmd.client.Groups,
+		DeployTokens:        cmd.client.DeployTokens,
+		GroupAccessTokens:   cmd.client.GroupAccessTokens,
+		ProjectAccessTokens: cmd.client.ProjectAccessTokens,
+	}
+	tokenType := cmd.options.Type
+	err = ForEachTokenOwner(ctx, &cmd.options.TokenSelectorOptions, s,
+		func(owner string) (bool, error) {
+			existing, err := ListTokens(ctx, s, cmd.options.GroupLevel, owner, tokenType)
+			if err != nil {
+				result.Fail(owner, owner, err)
+				return true, nil
+			}
+			if slices.ContainsFunc(existing, func(t *TokenRecord) bool {
+				return t.Active && t.Name == spec.Name
+			}) {
+				logging.Printf("- Token %q already exists in %q.\n", spec.Name, owner)
+				return true, nil
+			}
+			ChangeToken(ctx, result, i18n.T("Creating"), tokenType, owner, spec.Name,
+				func() error {
+					t, secret, err := CreateToken(
+						ctx, s, cmd.options.GroupLevel, owner, tokenType, spec)
+					if err != nil {
+						return err
+					}
+					return secrets.Add(t, secret)
+				},
+				cmd.options.DryRun)
+			return true, nil
+		})
+	closeErr := secrets.Close()
+	if err != nil {
+		return result, err
+	}
+	if closeErr != nil {
+		return result, closeErr
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not create token in %d group(s) or project(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,198 @@
+// This file provides the implementation for the "tokens list" command
+// which lists the deploy tokens and access tokens of the projects in a
+// group or of the group itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TokensListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TokensListOptions are the options needed by this command.
+type TokensListOptions struct {
+
+	// Embed the options that select the groups or projects.
+	TokenSelectorOptions
+}
+
+// Initialize initializes this TokensListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *TokensListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr, --type
+	opts.TokenSelectorOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// TokensListCommand
+////////////////////////////////////////////////////////////////////////
+
+// TokensListCommand implements the "tokens list" command which lists
+// the deploy tokens and access tokens of the projects in a group or of
+// the group itself.
+type TokensListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TokensListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TokensListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tokens list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the deploy tokens and access tokens of the selected\n")
+	i18n.Fprintf(out, "    projects or, with --group-level, of --group itself.  Each\n")
+	i18n.Fprintf(out, "    token is printed as its owner, type, ID, expiry date, state,\n")
+	i18n.Fprintf(out, "    name, and scopes.  Secrets are never printed because Gitlab\n")
+	i18n.Fprintf(out, "    never returns them.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTokensListCommand returns a new, initialized TokensListCommand
+// instance.
+func NewTokensListCommand(
+	name string,
+	opts *TokensListOptions,
+	session *Session,
+) *TokensListCommand {
+
+	// Create the new command.
+	cmd := &TokensListCommand{
+		GitlabCommand: GitlabCommand[TokensListOptions]{
+			BasicCommand: BasicCommand[TokensListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// tokenExpiry returns the date on which the token expires or "never".
+func tokenExpiry(t *TokenRecord) string {
+	if t.ExpiresAt == nil {
+		return i18n.T("never")
+	}
+	return t.ExpiresAt.Format("2006-01-02")
+}
+
+// tokenState returns "active" or "inactive" for the token.
+func tokenState(t *TokenRecord) string {
+	if t.Active {
+		return i18n.T("active")
+	}
+	return i18n.T("inactive")
+}
+
+// Run is the entry point for this command.
+func (cmd *TokensListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.TokenSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print each token.  For --output json, the tokens are collected
+	// and printed together at the end.
+	records := []*TokenRecord{}
+	err = ForEachToken(
+		ctx, result, &cmd.options.TokenSelectorOptions,
+		TokensServices{
+			Groups:              cmd.client.Groups,
+			DeployTokens:        cmd.client.DeployTokens,
+			GroupAccessTokens:   cmd.client.GroupAccessTokens,
+			ProjectAccessTokens: cmd.client.ProjectAccessTokens,
+		},
+		func(t *TokenRecord) (bool, error) {
+			if cmd.session.OutputJSON() {
+				records = append(records, t)
+			} else {
+				fmt.Printf("%-40s  %-6s  %-6d  %-10s  %-8s  %-20s  %s\n",
+					t.Owner, t.Type, t.ID, tokenExpiry(t), tokenState(t),
+					t.Name, strings.Join(t.Scopes, ","))
+			}
+			result.Succeed(t.Owner+":"+t.Name, t)
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the tokens as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, records)
+		if err != nil {
+			return result, err
+		}
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not list the tokens of %d group(s) or project(s)", failed)
+	}
+	return result, nil
+}
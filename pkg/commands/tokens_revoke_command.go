@@ -0,0 +1,227 @@
+// This file provides the implementation for the "tokens revoke"
+// command which revokes the access tokens and deletes the deploy
+// tokens having a name in the projects in a group or in the group
+// itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TokensRevokeOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TokensRevokeOptions are the options needed by this command.
+type TokensRevokeOptions struct {
+
+	// Embed the options that control when revoking the tokens
+	// must be confirmed.
+	ConfirmationOptions
+
+	// Embed the options that select the groups or projects.
+	TokenSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Name is the name of the tokens to revoke.  Defaults to "".
+	Name string `xml:"name"`
+}
+
+// Initialize initializes this TokensRevokeOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *TokensRevokeOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr, --type
+	opts.TokenSelectorOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("name of the tokens to revoke"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// TokensRevokeCommand
+////////////////////////////////////////////////////////////////////////
+
+// TokensRevokeCommand implements the "tokens revoke" command which
+// revokes access tokens and deletes deploy tokens.
+type TokensRevokeCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TokensRevokeOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TokensRevokeCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tokens revoke [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Revoke the active access tokens and delete the active deploy\n")
+	i18n.Fprintf(out, "    tokens named --name of the selected projects or, with\n")
+	i18n.Fprintf(out, "    --group-level, of --group itself.  Use --type to only revoke\n")
+	i18n.Fprintf(out, "    tokens of one type.  The tokens are listed and must be\n")
+	i18n.Fprintf(out, "    confirmed unless the global --yes option is passed.  When\n")
+	i18n.Fprintf(out, "    revoking more tokens than --confirm-threshold,\n")
+	i18n.Fprintf(out, "    --i-understand must be passed, and the group must be typed\n")
+	i18n.Fprintf(out, "    to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Revoke Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTokensRevokeCommand returns a new, initialized
+// TokensRevokeCommand instance.
+func NewTokensRevokeCommand(
+	name string,
+	opts *TokensRevokeOptions,
+	session *Session,
+) *TokensRevokeCommand {
+
+	// Create the new command.
+	cmd := &TokensRevokeCommand{
+		GitlabCommand: GitlabCommand[TokensRevokeOptions]{
+			BasicCommand: BasicCommand[TokensRevokeOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *TokensRevokeCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.TokenSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Name == "" {
+		return result, i18n.Errorf("%w: name not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Collect the active tokens having the name.
+	s := TokensServices{
+		Groups:              cmd.client.Groups,
+		DeployTokens:        cmd.client.DeployTokens,
+		GroupAccessTokens:   cmd.client.GroupAccessTokens,
+		ProjectAccessTokens: cmd.client.ProjectAccessTokens,
+	}
+	var tokens []*TokenRecord
+	err = ForEachToken(ctx, result, &cmd.options.TokenSelectorOptions, s,
+		func(t *TokenRecord) (bool, error) {
+			if t.Active && t.Name == cmd.options.Name {
+				tokens = append(tokens, t)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, t := range tokens {
+			names = append(names, t.Owner+":"+t.Name)
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("revoke"), names,
+			cmd.options.Group, cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Revoke the tokens stopping after the current token if
+	// interrupted.
+	for _, t := range tokens {
+		err = ctx.Err()
+		if err != nil {
+			return result, err
+		}
+		ChangeToken(ctx, result, i18n.T("Revoking"), t.Type, t.Owner, t.Name,
+			func() error { return RevokeToken(ctx, s, t) },
+			cmd.options.DryRun)
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not revoke %d token(s)", failed)
+	}
+	return result, nil
+}
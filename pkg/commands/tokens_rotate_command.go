@@ -0,0 +1,238 @@
+// This file provides the implementation for the "tokens rotate"
+// command which replaces the deploy tokens or access tokens having a
+// name in the projects in a group or in the group itself with new
+// tokens.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// TokensRotateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// TokensRotateOptions are the options needed by this command.
+type TokensRotateOptions struct {
+
+	// Embed the options that select the groups or projects.
+	TokenSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ExpiresAt is the date on which the new tokens expire.  Defaults
+	// to not set which means Gitlab chooses the expiry date of access
+	// tokens and deploy tokens keep their expiry date.
+	ExpiresAt date_arg.DateArg `xml:"expires-at"`
+
+	// Name is the name of the tokens to rotate.  Defaults to "".
+	Name string `xml:"name"`
+
+	// SecretFileName is the name of the file to which the secrets of
+	// the new tokens are written.  The file is only readable by its
+	// owner.  Defaults to "" which means the secrets are printed
+	// once instead.
+	SecretFileName string `xml:"secret-file"`
+}
+
+// Initialize initializes this TokensRotateOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *TokensRotateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr, --type
+	opts.TokenSelectorOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --expires-at
+	flags.Var(&opts.ExpiresAt, "expires-at",
+		i18n.T("date on which the new tokens expire the form of which is "+
+			"YYYY/MM/DD or YYYY-MM-DD"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("name of the tokens to rotate"))
+
+	// --secret-file
+	flags.StringVar(&opts.SecretFileName, "secret-file", opts.SecretFileName,
+		i18n.T("file only readable by its owner to which the secrets are "+
+			"written instead of printing them"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// TokensRotateCommand
+////////////////////////////////////////////////////////////////////////
+
+// TokensRotateCommand implements the "tokens rotate" command which
+// replaces tokens with new tokens having the same settings.
+type TokensRotateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[TokensRotateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *TokensRotateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] tokens rotate [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Replace the active tokens named --name of the selected\n")
+	i18n.Fprintf(out, "    projects or, with --group-level, of --group itself with new\n")
+	i18n.Fprintf(out, "    tokens having the same settings.  Gitlab rotates access\n")
+	i18n.Fprintf(out, "    tokens itself.  Deploy tokens cannot be rotated, so a new\n")
+	i18n.Fprintf(out, "    deploy token is created before the old one is deleted.  The\n")
+	i18n.Fprintf(out, "    secrets are printed once or written to --secret-file.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Rotate Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewTokensRotateCommand returns a new, initialized
+// TokensRotateCommand instance.
+func NewTokensRotateCommand(
+	name string,
+	opts *TokensRotateOptions,
+	session *Session,
+) *TokensRotateCommand {
+
+	// Create the new command.
+	cmd := &TokensRotateCommand{
+		GitlabCommand: GitlabCommand[TokensRotateOptions]{
+			BasicCommand: BasicCommand[TokensRotateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *TokensRotateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.TokenSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Name == "" {
+		return result, i18n.Errorf("%w: name not set", ErrInvalidOption)
+	}
+	var expiresAt *time.Time
+	if date := time.Time(cmd.options.ExpiresAt); !date.IsZero() {
+		expiresAt = &date
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Open the file for the secrets before rotating any token so a
+	// secret is never lost because the file cannot be written.
+	secrets, err := OpenTokenSecrets(cmd.options.SecretFileName)
+	if err != nil {
+		return result, err
+	}
+
+	// Rotate each active token having the name.  The secret of a new
+	// deploy token is kept even if the old token cannot be deleted.
+	s := TokensServices{
+		Groups:              cmd.client.Groups,
+		DeployTokens:        cmd.client.DeployTokens,
+		GroupAccessTokens:   cmd.client.GroupAccessTokens,
+		ProjectAccessTokens: cmd.client.ProjectAccessTokens,
+	}
+	err = ForEachToken(ctx, result, &cmd.options.TokenSelectorOptions, s,
+		func(t *TokenRecord) (bool, error) {
+			if !t.Active || t.Name != cmd.options.Name {
+				return true, nil
+			}
+			ChangeToken(ctx, result, i18n.T("Rotating"), t.Type, t.Owner, t.Name,
+				func() error {
+					nt, secret, err := RotateToken(ctx, s, t, expiresAt)
+					if nt != nil {
+						if addErr := secrets.Add(nt, secret); err == nil {
+							err = addErr
+						}
+					}
+					return err
+				},
+				cmd.options.DryRun)
+			return true, nil
+		})
+	closeErr := secrets.Close()
+	if err != nil {
+		return result, err
+	}
+	if closeErr != nil {
+		return result, closeErr
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not rotate %d token(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,472 @@
+// This file provides the undo file that destructive commands write so
+// their changes can be undone later by the "undo" command.  The undo
+// file records the state of each entity before it was changed, and
+// undoing restores as much of that state as Gitlab allows.
+
+package commands
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UndoPlan
+////////////////////////////////////////////////////////////////////////
+
+// UndoPlan is the content of an undo file.  It is safe for concurrent
+// use so bulk commands can record entities from multiple goroutines.
+type UndoPlan struct {
+	XMLName xml.Name `xml:"undo-plan"`
+
+	// Command is the command that made the changes (e.g., "projects
+	// delete").
+	Command string `xml:"command"`
+
+	// Created is when the undo plan was created.
+	Created time.Time `xml:"created"`
+
+	// Projects are the projects that were deleted or moved to the
+	// trash group as they were before.
+	Projects []*UndoPlanProject `xml:"projects>project"`
+
+	// ApprovalRules are the approval rules as they were before they
+	// were updated.
+	ApprovalRules []*UndoPlanApprovalRule `xml:"approval-rules>approval-rule"`
+
+	// mutex protects the members above while the command is running.
+	mutex sync.Mutex
+}
+
+// UndoPlanProject is the state of a project before it was deleted or
+// moved to the trash group.
+type UndoPlanProject struct {
+
+	// ID is the ID of the project.
+	ID int `xml:"id"`
+
+	// PathWithNamespace is the full path of the project.
+	PathWithNamespace string `xml:"path-with-namespace"`
+
+	// NamespaceID is the ID of the group that held the project.
+	NamespaceID int `xml:"namespace-id"`
+
+	// Path is the path of the project within its group.
+	Path string `xml:"path"`
+
+	// Name is the name of the project.
+	Name string `xml:"name"`
+
+	// Description is the description of the project.
+	Description string `xml:"description"`
+
+	// Visibility is the visibility of the project.
+	Visibility gitlab.VisibilityValue `xml:"visibility"`
+
+	// DefaultBranch is the default branch of the project.
+	DefaultBranch string `xml:"default-branch"`
+
+	// Topics are the topics of the project.
+	Topics []string `xml:"topics>topic"`
+
+	// Archived is whether the project was archived.
+	Archived bool `xml:"archived"`
+
+	// TrashGroup is the full path of the trash group to which the
+	// project was moved or "" if the project was deleted.
+	TrashGroup string `xml:"trash-group,omitempty"`
+}
+
+// UndoPlanApprovalRule is the state of an approval rule before it was
+// updated.
+type UndoPlanApprovalRule struct {
+
+	// ProjectID is the ID of the project that has the rule.
+	ProjectID int `xml:"project-id"`
+
+	// Project is the full path of the project that has the rule.
+	Project string `xml:"project"`
+
+	// ID is the ID of the rule.
+	ID int `xml:"id"`
+
+	// Name is the name of the rule.
+	Name string `xml:"name"`
+
+	// ApprovalsRequired is the number of approvals required.
+	ApprovalsRequired int `xml:"approvals-required"`
+
+	// UserIDs are the IDs of the approvers.
+	UserIDs []int `xml:"user-ids>user-id"`
+
+	// Usernames are the usernames of the approvers.  They are only
+	// recorded so the undo file is easier to read.
+	Usernames []string `xml:"usernames>username"`
+
+	// GroupIDs are the IDs of the approver groups.
+	GroupIDs []int `xml:"group-ids>group-id"`
+
+	// ProtectedBranchIDs are the IDs of the protected branches to
+	// which the rule applies.
+	ProtectedBranchIDs []int `xml:"protected-branch-ids>protected-branch-id"`
+
+	// AppliesToAllProtectedBranches is whether the rule applies to
+	// all protected branches.
+	AppliesToAllProtectedBranches bool `xml:"applies-to-all-protected-branches"`
+}
+
+// NewUndoPlan returns a new, empty UndoPlan for the command.
+func NewUndoPlan(command string) *UndoPlan {
+	return &UndoPlan{
+		Command: command,
+		Created: time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// AddProject records the project before it was deleted or, if
+// trashGroup is not "", before it was moved to the trash group.
+func (plan *UndoPlan) AddProject(p *gitlab.Project, trashGroup string) {
+	up := &UndoPlanProject{
+		ID:                p.ID,
+		PathWithNamespace: p.PathWithNamespace,
+		Path:              p.Path,
+		Name:              p.Name,
+		Description:       p.Description,
+		Visibility:        p.Visibility,
+		DefaultBranch:     p.DefaultBranch,
+		Topics:            p.Topics,
+		Archived:          p.Archived,
+		TrashGroup:        trashGroup,
+	}
+	if p.Namespace != nil {
+		up.NamespaceID = p.Namespace.ID
+	}
+	plan.mutex.Lock()
+	defer plan.mutex.Unlock()
+	plan.Projects = append(plan.Projects, up)
+}
+
+// AddApprovalRule records the approval rule of the project before it
+// was updated.
+func (plan *UndoPlan) AddApprovalRule(p *gitlab.Project, rule *gitlab.ProjectApprovalRule) {
+	ur := &UndoPlanApprovalRule{
+		ProjectID:                     p.ID,
+		Project:                       p.PathWithNamespace,
+		ID:                            rule.ID,
+		Name:                          rule.Name,
+		ApprovalsRequired:             rule.ApprovalsRequired,
+		Usernames:                     gitlab_util.GetApprovalRuleUsernames(rule),
+		AppliesToAllProtectedBranches: rule.AppliesToAllProtectedBranches,
+	}
+	for _, u := range rule.Users {
+		ur.UserIDs = append(ur.UserIDs, u.ID)
+	}
+	for _, g := range rule.Groups {
+		ur.GroupIDs = append(ur.GroupIDs, g.ID)
+	}
+	for _, b := range rule.ProtectedBranches {
+		ur.ProtectedBranchIDs = append(ur.ProtectedBranchIDs, b.ID)
+	}
+	plan.mutex.Lock()
+	defer plan.mutex.Unlock()
+	plan.ApprovalRules = append(plan.ApprovalRules, ur)
+}
+
+// Empty returns true if the undo plan has nothing to undo.
+func (plan *UndoPlan) Empty() bool {
+	plan.mutex.Lock()
+	defer plan.mutex.Unlock()
+	return len(plan.Projects) == 0 && len(plan.ApprovalRules) == 0
+}
+
+// LoadUndoPlan loads the undo plan from the file.
+func LoadUndoPlan(fileName string) (*UndoPlan, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("LoadUndoPlan: %w", err)
+	}
+	plan := &UndoPlan{}
+	err = xml.Unmarshal(data, plan)
+	if err != nil {
+		return nil, i18n.Errorf("LoadUndoPlan: unable to parse %q: %w", fileName, err)
+	}
+	return plan, nil
+}
+
+// Save atomically writes the undo plan to the file by first writing a
+// temporary file in the same directory and then renaming it.
+func (plan *UndoPlan) Save(fileName string) error {
+	plan.mutex.Lock()
+	data, err := xml.MarshalIndent(plan, "", "  ")
+	plan.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("UndoPlan.Save: %w", err)
+	}
+	data = append(data, '\n')
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".*")
+	if err != nil {
+		return fmt.Errorf("UndoPlan.Save: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		tmp.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("UndoPlan.Save: %w", err)
+	}
+	err = os.Rename(tmp.Name(), fileName)
+	if err != nil {
+		return fmt.Errorf("UndoPlan.Save: %w", err)
+	}
+	return nil
+}
+
+// saveUndoPlan saves the undo plan to the file unless fileName is ""
+// or there is nothing to undo and prints where it was saved.  It is
+// called at the end of destructive commands with the error (if any)
+// that stopped the command which is returned unless the undo plan
+// cannot be saved.
+func saveUndoPlan(plan *UndoPlan, fileName string, err error) error {
+	if fileName == "" || plan.Empty() {
+		return err
+	}
+	saveErr := plan.Save(fileName)
+	if saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	logging.Printf("- Undo file written to %q.\n", fileName)
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////
+// Undoing
+////////////////////////////////////////////////////////////////////////
+
+// UndoProject restores the project to its state before it was deleted
+// or moved to the trash group.  A project that still exists is
+// restored if it is marked for deletion, moved back to its group, and
+// given back its topics and archived state.  A project that no longer
+// exists is recreated with its settings, but its repository and other
+// content are lost.  If dryRun is true, this function only prints what
+// it would do without actually doing it.
+func UndoProject(
+	ctx context.Context,
+	client *gitlab.Client,
+	up *UndoPlanProject,
+	dryRun bool,
+) error {
+	var err error
+	name := up.PathWithNamespace
+	opts := gitlab.WithContext(gitlab_util.Uninterruptible(ctx))
+
+	// step prints the progress message and calls f unless this is a
+	// dry run.
+	step := func(format string, f func() error) error {
+		logging.Printf(format, name)
+		if !dryRun {
+			err := f()
+			if err != nil {
+				logging.Printf("Failed.\n")
+				return fmt.Errorf("UndoProject: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+		return nil
+	}
+
+	// Find the project by its ID or, because a recreated project has a
+	// new ID, by its original path.  Recreate the project if neither
+	// finds it.
+	p, _, err := client.Projects.GetProject(up.ID, nil, gitlab.WithContext(ctx))
+	if errors.Is(gitlab_util.ClassifyError(err), gitlab_util.ErrNotFound) {
+		p, _, err = client.Projects.GetProject(
+			up.PathWithNamespace, nil, gitlab.WithContext(ctx))
+	}
+	if errors.Is(gitlab_util.ClassifyError(err), gitlab_util.ErrNotFound) {
+		err = step("- Recreating project %q without its repository ... ", func() error {
+			p, _, err = client.Projects.CreateProject(&gitlab.CreateProjectOptions{
+				NamespaceID:   gitlab.Ptr(up.NamespaceID),
+				Path:          gitlab.Ptr(up.Path),
+				Name:          gitlab.Ptr(up.Name),
+				Description:   gitlab.Ptr(up.Description),
+				Visibility:    gitlab.Ptr(up.Visibility),
+				DefaultBranch: gitlab.Ptr(up.DefaultBranch),
+				Topics:        gitlab.Ptr(up.Topics),
+			}, opts)
+			if err == nil && up.Archived {
+				_, _, err = client.Projects.ArchiveProject(p.ID, opts)
+			}
+			return err
+		})
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("UndoProject: %w", gitlab_util.ClassifyError(err))
+	}
+
+	// Restore the project if it is marked for deletion.
+	changed := false
+	if p.MarkedForDeletionAt != nil {
+		changed = true
+		err = step("- Restoring project %q ... ", func() error {
+			return gitlab_util.RestoreProject(ctx, client, p.ID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Move the project back to its group.
+	if p.Namespace != nil && p.Namespace.ID != up.NamespaceID {
+		changed = true
+		err = step("- Moving project %q back ... ", func() error {
+			_, _, err := client.Projects.TransferProject(p.ID,
+				&gitlab.TransferProjectOptions{Namespace: up.NamespaceID}, opts)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Restore the topics.
+	if !slices.Equal(p.Topics, up.Topics) {
+		changed = true
+		err = step("- Restoring topics of project %q ... ", func() error {
+			topics := up.Topics
+			if topics == nil {
+				topics = []string{}
+			}
+			_, _, err := client.Projects.EditProject(p.ID,
+				&gitlab.EditProjectOptions{Topics: &topics}, opts)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Unarchive the project.
+	if p.Archived && !up.Archived {
+		changed = true
+		err = step("- Unarchiving project %q ... ", func() error {
+			_, _, err := client.Projects.UnarchiveProject(p.ID, opts)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if !changed {
+		logging.Printf("- Project %q already restored.\n", name)
+	}
+	return nil
+}
+
+// UndoApprovalRuleService is the set of gitlab.ProjectsService methods
+// needed to undo the update of an approval rule.
+type UndoApprovalRuleService interface {
+	gitlab_util.ApprovalRuleCreator
+	gitlab_util.ApprovalRulesGetter
+	gitlab_util.ApprovalRuleUpdater
+}
+
+// UndoApprovalRule restores the approvers, approver groups, and number
+// of required approvals of the approval rule.  If the rule no longer
+// exists, it is recreated.  If dryRun is true, this function only
+// prints what it would do without actually doing it.
+func UndoApprovalRule(
+	ctx context.Context,
+	s UndoApprovalRuleService, /* was *gitlab.ProjectsService */
+	ur *UndoPlanApprovalRule,
+	dryRun bool,
+) error {
+	name := fmt.Sprintf("%s:%s", ur.Project, ur.Name)
+
+	// Find the rule.
+	var existing *gitlab.ProjectApprovalRule
+	err := gitlab_util.ForEachApprovalRuleInProject(ctx, s,
+		&gitlab.Project{ID: ur.ProjectID, PathWithNamespace: ur.Project},
+		func(rule *gitlab.ProjectApprovalRule) (bool, error) {
+			if rule.ID == ur.ID {
+				existing = rule
+				return false, nil
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("UndoApprovalRule: %w", err)
+	}
+
+	// Recreate the rule if it no longer exists.
+	if existing == nil {
+		logging.Printf("- Recreating approval rule %q ... ", name)
+		if !dryRun {
+			_, _, err = s.CreateProjectApprovalRule(ur.ProjectID,
+				&gitlab.CreateProjectLevelRuleOptions{
+					Name:                          gitlab.Ptr(ur.Name),
+					ApprovalsRequired:             gitlab.Ptr(ur.ApprovalsRequired),
+					UserIDs:                       gitlab.Ptr(ur.UserIDs),
+					GroupIDs:                      gitlab.Ptr(ur.GroupIDs),
+					ProtectedBranchIDs:            gitlab.Ptr(ur.ProtectedBranchIDs),
+					AppliesToAllProtectedBranches: gitlab.Ptr(ur.AppliesToAllProtectedBranches),
+				},
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			if err != nil {
+				logging.Printf("Failed.\n")
+				return fmt.Errorf("UndoApprovalRule: %w", gitlab_util.ClassifyError(err))
+			}
+		}
+		logging.Printf("Done.\n")
+		return nil
+	}
+
+	// Leave the rule alone if it already matches.
+	if slices.Equal(gitlab_util.GetApprovalRuleUsernames(existing), ur.Usernames) &&
+		existing.ApprovalsRequired == ur.ApprovalsRequired {
+		logging.Printf("- Approval rule %q already restored.\n", name)
+		return nil
+	}
+
+	// Restore the rule.
+	rule := &gitlab.ProjectApprovalRule{
+		ID:                            ur.ID,
+		Name:                          ur.Name,
+		ApprovalsRequired:             ur.ApprovalsRequired,
+		AppliesToAllProtectedBranches: ur.AppliesToAllProtectedBranches,
+	}
+	for _, id := range ur.GroupIDs {
+		rule.Groups = append(rule.Groups, &gitlab.Group{ID: id})
+	}
+	for _, id := range ur.ProtectedBranchIDs {
+		rule.ProtectedBranches = append(rule.ProtectedBranches,
+			&gitlab.ProtectedBranch{ID: id})
+	}
+	logging.Printf("- Restoring approval rule %q ... ", name)
+	if !dryRun {
+		_, err = gitlab_util.UpdateApprovalRule(
+			gitlab_util.Uninterruptible(ctx), s, ur.ProjectID, rule, ur.UserIDs)
+		if err != nil {
+			logging.Printf("Failed.\n")
+			return fmt.Errorf("UndoApprovalRule: %w", err)
+		}
+	}
+	logging.Printf("Done.\n")
+	return nil
+}
@@ -0,0 +1,195 @@
+// This file provides the implementation for the "undo" command which
+// undoes the changes recorded in an undo file written by a destructive
+// command like "projects delete" or "projects approval-rules update".
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UndoOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UndoOptions are the options needed by this command.
+type UndoOptions struct {
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// From is the name of the undo file written by the command whose
+	// changes are undone.  Defaults to "".
+	From string `xml:"from"`
+}
+
+// Initialize initializes this UndoOptions instance so it can be used
+// with the "flag" package to parse the command-line arguments.
+func (opts *UndoOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --from
+	flags.StringVar(&opts.From, "from", opts.From,
+		i18n.T("name of the undo file written by the command whose changes are undone"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UndoCommand
+////////////////////////////////////////////////////////////////////////
+
+// UndoCommand implements the "undo" command which undoes the changes
+// recorded in an undo file.
+type UndoCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UndoOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UndoCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] undo [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Undoes the changes recorded in the --from undo file which\n")
+	i18n.Fprintf(out, "    is written by the --undo-file option of \"projects delete\"\n")
+	i18n.Fprintf(out, "    and \"projects approval-rules update\".  Projects moved to\n")
+	i18n.Fprintf(out, "    the trash group are moved back and unarchived.  Projects\n")
+	i18n.Fprintf(out, "    marked for deletion are restored.  Projects that were\n")
+	i18n.Fprintf(out, "    deleted are recreated with their settings, but their\n")
+	i18n.Fprintf(out, "    repositories cannot be restored.  Approval rules get their\n")
+	i18n.Fprintf(out, "    previous approvers back and are recreated if they were\n")
+	i18n.Fprintf(out, "    deleted.  Changes that were already undone are skipped so\n")
+	i18n.Fprintf(out, "    the command can be run again after a failure.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Undo Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUndoCommand returns a new, initialized UndoCommand instance.
+func NewUndoCommand(
+	name string,
+	opts *UndoOptions,
+	session *Session,
+) *UndoCommand {
+
+	// Create the new command.
+	cmd := &UndoCommand{
+		GitlabCommand: GitlabCommand[UndoOptions]{
+			BasicCommand: BasicCommand[UndoOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *UndoCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.From == "" {
+		return result, i18n.Errorf("%w: undo file not set", ErrInvalidOption)
+	}
+
+	// Load the undo plan.
+	plan, err := LoadUndoPlan(cmd.options.From)
+	if err != nil {
+		return result, err
+	}
+	logging.Printf("- Undoing %q from %s.\n", plan.Command,
+		plan.Created.Local().Format("2006-01-02 15:04:05"))
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Undo each change.  A change that cannot be undone is recorded in
+	// the result, and the remaining changes are still undone.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, up := range plan.Projects {
+		name := up.PathWithNamespace
+		hook.OnItemStart(name)
+		err = UndoProject(ctx, cmd.client, up, cmd.options.DryRun)
+		if err != nil {
+			hook.OnError(name, err)
+			result.Fail(name, up, err)
+			continue
+		}
+		hook.OnItemDone(name)
+		result.Succeed(name, up)
+	}
+	for _, ur := range plan.ApprovalRules {
+		name := ur.Project + ":" + strconv.Itoa(ur.ID)
+		hook.OnItemStart(name)
+		err = UndoApprovalRule(ctx, cmd.client.Projects, ur, cmd.options.DryRun)
+		if err != nil {
+			hook.OnError(name, err)
+			result.Fail(name, ur, err)
+			continue
+		}
+		hook.OnItemDone(name)
+		result.Succeed(name, ur)
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not undo %d change(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestUndoIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddGroup("trash")
+	server.SetTopics("foo/beta", "backend")
+	server.AddApprovalRule("foo/alpha", "reviewers", 1, "aberns")
+	undoFileName := filepath.Join(t.TempDir(), "undo.xml")
+	run := func(cmd Runner, args ...string) error {
+		_, _, err := runCommand(t, cmd, args)
+		return err
+	}
+	projects := func(args ...string) error {
+		session := NewSessionWithClient(server.Client(t))
+		session.globalOpts.Yes = true
+		return run(NewProjectsCommand("projects", &ProjectsOptions{}, session), args...)
+	}
+	undo := func(args ...string) error {
+		session := NewSessionWithClient(server.Client(t))
+		return run(NewUndoCommand("undo", &UndoOptions{}, session), args...)
+	}
+
+	// Write the approvers file using "users list".
+	approversFileName := filepath.Join(t.TempDir(), "users.xml")
+	err := run(NewUsersCommand("users", &UsersOptions{},
+		NewSessionWithClient(server.Client(t))),
+		"list", "--users", "bcrocket", "-o", approversFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Replace the approvers and undo it.
+	err = projects("approval-rules", "update", "--group", "foo",
+		"--expr", "alpha", "--approvers", approversFileName,
+		"--undo-file", undoFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	updated := gitlab_util.GetApprovalRuleUsernames(server.ApprovalRules("foo/alpha")[0])
+	err = undo("--from", undoFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	restored := gitlab_util.GetApprovalRuleUsernames(server.ApprovalRules("foo/alpha")[0])
+
+	// Move a project to the trash group and undo it.
+	err = projects("delete", "--group", "foo", "--expr", "beta",
+		"--trash-group", "trash", "--undo-file", undoFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	trashed := server.Projects()
+	err = undo("--from", undoFileName, "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dryRun := server.Projects()
+	err = undo("--from", undoFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	untrashed := server.Projects()
+	beta := server.Project("foo/beta")
+
+	// Delete a project and undo it.
+	err = projects("delete", "--group", "foo", "--expr", "alpha",
+		"--undo-file", undoFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	deleted := server.Projects()
+	err = undo("--from", undoFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recreated := server.Projects()
+
+	// Undoing again should do nothing.
+	err = undo("--from", undoFileName)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again := server.Projects()
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"updated", "[bcrocket]", updated},
+		{"restored", "[aberns]", restored},
+		{"trashed",
+			[]string{"foo/alpha", "trash/beta", "foo/test-gamma", "foo/bar/delta", "foo/bar/test-epsilon"},
+			trashed},
+		{"dry run", trashed, dryRun},
+		{"untrashed",
+			[]string{"foo/alpha", "foo/beta", "foo/test-gamma", "foo/bar/delta", "foo/bar/test-epsilon"},
+			untrashed},
+		{"unarchived", false, beta.Archived},
+		{"topics", "[backend]", beta.Topics},
+		{"deleted",
+			[]string{"foo/beta", "foo/test-gamma", "foo/bar/delta", "foo/bar/test-epsilon"},
+			deleted},
+		{"recreated", true, slices.Contains(recreated, "foo/alpha")},
+		{"again", recreated, again},
+		{"missing file", true, undo("--from", filepath.Join(t.TempDir(), "none.xml")) != nil},
+		{"no file", true, errors.Is(undo(), ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("undo %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
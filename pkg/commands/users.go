@@ -0,0 +1,145 @@
+// This file provides the options and functions shared by the "users"
+// subcommands which change the state of users (e.g., by blocking
+// them).
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+// UserStateOptions are the options of the "users" subcommands which
+// change the state of users.  They are embedded in the options of the
+// subcommands so the options have the same names and meaning
+// everywhere.  Because the struct is embedded, its XML elements appear
+// directly in the options of the embedding command in the options.xml
+// file.
+type UserStateOptions struct {
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Users are the IDs, names, usernames, or e-mail addresses of the
+	// users whose state is changed.  Each must match exactly one
+	// user.  Defaults to no users.
+	Users string_slice.StringSlice `xml:"users>user"`
+}
+
+// Initialize initializes this UserStateOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *UserStateOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --users
+	flags.Var(&opts.Users, "users",
+		i18n.T("comma-separated list of user IDs, names, usernames, or "+
+			"e-mail addresses"))
+}
+
+// Validate returns an error if the options do not select any users.
+func (opts *UserStateOptions) Validate() error {
+	if len(opts.Users) == 0 {
+		return i18n.Errorf("%w: users not set", ErrInvalidOption)
+	}
+	return nil
+}
+
+// GetSelectedUsers returns the users selected by --users.  Every
+// user must be found exactly so that no user is changed if any of them
+// is misspelled or ambiguous.
+func (opts *UserStateOptions) GetSelectedUsers(
+	ctx context.Context,
+	s gitlab_util.UserFinder, /* was *gitlab.UsersService */
+) ([]*gitlab.User, error) {
+	var result []*gitlab.User
+	for _, user := range opts.Users {
+		users, err := gitlab_util.FindUsers(ctx, s, user, true, time.Time{})
+		if err != nil {
+			return nil, i18n.Errorf("unable to find user: %q: %w", user, err)
+		}
+		result = append(result, users...)
+	}
+	return result, nil
+}
+
+// UserStateChange describes how the state of users is changed.
+type UserStateChange struct {
+
+	// Verb is used in the progress message (e.g., "Blocking").
+	Verb string
+
+	// State is the state of the users after the change (e.g.,
+	// "blocked").  Users already in the state are skipped.
+	State string
+
+	// Change changes the state of the user having the ID.  It has the
+	// signature of the methods of gitlab.UsersService (e.g.,
+	// BlockUser()).
+	Change func(user int, options ...gitlab.RequestOptionFunc) error
+}
+
+// usernamesToChange returns the usernames of the users that are not
+// already in the state which are the users ChangeUserStates() changes.
+func usernamesToChange(users []*gitlab.User, state string) []string {
+	var result []string
+	for _, u := range users {
+		if u.State != state {
+			result = append(result, u.Username)
+		}
+	}
+	return result
+}
+
+// ChangeUserStates changes the state of the users.  Users that are
+// already in the requested state are skipped.  A user whose state
+// cannot be changed is recorded in the result, and the remaining users
+// are still changed.  If dryRun is true, this function only prints
+// what it would do without actually doing it.
+func ChangeUserStates(
+	ctx context.Context,
+	result *Result,
+	users []*gitlab.User,
+	change UserStateChange,
+	dryRun bool,
+) {
+	hook := gitlab_util.EventHookFromContext(ctx)
+	for _, u := range users {
+		if u.State == change.State {
+			logging.Printf("- User %q already %s.\n", u.Username, change.State)
+			continue
+		}
+		hook.OnItemStart(u.Username)
+		logging.Printf("- %s user %q ... ", change.Verb, u.Username)
+		if !dryRun {
+			err := change.Change(u.ID,
+				gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			if err != nil {
+				logging.Printf("Failed.\n")
+				err = fmt.Errorf("ChangeUserStates: %w", gitlab_util.ClassifyError(err))
+				hook.OnError(u.Username, err)
+				result.Fail(u.Username, u, err)
+				continue
+			}
+		}
+		logging.Printf("Done.\n")
+		hook.OnItemDone(u.Username)
+		result.Succeed(u.Username, u)
+	}
+}
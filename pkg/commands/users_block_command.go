@@ -0,0 +1,171 @@
+// This file provides the implementation for the "users block" command
+// which blocks users so they can no longer sign in.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersBlockOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersBlockOptions are the options needed by this command.
+type UsersBlockOptions struct {
+
+	// Embed the options that control when blocking the users must
+	// be confirmed.
+	ConfirmationOptions
+
+	// Embed the options that select the users.
+	UserStateOptions
+}
+
+// Initialize initializes this UsersBlockOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *UsersBlockOptions) Initialize(flags *flag.FlagSet) {
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// -n, --dry-run, --users
+	opts.UserStateOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersBlockCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersBlockCommand implements the "users block" command which
+// blocks users so they can no longer sign in.
+type UsersBlockCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersBlockOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersBlockCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users block [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Block the users in --users so they can no longer sign in or use\n")
+	i18n.Fprintf(out, "    their tokens.  Users who are already blocked are skipped.\n")
+	i18n.Fprintf(out, "    Blocking users requires an administrator token.  The users\n")
+	i18n.Fprintf(out, "    are listed and must be confirmed unless the global --yes\n")
+	i18n.Fprintf(out, "    option is passed.  When blocking more users than\n")
+	i18n.Fprintf(out, "    --confirm-threshold, --i-understand must be passed, and a\n")
+	i18n.Fprintf(out, "    generated token must be typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Block Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersBlockCommand returns a new, initialized UsersBlockCommand
+// instance.
+func NewUsersBlockCommand(
+	name string,
+	opts *UsersBlockOptions,
+	session *Session,
+) *UsersBlockCommand {
+
+	// Create the new command.
+	cmd := &UsersBlockCommand{
+		GitlabCommand: GitlabCommand[UsersBlockOptions]{
+			BasicCommand: BasicCommand[UsersBlockOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersBlockCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Block the users.
+	users, err := cmd.options.GetSelectedUsers(ctx, cmd.client.Users)
+	if err != nil {
+		return result, err
+	}
+	if !cmd.options.DryRun {
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("block"),
+			usernamesToChange(users, "blocked"), "", cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+	ChangeUserStates(ctx, result, users,
+		UserStateChange{
+			Verb:   i18n.T("Blocking"),
+			State:  "blocked",
+			Change: cmd.client.Users.BlockUser,
+		},
+		cmd.options.DryRun)
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not block %d user(s)", failed)
+	}
+	return result, nil
+}
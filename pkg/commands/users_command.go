@@ -0,0 +1,171 @@
+// This file provides the implementation for the "users" command
+// which provides user related subcommands.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      UsersCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersOptions are the options needed by this command.
+type UsersOptions struct {
+	UsersActivateOpts         UsersActivateOptions         `xml:"activate-options"`
+	UsersBlockOpts            UsersBlockOptions            `xml:"block-options"`
+	UsersCreateOpts           UsersCreateOptions           `xml:"create-options"`
+	UsersCreateRandomOpts     UsersCreateRandomOptions     `xml:"create-random-options"`
+	UsersDeactivateOpts       UsersDeactivateOptions       `xml:"deactivate-options"`
+	UsersImportOpts           UsersImportOptions           `xml:"import-options"`
+	UsersInactiveOpts         UsersInactiveOptions         `xml:"inactive-options"`
+	UsersListOpts             UsersListOptions             `xml:"list-options"`
+	UsersMembershipReportOpts UsersMembershipReportOptions `xml:"membership-report-options"`
+	UsersReportOpts           UsersReportOptions           `xml:"report-options"`
+	UsersUnblockOpts          UsersUnblockOptions          `xml:"unblock-options"`
+}
+
+// Initialize initializes this UsersOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *UsersOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersCommand provides subcommands for Gitlab project related
+// maintenance.
+type UsersCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[UsersOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *UsersCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for administering a Gitlab users.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *UsersCommand) addSubcmds(session *Session) {
+	cmd.subcmds["activate"] = NewUsersActivateCommand(
+		"activate", &cmd.options.UsersActivateOpts, session)
+	cmd.subcmds["block"] = NewUsersBlockCommand(
+		"block", &cmd.options.UsersBlockOpts, session)
+	cmd.subcmds["create"] = NewUsersCreateCommand(
+		"create", &cmd.options.UsersCreateOpts, session)
+	cmd.subcmds["create-random"] = NewUsersCreateRandomCommand(
+		"create-random", &cmd.options.UsersCreateRandomOpts, session)
+	cmd.subcmds["deactivate"] = NewUsersDeactivateCommand(
+		"deactivate", &cmd.options.UsersDeactivateOpts, session)
+	cmd.subcmds["import"] = NewUsersImportCommand(
+		"import", &cmd.options.UsersImportOpts, session)
+	cmd.subcmds["inactive"] = NewUsersInactiveCommand(
+		"inactive", &cmd.options.UsersInactiveOpts, session)
+	cmd.subcmds["list"] = NewUsersListCommand(
+		"list", &cmd.options.UsersListOpts, session)
+	cmd.subcmds["membership-report"] = NewUsersMembershipReportCommand(
+		"membership-report", &cmd.options.UsersMembershipReportOpts, session)
+	cmd.subcmds["report"] = NewUsersReportCommand(
+		"report", &cmd.options.UsersReportOpts, session)
+	cmd.subcmds["unblock"] = NewUsersUnblockCommand(
+		"unblock", &cmd.options.UsersUnblockOpts, session)
+}
+
+// NewUsersCommand returns a new, initialized UsersCommand
+// instance having the specified name.
+func NewUsersCommand(
+	name string,
+	opts *UsersOptions,
+	session *Session,
+) *UsersCommand {
+
+	// Create the new command.
+	cmd := &UsersCommand{
+		ParentCommand: ParentCommand[UsersOptions]{
+			BasicCommand: BasicCommand[UsersOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
@@ -0,0 +1,327 @@
+// This file provides the implementation for the "users create" command
+// which creates a single user and optionally adds the user to a group.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_users"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersCreateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersCreateOptions are the options needed by this command.
+type UsersCreateOptions struct {
+
+	// AccessLevel is the access level the new user is granted in
+	// Group which is one of "guest", "reporter", "developer",
+	// "maintainer", or "owner".  Defaults to "".
+	AccessLevel string `xml:"access-level"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Email is the e-mail address of the new user.  Defaults to "".
+	Email string `xml:"email"`
+
+	// Group is the full path of the group to which the new user is
+	// added with AccessLevel.  If empty, the user is not added to a
+	// group.  Defaults to "".
+	Group string `xml:"group"`
+
+	// Name is the full name of the new user.  Defaults to "".
+	Name string `xml:"name"`
+
+	// Username is the username of the new user.  Defaults to "".
+	Username string `xml:"username"`
+}
+
+// Initialize initializes this UsersCreateOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *UsersCreateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --access-level
+	flags.StringVar(&opts.AccessLevel, "access-level", opts.AccessLevel,
+		i18n.T("access level of the new user in --group which is one of "+
+			"guest, reporter, developer, maintainer, or owner"))
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --email
+	flags.StringVar(&opts.Email, "email", opts.Email,
+		i18n.T("e-mail address of the new user"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the group to which the new user is added"))
+
+	// --name
+	flags.StringVar(&opts.Name, "name", opts.Name,
+		i18n.T("full name of the new user"))
+
+	// --username
+	flags.StringVar(&opts.Username, "username", opts.Username,
+		i18n.T("username of the new user"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersCreateCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersCreateCommand implements the "users create" command which
+// creates a single user and optionally adds the user to a group.
+type UsersCreateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersCreateOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersCreateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users create [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create a user with --username, --name, and --email.  The user\n")
+	i18n.Fprintf(out, "    is sent an e-mail with a link to set the password.  If\n")
+	i18n.Fprintf(out, "    --group is set, the user is also added to the group with\n")
+	i18n.Fprintf(out, "    --access-level.  Creating users requires an administrator\n")
+	i18n.Fprintf(out, "    token.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersCreateCommand returns a new, initialized UsersCreateCommand
+// instance.
+func NewUsersCreateCommand(
+	name string,
+	opts *UsersCreateOptions,
+	session *Session,
+) *UsersCreateCommand {
+
+	// Create the new command.
+	cmd := &UsersCreateCommand{
+		GitlabCommand: GitlabCommand[UsersCreateOptions]{
+			BasicCommand: BasicCommand[UsersCreateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// NewUser describes a user to be created by CreateUser().
+type NewUser struct {
+
+	// Username is the username of the user.
+	Username string
+
+	// Name is the full name of the user.
+	Name string
+
+	// Email is the e-mail address of the user.
+	Email string
+
+	// AccessLevel is the name of the access level the user is granted
+	// in the group as accepted by ParseAccessLevel().  If empty, the
+	// user is not added to the group.
+	AccessLevel string
+}
+
+// Validate returns an error if the user is incomplete or has values
+// that are not valid.
+func (u *NewUser) Validate() error {
+	if u.Username == "" {
+		return i18n.Errorf("%w: username not set", ErrInvalidOption)
+	}
+	if u.Name == "" {
+		return i18n.Errorf("%w: name not set", ErrInvalidOption)
+	}
+	if _, err := mail.ParseAddress(u.Email); err != nil {
+		return i18n.Errorf("%w: invalid e-mail address: %q",
+			ErrInvalidOption, u.Email)
+	}
+	if u.AccessLevel != "" {
+		if _, err := ParseAccessLevel(u.AccessLevel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UsersCreateServices are the Gitlab services needed to create users
+// and add them to a group.
+type UsersCreateServices struct {
+	Groups       gitlab_util.GroupFinder         /* was *gitlab.GroupsService */
+	GroupMembers gitlab_util.GroupMembersManager /* was *gitlab.GroupMembersService */
+	Users        gitlab_util.UserCreator         /* was *gitlab.UsersService */
+}
+
+// CreateUser creates the user which must already have been validated.
+// The user is sent an e-mail with a link to set the password.  If the
+// user has an access level, the user is then added to the group which
+// must not be nil.  If dryRun is true, this function only prints what
+// it would do without actually doing it.
+func CreateUser(
+	ctx context.Context,
+	s UsersCreateServices,
+	u *NewUser,
+	group *gitlab.Group,
+	dryRun bool,
+) (*gitlab.User, error) {
+
+	// Create the user.
+	user := &gitlab.User{
+		Username: u.Username,
+		Name:     u.Name,
+		Email:    u.Email,
+	}
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(u.Username)
+	logging.Printf("- Creating user: %q ... ", u.Username)
+	if !dryRun {
+		var err error
+		user, _, err = s.Users.CreateUser(
+			&gitlab.CreateUserOptions{
+				Username:      gitlab.Ptr(u.Username),
+				Name:          gitlab.Ptr(u.Name),
+				Email:         gitlab.Ptr(u.Email),
+				ResetPassword: gitlab.Ptr(true),
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			logging.Printf("Failed.\n")
+			err = fmt.Errorf("CreateUser: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(u.Username, err)
+			return nil, err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(u.Username)
+
+	// Add the user to the group.
+	if u.AccessLevel == "" {
+		return user, nil
+	}
+	level, err := ParseAccessLevel(u.AccessLevel)
+	if err != nil {
+		return user, err
+	}
+	err = AddMembership(ctx,
+		MembershipServices{GroupMembers: s.GroupMembers},
+		&MembershipSource{Type: "group", Path: group.FullPath},
+		xml_users.FromGitlabUser(user),
+		level, "", dryRun)
+	if err != nil {
+		return user, fmt.Errorf("CreateUser: %w", err)
+	}
+	return user, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersCreateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	u := &NewUser{
+		Username:    cmd.options.Username,
+		Name:        cmd.options.Name,
+		Email:       cmd.options.Email,
+		AccessLevel: cmd.options.AccessLevel,
+	}
+	err = u.Validate()
+	if err != nil {
+		return result, err
+	}
+	if (cmd.options.Group == "") != (u.AccessLevel == "") {
+		return result, i18n.Errorf(
+			"%w: --group and --access-level must be set together",
+			ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Find the group.
+	s := UsersCreateServices{
+		Groups:       cmd.client.Groups,
+		GroupMembers: cmd.client.GroupMembers,
+		Users:        cmd.client.Users,
+	}
+	var group *gitlab.Group
+	if cmd.options.Group != "" {
+		group, err = gitlab_util.FindExactGroup(ctx, s.Groups, cmd.options.Group)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Create the user.
+	user, err := CreateUser(ctx, s, u, group, cmd.options.DryRun)
+	if err != nil {
+		result.Fail(u.Username, user, err)
+		return result, err
+	}
+	result.Succeed(u.Username, user)
+	return result, nil
+}
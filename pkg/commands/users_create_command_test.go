@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestUsersCreateIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+
+	// run runs "users create" with the arguments.
+	run := func(args ...string) error {
+		var err error
+		cmd := NewUsersCommand("users", &UsersOptions{}, session)
+		_, _, err = runCommand(t, cmd, append([]string{"create"}, args...))
+		return err
+	}
+
+	// Verify the dry run changes nothing.
+	err := run("--username", "jdoe", "--name", "Jane Doe", "--email", "jdoe@example.com",
+		"--group", "foo", "--access-level", "developer", "--dry-run")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slices.Contains(server.Users(), "jdoe") {
+		t.Errorf("users create --dry-run: created user")
+	}
+
+	// Create the user.
+	err = run("--username", "jdoe", "--name", "Jane Doe", "--email", "jdoe@example.com",
+		"--group", "foo", "--access-level", "developer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"user", true, slices.Contains(server.Users(), "jdoe")},
+		{"access level", gitlab.DeveloperPermissions,
+			server.MemberAccessLevel("group", "foo", "jdoe")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("users create %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"--name", "Rich Roe", "--email", "rroe@example.com"},
+		{"--username", "rroe", "--email", "rroe@example.com"},
+		{"--username", "rroe", "--name", "Rich Roe", "--email", "rroe"},
+		{"--username", "rroe", "--name", "Rich Roe", "--email", "rroe@example.com",
+			"--group", "foo"},
+		{"--username", "rroe", "--name", "Rich Roe", "--email", "rroe@example.com",
+			"--group", "foo", "--access-level", "boss"},
+	} {
+		err := run(args...)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("users create %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
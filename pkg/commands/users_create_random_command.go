@@ -0,0 +1,239 @@
+// This file provides the implementation for the "users create-random"
+// command which creates random users en masse.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersCreateRandomOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersCreateRandomOptions are the options needed by this command.
+type UsersCreateRandomOptions struct {
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// UserBaseName is the base name all new users will have.  The
+	// full username will include random characters after the base
+	// name.  Defaults to "".
+	UserBaseName string `xml:"user-base-name"`
+
+	// UserCount is the number of users to create.  Defaults to 0.
+	UserCount uint64 `xml:"user-count"`
+}
+
+// Initialize initializes this UsersCreateRandomOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *UsersCreateRandomOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n
+	flags.BoolVar(
+		&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --user-base-name
+	flags.StringVar(&opts.UserBaseName, "user-base-name", opts.UserBaseName,
+		i18n.T("base name for new users"))
+
+	// --user-count
+	flags.Uint64Var(&opts.UserCount, "user-count", opts.UserCount,
+		i18n.T("number of new users to create"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersCreateRandomCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersCreateRandomCommand implements the "users create-random"
+// command which creates random users en masse.
+type UsersCreateRandomCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersCreateRandomOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersCreateRandomCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users create-random [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create users en masse with random usernames.  Creating users\n")
+	i18n.Fprintf(out, "    requires an administrator token.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Create-Random Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersCreateRandomCommand returns a new, initialized
+// UsersCreateRandomCommand instance.
+func NewUsersCreateRandomCommand(
+	name string,
+	opts *UsersCreateRandomOptions,
+	session *Session,
+) *UsersCreateRandomCommand {
+
+	// Create the new command.
+	cmd := &UsersCreateRandomCommand{
+		GitlabCommand: GitlabCommand[UsersCreateRandomOptions]{
+			BasicCommand: BasicCommand[UsersCreateRandomOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// CreateRandomUser creates a user whose username is a combination of
+// the user base name and a UUID.  The user gets a random password and
+// an "example.com" e-mail address that does not need to be confirmed.
+// If dryRun is true, this function only prints what it would do
+// without actually doing it.
+func CreateRandomUser(
+	ctx context.Context,
+	s gitlab_util.UserCreator, /* was *gitlab.UsersService */
+	userBaseName string,
+	dryRun bool,
+) (string, error) {
+
+	// Create UUID and use it as the suffix for the new username.
+	username := userBaseName + "-" + uuid.NewString()
+
+	// Set up options for creating the user.
+	opts := gitlab.CreateUserOptions{
+		Username:         gitlab.Ptr(username),
+		Name:             gitlab.Ptr(username),
+		Email:            gitlab.Ptr(username + "@example.com"),
+		Password:         gitlab.Ptr(uuid.NewString()),
+		SkipConfirmation: gitlab.Ptr(true),
+	}
+
+	// Create the user.
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(username)
+	logging.Printf("- Creating user: %q ... ", username)
+	if !dryRun {
+		_, _, err := s.CreateUser(&opts,
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+		if err != nil {
+			err = fmt.Errorf(
+				"CreateUser: %w", gitlab_util.ClassifyError(err))
+			hook.OnError(username, err)
+			return username, err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(username)
+
+	return username, nil
+}
+
+// CreateRandomUsers creates the specified number of users.  The
+// username of each user is a combination of the user base name and a
+// UUID.  If dryRun is true, this function only prints what it would
+// do without actually doing it.
+func CreateRandomUsers(
+	ctx context.Context,
+	result *Result,
+	s gitlab_util.UserCreator, /* was *gitlab.UsersService */
+	userBaseName string,
+	userCount uint64,
+	dryRun bool,
+) error {
+	for i := uint64(0); i < userCount; i++ {
+		username, err := CreateRandomUser(ctx, s, userBaseName, dryRun)
+		if err != nil {
+			result.Fail(username, nil, err)
+			return err
+		}
+		result.Succeed(username, nil)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersCreateRandomCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.UserBaseName == "" {
+		return result, i18n.Errorf("%w: invalid user base name: %q",
+			ErrInvalidOption, cmd.options.UserBaseName)
+	} else if cmd.options.UserCount == 0 {
+		return result, i18n.Errorf("%w: invalid user count: %v",
+			ErrInvalidOption, cmd.options.UserCount)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Create random users.
+	err = CreateRandomUsers(
+		ctx,
+		result,
+		cmd.client.Users,
+		cmd.options.UserBaseName,
+		cmd.options.UserCount,
+		cmd.options.DryRun)
+	return result, err
+}
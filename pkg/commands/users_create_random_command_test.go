@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUsersCreateRandomIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	cmd := NewUsersCommand("users", &UsersOptions{}, session)
+
+	// Create the random users.
+	_, _, err := runCommand(t, cmd, []string{"create-random",
+		"--user-base-name", "random", "--user-count", "3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the users were created.
+	count := 0
+	for _, u := range server.Users() {
+		if strings.HasPrefix(u, "random-") {
+			count++
+		}
+	}
+	if count != 3 {
+		t.Errorf("users create-random: expected=%d  actual=%d", 3, count)
+	}
+}
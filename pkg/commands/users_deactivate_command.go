@@ -0,0 +1,175 @@
+// This file provides the implementation for the "users deactivate"
+// command which deactivates dormant users so they stop counting as
+// billable users.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersDeactivateOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersDeactivateOptions are the options needed by this command.
+type UsersDeactivateOptions struct {
+
+	// Embed the options that control when deactivating the users must
+	// be confirmed.
+	ConfirmationOptions
+
+	// Embed the options that select the users.
+	UserStateOptions
+}
+
+// Initialize initializes this UsersDeactivateOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *UsersDeactivateOptions) Initialize(flags *flag.FlagSet) {
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// -n, --dry-run, --users
+	opts.UserStateOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersDeactivateCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersDeactivateCommand implements the "users deactivate" command
+// which deactivates dormant users so they stop counting as billable
+// users.
+type UsersDeactivateCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersDeactivateOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersDeactivateCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users deactivate [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Deactivate the users in --users so they stop counting as\n")
+	i18n.Fprintf(out, "    billable users.  Gitlab reactivates a deactivated user who\n")
+	i18n.Fprintf(out, "    signs in again and refuses to deactivate users who were\n")
+	i18n.Fprintf(out, "    active recently.  Users who are already deactivated are\n")
+	i18n.Fprintf(out, "    skipped.  Deactivating users requires an administrator token.\n")
+	i18n.Fprintf(out, "    The users are listed and must be confirmed unless the global\n")
+	i18n.Fprintf(out, "    --yes option is passed.  When deactivating more users than\n")
+	i18n.Fprintf(out, "    --confirm-threshold, --i-understand must be passed, and a\n")
+	i18n.Fprintf(out, "    generated token must be typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Deactivate Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersDeactivateCommand returns a new, initialized UsersDeactivateCommand
+// instance.
+func NewUsersDeactivateCommand(
+	name string,
+	opts *UsersDeactivateOptions,
+	session *Session,
+) *UsersDeactivateCommand {
+
+	// Create the new command.
+	cmd := &UsersDeactivateCommand{
+		GitlabCommand: GitlabCommand[UsersDeactivateOptions]{
+			BasicCommand: BasicCommand[UsersDeactivateOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersDeactivateCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Deactivate the users.
+	users, err := cmd.options.GetSelectedUsers(ctx, cmd.client.Users)
+	if err != nil {
+		return result, err
+	}
+	if !cmd.options.DryRun {
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("deactivate"),
+			usernamesToChange(users, "deactivated"), "", cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+	ChangeUserStates(ctx, result, users,
+		UserStateChange{
+			Verb:   i18n.T("Deactivating"),
+			State:  "deactivated",
+			Change: cmd.client.Users.DeactivateUser,
+		},
+		cmd.options.DryRun)
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not deactivate %d user(s)", failed)
+	}
+	return result, nil
+}
@@ -0,0 +1,325 @@
+// This file provides the implementation for the "users import" command
+// which creates the users listed in a CSV file.
+
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersImportOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersImportOptions are the options needed by this command.
+type UsersImportOptions struct {
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// FromCSV is the name of the CSV file holding the users to
+	// create.  Defaults to "".
+	FromCSV string `xml:"from-csv"`
+
+	// Group is the full path of the group to which the new users are
+	// added with the access level in their row.  It is required if
+	// any row has an access level.  Defaults to "".
+	Group string `xml:"group"`
+}
+
+// Initialize initializes this UsersImportOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *UsersImportOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --from-csv
+	flags.StringVar(&opts.FromCSV, "from-csv", opts.FromCSV,
+		i18n.T("name of the CSV file holding the users to create which has "+
+			"the columns username, name, email, and access-level"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the group to which the new users are added"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersImportCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersImportCommand implements the "users import" command which
+// creates the users listed in a CSV file.
+type UsersImportCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersImportOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersImportCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users import [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create the users listed in --from-csv.  The first row of the\n")
+	i18n.Fprintf(out, "    CSV file is a header naming the columns which are username,\n")
+	i18n.Fprintf(out, "    name, email, and the optional access-level.  Users with an\n")
+	i18n.Fprintf(out, "    access level are added to --group.  A row that fails does\n")
+	i18n.Fprintf(out, "    not stop the remaining rows, and the rows that failed are\n")
+	i18n.Fprintf(out, "    reported at the end.  Creating users requires an\n")
+	i18n.Fprintf(out, "    administrator token.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Import Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersImportCommand returns a new, initialized UsersImportCommand
+// instance.
+func NewUsersImportCommand(
+	name string,
+	opts *UsersImportOptions,
+	session *Session,
+) *UsersImportCommand {
+
+	// Create the new command.
+	cmd := &UsersImportCommand{
+		GitlabCommand: GitlabCommand[UsersImportOptions]{
+			BasicCommand: BasicCommand[UsersImportOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ImportRow is a user read from a row of the CSV file.
+type ImportRow struct {
+
+	// Embed the user to create.
+	NewUser
+
+	// Row is the number of the row in the CSV file counting the
+	// header as row 1.
+	Row int
+}
+
+// ReadImportRows reads the users from the CSV file.  The first row is
+// a header naming the columns in any order.  The username, name, and
+// email columns are required while the access-level column is
+// optional.  The rows are not validated.
+func ReadImportRows(fileName string) ([]*ImportRow, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("ReadImportRows: %w", err)
+	}
+	defer f.Close()
+
+	// Read the header.
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, i18n.Errorf("%w: no header in %q", ErrInvalidOption, fileName)
+	} else if err != nil {
+		return nil, fmt.Errorf("ReadImportRows: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+	for _, column := range []string{"username", "name", "email"} {
+		if !slices.Contains(header, column) {
+			return nil, i18n.Errorf("%w: %s: missing column: %q",
+				ErrInvalidOption, fileName, column)
+		}
+	}
+
+	// Read the rows.
+	var rows []*ImportRow
+	for n := 2; ; n++ {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("ReadImportRows: %w", err)
+		}
+		row := &ImportRow{Row: n}
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			switch header[i] {
+			case "username":
+				row.Username = value
+			case "name":
+				row.Name = value
+			case "email":
+				row.Email = value
+			case "access-level":
+				row.AccessLevel = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ImportUsers creates the users in the rows.  A row that fails is
+// recorded in the result, and the remaining rows are still created.
+// If dryRun is true, this function only prints what it would do
+// without actually doing it.
+func ImportUsers(
+	ctx context.Context,
+	result *Result,
+	s UsersCreateServices,
+	rows []*ImportRow,
+	group *gitlab.Group,
+	dryRun bool,
+) error {
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := fmt.Sprintf("row %d", row.Row)
+		if row.Username != "" {
+			name += " (" + row.Username + ")"
+		}
+		err := row.Validate()
+		if err == nil && row.AccessLevel != "" && group == nil {
+			err = i18n.Errorf("%w: access level set without --group",
+				ErrInvalidOption)
+		}
+		if err != nil {
+			result.Fail(name, row, err)
+			continue
+		}
+		user, err := CreateUser(ctx, s, &row.NewUser, group, dryRun)
+		if err != nil {
+			result.Fail(name, user, err)
+			continue
+		}
+		result.Succeed(name, user)
+	}
+	return nil
+}
+
+// PrintImportErrors prints the rows that failed.
+func PrintImportErrors(result *Result) {
+	failed := result.Failed()
+	if len(failed) == 0 {
+		return
+	}
+	fmt.Printf("\n")
+	i18n.Printf("Errors:\n")
+	fmt.Printf("\n")
+	for _, item := range failed {
+		fmt.Printf("  %s: %v\n", item.Name, item.Err)
+	}
+	fmt.Printf("\n")
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersImportCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.FromCSV == "" {
+		return result, i18n.Errorf("%w: CSV file not set", ErrInvalidOption)
+	}
+	rows, err := ReadImportRows(cmd.options.FromCSV)
+	if err != nil {
+		return result, err
+	}
+	if len(rows) == 0 {
+		return result, i18n.Errorf("%w: no users in %q",
+			ErrInvalidOption, cmd.options.FromCSV)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Find the group.
+	s := UsersCreateServices{
+		Groups:       cmd.client.Groups,
+		GroupMembers: cmd.client.GroupMembers,
+		Users:        cmd.client.Users,
+	}
+	var group *gitlab.Group
+	if cmd.options.Group != "" {
+		group, err = gitlab_util.FindExactGroup(ctx, s.Groups, cmd.options.Group)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Create the users and report the rows that failed.
+	err = ImportUsers(ctx, result, s, rows, group, cmd.options.DryRun)
+	PrintImportErrors(result)
+	if err != nil {
+		return result, err
+	}
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not create %d user(s)", failed)
+	}
+	return result, nil
+}
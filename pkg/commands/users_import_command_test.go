@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestUsersImportIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	fileName := filepath.Join(t.TempDir(), "users.csv")
+	err := os.WriteFile(fileName, []byte(
+		"username,name,email,access-level\n"+
+			"jdoe,Jane Doe,jdoe@example.com,developer\n"+
+			"aberns,Alice Berns,aberns@example.com,reporter\n"+
+			"rroe,Rich Roe,rroe,guest\n"+
+			"sbrown,Sam Brown,sbrown@example.com,\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// run runs "users import" with the arguments.
+	run := func(args ...string) (string, error) {
+		var err error
+		cmd := NewUsersCommand("users", &UsersOptions{}, session)
+		output, _, err := runCommand(t, cmd, append([]string{"import"}, args...))
+		return output, err
+	}
+
+	// Verify the dry run changes nothing but still reports the row
+	// that is not valid.
+	_, err = run("--from-csv", fileName, "--group", "foo", "-n")
+	if err == nil || !strings.Contains(err.Error(), "could not create 1 user(s)") {
+		t.Errorf("users import --dry-run: unexpected error: %v", err)
+	}
+	if slices.Contains(server.Users(), "jdoe") {
+		t.Errorf("users import --dry-run: created user")
+	}
+
+	// Import the users.  The existing user and the invalid e-mail
+	// address fail without stopping the other rows.
+	output, err := run("--from-csv", fileName, "--group", "foo")
+	if err == nil || !strings.Contains(err.Error(), "could not create 2 user(s)") {
+		t.Errorf("users import: unexpected error: %v", err)
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"jdoe", true, slices.Contains(server.Users(), "jdoe")},
+		{"sbrown", true, slices.Contains(server.Users(), "sbrown")},
+		{"rroe", false, slices.Contains(server.Users(), "rroe")},
+		{"jdoe access level", gitlab.DeveloperPermissions,
+			server.MemberAccessLevel("group", "foo", "jdoe")},
+		{"sbrown access level", gitlab.AccessLevelValue(0),
+			server.MemberAccessLevel("group", "foo", "sbrown")},
+		{"row 3 reported", true, strings.Contains(output, "row 3 (aberns): ")},
+		{"row 4 reported", true, strings.Contains(output, "row 4 (rroe): ")},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("users import %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+
+	// Verify the invalid options.
+	badFileName := filepath.Join(t.TempDir(), "bad.csv")
+	err = os.WriteFile(badFileName, []byte("username,email\njdoe,jdoe@example.com\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, args := range [][]string{
+		{},
+		{"--from-csv", badFileName},
+	} {
+		_, err := run(args...)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("users import %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+// This file provides the implementation for the "users inactive"
+// command which lists the users without activity since a date and
+// optionally blocks them for bulk offboarding.
+
+package commands
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersInactiveOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersInactiveOptions are the options needed by this command.
+type UsersInactiveOptions struct {
+
+	// Embed the options that control when blocking the users must
+	// be confirmed.
+	ConfirmationOptions
+
+	// Block should cause the inactive users to be blocked after they
+	// are listed.  Defaults to false.
+	Block bool `xml:"block"`
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// ExcludeFileName is the name of a file with one username per
+	// line of users who are never listed or blocked (e.g., service
+	// accounts).  Blank lines and lines starting with "#" are
+	// ignored.  Defaults to "".
+	ExcludeFileName string `xml:"exclude-file"`
+
+	// Since is the date since which the users have not signed in or
+	// otherwise used Gitlab.  Defaults to not set.
+	Since date_arg.DateArg `xml:"since"`
+}
+
+// Initialize initializes this UsersInactiveOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *UsersInactiveOptions) Initialize(flags *flag.FlagSet) {
+
+	// --block
+	flags.BoolVar(&opts.Block, "block", opts.Block,
+		i18n.T("block the inactive users after listing them"))
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --exclude-file
+	flags.StringVar(&opts.ExcludeFileName, "exclude-file", opts.ExcludeFileName,
+		i18n.T("file with one username per line of users who are never "+
+			"listed or blocked"))
+
+	// --since
+	flags.Var(&opts.Since, "since",
+		i18n.T("date since which the users have not been active the form "+
+			"of which is YYYY/MM/DD or YYYY-MM-DD"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersInactiveCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersInactiveCommand implements the "users inactive" command which
+// lists and optionally blocks the users without activity since a date.
+type UsersInactiveCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersInactiveOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersInactiveCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users inactive [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the active users who have not signed in or otherwise\n")
+	i18n.Fprintf(out, "    used Gitlab since --since.  With --block, the listed users\n")
+	i18n.Fprintf(out, "    are also blocked.  Users in --exclude-file (e.g., service\n")
+	i18n.Fprintf(out, "    accounts) are never listed or blocked.  Blocked users and\n")
+	i18n.Fprintf(out, "    bots are skipped.  This requires an administrator token.\n")
+	i18n.Fprintf(out, "    Blocking must be confirmed unless the global --yes option is\n")
+	i18n.Fprintf(out, "    passed.  When blocking more users than --confirm-threshold,\n")
+	i18n.Fprintf(out, "    --i-understand must be passed, and a generated token must be\n")
+	i18n.Fprintf(out, "    typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Inactive Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersInactiveCommand returns a new, initialized
+// UsersInactiveCommand instance.
+func NewUsersInactiveCommand(
+	name string,
+	opts *UsersInactiveOptions,
+	session *Session,
+) *UsersInactiveCommand {
+
+	// Create the new command.
+	cmd := &UsersInactiveCommand{
+		GitlabCommand: GitlabCommand[UsersInactiveOptions]{
+			BasicCommand: BasicCommand[UsersInactiveOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// ReadExcludedUsers reads the file with one username per line and
+// returns the set of usernames in lower case.  Blank lines and lines
+// starting with "#" are ignored.
+func ReadExcludedUsers(fileName string) (map[string]bool, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("ReadExcludedUsers: %w", err)
+	}
+	defer f.Close()
+	result := make(map[string]bool)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result[strings.ToLower(line)] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ReadExcludedUsers: %w", err)
+	}
+	return result, nil
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersInactiveCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	since := time.Time(cmd.options.Since)
+	if since.IsZero() {
+		return result, i18n.Errorf("%w: since not set", ErrInvalidOption)
+	}
+	var excluded map[string]bool
+	if cmd.options.ExcludeFileName != "" {
+		excluded, err = ReadExcludedUsers(cmd.options.ExcludeFileName)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Find the inactive users that are not excluded.
+	found, err := FindInactiveUsers(ctx, UsersReportInactiveServices{
+		Users:  cmd.client.Users,
+		Tokens: cmd.client.PersonalAccessTokens,
+	}, since)
+	if err != nil {
+		return result, err
+	}
+	var inactive []*InactiveUser
+	var users []*gitlab.User
+	for _, u := range found {
+		if excluded[strings.ToLower(u.User.Username)] {
+			continue
+		}
+		inactive = append(inactive, u)
+		users = append(users, u.User)
+	}
+
+	// Print the inactive users.
+	PrintInactiveUsers(os.Stdout, inactive)
+	if !cmd.options.Block {
+		for _, u := range users {
+			result.Succeed(u.Username, u)
+		}
+		return result, nil
+	}
+
+	// Block the inactive users.
+	if !cmd.options.DryRun {
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("block"),
+			usernamesToChange(users, "blocked"), "", cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+	ChangeUserStates(ctx, result, users,
+		UserStateChange{
+			Verb:   i18n.T("Blocking"),
+			State:  "blocked",
+			Change: cmd.client.Users.BlockUser,
+		},
+		cmd.options.DryRun)
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not block %d user(s)", failed)
+	}
+	return result, nil
+}
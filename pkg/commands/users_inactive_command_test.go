@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsersInactiveIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	now := time.Now()
+	server.AddUser("cdavis", "Carol Davis", "cdavis@example.com")
+	server.AddUser("svc-ci", "CI Service", "svc-ci@example.com")
+	server.SetSignIn("aberns", now.AddDate(0, 0, -10))
+	server.SetSignIn("bcrocket", now.AddDate(0, 0, -200))
+	server.SetSignIn("cdavis", now.AddDate(0, 0, -300))
+	server.SetSignIn("svc-ci", now.AddDate(0, 0, -400))
+	excludeFileName := filepath.Join(t.TempDir(), "exclude.txt")
+	err := os.WriteFile(excludeFileName, []byte("# Service accounts\n\nSVC-CI\n"), 0o644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	since := now.AddDate(0, 0, -90).Format("2006-01-02")
+
+	// run runs "users inactive" with the arguments and returns the
+	// usernames in the listing.
+	run := func(args ...string) ([]string, error) {
+		var err error
+		session := NewSessionWithClient(server.Client(t))
+		session.globalOpts.Yes = true
+		cmd := NewUsersCommand("users", &UsersOptions{}, session)
+		out, _, err := runCommand(t, cmd, append([]string{"inactive"}, args...))
+		var usernames []string
+		for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+			if fields := strings.Fields(line); len(fields) >= 4 && !strings.HasPrefix(line, "-") {
+				usernames = append(usernames, fields[3])
+			}
+		}
+		return usernames, err
+	}
+	states := func() []string {
+		var result []string
+		for _, u := range []string{"aberns", "bcrocket", "cdavis", "svc-ci"} {
+			result = append(result, server.UserState(u))
+		}
+		return result
+	}
+
+	// List, dry-run, and block the inactive users.
+	listed, listErr := run("--since", since, "--exclude-file", excludeFileName)
+	dryRun, dryRunErr := run("--since", since, "--exclude-file", excludeFileName, "--block", "-n")
+	dryRunStates := states()
+	_, blockErr := run("--since", since, "--exclude-file", excludeFileName, "--block")
+	blockStates := states()
+	_, sinceErr := run()
+
+	// Verify the results.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"list", []string{"cdavis", "bcrocket"}, listed},
+		{"list error", nil, listErr},
+		{"dry-run", []string{"cdavis", "bcrocket"}, dryRun},
+		{"dry-run error", nil, dryRunErr},
+		{"dry-run states", []string{"active", "active", "active", "active"}, dryRunStates},
+		{"block error", nil, blockErr},
+		{"block states", []string{"active", "blocked", "blocked", "active"}, blockStates},
+		{"since not set", true, errors.Is(sinceErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("users inactive %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,429 @@
+// This file provides the implementation for the "users list" command
+// which lists or searches for specific users so they can be used with
+// other commands.
+
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/jalitriver/gitlab-cmds/pkg/xml_users"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersListOptions are the options needed by this command.
+type UsersListOptions struct {
+
+	// ActiveAfter is the date after which users must have been
+	// active in order to be listed.
+	ActiveAfter date_arg.DateArg `xml:"active-after"`
+
+	// BlockedOnly controls whether only blocked users are listed.
+	BlockedOnly bool `xml:"blocked-only"`
+
+	// CreatedDate is the date after which users must have been
+	// created in order to be listed.
+	CreatedAfter date_arg.DateArg `xml:"created-after"`
+
+	// CreatedBefore is the date before which users must have been
+	// created in order to be listed.
+	CreatedBefore date_arg.DateArg `xml:"created-before"`
+
+	// Format is the format in which the users are printed which is
+	// either "table" or "csv".  For "csv", the CSV is written to
+	// OutputFileName instead if it is set.  Defaults to "table".
+	Format string `xml:"format"`
+
+	// OutputFileName is the name of XML output file to which users
+	// will be appended or, if Format is "csv", the name of the CSV
+	// file that is written.  If empty, no output file is written, but
+	// there will still be logging to the console.  If set to "-", the
+	// XML or CSV output will be written to os.Stdout.
+	OutputFileName string `xml:"output-file-name"`
+
+	// MatchSubstrings controls whether all substrings matches are
+	// reported instead of only reporting exact matches.
+	MatchSubstrings bool `xml:"match-substrings"`
+
+	// Users (for the --users option)
+	Users string_slice.StringSlice `xml:"users>user"`
+}
+
+// Initialize initializes this UsersListOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *UsersListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --active-after
+	flags.Var(&opts.ActiveAfter, "active-after",
+		i18n.T("date after which users must have been active to be listed "+
+			"the form of which is YYYY/MM/DD or YYYY-MM-DD"))
+
+	// --blocked-only
+	flags.BoolVar(&opts.BlockedOnly, "blocked-only", opts.BlockedOnly,
+		i18n.T("whether only blocked users are listed"))
+
+	// --created-after
+	flags.Var(&opts.CreatedAfter, "created-after",
+		i18n.T("date after which users not specified by user ID must have been "+
+			"created to be listed the form of which is YYYY/MM/DD or "+
+			"YYYY-MM-DD"))
+
+	// --created-before
+	flags.Var(&opts.CreatedBefore, "created-before",
+		i18n.T("date before which users must have been created to be listed "+
+			"the form of which is YYYY/MM/DD or YYYY-MM-DD"))
+
+	// --format
+	if opts.Format == "" {
+		opts.Format = "table"
+	}
+	flags.StringVar(&opts.Format, "format", opts.Format,
+		i18n.T("format in which the users are printed which is either table or csv"))
+
+	// --match-substrings
+	flags.BoolVar(&opts.MatchSubstrings, "match-substrings", opts.MatchSubstrings,
+		i18n.T("whether all substrings matches are reported instead of reporting "+
+			"only exact matches"))
+
+	// -o
+	flags.StringVar(&opts.OutputFileName, "o", opts.OutputFileName,
+		i18n.T("name of XML output file to which users will be appended "+
+			"or of the CSV file for --format csv"))
+
+	// --out
+	flags.StringVar(&opts.OutputFileName, "out", opts.OutputFileName,
+		i18n.T("name of XML output file to which users will be appended "+
+			"or of the CSV file for --format csv"))
+
+	// --users
+	flags.Var(&opts.Users, "users",
+		i18n.T("comma-separated list of user IDs, names, usernames, or "+
+			"e-mail addresses"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersListCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersListCommand implements the "users list" command which lists
+// (or looks up) specific users so they can be used with other
+// commands.
+type UsersListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List users matching search strings and optionally\n")
+	i18n.Fprintf(out, "    save the list of users to file.  The --active-after,\n")
+	i18n.Fprintf(out, "    --blocked-only, --created-after, and --created-before\n")
+	i18n.Fprintf(out, "    filters can be combined to select users by lifecycle.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    WARNING: At the time of writing, listing users by e-mail\n")
+	i18n.Fprintf(out, "    address and the --created-after flag are not working\n")
+	i18n.Fprintf(out, "    with Gitlab CE.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersListCommand returns a new, initialized
+// UsersListCommand instance.
+func NewUsersListCommand(
+	name string,
+	opts *UsersListOptions,
+	session *Session,
+) *UsersListCommand {
+
+	// Create the new command.
+	cmd := &UsersListCommand{
+		GitlabCommand: GitlabCommand[UsersListOptions]{
+			BasicCommand: BasicCommand[UsersListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// printUser prints the user.  If index is zero, the header is printed
+// on the line above the user.
+func printUser(index int, user *gitlab.User) error {
+
+	// Print the header if necessary.
+	if index == 0 {
+		_, err := fmt.Printf("%8s  %-16s  %-24s  %-24s\n",
+			"ID", "Username", "Name", "Email")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Print the user.
+	_, err := fmt.Printf("%8d  %-16s  %-24s  %-24s\n",
+		user.ID, user.Username, user.Name, user.Email)
+
+	return err
+}
+
+// WriteUsersCSV writes the users as CSV with a header row.
+func WriteUsersCSV(out io.Writer, users []*gitlab.User) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"id", "username", "name", "email", "state", "created_at"})
+	for _, u := range users {
+		createdAt := ""
+		if u.CreatedAt != nil {
+			createdAt = u.CreatedAt.Format(time.RFC3339)
+		}
+		w.Write([]string{fmt.Sprint(u.ID), u.Username, u.Name, u.Email,
+			u.State, createdAt})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("WriteUsersCSV: %w", err)
+	}
+	return nil
+}
+
+// WriteUsersCSVFile writes the users as CSV with a header row to the
+// file.  If fname is "-", the CSV is written to os.Stdout.
+func WriteUsersCSVFile(fname string, users []*gitlab.User) error {
+	if fname == "-" {
+		return WriteUsersCSV(os.Stdout, users)
+	}
+	f, err := os.Create(fname)
+	if err != nil {
+		return fmt.Errorf("WriteUsersCSVFile: %w", err)
+	}
+	err = WriteUsersCSV(f, users)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("WriteUsersCSVFile: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("WriteUsersCSVFile: %w", err)
+	}
+	return nil
+}
+
+// MatchesUser returns true if the user passes the --active-after,
+// --blocked-only, --created-after, and --created-before filters.  The
+// filters are applied here even though most of them are also passed to
+// Gitlab because not all versions of Gitlab support them.  Because
+// Gitlab only returns the creation date to administrators, users
+// without one are assumed to have been filtered by Gitlab.
+func (opts *UsersListOptions) MatchesUser(u *gitlab.User) bool {
+	activeAfter := time.Time(opts.ActiveAfter)
+	createdAfter := time.Time(opts.CreatedAfter)
+	createdBefore := time.Time(opts.CreatedBefore)
+	if !activeAfter.IsZero() {
+		if u.LastActivityOn == nil || !time.Time(*u.LastActivityOn).After(activeAfter) {
+			return false
+		}
+	}
+	if opts.BlockedOnly && u.State != "blocked" {
+		return false
+	}
+	if !createdAfter.IsZero() {
+		if u.CreatedAt != nil && !u.CreatedAt.After(createdAfter) {
+			return false
+		}
+	}
+	if !createdBefore.IsZero() {
+		if u.CreatedAt != nil && !u.CreatedAt.Before(createdBefore) {
+			return false
+		}
+	}
+	return true
+}
+
+// ListUsersOptions returns the options for listing all users which
+// pass the filters that Gitlab can apply.
+func (opts *UsersListOptions) ListUsersOptions() *gitlab.ListUsersOptions {
+	result := &gitlab.ListUsersOptions{}
+	if t := time.Time(opts.CreatedAfter); !t.IsZero() {
+		result.CreatedAfter = &t
+	}
+	if t := time.Time(opts.CreatedBefore); !t.IsZero() {
+		result.CreatedBefore = &t
+	}
+	if opts.BlockedOnly {
+		result.Blocked = gitlab.Ptr(true)
+	}
+	return result
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+	var found []*gitlab.User
+	var users []*gitlab.User
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Format != "table" && cmd.options.Format != "csv" {
+		return result, i18n.Errorf("%w: invalid format: %q",
+			ErrInvalidOption, cmd.options.Format)
+	}
+
+	// The table is printed as the users are found.  JSON and CSV are
+	// printed at the end.
+	printTable := !cmd.session.OutputJSON() && cmd.options.Format == "table"
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// If users were specified, try to find exact matches for the
+	// "user" search strings.  If an exact match is found, add them to
+	// the "found" list so we can write them to file before exiting if
+	// necessary.
+	if len(cmd.options.Users) > 0 {
+		i := 0
+		for _, user := range cmd.options.Users {
+			users, err = gitlab_util.FindUsers(
+				ctx,
+				cmd.client.Users,
+				user,
+				!cmd.options.MatchSubstrings,
+				time.Time(cmd.options.CreatedAfter))
+			if err != nil {
+				err = i18n.Errorf("unable to find user: %q: %w", user, err)
+				result.Fail(user, nil, err)
+				return result, err
+			}
+			for _, u := range users {
+				if !cmd.options.MatchesUser(u) {
+					continue
+				}
+				found = append(found, u)
+				i++
+				if printTable {
+					err = printUser(i-1, u)
+					if err != nil {
+						return result, err
+					}
+				}
+				result.Succeed(u.Username, u)
+			}
+		}
+	}
+
+	// If no users were specified, list all users.
+	if len(cmd.options.Users) == 0 {
+		i := 0
+		err = gitlab_util.ForEachUserWithOptions(
+			ctx,
+			cmd.client.Users,
+			cmd.options.ListUsersOptions(),
+			func(u *gitlab.User) (bool, error) {
+				if !cmd.options.MatchesUser(u) {
+					return true, nil
+				}
+				found = append(found, u)
+				i++
+				if printTable {
+					err := printUser(i-1, u)
+					if err != nil {
+						return false, err
+					}
+				}
+				result.Succeed(u.Username, u)
+				return true, nil
+			})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Print the users as JSON or as CSV if they are not written to
+	// the output file.
+	if cmd.session.OutputJSON() {
+		if found == nil {
+			found = []*gitlab.User{}
+		}
+		err = writeJSON(os.Stdout, found)
+		if err != nil {
+			return result, err
+		}
+	} else if cmd.options.Format == "csv" && cmd.options.OutputFileName == "" {
+		err = WriteUsersCSV(os.Stdout, found)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Save results to output file.
+	if cmd.options.OutputFileName != "" && cmd.options.Format == "csv" {
+		err = WriteUsersCSVFile(cmd.options.OutputFileName, found)
+		if err != nil {
+			return result, err
+		}
+	} else if cmd.options.OutputFileName != "" {
+		err = xml_users.WriteUsers(cmd.options.OutputFileName, found)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUsersListIntegration(t *testing.T) {
+	_, session := newFakeSession(t)
+	cmd := NewUsersCommand("users", &UsersOptions{}, session)
+
+	// Look up a user by username.
+	output, _, err := runCommand(t, cmd, []string{"list", "--users", "bcrocket"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(output, "bcrocket") || strings.Contains(output, "aberns") {
+		t.Errorf("users list: unexpected output: %q", output)
+	}
+}
+
+func TestUsersListKeysetIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddUser("cdragun", "Carol Dragun", "cdragun@example.com")
+	server.AddUser("delliot", "Dave Elliot", "delliot@example.com")
+	server.AddUser("efrank", "Eve Frank", "efrank@example.com")
+	session := NewSessionWithClient(server.Client(t))
+	expected := []string{"aberns", "bcrocket", "cdragun", "delliot", "efrank"}
+
+	// list lists all the users returning their usernames.
+	list := func() []string {
+		cmd := NewUsersCommand("users", &UsersOptions{}, session)
+		_, result, err := runCommand(t, cmd, []string{"list"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var usernames []string
+		for _, item := range result.Succeeded() {
+			usernames = append(usernames, item.Name)
+		}
+		return usernames
+	}
+
+	// List the users across several pages using keyset pagination.
+	actual := list()
+	if !slices.Equal(actual, expected) {
+		t.Errorf("users list keyset: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Fall back to offset pagination when keyset pagination is
+	// rejected.
+	server.InjectError("GET", "/users", http.StatusMethodNotAllowed, 1)
+	actual = list()
+	if !slices.Equal(actual, expected) {
+		t.Errorf("users list offset: expected=%v  actual=%v", expected, actual)
+	}
+}
+
+func TestUsersListCSVIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.SetUserCreated("aberns", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	session := NewSessionWithClient(server.Client(t))
+
+	// parse parses the CSV and verifies the header and usernames.
+	parse := func(content string) [][]string {
+		records, err := csv.NewReader(strings.NewReader(content)).ReadAll()
+		if err != nil {
+			t.Fatalf("users list --format csv: invalid CSV: %v: %q", err, content)
+		}
+		expected := []string{"id", "username", "name", "email", "state", "created_at"}
+		if len(records) != 3 || !slices.Equal(records[0], expected) {
+			t.Fatalf("users list --format csv: unexpected CSV: %q", content)
+		}
+		if records[1][1] != "aberns" || records[2][1] != "bcrocket" {
+			t.Errorf("users list --format csv: unexpected users: %q", records)
+		}
+		return records
+	}
+
+	// Print the users as CSV.
+	cmd := NewUsersCommand("users", &UsersOptions{}, session)
+	output, _, err := runCommand(t, cmd, []string{"list", "--format", "csv"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records := parse(output)
+	if actual := records[1][5]; actual != "2020-01-02T03:04:05Z" {
+		t.Errorf("users list --format csv: created_at: expected=%q  actual=%q",
+			"2020-01-02T03:04:05Z", actual)
+	}
+
+	// Write the users to a CSV file.
+	fname := filepath.Join(t.TempDir(), "users.csv")
+	cmd = NewUsersCommand("users", &UsersOptions{}, session)
+	output, _, err = runCommand(t, cmd, []string{"list", "--format", "csv", "-o", fname})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if output != "" {
+		t.Errorf("users list --format csv -o: unexpected output: %q", output)
+	}
+	content, err := os.ReadFile(fname)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parse(string(content))
+
+	// Write the users to stdout with "-o -".
+	cmd = NewUsersCommand("users", &UsersOptions{}, session)
+	output, _, err = runCommand(t, cmd, []string{"list", "--format", "csv", "-o", "-"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parse(output)
+
+	// Verify an invalid format is rejected.
+	cmd = NewUsersCommand("users", &UsersOptions{}, session)
+	_, err = cmd.Run(context.Background(), []string{"list", "--format", "xls"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("users list --format xls: expected ErrInvalidOption: %v", err)
+	}
+}
+
+func TestUsersListFiltersIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddUser("cdavis", "Carol Davis", "cdavis@example.com")
+	server.SetUserCreated("aberns", time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local))
+	server.SetUserCreated("bcrocket", time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local))
+	server.SetUserCreated("cdavis", time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local))
+	server.SetUserActivity("aberns", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	server.SetUserActivity("bcrocket", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))
+	server.BlockUser("bcrocket")
+	session := NewSessionWithClient(server.Client(t))
+
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"--created-after", "2021-01-01"}, []string{"bcrocket", "cdavis"}},
+		{[]string{"--created-before", "2023-01-01"}, []string{"aberns", "bcrocket"}},
+		{[]string{"--created-after", "2021-01-01", "--created-before", "2023-01-01"},
+			[]string{"bcrocket"}},
+		{[]string{"--active-after", "2024-01-01"}, []string{"aberns"}},
+		{[]string{"--blocked-only"}, []string{"bcrocket"}},
+		{[]string{"--blocked-only", "--active-after", "2024-01-01"}, nil},
+		{[]string{"--users", "cdavis", "--created-before", "2023-01-01"}, nil},
+	}
+	for _, d := range data {
+		cmd := NewUsersCommand("users", &UsersOptions{}, session)
+		_, result, err := runCommand(t, cmd, append([]string{"list"}, d.args...))
+		if err != nil {
+			t.Fatalf("users list %v: unexpected error: %v", d.args, err)
+		}
+		var actual []string
+		for _, item := range result.Succeeded() {
+			actual = append(actual, item.Name)
+		}
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("users list %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// This file provides the implementation for the "users
+// membership-report" command which reports every group and project
+// membership of each user beneath a root group for access reviews.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersMembershipReportOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersMembershipReportOptions are the options needed by this command.
+type UsersMembershipReportOptions struct {
+
+	// Format is the format of the report which is either "csv" or
+	// "json".  The global --output json option also selects "json".
+	// Defaults to "csv".
+	Format string `xml:"format"`
+
+	// Group is the full path of the root group beneath which the
+	// memberships are reported.  Defaults to "".
+	Group string `xml:"group"`
+
+	// OutputFileName is the name of the file to which the report is
+	// written.  Defaults to "" which means the report is written to
+	// os.Stdout.
+	OutputFileName string `xml:"output-file-name"`
+}
+
+// Initialize initializes this UsersMembershipReportOptions instance
+// so it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *UsersMembershipReportOptions) Initialize(flags *flag.FlagSet) {
+
+	// --format
+	if opts.Format == "" {
+		opts.Format = "csv"
+	}
+	flags.StringVar(&opts.Format, "format", opts.Format,
+		i18n.T("format of the report which is either csv or json"))
+
+	// --group
+	flags.StringVar(&opts.Group, "group", opts.Group,
+		i18n.T("full path of the root group beneath which memberships are reported"))
+
+	// -o
+	flags.StringVar(&opts.OutputFileName, "o", opts.OutputFileName,
+		i18n.T("file to which the report is written instead of stdout"))
+
+	// --output
+	flags.StringVar(&opts.OutputFileName, "output", opts.OutputFileName,
+		i18n.T("file to which the report is written instead of stdout"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersMembershipReportCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersMembershipReportCommand implements the "users
+// membership-report" command which reports the memberships of each
+// user beneath a root group.
+type UsersMembershipReportCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersMembershipReportOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersMembershipReportCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users membership-report [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report every membership of each user in the root group\n")
+	i18n.Fprintf(out, "    and in the groups and projects beneath it with the access\n")
+	i18n.Fprintf(out, "    level, whether the membership is direct or inherited, and\n")
+	i18n.Fprintf(out, "    the date the membership expires.  The report is sorted by\n")
+	i18n.Fprintf(out, "    username and written as CSV with one row per membership or\n")
+	i18n.Fprintf(out, "    as JSON with one object per user.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Membership Report Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersMembershipReportCommand returns a new, initialized
+// UsersMembershipReportCommand instance.
+func NewUsersMembershipReportCommand(
+	name string,
+	opts *UsersMembershipReportOptions,
+	session *Session,
+) *UsersMembershipReportCommand {
+
+	// Create the new command.
+	cmd := &UsersMembershipReportCommand{
+		GitlabCommand: GitlabCommand[UsersMembershipReportOptions]{
+			BasicCommand: BasicCommand[UsersMembershipReportOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// UserMembership is a single membership of a user in the JSON report.
+type UserMembership struct {
+
+	// SourceType is either "group" or "project".
+	SourceType string `json:"source_type"`
+
+	// Source is the full path of the group or project.
+	Source string `json:"source"`
+
+	// AccessLevel is the name of the access level (e.g.,
+	// "Developer").
+	AccessLevel string `json:"access_level"`
+
+	// Membership is either "direct" or "inherited".
+	Membership string `json:"membership"`
+
+	// ExpiresAt is the date on which the membership expires or "" if
+	// it does not expire.
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// UserMemberships are the memberships of a single user in the JSON
+// report.
+type UserMemberships struct {
+
+	// UserID is the ID of the user.
+	UserID int `json:"user_id"`
+
+	// Username is the username of the user.
+	Username string `json:"username"`
+
+	// Name is the name of the user.
+	Name string `json:"name"`
+
+	// Memberships are the memberships of the user sorted by the full
+	// path of the group or project.
+	Memberships []*UserMembership `json:"memberships"`
+}
+
+// SortMembershipsByUser sorts the memberships by username and then by
+// the full path of the group or project.
+func SortMembershipsByUser(memberships []*Membership) {
+	slices.SortStableFunc(memberships, func(a, b *Membership) int {
+		if c := strings.Compare(a.Username, b.Username); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Source, b.Source)
+	})
+}
+
+// GroupMembershipsByUser returns the memberships grouped by user.  The
+// memberships must already be sorted by SortMembershipsByUser().
+func GroupMembershipsByUser(memberships []*Membership) []*UserMemberships {
+	var result []*UserMemberships
+	for _, m := range memberships {
+		if len(result) == 0 || result[len(result)-1].UserID != m.UserID {
+			result = append(result, &UserMemberships{
+				UserID:   m.UserID,
+				Username: m.Username,
+				Name:     m.Name,
+			})
+		}
+		membership := "inherited"
+		if m.Direct {
+			membership = "direct"
+		}
+		expiresAt := ""
+		if m.ExpiresAt != nil {
+			expiresAt = m.ExpiresAt.String()
+		}
+		user := result[len(result)-1]
+		user.Memberships = append(user.Memberships, &UserMembership{
+			SourceType:  m.SourceType,
+			Source:      m.Source,
+			AccessLevel: gitlab_util.AccessLevelName(m.AccessLevel),
+			Membership:  membership,
+			ExpiresAt:   expiresAt,
+		})
+	}
+	return result
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersMembershipReportCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Group == "" {
+		return result, i18n.Errorf("%w: group not set", ErrInvalidOption)
+	}
+	format := cmd.options.Format
+	if cmd.session.OutputJSON() {
+		format = "json"
+	}
+	if format != "csv" && format != "json" {
+		return result, i18n.Errorf("%w: invalid format: %q",
+			ErrInvalidOption, cmd.options.Format)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Get the memberships.
+	memberships, err := GetAllMemberships(ctx, result, AccessReviewServices{
+		Groups:         cmd.client.Groups,
+		ProjectMembers: cmd.client.ProjectMembers,
+	}, cmd.options.Group)
+	if err != nil {
+		return result, err
+	}
+	SortMembershipsByUser(memberships)
+
+	// Open the output file.
+	out := io.Writer(os.Stdout)
+	if cmd.options.OutputFileName != "" {
+		f, err := os.Create(cmd.options.OutputFileName)
+		if err != nil {
+			return result, err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Write the report.
+	if format == "json" {
+		users := GroupMembershipsByUser(memberships)
+		if users == nil {
+			users = []*UserMemberships{}
+		}
+		return result, writeJSON(out, users)
+	}
+	return result, WriteMembershipsCSV(out, memberships)
+}
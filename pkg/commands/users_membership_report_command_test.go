@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestUsersMembershipReportIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddGroupMember("foo/bar", "bcrocket", gitlab.DeveloperPermissions)
+	server.SetMemberExpiry("group", "foo/bar", "bcrocket",
+		time.Date(2030, time.January, 31, 0, 0, 0, 0, time.UTC))
+	server.AddProjectMember("foo/alpha", "bcrocket", gitlab.MaintainerPermissions)
+	server.AddProjectMember("foo/beta", "aberns", gitlab.ReporterPermissions)
+	run := func(session *Session, args ...string) (string, error) {
+		cmd := NewUsersCommand("users", &UsersOptions{}, session)
+		output, _, err := runCommand(t, cmd, args)
+		return output, err
+	}
+
+	// Write the report as CSV and as JSON.
+	csvOutput, err := run(NewSessionWithClient(server.Client(t)),
+		"membership-report", "--group", "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	jsonSession := NewSessionWithClient(server.Client(t))
+	jsonSession.globalOpts.Output = OutputJSON
+	jsonOutput, err := run(jsonSession, "membership-report", "--group", "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var users []*UserMemberships
+	err = json.Unmarshal([]byte(jsonOutput), &users)
+	if err != nil {
+		t.Fatalf("unexpected error: %v: %s", err, jsonOutput)
+	}
+	_, formatErr := run(NewSessionWithClient(server.Client(t)),
+		"membership-report", "--group", "foo", "--format", "xml")
+
+	// Verify the report.
+	usernames := []string{}
+	var bcrocket []string
+	for _, u := range users {
+		usernames = append(usernames, u.Username)
+		if u.Username == "bcrocket" {
+			for _, m := range u.Memberships {
+				bcrocket = append(bcrocket, fmt.Sprintf("%s:%s:%s:%s",
+					m.Source, m.AccessLevel, m.Membership, m.ExpiresAt))
+			}
+		}
+	}
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"csv", "username,name,source_type,source,access_level,membership,expires_at\n" +
+			"aberns,Alice Berns,project,foo/beta,Reporter,direct,\n" +
+			"bcrocket,Bob Crocket,project,foo/alpha,Maintainer,direct,\n" +
+			"bcrocket,Bob Crocket,group,foo/bar,Developer,direct,2030-01-31\n" +
+			"bcrocket,Bob Crocket,project,foo/bar/delta,Developer,inherited,2030-01-31\n" +
+			"bcrocket,Bob Crocket,project,foo/bar/test-epsilon,Developer,inherited,2030-01-31\n",
+			csvOutput},
+		{"json users", []string{"aberns", "bcrocket"}, usernames},
+		{"json memberships", []string{
+			"foo/alpha:Maintainer:direct:",
+			"foo/bar:Developer:direct:2030-01-31",
+			"foo/bar/delta:Developer:inherited:2030-01-31",
+			"foo/bar/test-epsilon:Developer:inherited:2030-01-31",
+		}, bcrocket},
+		{"invalid format", true, errors.Is(formatErr, ErrInvalidOption)},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("users membership-report %s: expected=%q  actual=%q",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// This file provides the implementation for the "users report"
+// command which provides subcommands that report on users.
+//
+// If you need to add a new subcommand, do the following:
+//
+//   1) Create the new subcommand similar to
+//      pkg/commands/projects_command.go if the subcommand
+//      will have its own set of subcommands or similar to
+//      pkg/commands/projects_list_command.go if the
+//      subcommand will actually do something.
+//
+//   2) Add the resulting new options struct to the Options struct
+//      below so the options can also be specified in the options.xml
+//      file.
+//
+//   3) Add the new subcommand as demonstrated in
+//      UsersCommand.addSubcmds().
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersReportOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersReportOptions are the options needed by this command.
+type UsersReportOptions struct {
+
+	// Options for the "users report contributions" command.
+	UsersReportContributionsOpts UsersReportContributionsOptions `xml:"contributions-options"`
+
+	// Options for the "users report inactive" command.
+	UsersReportInactiveOpts UsersReportInactiveOptions `xml:"inactive-options"`
+}
+
+// Initialize initializes this UsersReportOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *UsersReportOptions) Initialize(flags *flag.FlagSet) {
+	// empty
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersReportCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersReportCommand provides subcommands that report on Gitlab
+// users.
+type UsersReportCommand struct {
+
+	// Embed the Command members.
+	ParentCommand[UsersReportOptions]
+}
+
+// Usage prints the main usage message to the output writer.  If
+// err is not nil, it will be printed before the main output.
+func (cmd *UsersReportCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users report [subcmd]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Command for reporting on Gitlab users.\n")
+	fmt.Fprintf(out, "\n")
+	fmt.Fprintf(out, "Subcommands:\n")
+	fmt.Fprintf(out, "\n")
+	for _, subcmd := range cmd.SortedCommandNames() {
+		fmt.Fprintf(out, "  %s\n", subcmd)
+	}
+	fmt.Fprintf(out, "\n")
+}
+
+// addSubcmds adds the subcommands for this command.
+func (cmd *UsersReportCommand) addSubcmds(session *Session) {
+	cmd.subcmds["contributions"] = NewUsersReportContributionsCommand(
+		"contributions", &cmd.options.UsersReportContributionsOpts, session)
+	cmd.subcmds["inactive"] = NewUsersReportInactiveCommand(
+		"inactive", &cmd.options.UsersReportInactiveOpts, session)
+}
+
+// NewUsersReportCommand returns a new, initialized
+// UsersReportCommand instance having the specified name.
+func NewUsersReportCommand(
+	name string,
+	opts *UsersReportOptions,
+	session *Session,
+) *UsersReportCommand {
+
+	// Create the new command.
+	cmd := &UsersReportCommand{
+		ParentCommand: ParentCommand[UsersReportOptions]{
+			BasicCommand: BasicCommand[UsersReportOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			subcmds: make(map[string]Runner),
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	cmd.options.Initialize(cmd.flags)
+
+	// Add the subcommands.
+	cmd.addSubcmds(session)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersReportCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+
+	// Parse command-line arguments.
+	err = cmd.flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	// Dispatch the subcommand specified by the remaining arguments.
+	return cmd.DispatchSubcommand(ctx, cmd.flags.Args())
+}
@@ -0,0 +1,382 @@
+// This file provides the implementation for the "users report
+// contributions" command which reports the commits, merge requests,
+// and reviews of each user in the projects under a group over a date
+// range for engineering metrics.
+
+package commands
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersReportContributionsOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersReportContributionsOptions are the options needed by this
+// command.
+type UsersReportContributionsOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// Format is the format of the report which is either "table" or
+	// "csv".  Defaults to "table".
+	Format string `xml:"format"`
+
+	// OutputFileName is the name of the file to which the report is
+	// written.  Defaults to "" which means the report is written to
+	// os.Stdout.
+	OutputFileName string `xml:"output-file-name"`
+
+	// Since is the first day of the date range.  Defaults to 30 days
+	// before Until.
+	Since date_arg.DateArg `xml:"since"`
+
+	// Until is the last day of the date range.  Defaults to today.
+	Until date_arg.DateArg `xml:"until"`
+}
+
+// Initialize initializes this UsersReportContributionsOptions
+// instance so it can be used with the "flag" package to parse the
+// command-line arguments.
+func (opts *UsersReportContributionsOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --format
+	if opts.Format == "" {
+		opts.Format = "table"
+	}
+	flags.StringVar(&opts.Format, "format", opts.Format,
+		i18n.T("format of the report which is either table or csv"))
+
+	// -o
+	flags.StringVar(&opts.OutputFileName, "o", opts.OutputFileName,
+		i18n.T("file to which the report is written instead of stdout"))
+
+	// --output
+	flags.StringVar(&opts.OutputFileName, "output", opts.OutputFileName,
+		i18n.T("file to which the report is written instead of stdout"))
+
+	// --since
+	flags.Var(&opts.Since, "since",
+		i18n.T("first day of the date range the form of which is "+
+			"YYYY/MM/DD or YYYY-MM-DD (defaults to 30 days before --until)"))
+
+	// --until
+	flags.Var(&opts.Until, "until",
+		i18n.T("last day of the date range the form of which is "+
+			"YYYY/MM/DD or YYYY-MM-DD (defaults to today)"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersReportContributionsCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersReportContributionsCommand implements the "users report
+// contributions" command which reports the contributions of each user.
+type UsersReportContributionsCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersReportContributionsOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersReportContributionsCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users report contributions [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the number of commits pushed, merge requests opened\n")
+	i18n.Fprintf(out, "    and merged, and merge requests reviewed by each user in the\n")
+	i18n.Fprintf(out, "    selected projects from --since through --until.  Reviews are\n")
+	i18n.Fprintf(out, "    counted for the merge requests opened in the date range for\n")
+	i18n.Fprintf(out, "    which the user is a reviewer.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Contributions Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersReportContributionsCommand returns a new, initialized
+// UsersReportContributionsCommand instance.
+func NewUsersReportContributionsCommand(
+	name string,
+	opts *UsersReportContributionsOptions,
+	session *Session,
+) *UsersReportContributionsCommand {
+
+	// Create the new command.
+	cmd := &UsersReportContributionsCommand{
+		GitlabCommand: GitlabCommand[UsersReportContributionsOptions]{
+			BasicCommand: BasicCommand[UsersReportContributionsOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// UsersReportContributionsServices are the Gitlab services needed to
+// find the contributions of users.
+type UsersReportContributionsServices struct {
+	Groups        gitlab_util.ProjectsInGroupLister      /* was *gitlab.GroupsService */
+	Events        gitlab_util.ProjectEventsLister        /* was *gitlab.EventsService */
+	MergeRequests gitlab_util.ProjectMergeRequestsLister /* was *gitlab.MergeRequestsService */
+}
+
+// UserContributions are the contributions of a user.
+type UserContributions struct {
+
+	// Username is the username of the user.
+	Username string
+
+	// Commits is the number of commits pushed by the user.
+	Commits int
+
+	// MergeRequestsOpened is the number of merge requests opened by
+	// the user.
+	MergeRequestsOpened int
+
+	// MergeRequestsMerged is the number of merge requests opened by
+	// the user that were merged.
+	MergeRequestsMerged int
+
+	// Reviews is the number of merge requests for which the user is
+	// a reviewer.
+	Reviews int
+}
+
+// FindUserContributions returns the contributions of each user to the
+// selected projects from start up to but not including end sorted by
+// username.
+func FindUserContributions(
+	ctx context.Context,
+	result *Result,
+	selector *ProjectSelectorOptions,
+	s UsersReportContributionsServices,
+	start time.Time,
+	end time.Time,
+) ([]*UserContributions, error) {
+	inRange := func(t *time.Time) bool {
+		return t != nil && !t.Before(start) && t.Before(end)
+	}
+
+	// Get the contributions of a user creating them as needed.
+	contributions := make(map[string]*UserContributions)
+	get := func(username string) *UserContributions {
+		c, ok := contributions[username]
+		if !ok {
+			c = &UserContributions{Username: username}
+			contributions[username] = c
+		}
+		return c
+	}
+
+	// Aggregate the contributions to each project.
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+
+			// Count the commits of each push.  Gitlab compares the
+			// dates exclusively.
+			events, err := gitlab_util.GetAllProjectEvents(
+				ctx, s.Events, p.ID, gitlab.PushedEventType,
+				start.AddDate(0, 0, -1), end)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, e := range events {
+				get(e.Author.Username).Commits += e.PushData.CommitCount
+			}
+
+			// Count the merge requests and reviews.
+			mrs, err := gitlab_util.GetAllProjectMergeRequests(
+				ctx, s.MergeRequests, p.ID, start)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, mr := range mrs {
+				if mr.Author == nil {
+					continue
+				}
+				if inRange(mr.CreatedAt) {
+					get(mr.Author.Username).MergeRequestsOpened++
+					for _, r := range mr.Reviewers {
+						get(r.Username).Reviews++
+					}
+				}
+				if inRange(mr.MergedAt) {
+					get(mr.Author.Username).MergeRequestsMerged++
+				}
+			}
+
+			result.Succeed(p.PathWithNamespace, p)
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindUserContributions: %w", err)
+	}
+
+	// Sort by username.
+	var sorted []*UserContributions
+	for _, c := range contributions {
+		sorted = append(sorted, c)
+	}
+	slices.SortFunc(sorted, func(a, b *UserContributions) int {
+		return strings.Compare(a.Username, b.Username)
+	})
+
+	return sorted, nil
+}
+
+// PrintUserContributions prints the contributions of each user as a
+// table.
+func PrintUserContributions(out io.Writer, contributions []*UserContributions) {
+	row := func(commits, opened, merged, reviews, username string) {
+		fmt.Fprintf(out, "%8s  %9s  %9s  %8s  %s\n",
+			commits, opened, merged, reviews, username)
+	}
+	row(i18n.T("COMMITS"), i18n.T("MRS OPENED"), i18n.T("MRS MERGED"),
+		i18n.T("REVIEWS"), i18n.T("USERNAME"))
+	for _, c := range contributions {
+		row(fmt.Sprint(c.Commits), fmt.Sprint(c.MergeRequestsOpened),
+			fmt.Sprint(c.MergeRequestsMerged), fmt.Sprint(c.Reviews),
+			c.Username)
+	}
+}
+
+// WriteUserContributionsCSV writes the contributions of each user as
+// CSV with a header row.
+func WriteUserContributionsCSV(out io.Writer, contributions []*UserContributions) error {
+	w := csv.NewWriter(out)
+	w.Write([]string{"username", "commits", "merge_requests_opened",
+		"merge_requests_merged", "reviews"})
+	for _, c := range contributions {
+		w.Write([]string{c.Username, fmt.Sprint(c.Commits),
+			fmt.Sprint(c.MergeRequestsOpened),
+			fmt.Sprint(c.MergeRequestsMerged), fmt.Sprint(c.Reviews)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("WriteUserContributionsCSV: %w", err)
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersReportContributionsCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Determine the date range.
+	start, end := date_arg.Range(cmd.options.Since, cmd.options.Until, 30)
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Format != "table" && cmd.options.Format != "csv" {
+		return result, i18n.Errorf("%w: invalid format: %q",
+			ErrInvalidOption, cmd.options.Format)
+	}
+	if !start.Before(end) {
+		return result, i18n.Errorf("%w: invalid date range: %v to %v",
+			ErrInvalidOption, cmd.options.Since.String(), cmd.options.Until.String())
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Find the contributions.
+	contributions, err := FindUserContributions(
+		ctx, result, &cmd.options.ProjectSelectorOptions,
+		UsersReportContributionsServices{
+			Groups:        cmd.client.Groups,
+			Events:        cmd.client.Events,
+			MergeRequests: cmd.client.MergeRequests,
+		}, start, end)
+	if err != nil {
+		return result, err
+	}
+
+	// Open the output file.
+	out := io.Writer(os.Stdout)
+	if cmd.options.OutputFileName != "" {
+		f, err := os.Create(cmd.options.OutputFileName)
+		if err != nil {
+			return result, err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Write the report.
+	if cmd.options.Format == "csv" {
+		return result, WriteUserContributionsCSV(out, contributions)
+	}
+	PrintUserContributions(out, contributions)
+	return result, nil
+}
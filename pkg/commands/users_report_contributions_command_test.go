@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestUsersReportContributionsIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	day := func(d int) time.Time {
+		return time.Date(2024, time.March, d, 12, 0, 0, 0, time.Local)
+	}
+	server.AddPushEvent("foo/alpha", "aberns", 3, day(5))
+	server.AddPushEvent("foo/bar/delta", "aberns", 2, day(20))
+	server.AddPushEvent("foo/alpha", "bcrocket", 7, day(1))
+	server.AddPushEvent("foo/alpha", "bcrocket", 4, day(10))
+	server.AddMergeRequest("foo/alpha", "aberns", day(6), gitlab.Ptr(day(8)), "bcrocket")
+	server.AddMergeRequest("foo/beta", "aberns", day(12), nil, "bcrocket")
+	server.AddMergeRequest("foo/beta", "bcrocket", day(1), gitlab.Ptr(day(3)))
+	cmd := NewUsersCommand("users", &UsersOptions{}, session)
+
+	// Report the contributions as CSV.
+	out, _, err := runCommand(t, cmd, []string{
+		"report", "contributions", "--group", "foo", "--recursive",
+		"--since", "2024-03-02", "--until", "2024-03-20", "--format", "csv"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the report.
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"csv", "username,commits,merge_requests_opened,merge_requests_merged,reviews\n" +
+			"aberns,5,2,1,0\n" +
+			"bcrocket,4,0,1,2\n", out},
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("users report contributions %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+// This file provides the implementation for the "users report
+// inactive" command which reports the users who have not used Gitlab
+// for a number of days so their licenses can be reclaimed.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersReportInactiveOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersReportInactiveOptions are the options needed by this command.
+type UsersReportInactiveOptions struct {
+
+	// Days is the number of days without activity after which a user
+	// is inactive.  Defaults to 90.
+	Days uint64 `xml:"days"`
+}
+
+// Initialize initializes this UsersReportInactiveOptions instance so
+// it can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *UsersReportInactiveOptions) Initialize(flags *flag.FlagSet) {
+
+	// --days
+	if opts.Days == 0 {
+		opts.Days = 90
+	}
+	flags.Uint64Var(&opts.Days, "days", opts.Days,
+		i18n.T("number of days without activity after which a user is inactive"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersReportInactiveCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersReportInactiveCommand implements the "users report inactive"
+// command which reports users without recent activity.
+type UsersReportInactiveCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersReportInactiveOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersReportInactiveCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users report inactive [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Report the active users who have not signed in or otherwise\n")
+	i18n.Fprintf(out, "    used Gitlab for the given number of days with the number of\n")
+	i18n.Fprintf(out, "    their memberships and whether they hold active personal access\n")
+	i18n.Fprintf(out, "    tokens.  Blocked users and bots are skipped.  This requires an\n")
+	i18n.Fprintf(out, "    administrator token.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Inactive Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersReportInactiveCommand returns a new, initialized
+// UsersReportInactiveCommand instance.
+func NewUsersReportInactiveCommand(
+	name string,
+	opts *UsersReportInactiveOptions,
+	session *Session,
+) *UsersReportInactiveCommand {
+
+	// Create the new command.
+	cmd := &UsersReportInactiveCommand{
+		GitlabCommand: GitlabCommand[UsersReportInactiveOptions]{
+			BasicCommand: BasicCommand[UsersReportInactiveOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// UsersReportInactiveServices are the Gitlab services needed to find
+// inactive users.
+type UsersReportInactiveServices struct {
+	Users  UsersReportInactiveUsersService        /* was *gitlab.UsersService */
+	Tokens gitlab_util.PersonalAccessTokensLister /* was *gitlab.PersonalAccessTokensService */
+}
+
+// UsersReportInactiveUsersService is an abstraction of
+// gitlab.UsersService which lists users and their memberships.
+type UsersReportInactiveUsersService interface {
+	gitlab_util.UsersLister
+	gitlab_util.UserMembershipsGetter
+}
+
+// InactiveUser is a user without recent activity.
+type InactiveUser struct {
+
+	// User is the inactive user.
+	User *gitlab.User
+
+	// LastActivity is the time of the last activity of the user or
+	// nil if the user has never been active.
+	LastActivity *time.Time
+
+	// Memberships is the number of direct group and project
+	// memberships of the user.
+	Memberships int
+
+	// HasTokens is whether the user holds active personal access
+	// tokens.
+	HasTokens bool
+}
+
+// LastUserActivity returns the time of the last sign-in or other
+// activity of the user or nil if the user has never been active.
+func LastUserActivity(u *gitlab.User) *time.Time {
+	var result *time.Time
+	candidates := []*time.Time{u.LastSignInAt, u.CurrentSignInAt}
+	if u.LastActivityOn != nil {
+		candidates = append(candidates, gitlab.Ptr(time.Time(*u.LastActivityOn)))
+	}
+	for _, t := range candidates {
+		if t != nil && (result == nil || t.After(*result)) {
+			result = t
+		}
+	}
+	return result
+}
+
+// FindInactiveUsers returns the active users whose last activity is
+// before the cutoff sorted from least to most recently active.  Users
+// who have never been active are only inactive if they were created
+// before the cutoff.  Blocked users and bots are skipped.
+func FindInactiveUsers(
+	ctx context.Context,
+	s UsersReportInactiveServices,
+	cutoff time.Time,
+) ([]*InactiveUser, error) {
+	var result []*InactiveUser
+
+	// Find the inactive users.
+	err := gitlab_util.ForEachUser(
+		ctx,
+		s.Users,
+		"", /* user */
+		time.Time{},
+		func(u *gitlab.User) (bool, error) {
+			if u.State != "active" || u.Bot {
+				return true, nil
+			}
+			last := LastUserActivity(u)
+			if last != nil && !last.Before(cutoff) {
+				return true, nil
+			}
+			if last == nil && u.CreatedAt != nil && !u.CreatedAt.Before(cutoff) {
+				return true, nil
+			}
+			result = append(result, &InactiveUser{User: u, LastActivity: last})
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindInactiveUsers: %w", err)
+	}
+
+	// Count the memberships and check for tokens.
+	for _, inactive := range result {
+		memberships, err := gitlab_util.GetAllUserMemberships(
+			ctx, s.Users, inactive.User.ID)
+		if err != nil {
+			return nil, fmt.Errorf("FindInactiveUsers: %w", err)
+		}
+		inactive.Memberships = len(memberships)
+		tokens, err := gitlab_util.GetAllPersonalAccessTokens(
+			ctx, s.Tokens, inactive.User.ID)
+		if err != nil {
+			return nil, fmt.Errorf("FindInactiveUsers: %w", err)
+		}
+		inactive.HasTokens = slices.ContainsFunc(tokens,
+			func(t *gitlab.PersonalAccessToken) bool { return t.Active })
+	}
+
+	// Sort from least to most recently active.
+	slices.SortStableFunc(result, func(a, b *InactiveUser) int {
+		x := a.LastActivity
+		y := b.LastActivity
+		switch {
+		case x == nil && y == nil:
+		case x == nil:
+			return -1
+		case y == nil:
+			return 1
+		case !x.Equal(*y):
+			return x.Compare(*y)
+		}
+		return strings.Compare(a.User.Username, b.User.Username)
+	})
+
+	return result, nil
+}
+
+// PrintInactiveUsers prints the inactive users as a table with the
+// date of their last activity, the number of their memberships, and
+// whether they hold active personal access tokens.
+func PrintInactiveUsers(out io.Writer, inactive []*InactiveUser) {
+	row := func(lastActivity, memberships, tokens, username, name string) {
+		fmt.Fprintf(out, "%-13s  %11s  %-6s  %-20s  %s\n",
+			lastActivity, memberships, tokens, username, name)
+	}
+	row(i18n.T("LAST ACTIVITY"), i18n.T("MEMBERSHIPS"), i18n.T("TOKENS"),
+		i18n.T("USERNAME"), i18n.T("NAME"))
+	for _, u := range inactive {
+		lastActivity := i18n.T("never")
+		if u.LastActivity != nil {
+			lastActivity = u.LastActivity.Format("2006-01-02")
+		}
+		tokens := i18n.T("no")
+		if u.HasTokens {
+			tokens = i18n.T("yes")
+		}
+		row(lastActivity, fmt.Sprint(u.Memberships), tokens,
+			u.User.Username, u.User.Name)
+	}
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersReportInactiveCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Days == 0 {
+		return result, i18n.Errorf("%w: invalid days: %v",
+			ErrInvalidOption, cmd.options.Days)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Find the inactive users.
+	cutoff := time.Now().AddDate(0, 0, -int(cmd.options.Days))
+	inactive, err := FindInactiveUsers(ctx, UsersReportInactiveServices{
+		Users:  cmd.client.Users,
+		Tokens: cmd.client.PersonalAccessTokens,
+	}, cutoff)
+	if err != nil {
+		return result, err
+	}
+	for _, u := range inactive {
+		result.Succeed(u.User.Username, u.User)
+	}
+
+	// Print the report.
+	PrintInactiveUsers(os.Stdout, inactive)
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestUsersReportInactiveIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	now := time.Now()
+	server.AddUser("cdavis", "Carol Davis", "cdavis@example.com")
+	server.SetSignIn("aberns", now.AddDate(0, 0, -10))
+	server.SetSignIn("bcrocket", now.AddDate(0, 0, -200))
+	server.AddGroupMember("foo", "bcrocket", gitlab.DeveloperPermissions)
+	server.AddProjectMember("foo/alpha", "bcrocket", gitlab.MaintainerPermissions)
+	server.AddPersonalAccessToken("bcrocket", "ci", true)
+	server.AddPersonalAccessToken("cdavis", "old", false)
+	cmd := NewUsersCommand("users", &UsersOptions{}, session)
+
+	// Report the inactive users.
+	out, _, err := runCommand(t, cmd, []string{"report", "inactive", "--days", "90"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the report.
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	data := Data{
+		{"rows", 3, len(lines)},
+		{"active", false, strings.Contains(out, "aberns")},
+	}
+	if len(lines) == 3 {
+		data = append(data, Data{
+			{"cdavis", []string{"never", "0", "no", "cdavis"},
+				strings.Fields(lines[1])[:4]},
+			{"bcrocket", []string{now.AddDate(0, 0, -200).Format("2006-01-02"), "2", "yes", "bcrocket"},
+				strings.Fields(lines[2])[:4]},
+		}...)
+	}
+	for _, d := range data {
+		if fmt.Sprint(d.actual) != fmt.Sprint(d.expected) {
+			t.Errorf("users report inactive %s: expected=%v  actual=%v",
+				d.name, d.expected, d.actual)
+		}
+	}
+}
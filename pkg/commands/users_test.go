@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+func TestUsersStateIntegration(t *testing.T) {
+	server, session := newFakeSession(t)
+	session.globalOpts.Yes = true
+
+	// run runs the "users" subcommand with the arguments.
+	run := func(args ...string) error {
+		var err error
+		cmd := NewUsersCommand("users", &UsersOptions{}, session)
+		_, _, err = runCommand(t, cmd, args)
+		return err
+	}
+
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"block", "--users", "aberns,bcrocket@example.com", "-n"},
+			[]string{"active", "active"}},
+		{[]string{"block", "--users", "aberns,bcrocket@example.com"},
+			[]string{"blocked", "blocked"}},
+		{[]string{"block", "--users", "aberns"},
+			[]string{"blocked", "blocked"}},
+		{[]string{"unblock", "--users", "Alice Berns,bcrocket"},
+			[]string{"active", "active"}},
+		{[]string{"deactivate", "--users", "aberns"},
+			[]string{"deactivated", "active"}},
+		{[]string{"activate", "--users", "aberns,bcrocket"},
+			[]string{"active", "active"}},
+	}
+	for _, d := range data {
+		err := run(d.args...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v: %v", d.args, err)
+		}
+		actual := []string{server.UserState("aberns"), server.UserState("bcrocket")}
+		if !slices.Equal(d.expected, actual) {
+			t.Errorf("users %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+	}
+
+	// Verify a user that Gitlab refuses to change is reported without
+	// stopping the other users.
+	server.BlockUser("aberns")
+	err := run("deactivate", "--users", "aberns,bcrocket")
+	if err == nil || !strings.Contains(err.Error(), "could not deactivate 1 user(s)") {
+		t.Errorf("users deactivate: unexpected error: %v", err)
+	}
+	if state := server.UserState("bcrocket"); state != "deactivated" {
+		t.Errorf("users deactivate: expected=%v  actual=%v", "deactivated", state)
+	}
+
+	// Verify nothing is changed if any user is not found.
+	err = run("block", "--users", "bcrocket,nobody")
+	if !errors.Is(err, gitlab_util.ErrUserNotFound) {
+		t.Errorf("users block: expected=%v  actual=%v", gitlab_util.ErrUserNotFound, err)
+	}
+	if state := server.UserState("bcrocket"); state != "deactivated" {
+		t.Errorf("users block: expected=%v  actual=%v", "deactivated", state)
+	}
+
+	// Verify the invalid options.
+	err = run("block")
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("users block: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+}
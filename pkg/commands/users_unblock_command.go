@@ -0,0 +1,149 @@
+// This file provides the implementation for the "users unblock" command
+// which unblocks users so they can sign in again.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+////////////////////////////////////////////////////////////////////////
+// UsersUnblockOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// UsersUnblockOptions are the options needed by this command.
+type UsersUnblockOptions struct {
+
+	// Embed the options that select the users.
+	UserStateOptions
+}
+
+// Initialize initializes this UsersUnblockOptions instance so it can be
+// used with the "flag" package to parse the command-line arguments.
+func (opts *UsersUnblockOptions) Initialize(flags *flag.FlagSet) {
+
+	// -n, --dry-run, --users
+	opts.UserStateOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// UsersUnblockCommand
+////////////////////////////////////////////////////////////////////////
+
+// UsersUnblockCommand implements the "users unblock" command which
+// unblocks users so they can sign in again.
+type UsersUnblockCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[UsersUnblockOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *UsersUnblockCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] users unblock [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Unblock the users in --users so they can sign in again.  Users\n")
+	i18n.Fprintf(out, "    who are already active are skipped.  Unblocking users requires\n")
+	i18n.Fprintf(out, "    an administrator token.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Unblock Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewUsersUnblockCommand returns a new, initialized UsersUnblockCommand
+// instance.
+func NewUsersUnblockCommand(
+	name string,
+	opts *UsersUnblockOptions,
+	session *Session,
+) *UsersUnblockCommand {
+
+	// Create the new command.
+	cmd := &UsersUnblockCommand{
+		GitlabCommand: GitlabCommand[UsersUnblockOptions]{
+			BasicCommand: BasicCommand[UsersUnblockOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// Run is the entry point for this command.
+func (cmd *UsersUnblockCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Unblock the users.
+	users, err := cmd.options.GetSelectedUsers(ctx, cmd.client.Users)
+	if err != nil {
+		return result, err
+	}
+	ChangeUserStates(ctx, result, users,
+		UserStateChange{
+			Verb:   i18n.T("Unblocking"),
+			State:  "active",
+			Change: cmd.client.Users.UnblockUser,
+		},
+		cmd.options.DryRun)
+	if failed := len(result.Failed()); failed > 0 {
+		return result, i18n.Errorf("could not unblock %d user(s)", failed)
+	}
+	return result, nil
+}
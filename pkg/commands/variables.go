@@ -0,0 +1,280 @@
+// This file provides the options and functions shared by the
+// "variables" subcommands which operate either on the CI/CD variables
+// of the projects in a group or on the CI/CD variables of the group
+// itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/xanzy/go-gitlab"
+)
+
+// VariableSelectorOptions select the groups or projects whose CI/CD
+// variables are operated on.  They are embedded in the options of the
+// "variables" subcommands so the options have the same names and
+// meaning everywhere.
+type VariableSelectorOptions struct {
+
+	// Embed the options that select the projects.
+	ProjectSelectorOptions
+
+	// GroupLevel controls whether the variables of --group itself are
+	// used instead of the variables of the selected projects.
+	// Defaults to false.
+	GroupLevel bool `xml:"group-level"`
+}
+
+// Initialize initializes this VariableSelectorOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *VariableSelectorOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --ignore-case, -r, --recursive,
+	// --test-expr
+	opts.ProjectSelectorOptions.Initialize(flags)
+
+	// --group-level
+	flags.BoolVar(&opts.GroupLevel, "group-level", opts.GroupLevel,
+		i18n.T("whether to use the variables of --group itself instead "+
+			"of the variables of its projects"))
+}
+
+// VariablesService is an abstraction of
+// gitlab.ProjectVariablesService which lists, creates, updates, and
+// removes project variables.
+type VariablesService interface {
+	gitlab_util.ProjectVariablesManager
+	gitlab_util.ProjectVariableRemover
+}
+
+// VariablesServices are the Gitlab services needed by the "variables"
+// subcommands.
+type VariablesServices struct {
+	Groups           gitlab_util.ProjectsInGroupLister /* was *gitlab.GroupsService */
+	GroupVariables   gitlab_util.GroupVariablesManager /* was *gitlab.GroupVariablesService */
+	ProjectVariables VariablesService                  /* was *gitlab.ProjectVariablesService */
+}
+
+// VariableRecord describes a CI/CD variable without its value so
+// variables can be listed without leaking secrets.
+type VariableRecord struct {
+
+	// Owner is the full path of the group or project having the
+	// variable.
+	Owner string `json:"owner"`
+
+	// Key is the key of the variable.
+	Key string `json:"key"`
+
+	// EnvironmentScope is the environment scope of the variable.
+	EnvironmentScope string `json:"environment_scope"`
+
+	// VariableType is "env_var" or "file".
+	VariableType gitlab.VariableTypeValue `json:"variable_type"`
+
+	// Protected is whether the variable is only exposed to protected
+	// branches and tags.
+	Protected bool `json:"protected"`
+
+	// Masked is whether the variable is masked in job logs.
+	Masked bool `json:"masked"`
+}
+
+// ForEachVariable calls f once for each CI/CD variable of the groups
+// or projects selected by the selector.  The variable is passed to f
+// as a *gitlab.ProjectVariable even for group variables because the
+// two have the same fields.  The owner passed to f is the full path of
+// the group or project having the variable.  If the variables of a
+// project cannot be listed, the failure is recorded in the result, and
+// the remaining projects are still processed.  The function f must
+// return true and no error to indicate that it wants to continue being
+// called with the remaining variables.
+func ForEachVariable(
+	ctx context.Context,
+	result *Result,
+	selector *VariableSelectorOptions,
+	s VariablesServices,
+	f func(owner string, v *gitlab.ProjectVariable) (bool, error),
+) error {
+
+	// Group variables.
+	if selector.GroupLevel {
+		vs, err := gitlab_util.GetAllGroupVariables(
+			ctx, s.GroupVariables, selector.Group)
+		if err != nil {
+			return fmt.Errorf("ForEachVariable: %w", err)
+		}
+		for _, v := range vs {
+			ok, err := f(selector.Group, groupToProjectVariable(v))
+			if !ok || err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Project variables.
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			vs, err := gitlab_util.GetAllProjectVariables(
+				ctx, s.ProjectVariables, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			for _, v := range vs {
+				ok, err := f(p.PathWithNamespace, v)
+				if !ok || err != nil {
+					return ok, err
+				}
+			}
+			return true, nil
+		})
+	if err != nil {
+		return fmt.Errorf("ForEachVariable: %w", err)
+	}
+	return nil
+}
+
+// ForEachVariableOwner calls f once for the group or for each project
+// selected by the selector with the full path of the group or project
+// and its existing CI/CD variables.  If the variables of a project
+// cannot be listed, the failure is recorded in the result, and the
+// remaining projects are still processed.
+func ForEachVariableOwner(
+	ctx context.Context,
+	result *Result,
+	selector *VariableSelectorOptions,
+	s VariablesServices,
+	f func(owner string, existing []*gitlab.ProjectVariable) error,
+) error {
+
+	// Group variables.
+	if selector.GroupLevel {
+		vs, err := gitlab_util.GetAllGroupVariables(
+			ctx, s.GroupVariables, selector.Group)
+		if err != nil {
+			return fmt.Errorf("ForEachVariableOwner: %w", err)
+		}
+		existing := make([]*gitlab.ProjectVariable, 0, len(vs))
+		for _, v := range vs {
+			existing = append(existing, groupToProjectVariable(v))
+		}
+		return f(selector.Group, existing)
+	}
+
+	// Project variables.
+	err := selector.ForEachProject(ctx, s.Groups,
+		func(p *gitlab.Project) (bool, error) {
+			existing, err := gitlab_util.GetAllProjectVariables(
+				ctx, s.ProjectVariables, p.ID)
+			if err != nil {
+				result.Fail(p.PathWithNamespace, p, err)
+				return true, nil
+			}
+			return true, f(p.PathWithNamespace, existing)
+		})
+	if err != nil {
+		return fmt.Errorf("ForEachVariableOwner: %w", err)
+	}
+	return nil
+}
+
+// groupToProjectVariable returns the group variable as a project
+// variable.
+func groupToProjectVariable(v *gitlab.GroupVariable) *gitlab.ProjectVariable {
+	return &gitlab.ProjectVariable{
+		Key:              v.Key,
+		Value:            v.Value,
+		VariableType:     v.VariableType,
+		Protected:        v.Protected,
+		Masked:           v.Masked,
+		Raw:              v.Raw,
+		EnvironmentScope: v.EnvironmentScope,
+		Description:      v.Description,
+	}
+}
+
+// findVariable returns the variable in vs having the key and
+// environment scope or nil if there is no such variable.
+func findVariable(vs []*gitlab.ProjectVariable, key string, scope string) *gitlab.ProjectVariable {
+	for _, v := range vs {
+		if v.Key == key && v.EnvironmentScope == scope {
+			return v
+		}
+	}
+	return nil
+}
+
+// ChangeVariable prints the progress message for the verb (e.g.,
+// "Setting") and the variable with the key in the group or project
+// having the full path, calls change unless dryRun is true, and
+// records the outcome in the result.  The error of a failed change is
+// returned after it is recorded so the caller can decide whether the
+// remaining variables are still changed.
+func ChangeVariable(
+	ctx context.Context,
+	result *Result,
+	verb string,
+	owner string,
+	key string,
+	change func() error,
+	dryRun bool,
+) error {
+	name := owner + ":" + key
+	hook := gitlab_util.EventHookFromContext(ctx)
+	hook.OnItemStart(name)
+	logging.Printf("- %s variable %q in %q ... ", verb, key, owner)
+	if !dryRun {
+		err := change()
+		if err != nil {
+			logging.Printf("Failed.\n")
+			hook.OnError(name, err)
+			result.Fail(name, owner, err)
+			return err
+		}
+	}
+	logging.Printf("Done.\n")
+	hook.OnItemDone(name)
+	result.Succeed(name, owner)
+	return nil
+}
+
+// ReadVariableValue returns the value of a variable read from the
+// file or from the environment variable.  Exactly one of fileName and
+// envName must be set.  Values are never taken from the command line
+// so they do not end up in the shell history or the process list.  A
+// single trailing newline is removed from the contents of the file.
+func ReadVariableValue(fileName string, envName string) (string, error) {
+	switch {
+	case fileName != "" && envName != "":
+		return "", i18n.Errorf("%w: both value-file and value-env set",
+			ErrInvalidOption)
+	case fileName != "":
+		data, err := os.ReadFile(fileName)
+		if err != nil {
+			return "", fmt.Errorf("ReadVariableValue: %w", err)
+		}
+		value := strings.TrimSuffix(string(data), "\n")
+		return strings.TrimSuffix(value, "\r"), nil
+	case envName != "":
+		value, ok := os.LookupEnv(envName)
+		if !ok {
+			return "", i18n.Errorf("%w: environment variable not set: %q",
+				ErrInvalidOption, envName)
+		}
+		return value, nil
+	default:
+		return "", i18n.Errorf("%w: neither value-file nor value-env set",
+			ErrInvalidOption)
+	}
+}
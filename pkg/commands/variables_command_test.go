@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestVariablesIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddProjectVariable("foo/beta", &gitlab.ProjectVariable{
+		Key: "TOKEN", Value: "old", EnvironmentScope: "*",
+	})
+	session := NewSessionWithClient(server.Client(t))
+	session.globalOpts.Yes = true
+	t.Setenv("GITLAB_CMDS_TEST_TOKEN", "from-env")
+	fname := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(fname, []byte("from-file\n"), 0600)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// run runs the "variables" subcommand and returns its output and
+	// the names of the variables that succeeded.
+	run := func(args ...string) (string, []string) {
+		cmd := NewVariablesCommand("variables", &VariablesOptions{}, session)
+		out, result, err := runCommand(t, cmd, args)
+		if err != nil {
+			t.Fatalf("variables %v: unexpected error: %v", args, err)
+		}
+		var names []string
+		for _, item := range result.Succeeded() {
+			names = append(names, item.Name)
+		}
+		return out, names
+	}
+
+	// Verify the commands.
+	type Data []struct {
+		args     []string
+		expected []string
+	}
+	data := Data{
+		{[]string{"set", "--group", "foo", "--expr", "alpha|beta",
+			"--key", "TOKEN", "--value-env", "GITLAB_CMDS_TEST_TOKEN", "--masked"},
+			[]string{"foo/alpha:TOKEN", "foo/beta:TOKEN"}},
+		{[]string{"set", "--group", "foo", "--group-level",
+			"--key", "TOKEN", "--value-file", fname},
+			[]string{"foo:TOKEN"}},
+		{[]string{"list", "--group", "foo", "-r"},
+			[]string{"foo/alpha:TOKEN", "foo/beta:TOKEN"}},
+		{[]string{"delete", "--group", "foo", "-r", "--key", "TOKEN", "--dry-run"},
+			[]string{"foo/alpha:TOKEN", "foo/beta:TOKEN"}},
+		{[]string{"delete", "--group", "foo", "--expr", "beta", "--key", "TOKEN"},
+			[]string{"foo/beta:TOKEN"}},
+		{[]string{"list", "--group", "foo", "--group-level"},
+			[]string{"foo:TOKEN"}},
+	}
+	for _, d := range data {
+		out, actual := run(d.args...)
+		if !slices.Equal(actual, d.expected) {
+			t.Errorf("variables %v: expected=%v  actual=%v", d.args, d.expected, actual)
+		}
+		if strings.Contains(out, "from-") {
+			t.Errorf("variables %v: value leaked: %q", d.args, out)
+		}
+	}
+
+	// Verify the values.
+	v := server.Variable("foo/alpha", "TOKEN")
+	if v == nil || v.Value != "from-env" || !v.Masked {
+		t.Errorf("variables set: expected=%v  actual=%v", "masked from-env", v)
+	}
+	if v := server.Variable("foo/beta", "TOKEN"); v != nil {
+		t.Errorf("variables delete: expected=%v  actual=%v", nil, v)
+	}
+	if keys := server.Variables("group", "foo"); !slices.Equal(keys, []string{"TOKEN"}) {
+		t.Errorf("variables set --group-level: expected=%v  actual=%v",
+			[]string{"TOKEN"}, keys)
+	}
+
+	// Verify the invalid options.
+	for _, args := range [][]string{
+		{"set", "--group", "foo", "--key", "TOKEN"},
+		{"set", "--group", "foo", "--key", "TOKEN",
+			"--value-file", fname, "--value-env", "GITLAB_CMDS_TEST_TOKEN"},
+		{"set", "--group", "foo", "--value-file", fname},
+		{"delete", "--group", "foo"},
+	} {
+		cmd := NewVariablesCommand("variables", &VariablesOptions{}, session)
+		_, err := cmd.Run(context.Background(), args)
+		if !errors.Is(err, ErrInvalidOption) {
+			t.Errorf("variables %v: expected=%v  actual=%v", args, ErrInvalidOption, err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+// This file provides the implementation for the "variables delete"
+// command which deletes a CI/CD variable from the projects in a group
+// or from the group itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// VariablesDeleteOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// VariablesDeleteOptions are the options needed by this command.
+type VariablesDeleteOptions struct {
+
+	// Embed the options that control when the deletion must be
+	// confirmed.
+	ConfirmationOptions
+
+	// Embed the options that control whether the variable is deleted in
+	// the remaining projects after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the groups or projects.
+	VariableSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// EnvironmentScope is the environment scope of the variable.
+	// Defaults to "*".
+	EnvironmentScope string `xml:"environment-scope"`
+
+	// Key is the key of the variable.  Defaults to "".
+	Key string `xml:"key"`
+}
+
+// Initialize initializes this VariablesDeleteOptions instance so it
+// can be used with the "flag" package to parse the command-line
+// arguments.
+func (opts *VariablesDeleteOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr
+	opts.VariableSelectorOptions.Initialize(flags)
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --environment-scope
+	if opts.EnvironmentScope == "" {
+		opts.EnvironmentScope = "*"
+	}
+	flags.StringVar(&opts.EnvironmentScope, "environment-scope", opts.EnvironmentScope,
+		i18n.T("environment scope of the variable"))
+
+	// --key
+	flags.StringVar(&opts.Key, "key", opts.Key,
+		i18n.T("key of the variable"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// VariablesDeleteCommand
+////////////////////////////////////////////////////////////////////////
+
+// VariablesDeleteCommand implements the "variables delete" command
+// which deletes a CI/CD variable from the projects in a group or from
+// the group itself.
+type VariablesDeleteCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[VariablesDeleteOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *VariablesDeleteCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] variables delete [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Delete the --key variable from the selected projects or,\n")
+	i18n.Fprintf(out, "    with --group-level, from --group itself.  Projects without\n")
+	i18n.Fprintf(out, "    the variable are skipped.  The variables are listed and must\n")
+	i18n.Fprintf(out, "    be confirmed unless the global --yes option is passed.  When\n")
+	i18n.Fprintf(out, "    deleting the variable from more projects than\n")
+	i18n.Fprintf(out, "    --confirm-threshold, --i-understand must be passed, and the\n")
+	i18n.Fprintf(out, "    group must be typed to confirm.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Delete Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewVariablesDeleteCommand returns a new, initialized
+// VariablesDeleteCommand instance.
+func NewVariablesDeleteCommand(
+	name string,
+	opts *VariablesDeleteOptions,
+	session *Session,
+) *VariablesDeleteCommand {
+
+	// Create the new command.
+	cmd := &VariablesDeleteCommand{
+		GitlabCommand: GitlabCommand[VariablesDeleteOptions]{
+			BasicCommand: BasicCommand[VariablesDeleteOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// DeleteProjectVariable deletes the variable having the key and
+// environment scope from the project.
+func DeleteProjectVariable(
+	ctx context.Context,
+	s gitlab_util.ProjectVariableRemover, /* was *gitlab.ProjectVariablesService */
+	project string,
+	key string,
+	scope string,
+) error {
+	_, err := s.RemoveVariable(project, key,
+		&gitlab.RemoveProjectVariableOptions{
+			Filter: &gitlab.VariableFilter{EnvironmentScope: scope},
+		},
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("DeleteProjectVariable: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// DeleteGroupVariable deletes the variable having the key from the
+// group.
+func DeleteGroupVariable(
+	ctx context.Context,
+	s gitlab_util.GroupVariablesManager, /* was *gitlab.GroupVariablesService */
+	group string,
+	key string,
+) error {
+	_, err := s.RemoveVariable(group, key,
+		gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	if err != nil {
+		return fmt.Errorf("DeleteGroupVariable: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *VariablesDeleteCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Key == "" {
+		return result, i18n.Errorf("%w: key not set", ErrInvalidOption)
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Collect the group or the projects that have the variable.
+	key := cmd.options.Key
+	scope := cmd.options.EnvironmentScope
+	var owners []string
+	err = ForEachVariableOwner(
+		ctx, result, &cmd.options.VariableSelectorOptions,
+		VariablesServices{
+			Groups:           cmd.client.Groups,
+			GroupVariables:   cmd.client.GroupVariables,
+			ProjectVariables: cmd.client.ProjectVariables,
+		},
+		func(owner string, existing []*gitlab.ProjectVariable) error {
+			if findVariable(existing, key, scope) != nil {
+				owners = append(owners, owner)
+			}
+			return nil
+		})
+	if err != nil {
+		err = cmd.options.Finish(os.Stdout, result, err, "could not delete variable from %d group(s) or project(s)")
+		return result, err
+	}
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		var names []string
+		for _, owner := range owners {
+			names = append(names, owner+":"+key)
+		}
+		err = cmd.options.Confirm(cmd.confirmIn, i18n.T("delete"), names,
+			cmd.options.Group, cmd.session.AssumeYes())
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Delete the variable from the group or each project stopping
+	// after the current one if interrupted.  If --keep-going is set,
+	// a variable that cannot be deleted is recorded in the result, and
+	// the variable is still deleted from the remaining projects.
+	for _, owner := range owners {
+		err = ctx.Err()
+		if err != nil {
+			break
+		}
+		err = ChangeVariable(ctx, result, i18n.T("Deleting"), owner, key,
+			func() error {
+				if cmd.options.GroupLevel {
+					return DeleteGroupVariable(
+						ctx, cmd.client.GroupVariables, owner, key)
+				}
+				return DeleteProjectVariable(
+					ctx, cmd.client.ProjectVariables, owner, key, scope)
+			},
+			cmd.options.DryRun)
+		if err != nil && !cmd.options.KeepGoing {
+			break
+		}
+	}
+	err = cmd.options.Finish(os.Stdout, result, err, "could not delete variable from %d group(s) or project(s)")
+	return result, err
+}
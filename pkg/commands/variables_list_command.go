@@ -0,0 +1,200 @@
+// This file provides the implementation for the "variables list"
+// command which lists the CI/CD variables of the projects in a group
+// or of the group itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// VariablesListOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// VariablesListOptions are the options needed by this command.
+type VariablesListOptions struct {
+
+	// Embed the options that select the groups or projects.
+	VariableSelectorOptions
+}
+
+// Initialize initializes this VariablesListOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *VariablesListOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr
+	opts.VariableSelectorOptions.Initialize(flags)
+}
+
+////////////////////////////////////////////////////////////////////////
+// VariablesListCommand
+////////////////////////////////////////////////////////////////////////
+
+// VariablesListCommand implements the "variables list" command which
+// lists the CI/CD variables of the projects in a group or of the
+// group itself.
+type VariablesListCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[VariablesListOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *VariablesListCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] variables list [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    List the CI/CD variables of the selected projects or, with\n")
+	i18n.Fprintf(out, "    --group-level, of --group itself.  Each variable is printed\n")
+	i18n.Fprintf(out, "    as its owner, key, environment scope, and flags.  Values are\n")
+	i18n.Fprintf(out, "    never printed.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "List Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewVariablesListCommand returns a new, initialized
+// VariablesListCommand instance.
+func NewVariablesListCommand(
+	name string,
+	opts *VariablesListOptions,
+	session *Session,
+) *VariablesListCommand {
+
+	// Create the new command.
+	cmd := &VariablesListCommand{
+		GitlabCommand: GitlabCommand[VariablesListOptions]{
+			BasicCommand: BasicCommand[VariablesListOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// variableFlags returns the comma-separated flags (e.g.,
+// "masked,protected") of the variable or "-" if it has none.
+func variableFlags(v *gitlab.ProjectVariable) string {
+	var flags []string
+	if v.VariableType == gitlab.FileVariableType {
+		flags = append(flags, "file")
+	}
+	if v.Masked {
+		flags = append(flags, "masked")
+	}
+	if v.Protected {
+		flags = append(flags, "protected")
+	}
+	if len(flags) == 0 {
+		return "-"
+	}
+	return strings.Join(flags, ",")
+}
+
+// Run is the entry point for this command.
+func (cmd *VariablesListCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Print each variable.  For --output json, the variables are
+	// collected and printed together at the end.
+	records := []VariableRecord{}
+	err = ForEachVariable(
+		ctx, result, &cmd.options.VariableSelectorOptions,
+		VariablesServices{
+			Groups:           cmd.client.Groups,
+			GroupVariables:   cmd.client.GroupVariables,
+			ProjectVariables: cmd.client.ProjectVariables,
+		},
+		func(owner string, v *gitlab.ProjectVariable) (bool, error) {
+			if cmd.session.OutputJSON() {
+				records = append(records, VariableRecord{
+					Owner:            owner,
+					Key:              v.Key,
+					EnvironmentScope: v.EnvironmentScope,
+					VariableType:     v.VariableType,
+					Protected:        v.Protected,
+					Masked:           v.Masked,
+				})
+			} else {
+				fmt.Printf("%-40s  %-30s  %-12s  %s\n",
+					owner, v.Key, v.EnvironmentScope, variableFlags(v))
+			}
+			result.Succeed(owner+":"+v.Key, owner)
+			return true, nil
+		})
+	if err != nil {
+		return result, err
+	}
+
+	// Print the variables as JSON.
+	if cmd.session.OutputJSON() {
+		err = writeJSON(os.Stdout, records)
+	}
+	return result, err
+}
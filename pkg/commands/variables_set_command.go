@@ -0,0 +1,335 @@
+// This file provides the implementation for the "variables set"
+// command which creates or updates a CI/CD variable in the projects
+// in a group or in the group itself.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// VariablesSetOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// VariablesSetOptions are the options needed by this command.
+type VariablesSetOptions struct {
+
+	// Embed the options that control whether the variable is set in
+	// the remaining projects after a project fails.
+	KeepGoingOptions
+
+	// Embed the options that select the groups or projects.
+	VariableSelectorOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// EnvironmentScope is the environment scope of the variable.
+	// Defaults to "*".
+	EnvironmentScope string `xml:"environment-scope"`
+
+	// Key is the key of the variable.  Defaults to "".
+	Key string `xml:"key"`
+
+	// Masked is whether the variable is masked in job logs.
+	// Defaults to false.
+	Masked bool `xml:"masked"`
+
+	// Protected is whether the variable is only exposed to protected
+	// branches and tags.  Defaults to false.
+	Protected bool `xml:"protected"`
+
+	// ValueEnv is the name of the environment variable holding the
+	// value of the variable.  Either ValueEnv or ValueFileName must
+	// be set.  The value is never taken from the command line so it
+	// does not end up in the shell history.  Defaults to "".
+	ValueEnv string `xml:"value-env"`
+
+	// ValueFileName is the name of the file holding the value of the
+	// variable.  A single trailing newline is ignored.  Defaults to
+	// "".
+	ValueFileName string `xml:"value-file-name"`
+}
+
+// Initialize initializes this VariablesSetOptions instance so it can
+// be used with the "flag" package to parse the command-line arguments.
+func (opts *VariablesSetOptions) Initialize(flags *flag.FlagSet) {
+
+	// --anchored, --expr, --group, --group-level, --ignore-case, -r,
+	// --recursive, --test-expr
+	opts.VariableSelectorOptions.Initialize(flags)
+
+	// --keep-going
+	opts.KeepGoingOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --environment-scope
+	if opts.EnvironmentScope == "" {
+		opts.EnvironmentScope = "*"
+	}
+	flags.StringVar(&opts.EnvironmentScope, "environment-scope", opts.EnvironmentScope,
+		i18n.T("environment scope of the variable"))
+
+	// --key
+	flags.StringVar(&opts.Key, "key", opts.Key,
+		i18n.T("key of the variable"))
+
+	// --masked
+	flags.BoolVar(&opts.Masked, "masked", opts.Masked,
+		i18n.T("whether the variable is masked in job logs"))
+
+	// --protected
+	flags.BoolVar(&opts.Protected, "protected", opts.Protected,
+		i18n.T("whether the variable is only exposed to protected branches and tags"))
+
+	// --value-env
+	flags.StringVar(&opts.ValueEnv, "value-env", opts.ValueEnv,
+		i18n.T("name of the environment variable holding the value"))
+
+	// --value-file
+	flags.StringVar(&opts.ValueFileName, "value-file", opts.ValueFileName,
+		i18n.T("name of the file holding the value"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// VariablesSetCommand
+////////////////////////////////////////////////////////////////////////
+
+// VariablesSetCommand implements the "variables set" command which
+// creates or updates a CI/CD variable in the projects in a group or in
+// the group itself.
+type VariablesSetCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[VariablesSetOptions]
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *VariablesSetCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] variables set [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Create or update the --key variable in the selected projects\n")
+	i18n.Fprintf(out, "    or, with --group-level, in --group itself.  The value is read\n")
+	i18n.Fprintf(out, "    from --value-file or --value-env so it does not end up in\n")
+	i18n.Fprintf(out, "    the shell history.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Set Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewVariablesSetCommand returns a new, initialized VariablesSetCommand
+// instance.
+func NewVariablesSetCommand(
+	name string,
+	opts *VariablesSetOptions,
+	session *Session,
+) *VariablesSetCommand {
+
+	// Create the new command.
+	cmd := &VariablesSetCommand{
+		GitlabCommand: GitlabCommand[VariablesSetOptions]{
+			BasicCommand: BasicCommand[VariablesSetOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// SetProjectVariable creates the variable v in the project or updates
+// it if the project already has a variable with the same key and
+// environment scope.
+func SetProjectVariable(
+	ctx context.Context,
+	s gitlab_util.ProjectVariablesManager, /* was *gitlab.ProjectVariablesService */
+	project string,
+	exists bool,
+	v *gitlab.ProjectVariable,
+) error {
+	var err error
+	if exists {
+		_, _, err = s.UpdateVariable(project, v.Key,
+			&gitlab.UpdateProjectVariableOptions{
+				Value:            &v.Value,
+				EnvironmentScope: &v.EnvironmentScope,
+				Filter:           &gitlab.VariableFilter{EnvironmentScope: v.EnvironmentScope},
+				Masked:           &v.Masked,
+				Protected:        &v.Protected,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	} else {
+		_, _, err = s.CreateVariable(project,
+			&gitlab.CreateProjectVariableOptions{
+				Key:              &v.Key,
+				Value:            &v.Value,
+				EnvironmentScope: &v.EnvironmentScope,
+				Masked:           &v.Masked,
+				Protected:        &v.Protected,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	}
+	if err != nil {
+		return fmt.Errorf("SetProjectVariable: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// SetGroupVariable creates the variable v in the group or updates it
+// if the group already has a variable with the same key and
+// environment scope.
+func SetGroupVariable(
+	ctx context.Context,
+	s gitlab_util.GroupVariablesManager, /* was *gitlab.GroupVariablesService */
+	group string,
+	exists bool,
+	v *gitlab.ProjectVariable,
+) error {
+	var err error
+	if exists {
+		_, _, err = s.UpdateVariable(group, v.Key,
+			&gitlab.UpdateGroupVariableOptions{
+				Value:            &v.Value,
+				EnvironmentScope: &v.EnvironmentScope,
+				Masked:           &v.Masked,
+				Protected:        &v.Protected,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	} else {
+		_, _, err = s.CreateVariable(group,
+			&gitlab.CreateGroupVariableOptions{
+				Key:              &v.Key,
+				Value:            &v.Value,
+				EnvironmentScope: &v.EnvironmentScope,
+				Masked:           &v.Masked,
+				Protected:        &v.Protected,
+			},
+			gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+	}
+	if err != nil {
+		return fmt.Errorf("SetGroupVariable: %w", gitlab_util.ClassifyError(err))
+	}
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *VariablesSetCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	err = cmd.options.ProjectSelectorOptions.Validate()
+	if err != nil {
+		return result, err
+	}
+	if cmd.options.Key == "" {
+		return result, i18n.Errorf("%w: key not set", ErrInvalidOption)
+	}
+
+	// Read the value.
+	value, err := ReadVariableValue(cmd.options.ValueFileName, cmd.options.ValueEnv)
+	if err != nil {
+		return result, err
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Only print the selected projects for --test-expr.
+	if cmd.options.TestExpr {
+		err = cmd.options.PrintSelectedProjects(ctx, result, cmd.client.Groups)
+		return result, err
+	}
+
+	// Set the variable in the group or each project.
+	v := &gitlab.ProjectVariable{
+		Key:              cmd.options.Key,
+		Value:            value,
+		EnvironmentScope: cmd.options.EnvironmentScope,
+		Masked:           cmd.options.Masked,
+		Protected:        cmd.options.Protected,
+	}
+	err = ForEachVariableOwner(
+		ctx, result, &cmd.options.VariableSelectorOptions,
+		VariablesServices{
+			Groups:           cmd.client.Groups,
+			GroupVariables:   cmd.client.GroupVariables,
+			ProjectVariables: cmd.client.ProjectVariables,
+		},
+		func(owner string, existing []*gitlab.ProjectVariable) error {
+			exists := findVariable(existing, v.Key, v.EnvironmentScope) != nil
+			err := ChangeVariable(ctx, result, i18n.T("Setting"), owner, v.Key,
+				func() error {
+					if cmd.options.GroupLevel {
+						return SetGroupVariable(
+							ctx, cmd.client.GroupVariables, owner, exists, v)
+					}
+					return SetProjectVariable(
+						ctx, cmd.client.ProjectVariables, owner, exists, v)
+				},
+				cmd.options.DryRun)
+			if err != nil && !cmd.options.KeepGoing {
+				return err
+			}
+			return nil
+		})
+	err = cmd.options.Finish(os.Stdout, result, err, "could not set variable in %d group(s) or project(s)")
+	return result, err
+}
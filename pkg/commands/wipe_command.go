@@ -0,0 +1,474 @@
+// This file provides the implementation for the "wipe" command which
+// deletes all projects, groups, and users whose path or username
+// starts with a prefix on a test instance.
+
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+	"github.com/jalitriver/gitlab-cmds/pkg/logging"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// WipeOptions
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: We cannot put these options in the Command struct because the
+// way it works is the options are (eventually) embedded in the single
+// large "Options" data structure in global_command.go so that all of
+// the options can be read from a single options.xml file.  Because we
+// want the main "Options" data structure in global_command.go to be
+// lean, we factor out our options into their own data structure.
+//
+
+// WipeOptions are the options needed by this command.
+type WipeOptions struct {
+
+	// Embed the options that control when the wipe must be confirmed.
+	ConfirmationOptions
+
+	// DryRun should cause the command to print what it would do
+	// instead of actually doing it.  Defaults to false.
+	DryRun bool `xml:"dry-run"`
+
+	// Prefix is the prefix of the paths of the projects and groups
+	// and of the usernames of the users to delete.  Defaults to "".
+	Prefix string `xml:"prefix"`
+
+	// ProductionURLs are the base URLs of Gitlab instances that must
+	// never be wiped in addition to [DefaultProductionURL] which is
+	// always refused.  Defaults to no URLs.
+	ProductionURLs string_slice.StringSlice `xml:"production-urls>url"`
+}
+
+// Initialize initializes this WipeOptions instance so it can be used
+// with the "flag" package to parse the command-line arguments.
+func (opts *WipeOptions) Initialize(flags *flag.FlagSet) {
+
+	// --confirm-threshold, --i-understand
+	opts.ConfirmationOptions.Initialize(flags)
+
+	// -n
+	flags.BoolVar(&opts.DryRun, "n", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --dry-run
+	flags.BoolVar(&opts.DryRun, "dry-run", opts.DryRun,
+		i18n.T("print what it would do instead of actually doing it"))
+
+	// --prefix
+	flags.StringVar(&opts.Prefix, "prefix", opts.Prefix,
+		i18n.T("prefix of the paths and usernames to delete"))
+
+	// --production-urls
+	flags.Var(&opts.ProductionURLs, "production-urls",
+		i18n.T("comma-separated base URLs of instances that must never be wiped "+
+			"in addition to https://gitlab.com/"))
+}
+
+////////////////////////////////////////////////////////////////////////
+// WipeCommand
+////////////////////////////////////////////////////////////////////////
+
+// WipeCommand implements the "wipe" command which deletes all
+// projects, groups, and users whose path or username starts with a
+// prefix on a test instance.
+type WipeCommand struct {
+
+	// Embed the Command members.
+	GitlabCommand[WipeOptions]
+
+	// confirmIn is where the confirmation is read from.  If nil, it
+	// is read from os.Stdin which must be a terminal.
+	confirmIn io.Reader
+}
+
+// Usage prints the usage message to the output writer.  If err is not
+// nil, it will be printed before the main output.
+func (cmd *WipeCommand) Usage(out io.Writer, err error) {
+	basename := filepath.Base(os.Args[0])
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+	}
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out,
+		"Usage: %s [global_options] wipe [subcmd_options]\n",
+		basename)
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "    Deletes all projects, groups, and users on a test instance\n")
+	i18n.Fprintf(out, "    whose path or username starts with --prefix.  Deleting a\n")
+	i18n.Fprintf(out, "    group also deletes everything in it.  The prefix must be\n")
+	i18n.Fprintf(out, "    typed interactively to confirm, and instances listed in\n")
+	i18n.Fprintf(out, "    --production-urls are always refused.  If more items than\n")
+	i18n.Fprintf(out, "    --confirm-threshold match, --i-understand must be passed,\n")
+	i18n.Fprintf(out, "    and a generated token must be typed instead of the prefix.\n")
+	i18n.Fprintf(out, "    The confirmation is mandatory, so the global --yes option\n")
+	i18n.Fprintf(out, "    does not skip it.\n")
+	fmt.Fprintf(out, "\n")
+	i18n.Fprintf(out, "Wipe Options:\n")
+	fmt.Fprintf(out, "\n")
+	cmd.flags.SetOutput(out)
+	cmd.flags.PrintDefaults()
+	fmt.Fprintf(out, "\n")
+}
+
+// NewWipeCommand returns a new, initialized WipeCommand instance.
+func NewWipeCommand(
+	name string,
+	opts *WipeOptions,
+	session *Session,
+) *WipeCommand {
+
+	// Create the new command.
+	cmd := &WipeCommand{
+		GitlabCommand: GitlabCommand[WipeOptions]{
+			BasicCommand: BasicCommand[WipeOptions]{
+				name:    name,
+				flags:   flag.NewFlagSet(name, flag.ContinueOnError),
+				options: opts,
+			},
+			session: session,
+		},
+	}
+
+	// Set up the function that prints the usage when there is a
+	// problem parsing the command-line arguments.
+	cmd.flags.Usage = func() { cmd.Usage(os.Stderr, nil) }
+
+	// Initialize our command-line options.
+	opts.Initialize(cmd.flags)
+
+	return cmd
+}
+
+// WipeServices are the Gitlab services needed to wipe an instance.
+type WipeServices struct {
+	Groups   WipeGroupsService   /* was *gitlab.GroupsService */
+	Projects WipeProjectsService /* was *gitlab.ProjectsService */
+	Users    WipeUsersService    /* was *gitlab.UsersService */
+}
+
+// WipeGroupsService is an abstraction of gitlab.GroupsService which
+// lists and deletes groups.
+type WipeGroupsService interface {
+	gitlab_util.GroupsLister
+	gitlab_util.GroupDeleter
+}
+
+// WipeProjectsService is an abstraction of gitlab.ProjectsService
+// which lists and deletes projects.
+type WipeProjectsService interface {
+	gitlab_util.ProjectsLister
+	gitlab_util.ProjectDeleter
+}
+
+// WipeUsersService is an abstraction of gitlab.UsersService which
+// lists and deletes users.
+type WipeUsersService interface {
+	gitlab_util.UsersLister
+	gitlab_util.UserDeleter
+}
+
+// WipeTargets are the groups, projects, and users to delete.  Groups
+// and projects inside a group that is deleted are not included
+// because they are deleted along with the group.
+type WipeTargets struct {
+	Groups   []*gitlab.Group
+	Projects []*gitlab.Project
+	Users    []*gitlab.User
+}
+
+// Count returns the number of targets.
+func (targets *WipeTargets) Count() int {
+	return len(targets.Groups) + len(targets.Projects) + len(targets.Users)
+}
+
+// Names returns the full paths of the groups and projects and the
+// usernames of the users.
+func (targets *WipeTargets) Names() []string {
+	var result []string
+	for _, g := range targets.Groups {
+		result = append(result, g.FullPath)
+	}
+	for _, p := range targets.Projects {
+		result = append(result, p.PathWithNamespace)
+	}
+	for _, u := range targets.Users {
+		result = append(result, u.Username)
+	}
+	return result
+}
+
+// normalizeBaseURL returns the host and path of the base URL in
+// lower case without the trailing "/" or "/api/v4" so base URLs can
+// be compared regardless of how they were written.
+func normalizeBaseURL(baseURL string) string {
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return strings.ToLower(baseURL)
+	}
+	path := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/api/v4")
+	return strings.ToLower(u.Host + strings.TrimSuffix(path, "/"))
+}
+
+// DefaultProductionURL is the base URL of the Gitlab instance that is
+// always refused by the "wipe" command regardless of --production-urls.
+const DefaultProductionURL = "https://gitlab.com/"
+
+// CheckNotProduction returns an error if the base URL matches any of
+// the production URLs.
+func CheckNotProduction(baseURL string, productionURLs []string) error {
+	for _, p := range productionURLs {
+		if normalizeBaseURL(p) == normalizeBaseURL(baseURL) {
+			return i18n.Errorf(
+				"%w: refusing to wipe production instance %q",
+				ErrInvalidOption, baseURL)
+		}
+	}
+	return nil
+}
+
+// FindWipeTargets returns the groups, projects, and users whose path
+// or username starts with the prefix.
+func FindWipeTargets(
+	ctx context.Context,
+	s *WipeServices,
+	prefix string,
+) (*WipeTargets, error) {
+	var err error
+	targets := &WipeTargets{}
+
+	// Find the groups keeping only the outermost ones.
+	groups, err := gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.Group, *gitlab.Response, error) {
+			opts := gitlab.ListGroupsOptions{
+				AllAvailable: gitlab.Ptr(true),
+				Search:       gitlab.Ptr(prefix),
+			}
+			opts.Page = page
+			gs, resp, err := s.Groups.ListGroups(&opts, gitlab.WithContext(ctx))
+			if err != nil {
+				return nil, nil, gitlab_util.ClassifyError(err)
+			}
+			return gs, resp, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindWipeTargets: %w", err)
+	}
+	groups = slices.DeleteFunc(groups, func(g *gitlab.Group) bool {
+		return !strings.HasPrefix(g.Path, prefix)
+	})
+	inDeletedGroup := func(fullPath string) bool {
+		return slices.ContainsFunc(groups, func(g *gitlab.Group) bool {
+			return strings.HasPrefix(fullPath, g.FullPath+"/")
+		})
+	}
+	for _, g := range groups {
+		if !inDeletedGroup(g.FullPath) {
+			targets.Groups = append(targets.Groups, g)
+		}
+	}
+
+	// Find the projects that are not in a group being deleted.
+	projects, err := gitlab_util.GetAllPages(ctx,
+		func(page int) ([]*gitlab.Project, *gitlab.Response, error) {
+			opts := gitlab.ListProjectsOptions{
+				Search: gitlab.Ptr(prefix),
+			}
+			opts.Page = page
+			ps, resp, err := s.Projects.ListProjects(&opts, gitlab.WithContext(ctx))
+			if err != nil {
+				return nil, nil, gitlab_util.ClassifyError(err)
+			}
+			return ps, resp, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindWipeTargets: %w", err)
+	}
+	for _, p := range projects {
+		if strings.HasPrefix(p.Path, prefix) && !inDeletedGroup(p.PathWithNamespace) {
+			targets.Projects = append(targets.Projects, p)
+		}
+	}
+
+	// Find the users.
+	err = gitlab_util.ForEachUser(ctx, s.Users, prefix, time.Time{},
+		func(u *gitlab.User) (bool, error) {
+			if strings.HasPrefix(u.Username, prefix) {
+				targets.Users = append(targets.Users, u)
+			}
+			return true, nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("FindWipeTargets: %w", err)
+	}
+
+	return targets, nil
+}
+
+// Wipe deletes the targets.  Projects are deleted first, then groups,
+// and then users.  If dryRun is true, this function only prints what
+// it would without actually doing it.
+func Wipe(
+	ctx context.Context,
+	result *Result,
+	s *WipeServices,
+	targets *WipeTargets,
+	dryRun bool,
+) error {
+	hook := gitlab_util.EventHookFromContext(ctx)
+
+	// wipe deletes one item.  Nothing is deleted if ctx is done so
+	// an interrupt stops the wipe after the current item.
+	wipe := func(name string, desc string, f func() (*gitlab.Response, error)) error {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("Wipe: %w", err)
+		}
+		hook.OnItemStart(name)
+		logging.Printf("- %s %q ... ", desc, name)
+		if !dryRun {
+			_, err := f()
+			if err != nil {
+				err = fmt.Errorf("Wipe: %w", gitlab_util.ClassifyError(err))
+				hook.OnError(name, err)
+				result.Fail(name, nil, err)
+				return err
+			}
+		}
+		logging.Printf("Done.\n")
+		hook.OnItemDone(name)
+		result.Succeed(name, nil)
+		return nil
+	}
+
+	for _, p := range targets.Projects {
+		err := wipe(p.PathWithNamespace, i18n.T("Deleting project"),
+			func() (*gitlab.Response, error) {
+				return s.Projects.DeleteProject(p.ID,
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			})
+		if err != nil {
+			return err
+		}
+	}
+	for _, g := range targets.Groups {
+		err := wipe(g.FullPath, i18n.T("Deleting group"),
+			func() (*gitlab.Response, error) {
+				return s.Groups.DeleteGroup(g.ID, nil,
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			})
+		if err != nil {
+			return err
+		}
+	}
+	for _, u := range targets.Users {
+		err := wipe(u.Username, i18n.T("Deleting user"),
+			func() (*gitlab.Response, error) {
+				return s.Users.DeleteUser(u.ID,
+					gitlab.WithContext(gitlab_util.Uninterruptible(ctx)))
+			})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run is the entry point for this command.
+func (cmd *WipeCommand) Run(ctx context.Context, args []string) (*Result, error) {
+	var err error
+	result := NewResult()
+
+	// Parse command-line arguments.
+	err = cmd.parseFlags(args)
+	if err != nil {
+		return result, err
+	}
+
+	// Validate the options.
+	if cmd.options.Prefix == "" {
+		return result, i18n.Errorf("%w: prefix not set", ErrInvalidOption)
+	}
+
+	// The confirmation must be interactive.  Unlike the other
+	// deletion commands, the global --yes option does not skip it.
+	in := cmd.confirmIn
+	if in == nil && !cmd.options.DryRun {
+		fi, err := os.Stdin.Stat()
+		if err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+			return result, i18n.Errorf(
+				"%w: wipe must be confirmed interactively", ErrNotConfirmed)
+		}
+		in = os.Stdin
+	}
+
+	// Connect to Gitlab.
+	err = cmd.connect()
+	if err != nil {
+		return result, err
+	}
+
+	// Refuse to wipe production instances.  The default is added
+	// here instead of in Initialize() because options.xml appends to
+	// the list instead of replacing it.
+	baseURL := cmd.client.BaseURL().String()
+	err = CheckNotProduction(baseURL,
+		append([]string{DefaultProductionURL}, cmd.options.ProductionURLs...))
+	if err != nil {
+		return result, err
+	}
+
+	// Find what to delete.
+	services := &WipeServices{
+		Groups:   cmd.client.Groups,
+		Projects: cmd.client.Projects,
+		Users:    cmd.client.Users,
+	}
+	targets, err := FindWipeTargets(ctx, services, cmd.options.Prefix)
+	if err != nil {
+		return result, err
+	}
+	if targets.Count() == 0 {
+		i18n.Printf("Nothing to wipe.\n")
+		return result, nil
+	}
+
+	// Ask for confirmation.
+	if !cmd.options.DryRun {
+		i18n.Printf("About to delete %d project(s), %d group(s), and %d user(s) from %s.\n",
+			len(targets.Projects), len(targets.Groups), len(targets.Users), baseURL)
+		names := targets.Names()
+		if len(names) > cmd.options.ConfirmThreshold {
+			err = cmd.options.Confirm(in, i18n.T("delete"), names, "", false)
+		} else {
+			PrintConfirmationPreview(i18n.T("delete"), names)
+			err = promptConfirmation(in, cmd.options.Prefix)
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Delete everything.
+	err = Wipe(ctx, result, services, targets, cmd.options.DryRun)
+	return result, err
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestWipeIntegration(t *testing.T) {
+	server := newFakeServer(t)
+	server.AddGroup("foo/test-sub")
+	server.AddProject("foo/test-sub", "keep")
+	server.AddProject("foo/test-sub", "test-nested")
+	server.AddUser("test-user", "Test User", "test-user@example.com")
+	client := server.Client(t)
+	session := NewSessionWithClient(client)
+
+	// run runs "wipe --prefix test-" with the confirmation and extra
+	// arguments.
+	run := func(confirmation string, args ...string) error {
+		cmd := NewWipeCommand("wipe", &WipeOptions{}, session)
+		cmd.confirmIn = strings.NewReader(confirmation)
+		var err error
+		captureStdout(t, func() {
+			_, err = cmd.Run(context.Background(),
+				append([]string{"--prefix", "test-"}, args...))
+		})
+		return err
+	}
+
+	// Verify production instances are refused.
+	err := run("test-\n", "--production-urls", client.BaseURL().String())
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("wipe production: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+
+	// Verify the default production instance is refused even if other
+	// production instances are listed.
+	gitlabCom, err := gitlab.NewClient("", gitlab.WithBaseURL(DefaultProductionURL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmd := NewWipeCommand("wipe", &WipeOptions{}, NewSessionWithClient(gitlabCom))
+	_, err = cmd.Run(context.Background(), []string{"--prefix", "test-",
+		"--production-urls", "https://gitlab.example.com/"})
+	if !errors.Is(err, ErrInvalidOption) {
+		t.Errorf("wipe gitlab.com: expected=%v  actual=%v", ErrInvalidOption, err)
+	}
+
+	// Verify the default production instance is listed once by
+	// --show-options when options.xml lists it too.
+	optionsFileName := filepath.Join(t.TempDir(), "options.xml")
+	err = os.WriteFile(optionsFileName, []byte(`<options><wipe-options>
+	  <production-urls><url>https://gitlab.com/</url></production-urls>
+	</wipe-options></options>`), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := captureStdout(t, func() {
+		_, err = NewGlobalCommand("glcmds", "0.0.0").Run(context.Background(),
+			[]string{"--options", optionsFileName, "--show-options", "wipe"})
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := strings.Count(out, "<url>https://gitlab.com/</url>"); n != 1 {
+		t.Errorf("wipe --show-options: expected=1  actual=%d", n)
+	}
+
+	// Verify nothing is deleted without confirmation.
+	err = run("yes\n")
+	if !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("wipe unconfirmed: expected=%v  actual=%v", ErrNotConfirmed, err)
+	}
+	if len(server.Projects()) != 7 {
+		t.Fatalf("wipe unconfirmed: expected=7 projects  actual=%v", server.Projects())
+	}
+
+	// Verify the global --yes option does not skip the confirmation.
+	session.globalOpts.Yes = true
+	err = run("\n")
+	if !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("wipe --yes: expected=%v  actual=%v", ErrNotConfirmed, err)
+	}
+	if len(server.Projects()) != 7 {
+		t.Fatalf("wipe --yes: expected=7 projects  actual=%v", server.Projects())
+	}
+
+	// Wipe and verify only the prefixed items are gone.
+	err = run("test-\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	type Data []struct {
+		name     string
+		expected []string
+		actual   []string
+	}
+	data := Data{
+		{"groups", []string{"foo", "foo/bar"}, server.Groups()},
+		{"projects", []string{"foo/alpha", "foo/beta", "foo/bar/delta"}, server.Projects()},
+		{"users", []string{"aberns", "bcrocket"}, server.Users()},
+	}
+	for _, d := range data {
+		if !slices.Equal(d.actual, d.expected) {
+			t.Errorf("wipe %s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// This file provides the search for configuration files like auth.xml
+// and options.xml in the standard per-user configuration directories
+// so the program works from any working directory.
+
+package config_path
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AppName is the name of the subdirectory of each per-user
+// configuration directory that holds the configuration files.
+const AppName = "glcmds"
+
+// SearchDirs returns the directories that are searched for
+// configuration files in order of decreasing precedence:
+//
+//  1. the current directory
+//
+//  2. $XDG_CONFIG_HOME/glcmds if $XDG_CONFIG_HOME is set
+//
+//  3. the per-user configuration directory returned by
+//     os.UserConfigDir() (e.g., %APPDATA%\glcmds on Windows or
+//     ~/Library/Application Support/glcmds on macOS)
+//
+//  4. ~/.config/glcmds
+//
+// Duplicates are removed so each directory appears only once.
+func SearchDirs() []string {
+	var result []string
+
+	// add appends dir to the result if it is not already present.
+	add := func(dir string) {
+		for _, d := range result {
+			if d == dir {
+				return
+			}
+		}
+		result = append(result, dir)
+	}
+
+	add(".")
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		add(filepath.Join(xdg, AppName))
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		add(filepath.Join(dir, AppName))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		add(filepath.Join(home, ".config", AppName))
+	}
+
+	return result
+}
+
+// Find returns the path to the configuration file.  If name has a
+// directory component (e.g., "./auth.xml" or "/etc/glcmds/auth.xml"),
+// it is returned unchanged so users can always specify an exact
+// location.  Otherwise, the directories returned by SearchDirs() are
+// searched in order and the path to the first file found is returned.
+// If the file is not found, name is returned unchanged so the caller
+// reports the error for the name the user expects.
+func Find(name string) string {
+	if name == "" || filepath.Base(name) != name {
+		return name
+	}
+	for _, dir := range SearchDirs() {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return name
+}
+
+// CachePath returns the path to the cache file.  If name has a
+// directory component, it is returned unchanged.  Otherwise, it is
+// placed in the glcmds subdirectory of the per-user cache directory
+// returned by os.UserCacheDir() (e.g., ~/.cache/glcmds on Linux).  If
+// there is no per-user cache directory, name is returned unchanged so
+// the file is placed in the current directory.
+func CachePath(name string) string {
+	if name == "" || filepath.Base(name) != name {
+		return name
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(dir, AppName, name)
+}
@@ -0,0 +1,91 @@
+package config_path
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+
+	// Create a fake current directory and a fake XDG config directory.
+	cwd := t.TempDir()
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	err := os.Mkdir(filepath.Join(xdg, AppName), 0o755)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, path := range []string{
+		filepath.Join(cwd, "both.xml"),
+		filepath.Join(xdg, AppName, "both.xml"),
+		filepath.Join(xdg, AppName, "xdg.xml"),
+	} {
+		err = os.WriteFile(path, nil, 0o644)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Change to the fake current directory.
+	oldCwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err = os.Chdir(cwd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer os.Chdir(oldCwd)
+
+	type Data []struct {
+		name     string
+		expected string
+	}
+
+	data := Data{
+		{name: "both.xml", expected: "both.xml"},
+		{name: "xdg.xml", expected: filepath.Join(xdg, AppName, "xdg.xml")},
+		{name: "missing.xml", expected: "missing.xml"},
+		{name: "./xdg.xml", expected: "./xdg.xml"},
+		{name: "", expected: ""},
+	}
+
+	for _, d := range data {
+		actual := Find(d.name)
+		if actual != d.expected {
+			t.Errorf("Find(%q): expected=%q  actual=%q",
+				d.name, d.expected, actual)
+		}
+	}
+}
+
+func TestCachePath(t *testing.T) {
+
+	// Create a fake per-user cache directory.  Not every platform
+	// honors $XDG_CACHE_HOME, so ask for the directory actually used.
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		t.Skipf("no per-user cache directory: %v", err)
+	}
+
+	type Data []struct {
+		name     string
+		expected string
+	}
+
+	data := Data{
+		{name: "lookup-cache.xml", expected: filepath.Join(cache, AppName, "lookup-cache.xml")},
+		{name: "./lookup-cache.xml", expected: "./lookup-cache.xml"},
+		{name: "", expected: ""},
+	}
+
+	for _, d := range data {
+		actual := CachePath(d.name)
+		if actual != d.expected {
+			t.Errorf("CachePath(%q): expected=%q  actual=%q",
+				d.name, d.expected, actual)
+		}
+	}
+}
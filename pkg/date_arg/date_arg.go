@@ -7,6 +7,7 @@ package date_arg
 import (
 	"encoding/xml"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -16,13 +17,20 @@ type DateArg time.Time
 // flag.Value implementation
 ////////////////////////////////////////////////////////////////////////
 
-// Set sets parses the string setting the date.  This method is part
+// Set sets parses the string setting the date.  An empty string sets
+// the zero date which means the date is not set.  This method is part
 // of the flag.Value interface need by the "flag" package to parse
 // dates present on the command line.
 func (d *DateArg) Set(s string) error {
 	var date time.Time
 	var err error
 
+	// An empty string means the date is not set.
+	if strings.TrimSpace(s) == "" {
+		*d = DateArg(time.Time{})
+		return nil
+	}
+
 	// Use time.Now() to get the current timezone/location.
 	now := time.Now()
 
@@ -48,7 +56,7 @@ func (d *DateArg) Set(s string) error {
 // parse dates present on the command line.
 func (d *DateArg) String() string {
 	date := time.Time(*d)
-	return fmt.Sprintf("%d-%0m-%0d", date.Year, date.Month, date.Day)
+	return fmt.Sprintf("%04d-%02d-%02d", date.Year(), date.Month(), date.Day())
 }
 
 ////////////////////////////////////////////////////////////////////////
@@ -77,3 +85,27 @@ func (d *DateArg) UnmarshalXML(decoder *xml.Decoder, start xml.StartElement) err
 	// Parse the string.
 	return d.Set(s)
 }
+
+////////////////////////////////////////////////////////////////////////
+// Date ranges
+////////////////////////////////////////////////////////////////////////
+
+// Range returns the half-open time range [start, end) that covers
+// every day from since through until.  If until is not set, it
+// defaults to today, and if since is not set, it defaults to the
+// number of days before until.
+func Range(since DateArg, until DateArg, days int) (time.Time, time.Time) {
+	last := time.Time(until)
+	if last.IsZero() {
+		last = time.Now()
+	}
+	end := time.Date(last.Year(), last.Month(), last.Day(),
+		0, 0, 0, 0, last.Location()).AddDate(0, 0, 1)
+	first := time.Time(since)
+	if first.IsZero() {
+		first = last.AddDate(0, 0, -days)
+	}
+	start := time.Date(first.Year(), first.Month(), first.Day(),
+		0, 0, 0, 0, first.Location())
+	return start, end
+}
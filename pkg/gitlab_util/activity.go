@@ -0,0 +1,139 @@
+// This file provides utility functions for the activity in projects
+// such as pushes, merge requests, and merge request notes.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectEventsLister is an abstraction of ListProjectVisibleEvents()
+// in gitlab.EventsService.
+type ProjectEventsLister interface {
+	ListProjectVisibleEvents(
+		pid interface{},
+		opt *gitlab.ListProjectVisibleEventsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectEvent, *gitlab.Response, error)
+}
+
+// ProjectMergeRequestsLister is an abstraction of
+// ListProjectMergeRequests() in gitlab.MergeRequestsService.
+type ProjectMergeRequestsLister interface {
+	ListProjectMergeRequests(
+		pid interface{},
+		opt *gitlab.ListProjectMergeRequestsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.MergeRequest, *gitlab.Response, error)
+}
+
+// MergeRequestNotesLister is an abstraction of ListMergeRequestNotes()
+// in gitlab.NotesService.
+type MergeRequestNotesLister interface {
+	ListMergeRequestNotes(
+		pid interface{},
+		mergeRequest int,
+		opt *gitlab.ListMergeRequestNotesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Note, *gitlab.Response, error)
+}
+
+// GetAllProjectEvents returns the events of the action type (e.g.,
+// gitlab.PushedEventType) in the project which can be the project ID
+// or its full path.  Only events on days strictly after the day of
+// after and strictly before the day of before are returned which is
+// how Gitlab compares the dates.
+func GetAllProjectEvents(
+	ctx context.Context,
+	s ProjectEventsLister, /* was *gitlab.EventsService */
+	project interface{},
+	action gitlab.EventTypeValue,
+	after time.Time,
+	before time.Time,
+) ([]*gitlab.ProjectEvent, error) {
+
+	// Get each page of events.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.ProjectEvent, *gitlab.Response, error) {
+		opts := gitlab.ListProjectVisibleEventsOptions{
+			Action: gitlab.Ptr(action),
+			After:  gitlab.Ptr(gitlab.ISOTime(after)),
+			Before: gitlab.Ptr(gitlab.ISOTime(before)),
+		}
+		opts.Page = page
+		es, resp, err := s.ListProjectVisibleEvents(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectEvents: %w", ClassifyError(err))
+		}
+		return es, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProjectMergeRequests returns the merge requests in any state
+// that were updated after updatedAfter in the project which can be the
+// project ID or its full path.
+func GetAllProjectMergeRequests(
+	ctx context.Context,
+	s ProjectMergeRequestsLister, /* was *gitlab.MergeRequestsService */
+	project interface{},
+	updatedAfter time.Time,
+) ([]*gitlab.MergeRequest, error) {
+
+	// Get each page of merge requests.  Note that each call gets its
+	// own copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+		opts := gitlab.ListProjectMergeRequestsOptions{
+			State:        gitlab.Ptr("all"),
+			UpdatedAfter: gitlab.Ptr(updatedAfter),
+		}
+		opts.Page = page
+		mrs, resp, err := s.ListProjectMergeRequests(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectMergeRequests: %w", ClassifyError(err))
+		}
+		return mrs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllMergeRequestNotes returns the notes of the merge request
+// having the IID in the project which can be the project ID or its
+// full path.  The notes include system notes such as approvals and are
+// sorted from oldest to newest.
+func GetAllMergeRequestNotes(
+	ctx context.Context,
+	s MergeRequestNotesLister, /* was *gitlab.NotesService */
+	project interface{},
+	iid int,
+) ([]*gitlab.Note, error) {
+
+	// Get each page of notes.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Note, *gitlab.Response, error) {
+		opts := gitlab.ListMergeRequestNotesOptions{
+			OrderBy: gitlab.Ptr("created_at"),
+			Sort:    gitlab.Ptr("asc"),
+		}
+		opts.Page = page
+		notes, resp, err := s.ListMergeRequestNotes(project, iid, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllMergeRequestNotes: %w", ClassifyError(err))
+		}
+		return notes, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
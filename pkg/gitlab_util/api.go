@@ -0,0 +1,158 @@
+// This file provides utility functions for sending arbitrary requests
+// to the REST API so that endpoints not otherwise supported can still
+// be reached using the authentication of a gitlab.Client.
+
+package gitlab_util
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// APIRequest is an arbitrary request for the REST API.
+type APIRequest struct {
+
+	// Method is the HTTP method (e.g., "GET").
+	Method string
+
+	// Path is the path of the endpoint relative to the "api/v4"
+	// REST endpoint (e.g., "projects/foo%2Fbar/hooks").  It can
+	// include a query string.
+	Path string
+
+	// Query are the parameters that are added to the query string.
+	Query url.Values
+
+	// Body is the JSON body of the request or nil if the request
+	// does not have a body.
+	Body json.RawMessage
+}
+
+// splitAPIPath splits the path into the part relative to the "api/v4"
+// REST endpoint and its query string.  Leading slashes and an
+// "api/v4/" prefix are removed so the path can be copied from the
+// Gitlab API documentation as is.
+func splitAPIPath(path string) (string, string) {
+	path, rawQuery, _ := strings.Cut(path, "?")
+	path = strings.TrimLeft(path, "/")
+	path = strings.TrimPrefix(path, "api/v4/")
+	return path, rawQuery
+}
+
+// DoAPIRequest sends the request for the page of results using the
+// client and returns the raw body of the response.  If page is less
+// than 1, the page is not added to the query string.
+func DoAPIRequest(
+	ctx context.Context,
+	client *gitlab.Client,
+	request *APIRequest,
+	page int,
+) ([]byte, *gitlab.Response, error) {
+
+	// Create the request.  The body is passed as json.RawMessage so
+	// it is sent without being encoded again.
+	path, rawQuery := splitAPIPath(request.Path)
+	var body any
+	if request.Body != nil {
+		body = request.Body
+	}
+	req, err := client.NewRequest(request.Method, path, body,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("DoAPIRequest: %w", err)
+	}
+
+	// Set the query string.  The gitlab.Client only knows how to
+	// encode structs so we encode the parameters ourselves.
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("DoAPIRequest: %w", err)
+	}
+	for k, vs := range request.Query {
+		for _, v := range vs {
+			query.Add(k, v)
+		}
+	}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	req.URL.RawQuery = query.Encode()
+
+	// Send the request.  Note that client.Do() adds the
+	// authentication headers.
+	var out bytes.Buffer
+	resp, err := client.Do(req, &out)
+	if err != nil {
+		return nil, resp, fmt.Errorf("DoAPIRequest: %w", ClassifyError(err))
+	}
+
+	return out.Bytes(), resp, nil
+}
+
+// DoAllAPIRequestPages sends the GET request for every page of
+// results and returns a single JSON array with the items of all of
+// the pages.  Both offset-based pagination (the X-Next-Page header)
+// and keyset-based pagination (the "next" link of the Link header) are
+// followed.  It is an error if a page is not a JSON array.
+func DoAllAPIRequestPages(
+	ctx context.Context,
+	client *gitlab.Client,
+	request *APIRequest,
+) ([]byte, error) {
+	if request.Method != http.MethodGet {
+		return nil, fmt.Errorf(
+			"DoAllAPIRequestPages: cannot paginate %s requests", request.Method)
+	}
+	items := []json.RawMessage{}
+	for n, page := 1, 1; ; n++ {
+		body, resp, err := DoAPIRequest(ctx, client, request, page)
+		if err != nil {
+			return nil, fmt.Errorf("DoAllAPIRequestPages: %w", err)
+		}
+		var pageItems []json.RawMessage
+		err = json.Unmarshal(body, &pageItems)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"DoAllAPIRequestPages: page %d is not a JSON array: %w", n, err)
+		}
+		items = append(items, pageItems...)
+
+		// Move to the next page.  Keyset-based pagination has no
+		// page number; the link carries the cursor instead.
+		if resp.NextPage != 0 {
+			page = resp.NextPage
+		} else if resp.NextLink != "" {
+			request, err = withNextLink(request, resp.NextLink)
+			if err != nil {
+				return nil, fmt.Errorf("DoAllAPIRequestPages: %w", err)
+			}
+			page = 0
+		} else {
+			break
+		}
+	}
+	return json.Marshal(items)
+}
+
+// withNextLink returns a copy of the request for the "next" link of
+// the Link header used by keyset-based pagination.  The link has every
+// query parameter of the next page so they replace the query string of
+// the request.
+func withNextLink(request *APIRequest, nextLink string) (*APIRequest, error) {
+	u, err := url.Parse(nextLink)
+	if err != nil {
+		return nil, fmt.Errorf("invalid next link: %q: %w", nextLink, err)
+	}
+	result := *request
+	result.Path, _, _ = strings.Cut(request.Path, "?")
+	result.Query = u.Query()
+	return &result, nil
+}
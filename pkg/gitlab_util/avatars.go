@@ -0,0 +1,31 @@
+// This file provides abstractions of the methods that upload avatars.
+
+package gitlab_util
+
+import (
+	"io"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectAvatarUploader is an abstraction of UploadAvatar() in
+// gitlab.ProjectsService.
+type ProjectAvatarUploader interface {
+	UploadAvatar(
+		pid interface{},
+		avatar io.Reader,
+		filename string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Project, *gitlab.Response, error)
+}
+
+// GroupAvatarUploader is an abstraction of UploadAvatar() in
+// gitlab.GroupsService.
+type GroupAvatarUploader interface {
+	UploadAvatar(
+		gid interface{},
+		avatar io.Reader,
+		filename string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Group, *gitlab.Response, error)
+}
@@ -0,0 +1,190 @@
+// This file provides LookupCache which remembers the IDs of groups
+// found by their full path in a file so later invocations of the
+// program can get a group directly by its ID instead of searching for
+// it page by page.
+
+package gitlab_util
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultLookupCacheTTL is how long a cached ID is used before the
+// group is searched for again.
+const DefaultLookupCacheTTL = 24 * time.Hour
+
+// LookupCache maps the full paths of groups to their IDs.  Because the
+// same path can refer to different groups on different Gitlab
+// instances, each entry is scoped (e.g., by the base URL of the
+// instance).  Entries expire after the TTL.  If the cache has a file
+// name, the entries are loaded from the file on first use and written
+// back to it on each change so they outlive the process.  A
+// LookupCache is safe for concurrent use.
+type LookupCache struct {
+	mutex    sync.Mutex
+	fileName string
+	ttl      time.Duration
+	entries  map[lookupCacheKey]*lookupCacheEntry
+}
+
+// lookupCacheKey is the key for LookupCache.entries.
+type lookupCacheKey struct {
+	scope string
+	kind  string
+	path  string
+}
+
+// lookupCacheFile is the format of the file that holds the entries.
+type lookupCacheFile struct {
+	XMLName xml.Name            `xml:"lookup-cache"`
+	Entries []*lookupCacheEntry `xml:"entry"`
+}
+
+// lookupCacheEntry is a single entry of the cache.
+type lookupCacheEntry struct {
+	Scope   string    `xml:"scope"`
+	Kind    string    `xml:"kind"`
+	Path    string    `xml:"path"`
+	ID      int       `xml:"id"`
+	Expires time.Time `xml:"expires"`
+}
+
+// NewLookupCache returns a new LookupCache whose entries expire after
+// ttl.  If fileName is empty, the entries are only kept in memory.
+func NewLookupCache(fileName string, ttl time.Duration) *LookupCache {
+	return &LookupCache{
+		fileName: fileName,
+		ttl:      ttl,
+	}
+}
+
+// FileName returns the name of the file that holds the entries or ""
+// if the entries are only kept in memory.
+func (c *LookupCache) FileName() string {
+	return c.fileName
+}
+
+// load loads the entries from the file if they have not been loaded
+// yet.  The cache is only an optimization, so a missing or corrupt
+// file is treated as an empty cache.  The caller must hold the lock.
+func (c *LookupCache) load() {
+	if c.entries != nil {
+		return
+	}
+	c.entries = make(map[lookupCacheKey]*lookupCacheEntry)
+	if c.fileName == "" {
+		return
+	}
+	data, err := os.ReadFile(c.fileName)
+	if err != nil {
+		return
+	}
+	var file lookupCacheFile
+	if xml.Unmarshal(data, &file) != nil {
+		return
+	}
+	for _, e := range file.Entries {
+		c.entries[lookupCacheKey{scope: e.Scope, kind: e.Kind, path: e.Path}] = e
+	}
+}
+
+// save writes the entries that have not expired to the file.  The file
+// is replaced atomically so a concurrent invocation of the program
+// never reads a partially written file.  The caller must hold the
+// lock.
+func (c *LookupCache) save() error {
+	if c.fileName == "" {
+		return nil
+	}
+	now := time.Now()
+	var file lookupCacheFile
+	for _, e := range c.entries {
+		if now.Before(e.Expires) {
+			file.Entries = append(file.Entries, e)
+		}
+	}
+	data, err := xml.MarshalIndent(&file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("LookupCache: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(c.fileName), 0700)
+	if err != nil {
+		return fmt.Errorf("LookupCache: %w", err)
+	}
+	tmp := c.fileName + ".tmp"
+	err = os.WriteFile(tmp, append(data, '\n'), 0600)
+	if err != nil {
+		return fmt.Errorf("LookupCache: %w", err)
+	}
+	err = os.Rename(tmp, c.fileName)
+	if err != nil {
+		return fmt.Errorf("LookupCache: %w", err)
+	}
+	return nil
+}
+
+// Get returns the ID cached for the path of the kind (e.g., "group")
+// in the scope.  The boolean return value is false if there is no
+// entry or if the entry has expired.
+func (c *LookupCache) Get(scope string, kind string, path string) (int, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.load()
+	e, ok := c.entries[lookupCacheKey{scope: scope, kind: kind, path: path}]
+	if !ok || !time.Now().Before(e.Expires) {
+		return 0, false
+	}
+	return e.ID, true
+}
+
+// Put caches the ID for the path of the kind (e.g., "group") in the
+// scope.
+func (c *LookupCache) Put(scope string, kind string, path string, id int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.load()
+	c.entries[lookupCacheKey{scope: scope, kind: kind, path: path}] = &lookupCacheEntry{
+		Scope:   scope,
+		Kind:    kind,
+		Path:    path,
+		ID:      id,
+		Expires: time.Now().Add(c.ttl),
+	}
+	return c.save()
+}
+
+// Delete removes the entry for the path of the kind (e.g., "group") in
+// the scope which is useful when the cached ID turns out to be stale.
+func (c *LookupCache) Delete(scope string, kind string, path string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.load()
+	key := lookupCacheKey{scope: scope, kind: kind, path: path}
+	if _, ok := c.entries[key]; !ok {
+		return nil
+	}
+	delete(c.entries, key)
+	return c.save()
+}
+
+// Clear removes all the entries including the file.
+func (c *LookupCache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[lookupCacheKey]*lookupCacheEntry)
+	if c.fileName == "" {
+		return nil
+	}
+	err := os.Remove(c.fileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("LookupCache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestLookupCache(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "glcmds", "lookup-cache.xml")
+
+	// Cache an ID and verify a new cache loads it from the file.
+	cache := NewLookupCache(fileName, time.Hour)
+	err := cache.Put("https://a.example.com/", "group", "foo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache = NewLookupCache(fileName, time.Hour)
+
+	type Data []struct {
+		name     string
+		expected any
+		actual   any
+	}
+	get := func(scope string, path string) string {
+		id, ok := cache.Get(scope, "group", path)
+		return fmt.Sprint(id, ok)
+	}
+	data := Data{
+		{"cached", "1 true", get("https://a.example.com/", "foo")},
+		{"other scope", "0 false", get("https://b.example.com/", "foo")},
+		{"other path", "0 false", get("https://a.example.com/", "foo/bar")},
+	}
+
+	// Verify expired entries are not used.
+	expired := NewLookupCache("", 0)
+	err = expired.Put("https://a.example.com/", "group", "foo", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, ok := expired.Get("https://a.example.com/", "group", "foo")
+	data = append(data, Data{{"expired", false, ok}}...)
+
+	// Verify clearing removes the entries including the file.
+	err = cache.Clear()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache = NewLookupCache(fileName, time.Hour)
+	data = append(data, Data{{"cleared", "0 false", get("https://a.example.com/", "foo")}}...)
+
+	for _, d := range data {
+		if fmt.Sprint(d.expected) != fmt.Sprint(d.actual) {
+			t.Errorf("%s: expected=%v  actual=%v", d.name, d.expected, d.actual)
+		}
+	}
+}
+
+func TestGroupMemoLookupCache(t *testing.T) {
+	var searches, gets int
+
+	// Create a fake Gitlab server that counts the number of group
+	// searches and the number of groups gotten by ID.  The group
+	// with ID 3 has moved so its cached ID is stale.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/groups", func(w http.ResponseWriter, r *http.Request) {
+		searches++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": 1, "full_path": "foo"}, {"id": 2, "full_path": "foo/bar"}]`)
+	})
+	mux.HandleFunc("/api/v4/groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		gets++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PathValue("id") {
+		case "1":
+			fmt.Fprintf(w, `{"id": 1, "full_path": "foo"}`)
+		case "2":
+			fmt.Fprintf(w, `{"id": 2, "full_path": "foo/bar"}`)
+		case "3":
+			fmt.Fprintf(w, `{"id": 3, "full_path": "moved"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"message": "404 Group Not Found"}`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// find looks up the group using a new memo, as a new invocation
+	// of the program would, backed by a cache in the same file.
+	fileName := filepath.Join(t.TempDir(), "lookup-cache.xml")
+	scope := client.BaseURL().String()
+	find := func(path string) {
+		memo := NewGroupMemo()
+		memo.SetLookupCache(client.Groups, NewLookupCache(fileName, time.Hour), scope)
+		g, err := memo.FindExactGroup(context.Background(), client.Groups, path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if g.FullPath != path {
+			t.Errorf("FindExactGroup: expected=%q  actual=%q", path, g.FullPath)
+		}
+	}
+
+	// The first lookup searches, and the later ones get the group by
+	// its cached ID.
+	for i := 0; i < 3; i++ {
+		find("foo")
+	}
+	if searches != 1 || gets != 2 {
+		t.Errorf("FindExactGroup: expected=1 search and 2 gets  actual=%d and %d",
+			searches, gets)
+	}
+
+	// A stale ID falls back to searching.
+	err = NewLookupCache(fileName, time.Hour).Put(scope, "group", "foo/bar", 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	searches, gets = 0, 0
+	find("foo/bar")
+	find("foo/bar")
+	if searches != 1 || gets != 2 {
+		t.Errorf("FindExactGroup: expected=1 search and 2 gets  actual=%d and %d",
+			searches, gets)
+	}
+}
+
+func TestGroupMemoTTL(t *testing.T) {
+	var searches int
+
+	// Create a fake Gitlab server that counts the number of group
+	// searches.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/groups", func(w http.ResponseWriter, r *http.Request) {
+		searches++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": 1, "full_path": "foo"}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// With a tiny TTL, every lookup searches again.
+	memo := NewGroupMemo()
+	memo.TTL = time.Nanosecond
+	for i := 0; i < 2; i++ {
+		time.Sleep(time.Millisecond)
+		_, err = memo.FindExactGroup(context.Background(), client.Groups, "foo")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if searches != 2 {
+		t.Errorf("FindExactGroup: expected=%d searches  actual=%d searches",
+			2, searches)
+	}
+}
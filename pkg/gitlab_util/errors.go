@@ -0,0 +1,72 @@
+// This file provides the errors returned by the functions in this
+// package.
+
+package gitlab_util
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Errors
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: The errors below are always wrapped so callers should test
+// for them using errors.Is() instead of comparing error strings.
+// When an error is caused by a Gitlab REST API response, the
+// *gitlab.ErrorResponse is wrapped too so callers can still use
+// errors.As() to get at the original response.
+//
+
+var (
+	// ErrGroupNotFound is returned when a group cannot be found.
+	ErrGroupNotFound = errors.New("group not found")
+
+	// ErrUserNotFound is returned when a user cannot be found.
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrAmbiguousUser is returned when a user string exactly
+	// matches more than one user.
+	ErrAmbiguousUser = errors.New("ambiguous user")
+
+	// ErrNotFound is returned when Gitlab responds with 404 Not Found.
+	ErrNotFound = errors.New("not found")
+
+	// ErrPermissionDenied is returned when Gitlab responds with 401
+	// Unauthorized or 403 Forbidden.
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrRateLimited is returned when Gitlab responds with 429 Too
+	// Many Requests.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrGraphQLUnavailable is returned when the GraphQL API does not
+	// exist on the server (e.g., because it has been disabled).
+	ErrGraphQLUnavailable = errors.New("GraphQL API unavailable")
+)
+
+// ClassifyError wraps err with ErrNotFound, ErrPermissionDenied, or
+// ErrRateLimited based on the HTTP status code of the
+// *gitlab.ErrorResponse that err wraps.  If err is nil or does not
+// wrap a *gitlab.ErrorResponse with one of these status codes, err is
+// returned unchanged.
+func ClassifyError(err error) error {
+	var resp *gitlab.ErrorResponse
+	if !errors.As(err, &resp) || resp.Response == nil {
+		return err
+	}
+	switch resp.Response.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	}
+	return err
+}
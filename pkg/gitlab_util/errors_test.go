@@ -0,0 +1,48 @@
+package gitlab_util
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestClassifyError(t *testing.T) {
+	type Data []struct {
+		status   int
+		expected error
+	}
+
+	data := Data{
+		{status: http.StatusNotFound, expected: ErrNotFound},
+		{status: http.StatusUnauthorized, expected: ErrPermissionDenied},
+		{status: http.StatusForbidden, expected: ErrPermissionDenied},
+		{status: http.StatusTooManyRequests, expected: ErrRateLimited},
+		{status: http.StatusInternalServerError, expected: nil},
+	}
+
+	for _, d := range data {
+		resp := &gitlab.ErrorResponse{
+			Response: &http.Response{StatusCode: d.status},
+		}
+		err := ClassifyError(fmt.Errorf("wrapped: %w", resp))
+
+		// Verify the sentinel error was added.
+		if d.expected != nil && !errors.Is(err, d.expected) {
+			t.Errorf("%v: expected=%v  actual=%v", d.status, d.expected, err)
+		}
+
+		// Verify the original response is still available.
+		var actual *gitlab.ErrorResponse
+		if !errors.As(err, &actual) || actual != resp {
+			t.Errorf("%v: expected=%v  actual=%v", d.status, resp, actual)
+		}
+	}
+
+	// Verify nil is passed through.
+	if err := ClassifyError(nil); err != nil {
+		t.Errorf("nil: expected=%v  actual=%v", nil, err)
+	}
+}
@@ -0,0 +1,66 @@
+// This file provides the event hooks that let callers observe
+// long-running operations.
+
+package gitlab_util
+
+import (
+	"context"
+)
+
+////////////////////////////////////////////////////////////////////////
+// EventHook
+////////////////////////////////////////////////////////////////////////
+
+// EventHook is implemented by callers that want to observe
+// long-running operations (e.g., to drive a progress bar or a GUI)
+// without having to parse console output.  The hook is attached to
+// the context with WithEventHook() and is then used by the iterators
+// in this package and by the bulk operations in the commands package.
+// Implementations that only care about some of the events can embed
+// NopEventHook.  Because pages are prefetched, implementations must
+// be safe to call from multiple goroutines.
+type EventHook interface {
+
+	// OnItemStart is called before the item is processed.  The name
+	// identifies the item (e.g., the full path of a project).
+	OnItemStart(name string)
+
+	// OnItemDone is called after the item was processed
+	// successfully.
+	OnItemDone(name string)
+
+	// OnError is called when processing the item failed.  The name
+	// is empty if the error is not specific to an item (e.g., when
+	// getting a page of results fails).
+	OnError(name string, err error)
+
+	// OnPage is called after each page of results is received.
+	// totalPages is zero if Gitlab did not report the total.
+	OnPage(page int, totalPages int, count int)
+}
+
+// NopEventHook is an EventHook that ignores all events.
+type NopEventHook struct{}
+
+func (NopEventHook) OnItemStart(name string)                    {}
+func (NopEventHook) OnItemDone(name string)                     {}
+func (NopEventHook) OnError(name string, err error)             {}
+func (NopEventHook) OnPage(page int, totalPages int, count int) {}
+
+// eventHookKey is the context key for the EventHook.
+type eventHookKey struct{}
+
+// WithEventHook returns a copy of ctx with the hook attached.
+func WithEventHook(ctx context.Context, hook EventHook) context.Context {
+	return context.WithValue(ctx, eventHookKey{}, hook)
+}
+
+// EventHookFromContext returns the EventHook attached to ctx or a
+// NopEventHook if there is none so callers never have to check for
+// nil.
+func EventHookFromContext(ctx context.Context) EventHook {
+	if hook, ok := ctx.Value(eventHookKey{}).(EventHook); ok && hook != nil {
+		return hook
+	}
+	return NopEventHook{}
+}
@@ -0,0 +1,1050 @@
+// This file provides utility functions related to the Gitlab REST API.
+
+package gitlab_util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc64"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Service Interfaces
+////////////////////////////////////////////////////////////////////////
+
+//
+// NOTE: The functions in this package accept the narrow interfaces
+// below instead of the concrete services in gitlab.Client (e.g.,
+// *gitlab.GroupsService) so they can be unit tested with stubs
+// instead of requiring a live Gitlab server.  The concrete services
+// in gitlab.Client satisfy these interfaces so callers can just pass
+// in client.Groups, client.Projects, or client.Users.
+//
+
+// GroupGetter is an abstraction of GetGroup() in
+// gitlab.GroupsService.
+type GroupGetter interface {
+	GetGroup(
+		gid interface{},
+		opt *gitlab.GetGroupOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Group, *gitlab.Response, error)
+}
+
+// GroupsLister is an abstraction of ListGroups() in
+// gitlab.GroupsService.
+type GroupsLister interface {
+	ListGroups(
+		opt *gitlab.ListGroupsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Group, *gitlab.Response, error)
+}
+
+// GroupProjectsLister is an abstraction of ListGroupProjects() in
+// gitlab.GroupsService.
+type GroupProjectsLister interface {
+	ListGroupProjects(
+		gid interface{},
+		opt *gitlab.ListGroupProjectsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Project, *gitlab.Response, error)
+}
+
+// GroupFinder is the set of gitlab.GroupsService methods needed by
+// FindExactGroup().
+type GroupFinder interface {
+	GroupGetter
+	GroupsLister
+}
+
+// ProjectsInGroupLister is the set of gitlab.GroupsService methods
+// needed by ForEachProjectInGroup().
+type ProjectsInGroupLister interface {
+	GroupFinder
+	GroupProjectsLister
+}
+
+// GroupCreator is an abstraction of CreateGroup() in
+// gitlab.GroupsService.
+type GroupCreator interface {
+	CreateGroup(
+		opt *gitlab.CreateGroupOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Group, *gitlab.Response, error)
+}
+
+// GroupDeleter is an abstraction of DeleteGroup() in
+// gitlab.GroupsService.
+type GroupDeleter interface {
+	DeleteGroup(
+		gid interface{},
+		opt *gitlab.DeleteGroupOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// ProjectsLister is an abstraction of ListProjects() in
+// gitlab.ProjectsService.
+type ProjectsLister interface {
+	ListProjects(
+		opt *gitlab.ListProjectsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Project, *gitlab.Response, error)
+}
+
+// ProjectGetter is an abstraction of GetProject() in
+// gitlab.ProjectsService.
+type ProjectGetter interface {
+	GetProject(
+		pid interface{},
+		opt *gitlab.GetProjectOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Project, *gitlab.Response, error)
+}
+
+// ProjectCreator is an abstraction of CreateProject() in
+// gitlab.ProjectsService.
+type ProjectCreator interface {
+	CreateProject(
+		opt *gitlab.CreateProjectOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Project, *gitlab.Response, error)
+}
+
+// ProjectDeleter is an abstraction of DeleteProject() in
+// gitlab.ProjectsService.
+type ProjectDeleter interface {
+	DeleteProject(
+		pid interface{},
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// ApprovalRuleUpdater is an abstraction of
+// UpdateProjectApprovalRule() in gitlab.ProjectsService.
+type ApprovalRuleUpdater interface {
+	UpdateProjectApprovalRule(
+		pid interface{},
+		approvalRule int,
+		opt *gitlab.UpdateProjectLevelRuleOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectApprovalRule, *gitlab.Response, error)
+}
+
+// ApprovalRuleCreator is an abstraction of
+// CreateProjectApprovalRule() in gitlab.ProjectsService.
+type ApprovalRuleCreator interface {
+	CreateProjectApprovalRule(
+		pid interface{},
+		opt *gitlab.CreateProjectLevelRuleOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectApprovalRule, *gitlab.Response, error)
+}
+
+// UserCreator is an abstraction of CreateUser() in
+// gitlab.UsersService.
+type UserCreator interface {
+	CreateUser(
+		opt *gitlab.CreateUserOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.User, *gitlab.Response, error)
+}
+
+// UserDeleter is an abstraction of DeleteUser() in
+// gitlab.UsersService.
+type UserDeleter interface {
+	DeleteUser(
+		user int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// UserGetter is an abstraction of GetUser() in gitlab.UsersService.
+type UserGetter interface {
+	GetUser(
+		user int,
+		opt gitlab.GetUsersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.User, *gitlab.Response, error)
+}
+
+// UsersLister is an abstraction of ListUsers() in
+// gitlab.UsersService.
+type UsersLister interface {
+	ListUsers(
+		opt *gitlab.ListUsersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.User, *gitlab.Response, error)
+}
+
+// UserFinder is the set of gitlab.UsersService methods needed by
+// FindUsers().
+type UserFinder interface {
+	UserGetter
+	UsersLister
+}
+
+////////////////////////////////////////////////////////////////////////
+// Pagination
+////////////////////////////////////////////////////////////////////////
+
+// pageResult holds the result of fetching a single page.
+type pageResult[T any] struct {
+	items []T
+	resp  *gitlab.Response
+	err   error
+}
+
+// PageCursor identifies a page of results.  For keyset pagination,
+// Keyset is true, and NextLink is the "next" link from the Link header
+// of the previous page or empty for the first page.  For offset
+// pagination, Page is the page number.
+type PageCursor struct {
+	Keyset   bool
+	NextLink string
+	Page     int
+}
+
+// Apply sets the pagination fields of opts for the page and returns
+// the request options that must also be passed to the Gitlab list
+// function to get the page.
+func (c PageCursor) Apply(opts *gitlab.ListOptions) []gitlab.RequestOptionFunc {
+	if !c.Keyset {
+		opts.Page = c.Page
+		return nil
+	}
+	opts.Pagination = "keyset"
+	if c.NextLink == "" {
+		return nil
+	}
+	return []gitlab.RequestOptionFunc{
+		gitlab.WithKeysetPaginationParameters(c.NextLink),
+	}
+}
+
+// next returns the cursor for the page after the page for which resp
+// is the response or nil if there are no more pages.  Gitlab ignores
+// the request for keyset pagination for endpoints that do not support
+// it and responds with the offset pagination headers instead in which
+// case the returned cursor falls back to offset pagination.
+func (c PageCursor) next(resp *gitlab.Response) *PageCursor {
+	if c.Keyset && resp.NextLink != "" {
+		return &PageCursor{Keyset: true, NextLink: resp.NextLink}
+	}
+	if resp.NextPage != 0 {
+		return &PageCursor{Page: resp.NextPage}
+	}
+	return nil
+}
+
+// isKeysetUnsupported returns true if err is the error Gitlab returns
+// when keyset pagination is not supported for the endpoint or for the
+// requested order.
+func isKeysetUnsupported(err error) bool {
+	var resp *gitlab.ErrorResponse
+	if !errors.As(err, &resp) || resp.Response == nil {
+		return false
+	}
+	switch resp.Response.StatusCode {
+	case http.StatusBadRequest, http.StatusMethodNotAllowed:
+		return true
+	}
+	return false
+}
+
+// forEachPrefetchedCursorPage calls getPage to get each page starting
+// with the first page and then calls f once for each item on the
+// page.  While f is processing the items on one page, the next page is
+// prefetched concurrently which overlaps the network latency of
+// getting the next page with the processing of the current page.  The
+// function f must return true and no error to indicate that it wants
+// to continue being called with the remaining items.  If f returns an
+// error, it will be forwarded to the caller as the error return value
+// for this function.  Errors returned by getPage are returned as is.
+//
+// If first requests keyset pagination and Gitlab rejects the request
+// because keyset pagination is not supported, the first page is
+// requested again using offset pagination, and offset pagination is
+// used for the remaining pages.
+//
+// Note that getPage is called from a different goroutine than f so
+// getPage must not share mutable state (e.g., the options passed to
+// the Gitlab list functions) with anything else.  If ctx is done
+// before all the items have been processed, f is not called with the
+// remaining items, and ctx.Err() is returned.
+func forEachPrefetchedCursorPage[T any](
+	ctx context.Context,
+	first PageCursor,
+	getPage func(cursor PageCursor) ([]T, *gitlab.Response, error),
+	f func(item T) (bool, error),
+) error {
+
+	// fetch gets the page in a separate goroutine and returns the
+	// channel on which the result will be sent.  The channel is
+	// buffered so the goroutine does not leak if we return early
+	// without waiting for the result.
+	fetch := func(cursor PageCursor) <-chan pageResult[T] {
+		ch := make(chan pageResult[T], 1)
+		go func() {
+			items, resp, err := getPage(cursor)
+			ch <- pageResult[T]{items: items, resp: resp, err: err}
+		}()
+		return ch
+	}
+
+	// Iterate over each page.
+	hook := EventHookFromContext(ctx)
+	page := 1
+	cursor := first
+	next := fetch(cursor)
+	for next != nil {
+
+		// Wait for the page falling back to offset pagination if
+		// keyset pagination is not supported.
+		result := <-next
+		if result.err != nil && cursor.Keyset && cursor.NextLink == "" &&
+			isKeysetUnsupported(result.err) {
+			cursor = PageCursor{Page: 1}
+			result = <-fetch(cursor)
+		}
+		if result.err != nil {
+			hook.OnError("", result.err)
+			return result.err
+		}
+		hook.OnPage(page, result.resp.TotalPages, len(result.items))
+
+		// Stop if the context is done.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Start prefetching the next page before processing the
+		// current page.
+		next = nil
+		if c := cursor.next(result.resp); c != nil {
+			cursor = *c
+			page++
+			next = fetch(cursor)
+		}
+
+		// Invoke the callback for each item on the current page.
+		for _, item := range result.items {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			more, err := f(item)
+			if err != nil {
+				return err
+			}
+			if !more {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// forEachPrefetchedPage is the same as forEachPrefetchedCursorPage()
+// except only offset pagination is used, and getPage is passed the
+// page number starting with page 1.
+func forEachPrefetchedPage[T any](
+	ctx context.Context,
+	getPage func(page int) ([]T, *gitlab.Response, error),
+	f func(item T) (bool, error),
+) error {
+	return forEachPrefetchedCursorPage(ctx, PageCursor{Page: 1},
+		func(cursor PageCursor) ([]T, *gitlab.Response, error) {
+			return getPage(cursor.Page)
+		}, f)
+}
+
+// forEachPrefetchedKeysetPage is the same as
+// forEachPrefetchedCursorPage() except keyset pagination is requested
+// for the first page.  Keyset pagination is much faster than offset
+// pagination for very large result sets and is not affected by items
+// being added or removed while paging.  The cursor passed to getPage
+// should be applied to the options passed to the Gitlab list function
+// using [PageCursor.Apply()].
+func forEachPrefetchedKeysetPage[T any](
+	ctx context.Context,
+	getPage func(cursor PageCursor) ([]T, *gitlab.Response, error),
+	f func(item T) (bool, error),
+) error {
+	return forEachPrefetchedCursorPage(ctx, PageCursor{Keyset: true}, getPage, f)
+}
+
+// GetAllPages calls getPage to get each page starting with page 1 and
+// returns all the items on all the pages.  The next page is
+// prefetched as described for forEachPrefetchedPage() so getPage must
+// not share mutable state with anything else.
+func GetAllPages[T any](
+	ctx context.Context,
+	getPage func(page int) ([]T, *gitlab.Response, error),
+) ([]T, error) {
+	var result []T
+	err := forEachPrefetchedPage(ctx, getPage, func(item T) (bool, error) {
+		result = append(result, item)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetAllKeysetPages is the same as GetAllPages() except keyset
+// pagination is used if the endpoint supports it as described for
+// forEachPrefetchedKeysetPage().
+func GetAllKeysetPages[T any](
+	ctx context.Context,
+	getPage func(cursor PageCursor) ([]T, *gitlab.Response, error),
+) ([]T, error) {
+	var result []T
+	err := forEachPrefetchedKeysetPage(ctx, getPage, func(item T) (bool, error) {
+		result = append(result, item)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// Groups
+////////////////////////////////////////////////////////////////////////
+
+// GroupFullPaths returns just the full paths for the groups.
+func GroupFullPaths(groups []*gitlab.Group) []string {
+	result := make([]string, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, group.FullPath)
+	}
+	return result
+}
+
+// GroupMemo memoizes the groups found by FindExactGroup() so the
+// expensive search for a group executes at most once per unique
+// group for the lifetime of the GroupMemo.  Because the same group
+// path can refer to different groups on different Gitlab instances,
+// the memo is keyed by both the service used for the lookup and the
+// group search string.  Thus, the dynamic type of the service must be
+// comparable (e.g., a pointer).  A GroupMemo is safe for concurrent
+// use.
+//
+// A [LookupCache] can be attached for each service so the ID of a
+// group that was found by an earlier invocation of the program is
+// used to get the group directly instead of searching for it.
+type GroupMemo struct {
+
+	// TTL is how long a memoized group is used before it is looked up
+	// again.  Zero means the group is memoized for the lifetime of
+	// the GroupMemo.  It must be set before the GroupMemo is used.
+	TTL time.Duration
+
+	mutex  sync.Mutex
+	groups map[groupMemoKey]*groupMemoEntry
+	caches map[GroupFinder]*groupMemoCache
+}
+
+// groupMemoKey is the key for GroupMemo.groups.
+type groupMemoKey struct {
+	s     GroupFinder
+	group string
+}
+
+// groupMemoEntry is a memoized group.
+type groupMemoEntry struct {
+	group    *gitlab.Group
+	memoized time.Time
+}
+
+// groupMemoCache is the LookupCache attached to a service along with
+// the scope of its entries.
+type groupMemoCache struct {
+	cache *LookupCache
+	scope string
+}
+
+// NewGroupMemo returns a new, empty GroupMemo.
+func NewGroupMemo() *GroupMemo {
+	return &GroupMemo{
+		groups: make(map[groupMemoKey]*groupMemoEntry),
+		caches: make(map[GroupFinder]*groupMemoCache),
+	}
+}
+
+// SetLookupCache attaches the cache to the service so the IDs of the
+// groups found with the service are cached in the scope (e.g., the
+// base URL of the Gitlab instance).  If cache is nil, the cache is
+// detached.
+func (memo *GroupMemo) SetLookupCache(s GroupFinder, cache *LookupCache, scope string) {
+	memo.mutex.Lock()
+	defer memo.mutex.Unlock()
+	if cache == nil {
+		delete(memo.caches, s)
+		return
+	}
+	memo.caches[s] = &groupMemoCache{cache: cache, scope: scope}
+}
+
+// DefaultGroupMemo is the GroupMemo used by FindExactGroup().  It is
+// shared by all commands run by the current invocation of the
+// program.
+var DefaultGroupMemo = NewGroupMemo()
+
+// FindExactGroup returns the group that exactly matches the search
+// string using a previously memoized result if one exists.  See
+// [FindExactGroup()] for more.
+func (memo *GroupMemo) FindExactGroup(
+	ctx context.Context,
+	s GroupFinder, /* was *gitlab.GroupsService */
+	group string,
+) (*gitlab.Group, error) {
+
+	key := groupMemoKey{s: s, group: group}
+
+	// Return the memoized group if there is one that has not
+	// expired.
+	memo.mutex.Lock()
+	e, ok := memo.groups[key]
+	c := memo.caches[s]
+	memo.mutex.Unlock()
+	if ok && (memo.TTL == 0 || time.Since(e.memoized) < memo.TTL) {
+		return e.group, nil
+	}
+
+	// Look up the group.  Note that we do not hold the lock while
+	// waiting on Gitlab.  If two goroutines race to look up the same
+	// group, both lookups return the same group so it does not
+	// matter which one is memoized.
+	g, err := memo.findExactGroup(ctx, s, c, group)
+	if err != nil {
+		return nil, err
+	}
+
+	// Memoize the group.
+	memo.mutex.Lock()
+	memo.groups[key] = &groupMemoEntry{group: g, memoized: time.Now()}
+	memo.mutex.Unlock()
+
+	return g, nil
+}
+
+// findExactGroup returns the group that exactly matches the search
+// string.  If c is not nil, the group is first looked up by the ID in
+// the cache, and the ID of the group that is found is cached.  Because
+// the cache is only an optimization, errors reading or writing the
+// cache are ignored.
+func (memo *GroupMemo) findExactGroup(
+	ctx context.Context,
+	s GroupFinder,
+	c *groupMemoCache,
+	group string,
+) (*gitlab.Group, error) {
+	if c == nil {
+		return findExactGroup(ctx, s, group)
+	}
+
+	// Use the cached ID unless it is stale because, for example, the
+	// group has been moved or deleted.
+	if id, ok := c.cache.Get(c.scope, "group", group); ok {
+		g, err := findExactGroup(ctx, s, strconv.Itoa(id))
+		if err == nil && g.FullPath == group {
+			return g, nil
+		}
+		_ = c.cache.Delete(c.scope, "group", group)
+	}
+
+	// Search for the group and cache its ID.  A search string that is
+	// already an ID is not cached.
+	g, err := findExactGroup(ctx, s, group)
+	if err != nil {
+		return nil, err
+	}
+	if g.FullPath == group {
+		_ = c.cache.Put(c.scope, "group", group, g.ID)
+	}
+	return g, nil
+}
+
+// Clear removes all memoized groups.
+func (memo *GroupMemo) Clear() {
+	memo.mutex.Lock()
+	defer memo.mutex.Unlock()
+	clear(memo.groups)
+}
+
+// FindExactGroup returns the group that exactly matches the search
+// string.  If the group search string is an integer, it is assumed to
+// be a group ID.  Results are memoized in [DefaultGroupMemo] so
+// repeated lookups of the same group do not result in repeated
+// searches.
+func FindExactGroup(
+	ctx context.Context,
+	s GroupFinder, /* was *gitlab.GroupsService */
+	group string,
+) (*gitlab.Group, error) {
+	return DefaultGroupMemo.FindExactGroup(ctx, s, group)
+}
+
+// findExactGroup returns the group that exactly matches the search
+// string without consulting any memo.  If the group search string is
+// an integer, it is assumed to be a group ID.
+func findExactGroup(
+	ctx context.Context,
+	s GroupFinder,
+	group string,
+) (*gitlab.Group, error) {
+
+	// If "group" is an integer, it is a group ID which requires
+	// different processing.
+	groupID, err := strconv.Atoi(group)
+	if err == nil {
+		opts := gitlab.GetGroupOptions{}
+		g, _, err := s.GetGroup(groupID, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			err = ClassifyError(err)
+			if errors.Is(err, ErrNotFound) {
+				err = fmt.Errorf("%w: %w", ErrGroupNotFound, err)
+			}
+			return nil, fmt.Errorf("FindExactGroup: %w", err)
+		}
+		return g, nil
+	}
+	err = nil
+
+	// Set the group search string.
+	opts := gitlab.ListGroupsOptions{}
+	opts.Page = 1
+	opts.Search = gitlab.Ptr(group)
+
+	// Iterate over each page of groups.
+	for {
+
+		// Get a page of matching groups.
+		gs, resp, err := s.ListGroups(&opts, gitlab.WithContext(ctx))
+		if err != nil {
+			err = fmt.Errorf("FindExactGroup: %w", ClassifyError(err))
+			return nil, err
+		}
+
+		// Check each group for an exact match.
+		for _, g := range gs {
+			if g.FullPath == group {
+				return g, nil
+			}
+		}
+
+		// Check if done.
+		if resp.NextPage == 0 {
+			break
+		}
+
+		// Move to the next page.
+		opts.Page = resp.NextPage
+	}
+
+	// Could not find a matching group.
+	err = fmt.Errorf(
+		"FindExactGroup: %w: could not find exact match for group: %q",
+		ErrGroupNotFound, group)
+	return nil, err
+}
+
+////////////////////////////////////////////////////////////////////////
+// Projects
+////////////////////////////////////////////////////////////////////////
+
+// ForEachProjectInGroup iterates over the projects in a group (which
+// can be the full path to the group or the group ID) and recursively
+// or not) calls the function f once for each project whose full path
+// name matches the regular expression.  An empty regular expression
+// matches any string.  The function f must return true and no error
+// to indicate that it wants to continue being called with the
+// remaining projects.  If f returns an error, it will be forwarded to
+// the caller as the error return value for this function.  Prefer
+// this function over GetAllProjects() to avoid the long delay to the
+// user while waiting to collect all the projects.
+func ForEachProjectInGroup(
+	ctx context.Context,
+	s ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	group string,
+	expr string,
+	recursive bool,
+	f func(group *gitlab.Group, project *gitlab.Project) (bool, error),
+) error {
+
+	// Find the group.
+	g, err := FindExactGroup(ctx, s, group)
+	if err != nil {
+		return fmt.Errorf("ForEachProjectInGroup: %w", err)
+	}
+
+	// Compile the regexp.
+	r, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("ForEachProjectInGroup: %w", err)
+	}
+
+	// Set up the options for ListGroupProjects().
+	opts := gitlab.ListGroupProjectsOptions{}
+	opts.IncludeSubGroups = gitlab.Ptr(recursive)
+
+	// Get each page of projects.  Note that each call gets its own
+	// copy of opts because the next page is prefetched concurrently.
+	// Keyset pagination requires ordering by ID which, because IDs
+	// increase with time, is the same as the default ordering by
+	// creation date.
+	getPage := func(cursor PageCursor) ([]*gitlab.Project, *gitlab.Response, error) {
+		pageOpts := opts
+		if cursor.Keyset {
+			pageOpts.OrderBy = gitlab.Ptr("id")
+		}
+		options := cursor.Apply(&pageOpts.ListOptions)
+		ps, resp, err := s.ListGroupProjects(
+			g.ID, &pageOpts, append(options, gitlab.WithContext(ctx))...)
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"ForEachProjectInGroup: %w", ClassifyError(err))
+		}
+		return ps, resp, nil
+	}
+
+	// Invoke the callback for each project whose full path matches
+	// the regular expression.
+	return forEachPrefetchedKeysetPage(ctx, getPage, func(p *gitlab.Project) (bool, error) {
+		if !r.MatchString(p.PathWithNamespace) {
+			return true, nil
+		}
+		return f(g, p)
+	})
+}
+
+// GetAllProjects returns all the projects in a group recursively (or
+// not) for each project whose full path name matches the regular
+// expression.  An empty regular expression matches any string.
+// Prefer ForEachProjectInGroup() over this function to avoid the long
+// delay while waiting to collect all the projects.  The main reason
+// to use this function is when deleting projects because Gitlab's
+// paging gets confused because Gitlab's paging is relative to when
+// you make the request for the next page, not when you made the
+// request for the first page, and deleting projects necessarily
+// changes the page on which some remaining projects appear.  This
+// function is better to use when deleting projects because it
+// collects all the projects up front allowing the caller to delete
+// them with impunity because there will be no next page to get.
+func GetAllProjects(
+	ctx context.Context,
+	s ProjectsInGroupLister, /* was *gitlab.GroupsService */
+	group string,
+	expr string,
+	recursive bool,
+) ([]*gitlab.Project, error) {
+
+	var result []*gitlab.Project
+
+	// Callback function used to collect all of the projects.
+	f := func(group *gitlab.Group, project *gitlab.Project) (bool, error) {
+		result = append(result, project)
+		return true, nil
+	}
+
+	// Collect all the projects.
+	err := ForEachProjectInGroup(ctx, s, group, expr, recursive, f)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllProjects: %w", err)
+	}
+
+	return result, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// Approval Rules
+////////////////////////////////////////////////////////////////////////
+
+// GetApprovalRuleUsernames returns the sorted list of usernames for
+// the given approval rule.
+func GetApprovalRuleUsernames(rule *gitlab.ProjectApprovalRule) []string {
+	var usernames []string
+
+	// Extract the usernames of the eligible approvers.
+	for _, u := range rule.Users {
+		usernames = append(usernames, u.Username)
+	}
+
+	// Sort the usernames.
+	slices.Sort(usernames)
+
+	return usernames
+}
+
+// ApprovalRuleToString converts the approval rule into a
+// human-readable string.
+func ApprovalRuleToString(rule *gitlab.ProjectApprovalRule) string {
+
+	// Get the users for the approval rule.  Note that this does *not*
+	// include any groups that might also be part of the approval rule.
+	usernames := GetApprovalRuleUsernames(rule)
+
+	// Get the string representation of the list of usernames.
+	usernamesAsString := fmt.Sprintf("%q", usernames)
+
+	// Calculate the CRC-64 checksum of the usernames string.
+	cksum := crc64.Checksum(
+		[]byte(usernamesAsString),
+		crc64.MakeTable(crc64.ISO))
+
+	// Add rule ID and name.
+	return fmt.Sprintf("%#016x  %8d  %-16s  %s",
+		cksum, rule.ID, rule.Name, usernamesAsString)
+}
+
+// UpdateApprovalRule updates the approval rule for the project to
+// have the same values as before except with a new list of user IDs.
+// This function is designed to be the callback for
+// [ForEachApprovalRuleInProject()].
+func UpdateApprovalRule(
+	ctx context.Context,
+	s ApprovalRuleUpdater, /* was *gitlab.ProjectsService */
+	projectID int,
+	rule *gitlab.ProjectApprovalRule,
+	userIDs []int,
+) (
+	*gitlab.ProjectApprovalRule,
+	error,
+){
+	var err error
+	var newRule *gitlab.ProjectApprovalRule
+	
+	// Extract the existing group IDs.
+	var groupIDs []int
+	for _, group := range rule.Groups {
+		groupIDs = append(groupIDs, group.ID)
+	}
+
+	// Extract the existing branch IDs.
+	var branchIDs []int
+	for _, branch := range rule.ProtectedBranches {
+		branchIDs = append(branchIDs, branch.ID)
+	}
+
+	// Set update options.
+	opts := gitlab.UpdateProjectLevelRuleOptions{
+		Name: gitlab.Ptr(rule.Name),
+		ApprovalsRequired: gitlab.Ptr(rule.ApprovalsRequired),
+		UserIDs: &userIDs,
+		GroupIDs: &groupIDs,
+		ProtectedBranchIDs: &branchIDs,
+		AppliesToAllProtectedBranches: gitlab.Ptr(rule.AppliesToAllProtectedBranches),
+	}
+
+	// Update the approval rule.
+	newRule, _, err = s.UpdateProjectApprovalRule(
+		projectID, rule.ID, &opts, gitlab.WithContext(Uninterruptible(ctx)))
+	if err != nil {
+		return nil, fmt.Errorf("UpdateApprovalRule: %w", ClassifyError(err))
+	}
+	return newRule, nil
+}
+
+// ApprovalRulesGetter is an abstraction of GetProjectApprovalRules()
+// in gitlab.ProjectsService which was added so
+// ForEachApprovalRuleInProject() can be tested with requiring a paid
+// Gitlab account because Gitlab CE (the free version of Gitlab) does
+// not support approval rules.
+type ApprovalRulesGetter interface {
+	GetProjectApprovalRules(
+		pid interface{},
+		opt *gitlab.GetProjectApprovalRulesListsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectApprovalRule, *gitlab.Response, error)
+}
+
+// ForEachApprovalRuleInProject iterates over the approval rules in a
+// project and calls the function f once for each approval rule.  The
+// function f must return true and no error to indicate that it wants
+// to continue being called with the remaining projects.  If f returns
+// an error, it will be forwarded to the caller as the error return
+// value for this function.  If ctx is done before all the approval
+// rules have been processed, ctx.Err() is returned.
+func ForEachApprovalRuleInProject(
+	ctx context.Context,
+	s ApprovalRulesGetter, /* was *gitlab.ProjectsService */
+	p *gitlab.Project,
+	f func(
+		approvalRule *gitlab.ProjectApprovalRule,
+	) (bool, error),
+) error {
+
+	// Set up the options for ListGroupProjects().
+	opts := gitlab.GetProjectApprovalRulesListsOptions{}
+	opts.Page = 1
+
+	// Iterate over each page of approval rules.
+	for {
+
+		// Get the next page of approval rules.
+		rules, resp, err := s.GetProjectApprovalRules(
+			p.ID, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf(
+				"ForEachApprovalRuleInProject: %w", ClassifyError(err))
+		}
+
+		// Invoke the callbacks.
+		for _, rule := range rules {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			more, err := f(rule)
+			if err != nil {
+				return err
+			}
+			if !more {
+				return nil
+			}
+		}
+
+		// Check if done.
+		if resp.NextPage == 0 {
+			break
+		}
+
+		// Move to the next page.
+		opts.Page = resp.NextPage
+	}
+
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// Users
+////////////////////////////////////////////////////////////////////////
+
+// FindUser search for the user and returns the user that exactly
+// matches the search string or all substring matches if exact is
+// false.  The search string can be the user ID, name, username or
+// e-mail address of the user.  If the search string is a user ID, the
+// exact flag is ignored, and only the exact user with that ID will be
+// returned.
+func FindUsers(
+	ctx context.Context,
+	s UserFinder, /* was *gitlab.UsersService */
+	user string,
+	exact bool,
+	date time.Time,
+) ([]*gitlab.User, error) {
+	var err error
+	var matches []*gitlab.User
+	var u *gitlab.User
+	var userID int
+
+	// If "user" is an integer, it is a user ID which requires
+	// different processing.
+	userID, err = strconv.Atoi(user)
+	if err == nil {
+		opts := gitlab.GetUsersOptions{}
+		u, _, err = s.GetUser(userID, opts, gitlab.WithContext(ctx))
+		if err != nil {
+			err = ClassifyError(err)
+			if errors.Is(err, ErrNotFound) {
+				err = fmt.Errorf("%w: %w", ErrUserNotFound, err)
+			}
+			return nil, fmt.Errorf("FindUsers: %w", err)
+		}
+		return []*gitlab.User{u}, nil
+	}
+	err = nil
+
+	// Iterate over all the users that match the "user" string.
+	err = ForEachUser(ctx, s, user, date, func(u *gitlab.User) (bool, error) {
+		if !exact || u.Email == user || u.Username == user || u.Name == user {
+			matches = append(matches, u)
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf(
+			"FindUsers: %w: no match found for user: %q", ErrUserNotFound, user)
+	}
+	if exact && len(matches) > 1 {
+		var usernames []string
+		for _, match := range matches {
+			usernames = append(usernames, match.Username)
+		}
+		return nil, fmt.Errorf(
+			"FindUsers: %w: multiple exact matches found: %q",
+			ErrAmbiguousUser, usernames)
+	}
+
+	return matches, nil
+}
+
+// ForEachUser iterates over users calling the function f once for
+// each user matching the search string.  An empty search string
+// matches all users.  The search string can be the name, username, or
+// e-mail address of the user.  The function f must return true and no
+// error to indicate that it wants to continue being called with the
+// remaining users.  If f returns an error, it will be forwarded to
+// the caller as the error return value for this function.
+//
+// Also see [FindExactUser()].
+func ForEachUser(
+	ctx context.Context,
+	s UsersLister, /* was *gitlab.UsersService */
+	user string,
+	date time.Time,
+	f func(user *gitlab.User) (bool, error),
+) error {
+
+	// Set up the options for ListUsers().
+	opts := gitlab.ListUsersOptions{}
+	opts.CreatedAfter = &date
+	if user != "" {
+		opts.Search = &user
+	}
+
+	return ForEachUserWithOptions(ctx, s, &opts, f)
+}
+
+// ForEachUserWithOptions is the same as ForEachUser() except the
+// users are selected by the options passed to ListUsers() which allows
+// filters like CreatedBefore and Blocked to be used.
+func ForEachUserWithOptions(
+	ctx context.Context,
+	s UsersLister, /* was *gitlab.UsersService */
+	opts *gitlab.ListUsersOptions,
+	f func(user *gitlab.User) (bool, error),
+) error {
+
+	// Get each page of users.  Note that each call gets its own copy
+	// of opts because the next page is prefetched concurrently.
+	getPage := func(cursor PageCursor) ([]*gitlab.User, *gitlab.Response, error) {
+		pageOpts := *opts
+		options := cursor.Apply(&pageOpts.ListOptions)
+		users, resp, err := s.ListUsers(
+			&pageOpts, append(options, gitlab.WithContext(ctx))...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ForEachUserWithOptions: %w", ClassifyError(err))
+		}
+		return users, resp, nil
+	}
+
+	// Invoke the callback for each user.
+	return forEachPrefetchedKeysetPage(ctx, getPage, f)
+}
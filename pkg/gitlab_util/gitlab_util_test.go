@@ -0,0 +1,286 @@
+package gitlab_util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Stubs
+////////////////////////////////////////////////////////////////////////
+
+type GitlabProjectsServiceStub struct{}
+
+func (s *GitlabProjectsServiceStub) GetProjectApprovalRules(
+	pid interface{},
+	opt *gitlab.GetProjectApprovalRulesListsOptions,
+	options ...gitlab.RequestOptionFunc,
+) ([]*gitlab.ProjectApprovalRule, *gitlab.Response, error) {
+
+	resp := gitlab.Response{
+		NextPage: 0,
+	}
+
+	rules := []*gitlab.ProjectApprovalRule{
+		&gitlab.ProjectApprovalRule{
+			ID:   1,
+			Name: "Rule1",
+			Users: []*gitlab.BasicUser{
+				&gitlab.BasicUser{
+					ID:       1,
+					Username: "aberns",
+				},
+				&gitlab.BasicUser{
+					ID:       2,
+					Username: "bcrocket",
+				},
+			},
+		},
+		&gitlab.ProjectApprovalRule{
+			ID:   2,
+			Name: "Rule2",
+			Users: []*gitlab.BasicUser{
+				&gitlab.BasicUser{
+					ID:       3,
+					Username: "cdragun",
+				},
+				&gitlab.BasicUser{
+					ID:       4,
+					Username: "delliot",
+				},
+			},
+		},
+	}
+
+	return rules, &resp, nil
+}
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestForEachApprovalRuleInProject(t *testing.T) {
+	var err error
+	service := GitlabProjectsServiceStub{}
+	p := gitlab.Project{}
+	var actual []string
+	expected := []string{
+		`0xcac460d19ffbb714         1  Rule1             ["aberns" "bcrocket"]`,
+		`0x056daac148e9b0a1         2  Rule2             ["cdragun" "delliot"]`,
+	}
+
+	err = ForEachApprovalRuleInProject(
+		context.Background(), &service, &p,
+		func(rule *gitlab.ProjectApprovalRule) (bool, error) {
+			actual = append(actual, ApprovalRuleToString(rule))
+			return true, nil
+		})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if !slices.Equal(actual, expected) {
+		t.Errorf("ForEachApprovalRuleInProject: expected=%v  actual=%v",
+			expected, actual)
+
+	}
+}
+
+func TestGroupMemoFindExactGroup(t *testing.T) {
+	var searches int
+
+	// Create a fake Gitlab server that counts the number of group
+	// searches.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/groups", func(w http.ResponseWriter, r *http.Request) {
+		searches++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": 1, "full_path": "foo"}, {"id": 2, "full_path": "foo/bar"}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Repeatedly look up the same groups.
+	memo := NewGroupMemo()
+	for i := 0; i < 3; i++ {
+		for _, path := range []string{"foo", "foo/bar"} {
+			g, err := memo.FindExactGroup(context.Background(), client.Groups, path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if g.FullPath != path {
+				t.Errorf("FindExactGroup: expected=%q  actual=%q", path, g.FullPath)
+			}
+		}
+	}
+
+	// Each unique group should only have been searched for once.
+	if searches != 2 {
+		t.Errorf("FindExactGroup: expected=%d searches  actual=%d searches",
+			2, searches)
+	}
+
+	// Clearing the memo should force the search to happen again.
+	memo.Clear()
+	_, err = memo.FindExactGroup(context.Background(), client.Groups, "foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if searches != 3 {
+		t.Errorf("FindExactGroup: expected=%d searches  actual=%d searches",
+			3, searches)
+	}
+}
+
+func TestForEachPrefetchedPage(t *testing.T) {
+	pages := [][]int{{1, 2, 3}, {4, 5}, {6}}
+
+	// getPage returns the requested page from pages.
+	getPage := func(page int) ([]int, *gitlab.Response, error) {
+		resp := gitlab.Response{}
+		if page < len(pages) {
+			resp.NextPage = page + 1
+		}
+		return pages[page-1], &resp, nil
+	}
+
+	// Collect all the items.
+	var actual []int
+	err := forEachPrefetchedPage(context.Background(), getPage, func(x int) (bool, error) {
+		actual = append(actual, x)
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []int{1, 2, 3, 4, 5, 6}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("forEachPrefetchedPage: expected=%v  actual=%v",
+			expected, actual)
+	}
+
+	// Stop early.
+	actual = nil
+	err = forEachPrefetchedPage(context.Background(), getPage, func(x int) (bool, error) {
+		actual = append(actual, x)
+		return x < 4, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = []int{1, 2, 3, 4}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("forEachPrefetchedPage: expected=%v  actual=%v",
+			expected, actual)
+	}
+
+	// Stop when the context is canceled in the middle of a page.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	actual = nil
+	err = forEachPrefetchedPage(ctx, getPage, func(x int) (bool, error) {
+		actual = append(actual, x)
+		if x == 2 {
+			cancel()
+		}
+		return true, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("forEachPrefetchedPage: expected context.Canceled: %v", err)
+	}
+	expected = []int{1, 2}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("forEachPrefetchedPage: expected=%v  actual=%v",
+			expected, actual)
+	}
+}
+
+func TestForEachPrefetchedKeysetPage(t *testing.T) {
+	pages := [][]int{{1, 2, 3}, {4, 5}, {6}}
+
+	// collect collects all the items returned by getPage.
+	collect := func(getPage func(cursor PageCursor) ([]int, *gitlab.Response, error)) []int {
+		var actual []int
+		err := forEachPrefetchedKeysetPage(context.Background(), getPage,
+			func(x int) (bool, error) {
+				actual = append(actual, x)
+				return true, nil
+			})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return actual
+	}
+	expected := []int{1, 2, 3, 4, 5, 6}
+
+	// Keyset pagination where the "next" link is the index of the
+	// next page.
+	var offsetRequests int
+	actual := collect(func(cursor PageCursor) ([]int, *gitlab.Response, error) {
+		if !cursor.Keyset {
+			offsetRequests++
+			return nil, nil, fmt.Errorf("unexpected offset cursor: %+v", cursor)
+		}
+		i := 0
+		if cursor.NextLink != "" {
+			i = int(cursor.NextLink[0] - '0')
+		}
+		resp := gitlab.Response{}
+		if i+1 < len(pages) {
+			resp.NextLink = fmt.Sprint(i + 1)
+		}
+		return pages[i], &resp, nil
+	})
+	if !slices.Equal(actual, expected) || offsetRequests != 0 {
+		t.Errorf("keyset: expected=%v  actual=%v  offsetRequests=%d",
+			expected, actual, offsetRequests)
+	}
+
+	// Fall back to offset pagination when keyset pagination is
+	// ignored by the endpoint.
+	actual = collect(func(cursor PageCursor) ([]int, *gitlab.Response, error) {
+		page := max(cursor.Page, 1)
+		resp := gitlab.Response{}
+		if page < len(pages) {
+			resp.NextPage = page + 1
+		}
+		return pages[page-1], &resp, nil
+	})
+	if !slices.Equal(actual, expected) {
+		t.Errorf("ignored keyset: expected=%v  actual=%v", expected, actual)
+	}
+
+	// Fall back to offset pagination when keyset pagination is
+	// rejected by the endpoint.
+	var keysetRequests int
+	actual = collect(func(cursor PageCursor) ([]int, *gitlab.Response, error) {
+		if cursor.Keyset {
+			keysetRequests++
+			return nil, nil, &gitlab.ErrorResponse{
+				Response: &http.Response{StatusCode: http.StatusMethodNotAllowed},
+			}
+		}
+		resp := gitlab.Response{}
+		if cursor.Page < len(pages) {
+			resp.NextPage = cursor.Page + 1
+		}
+		return pages[cursor.Page-1], &resp, nil
+	})
+	if !slices.Equal(actual, expected) || keysetRequests != 1 {
+		t.Errorf("rejected keyset: expected=%v  actual=%v  keysetRequests=%d",
+			expected, actual, keysetRequests)
+	}
+}
@@ -0,0 +1,273 @@
+package gitlab_util
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestForEachProjectInGroupGraphQL(t *testing.T) {
+
+	// Create a fake Gitlab server that returns two pages of projects.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		project := `{"id": "gid://gitlab/Project/1", "fullPath": "foo/a"}`
+		pageInfo := `{"hasNextPage": true, "endCursor": "c1"}`
+		if req.Variables["after"] == "c1" {
+			project = `{"id": "gid://gitlab/Project/2", "fullPath": "foo/bar/b"}`
+			pageInfo = `{"hasNextPage": false}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w,
+			`{"data": {"group": {"id": "gid://gitlab/Group/7", "fullPath": "foo",
+			  "projects": {"nodes": [%s], "pageInfo": %s}}}}`,
+			project, pageInfo)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Collect the projects.
+	var actual []string
+	err = ForEachProjectInGroupGraphQL(context.Background(), client, "foo", "", true,
+		func(g *gitlab.Group, p *gitlab.Project) (bool, error) {
+			actual = append(actual, fmt.Sprintf("%d:%d:%s",
+				g.ID, p.ID, p.PathWithNamespace))
+			return true, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"7:1:foo/a", "7:2:foo/bar/b"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("ForEachProjectInGroupGraphQL: expected=%v  actual=%v",
+			expected, actual)
+	}
+}
+
+func TestForEachProjectInGroupGraphQLFallback(t *testing.T) {
+
+	// Create a fake Gitlab server that only supports the REST API.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/groups", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": 7, "full_path": "foo"}]`)
+	})
+	mux.HandleFunc("/api/v4/groups/7/projects", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{"id": 1, "path_with_namespace": "foo/a"}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Collect the projects.
+	var actual []string
+	err = ForEachProjectInGroupGraphQL(context.Background(), client, "foo", "", false,
+		func(g *gitlab.Group, p *gitlab.Project) (bool, error) {
+			actual = append(actual, p.PathWithNamespace)
+			return true, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"foo/a"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("ForEachProjectInGroupGraphQL: expected=%v  actual=%v",
+			expected, actual)
+	}
+}
+
+func TestForEachMergeRequestInGroupGraphQL(t *testing.T) {
+
+	// Create a fake Gitlab server that returns the merge requests of
+	// two projects and records the variables of the query.
+	var variables map[string]any
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		variables = req.Variables
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w,
+			`{"data": {"group": {"mergeRequests": {"nodes": [
+			  {"id": "gid://gitlab/MergeRequest/11", "iid": "1", "title": "a",
+			   "author": {"username": "renovate"},
+			   "project": {"id": "gid://gitlab/Project/1", "fullPath": "foo/a"}},
+			  {"id": "gid://gitlab/MergeRequest/12", "iid": "2", "title": "b",
+			   "project": {"id": "gid://gitlab/Project/2", "fullPath": "foo/bar/b"}}],
+			  "pageInfo": {"hasNextPage": false}}}}}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Collect the merge requests of the projects matching "/a$".
+	var actual []string
+	opts := &gitlab.ListProjectMergeRequestsOptions{
+		State:        gitlab.Ptr("opened"),
+		TargetBranch: gitlab.Ptr("main"),
+	}
+	err = ForEachMergeRequestInGroupGraphQL(context.Background(), client, "foo", "/a$", true, opts,
+		func(p *gitlab.Project, mr *gitlab.MergeRequest) (bool, error) {
+			actual = append(actual, fmt.Sprintf("%d:%s!%d:%d:%s",
+				p.ID, p.PathWithNamespace, mr.IID, mr.ID, mr.Author.Username))
+			return true, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"1:foo/a!1:11:renovate"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("ForEachMergeRequestInGroupGraphQL: expected=%v  actual=%v",
+			expected, actual)
+	}
+	expectedVariables := `map[fullPath:foo includeSubgroups:true state:opened targetBranches:[main]]`
+	if fmt.Sprint(variables) != expectedVariables {
+		t.Errorf("ForEachMergeRequestInGroupGraphQL: expected=%v  actual=%v",
+			expectedVariables, variables)
+	}
+}
+
+func TestForEachIssueInGroupGraphQL(t *testing.T) {
+
+	// Create a fake Gitlab server that returns two pages of issues.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var req GraphQLRequest
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		issue := `{"id": "gid://gitlab/Issue/21", "iid": "1", "projectId": 1,
+		  "reference": "foo/a#1", "updatedAt": "2024-01-02T03:04:05Z"}`
+		pageInfo := `{"hasNextPage": true, "endCursor": "c1"}`
+		if req.Variables["after"] == "c1" {
+			issue = `{"id": "gid://gitlab/Issue/22", "iid": "3", "projectId": 2,
+			  "reference": "foo/bar/b#3"}`
+			pageInfo = `{"hasNextPage": false}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w,
+			`{"data": {"group": {"issues": {"nodes": [%s], "pageInfo": %s}}}}`,
+			issue, pageInfo)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Collect the issues.
+	var actual []string
+	err = ForEachIssueInGroupGraphQL(context.Background(), client, "foo", "", true,
+		&gitlab.ListProjectIssuesOptions{},
+		func(p *gitlab.Project, issue *gitlab.Issue) (bool, error) {
+			actual = append(actual, fmt.Sprintf("%d:%s#%d:%d:%v",
+				p.ID, p.PathWithNamespace, issue.IID, issue.ID, issue.UpdatedAt != nil))
+			return true, nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"1:foo/a#1:21:true", "2:foo/bar/b#3:22:false"}
+	if !slices.Equal(actual, expected) {
+		t.Errorf("ForEachIssueInGroupGraphQL: expected=%v  actual=%v",
+			expected, actual)
+	}
+}
+
+func TestForEachIssueInGroupGraphQLUnavailable(t *testing.T) {
+
+	// Create a fake Gitlab server without the GraphQL API.
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the caller can fall back to the REST API.
+	err = ForEachIssueInGroupGraphQL(context.Background(), client, "foo", "", false,
+		&gitlab.ListProjectIssuesOptions{},
+		func(p *gitlab.Project, issue *gitlab.Issue) (bool, error) {
+			t.Errorf("unexpected issue: %v", issue.IID)
+			return true, nil
+		})
+	if !errors.Is(err, ErrGraphQLUnavailable) {
+		t.Errorf("ForEachIssueInGroupGraphQL: expected=%v  actual=%v",
+			ErrGraphQLUnavailable, err)
+	}
+}
+
+func TestForEachProjectInGroupGraphQLNoFallback(t *testing.T) {
+
+	// Create a fake Gitlab server that rejects the token for both the
+	// GraphQL API and the REST API.
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"message": "401 Unauthorized"}`)
+		}))
+	defer server.Close()
+
+	// Create a client that talks to the fake Gitlab server.
+	client, err := gitlab.NewClient("", gitlab.WithBaseURL(server.URL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the error is returned instead of falling back to the
+	// REST API.
+	err = ForEachProjectInGroupGraphQL(context.Background(), client, "foo", "", false,
+		func(g *gitlab.Group, p *gitlab.Project) (bool, error) {
+			return true, nil
+		})
+	if !errors.Is(err, ErrPermissionDenied) || errors.Is(err, ErrGraphQLUnavailable) {
+		t.Errorf("ForEachProjectInGroupGraphQL: expected=%v  actual=%v",
+			ErrPermissionDenied, err)
+	}
+	expected := []string{"/api/graphql"}
+	if !slices.Equal(paths, expected) {
+		t.Errorf("ForEachProjectInGroupGraphQL: expected=%v  actual=%v",
+			expected, paths)
+	}
+}
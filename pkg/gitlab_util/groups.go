@@ -0,0 +1,114 @@
+// This file provides utility functions for walking the hierarchy of
+// groups.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// SubgroupsLister is an abstraction of ListSubGroups() in
+// gitlab.GroupsService.
+type SubgroupsLister interface {
+	ListSubGroups(
+		gid interface{},
+		opt *gitlab.ListSubGroupsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Group, *gitlab.Response, error)
+}
+
+// GetAllSubgroups returns the immediate subgroups of the group which
+// can be the group ID or its full path.
+func GetAllSubgroups(
+	ctx context.Context,
+	s SubgroupsLister, /* was *gitlab.GroupsService */
+	group interface{},
+) ([]*gitlab.Group, error) {
+
+	// Get each page of subgroups.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Group, *gitlab.Response, error) {
+		opts := gitlab.ListSubGroupsOptions{}
+		opts.Page = page
+		gs, resp, err := s.ListSubGroups(group, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllSubgroups: %w", ClassifyError(err))
+		}
+		return gs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllDescendantGroups returns the subgroups of the group at every
+// depth which can be the group ID or its full path.  Each group is
+// followed by its own descendants.
+func GetAllDescendantGroups(
+	ctx context.Context,
+	s SubgroupsLister, /* was *gitlab.GroupsService */
+	group interface{},
+) ([]*gitlab.Group, error) {
+	var result []*gitlab.Group
+	subgroups, err := GetAllSubgroups(ctx, s, group)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range subgroups {
+		result = append(result, g)
+		descendants, err := GetAllDescendantGroups(ctx, s, g.ID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, descendants...)
+	}
+	return result, nil
+}
+
+// GroupTreeLister is the set of gitlab.GroupsService methods needed by
+// GetAllGroups().
+type GroupTreeLister interface {
+	GroupFinder
+	SubgroupsLister
+}
+
+// GetAllGroups returns the group (which can be the group ID or its
+// full path) and, if recursive is true, its descendants whose full
+// path matches the regular expression.  An empty regular expression
+// matches any string.
+func GetAllGroups(
+	ctx context.Context,
+	s GroupTreeLister, /* was *gitlab.GroupsService */
+	group string,
+	expr string,
+	recursive bool,
+) ([]*gitlab.Group, error) {
+	r, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllGroups: %w", err)
+	}
+	root, err := FindExactGroup(ctx, s, group)
+	if err != nil {
+		return nil, fmt.Errorf("GetAllGroups: %w", err)
+	}
+	groups := []*gitlab.Group{root}
+	if recursive {
+		descendants, err := GetAllDescendantGroups(ctx, s, root.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetAllGroups: %w", err)
+		}
+		groups = append(groups, descendants...)
+	}
+	var result []*gitlab.Group
+	for _, g := range groups {
+		if r.MatchString(g.FullPath) {
+			result = append(result, g)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,110 @@
+// This file provides utility functions for the webhooks of projects.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectHooksLister is an abstraction of ListProjectHooks() in
+// gitlab.ProjectsService.
+type ProjectHooksLister interface {
+	ListProjectHooks(
+		pid interface{},
+		opt *gitlab.ListProjectHooksOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectHook, *gitlab.Response, error)
+}
+
+// ProjectHookEditor is an abstraction of EditProjectHook() in
+// gitlab.ProjectsService.
+type ProjectHookEditor interface {
+	EditProjectHook(
+		pid interface{},
+		hook int,
+		opt *gitlab.EditProjectHookOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectHook, *gitlab.Response, error)
+}
+
+// ProjectHookAdder is an abstraction of AddProjectHook() in
+// gitlab.ProjectsService.
+type ProjectHookAdder interface {
+	AddProjectHook(
+		pid interface{},
+		opt *gitlab.AddProjectHookOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectHook, *gitlab.Response, error)
+}
+
+// ProjectHookDeleter is an abstraction of DeleteProjectHook() in
+// gitlab.ProjectsService.
+type ProjectHookDeleter interface {
+	DeleteProjectHook(
+		pid interface{},
+		hook int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GetAllProjectHooks returns the webhooks of the project which can be
+// the project ID or its full path.
+func GetAllProjectHooks(
+	ctx context.Context,
+	s ProjectHooksLister, /* was *gitlab.ProjectsService */
+	project interface{},
+) ([]*gitlab.ProjectHook, error) {
+
+	// Get each page of hooks.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.ProjectHook, *gitlab.Response, error) {
+		opts := gitlab.ListProjectHooksOptions{}
+		opts.Page = page
+		hs, resp, err := s.ListProjectHooks(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectHooks: %w", ClassifyError(err))
+		}
+		return hs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// TestProjectHook triggers a test delivery of the trigger event (e.g.,
+// "push_events") to the webhook of the project which can be the
+// project ID or its full path.  Gitlab responds with an error if the
+// endpoint of the webhook does not respond with a 2xx status code in
+// which case the returned error holds the reason.  The gitlab.Client
+// does not provide a method for this endpoint so the request is built
+// by hand.
+func TestProjectHook(
+	ctx context.Context,
+	client *gitlab.Client,
+	project interface{},
+	hook int,
+	trigger string,
+) error {
+
+	// Create the request.
+	u := fmt.Sprintf("projects/%s/hooks/%d/test/%s",
+		gitlab.PathEscape(fmt.Sprint(project)), hook, gitlab.PathEscape(trigger))
+	req, err := client.NewRequest(http.MethodPost, u, nil,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return fmt.Errorf("TestProjectHook: %w", err)
+	}
+
+	// Send the request.
+	_, err = client.Do(req, nil)
+	if err != nil {
+		return fmt.Errorf("TestProjectHook: %w", ClassifyError(err))
+	}
+
+	return nil
+}
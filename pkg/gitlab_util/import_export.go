@@ -0,0 +1,127 @@
+// This file provides functions for downloading project exports and
+// importing them that stream the export archive instead of holding it
+// in memory which the methods in gitlab.ProjectImportExportService do.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// DownloadProjectExport writes the export archive of the project
+// (which can be the project ID or its full path) to w.  The export
+// must have already finished.
+func DownloadProjectExport(
+	ctx context.Context,
+	client *gitlab.Client,
+	project interface{},
+	w io.Writer,
+) error {
+
+	// Create the request.
+	u := fmt.Sprintf("projects/%s/export/download",
+		gitlab.PathEscape(fmt.Sprint(project)))
+	req, err := client.NewRequest(http.MethodGet, u, nil,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return fmt.Errorf("DownloadProjectExport: %w", err)
+	}
+
+	// Send the request.  The body is copied to w as it is read.
+	_, err = client.Do(req, w)
+	if err != nil {
+		return fmt.Errorf("DownloadProjectExport: %w", ClassifyError(err))
+	}
+
+	return nil
+}
+
+// ImportProjectFromFile schedules the import of the export archive in
+// the file having the name as a new project having the name and path
+// in the namespace (which is the full path of a group).  The archive
+// is streamed from the file each time the request is sent so it is
+// never held in memory.
+func ImportProjectFromFile(
+	ctx context.Context,
+	client *gitlab.Client,
+	fileName string,
+	namespace string,
+	name string,
+	path string,
+) (*gitlab.ImportStatus, error) {
+
+	// Create the request.
+	req, err := client.NewRequest(http.MethodPost, "projects/import", nil,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, fmt.Errorf("ImportProjectFromFile: %w", err)
+	}
+
+	// Set the body which is a multipart form that is written to a
+	// pipe as it is sent.  The same boundary is used each time so
+	// it matches the Content-Type header.
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	fields := [][2]string{{"namespace", namespace}, {"name", name}, {"path", path}}
+	err = req.SetBody(func() (io.Reader, error) {
+		f, err := os.Open(fileName)
+		if err != nil {
+			return nil, err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			defer f.Close()
+			pw.CloseWithError(writeImportForm(pw, boundary, fields, f))
+		}()
+		return pr, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ImportProjectFromFile: %w", err)
+	}
+	req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+
+	// Send the request.
+	var status gitlab.ImportStatus
+	_, err = client.Do(req, &status)
+	if err != nil {
+		return nil, fmt.Errorf("ImportProjectFromFile: %w", ClassifyError(err))
+	}
+
+	return &status, nil
+}
+
+// writeImportForm writes the multipart form for importing a project
+// that has the fields and the archive to w using the boundary.
+func writeImportForm(
+	w io.Writer,
+	boundary string,
+	fields [][2]string,
+	archive io.Reader,
+) error {
+	mw := multipart.NewWriter(w)
+	err := mw.SetBoundary(boundary)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		err = mw.WriteField(field[0], field[1])
+		if err != nil {
+			return err
+		}
+	}
+	fw, err := mw.CreateFormFile("file", "archive.tar.gz")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, archive)
+	if err != nil {
+		return err
+	}
+	return mw.Close()
+}
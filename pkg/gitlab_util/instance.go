@@ -0,0 +1,155 @@
+// This file provides utility functions for getting information about
+// the Gitlab instance itself.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Service Interfaces
+////////////////////////////////////////////////////////////////////////
+
+// VersionGetter is an abstraction of GetVersion() in
+// gitlab.VersionService.
+type VersionGetter interface {
+	GetVersion(
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Version, *gitlab.Response, error)
+}
+
+// MetadataGetter is an abstraction of GetMetadata() in
+// gitlab.MetadataService.
+type MetadataGetter interface {
+	GetMetadata(
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Metadata, *gitlab.Response, error)
+}
+
+// LicenseGetter is an abstraction of GetLicense() in
+// gitlab.LicenseService.
+type LicenseGetter interface {
+	GetLicense(
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.License, *gitlab.Response, error)
+}
+
+////////////////////////////////////////////////////////////////////////
+// RateLimit
+////////////////////////////////////////////////////////////////////////
+
+// RateLimit is the rate limit status reported by Gitlab in the
+// RateLimit-* response headers.
+type RateLimit struct {
+
+	// Reported is whether Gitlab reported the rate limit.  Many
+	// self-managed instances do not.
+	Reported bool
+
+	// Limit is the number of requests allowed per period.
+	Limit int
+
+	// Remaining is the number of requests remaining in the period.
+	Remaining int
+
+	// Reset is when the period resets.
+	Reset time.Time
+}
+
+// GetRateLimit returns the rate limit status from the response
+// headers.
+func GetRateLimit(header http.Header) RateLimit {
+	var result RateLimit
+	limit, err := strconv.Atoi(header.Get("RateLimit-Limit"))
+	if err != nil {
+		return result
+	}
+	remaining, err := strconv.Atoi(header.Get("RateLimit-Remaining"))
+	if err != nil {
+		return result
+	}
+	result.Reported = true
+	result.Limit = limit
+	result.Remaining = remaining
+	if reset, err := strconv.ParseInt(header.Get("RateLimit-Reset"), 10, 64); err == nil {
+		result.Reset = time.Unix(reset, 0)
+	}
+	return result
+}
+
+////////////////////////////////////////////////////////////////////////
+// ServerInfo
+////////////////////////////////////////////////////////////////////////
+
+// ServerInfo holds information about the Gitlab instance.
+type ServerInfo struct {
+
+	// Version is the Gitlab version (e.g., "16.11.0-ee").
+	Version string
+
+	// Revision is the Gitlab revision.
+	Revision string
+
+	// Enterprise is whether the instance is the enterprise edition.
+	Enterprise bool
+
+	// Plan is the license plan (e.g., "premium") or the error that
+	// prevented it from being determined.  Only administrators can
+	// get the license.
+	Plan string
+
+	// Latency is how long the version request took which is a rough
+	// measure of the round-trip time to the server.
+	Latency time.Duration
+
+	// RateLimit is the rate limit status reported with the version
+	// request.
+	RateLimit RateLimit
+}
+
+// GetServerInfo returns information about the Gitlab instance.  Only
+// failing to get the version is treated as an error because it means
+// the server cannot be reached or the credentials are bad.
+func GetServerInfo(
+	ctx context.Context,
+	versions VersionGetter, /* was *gitlab.VersionService */
+	metadata MetadataGetter, /* was *gitlab.MetadataService */
+	licenses LicenseGetter, /* was *gitlab.LicenseService */
+) (*ServerInfo, error) {
+	var result ServerInfo
+
+	// Get the version measuring how long it takes.
+	start := time.Now()
+	v, resp, err := versions.GetVersion(gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("GetServerInfo: %w", ClassifyError(err))
+	}
+	result.Latency = time.Since(start)
+	result.Version = v.Version
+	result.Revision = v.Revision
+	result.RateLimit = GetRateLimit(resp.Header)
+
+	// Get the edition.  The metadata endpoint was only added in
+	// Gitlab 15.2 so failures are ignored.
+	m, _, err := metadata.GetMetadata(gitlab.WithContext(ctx))
+	if err == nil {
+		result.Enterprise = m.Enterprise
+	}
+
+	// Get the license plan.
+	l, _, err := licenses.GetLicense(gitlab.WithContext(ctx))
+	if err != nil {
+		result.Plan = fmt.Sprintf("unknown (%v)", ClassifyError(err))
+	} else {
+		result.Plan = l.Plan
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,120 @@
+// This file provides utility functions for the integrations (formerly
+// known as services) of projects such as Slack notifications or Jira.
+// Each integration has its own set of settings, and the gitlab.Client
+// has a different type for each integration, so these functions treat
+// the settings of every integration generically as name/value pairs.
+
+package gitlab_util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// integrationMetadata are the fields of an integration returned by
+// Gitlab that are not settings.
+var integrationMetadata = map[string]bool{
+	"created_at": true,
+	"id":         true,
+	"inherited":  true,
+	"properties": true,
+	"slug":       true,
+	"title":      true,
+	"updated_at": true,
+}
+
+// integrationURL returns the URL relative to the REST endpoint of the
+// integration of the project which can be the project ID or its full
+// path.
+func integrationURL(project interface{}, slug string) string {
+	return fmt.Sprintf("projects/%s/integrations/%s",
+		gitlab.PathEscape(fmt.Sprint(project)), gitlab.PathEscape(slug))
+}
+
+// GetProjectIntegration returns the settings of the integration (e.g.,
+// "slack" or "jira") of the project which can be the project ID or
+// its full path.  The event flags and the integration-specific
+// properties are returned together as strings.  Gitlab does not
+// return secrets such as passwords so they are not included.  If the
+// integration has never been configured, nil is returned without an
+// error.
+func GetProjectIntegration(
+	ctx context.Context,
+	client *gitlab.Client,
+	project interface{},
+	slug string,
+) (map[string]string, error) {
+
+	// Create the request.
+	req, err := client.NewRequest(http.MethodGet, integrationURL(project, slug), nil,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return nil, fmt.Errorf("GetProjectIntegration: %w", err)
+	}
+
+	// Send the request.
+	var integration map[string]any
+	_, err = client.Do(req, &integration)
+	if err != nil {
+		err = ClassifyError(err)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetProjectIntegration: %w", err)
+	}
+
+	// Flatten the settings.
+	settings := make(map[string]string)
+	add := func(name string, value any) {
+		switch value.(type) {
+		case nil, map[string]any, []any:
+			// Skip values that cannot be set as a single string.
+		default:
+			settings[name] = fmt.Sprint(value)
+		}
+	}
+	for name, value := range integration {
+		if !integrationMetadata[name] {
+			add(name, value)
+		}
+	}
+	if properties, ok := integration["properties"].(map[string]any); ok {
+		for name, value := range properties {
+			add(name, value)
+		}
+	}
+
+	return settings, nil
+}
+
+// SetProjectIntegration configures and activates the integration
+// (e.g., "slack" or "jira") of the project which can be the project
+// ID or its full path.  Settings that are not given keep their current
+// value.
+func SetProjectIntegration(
+	ctx context.Context,
+	client *gitlab.Client,
+	project interface{},
+	slug string,
+	settings map[string]string,
+) error {
+
+	// Create the request.
+	req, err := client.NewRequest(http.MethodPut, integrationURL(project, slug), settings,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return fmt.Errorf("SetProjectIntegration: %w", err)
+	}
+
+	// Send the request.
+	_, err = client.Do(req, nil)
+	if err != nil {
+		return fmt.Errorf("SetProjectIntegration: %w", ClassifyError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+// This file provides the support for stopping long-running operations
+// cleanly when the user interrupts the program.
+
+package gitlab_util
+
+import (
+	"context"
+)
+
+// Uninterruptible returns a copy of ctx that is never canceled but
+// still carries the values (e.g., the EventHook) of ctx.  It is meant
+// for API calls that change Gitlab (e.g., deleting a project) so that
+// an interrupt lets the call in progress finish instead of abandoning
+// it midway in which case it would be unknown whether Gitlab made the
+// change.  The loops making these calls should still check ctx between
+// calls so that they stop after the current call.
+func Uninterruptible(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
@@ -0,0 +1,184 @@
+// This file provides abstractions for issues and merge requests.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// IssueCreator is an abstraction of CreateIssue() in
+// gitlab.IssuesService.
+type IssueCreator interface {
+	CreateIssue(
+		pid interface{},
+		opt *gitlab.CreateIssueOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Issue, *gitlab.Response, error)
+}
+
+// ProjectIssuesLister is an abstraction of ListProjectIssues() in
+// gitlab.IssuesService.
+type ProjectIssuesLister interface {
+	ListProjectIssues(
+		pid interface{},
+		opt *gitlab.ListProjectIssuesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Issue, *gitlab.Response, error)
+}
+
+// IssueUpdater is an abstraction of UpdateIssue() in
+// gitlab.IssuesService.
+type IssueUpdater interface {
+	UpdateIssue(
+		pid interface{},
+		issue int,
+		opt *gitlab.UpdateIssueOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Issue, *gitlab.Response, error)
+}
+
+// IssueMover is an abstraction of MoveIssue() in gitlab.IssuesService.
+type IssueMover interface {
+	MoveIssue(
+		pid interface{},
+		issue int,
+		opt *gitlab.MoveIssueOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Issue, *gitlab.Response, error)
+}
+
+// MergeRequestCreator is an abstraction of CreateMergeRequest() in
+// gitlab.MergeRequestsService.
+type MergeRequestCreator interface {
+	CreateMergeRequest(
+		pid interface{},
+		opt *gitlab.CreateMergeRequestOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.MergeRequest, *gitlab.Response, error)
+}
+
+// MergeRequestApprover is an abstraction of ApproveMergeRequest() in
+// gitlab.MergeRequestApprovalsService.
+type MergeRequestApprover interface {
+	ApproveMergeRequest(
+		pid interface{},
+		mr int,
+		opt *gitlab.ApproveMergeRequestOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.MergeRequestApprovals, *gitlab.Response, error)
+}
+
+// MergeRequestAccepter is an abstraction of AcceptMergeRequest() in
+// gitlab.MergeRequestsService.
+type MergeRequestAccepter interface {
+	AcceptMergeRequest(
+		pid interface{},
+		mergeRequest int,
+		opt *gitlab.AcceptMergeRequestOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.MergeRequest, *gitlab.Response, error)
+}
+
+// MergeRequestUpdater is an abstraction of UpdateMergeRequest() in
+// gitlab.MergeRequestsService.
+type MergeRequestUpdater interface {
+	UpdateMergeRequest(
+		pid interface{},
+		mergeRequest int,
+		opt *gitlab.UpdateMergeRequestOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.MergeRequest, *gitlab.Response, error)
+}
+
+// GetAllProjectIssues returns the issues of the project which can be
+// the project ID or its full path.  If state is not empty, only issues
+// in the state (e.g., "opened") are returned.  If labels is not empty,
+// only issues having all of the labels are returned.
+func GetAllProjectIssues(
+	ctx context.Context,
+	s ProjectIssuesLister, /* was *gitlab.IssuesService */
+	project interface{},
+	state string,
+	labels []string,
+) ([]*gitlab.Issue, error) {
+
+	// Get each page of issues.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Issue, *gitlab.Response, error) {
+		opts := gitlab.ListProjectIssuesOptions{}
+		opts.Page = page
+		if state != "" {
+			opts.State = gitlab.Ptr(state)
+		}
+		if len(labels) > 0 {
+			opts.Labels = (*gitlab.LabelOptions)(&labels)
+		}
+		is, resp, err := s.ListProjectIssues(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectIssues: %w", ClassifyError(err))
+		}
+		return is, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProjectIssuesWithOptions returns the issues of the project
+// which can be the project ID or its full path.  The options filter
+// the issues.  The page of the options is ignored.
+func GetAllProjectIssuesWithOptions(
+	ctx context.Context,
+	s ProjectIssuesLister, /* was *gitlab.IssuesService */
+	project interface{},
+	opts *gitlab.ListProjectIssuesOptions,
+) ([]*gitlab.Issue, error) {
+
+	// Get each page of issues.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Issue, *gitlab.Response, error) {
+		pageOpts := *opts
+		pageOpts.Page = page
+		is, resp, err := s.ListProjectIssues(project, &pageOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectIssuesWithOptions: %w", ClassifyError(err))
+		}
+		return is, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProjectMergeRequestsWithOptions returns the merge requests of
+// the project which can be the project ID or its full path.  The
+// options filter the merge requests.  The page of the options is
+// ignored.
+func GetAllProjectMergeRequestsWithOptions(
+	ctx context.Context,
+	s ProjectMergeRequestsLister, /* was *gitlab.MergeRequestsService */
+	project interface{},
+	opts *gitlab.ListProjectMergeRequestsOptions,
+) ([]*gitlab.MergeRequest, error) {
+
+	// Get each page of merge requests.  Note that each call gets its
+	// own copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+		pageOpts := *opts
+		pageOpts.Page = page
+		mrs, resp, err := s.ListProjectMergeRequests(project, &pageOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectMergeRequestsWithOptions: %w", ClassifyError(err))
+		}
+		return mrs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,179 @@
+// This file provides utility functions for the members of groups and
+// projects.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// GroupMembersLister is an abstraction of ListGroupMembers() and
+// ListAllGroupMembers() in gitlab.GroupsService.
+type GroupMembersLister interface {
+	ListGroupMembers(
+		gid interface{},
+		opt *gitlab.ListGroupMembersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.GroupMember, *gitlab.Response, error)
+
+	ListAllGroupMembers(
+		gid interface{},
+		opt *gitlab.ListGroupMembersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.GroupMember, *gitlab.Response, error)
+}
+
+// ProjectMembersLister is an abstraction of ListProjectMembers() and
+// ListAllProjectMembers() in gitlab.ProjectMembersService.
+type ProjectMembersLister interface {
+	ListProjectMembers(
+		pid interface{},
+		opt *gitlab.ListProjectMembersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectMember, *gitlab.Response, error)
+
+	ListAllProjectMembers(
+		pid interface{},
+		opt *gitlab.ListProjectMembersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectMember, *gitlab.Response, error)
+}
+
+// GroupMembersManager is an abstraction of gitlab.GroupMembersService
+// which adds, edits, and removes the direct members of groups.
+type GroupMembersManager interface {
+	AddGroupMember(
+		gid interface{},
+		opt *gitlab.AddGroupMemberOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.GroupMember, *gitlab.Response, error)
+
+	EditGroupMember(
+		gid interface{},
+		user int,
+		opt *gitlab.EditGroupMemberOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.GroupMember, *gitlab.Response, error)
+
+	RemoveGroupMember(
+		gid interface{},
+		user int,
+		opt *gitlab.RemoveGroupMemberOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// ProjectMembersManager is an abstraction of
+// gitlab.ProjectMembersService which adds, edits, and removes the
+// direct members of projects.
+type ProjectMembersManager interface {
+	AddProjectMember(
+		pid interface{},
+		opt *gitlab.AddProjectMemberOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectMember, *gitlab.Response, error)
+
+	EditProjectMember(
+		pid interface{},
+		user int,
+		opt *gitlab.EditProjectMemberOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectMember, *gitlab.Response, error)
+
+	DeleteProjectMember(
+		pid interface{},
+		user int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// AccessLevelName returns the name of the access level as shown in
+// the Gitlab UI.
+func AccessLevelName(level gitlab.AccessLevelValue) string {
+	switch level {
+	case gitlab.NoPermissions:
+		return "No access"
+	case gitlab.MinimalAccessPermissions:
+		return "Minimal Access"
+	case gitlab.GuestPermissions:
+		return "Guest"
+	case gitlab.ReporterPermissions:
+		return "Reporter"
+	case gitlab.DeveloperPermissions:
+		return "Developer"
+	case gitlab.MaintainerPermissions:
+		return "Maintainer"
+	case gitlab.OwnerPermissions:
+		return "Owner"
+	case gitlab.AdminPermissions:
+		return "Admin"
+	}
+	return fmt.Sprintf("%d", level)
+}
+
+// GetAllGroupMembers returns the members of the group which can be the
+// group ID or its full path.  If inherited is true, the members
+// inherited from ancestor groups are included; otherwise, only the
+// direct members are returned.
+func GetAllGroupMembers(
+	ctx context.Context,
+	s GroupMembersLister, /* was *gitlab.GroupsService */
+	group interface{},
+	inherited bool,
+) ([]*gitlab.GroupMember, error) {
+
+	// Get each page of members.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.GroupMember, *gitlab.Response, error) {
+		opts := gitlab.ListGroupMembersOptions{}
+		opts.Page = page
+		list := s.ListGroupMembers
+		if inherited {
+			list = s.ListAllGroupMembers
+		}
+		ms, resp, err := list(group, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllGroupMembers: %w", ClassifyError(err))
+		}
+		return ms, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProjectMembers returns the members of the project which can be
+// the project ID or its full path.  If inherited is true, the members
+// inherited from ancestor groups are included; otherwise, only the
+// direct members are returned.
+func GetAllProjectMembers(
+	ctx context.Context,
+	s ProjectMembersLister, /* was *gitlab.ProjectMembersService */
+	project interface{},
+	inherited bool,
+) ([]*gitlab.ProjectMember, error) {
+
+	// Get each page of members.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.ProjectMember, *gitlab.Response, error) {
+		opts := gitlab.ListProjectMembersOptions{}
+		opts.Page = page
+		list := s.ListProjectMembers
+		if inherited {
+			list = s.ListAllProjectMembers
+		}
+		ms, resp, err := list(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectMembers: %w", ClassifyError(err))
+		}
+		return ms, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,135 @@
+// This file provides utility functions for project metadata like
+// labels, milestones, and issue boards.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Service Interfaces
+////////////////////////////////////////////////////////////////////////
+
+// LabelsManager is an abstraction of gitlab.LabelsService which lists
+// and creates project labels.
+type LabelsManager interface {
+	ListLabels(
+		pid interface{},
+		opt *gitlab.ListLabelsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Label, *gitlab.Response, error)
+
+	CreateLabel(
+		pid interface{},
+		opt *gitlab.CreateLabelOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Label, *gitlab.Response, error)
+}
+
+// MilestonesManager is an abstraction of gitlab.MilestonesService
+// which lists and creates project milestones.
+type MilestonesManager interface {
+	ListMilestones(
+		pid interface{},
+		opt *gitlab.ListMilestonesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Milestone, *gitlab.Response, error)
+
+	CreateMilestone(
+		pid interface{},
+		opt *gitlab.CreateMilestoneOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Milestone, *gitlab.Response, error)
+}
+
+// IssueBoardsManager is an abstraction of gitlab.IssueBoardsService
+// which lists and creates project issue boards and their lists.
+type IssueBoardsManager interface {
+	ListIssueBoards(
+		pid interface{},
+		opt *gitlab.ListIssueBoardsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.IssueBoard, *gitlab.Response, error)
+
+	CreateIssueBoard(
+		pid interface{},
+		opt *gitlab.CreateIssueBoardOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.IssueBoard, *gitlab.Response, error)
+
+	CreateIssueBoardList(
+		pid interface{},
+		board int,
+		opt *gitlab.CreateIssueBoardListOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.BoardList, *gitlab.Response, error)
+}
+
+////////////////////////////////////////////////////////////////////////
+// Listing
+////////////////////////////////////////////////////////////////////////
+
+// GetAllLabels returns all the labels of the project which can be the
+// project ID or its full path.  If includeAncestors is true, the
+// labels inherited from the ancestor groups are also returned.
+func GetAllLabels(
+	ctx context.Context,
+	s LabelsManager, /* was *gitlab.LabelsService */
+	project interface{},
+	includeAncestors bool,
+) ([]*gitlab.Label, error) {
+	getPage := func(page int) ([]*gitlab.Label, *gitlab.Response, error) {
+		opts := gitlab.ListLabelsOptions{
+			IncludeAncestorGroups: &includeAncestors,
+		}
+		opts.Page = page
+		ls, resp, err := s.ListLabels(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetAllLabels: %w", ClassifyError(err))
+		}
+		return ls, resp, nil
+	}
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllMilestones returns all the milestones of the project which
+// can be the project ID or its full path.
+func GetAllMilestones(
+	ctx context.Context,
+	s MilestonesManager, /* was *gitlab.MilestonesService */
+	project interface{},
+) ([]*gitlab.Milestone, error) {
+	getPage := func(page int) ([]*gitlab.Milestone, *gitlab.Response, error) {
+		opts := gitlab.ListMilestonesOptions{}
+		opts.Page = page
+		ms, resp, err := s.ListMilestones(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetAllMilestones: %w", ClassifyError(err))
+		}
+		return ms, resp, nil
+	}
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllIssueBoards returns all the issue boards (including their
+// lists) of the project which can be the project ID or its full path.
+func GetAllIssueBoards(
+	ctx context.Context,
+	s IssueBoardsManager, /* was *gitlab.IssueBoardsService */
+	project interface{},
+) ([]*gitlab.IssueBoard, error) {
+	getPage := func(page int) ([]*gitlab.IssueBoard, *gitlab.Response, error) {
+		opts := gitlab.ListIssueBoardsOptions{}
+		opts.Page = page
+		bs, resp, err := s.ListIssueBoards(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetAllIssueBoards: %w", ClassifyError(err))
+		}
+		return bs, resp, nil
+	}
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,83 @@
+// This file provides utility functions for the push and pull mirrors
+// of projects.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectMirrorsLister is an abstraction of ListProjectMirror() in
+// gitlab.ProjectMirrorService.
+type ProjectMirrorsLister interface {
+	ListProjectMirror(
+		pid interface{},
+		opt *gitlab.ListProjectMirrorOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectMirror, *gitlab.Response, error)
+}
+
+// ProjectMirrorsManager is an abstraction of
+// gitlab.ProjectMirrorService which adds and edits the push mirrors
+// of projects.
+type ProjectMirrorsManager interface {
+	AddProjectMirror(
+		pid interface{},
+		opt *gitlab.AddProjectMirrorOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectMirror, *gitlab.Response, error)
+
+	EditProjectMirror(
+		pid interface{},
+		mirror int,
+		opt *gitlab.EditProjectMirrorOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectMirror, *gitlab.Response, error)
+}
+
+// PullMirrorDetailsGetter is an abstraction of
+// GetProjectPullMirrorDetails() in gitlab.ProjectsService.
+type PullMirrorDetailsGetter interface {
+	GetProjectPullMirrorDetails(
+		pid interface{},
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectPullMirrorDetails, *gitlab.Response, error)
+}
+
+// ProjectEditor is an abstraction of EditProject() in
+// gitlab.ProjectsService.
+type ProjectEditor interface {
+	EditProject(
+		pid interface{},
+		opt *gitlab.EditProjectOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Project, *gitlab.Response, error)
+}
+
+// GetAllProjectMirrors returns the push mirrors of the project which
+// can be the project ID or its full path.
+func GetAllProjectMirrors(
+	ctx context.Context,
+	s ProjectMirrorsLister, /* was *gitlab.ProjectMirrorService */
+	project interface{},
+) ([]*gitlab.ProjectMirror, error) {
+
+	// Get each page of mirrors.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.ProjectMirror, *gitlab.Response, error) {
+		opts := gitlab.ListProjectMirrorOptions{}
+		opts.Page = page
+		ms, resp, err := s.ListProjectMirror(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectMirrors: %w", ClassifyError(err))
+		}
+		return ms, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,46 @@
+// This file provides utility functions for the notification settings
+// of users for groups and projects.
+
+package gitlab_util
+
+import (
+	"github.com/xanzy/go-gitlab"
+)
+
+// NotificationSettingsManager is an abstraction of the methods of
+// gitlab.NotificationSettingsService that get and update the
+// notification settings for groups and projects.
+type NotificationSettingsManager interface {
+	GetSettingsForGroup(
+		gid interface{},
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.NotificationSettings, *gitlab.Response, error)
+
+	GetSettingsForProject(
+		pid interface{},
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.NotificationSettings, *gitlab.Response, error)
+
+	UpdateSettingsForGroup(
+		gid interface{},
+		opt *gitlab.NotificationSettingsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.NotificationSettings, *gitlab.Response, error)
+
+	UpdateSettingsForProject(
+		pid interface{},
+		opt *gitlab.NotificationSettingsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.NotificationSettings, *gitlab.Response, error)
+}
+
+// NotificationLevels are the notification levels that can be set
+// without also setting the individual events which the "custom" level
+// requires.
+var NotificationLevels = []gitlab.NotificationLevelValue{
+	gitlab.DisabledNotificationLevel,
+	gitlab.ParticipatingNotificationLevel,
+	gitlab.WatchNotificationLevel,
+	gitlab.GlobalNotificationLevel,
+	gitlab.MentionNotificationLevel,
+}
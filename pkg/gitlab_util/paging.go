@@ -0,0 +1,44 @@
+// This file provides the client option that sets how many items
+// Gitlab returns on each page of a list.
+
+package gitlab_util
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	// DefaultPerPage is the default number of items requested per
+	// page.  Gitlab returns only 20 items per page unless asked for
+	// more.
+	DefaultPerPage = 100
+
+	// MaxPerPage is the largest number of items per page that Gitlab
+	// allows.
+	MaxPerPage = 100
+)
+
+// PerPageOption returns the option for gitlab.NewClient() that requests
+// perPage items per page for every list request that does not already
+// ask for a specific number (e.g., by setting the PerPage field of
+// gitlab.ListOptions).  Because it applies to every request, every
+// iterator in this package (e.g., ForEachProjectInGroup()) gets the
+// larger pages without having to set PerPage itself.
+func PerPageOption(perPage int) gitlab.ClientOptionFunc {
+	return gitlab.WithRequestOptions(func(req *retryablehttp.Request) error {
+		if req.Method != http.MethodGet {
+			return nil
+		}
+		q := req.URL.Query()
+		if q.Has("per_page") {
+			return nil
+		}
+		q.Set("per_page", strconv.Itoa(perPage))
+		req.URL.RawQuery = q.Encode()
+		return nil
+	})
+}
@@ -0,0 +1,52 @@
+package gitlab_util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestPerPageOption(t *testing.T) {
+	var perPage string
+
+	// Create a fake Gitlab server that records the number of items
+	// requested per page.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/users", func(w http.ResponseWriter, r *http.Request) {
+		perPage = r.URL.Query().Get("per_page")
+		w.Write([]byte(`[]`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client, err := gitlab.NewClient("token",
+		gitlab.WithBaseURL(server.URL), PerPageOption(DefaultPerPage))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Verify the option is used unless the request asks for a
+	// specific number of items per page.
+	type Data []struct {
+		opts     *gitlab.ListUsersOptions
+		expected string
+	}
+	data := Data{
+		{&gitlab.ListUsersOptions{}, "100"},
+		{&gitlab.ListUsersOptions{ListOptions: gitlab.ListOptions{PerPage: 1}}, "1"},
+	}
+	for _, d := range data {
+		_, _, err := client.Users.ListUsers(d.opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if perPage != d.expected {
+			t.Errorf("ListUsers: expected=%q  actual=%q", d.expected, perPage)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// This file provides abstractions for CI/CD pipelines.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectPipelinesLister is an abstraction of ListProjectPipelines()
+// in gitlab.PipelinesService.
+type ProjectPipelinesLister interface {
+	ListProjectPipelines(
+		pid interface{},
+		opt *gitlab.ListProjectPipelinesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.PipelineInfo, *gitlab.Response, error)
+}
+
+// PipelineCreator is an abstraction of CreatePipeline() in
+// gitlab.PipelinesService.
+type PipelineCreator interface {
+	CreatePipeline(
+		pid interface{},
+		opt *gitlab.CreatePipelineOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Pipeline, *gitlab.Response, error)
+}
+
+// PipelineRetrier is an abstraction of RetryPipelineBuild() in
+// gitlab.PipelinesService.
+type PipelineRetrier interface {
+	RetryPipelineBuild(
+		pid interface{},
+		pipeline int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Pipeline, *gitlab.Response, error)
+}
+
+// PipelineCanceler is an abstraction of CancelPipelineBuild() in
+// gitlab.PipelinesService.
+type PipelineCanceler interface {
+	CancelPipelineBuild(
+		pid interface{},
+		pipeline int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Pipeline, *gitlab.Response, error)
+}
+
+// GetAllProjectPipelines returns the pipelines of the project which
+// can be the project ID or its full path.  The options filter the
+// pipelines.  The page of the options is ignored.
+func GetAllProjectPipelines(
+	ctx context.Context,
+	s ProjectPipelinesLister, /* was *gitlab.PipelinesService */
+	project interface{},
+	opts *gitlab.ListProjectPipelinesOptions,
+) ([]*gitlab.PipelineInfo, error) {
+
+	// Get each page of pipelines.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		pageOpts := *opts
+		pageOpts.Page = page
+		pipelines, resp, err := s.ListProjectPipelines(project, &pageOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectPipelines: %w", ClassifyError(err))
+		}
+		return pipelines, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetLatestProjectPipeline returns the newest pipeline of the project
+// (which can be the project ID or its full path) selected by the
+// options or nil if there is no such pipeline.  Only a single page
+// with a single pipeline is requested.  The order and pagination of
+// the options are ignored.
+func GetLatestProjectPipeline(
+	ctx context.Context,
+	s ProjectPipelinesLister, /* was *gitlab.PipelinesService */
+	project interface{},
+	opts *gitlab.ListProjectPipelinesOptions,
+) (*gitlab.PipelineInfo, error) {
+	latestOpts := *opts
+	latestOpts.Page = 1
+	latestOpts.PerPage = 1
+	latestOpts.OrderBy = gitlab.Ptr("id")
+	latestOpts.Sort = gitlab.Ptr("desc")
+	pipelines, _, err := s.ListProjectPipelines(project, &latestOpts, gitlab.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestProjectPipeline: %w", ClassifyError(err))
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+	return pipelines[0], nil
+}
@@ -0,0 +1,69 @@
+// This file provides abstractions for archiving, transferring, and
+// restoring projects.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectArchiver is an abstraction of ArchiveProject() in
+// gitlab.ProjectsService.
+type ProjectArchiver interface {
+	ArchiveProject(
+		pid interface{},
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Project, *gitlab.Response, error)
+}
+
+// ProjectUnarchiver is an abstraction of UnarchiveProject() in
+// gitlab.ProjectsService.
+type ProjectUnarchiver interface {
+	UnarchiveProject(
+		pid interface{},
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Project, *gitlab.Response, error)
+}
+
+// ProjectTransferrer is an abstraction of TransferProject() in
+// gitlab.ProjectsService.
+type ProjectTransferrer interface {
+	TransferProject(
+		pid interface{},
+		opt *gitlab.TransferProjectOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Project, *gitlab.Response, error)
+}
+
+// RestoreProject restores the project (which can be the project ID or
+// its full path) that is marked for deletion.  Projects are only
+// marked for deletion instead of being deleted immediately if delayed
+// deletion is enabled on the Gitlab instance.  The gitlab.Client does
+// not provide a method for this endpoint so the request is built by
+// hand.
+func RestoreProject(
+	ctx context.Context,
+	client *gitlab.Client,
+	project interface{},
+) error {
+
+	// Create the request.
+	u := fmt.Sprintf("projects/%s/restore", gitlab.PathEscape(fmt.Sprint(project)))
+	req, err := client.NewRequest(http.MethodPost, u, nil,
+		[]gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return fmt.Errorf("RestoreProject: %w", err)
+	}
+
+	// Send the request.
+	_, err = client.Do(req, nil)
+	if err != nil {
+		return fmt.Errorf("RestoreProject: %w", ClassifyError(err))
+	}
+
+	return nil
+}
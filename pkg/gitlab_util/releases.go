@@ -0,0 +1,65 @@
+// This file provides abstractions for the releases of projects.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectReleasesLister is an abstraction of ListReleases() in
+// gitlab.ReleasesService.
+type ProjectReleasesLister interface {
+	ListReleases(
+		pid interface{},
+		opt *gitlab.ListReleasesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Release, *gitlab.Response, error)
+}
+
+// ReleaseCreator is an abstraction of CreateRelease() in
+// gitlab.ReleasesService.
+type ReleaseCreator interface {
+	CreateRelease(
+		pid interface{},
+		opts *gitlab.CreateReleaseOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Release, *gitlab.Response, error)
+}
+
+// ReleaseDeleter is an abstraction of DeleteRelease() in
+// gitlab.ReleasesService.
+type ReleaseDeleter interface {
+	DeleteRelease(
+		pid interface{},
+		tagName string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Release, *gitlab.Response, error)
+}
+
+// GetAllProjectReleases returns the releases of the project which can
+// be the project ID or its full path.
+func GetAllProjectReleases(
+	ctx context.Context,
+	s ProjectReleasesLister, /* was *gitlab.ReleasesService */
+	project interface{},
+) ([]*gitlab.Release, error) {
+
+	// Get each page of releases.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Release, *gitlab.Response, error) {
+		opts := gitlab.ListReleasesOptions{}
+		opts.Page = page
+		rs, resp, err := s.ListReleases(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectReleases: %w", ClassifyError(err))
+		}
+		return rs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
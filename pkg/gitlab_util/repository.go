@@ -0,0 +1,178 @@
+// This file provides utility functions for repository files,
+// commits, branches, and protected branches.
+
+package gitlab_util
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// RepositoryFileGetter is an abstraction of GetFile() in
+// gitlab.RepositoryFilesService.
+type RepositoryFileGetter interface {
+	GetFile(
+		pid interface{},
+		fileName string,
+		opt *gitlab.GetFileOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.File, *gitlab.Response, error)
+}
+
+// CommitCreator is an abstraction of CreateCommit() in
+// gitlab.CommitsService.
+type CommitCreator interface {
+	CreateCommit(
+		pid interface{},
+		opt *gitlab.CreateCommitOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Commit, *gitlab.Response, error)
+}
+
+// BranchesLister is an abstraction of ListBranches() in
+// gitlab.BranchesService.
+type BranchesLister interface {
+	ListBranches(
+		pid interface{},
+		opts *gitlab.ListBranchesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Branch, *gitlab.Response, error)
+}
+
+// BranchDeleter is an abstraction of DeleteBranch() in
+// gitlab.BranchesService.
+type BranchDeleter interface {
+	DeleteBranch(
+		pid interface{},
+		branch string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// BranchProtector is an abstraction of ProtectRepositoryBranches() in
+// gitlab.ProtectedBranchesService.
+type BranchProtector interface {
+	ProtectRepositoryBranches(
+		pid interface{},
+		opt *gitlab.ProtectRepositoryBranchesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProtectedBranch, *gitlab.Response, error)
+}
+
+// ProtectedBranchesLister is an abstraction of
+// ListProtectedBranches() in gitlab.ProtectedBranchesService.
+type ProtectedBranchesLister interface {
+	ListProtectedBranches(
+		pid interface{},
+		opt *gitlab.ListProtectedBranchesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProtectedBranch, *gitlab.Response, error)
+}
+
+// ProtectedBranchesManager is an abstraction of
+// gitlab.ProtectedBranchesService which lists, protects, updates, and
+// unprotects branches.
+type ProtectedBranchesManager interface {
+	BranchProtector
+	ProtectedBranchesLister
+
+	UpdateProtectedBranch(
+		pid interface{},
+		branch string,
+		opt *gitlab.UpdateProtectedBranchOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProtectedBranch, *gitlab.Response, error)
+
+	UnprotectRepositoryBranches(
+		pid interface{},
+		branch string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GetAllBranches returns all the branches of the repository of the
+// project which can be the project ID or its full path.
+func GetAllBranches(
+	ctx context.Context,
+	s BranchesLister, /* was *gitlab.BranchesService */
+	project interface{},
+) ([]*gitlab.Branch, error) {
+
+	// Get each page of branches.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Branch, *gitlab.Response, error) {
+		opts := gitlab.ListBranchesOptions{}
+		opts.Page = page
+		bs, resp, err := s.ListBranches(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllBranches: %w", ClassifyError(err))
+		}
+		return bs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProtectedBranches returns all the protected branches of the
+// project which can be the project ID or its full path.
+func GetAllProtectedBranches(
+	ctx context.Context,
+	s ProtectedBranchesLister, /* was *gitlab.ProtectedBranchesService */
+	project interface{},
+) ([]*gitlab.ProtectedBranch, error) {
+
+	// Get each page of protected branches.  Note that each call gets
+	// its own copy of the options because the next page is
+	// prefetched concurrently.
+	getPage := func(page int) ([]*gitlab.ProtectedBranch, *gitlab.Response, error) {
+		opts := gitlab.ListProtectedBranchesOptions{}
+		opts.Page = page
+		bs, resp, err := s.ListProtectedBranches(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProtectedBranches: %w", ClassifyError(err))
+		}
+		return bs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetFileContent returns the decoded content of the file at the path
+// in the repository of the project on the ref (e.g., a branch name).
+// The boolean return value is false if the file does not exist.
+func GetFileContent(
+	ctx context.Context,
+	s RepositoryFileGetter, /* was *gitlab.RepositoryFilesService */
+	project interface{},
+	path string,
+	ref string,
+) (string, bool, error) {
+
+	// Get the file.
+	f, _, err := s.GetFile(project, path,
+		&gitlab.GetFileOptions{Ref: &ref}, gitlab.WithContext(ctx))
+	if err != nil {
+		err = ClassifyError(err)
+		if errors.Is(err, ErrNotFound) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("GetFileContent: %w", err)
+	}
+
+	// Decode the content.
+	if f.Encoding != "base64" {
+		return f.Content, true, nil
+	}
+	content, err := base64.StdEncoding.DecodeString(f.Content)
+	if err != nil {
+		return "", false, fmt.Errorf("GetFileContent: %s: %w", path, err)
+	}
+	return string(content), true, nil
+}
@@ -0,0 +1,88 @@
+// This file provides the client options that make the Gitlab client
+// resilient to rate limiting and transient server errors during bulk
+// operations.
+
+package gitlab_util
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultMaxRetries is the default number of times a request that
+	// failed with 429 Too Many Requests or a 5xx status is retried.
+	DefaultMaxRetries = 5
+
+	// retryWaitMin is the wait before the first retry which doubles
+	// with each retry.
+	retryWaitMin = 1 * time.Second
+
+	// retryWaitMax is the longest wait between retries unless Gitlab
+	// asks for a longer wait using the Retry-After header.
+	retryWaitMax = 60 * time.Second
+)
+
+// RetryOptions returns the options for gitlab.NewClient() that retry
+// requests that fail with 429 Too Many Requests or a 5xx status up to
+// maxRetries times using ExponentialBackoff().  If rateLimit is
+// positive, the client also makes at most rateLimit requests per
+// second.  Otherwise, the client uses the rate limit advertised by
+// Gitlab in its response headers.
+func RetryOptions(maxRetries int, rateLimit float64) []gitlab.ClientOptionFunc {
+	options := []gitlab.ClientOptionFunc{
+		gitlab.WithCustomRetryMax(maxRetries),
+		gitlab.WithCustomRetryWaitMinMax(retryWaitMin, retryWaitMax),
+		gitlab.WithCustomBackoff(ExponentialBackoff),
+	}
+	if rateLimit > 0 {
+		options = append(options,
+			gitlab.WithCustomLimiter(rate.NewLimiter(rate.Limit(rateLimit), 1)))
+	}
+	return options
+}
+
+// ExponentialBackoff returns how long to wait before retrying the
+// request for the attemptNum'th time (starting with 0).  The wait
+// starts at min and doubles with each attempt up to max.  Up to 25%
+// jitter is added so that parallel requests do not retry in lockstep.
+// If resp holds a Retry-After header (in seconds) or a RateLimit-Reset
+// header (as a Unix time), the wait is at least as long as Gitlab
+// asked.  It implements retryablehttp.Backoff.
+func ExponentialBackoff(
+	min time.Duration,
+	max time.Duration,
+	attemptNum int,
+	resp *http.Response,
+) time.Duration {
+
+	// Double the wait with each attempt being careful not to
+	// overflow.
+	wait := max
+	if attemptNum < 32 {
+		if w := min << attemptNum; w > 0 && w < max {
+			wait = w
+		}
+	}
+	wait += time.Duration(rand.Int63n(int64(wait)/4 + 1))
+
+	// Honor the wait requested by Gitlab.
+	if resp != nil {
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			if w := time.Duration(s) * time.Second; w > wait {
+				wait = w
+			}
+		} else if t, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+			if w := time.Until(time.Unix(t, 0)); w > wait {
+				wait = w
+			}
+		}
+	}
+
+	return wait
+}
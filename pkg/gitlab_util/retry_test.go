@@ -0,0 +1,121 @@
+package gitlab_util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestExponentialBackoff(t *testing.T) {
+	min := 1 * time.Second
+	max := 10 * time.Second
+
+	// Verify the wait doubles (plus up to 25% jitter) up to max.
+	type Data []struct {
+		attemptNum int
+		expected   time.Duration
+	}
+	data := Data{
+		{0, 1 * time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 8 * time.Second},
+		{4, 10 * time.Second},
+		{100, 10 * time.Second},
+	}
+	for _, d := range data {
+		actual := ExponentialBackoff(min, max, d.attemptNum, nil)
+		if actual < d.expected || actual > d.expected+d.expected/4 {
+			t.Errorf("ExponentialBackoff: attempt %d: expected=%v  actual=%v",
+				d.attemptNum, d.expected, actual)
+		}
+	}
+
+	// Verify the wait requested by Gitlab is honored.
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"30"}},
+	}
+	if actual := ExponentialBackoff(min, max, 0, resp); actual != 30*time.Second {
+		t.Errorf("ExponentialBackoff: Retry-After: expected=%v  actual=%v",
+			30*time.Second, actual)
+	}
+}
+
+func TestRetryOptions(t *testing.T) {
+	var requests int
+
+	// Create a fake Gitlab server that fails the first two requests
+	// with 429 and 503.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v4/version", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"version": "16.0.0"}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// newClient creates a client that waits only briefly between
+	// retries.
+	newClient := func(maxRetries int, rateLimit float64) *gitlab.Client {
+		options := append(RetryOptions(maxRetries, rateLimit),
+			gitlab.WithBaseURL(server.URL),
+			gitlab.WithCustomRetryWaitMinMax(time.Millisecond, 10*time.Millisecond))
+		client, err := gitlab.NewClient("token", options...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return client
+	}
+
+	// Verify the request is retried until it succeeds.
+	client := newClient(DefaultMaxRetries, 0)
+	v, _, err := client.Version.GetVersion()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v.Version != "16.0.0" || requests != 3 {
+		t.Errorf("GetVersion: expected=%q after %d requests  actual=%q after %d requests",
+			"16.0.0", 3, v.Version, requests)
+	}
+
+	// Verify the error is returned when the retries are exhausted.
+	requests = 0
+	client = newClient(1, 0)
+	_, _, err = client.Version.GetVersion()
+	if err == nil {
+		t.Errorf("GetVersion: expected error")
+	}
+	if requests != 2 {
+		t.Errorf("GetVersion: expected=%d requests  actual=%d requests", 2, requests)
+	}
+
+	// Verify the rate limit spaces out the requests.
+	requests = 2
+	client = newClient(0, 50)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_, _, err = client.Version.GetVersion()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("GetVersion: expected at least %v  actual=%v",
+			80*time.Millisecond, elapsed)
+	}
+}
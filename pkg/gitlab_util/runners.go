@@ -0,0 +1,148 @@
+// This file provides abstractions for CI/CD runners.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// AllRunnersLister is an abstraction of ListAllRunners() in
+// gitlab.RunnersService.
+type AllRunnersLister interface {
+	ListAllRunners(
+		opt *gitlab.ListRunnersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Runner, *gitlab.Response, error)
+}
+
+// GroupRunnersLister is an abstraction of ListGroupsRunners() in
+// gitlab.RunnersService.
+type GroupRunnersLister interface {
+	ListGroupsRunners(
+		gid interface{},
+		opt *gitlab.ListGroupsRunnersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Runner, *gitlab.Response, error)
+}
+
+// ProjectRunnersLister is an abstraction of ListProjectRunners() in
+// gitlab.RunnersService.
+type ProjectRunnersLister interface {
+	ListProjectRunners(
+		pid interface{},
+		opt *gitlab.ListProjectRunnersOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Runner, *gitlab.Response, error)
+}
+
+// RunnerUpdater is an abstraction of UpdateRunnerDetails() in
+// gitlab.RunnersService.
+type RunnerUpdater interface {
+	UpdateRunnerDetails(
+		rid interface{},
+		opt *gitlab.UpdateRunnerDetailsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.RunnerDetails, *gitlab.Response, error)
+}
+
+// RunnerRemover is an abstraction of RemoveRunner() in
+// gitlab.RunnersService.
+type RunnerRemover interface {
+	RemoveRunner(
+		rid interface{},
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// ProjectRunnerEnabler is an abstraction of EnableProjectRunner() in
+// gitlab.RunnersService.
+type ProjectRunnerEnabler interface {
+	EnableProjectRunner(
+		pid interface{},
+		opt *gitlab.EnableProjectRunnerOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Runner, *gitlab.Response, error)
+}
+
+// GetAllRunners returns all the runners of the instance which pass the
+// filters in the options.  The page of the options is ignored.
+// Listing all runners requires administrator access.
+func GetAllRunners(
+	ctx context.Context,
+	s AllRunnersLister, /* was *gitlab.RunnersService */
+	opts *gitlab.ListRunnersOptions,
+) ([]*gitlab.Runner, error) {
+
+	// Get each page of runners.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+		pageOpts := *opts
+		pageOpts.Page = page
+		runners, resp, err := s.ListAllRunners(&pageOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllRunners: %w", ClassifyError(err))
+		}
+		return runners, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllGroupRunners returns the runners of the group (which can be
+// the group ID or its full path) which pass the filters in the
+// options.  The page of the options is ignored.
+func GetAllGroupRunners(
+	ctx context.Context,
+	s GroupRunnersLister, /* was *gitlab.RunnersService */
+	group interface{},
+	opts *gitlab.ListGroupsRunnersOptions,
+) ([]*gitlab.Runner, error) {
+
+	// Get each page of runners.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+		pageOpts := *opts
+		pageOpts.Page = page
+		runners, resp, err := s.ListGroupsRunners(group, &pageOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllGroupRunners: %w", ClassifyError(err))
+		}
+		return runners, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProjectRunners returns the runners of the project (which can
+// be the project ID or its full path) which pass the filters in the
+// options.  The page of the options is ignored.
+func GetAllProjectRunners(
+	ctx context.Context,
+	s ProjectRunnersLister, /* was *gitlab.RunnersService */
+	project interface{},
+	opts *gitlab.ListProjectRunnersOptions,
+) ([]*gitlab.Runner, error) {
+
+	// Get each page of runners.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Runner, *gitlab.Response, error) {
+		pageOpts := *opts
+		pageOpts.Page = page
+		runners, resp, err := s.ListProjectRunners(project, &pageOpts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectRunners: %w", ClassifyError(err))
+		}
+		return runners, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,45 @@
+// This file provides utility functions for the search API.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// SearchFunc is the signature of the methods of gitlab.SearchService
+// that search the whole instance for one scope (e.g., Projects() or
+// Blobs()).  Methods that search a group can be adapted by binding the
+// group in a closure.
+type SearchFunc[T any] func(
+	query string,
+	opt *gitlab.SearchOptions,
+	options ...gitlab.RequestOptionFunc,
+) ([]T, *gitlab.Response, error)
+
+// GetAllSearchResults returns every result of searching for the query
+// using the search function.
+func GetAllSearchResults[T any](
+	ctx context.Context,
+	search SearchFunc[T], /* was a method of *gitlab.SearchService */
+	query string,
+) ([]T, error) {
+
+	// Get each page of results.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]T, *gitlab.Response, error) {
+		opts := gitlab.SearchOptions{}
+		opts.Page = page
+		rs, resp, err := search(query, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllSearchResults: %w", ClassifyError(err))
+		}
+		return rs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,126 @@
+// This file provides utility functions for personal and project
+// snippets.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// SnippetsLister is an abstraction of ListSnippets() in
+// gitlab.SnippetsService.
+type SnippetsLister interface {
+	ListSnippets(
+		opt *gitlab.ListSnippetsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Snippet, *gitlab.Response, error)
+}
+
+// ProjectSnippetsLister is an abstraction of ListSnippets() in
+// gitlab.ProjectSnippetsService.
+type ProjectSnippetsLister interface {
+	ListSnippets(
+		pid interface{},
+		opt *gitlab.ListProjectSnippetsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Snippet, *gitlab.Response, error)
+}
+
+// ProjectSnippetContentGetter is an abstraction of SnippetContent() in
+// gitlab.ProjectSnippetsService.
+type ProjectSnippetContentGetter interface {
+	SnippetContent(
+		pid interface{},
+		snippet int,
+		options ...gitlab.RequestOptionFunc,
+	) ([]byte, *gitlab.Response, error)
+}
+
+// SnippetCreator is an abstraction of CreateSnippet() in
+// gitlab.SnippetsService.
+type SnippetCreator interface {
+	CreateSnippet(
+		opt *gitlab.CreateSnippetOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Snippet, *gitlab.Response, error)
+}
+
+// ProjectSnippetCreator is an abstraction of CreateSnippet() in
+// gitlab.ProjectSnippetsService.
+type ProjectSnippetCreator interface {
+	CreateSnippet(
+		pid interface{},
+		opt *gitlab.CreateProjectSnippetOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Snippet, *gitlab.Response, error)
+}
+
+// SnippetDeleter is an abstraction of DeleteSnippet() in
+// gitlab.SnippetsService.
+type SnippetDeleter interface {
+	DeleteSnippet(
+		snippet int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// ProjectSnippetDeleter is an abstraction of DeleteSnippet() in
+// gitlab.ProjectSnippetsService.
+type ProjectSnippetDeleter interface {
+	DeleteSnippet(
+		pid interface{},
+		snippet int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GetAllSnippets returns the personal snippets of the caller.
+func GetAllSnippets(
+	ctx context.Context,
+	s SnippetsLister, /* was *gitlab.SnippetsService */
+) ([]*gitlab.Snippet, error) {
+
+	// Get each page of snippets.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Snippet, *gitlab.Response, error) {
+		opts := gitlab.ListSnippetsOptions{}
+		opts.Page = page
+		ss, resp, err := s.ListSnippets(&opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllSnippets: %w", ClassifyError(err))
+		}
+		return ss, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProjectSnippets returns the snippets of the project which can
+// be the project ID or its full path.
+func GetAllProjectSnippets(
+	ctx context.Context,
+	s ProjectSnippetsLister, /* was *gitlab.ProjectSnippetsService */
+	project interface{},
+) ([]*gitlab.Snippet, error) {
+
+	// Get each page of snippets.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Snippet, *gitlab.Response, error) {
+		opts := gitlab.ListProjectSnippetsOptions{}
+		opts.Page = page
+		ss, resp, err := s.ListSnippets(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectSnippets: %w", ClassifyError(err))
+		}
+		return ss, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,145 @@
+// This file provides abstractions for the tags and protected tags of
+// projects.
+
+package gitlab_util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// TagsLister is an abstraction of ListTags() in gitlab.TagsService.
+type TagsLister interface {
+	ListTags(
+		pid interface{},
+		opt *gitlab.ListTagsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.Tag, *gitlab.Response, error)
+}
+
+// TagGetter is an abstraction of GetTag() in gitlab.TagsService.
+type TagGetter interface {
+	GetTag(
+		pid interface{},
+		tag string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Tag, *gitlab.Response, error)
+}
+
+// TagCreator is an abstraction of CreateTag() in gitlab.TagsService.
+type TagCreator interface {
+	CreateTag(
+		pid interface{},
+		opt *gitlab.CreateTagOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Tag, *gitlab.Response, error)
+}
+
+// TagDeleter is an abstraction of DeleteTag() in gitlab.TagsService.
+type TagDeleter interface {
+	DeleteTag(
+		pid interface{},
+		tag string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// ProtectedTagsLister is an abstraction of ListProtectedTags() in
+// gitlab.ProtectedTagsService.
+type ProtectedTagsLister interface {
+	ListProtectedTags(
+		pid interface{},
+		opt *gitlab.ListProtectedTagsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProtectedTag, *gitlab.Response, error)
+}
+
+// ProtectedTagsManager is an abstraction of
+// gitlab.ProtectedTagsService which lists, protects, and unprotects
+// tags.
+type ProtectedTagsManager interface {
+	ProtectedTagsLister
+
+	ProtectRepositoryTags(
+		pid interface{},
+		opt *gitlab.ProtectRepositoryTagsOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProtectedTag, *gitlab.Response, error)
+
+	UnprotectRepositoryTags(
+		pid interface{},
+		tag string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GetAllTags returns all the tags of the repository of the project
+// which can be the project ID or its full path.
+func GetAllTags(
+	ctx context.Context,
+	s TagsLister, /* was *gitlab.TagsService */
+	project interface{},
+) ([]*gitlab.Tag, error) {
+
+	// Get each page of tags.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.Tag, *gitlab.Response, error) {
+		opts := gitlab.ListTagsOptions{}
+		opts.Page = page
+		ts, resp, err := s.ListTags(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetAllTags: %w", ClassifyError(err))
+		}
+		return ts, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// FindTag returns the tag of the project (which can be the project ID
+// or its full path) having the name or nil if there is no such tag.
+func FindTag(
+	ctx context.Context,
+	s TagGetter, /* was *gitlab.TagsService */
+	project interface{},
+	name string,
+) (*gitlab.Tag, error) {
+	t, _, err := s.GetTag(project, name, gitlab.WithContext(ctx))
+	if err != nil {
+		err = ClassifyError(err)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("FindTag: %w", err)
+	}
+	return t, nil
+}
+
+// GetAllProtectedTags returns all the protected tags of the project
+// which can be the project ID or its full path.
+func GetAllProtectedTags(
+	ctx context.Context,
+	s ProtectedTagsLister, /* was *gitlab.ProtectedTagsService */
+	project interface{},
+) ([]*gitlab.ProtectedTag, error) {
+
+	// Get each page of protected tags.  Note that each call gets its
+	// own copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.ProtectedTag, *gitlab.Response, error) {
+		opts := gitlab.ListProtectedTagsOptions{}
+		opts.Page = page
+		ts, resp, err := s.ListProtectedTags(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProtectedTags: %w", ClassifyError(err))
+		}
+		return ts, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
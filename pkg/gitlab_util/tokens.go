@@ -0,0 +1,212 @@
+// This file provides utility functions for the deploy tokens and
+// access tokens of groups and projects.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// DeployTokensManager is an abstraction of gitlab.DeployTokensService
+// which lists, creates, and deletes the deploy tokens of groups and
+// projects.
+type DeployTokensManager interface {
+	ListProjectDeployTokens(
+		pid interface{},
+		opt *gitlab.ListProjectDeployTokensOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.DeployToken, *gitlab.Response, error)
+
+	CreateProjectDeployToken(
+		pid interface{},
+		opt *gitlab.CreateProjectDeployTokenOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.DeployToken, *gitlab.Response, error)
+
+	DeleteProjectDeployToken(
+		pid interface{},
+		deployToken int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+
+	ListGroupDeployTokens(
+		gid interface{},
+		opt *gitlab.ListGroupDeployTokensOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.DeployToken, *gitlab.Response, error)
+
+	CreateGroupDeployToken(
+		gid interface{},
+		opt *gitlab.CreateGroupDeployTokenOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.DeployToken, *gitlab.Response, error)
+
+	DeleteGroupDeployToken(
+		gid interface{},
+		deployToken int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// ProjectAccessTokensManager is an abstraction of
+// gitlab.ProjectAccessTokensService which lists, creates, rotates, and
+// revokes project access tokens.
+type ProjectAccessTokensManager interface {
+	ListProjectAccessTokens(
+		pid interface{},
+		opt *gitlab.ListProjectAccessTokensOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectAccessToken, *gitlab.Response, error)
+
+	CreateProjectAccessToken(
+		pid interface{},
+		opt *gitlab.CreateProjectAccessTokenOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectAccessToken, *gitlab.Response, error)
+
+	RotateProjectAccessToken(
+		pid interface{},
+		id int,
+		opt *gitlab.RotateProjectAccessTokenOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectAccessToken, *gitlab.Response, error)
+
+	RevokeProjectAccessToken(
+		pid interface{},
+		id int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GroupAccessTokensManager is an abstraction of
+// gitlab.GroupAccessTokensService which lists, creates, rotates, and
+// revokes group access tokens.
+type GroupAccessTokensManager interface {
+	ListGroupAccessTokens(
+		gid interface{},
+		opt *gitlab.ListGroupAccessTokensOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.GroupAccessToken, *gitlab.Response, error)
+
+	CreateGroupAccessToken(
+		gid interface{},
+		opt *gitlab.CreateGroupAccessTokenOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.GroupAccessToken, *gitlab.Response, error)
+
+	RotateGroupAccessToken(
+		gid interface{},
+		id int,
+		opt *gitlab.RotateGroupAccessTokenOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.GroupAccessToken, *gitlab.Response, error)
+
+	RevokeGroupAccessToken(
+		gid interface{},
+		id int,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GetAllProjectDeployTokens returns the deploy tokens of the project
+// which can be the project ID or its full path.
+func GetAllProjectDeployTokens(
+	ctx context.Context,
+	s DeployTokensManager, /* was *gitlab.DeployTokensService */
+	project interface{},
+) ([]*gitlab.DeployToken, error) {
+
+	// Get each page of tokens.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.DeployToken, *gitlab.Response, error) {
+		opts := gitlab.ListProjectDeployTokensOptions{}
+		opts.Page = page
+		ts, resp, err := s.ListProjectDeployTokens(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectDeployTokens: %w", ClassifyError(err))
+		}
+		return ts, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllGroupDeployTokens returns the deploy tokens of the group which
+// can be the group ID or its full path.
+func GetAllGroupDeployTokens(
+	ctx context.Context,
+	s DeployTokensManager, /* was *gitlab.DeployTokensService */
+	group interface{},
+) ([]*gitlab.DeployToken, error) {
+
+	// Get each page of tokens.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.DeployToken, *gitlab.Response, error) {
+		opts := gitlab.ListGroupDeployTokensOptions{}
+		opts.Page = page
+		ts, resp, err := s.ListGroupDeployTokens(group, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllGroupDeployTokens: %w", ClassifyError(err))
+		}
+		return ts, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllProjectAccessTokens returns the access tokens of the project
+// which can be the project ID or its full path.
+func GetAllProjectAccessTokens(
+	ctx context.Context,
+	s ProjectAccessTokensManager, /* was *gitlab.ProjectAccessTokensService */
+	project interface{},
+) ([]*gitlab.ProjectAccessToken, error) {
+
+	// Get each page of tokens.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.ProjectAccessToken, *gitlab.Response, error) {
+		opts := gitlab.ListProjectAccessTokensOptions{}
+		opts.Page = page
+		ts, resp, err := s.ListProjectAccessTokens(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectAccessTokens: %w", ClassifyError(err))
+		}
+		return ts, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllGroupAccessTokens returns the access tokens of the group which
+// can be the group ID or its full path.
+func GetAllGroupAccessTokens(
+	ctx context.Context,
+	s GroupAccessTokensManager, /* was *gitlab.GroupAccessTokensService */
+	group interface{},
+) ([]*gitlab.GroupAccessToken, error) {
+
+	// Get each page of tokens.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.GroupAccessToken, *gitlab.Response, error) {
+		opts := gitlab.ListGroupAccessTokensOptions{}
+		opts.Page = page
+		ts, resp, err := s.ListGroupAccessTokens(group, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllGroupAccessTokens: %w", ClassifyError(err))
+		}
+		return ts, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,81 @@
+// This file provides utility functions for the memberships and
+// personal access tokens of users.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// UserMembershipsGetter is an abstraction of GetUserMemberships() in
+// gitlab.UsersService.
+type UserMembershipsGetter interface {
+	GetUserMemberships(
+		user int,
+		opt *gitlab.GetUserMembershipOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.UserMembership, *gitlab.Response, error)
+}
+
+// PersonalAccessTokensLister is an abstraction of
+// ListPersonalAccessTokens() in gitlab.PersonalAccessTokensService.
+type PersonalAccessTokensLister interface {
+	ListPersonalAccessTokens(
+		opt *gitlab.ListPersonalAccessTokensOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.PersonalAccessToken, *gitlab.Response, error)
+}
+
+// GetAllUserMemberships returns the direct group and project
+// memberships of the user.  This requires an administrator token.
+func GetAllUserMemberships(
+	ctx context.Context,
+	s UserMembershipsGetter, /* was *gitlab.UsersService */
+	user int,
+) ([]*gitlab.UserMembership, error) {
+
+	// Get each page of memberships.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.UserMembership, *gitlab.Response, error) {
+		opts := gitlab.GetUserMembershipOptions{}
+		opts.Page = page
+		ms, resp, err := s.GetUserMemberships(user, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllUserMemberships: %w", ClassifyError(err))
+		}
+		return ms, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// GetAllPersonalAccessTokens returns the personal access tokens of the
+// user including revoked and expired tokens.  Getting the tokens of
+// other users requires an administrator token.
+func GetAllPersonalAccessTokens(
+	ctx context.Context,
+	s PersonalAccessTokensLister, /* was *gitlab.PersonalAccessTokensService */
+	user int,
+) ([]*gitlab.PersonalAccessToken, error) {
+
+	// Get each page of tokens.  Note that each call gets its own copy
+	// of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.PersonalAccessToken, *gitlab.Response, error) {
+		opts := gitlab.ListPersonalAccessTokensOptions{UserID: gitlab.Ptr(user)}
+		opts.Page = page
+		ts, resp, err := s.ListPersonalAccessTokens(&opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllPersonalAccessTokens: %w", ClassifyError(err))
+		}
+		return ts, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
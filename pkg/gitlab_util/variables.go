@@ -0,0 +1,137 @@
+// This file provides utility functions for CI/CD variables.
+
+package gitlab_util
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// ProjectVariablesLister is an abstraction of ListVariables() in
+// gitlab.ProjectVariablesService.
+type ProjectVariablesLister interface {
+	ListVariables(
+		pid interface{},
+		opt *gitlab.ListProjectVariablesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.ProjectVariable, *gitlab.Response, error)
+}
+
+// ProjectVariablesManager is an abstraction of
+// gitlab.ProjectVariablesService which lists, creates, and updates
+// project variables.
+type ProjectVariablesManager interface {
+	ProjectVariablesLister
+
+	CreateVariable(
+		pid interface{},
+		opt *gitlab.CreateProjectVariableOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectVariable, *gitlab.Response, error)
+
+	UpdateVariable(
+		pid interface{},
+		key string,
+		opt *gitlab.UpdateProjectVariableOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.ProjectVariable, *gitlab.Response, error)
+}
+
+// GetAllProjectVariables returns all the CI/CD variables of the
+// project which can be the project ID or its full path.
+func GetAllProjectVariables(
+	ctx context.Context,
+	s ProjectVariablesLister, /* was *gitlab.ProjectVariablesService */
+	project interface{},
+) ([]*gitlab.ProjectVariable, error) {
+
+	// Get each page of variables.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.ProjectVariable, *gitlab.Response, error) {
+		opts := gitlab.ListProjectVariablesOptions{}
+		opts.Page = page
+		vs, resp, err := s.ListVariables(project, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllProjectVariables: %w", ClassifyError(err))
+		}
+		return vs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
+
+// ProjectVariableRemover is an abstraction of RemoveVariable() in
+// gitlab.ProjectVariablesService.
+type ProjectVariableRemover interface {
+	RemoveVariable(
+		pid interface{},
+		key string,
+		opt *gitlab.RemoveProjectVariableOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GroupVariablesLister is an abstraction of ListVariables() in
+// gitlab.GroupVariablesService.
+type GroupVariablesLister interface {
+	ListVariables(
+		gid interface{},
+		opt *gitlab.ListGroupVariablesOptions,
+		options ...gitlab.RequestOptionFunc,
+	) ([]*gitlab.GroupVariable, *gitlab.Response, error)
+}
+
+// GroupVariablesManager is an abstraction of
+// gitlab.GroupVariablesService which lists, creates, updates, and
+// removes group variables.
+type GroupVariablesManager interface {
+	GroupVariablesLister
+
+	CreateVariable(
+		gid interface{},
+		opt *gitlab.CreateGroupVariableOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.GroupVariable, *gitlab.Response, error)
+
+	UpdateVariable(
+		gid interface{},
+		key string,
+		opt *gitlab.UpdateGroupVariableOptions,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.GroupVariable, *gitlab.Response, error)
+
+	RemoveVariable(
+		gid interface{},
+		key string,
+		options ...gitlab.RequestOptionFunc,
+	) (*gitlab.Response, error)
+}
+
+// GetAllGroupVariables returns all the CI/CD variables of the group
+// which can be the group ID or its full path.
+func GetAllGroupVariables(
+	ctx context.Context,
+	s GroupVariablesLister, /* was *gitlab.GroupVariablesService */
+	group interface{},
+) ([]*gitlab.GroupVariable, error) {
+
+	// Get each page of variables.  Note that each call gets its own
+	// copy of the options because the next page is prefetched
+	// concurrently.
+	getPage := func(page int) ([]*gitlab.GroupVariable, *gitlab.Response, error) {
+		opts := gitlab.ListGroupVariablesOptions{}
+		opts.Page = page
+		vs, resp, err := s.ListVariables(group, &opts, gitlab.WithContext(ctx))
+		if err != nil {
+			return nil, nil, fmt.Errorf(
+				"GetAllGroupVariables: %w", ClassifyError(err))
+		}
+		return vs, resp, nil
+	}
+
+	return GetAllPages(ctx, getPage)
+}
@@ -0,0 +1,91 @@
+// This file provides a bounded worker pool for performing the API
+// calls of bulk operations in parallel.
+
+package gitlab_util
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ForEachConcurrently calls f once for each item using at most
+// concurrency goroutines at a time.  If concurrency is less than one,
+// one is used which processes the items in order.  Unlike the
+// iterators in this package, an error returned by f does not stop the
+// remaining items from being processed.  Instead, the errors of all
+// the items are joined (in the order of the items) into the returned
+// error so they can all be reported.  If ctx is done, the items not yet
+// started are skipped, and ctx.Err() is also returned.  Because f is
+// called from multiple goroutines, it must be safe for concurrent use.
+func ForEachConcurrently[T any](
+	ctx context.Context,
+	items []T,
+	concurrency int,
+	f func(item T) error,
+) error {
+	return forEachConcurrently(ctx, items, concurrency, false, f)
+}
+
+// ForEachConcurrentlyUntilError is the same as ForEachConcurrently()
+// except that once f returns an error, the items not yet started are
+// skipped.  The items already running are allowed to finish, and
+// their errors are also returned.  If concurrency is one, no item is
+// started after the first item that fails.
+func ForEachConcurrentlyUntilError[T any](
+	ctx context.Context,
+	items []T,
+	concurrency int,
+	f func(item T) error,
+) error {
+	return forEachConcurrently(ctx, items, concurrency, true, f)
+}
+
+// forEachConcurrently implements ForEachConcurrently() and
+// ForEachConcurrentlyUntilError().  If stopOnError is true, the items
+// not yet started when f returns an error are skipped.
+func forEachConcurrently[T any](
+	ctx context.Context,
+	items []T,
+	concurrency int,
+	stopOnError bool,
+	f func(item T) error,
+) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Start a goroutine for each item but only allow concurrency of
+	// them to run at a time.  Because the failed flag is set before
+	// the slot of the failed item is released, no item is started
+	// after a failure when only one item runs at a time.
+	errs := make([]error, len(items)+1)
+	sem := make(chan struct{}, concurrency)
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+	for i, item := range items {
+		sem <- struct{}{}
+		if stopOnError && failed.Load() {
+			<-sem
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			errs[len(items)] = err
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = f(item)
+			if errs[i] != nil {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
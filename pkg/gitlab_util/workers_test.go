@@ -0,0 +1,124 @@
+package gitlab_util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestForEachConcurrently(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	errOdd := errors.New("odd")
+
+	type Data []struct {
+		concurrency int
+		maxRunning  int
+	}
+	data := Data{
+		{concurrency: 0, maxRunning: 1},
+		{concurrency: 1, maxRunning: 1},
+		{concurrency: 3, maxRunning: 3},
+	}
+
+	for _, d := range data {
+		var mutex sync.Mutex
+		running := 0
+		maxRunning := 0
+		processed := map[int]bool{}
+		err := ForEachConcurrently(context.Background(), items, d.concurrency,
+			func(item int) error {
+				mutex.Lock()
+				running++
+				maxRunning = max(maxRunning, running)
+				processed[item] = true
+				mutex.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mutex.Lock()
+				running--
+				mutex.Unlock()
+				if item%2 == 1 {
+					return fmt.Errorf("item %d: %w", item, errOdd)
+				}
+				return nil
+			})
+
+		// Verify all the items were processed despite the errors and
+		// that the errors are aggregated in order.
+		if len(processed) != len(items) {
+			t.Errorf("concurrency=%d: processed %d of %d items",
+				d.concurrency, len(processed), len(items))
+		}
+		if !errors.Is(err, errOdd) {
+			t.Fatalf("concurrency=%d: expected=%v  actual=%v", d.concurrency, errOdd, err)
+		}
+		expected := "item 1: odd\nitem 3: odd\nitem 5: odd\nitem 7: odd"
+		if err.Error() != expected {
+			t.Errorf("concurrency=%d: expected=%q  actual=%q",
+				d.concurrency, expected, err.Error())
+		}
+		if maxRunning > d.maxRunning {
+			t.Errorf("concurrency=%d: expected at most %d running  actual=%d",
+				d.concurrency, d.maxRunning, maxRunning)
+		}
+	}
+
+	// Verify items are not started after the context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+	err := ForEachConcurrently(ctx, items, 1, func(item int) error {
+		count++
+		if item == 2 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || count != 2 {
+		t.Errorf("canceled: expected 2 items and %v: count=%d  err=%v",
+			context.Canceled, count, err)
+	}
+}
+
+func TestForEachConcurrentlyUntilError(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6}
+	errThree := errors.New("three")
+
+	// Verify no item is started after the first failure when the
+	// items are processed one at a time.
+	var processed []int
+	err := ForEachConcurrentlyUntilError(context.Background(), items, 1,
+		func(item int) error {
+			processed = append(processed, item)
+			if item == 3 {
+				return errThree
+			}
+			return nil
+		})
+	if !errors.Is(err, errThree) {
+		t.Errorf("expected=%v  actual=%v", errThree, err)
+	}
+	if fmt.Sprint(processed) != "[1 2 3]" {
+		t.Errorf("expected=%v  actual=%v", "[1 2 3]", processed)
+	}
+
+	// Verify all the items are processed if none fails.
+	var mutex sync.Mutex
+	count := 0
+	err = ForEachConcurrentlyUntilError(context.Background(), items, 3,
+		func(item int) error {
+			mutex.Lock()
+			defer mutex.Unlock()
+			count++
+			return nil
+		})
+	if err != nil || count != len(items) {
+		t.Errorf("expected %d items and no error: count=%d  err=%v",
+			len(items), count, err)
+	}
+}
@@ -0,0 +1,144 @@
+// This file provides a small message catalog so user-facing strings
+// can be translated without patching every call to fmt.Printf().
+//
+// Messages are looked up by their English text (i.e., the format
+// string passed to Printf(), Fprintf(), Errorf(), or Sprintf()).  If
+// there is no translation for the current locale, the English text is
+// used.  To ship a translated build, add a file that registers a
+// Catalog for the locale from an init() function, for example:
+//
+//	func init() {
+//		i18n.Register("de", i18n.Catalog{
+//			"- Deleting project: %q ... ": "- Lösche Projekt: %q ... ",
+//			"Done.\n":                     "Fertig.\n",
+//		})
+//	}
+//
+// Translations must keep the same formatting verbs in the same order
+// as the English text.
+
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Catalog
+////////////////////////////////////////////////////////////////////////
+
+// Catalog maps the English text of a message to its translation.
+type Catalog map[string]string
+
+var (
+	// mutex protects catalogs and locale.
+	mutex sync.RWMutex
+
+	// catalogs maps from locale (e.g., "de" or "de_DE") to the
+	// catalog for the locale.
+	catalogs = make(map[string]Catalog)
+
+	// locale is the current locale.  It is detected from the
+	// environment when this package is initialized.
+	locale = DetectLocale()
+)
+
+// Register adds the translations in catalog to the catalog for the
+// locale.  Locales have the form "language" or "language_TERRITORY"
+// (e.g., "de" or "pt_BR").
+func Register(locale string, catalog Catalog) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	c, ok := catalogs[locale]
+	if !ok {
+		c = make(Catalog)
+		catalogs[locale] = c
+	}
+	for k, v := range catalog {
+		c[k] = v
+	}
+}
+
+// DetectLocale returns the locale from the LC_ALL, LC_MESSAGES, or
+// LANG environment variables (in that order) without the encoding or
+// modifier (e.g., "de_DE.UTF-8" becomes "de_DE").  It returns "" if
+// none of them are set or if the locale is "C" or "POSIX".
+func DetectLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		value, _, _ = strings.Cut(value, ".")
+		value, _, _ = strings.Cut(value, "@")
+		if value == "C" || value == "POSIX" {
+			return ""
+		}
+		return value
+	}
+	return ""
+}
+
+// Locale returns the current locale.
+func Locale() string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	return locale
+}
+
+// SetLocale sets the current locale.  An empty locale disables
+// translation.
+func SetLocale(l string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	locale = l
+}
+
+////////////////////////////////////////////////////////////////////////
+// Translation
+////////////////////////////////////////////////////////////////////////
+
+// T returns the translation of msg for the current locale.  The
+// catalog for the full locale (e.g., "pt_BR") is tried before the
+// catalog for just the language (e.g., "pt").  If neither has a
+// translation, msg is returned unchanged.
+func T(msg string) string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	if locale == "" {
+		return msg
+	}
+	if s, ok := catalogs[locale][msg]; ok {
+		return s
+	}
+	language, _, _ := strings.Cut(locale, "_")
+	if s, ok := catalogs[language][msg]; ok {
+		return s
+	}
+	return msg
+}
+
+// Sprintf is fmt.Sprintf() with a translated format.
+func Sprintf(format string, a ...any) string {
+	return fmt.Sprintf(T(format), a...)
+}
+
+// Printf is fmt.Printf() with a translated format.
+func Printf(format string, a ...any) (int, error) {
+	return fmt.Printf(T(format), a...)
+}
+
+// Fprintf is fmt.Fprintf() with a translated format.
+func Fprintf(w io.Writer, format string, a ...any) (int, error) {
+	return fmt.Fprintf(w, T(format), a...)
+}
+
+// Errorf is fmt.Errorf() with a translated format.  Errors wrapped
+// with %w can still be unwrapped.
+func Errorf(format string, a ...any) error {
+	return fmt.Errorf(T(format), a...)
+}
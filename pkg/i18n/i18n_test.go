@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestT(t *testing.T) {
+	Register("xx", Catalog{"Done.\n": "xx-Done.\n", "hello": "xx-hello"})
+	Register("xx_YY", Catalog{"hello": "xx_YY-hello"})
+	defer SetLocale(Locale())
+
+	type Data []struct {
+		locale   string
+		msg      string
+		expected string
+	}
+
+	data := Data{
+		{locale: "", msg: "hello", expected: "hello"},
+		{locale: "xx", msg: "hello", expected: "xx-hello"},
+		{locale: "xx_YY", msg: "hello", expected: "xx_YY-hello"},
+		{locale: "xx_YY", msg: "Done.\n", expected: "xx-Done.\n"},
+		{locale: "xx", msg: "missing", expected: "missing"},
+		{locale: "zz", msg: "hello", expected: "hello"},
+	}
+
+	for _, d := range data {
+		SetLocale(d.locale)
+		actual := T(d.msg)
+		if actual != d.expected {
+			t.Errorf("%q: T(%q): expected=%q  actual=%q",
+				d.locale, d.msg, d.expected, actual)
+		}
+	}
+}
+
+func TestDetectLocale(t *testing.T) {
+	type Data []struct {
+		lcAll    string
+		lang     string
+		expected string
+	}
+
+	data := Data{
+		{lcAll: "", lang: "", expected: ""},
+		{lcAll: "", lang: "de_DE.UTF-8", expected: "de_DE"},
+		{lcAll: "fr_FR@euro", lang: "de_DE.UTF-8", expected: "fr_FR"},
+		{lcAll: "C", lang: "de_DE.UTF-8", expected: ""},
+	}
+
+	for _, d := range data {
+		t.Setenv("LC_ALL", d.lcAll)
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", d.lang)
+		actual := DetectLocale()
+		if actual != d.expected {
+			t.Errorf("LC_ALL=%q LANG=%q: expected=%q  actual=%q",
+				d.lcAll, d.lang, d.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// This file provides the event hook that logs the events of
+// long-running operations as structured records.
+
+package logging
+
+import (
+	"log/slog"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/gitlab_util"
+)
+
+// EventHook is a gitlab_util.EventHook that logs each event with the
+// default slog logger and then forwards it to Next.  Items and pages
+// are logged at the debug level, and errors are logged at the error
+// level.
+type EventHook struct {
+
+	// Next is the hook that also receives the events.  If nil, the
+	// events are only logged.
+	Next gitlab_util.EventHook
+}
+
+// NewEventHook returns a new EventHook that forwards the events to
+// next which can be nil.
+func NewEventHook(next gitlab_util.EventHook) *EventHook {
+	return &EventHook{Next: next}
+}
+
+func (h *EventHook) OnItemStart(name string) {
+	slog.Debug("item started", "item", name)
+	if h.Next != nil {
+		h.Next.OnItemStart(name)
+	}
+}
+
+func (h *EventHook) OnItemDone(name string) {
+	slog.Debug("item done", "item", name)
+	if h.Next != nil {
+		h.Next.OnItemDone(name)
+	}
+}
+
+func (h *EventHook) OnError(name string, err error) {
+	slog.Error("item failed", "item", name, "error", err)
+	if h.Next != nil {
+		h.Next.OnError(name, err)
+	}
+}
+
+func (h *EventHook) OnPage(page int, totalPages int, count int) {
+	slog.Debug("page received", "page", page, "total_pages", totalPages, "count", count)
+	if h.Next != nil {
+		h.Next.OnPage(page, totalPages, count)
+	}
+}
@@ -0,0 +1,88 @@
+// This file provides the logging layer which is based on log/slog.
+// It decides how verbose the program is, prints the progress messages
+// of the commands, logs the events of long-running operations as
+// structured records, and optionally traces HTTP requests.
+//
+// There are two kinds of output.  Progress messages (e.g., "- Deleting
+// project ... Done.") are written to stdout with Printf() at the info
+// level so --quiet suppresses them.  Structured records are written to
+// stderr by the default slog logger so they never mix with the output
+// of list commands.
+
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/i18n"
+)
+
+// Names of the log levels accepted by ParseLevel().
+const (
+	LevelDebug = "debug"
+	LevelInfo  = "info"
+	LevelWarn  = "warn"
+	LevelError = "error"
+)
+
+// Levels are the names of the log levels in order of increasing
+// severity.
+var Levels = []string{LevelDebug, LevelInfo, LevelWarn, LevelError}
+
+// level is the current log level.  It is shared by the default slog
+// logger and Printf() so both can be changed at once with SetLevel().
+var level = new(slog.LevelVar)
+
+// ParseLevel returns the slog.Level for the name which is one of
+// Levels.  Case is ignored.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case LevelDebug:
+		return slog.LevelDebug, nil
+	case LevelInfo:
+		return slog.LevelInfo, nil
+	case LevelWarn:
+		return slog.LevelWarn, nil
+	case LevelError:
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, i18n.Errorf("ParseLevel: invalid log level: %q", name)
+}
+
+// Setup makes the default slog logger write text records at or above
+// the level to w and sets the level used by Printf().
+func Setup(w io.Writer, l slog.Level) {
+	level.Set(l)
+	slog.SetDefault(slog.New(slog.NewTextHandler(w,
+		&slog.HandlerOptions{Level: level})))
+}
+
+// SetLevel sets the level used by the default slog logger (if it was
+// created by Setup()) and by Printf().
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Level returns the current log level.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// Enabled returns true if messages at the level are logged.
+func Enabled(l slog.Level) bool {
+	return l >= level.Level()
+}
+
+// Printf prints the translated progress message to stdout if the
+// level is info or lower.  It replaces i18n.Printf() for progress
+// messages so they can be silenced with --quiet.
+func Printf(format string, a ...any) (int, error) {
+	if !Enabled(slog.LevelInfo) {
+		return 0, nil
+	}
+	return fmt.Fprintf(os.Stdout, i18n.T(format), a...)
+}
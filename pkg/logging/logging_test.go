@@ -0,0 +1,113 @@
+package logging
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestParseLevel(t *testing.T) {
+	type Data []struct {
+		name     string
+		expected slog.Level
+		valid    bool
+	}
+	data := Data{
+		{"debug", slog.LevelDebug, true},
+		{"INFO", slog.LevelInfo, true},
+		{"warn", slog.LevelWarn, true},
+		{"error", slog.LevelError, true},
+		{"bogus", slog.LevelInfo, false},
+	}
+	for _, d := range data {
+		actual, err := ParseLevel(d.name)
+		if actual != d.expected || (err == nil) != d.valid {
+			t.Errorf("ParseLevel(%q): expected=%v,%v  actual=%v,%v",
+				d.name, d.expected, d.valid, actual, err)
+		}
+	}
+}
+
+func TestPrintf(t *testing.T) {
+	defer SetLevel(slog.LevelInfo)
+
+	// print captures what Printf() writes to stdout at the level.
+	print := func(l slog.Level) string {
+		SetLevel(l)
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		stdout := os.Stdout
+		os.Stdout = w
+		Printf("- Deleting %q ... ", "foo")
+		os.Stdout = stdout
+		w.Close()
+		out, _ := io.ReadAll(r)
+		return string(out)
+	}
+
+	if actual := print(slog.LevelInfo); actual != `- Deleting "foo" ... ` {
+		t.Errorf("Printf at info: expected=%q  actual=%q", `- Deleting "foo" ... `, actual)
+	}
+	if actual := print(slog.LevelWarn); actual != "" {
+		t.Errorf("Printf at warn: expected=%q  actual=%q", "", actual)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	defer Setup(os.Stderr, slog.LevelInfo)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/missing" {
+				http.Error(w, `{"message":"404 Project Not Found"}`, http.StatusNotFound)
+				return
+			}
+			w.Write([]byte("ok"))
+		}))
+	defer server.Close()
+	client := NewHTTPClient()
+
+	// get gets the path and returns what was logged and the body the
+	// caller received.
+	get := func(l slog.Level, path string) (string, string) {
+		var log bytes.Buffer
+		Setup(&log, l)
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: unexpected error: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return log.String(), string(body)
+	}
+
+	// Verify nothing is traced above the debug level.
+	log, _ := get(slog.LevelInfo, "/ok")
+	if log != "" {
+		t.Errorf("Transport at info: expected=%q  actual=%q", "", log)
+	}
+
+	// Verify requests are traced, secrets are redacted, and the body
+	// of an error response is logged and still readable.
+	log, _ = get(slog.LevelDebug, "/ok?private_token=secret")
+	if !strings.Contains(log, "status=200") || strings.Contains(log, "secret") {
+		t.Errorf("Transport at debug: unexpected log: %q", log)
+	}
+	log, body := get(slog.LevelDebug, "/missing")
+	if !strings.Contains(log, "status=404") || !strings.Contains(log, "Project Not Found") {
+		t.Errorf("Transport error body: unexpected log: %q", log)
+	}
+	if !strings.Contains(body, "Project Not Found") {
+		t.Errorf("Transport error body: expected readable body, actual=%q", body)
+	}
+}
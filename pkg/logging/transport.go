@@ -0,0 +1,104 @@
+// This file provides the HTTP transport that traces requests and
+// responses so failed API calls can be debugged.
+
+package logging
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxTracedBody is the maximum number of bytes of a response body
+// that are logged.
+const maxTracedBody = 4096
+
+// redactedParams are the query parameters whose values are replaced in
+// traced URLs because they hold secrets.
+var redactedParams = []string{"private_token", "access_token", "job_token"}
+
+// Transport is an http.RoundTripper that logs each request and its
+// response at the debug level with the default slog logger.  The body
+// of a response with an error status is logged too because it usually
+// explains the error.  Headers are never logged because they hold the
+// authentication token.
+type Transport struct {
+
+	// Base is the transport that actually sends the requests.  If
+	// nil, http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// NewHTTPClient returns a new http.Client that traces its requests
+// with a Transport.
+func NewHTTPClient() *http.Client {
+	return &http.Client{Transport: &Transport{}}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	// Skip the work if tracing is disabled.
+	if !Enabled(slog.LevelDebug) {
+		return base.RoundTrip(req)
+	}
+
+	// Send the request.
+	u := redactURL(req.URL)
+	slog.Debug("http request", "method", req.Method, "url", u)
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		slog.Debug("http error", "method", req.Method, "url", u,
+			"elapsed", elapsed, "error", err)
+		return nil, err
+	}
+
+	// Log the response.  For an error status, the body is read so it
+	// can be logged and then replaced so the caller can still read
+	// it.
+	attrs := []any{
+		"method", req.Method, "url", u,
+		"status", resp.StatusCode, "elapsed", elapsed,
+	}
+	if resp.StatusCode >= http.StatusBadRequest && resp.Body != nil {
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr == nil {
+			if len(body) > maxTracedBody {
+				body = body[:maxTracedBody]
+			}
+			attrs = append(attrs, "body", string(body))
+		}
+	}
+	slog.Debug("http response", attrs...)
+	return resp, nil
+}
+
+// redactURL returns the URL as a string with the values of the
+// redactedParams replaced.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	changed := false
+	for _, name := range redactedParams {
+		if query.Has(name) {
+			query.Set(name, "REDACTED")
+			changed = true
+		}
+	}
+	if !changed {
+		return u.String()
+	}
+	copy := *u
+	copy.RawQuery = query.Encode()
+	return copy.String()
+}
@@ -0,0 +1,54 @@
+package string_slice
+
+import (
+	"os"
+	"strings"
+)
+
+type StringSlice []string
+
+// Convert xs to a string.  This is needed for the flag.Value
+// interface so we can read comman-separated values into a
+// StringSlice.
+func (xs StringSlice) String() string {
+	var result strings.Builder
+	for i, x := range xs {
+		if i > 0 {
+			result.WriteString(",")
+		}
+		result.WriteString(x)
+	}
+	return result.String()
+}
+
+// Set appends the values in s to xs.  To specify multiple values
+// separate each with a comma and no space, or give the flag multiple
+// times because the values accumulate.  If s is "@" followed by a file
+// name, each line of the file is a single value which allows values
+// to contain commas.  Blank lines and lines starting with "#" are
+// ignored.  This is needed for the flag.Value interface so we can read
+// comman-separated values into xs.
+func (xs *StringSlice) Set(s string) error {
+
+	// Read one value per line from the file.
+	if fileName, ok := strings.CutPrefix(s, "@"); ok {
+		content, err := os.ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			*xs = append(*xs, line)
+		}
+		return nil
+	}
+
+	// Split the comma-separated values.
+	for _, part := range strings.Split(s, ",") {
+		*xs = append(*xs, strings.TrimSpace(part))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package string_slice
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSet(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "users.txt")
+	err := os.WriteFile(fileName,
+		[]byte("# Users\nfoo\n\n  Bar, Baz  \n"), 0644)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type Data []struct {
+		values   []string
+		expected StringSlice
+	}
+
+	data := Data{
+		{
+			values:   []string{"foo"},
+			expected: StringSlice{"foo"},
+		},
+		{
+			values:   []string{"foo,bar"},
+			expected: StringSlice{"foo", "bar"},
+		},
+		{
+			values:   []string{"foo", "bar,baz"},
+			expected: StringSlice{"foo", "bar", "baz"},
+		},
+		{
+			values:   []string{"@" + fileName},
+			expected: StringSlice{"foo", "Bar, Baz"},
+		},
+		{
+			values:   []string{"qux", "@" + fileName},
+			expected: StringSlice{"qux", "foo", "Bar, Baz"},
+		},
+	}
+
+	for _, d := range data {
+		var actual StringSlice
+		for _, v := range d.values {
+			err := actual.Set(v)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if diff := cmp.Diff(d.expected, actual); diff != "" {
+			t.Errorf("Set(%q): -expected +actual:\n%s", d.values, diff)
+		}
+	}
+
+	// Verify a missing file is an error.
+	var xs StringSlice
+	err = xs.Set("@" + filepath.Join(t.TempDir(), "missing.txt"))
+	if err == nil {
+		t.Errorf("Set: expected an error for a missing file")
+	}
+}
@@ -0,0 +1,340 @@
+// This file validates XML files against the Go types they are decoded
+// into.  The "xml" package silently ignores elements it does not know
+// about, so a misspelled or misplaced element in a configuration file
+// would otherwise go unnoticed.  The schema is derived from the "xml"
+// struct tags of the type so it never gets out of sync with the code.
+
+package xml_schema
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Errors
+////////////////////////////////////////////////////////////////////////
+
+var (
+	ErrInvalid = errors.New("invalid XML")
+)
+
+// Error describes a single problem with the XML including where it
+// was found.
+type Error struct {
+
+	// FileName is the name of the file with the problem.
+	FileName string
+
+	// Line is the line (starting at 1) of the problem.
+	Line int
+
+	// Column is the column (starting at 1) of the problem.
+	Column int
+
+	// Message describes the problem.
+	Message string
+}
+
+// Error returns the message prefixed with the file name, line, and
+// column in the usual "file:line:column" format.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.FileName, e.Line, e.Column, e.Message)
+}
+
+// Errors are all of the problems found in the XML.  It wraps
+// ErrInvalid so callers can use errors.Is().
+type Errors []*Error
+
+// Error returns the problems with one per line.
+func (errs Errors) Error() string {
+	var lines []string
+	for _, e := range errs {
+		lines = append(lines, e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns ErrInvalid.
+func (errs Errors) Unwrap() error {
+	return ErrInvalid
+}
+
+////////////////////////////////////////////////////////////////////////
+// Schema
+////////////////////////////////////////////////////////////////////////
+
+// schema describes the allowed content of an element.  Elements with
+// children have a non-nil children map.  Other elements hold text
+// which must be valid for the leaf type.  Wrapper elements are the
+// intermediate elements of tags like "users>user".
+type schema struct {
+	children map[string]*schema
+	leaf     reflect.Type
+	wrapper  bool
+}
+
+var (
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	xmlNameType         = reflect.TypeOf(xml.Name{})
+)
+
+// isLeaf returns true if values of the type are parsed from the text
+// of an element instead of from child elements.
+func isLeaf(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	if pt.Implements(flagValueType) || pt.Implements(textUnmarshalerType) {
+		return t.Kind() != reflect.Slice
+	}
+	return t.Kind() != reflect.Struct
+}
+
+// newSchema returns the schema for elements decoded into the type.
+func newSchema(t reflect.Type) *schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isLeaf(t) {
+		return &schema{leaf: t}
+	}
+	s := &schema{children: map[string]*schema{}}
+	s.addFields(t)
+	return s
+}
+
+// addFields adds the children for the fields of the struct type.
+// Fields of embedded structs without a tag are added as if they were
+// fields of the struct itself.
+func (s *schema) addFields(t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("xml")
+		if tag == "-" || field.Type == xmlNameType {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		if flags != "" && flags != "omitempty" {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			s.addFields(field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		// The elements of slices are repeated elements.
+		ft := field.Type
+		if ft.Kind() == reflect.Slice && ft.Elem().Kind() != reflect.Uint8 {
+			ft = ft.Elem()
+		}
+
+		// Handle paths like "users>user" by adding the intermediate
+		// elements.
+		parent := s
+		path := strings.Split(name, ">")
+		for _, p := range path[:len(path)-1] {
+			child := parent.children[p]
+			if child == nil {
+				child = &schema{children: map[string]*schema{}, wrapper: true}
+				parent.children[p] = child
+			}
+			parent = child
+		}
+		parent.children[path[len(path)-1]] = newSchema(ft)
+	}
+}
+
+// rootName returns the name of the root element required by the
+// XMLName field of the struct type or "" if any name is allowed.
+func rootName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Type == xmlNameType {
+			name, _, _ := strings.Cut(field.Tag.Get("xml"), ",")
+			return name
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if name := rootName(field.Type); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// findPaths returns the paths (relative to s) of the elements having
+// the name.
+func (s *schema) findPaths(name string, prefix string) []string {
+	var result []string
+	for childName, child := range s.children {
+		path := prefix + "<" + childName + ">"
+		if childName == name {
+			result = append(result, path)
+		}
+		result = append(result, child.findPaths(name, path)...)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// checkLeaf returns an error if the text is not a valid value for the
+// leaf type.
+func checkLeaf(t reflect.Type, text string) error {
+	v := reflect.New(t)
+	if u, ok := v.Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(text))
+	}
+	if f, ok := v.Interface().(flag.Value); ok {
+		return f.Set(text)
+	}
+	text = strings.TrimSpace(text)
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		_, err = strconv.ParseBool(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err = strconv.ParseInt(text, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		_, err = strconv.ParseUint(text, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		_, err = strconv.ParseFloat(text, t.Bits())
+	}
+	if err != nil && text == "" {
+
+		// The "xml" package sets empty numbers and booleans to
+		// their zero values.
+		return nil
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////
+// Validate
+////////////////////////////////////////////////////////////////////////
+
+// Validate checks the XML against the schema derived from the "xml"
+// struct tags of the type of v which is the same type the XML is
+// decoded into.  It reports unknown and misplaced elements and values
+// that cannot be parsed.  The file name is only used in the errors.
+// If there are problems, the returned error is of type Errors which
+// wraps ErrInvalid.
+func Validate(fileName string, data []byte, v any) error {
+	var errs Errors
+	t := reflect.TypeOf(v)
+	root := newSchema(t)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	// frame is an open element.  If s is nil, the element is not
+	// known, and its content is not checked.
+	type frame struct {
+		name   string
+		s      *schema
+		line   int
+		column int
+		text   strings.Builder
+	}
+	var stack []*frame
+
+	// fail records a problem.
+	fail := func(line int, column int, format string, args ...any) {
+		errs = append(errs, &Error{
+			FileName: fileName,
+			Line:     line,
+			Column:   column,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	for {
+		line, column := decoder.InputPos()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				fail(syntaxErr.Line, 1, "%s", syntaxErr.Msg)
+			} else {
+				fail(line, column, "%v", err)
+			}
+			return errs
+		}
+
+		switch token := token.(type) {
+		case xml.StartElement:
+			name := token.Name.Local
+			f := &frame{name: name, line: line, column: column}
+
+			// Find the schema of the element.
+			switch {
+			case len(stack) == 0:
+				f.s = root
+				if want := rootName(t); want != "" && want != name {
+					fail(line, column, "root element is <%s> instead of <%s>", name, want)
+					f.s = nil
+				}
+			case stack[len(stack)-1].s == nil:
+			case stack[len(stack)-1].s.children == nil:
+				parent := stack[len(stack)-1]
+				fail(line, column, "unexpected element <%s> in <%s> which holds a value",
+					name, parent.name)
+			default:
+				parent := stack[len(stack)-1]
+				f.s = parent.s.children[name]
+				if f.s == nil {
+					paths := root.findPaths(name, "<"+stack[0].name+">")
+					if len(paths) > 0 {
+						fail(line, column, "misplaced element <%s> in <%s>; expected in %s",
+							name, parent.name, strings.Join(paths, " or "))
+					} else {
+						fail(line, column, "unknown element <%s> in <%s>", name, parent.name)
+					}
+				}
+			}
+			stack = append(stack, f)
+
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(token)
+			}
+
+		case xml.EndElement:
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if f.s != nil && f.s.children == nil {
+				err := checkLeaf(f.s.leaf, f.text.String())
+				if err != nil {
+					fail(f.line, f.column, "invalid value %q for <%s>: %v",
+						strings.TrimSpace(f.text.String()), f.name, err)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,270 @@
+package xml_schema
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jalitriver/gitlab-cmds/pkg/date_arg"
+	"github.com/jalitriver/gitlab-cmds/pkg/string_slice"
+)
+
+////////////////////////////////////////////////////////////////////////
+// Types
+////////////////////////////////////////////////////////////////////////
+
+type testCommonOptions struct {
+	Recursive bool `xml:"recursive"`
+}
+
+type testListOptions struct {
+	testCommonOptions
+	CreatedAfter date_arg.DateArg         `xml:"created-after"`
+	Names        string_slice.StringSlice `xml:"name"`
+	Limit        int                      `xml:"limit"`
+}
+
+type testGlobalOptions struct {
+	BaseURL string `xml:"base-url"`
+}
+
+type testOptions struct {
+	XMLName    xml.Name          `xml:"options"`
+	GlobalOpts testGlobalOptions `xml:"global-options"`
+	ListOpts   testListOptions   `xml:"list-options"`
+	Users      []string          `xml:"users>user"`
+}
+
+////////////////////////////////////////////////////////////////////////
+// Tests
+////////////////////////////////////////////////////////////////////////
+
+func TestValidate(t *testing.T) {
+	type Data []struct {
+		xml      string
+		expected []string
+	}
+
+	data := Data{
+		{
+			xml: `<options>
+  <global-options>
+    <base-url>https://gitlab.com/</base-url>
+  </global-options>
+  <list-options>
+    <recursive>true</recursive>
+    <created-after>2024/01/02</created-after>
+    <name>a</name>
+    <name>b</name>
+    <limit></limit>
+  </list-options>
+  <users>
+    <user>aberns</user>
+  </users>
+</options>`,
+			expected: nil,
+		},
+		{
+			xml: `<options>
+  <global-options>
+    <base-ulr>https://gitlab.com/</base-ulr>
+  </global-options>
+</options>`,
+			expected: []string{
+				"test.xml:3:5: unknown element <base-ulr> in <global-options>",
+			},
+		},
+		{
+			xml: `<options>
+  <recursive>true</recursive>
+</options>`,
+			expected: []string{
+				"test.xml:2:3: misplaced element <recursive> in <options>; " +
+					"expected in <options><list-options><recursive>",
+			},
+		},
+		{
+			xml: `<options>
+  <list-options>
+    <limit>ten</limit>
+    <created-after>yesterday</created-after>
+    <recursive>yes</recursive>
+  </list-options>
+</options>`,
+			expected: []string{
+				`test.xml:3:5: invalid value "ten" for <limit>: `,
+				`test.xml:4:5: invalid value "yesterday" for <created-after>: `,
+				`test.xml:5:5: invalid value "yes" for <recursive>: `,
+			},
+		},
+		{
+			xml: `<options>
+  <global-options>
+    <base-url>x<b>y</b></base-url>
+  </global-options>
+</options>`,
+			expected: []string{
+				"test.xml:3:16: unexpected element <b> in <base-url> which holds a value",
+			},
+		},
+		{
+			xml: `<settings>
+</settings>`,
+			expected: []string{
+				"test.xml:1:1: root element is <settings> instead of <options>",
+			},
+		},
+		{
+			xml: `<options>
+  <global-options>
+</options>`,
+			expected: []string{
+				"test.xml:3:",
+			},
+		},
+	}
+
+	for _, d := range data {
+		err := Validate("test.xml", []byte(d.xml), &testOptions{})
+		if d.expected == nil {
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected ErrInvalid: %v", err)
+			continue
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != len(d.expected) {
+			t.Errorf("unexpected errors: expected=%q  actual=%q", d.expected, lines)
+			continue
+		}
+		for i, line := range lines {
+			if !strings.HasPrefix(line, d.expected[i]) {
+				t.Errorf("unexpected error: expected=%q  actual=%q", d.expected[i], line)
+			}
+		}
+	}
+}
+
+func TestConvertYAML(t *testing.T) {
+
+	// Verify YAML and JSON are converted to XML that decodes to the
+	// expected options.
+	data := []string{
+		`
+global-options:
+  base-url: https://gitlab.com/?a=1&b=2
+list-options:
+  recursive: true
+  created-after: 2024/01/02
+  name: [a, b]
+  limit:
+users:
+  - aberns
+  - bcrocket
+`,
+		`{
+  "global-options": {"base-url": "https://gitlab.com/?a=1&b=2"},
+  "list-options": {
+    "recursive": true,
+    "created-after": "2024/01/02",
+    "name": ["a", "b"],
+    "limit": null
+  },
+  "users": ["aberns", "bcrocket"]
+}`,
+	}
+	for _, d := range data {
+		content, err := ConvertYAML("test.yaml", []byte(d), &testOptions{})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		err = Validate("test.xml", content, &testOptions{})
+		if err != nil {
+			t.Errorf("unexpected error: %v\n%s", err, content)
+			continue
+		}
+		var opts testOptions
+		err = xml.Unmarshal(content, &opts)
+		if err != nil {
+			t.Errorf("unexpected error: %v\n%s", err, content)
+			continue
+		}
+		if opts.GlobalOpts.BaseURL != "https://gitlab.com/?a=1&b=2" ||
+			!opts.ListOpts.Recursive ||
+			strings.Join(opts.ListOpts.Names, ",") != "a,b" ||
+			strings.Join(opts.Users, ",") != "aberns,bcrocket" {
+			t.Errorf("unexpected options: %+v", opts)
+		}
+	}
+
+	// Verify problems are reported with their position in the YAML.
+	type Data []struct {
+		yaml     string
+		expected []string
+	}
+	errData := Data{
+		{
+			yaml: `global-options:
+  base-ulr: https://gitlab.com/
+`,
+			expected: []string{
+				"test.yaml:2:3: unknown element <base-ulr> in <global-options>",
+			},
+		},
+		{
+			yaml: `recursive: true
+`,
+			expected: []string{
+				"test.yaml:1:1: misplaced element <recursive> in <options>; " +
+					"expected in <options><list-options><recursive>",
+			},
+		},
+		{
+			yaml: `list-options:
+  limit: ten
+  recursive: {a: b}
+`,
+			expected: []string{
+				`test.yaml:2:10: invalid value "ten" for <limit>: `,
+				`test.yaml:3:14: expected a value for <recursive>`,
+			},
+		},
+		{
+			yaml: `global-options: [a]
+`,
+			expected: []string{
+				"test.yaml:1:18: expected a mapping for <global-options>",
+			},
+		},
+		{
+			yaml: `global-options: {
+`,
+			expected: []string{
+				"test.yaml:1:1: ",
+			},
+		},
+	}
+	for _, d := range errData {
+		_, err := ConvertYAML("test.yaml", []byte(d.yaml), &testOptions{})
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected ErrInvalid: %v", err)
+			continue
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != len(d.expected) {
+			t.Errorf("unexpected errors: expected=%q  actual=%q", d.expected, lines)
+			continue
+		}
+		for i, line := range lines {
+			if !strings.HasPrefix(line, d.expected[i]) {
+				t.Errorf("unexpected error: expected=%q  actual=%q", d.expected[i], line)
+			}
+		}
+	}
+}
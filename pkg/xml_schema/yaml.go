@@ -0,0 +1,179 @@
+// This file converts YAML (and therefore JSON which is a subset of
+// YAML) into the equivalent XML so that files authored in YAML can be
+// decoded by the same code that decodes XML.  The conversion is guided
+// by the same schema used by Validate() so the YAML is validated while
+// it is converted, and problems are reported with their location in
+// the YAML instead of in the generated XML.
+
+package xml_schema
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConvertYAML converts the YAML into the XML that is decoded into the
+// type of v.  Mapping keys are element names, and scalars are the text
+// of the elements.  A sequence is converted into repeated elements
+// except for a wrapper element (e.g., <users> for the "users>user"
+// tag) in which case each item of the sequence becomes a child of the
+// wrapper.  For example, the following YAML
+//
+//	users:
+//	  - alice
+//	  - bob
+//
+// is converted into "<users><user>alice</user><user>bob</user></users>".
+// The file name is only used in the errors.  If there are problems,
+// the returned error is of type Errors which wraps ErrInvalid.
+func ConvertYAML(fileName string, data []byte, v any) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	c := &converter{
+		fileName: fileName,
+		root:     newSchema(t),
+		rootName: rootName(t),
+	}
+	if c.rootName == "" {
+		c.rootName = "root"
+	}
+
+	// Parse the YAML.
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			err = errors.New(strings.Join(typeErr.Errors, "; "))
+		}
+		c.fail(&yaml.Node{Line: 1, Column: 1}, "%v", err)
+		return nil, c.errs
+	}
+
+	// Convert the document which is empty or holds a single mapping.
+	c.out.WriteString("<" + c.rootName + ">\n")
+	if len(doc.Content) > 0 {
+		c.convertChildren(doc.Content[0], c.root, c.rootName, "<"+c.rootName+">")
+	}
+	c.out.WriteString("</" + c.rootName + ">\n")
+
+	if len(c.errs) > 0 {
+		return nil, c.errs
+	}
+	return c.out.Bytes(), nil
+}
+
+// converter holds the state of a single conversion.
+type converter struct {
+	fileName string
+	root     *schema
+	rootName string
+	out      bytes.Buffer
+	errs     Errors
+}
+
+// fail records a problem at the node.
+func (c *converter) fail(n *yaml.Node, format string, args ...any) {
+	c.errs = append(c.errs, &Error{
+		FileName: c.fileName,
+		Line:     n.Line,
+		Column:   n.Column,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// resolve returns the node an alias refers to or the node itself.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// isNull returns true if the node is an empty or null scalar.
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && (n.Tag == "!!null" || n.Value == "")
+}
+
+// convertChildren converts the mapping node n into the children of the
+// element having the name and schema s.  The path is the path of the
+// element used in messages.
+func (c *converter) convertChildren(n *yaml.Node, s *schema, name string, path string) {
+	n = resolve(n)
+	if isNull(n) {
+		return
+	}
+	if n.Kind != yaml.MappingNode {
+		c.fail(n, "expected a mapping for <%s>", name)
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		value := resolve(n.Content[i+1])
+		child := s.children[key.Value]
+		if child == nil {
+			paths := c.root.findPaths(key.Value, "<"+c.rootName+">")
+			if len(paths) > 0 {
+				c.fail(key, "misplaced element <%s> in <%s>; expected in %s",
+					key.Value, name, strings.Join(paths, " or "))
+			} else {
+				c.fail(key, "unknown element <%s> in <%s>", key.Value, name)
+			}
+			continue
+		}
+		childPath := path + "<" + key.Value + ">"
+
+		// A sequence is either repeated elements or, for a wrapper
+		// element with a single child, the children of the wrapper.
+		if value.Kind == yaml.SequenceNode {
+			if child.wrapper && len(child.children) == 1 {
+				c.out.WriteString("<" + key.Value + ">")
+				for itemName, itemSchema := range child.children {
+					for _, item := range value.Content {
+						c.convertElement(resolve(item), itemSchema, itemName,
+							childPath+"<"+itemName+">")
+					}
+				}
+				c.out.WriteString("</" + key.Value + ">\n")
+			} else {
+				for _, item := range value.Content {
+					c.convertElement(resolve(item), child, key.Value, childPath)
+				}
+			}
+			continue
+		}
+		c.convertElement(value, child, key.Value, childPath)
+	}
+}
+
+// convertElement converts the node n into the element having the name
+// and schema s.
+func (c *converter) convertElement(n *yaml.Node, s *schema, name string, path string) {
+	if s.children != nil {
+		c.out.WriteString("<" + name + ">")
+		c.convertChildren(n, s, name, path)
+		c.out.WriteString("</" + name + ">\n")
+		return
+	}
+	if n.Kind != yaml.ScalarNode {
+		c.fail(n, "expected a value for <%s>", name)
+		return
+	}
+	text := n.Value
+	if n.Tag == "!!null" {
+		text = ""
+	}
+	err := checkLeaf(s.leaf, text)
+	if err != nil {
+		c.fail(n, "invalid value %q for <%s>: %v", text, name, err)
+		return
+	}
+	c.out.WriteString("<" + name + ">")
+	xml.EscapeText(&c.out, []byte(text))
+	c.out.WriteString("</" + name + ">\n")
+}